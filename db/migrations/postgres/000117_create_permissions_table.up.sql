@@ -0,0 +1,11 @@
+BEGIN;
+CREATE TABLE permissions (
+  seq               SERIAL          PRIMARY KEY,
+  namespace         VARCHAR(64)     NOT NULL,
+  principal         VARCHAR(1024)   NOT NULL,
+  role              VARCHAR(64)     NOT NULL,
+  created           BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX permissions_principal ON permissions(namespace,principal);
+COMMIT;
\ No newline at end of file
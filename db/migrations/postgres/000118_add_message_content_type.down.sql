@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE messages DROP COLUMN content_type;
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE messages ADD COLUMN content_type VARCHAR(255) DEFAULT '';
+COMMIT;
\ No newline at end of file
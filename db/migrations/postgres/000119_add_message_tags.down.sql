@@ -0,0 +1,4 @@
+BEGIN;
+DROP TABLE IF EXISTS messages_tags;
+ALTER TABLE messages DROP COLUMN tags;
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+ALTER TABLE messages ADD COLUMN tags VARCHAR(1024) DEFAULT '';
+
+CREATE TABLE messages_tags (
+  seq         SERIAL          PRIMARY KEY,
+  namespace   VARCHAR(64)     NOT NULL,
+  message_id  UUID            NOT NULL,
+  tag         VARCHAR(64)     NOT NULL
+);
+CREATE INDEX messages_tags_tag ON messages_tags(namespace,tag);
+CREATE INDEX messages_tags_message ON messages_tags(namespace,message_id);
+COMMIT;
@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE data DROP COLUMN dtype;
+COMMIT;
\ No newline at end of file
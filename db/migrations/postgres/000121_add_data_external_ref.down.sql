@@ -0,0 +1,4 @@
+BEGIN;
+ALTER TABLE data DROP COLUMN external_url;
+ALTER TABLE data DROP COLUMN external_hash;
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,4 @@
+BEGIN;
+ALTER TABLE data ADD COLUMN external_url VARCHAR(1024) DEFAULT '';
+ALTER TABLE data ADD COLUMN external_hash CHAR(64);
+COMMIT;
\ No newline at end of file
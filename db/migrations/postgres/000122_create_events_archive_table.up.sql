@@ -0,0 +1,13 @@
+BEGIN;
+CREATE TABLE events_archive (
+  seq            BIGINT          PRIMARY KEY,
+  id             UUID            NOT NULL,
+  etype          VARCHAR(64)     NOT NULL,
+  namespace      VARCHAR(64)     NOT NULL,
+  ref            UUID,
+  cid            UUID,
+  tx_id          UUID,
+  topic          VARCHAR(64)     NOT NULL,
+  created        BIGINT          NOT NULL
+);
+COMMIT;
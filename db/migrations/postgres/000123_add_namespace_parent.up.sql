@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE namespaces ADD COLUMN parent VARCHAR(64);
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+DROP INDEX messages_reply_to;
+ALTER TABLE messages DROP COLUMN reply_to;
+COMMIT;
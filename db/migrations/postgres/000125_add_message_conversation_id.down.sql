@@ -0,0 +1,4 @@
+BEGIN;
+DROP INDEX messages_conversation_id;
+ALTER TABLE messages DROP COLUMN conversation_id;
+COMMIT;
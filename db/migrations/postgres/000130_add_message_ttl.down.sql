@@ -0,0 +1,5 @@
+BEGIN;
+DROP INDEX IF EXISTS messages_expires;
+ALTER TABLE messages DROP COLUMN expires;
+ALTER TABLE messages DROP COLUMN ttl;
+COMMIT;
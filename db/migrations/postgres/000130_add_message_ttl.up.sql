@@ -0,0 +1,5 @@
+BEGIN;
+ALTER TABLE messages ADD COLUMN ttl VARCHAR(64);
+ALTER TABLE messages ADD COLUMN expires BIGINT;
+CREATE INDEX messages_expires ON messages(namespace_local, expires);
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+DROP TABLE IF EXISTS pending_delivery_receipts;
+DROP TABLE IF EXISTS delivery_receipts;
+COMMIT;
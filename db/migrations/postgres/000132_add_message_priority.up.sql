@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE messages ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+COMMIT;
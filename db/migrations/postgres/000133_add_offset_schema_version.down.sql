@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE offsets DROP COLUMN schema_version;
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+ALTER TABLE offsets ADD COLUMN schema_version INTEGER NOT NULL DEFAULT 0;
+-- Aggregator offsets stored before this migration refer to the current sequences
+UPDATE offsets SET schema_version = 1 WHERE otype = 'aggregator';
+COMMIT;
@@ -0,0 +1,9 @@
+CREATE TABLE permissions (
+  seq               INTEGER         PRIMARY KEY AUTOINCREMENT,
+  namespace         VARCHAR(64)     NOT NULL,
+  principal         VARCHAR(1024)   NOT NULL,
+  role              VARCHAR(64)     NOT NULL,
+  created           BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX permissions_principal ON permissions(namespace,principal);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE messages ADD COLUMN content_type VARCHAR(255) DEFAULT '';
\ No newline at end of file
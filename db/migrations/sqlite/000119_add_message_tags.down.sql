@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS messages_tags;
+ALTER TABLE messages DROP COLUMN tags;
@@ -0,0 +1 @@
+ALTER TABLE data DROP COLUMN dtype;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE data ADD COLUMN dtype VARCHAR(64) DEFAULT '';
+UPDATE data SET dtype = 'value' WHERE blob_hash IS NULL;
+UPDATE data SET dtype = 'blob' WHERE blob_hash IS NOT NULL;
\ No newline at end of file
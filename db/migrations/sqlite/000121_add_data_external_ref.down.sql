@@ -0,0 +1,2 @@
+ALTER TABLE data DROP COLUMN external_url;
+ALTER TABLE data DROP COLUMN external_hash;
\ No newline at end of file
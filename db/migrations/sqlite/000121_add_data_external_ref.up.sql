@@ -0,0 +1,2 @@
+ALTER TABLE data ADD COLUMN external_url VARCHAR(1024) DEFAULT '';
+ALTER TABLE data ADD COLUMN external_hash CHAR(64);
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE events_archive (
+  seq            INTEGER         PRIMARY KEY,
+  id             UUID            NOT NULL,
+  etype          VARCHAR(64)     NOT NULL,
+  namespace      VARCHAR(64)     NOT NULL,
+  ref            UUID,
+  cid            UUID,
+  tx_id          UUID,
+  topic          VARCHAR(64)     NOT NULL,
+  created        BIGINT          NOT NULL
+);
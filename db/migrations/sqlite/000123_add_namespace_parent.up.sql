@@ -0,0 +1 @@
+ALTER TABLE namespaces ADD COLUMN parent VARCHAR(64);
@@ -0,0 +1,2 @@
+ALTER TABLE messages ADD COLUMN reply_to UUID;
+CREATE INDEX messages_reply_to ON messages(namespace_local, reply_to);
@@ -0,0 +1,2 @@
+DROP INDEX messages_conversation_id;
+ALTER TABLE messages DROP COLUMN conversation_id;
@@ -0,0 +1,2 @@
+ALTER TABLE messages ADD COLUMN conversation_id UUID;
+CREATE INDEX messages_conversation_id ON messages(namespace_local, conversation_id);
@@ -0,0 +1,13 @@
+CREATE TABLE deadletters (
+  seq               INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id                UUID            NOT NULL,
+  namespace         VARCHAR(64)     NOT NULL,
+  subscription_id   UUID            NOT NULL,
+  event_id          UUID            NOT NULL,
+  event_seq         BIGINT          NOT NULL,
+  error             TEXT,
+  created           BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX deadletters_id ON deadletters(id);
+CREATE INDEX deadletters_subscription ON deadletters(namespace, subscription_id);
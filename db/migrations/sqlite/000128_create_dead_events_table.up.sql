@@ -0,0 +1,16 @@
+CREATE TABLE dead_events (
+  seq               INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id                UUID            NOT NULL,
+  namespace         VARCHAR(64)     NOT NULL,
+  pin_seq           BIGINT          NOT NULL,
+  batch_id          UUID,
+  hash              CHAR(64),
+  pin_index         BIGINT,
+  attempts          INTEGER         NOT NULL,
+  error             TEXT,
+  created           BIGINT          NOT NULL,
+  updated           BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX dead_events_id ON dead_events(id);
+CREATE UNIQUE INDEX dead_events_pin ON dead_events(namespace, pin_seq);
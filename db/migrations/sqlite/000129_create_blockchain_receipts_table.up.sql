@@ -0,0 +1,15 @@
+CREATE TABLE blockchain_receipts (
+  seq               INTEGER         PRIMARY KEY AUTOINCREMENT,
+  namespace         VARCHAR(64)     NOT NULL,
+  op_id             UUID            NOT NULL,
+  tx_id             VARCHAR(1024),
+  message_id        UUID,
+  block_number      VARCHAR(256),
+  block_hash        VARCHAR(256),
+  status            VARCHAR(64)     NOT NULL,
+  confirmed_at      BIGINT
+);
+
+CREATE UNIQUE INDEX blockchain_receipts_op ON blockchain_receipts(namespace, op_id);
+CREATE INDEX blockchain_receipts_tx ON blockchain_receipts(namespace, tx_id);
+CREATE INDEX blockchain_receipts_message ON blockchain_receipts(namespace, message_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS messages_expires;
+ALTER TABLE messages DROP COLUMN expires;
+ALTER TABLE messages DROP COLUMN ttl;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pending_delivery_receipts;
+DROP TABLE IF EXISTS delivery_receipts;
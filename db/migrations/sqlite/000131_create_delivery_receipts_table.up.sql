@@ -0,0 +1,27 @@
+CREATE TABLE delivery_receipts (
+  seq               INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id                UUID            NOT NULL,
+  namespace         VARCHAR(64)     NOT NULL,
+  message_id        UUID            NOT NULL,
+  message_hash      CHAR(64)        NOT NULL,
+  recipient         VARCHAR(1024)   NOT NULL,
+  key               VARCHAR(1024),
+  broadcast_id      UUID,
+  received          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX delivery_receipts_id ON delivery_receipts(id);
+CREATE UNIQUE INDEX delivery_receipts_recipient ON delivery_receipts(namespace, message_id, recipient);
+
+CREATE TABLE pending_delivery_receipts (
+  seq               INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id                UUID            NOT NULL,
+  namespace         VARCHAR(64)     NOT NULL,
+  message_id        UUID            NOT NULL,
+  message_hash      CHAR(64)        NOT NULL,
+  recipient         VARCHAR(1024)   NOT NULL,
+  received          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX pending_delivery_receipts_id ON pending_delivery_receipts(id);
+CREATE INDEX pending_delivery_receipts_namespace ON pending_delivery_receipts(namespace);
@@ -0,0 +1 @@
+ALTER TABLE messages ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
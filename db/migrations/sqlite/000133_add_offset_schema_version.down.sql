@@ -0,0 +1 @@
+ALTER TABLE offsets DROP COLUMN schema_version;
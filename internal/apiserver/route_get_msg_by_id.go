@@ -34,6 +34,7 @@ var getMsgByID = &ffapi.Route{
 	},
 	QueryParams: []*ffapi.QueryParam{
 		{Name: "fetchdata", IsBool: true, Description: coremsgs.APIFetchDataDesc},
+		{Name: "includePinStatus", IsBool: true, Description: coremsgs.APIIncludePinStatusDesc},
 	},
 	Description:     coremsgs.APIEndpointsGetMsgByID,
 	JSONInputValue:  nil,
@@ -41,10 +42,19 @@ var getMsgByID = &ffapi.Route{
 	JSONOutputCodes: []int{http.StatusOK},
 	Extensions: &coreExtensions{
 		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
+			includePinStatus := strings.EqualFold(r.QP["includePinStatus"], "true")
 			if strings.EqualFold(r.QP["data"], "true") || strings.EqualFold(r.QP["fetchdata"], "true") {
-				return cr.or.GetMessageByIDWithData(cr.ctx, r.PP["msgid"])
+				msg, err := cr.or.GetMessageByIDWithData(cr.ctx, r.PP["msgid"])
+				if err == nil && includePinStatus {
+					err = cr.or.PopulateMessagePinStatus(cr.ctx, []*core.Message{&msg.Message})
+				}
+				return msg, err
 			}
-			return cr.or.GetMessageByID(cr.ctx, r.PP["msgid"])
+			msg, err := cr.or.GetMessageByID(cr.ctx, r.PP["msgid"])
+			if err == nil && includePinStatus {
+				err = cr.or.PopulateMessagePinStatus(cr.ctx, []*core.Message{msg})
+			}
+			return msg, err
 		},
 	},
 }
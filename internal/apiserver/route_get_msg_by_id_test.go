@@ -52,3 +52,39 @@ func TestGetMessageByIDWithData(t *testing.T) {
 
 	assert.Equal(t, 200, res.Result().StatusCode)
 }
+
+func TestGetMessageByIDWithPinStatus(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages/abcd12345?includePinStatus", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	msg := &core.Message{}
+	o.On("GetMessageByID", mock.Anything, "abcd12345").
+		Return(msg, nil)
+	o.On("PopulateMessagePinStatus", mock.Anything, []*core.Message{msg}).
+		Return(nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	o.AssertExpectations(t)
+}
+
+func TestGetMessageByIDWithDataAndPinStatus(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages/abcd12345?fetchdata&includePinStatus", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	msg := &core.MessageInOut{}
+	o.On("GetMessageByIDWithData", mock.Anything, "abcd12345").
+		Return(msg, nil)
+	o.On("PopulateMessagePinStatus", mock.Anything, []*core.Message{&msg.Message}).
+		Return(nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	o.AssertExpectations(t)
+}
@@ -0,0 +1,98 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/orchestrator"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// blobContentTypes are the message content types that are served as-is on blob downloads.
+// The content type is chosen by the sender, so anything else is served as application/octet-stream
+// to stop a browser rendering it as active content (such as text/html) on the API origin.
+var blobContentTypes = map[string]bool{
+	"application/json":         true,
+	"application/octet-stream": true,
+	"image/gif":                true,
+	"image/jpeg":               true,
+	"image/png":                true,
+	"text/csv":                 true,
+	"text/plain":               true,
+}
+
+func blobContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !blobContentTypes[mediaType] {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+var getMsgDataBlob = &ffapi.Route{
+	Name:   "getMsgDataBlob",
+	Path:   "messages/{msgid}/data/{dataid}/blob",
+	Method: http.MethodGet,
+	PathParams: []*ffapi.PathParam{
+		{Name: "msgid", Description: coremsgs.APIParamsMessageID},
+		{Name: "dataid", Description: coremsgs.APIParamsDataID},
+	},
+	QueryParams:     nil,
+	FilterFactory:   nil,
+	Description:     coremsgs.APIEndpointsGetMsgDataBlob,
+	JSONInputValue:  nil,
+	JSONOutputValue: func() interface{} { return []byte{} },
+	JSONOutputCodes: []int{http.StatusOK},
+	Extensions: &coreExtensions{
+		EnabledIf: func(or orchestrator.Orchestrator) bool {
+			return or.Data().BlobsEnabled()
+		},
+		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
+			msg, err := cr.or.GetMessageByID(cr.ctx, r.PP["msgid"])
+			if err != nil {
+				return nil, err
+			}
+			attached := false
+			for _, dataRef := range msg.Data {
+				if dataRef.ID != nil && dataRef.ID.String() == r.PP["dataid"] {
+					attached = true
+					break
+				}
+			}
+			if !attached {
+				return nil, i18n.NewError(cr.ctx, coremsgs.Msg404NotFound)
+			}
+			blob, reader, err := cr.or.Data().DownloadBlob(cr.ctx, r.PP["dataid"])
+			if err != nil {
+				return nil, err
+			}
+			r.ResponseHeaders.Set(core.HTTPHeadersBlobHashSHA256, blob.Hash.String())
+			if blob.Size > 0 {
+				r.ResponseHeaders.Set(core.HTTPHeadersBlobSize, strconv.FormatInt(blob.Size, 10))
+			}
+			r.ResponseHeaders.Set("Content-Type", blobContentType(msg.Header.ContentType))
+			r.ResponseHeaders.Set("X-Content-Type-Options", "nosniff")
+			return reader, nil
+		},
+	},
+}
@@ -0,0 +1,125 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/multipartymocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetMsgDataBlob(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	mdm := &datamocks.Manager{}
+	mdm.On("BlobsEnabled").Return(true)
+	o.On("Data").Return(mdm)
+	o.On("MultiParty").Return(&multipartymocks.Manager{})
+	dataID := fftypes.NewUUID()
+	o.On("GetMessageByID", mock.Anything, "msg1").Return(&core.Message{
+		Header: core.MessageHeader{ContentType: "image/png"},
+		Data:   core.DataRefs{{ID: dataID}},
+	}, nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages/msg1/data/"+dataID.String()+"/blob", nil)
+	res := httptest.NewRecorder()
+
+	blobHash := fftypes.NewRandB32()
+	mdm.On("DownloadBlob", mock.Anything, dataID.String()).
+		Return(&core.Blob{
+			Hash: blobHash,
+			Size: 12345,
+		}, io.NopCloser(bytes.NewReader([]byte("hello"))), nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	b, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(b))
+	assert.Equal(t, "image/png", res.Result().Header.Get("Content-Type"))
+	assert.Equal(t, "nosniff", res.Result().Header.Get("X-Content-Type-Options"))
+	assert.Equal(t, "12345", res.Result().Header.Get(core.HTTPHeadersBlobSize))
+	assert.Equal(t, blobHash.String(), res.Result().Header.Get(core.HTTPHeadersBlobHashSHA256))
+}
+
+func TestGetMsgDataBlobNotAttached(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	mdm := &datamocks.Manager{}
+	mdm.On("BlobsEnabled").Return(true)
+	o.On("Data").Return(mdm)
+	o.On("MultiParty").Return(&multipartymocks.Manager{})
+	o.On("GetMessageByID", mock.Anything, "msg1").Return(&core.Message{
+		Data: core.DataRefs{{ID: fftypes.NewUUID()}},
+	}, nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages/msg1/data/"+fftypes.NewUUID().String()+"/blob", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 404, res.Result().StatusCode)
+}
+
+func TestGetMsgDataBlobMsgFail(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	mdm := &datamocks.Manager{}
+	mdm.On("BlobsEnabled").Return(true)
+	o.On("Data").Return(mdm)
+	o.On("MultiParty").Return(&multipartymocks.Manager{})
+	o.On("GetMessageByID", mock.Anything, "msg1").Return(nil, fmt.Errorf("pop"))
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages/msg1/data/abcd/blob", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 500, res.Result().StatusCode)
+}
+
+func TestGetMsgDataBlobDownloadFail(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	mdm := &datamocks.Manager{}
+	mdm.On("BlobsEnabled").Return(true)
+	o.On("Data").Return(mdm)
+	o.On("MultiParty").Return(&multipartymocks.Manager{})
+	dataID := fftypes.NewUUID()
+	o.On("GetMessageByID", mock.Anything, "msg1").Return(&core.Message{
+		Data: core.DataRefs{{ID: dataID}},
+	}, nil)
+	mdm.On("DownloadBlob", mock.Anything, dataID.String()).Return(nil, nil, fmt.Errorf("pop"))
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages/msg1/data/"+dataID.String()+"/blob", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 500, res.Result().StatusCode)
+}
+
+func TestBlobContentType(t *testing.T) {
+	assert.Equal(t, "image/png", blobContentType("image/png"))
+	assert.Equal(t, "text/plain", blobContentType("text/plain; charset=utf-8"))
+	assert.Equal(t, "application/octet-stream", blobContentType(""))
+	assert.Equal(t, "application/octet-stream", blobContentType("text/html"))
+	assert.Equal(t, "application/octet-stream", blobContentType("image/svg+xml"))
+	assert.Equal(t, "application/octet-stream", blobContentType("!!!"))
+}
@@ -33,6 +33,8 @@ var getMsgs = &ffapi.Route{
 	PathParams: nil,
 	QueryParams: []*ffapi.QueryParam{
 		{Name: "fetchdata", IsBool: true, Description: coremsgs.APIFetchDataDesc},
+		{Name: "includePinStatus", IsBool: true, Description: coremsgs.APIIncludePinStatusDesc},
+		{Name: "hastag", Description: coremsgs.APIHasTagDesc},
 	},
 	FilterFactory:   database.MessageQueryFactory,
 	Description:     coremsgs.APIEndpointsGetMsgs,
@@ -41,10 +43,36 @@ var getMsgs = &ffapi.Route{
 	JSONOutputCodes: []int{http.StatusOK},
 	Extensions: &coreExtensions{
 		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
+			includePinStatus := strings.EqualFold(r.QP["includePinStatus"], "true")
+			tag := r.QP["hastag"]
 			if strings.EqualFold(r.QP["fetchdata"], "true") {
-				return r.FilterResult(cr.or.GetMessagesWithData(cr.ctx, r.Filter))
+				var msgs []*core.MessageInOut
+				var fr *ffapi.FilterResult
+				if tag != "" {
+					msgs, fr, err = cr.or.GetMessagesWithDataForTag(cr.ctx, tag, r.Filter)
+				} else {
+					msgs, fr, err = cr.or.GetMessagesWithData(cr.ctx, r.Filter)
+				}
+				if err == nil && includePinStatus {
+					plain := make([]*core.Message, len(msgs))
+					for i, msg := range msgs {
+						plain[i] = &msg.Message
+					}
+					err = cr.or.PopulateMessagePinStatus(cr.ctx, plain)
+				}
+				return r.FilterResult(msgs, fr, err)
 			}
-			return r.FilterResult(cr.or.GetMessages(cr.ctx, r.Filter))
+			var msgs []*core.Message
+			var fr *ffapi.FilterResult
+			if tag != "" {
+				msgs, fr, err = cr.or.GetMessagesForTag(cr.ctx, tag, r.Filter)
+			} else {
+				msgs, fr, err = cr.or.GetMessages(cr.ctx, r.Filter)
+			}
+			if err == nil && includePinStatus {
+				err = cr.or.PopulateMessagePinStatus(cr.ctx, msgs)
+			}
+			return r.FilterResult(msgs, fr, err)
 		},
 	},
 }
@@ -86,3 +86,67 @@ func TestGetMessagesWithCountAndData(t *testing.T) {
 	assert.Equal(t, int64(0), resWithCount.Count)
 	assert.Equal(t, int64(10), *resWithCount.Total)
 }
+
+func TestGetMessagesWithPinStatus(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages?includePinStatus", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	msgs := []*core.Message{{}}
+	o.On("GetMessages", mock.Anything, mock.Anything).
+		Return(msgs, nil, nil)
+	o.On("PopulateMessagePinStatus", mock.Anything, msgs).
+		Return(nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	o.AssertExpectations(t)
+}
+
+func TestGetMessagesWithDataAndPinStatus(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages?fetchdata&includePinStatus", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	msgs := []*core.MessageInOut{{}}
+	o.On("GetMessagesWithData", mock.Anything, mock.Anything).
+		Return(msgs, nil, nil)
+	o.On("PopulateMessagePinStatus", mock.Anything, []*core.Message{&msgs[0].Message}).
+		Return(nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	o.AssertExpectations(t)
+}
+
+func TestGetMessagesForTag(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages?hastag=invoice", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("GetMessagesForTag", mock.Anything, "invoice", mock.Anything).
+		Return([]*core.Message{}, nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestGetMessagesWithDataForTag(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages?hastag=invoice&fetchdata", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("GetMessagesWithDataForTag", mock.Anything, "invoice", mock.Anything).
+		Return([]*core.MessageInOut{}, nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
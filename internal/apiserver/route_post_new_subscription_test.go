@@ -18,10 +18,14 @@ package apiserver
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -43,3 +47,47 @@ func TestPostNewSubscription(t *testing.T) {
 
 	assert.Equal(t, 201, res.Result().StatusCode)
 }
+
+func TestPostNewSubscriptionNamespacesAuthorized(t *testing.T) {
+	mgr, o, as := newTestServer()
+	r := as.createMuxRouter(context.Background(), mgr)
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	mgr.On("Authorize", mock.Anything, mock.MatchedBy(func(authReq *fftypes.AuthReq) bool {
+		return authReq.Namespace == "ns2" && authReq.Method == http.MethodGet
+	})).Return(nil)
+	input := core.Subscription{
+		Filter: core.SubscriptionFilter{Namespaces: []string{"ns2"}},
+	}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/subscriptions", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("CreateSubscription", mock.Anything, mock.AnythingOfType("*core.Subscription")).
+		Return(&core.Subscription{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 201, res.Result().StatusCode)
+	mgr.AssertExpectations(t)
+}
+
+func TestPostNewSubscriptionNamespacesUnauthorized(t *testing.T) {
+	mgr, o, as := newTestServer()
+	r := as.createMuxRouter(context.Background(), mgr)
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	mgr.On("Authorize", mock.Anything, mock.Anything).Return(i18n.NewError(context.Background(), i18n.MsgUnauthorized))
+	input := core.Subscription{
+		Filter: core.SubscriptionFilter{Namespaces: []string{"ns2"}},
+	}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/subscriptions", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 401, res.Result().StatusCode)
+	o.AssertNotCalled(t, "CreateSubscription", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,51 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"net/http"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+var postNewWebhookSubscription = &ffapi.Route{
+	Name:            "postNewWebhookSubscription",
+	Path:            "subscriptions/webhooks",
+	Method:          http.MethodPost,
+	PathParams:      nil,
+	QueryParams:     nil,
+	Description:     coremsgs.APIEndpointsPostNewWebhookSubscription,
+	JSONInputValue:  func() interface{} { return &core.Subscription{} },
+	JSONOutputValue: func() interface{} { return &core.Subscription{} },
+	JSONOutputCodes: []int{http.StatusCreated}, // Sync operation
+	Extensions: &coreExtensions{
+		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
+			sub := r.Input.(*core.Subscription)
+			sub.Transport = "webhooks"
+			if sub.Filter.Events == "" {
+				sub.Filter.Events = core.EventTypeMessageConfirmed.String()
+			}
+			if err := authorizeSubscriptionNamespaces(r, cr, sub); err != nil {
+				return nil, err
+			}
+			output, err = cr.or.CreateSubscription(cr.ctx, sub)
+			return output, err
+		},
+	},
+}
@@ -36,7 +36,11 @@ var putSubscription = &ffapi.Route{
 	JSONOutputCodes: []int{http.StatusOK}, // Sync operation
 	Extensions: &coreExtensions{
 		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
-			output, err = cr.or.CreateUpdateSubscription(cr.ctx, r.Input.(*core.Subscription))
+			sub := r.Input.(*core.Subscription)
+			if err := authorizeSubscriptionNamespaces(r, cr, sub); err != nil {
+				return nil, err
+			}
+			output, err = cr.or.CreateUpdateSubscription(cr.ctx, sub)
 			return output, err
 		},
 	},
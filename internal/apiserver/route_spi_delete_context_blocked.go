@@ -0,0 +1,47 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"net/http"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+var spiDeleteContextBlocked = &ffapi.Route{
+	Name:   "spiDeleteContextBlocked",
+	Path:   "contexts/{context}/blocked",
+	Method: http.MethodDelete,
+	PathParams: []*ffapi.PathParam{
+		{Name: "context", Description: coremsgs.APIParamsContextHash},
+	},
+	QueryParams:     nil,
+	Description:     coremsgs.APIEndpointsAdminDeleteContextBlocked,
+	JSONInputValue:  nil,
+	JSONOutputValue: nil,
+	JSONOutputCodes: []int{http.StatusNoContent},
+	Extensions: &coreExtensions{
+		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
+			// The caller is recorded against the skipped pin, for audit
+			authReq := &fftypes.AuthReq{Method: r.Req.Method, URL: r.Req.URL, Header: r.Req.Header}
+			err = cr.or.ForceUnblockContext(cr.ctx, r.PP["context"], authReq)
+			return nil, err
+		},
+	},
+}
@@ -0,0 +1,60 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSPIDeleteContextBlocked(t *testing.T) {
+	or, r := newTestSPIServer()
+	or.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	hash := fftypes.NewRandB32()
+	req := httptest.NewRequest("DELETE", "/spi/v1/namespaces/ns1/contexts/"+hash.String()+"/blocked", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.SetBasicAuth("admin1", "pass")
+	res := httptest.NewRecorder()
+
+	or.On("ForceUnblockContext", mock.Anything, hash.String(), mock.MatchedBy(func(authReq *fftypes.AuthReq) bool {
+		user, _, _ := (&http.Request{Header: authReq.Header}).BasicAuth()
+		return user == "admin1"
+	})).Return(nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 204, res.Result().StatusCode)
+}
+
+func TestSPIDeleteContextBlockedFail(t *testing.T) {
+	or, r := newTestSPIServer()
+	or.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	hash := fftypes.NewRandB32()
+	req := httptest.NewRequest("DELETE", "/spi/v1/contexts/"+hash.String()+"/blocked", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	or.On("ForceUnblockContext", mock.Anything, hash.String(), mock.Anything).Return(fmt.Errorf("pop"))
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 500, res.Result().StatusCode)
+}
@@ -0,0 +1,54 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+var spiPostPurgeEvents = &ffapi.Route{
+	Name:       "spiPostPurgeEvents",
+	Path:       "events/purge",
+	Method:     http.MethodPost,
+	PathParams: nil,
+	QueryParams: []*ffapi.QueryParam{
+		{Name: "before", Description: coremsgs.APIPurgeEventsBeforeParam, IsBool: false},
+	},
+	Description:     coremsgs.APIEndpointsAdminPostPurgeEvents,
+	JSONInputValue:  func() interface{} { return &core.EmptyInput{} },
+	JSONOutputValue: func() interface{} { return &core.EventPurgeResult{} },
+	JSONOutputCodes: []int{http.StatusOK},
+	Extensions: &coreExtensions{
+		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
+			before, err := strconv.ParseInt(r.QP["before"], 10, 64)
+			if err != nil {
+				return nil, i18n.NewError(cr.ctx, coremsgs.MsgInvalidChartNumberParam, "before")
+			}
+			deleted, err := cr.or.DeleteEventsBefore(cr.ctx, before)
+			if err != nil {
+				return nil, err
+			}
+			return &core.EventPurgeResult{Before: before, Deleted: deleted}, nil
+		},
+	},
+}
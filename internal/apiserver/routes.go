@@ -49,6 +49,7 @@ var nsRoutes = []*ffapi.Route{}
 var routes = append(
 	globalRoutes([]*ffapi.Route{
 		getNamespace,
+		getNamespaceDescendants,
 		getNamespaces,
 		getWebSockets,
 	}),
@@ -57,6 +58,7 @@ var routes = append(
 		deleteContractInterface,
 		deleteContractListener,
 		deleteData,
+		deleteMessage,
 		deleteSubscription,
 		deleteTokenPool,
 		getBatchByID,
@@ -73,6 +75,9 @@ var routes = append(
 		getContractInterfaces,
 		getContractListenerByNameOrID,
 		getContractListeners,
+		getContextsBlocked,
+		getContextsBlockedStats,
+		getConversationMsgs,
 		getData,
 		getDataBlob,
 		getDataSubPaths,
@@ -81,6 +86,7 @@ var routes = append(
 		getDataMsgs,
 		getDatatypeByName,
 		getDatatypes,
+		getDeadEvents,
 		getEventByID,
 		getEvents,
 		getGroupByHash,
@@ -92,7 +98,10 @@ var routes = append(
 		getIdentityVerifiers,
 		getMsgByID,
 		getMsgData,
+		getMsgDataBlob,
 		getMsgEvents,
+		getMsgReceipts,
+		getMsgReplies,
 		getMsgs,
 		getMsgTxn,
 		getNetworkDIDDocByDID,
@@ -110,6 +119,7 @@ var routes = append(
 		getStatusBatchManager,
 		getSubscriptionByID,
 		getSubscriptions,
+		getSubscriptionDeadLetters,
 		getSubscriptionEventsFiltered,
 		getTokenAccountPools,
 		getTokenAccounts,
@@ -140,7 +150,9 @@ var routes = append(
 		postContractQuery,
 		postData,
 		postDataBlobPublish,
+		postDataCopy,
 		postDataValuePublish,
+		postDeadEventRequeue,
 		postNetworkAction,
 		postNewContractAPI,
 		postNewContractInterface,
@@ -151,6 +163,7 @@ var routes = append(
 		postNewMessagePrivate,
 		postNewMessageRequestReply,
 		postNewSubscription,
+		postNewWebhookSubscription,
 		postNewOrganization,
 		postNewOrganizationSelf,
 		postNodesSelf,
@@ -38,6 +38,7 @@ import (
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/namespace"
 	"github.com/hyperledger/firefly/internal/orchestrator"
+	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
@@ -164,6 +165,23 @@ func getOrchestrator(ctx context.Context, mgr namespace.Manager, tag string, r *
 	return nil, i18n.NewError(ctx, coremsgs.MsgMissingNamespace)
 }
 
+// authorizeSubscriptionNamespaces checks the caller is authorized to read from each additional
+// namespace a subscription delivers events from, as the request is only authorized against its own namespace
+func authorizeSubscriptionNamespaces(r *ffapi.APIRequest, cr *coreRequest, sub *core.Subscription) error {
+	for _, ns := range sub.Filter.Namespaces {
+		authReq := &fftypes.AuthReq{
+			Method:    http.MethodGet,
+			URL:       r.Req.URL,
+			Header:    r.Req.Header,
+			Namespace: ns,
+		}
+		if err := cr.mgr.Authorize(cr.ctx, authReq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (as *apiServer) baseSwaggerGenOptions() ffapi.SwaggerGenOptions {
 	return ffapi.SwaggerGenOptions{
 		Title:                     "Hyperledger FireFly",
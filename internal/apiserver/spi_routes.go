@@ -28,11 +28,22 @@ var spiRoutes = append(globalRoutes([]*ffapi.Route{
 	spiGetNamespaceByName,
 	spiGetNamespaces,
 	spiGetOpByID,
+	spiGetSubscriptions,
 	spiPatchOpByID,
 	spiPostReset,
 }),
 	namespacedSPIRoutes([]*ffapi.Route{
+		spiDeleteContextBlocked,
+		spiDeleteEventByID,
+		spiDeletePermission,
+		spiGetAggregatorReplay,
 		spiGetOps,
+		spiGetPermissions,
+		spiPostAggregatorPause,
+		spiPostAggregatorReplay,
+		spiPostAggregatorResume,
+		spiPostPermission,
+		spiPostPurgeEvents,
 	})...,
 )
 
@@ -189,6 +189,9 @@ func (cb *callbacks) PrepareBatchPinOrNetworkAction(ctx context.Context, events
 	if err != nil {
 		return // move on
 	}
+	if params.PayloadRef == blockchain.MessagePinPayloadRef {
+		batch.TransactionType = core.TransactionTypeMessagePin
+	}
 
 	// For V1 of the FireFly contract, namespace is passed explicitly, but needs to be mapped to local name(s).
 	// For V2+, namespace is inferred from the subscription.
@@ -202,7 +205,9 @@ func (cb *callbacks) PrepareBatchPinOrNetworkAction(ctx context.Context, events
 		cb.addBatchPinComplete(ctx, events, namespaces, batch, signingKey)
 		return
 	}
-	batch.TransactionType = core.TransactionTypeBatchPin
+	if batch.TransactionType == "" {
+		batch.TransactionType = core.TransactionTypeBatchPin
+	}
 	if strings.HasPrefix(params.NsOrAction, blockchain.FireFlyActionPrefix) {
 		typeName := params.NsOrAction[len(blockchain.FireFlyActionPrefix):]
 		if typeName == "contract_invoke_pin" {
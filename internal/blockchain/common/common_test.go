@@ -165,6 +165,44 @@ func TestBatchPinContractInvokePin(t *testing.T) {
 	mcb.AssertExpectations(t)
 }
 
+func TestBatchPinMessagePin(t *testing.T) {
+	event := &blockchain.Event{}
+	verifier := &core.VerifierRef{}
+	params := &BatchPinParams{
+		UUIDs:      "0xe19af8b390604051812d7597d19adfb9847d3bfd074249efb65d3fed15f5b0a6",
+		BatchHash:  "0xd71eb138d74c229a388eb0e1abc03f4c7cbb21d4fc4b839fbf0ec73e4263f6be",
+		Contexts:   []string{},
+		PayloadRef: blockchain.MessagePinPayloadRef,
+	}
+
+	mcb := &blockchainmocks.Callbacks{}
+	cb := NewBlockchainCallbacks()
+	cb.SetHandler("ns1", mcb)
+
+	mcb.On("BlockchainEventBatch", matchBatchPinEvent("ns1", core.TransactionTypeMessagePin)).Return(nil).Twice()
+
+	sub := &SubscriptionInfo{
+		Version:     2,
+		V2Namespace: "ns1",
+	}
+	events := make(EventsToDispatch)
+	cb.PrepareBatchPinOrNetworkAction(context.Background(), events, sub, fftypes.JSONAnyPtr("{}"), event, verifier, params)
+	err := cb.DispatchBlockchainEvents(context.Background(), events)
+	assert.NoError(t, err)
+
+	params.NsOrAction = "ns1"
+	sub = &SubscriptionInfo{
+		Version:     1,
+		V1Namespace: map[string][]string{"ns1": {"ns1"}},
+	}
+	events = make(EventsToDispatch)
+	cb.PrepareBatchPinOrNetworkAction(context.Background(), events, sub, fftypes.JSONAnyPtr("{}"), event, verifier, params)
+	err = cb.DispatchBlockchainEvents(context.Background(), events)
+	assert.NoError(t, err)
+
+	mcb.AssertExpectations(t)
+}
+
 func TestCallbackBatchPin(t *testing.T) {
 	event := &blockchain.Event{}
 	verifier := &core.VerifierRef{}
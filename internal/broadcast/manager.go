@@ -18,6 +18,7 @@ package broadcast
 
 import (
 	"context"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
@@ -48,8 +49,10 @@ type Manager interface {
 
 	NewBroadcast(in *core.MessageInOut) syncasync.Sender
 	BroadcastMessage(ctx context.Context, in *core.MessageInOut, waitConfirm bool) (out *core.Message, err error)
+	RequestReply(ctx context.Context, in *core.MessageInOut, timeout time.Duration) (reply *core.MessageInOut, err error)
 	PublishDataValue(ctx context.Context, id string, idempotencyKey core.IdempotencyKey) (*core.Data, error)
 	PublishDataBlob(ctx context.Context, id string, idempotencyKey core.IdempotencyKey) (*core.Data, error)
+	PinMessage(ctx context.Context, id string) (txID *fftypes.UUID, err error)
 	Start() error
 	WaitStop()
 
@@ -119,6 +122,7 @@ func NewBroadcastManager(ctx context.Context, ns *core.Namespace, di database.Pl
 		core.OpTypeSharedStorageUploadBatch,
 		core.OpTypeSharedStorageUploadBlob,
 		core.OpTypeSharedStorageUploadValue,
+		core.OpTypeBlockchainPinMessage,
 	})
 
 	return bm, nil
@@ -163,6 +167,13 @@ func (bm *broadcastManager) uploadBlobs(ctx context.Context, tx *fftypes.UUID, d
 	for _, d := range data {
 		// We only need to send a blob if there is one, and it's not been uploaded to the shared storage
 		if d.Blob != nil && d.Blob.Hash != nil && d.Blob.Public == "" {
+			deduplicated, err := bm.reusePublishedBlob(ctx, d)
+			if err != nil {
+				return err
+			}
+			if deduplicated {
+				continue
+			}
 			if err := bm.uploadDataBlob(ctx, tx, d, idempotentSubmit); err != nil {
 				return err
 			}
@@ -172,6 +183,28 @@ func (bm *broadcastManager) uploadBlobs(ctx context.Context, tx *fftypes.UUID, d
 	return nil
 }
 
+// reusePublishedBlob avoids uploading a second copy of a blob to shared storage, when identical
+// blob content has already been published for another data record in this namespace
+func (bm *broadcastManager) reusePublishedBlob(ctx context.Context, d *core.Data) (bool, error) {
+	fb := database.DataQueryFactory.NewFilter(ctx)
+	existing, _, err := bm.database.GetData(ctx, bm.namespace.Name, fb.And(
+		fb.Eq("blob.hash", d.Blob.Hash),
+		fb.Neq("blob.public", ""),
+		fb.Neq("id", d.ID),
+	).Limit(1))
+	if err != nil || len(existing) == 0 {
+		return false, err
+	}
+
+	d.Blob.Public = existing[0].Blob.Public
+	err = bm.database.UpdateData(ctx, bm.namespace.Name, d.ID, database.DataQueryFactory.NewUpdate(ctx).Set("blob.public", d.Blob.Public))
+	if err != nil {
+		return false, err
+	}
+	log.L(ctx).Infof("Reusing published blob with hash '%s' from data '%s' for data '%s': '%s'", d.Blob.Hash, existing[0].ID, d.ID, d.Blob.Public)
+	return true, nil
+}
+
 func (bm *broadcastManager) resolveData(ctx context.Context, id string) (*core.Data, error) {
 	u, err := fftypes.ParseUUID(ctx, id)
 	if err != nil {
@@ -308,6 +341,105 @@ func (bm *broadcastManager) PublishDataBlob(ctx context.Context, id string, idem
 	return d, nil
 }
 
+// PinMessage submits the hash of a confirmed message to the blockchain, to anchor its content, and returns the
+// ID of the pinning transaction. A message is only pinned once - pinning it again returns the existing transaction.
+func (bm *broadcastManager) PinMessage(ctx context.Context, id string) (txID *fftypes.UUID, err error) {
+	if bm.multiparty == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgActionNotSupported)
+	}
+
+	msgID, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := bm.database.GetMessageByID(ctx, bm.namespace.Name, msgID)
+	if err != nil {
+		return nil, err
+	} else if msg == nil {
+		return nil, i18n.NewError(ctx, coremsgs.Msg404NotFound)
+	} else if msg.State != core.MessageStateConfirmed {
+		return nil, i18n.NewError(ctx, coremsgs.MsgMessageNotConfirmed, msg.Header.ID)
+	}
+
+	// The idempotency key is derived from the message, so each message has at most one pinning transaction
+	txid, err := bm.txHelper.SubmitNewTransaction(ctx, core.TransactionTypeMessagePin, core.IdempotencyKey("pin_message:"+msgID.String()))
+	if err != nil {
+		idemErr, ok := err.(*sqlcommon.IdempotencyError)
+		if !ok {
+			return nil, err
+		}
+		// There might be an operation still in "Initialized" state that needs submitting to the blockchain
+		total, resubmitted, err := bm.operations.ResubmitOperations(ctx, idemErr.ExistingTXID)
+		switch {
+		case err != nil:
+			return nil, err
+		case total > 0 && len(resubmitted) == 0:
+			// If the last attempt failed we try again, rather than leaving the message unpinned for good
+			if err := bm.retryFailedMessagePin(ctx, idemErr.ExistingTXID); err != nil {
+				return nil, err
+			}
+			return idemErr.ExistingTXID, nil
+		case total > 0:
+			log.L(ctx).Infof("Message '%s' pin resubmitted in transaction '%s'", msgID, idemErr.ExistingTXID)
+			return idemErr.ExistingTXID, nil
+		}
+		// We didn't do anything last time - just start again
+		txid = idemErr.ExistingTXID
+	}
+
+	signingKey, err := bm.identity.ResolveInputSigningKey(ctx, "", identity.KeyNormalizationBlockchainPlugin)
+	if err != nil {
+		return nil, err
+	}
+	op := core.NewOperation(
+		bm.blockchain,
+		bm.namespace.Name,
+		txid,
+		core.OpTypeBlockchainPinMessage)
+	addPinMessageInputs(op, msgID, signingKey)
+	if err := bm.operations.AddOrReuseOperation(ctx, op); err != nil {
+		return nil, err
+	}
+	if _, err := bm.operations.RunOperation(ctx, opPinMessage(op, msg, signingKey), true); err != nil {
+		return nil, err
+	}
+	// The message_pinned events are emitted once the blockchain connector confirms the transaction
+	return txid, nil
+}
+
+// retryFailedMessagePin retries the latest pinning operation of a transaction, if it failed
+func (bm *broadcastManager) retryFailedMessagePin(ctx context.Context, txid *fftypes.UUID) error {
+	fb := database.OperationQueryFactory.NewFilter(ctx)
+	ops, _, err := bm.database.GetOperations(ctx, bm.namespace.Name, fb.And(
+		fb.Eq("tx", txid),
+		fb.Eq("type", core.OpTypeBlockchainPinMessage),
+	))
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		if op.Retry == nil && op.Status == core.OpStatusFailed {
+			log.L(ctx).Infof("Retrying failed pin operation '%s' in transaction '%s'", op.ID, txid)
+			_, err := bm.operations.RetryOperation(ctx, op.ID)
+			return err
+		}
+	}
+	log.L(ctx).Infof("Message already pinned in transaction '%s'", txid)
+	return nil
+}
+
+func (bm *broadcastManager) emitMessagePinned(ctx context.Context, msg *core.Message, txid *fftypes.UUID) error {
+	// One event per topic, as with the other message events
+	for _, topic := range msg.Header.Topics {
+		event := core.NewEvent(core.EventTypeMessagePinned, bm.namespace.Name, msg.Header.ID, txid, topic)
+		event.Correlator = msg.Header.CID
+		if err := bm.database.InsertEvent(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (bm *broadcastManager) Start() error {
 	return nil
 }
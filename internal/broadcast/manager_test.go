@@ -28,6 +28,7 @@ import (
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/hyperledger/firefly/internal/identity"
 	"github.com/hyperledger/firefly/mocks/batchmocks"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
@@ -134,6 +135,7 @@ func TestBroadcastMessageGood(t *testing.T) {
 	}
 
 	mdm := bm.data.(*datamocks.Manager)
+	mdm.On("UpsertNewDataByHash", mock.Anything, mock.Anything).Return(nil)
 	mdm.On("WriteNewMessage", mock.Anything, newMsg).Return(nil)
 
 	broadcast := broadcastSender{
@@ -166,6 +168,9 @@ func TestBroadcastMessageBad(t *testing.T) {
 		},
 	}
 
+	mdm := bm.data.(*datamocks.Manager)
+	mdm.On("UpsertNewDataByHash", mock.Anything, newMsg).Return(nil)
+
 	broadcast := broadcastSender{
 		mgr: bm,
 		msg: newMsg,
@@ -175,6 +180,32 @@ func TestBroadcastMessageBad(t *testing.T) {
 
 }
 
+func TestBroadcastMessageUpsertDataFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	newMsg := &data.NewMessage{
+		Message: &core.MessageInOut{
+			Message: core.Message{
+				Header: core.MessageHeader{
+					ID: fftypes.NewUUID(),
+				},
+			},
+		},
+	}
+
+	mdm := bm.data.(*datamocks.Manager)
+	mdm.On("UpsertNewDataByHash", mock.Anything, newMsg).Return(fmt.Errorf("pop"))
+
+	broadcast := broadcastSender{
+		mgr: bm,
+		msg: newMsg,
+	}
+	err := broadcast.sendInternal(context.Background(), methodSend)
+	assert.EqualError(t, err, "pop")
+	mdm.AssertNotCalled(t, "WriteNewMessage", mock.Anything, mock.Anything)
+}
+
 func TestDispatchBatchBlobsFail(t *testing.T) {
 	bm, cancel := newTestBroadcast(t)
 	defer cancel()
@@ -193,6 +224,7 @@ func TestDispatchBatchBlobsFail(t *testing.T) {
 	mom.On("AddOrReuseOperation", mock.Anything, mock.Anything).Return(nil)
 
 	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetData", mock.Anything, bm.namespace.Name, mock.Anything).Return(core.DataArray{}, nil, nil)
 	mdi.On("GetBlobs", mock.Anything, bm.namespace.Name, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
 
 	err := bm.dispatchBatch(bm.ctx, state)
@@ -491,6 +523,7 @@ func TestUploadBlobsGetBlobFail(t *testing.T) {
 	bm, cancel := newTestBroadcast(t)
 	defer cancel()
 	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetData", mock.Anything, bm.namespace.Name, mock.Anything).Return(core.DataArray{}, nil, nil)
 
 	blob := &core.Blob{
 		Hash:       fftypes.NewRandB32(),
@@ -523,6 +556,7 @@ func TestUploadBlobsGetBlobNotFound(t *testing.T) {
 	bm, cancel := newTestBroadcast(t)
 	defer cancel()
 	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetData", mock.Anything, bm.namespace.Name, mock.Anything).Return(core.DataArray{}, nil, nil)
 
 	blob := &core.Blob{
 		Hash:       fftypes.NewRandB32(),
@@ -554,6 +588,8 @@ func TestUploadBlobsGetBlobNotFound(t *testing.T) {
 func TestUploadBlobsGetBlobInsertOpFail(t *testing.T) {
 	bm, cancel := newTestBroadcast(t)
 	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetData", mock.Anything, bm.namespace.Name, mock.Anything).Return(core.DataArray{}, nil, nil)
 
 	blob := &core.Blob{
 		Hash:       fftypes.NewRandB32(),
@@ -579,6 +615,82 @@ func TestUploadBlobsGetBlobInsertOpFail(t *testing.T) {
 
 }
 
+func TestUploadBlobsReusePublished(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+
+	blobHash := fftypes.NewRandB32()
+	dataID := fftypes.NewUUID()
+	existingID := fftypes.NewUUID()
+
+	ctx := context.Background()
+	mdi.On("GetData", ctx, bm.namespace.Name, mock.Anything).Return(core.DataArray{
+		{ID: existingID, Blob: &core.BlobRef{Hash: blobHash, Public: "public-ref"}},
+	}, nil, nil)
+	mdi.On("UpdateData", ctx, bm.namespace.Name, dataID, mock.Anything).Return(nil)
+
+	data := core.DataArray{
+		{
+			ID: dataID,
+			Blob: &core.BlobRef{
+				Hash: blobHash,
+			},
+		},
+	}
+	err := bm.uploadBlobs(ctx, fftypes.NewUUID(), data, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "public-ref", data[0].Blob.Public)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestUploadBlobsReusePublishedQueryFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+
+	ctx := context.Background()
+	mdi.On("GetData", ctx, bm.namespace.Name, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := bm.uploadBlobs(ctx, fftypes.NewUUID(), core.DataArray{
+		{
+			ID: fftypes.NewUUID(),
+			Blob: &core.BlobRef{
+				Hash: fftypes.NewRandB32(),
+			},
+		},
+	}, false)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestUploadBlobsReusePublishedUpdateFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+
+	blobHash := fftypes.NewRandB32()
+	ctx := context.Background()
+	mdi.On("GetData", ctx, bm.namespace.Name, mock.Anything).Return(core.DataArray{
+		{ID: fftypes.NewUUID(), Blob: &core.BlobRef{Hash: blobHash, Public: "public-ref"}},
+	}, nil, nil)
+	mdi.On("UpdateData", ctx, bm.namespace.Name, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := bm.uploadBlobs(ctx, fftypes.NewUUID(), core.DataArray{
+		{
+			ID: fftypes.NewUUID(),
+			Blob: &core.BlobRef{
+				Hash: blobHash,
+			},
+		},
+	}, false)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
 func TestUploadValueNotFound(t *testing.T) {
 	bm, cancel := newTestBroadcast(t)
 	defer cancel()
@@ -942,3 +1054,316 @@ func TestUploadValueTXFail(t *testing.T) {
 	mps.AssertExpectations(t)
 
 }
+
+func newTestPinnableMessage() *core.Message {
+	return &core.Message{
+		Header: core.MessageHeader{
+			ID:     fftypes.NewUUID(),
+			CID:    fftypes.NewUUID(),
+			Topics: fftypes.FFStringArray{"topic1", "topic2"},
+		},
+		Hash:  fftypes.NewRandB32(),
+		State: core.MessageStateConfirmed,
+	}
+}
+
+func TestPinMessageOk(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+	mom := bm.operations.(*operationmocks.Manager)
+	mtx := bm.txHelper.(*txcommonmocks.Helper)
+
+	msg := newTestPinnableMessage()
+	txID := fftypes.NewUUID()
+	mdi.On("GetMessageByID", context.Background(), "ns1", msg.Header.ID).Return(msg, nil)
+	mtx.On("SubmitNewTransaction", context.Background(), core.TransactionTypeMessagePin, core.IdempotencyKey("pin_message:"+msg.Header.ID.String())).Return(txID, nil)
+	mim.On("ResolveInputSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	mom.On("AddOrReuseOperation", context.Background(), mock.MatchedBy(func(op *core.Operation) bool {
+		return op.Type == core.OpTypeBlockchainPinMessage && op.Transaction.Equals(txID) &&
+			op.Input.GetString("message") == msg.Header.ID.String() && op.Input.GetString("key") == "0x12345"
+	})).Return(nil)
+	mom.On("RunOperation", context.Background(), mock.MatchedBy(func(op *core.PreparedOperation) bool {
+		data := op.Data.(pinMessageData)
+		return op.Type == core.OpTypeBlockchainPinMessage && data.Message == msg && data.Transaction.Equals(txID) && data.Key == "0x12345"
+	}), true).Return(nil, nil)
+
+	pinTX, err := bm.PinMessage(context.Background(), msg.Header.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, txID, pinTX)
+
+	// Events are not emitted until the transaction is confirmed
+	mdi.AssertNotCalled(t, "InsertEvent", mock.Anything, mock.Anything)
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mom.AssertExpectations(t)
+	mtx.AssertExpectations(t)
+}
+
+func TestPinMessageNotMultiparty(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	bm.multiparty = nil
+
+	_, err := bm.PinMessage(context.Background(), fftypes.NewUUID().String())
+	assert.Regexp(t, "FF10414", err)
+}
+
+func TestPinMessageBadID(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	_, err := bm.PinMessage(context.Background(), "bad")
+	assert.Regexp(t, "FF00138", err)
+}
+
+func TestPinMessageGetMessageFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetMessageByID", context.Background(), "ns1", mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := bm.PinMessage(context.Background(), fftypes.NewUUID().String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPinMessageNotFound(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetMessageByID", context.Background(), "ns1", mock.Anything).Return(nil, nil)
+
+	_, err := bm.PinMessage(context.Background(), fftypes.NewUUID().String())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestPinMessageNotConfirmed(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+
+	msg := newTestPinnableMessage()
+	msg.State = core.MessageStatePending
+	mdi.On("GetMessageByID", context.Background(), "ns1", msg.Header.ID).Return(msg, nil)
+
+	_, err := bm.PinMessage(context.Background(), msg.Header.ID.String())
+	assert.Regexp(t, "FF10492", err)
+}
+
+func TestPinMessageSubmitTransactionFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mtx := bm.txHelper.(*txcommonmocks.Helper)
+
+	msg := newTestPinnableMessage()
+	mdi.On("GetMessageByID", context.Background(), "ns1", msg.Header.ID).Return(msg, nil)
+	mtx.On("SubmitNewTransaction", context.Background(), core.TransactionTypeMessagePin, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := bm.PinMessage(context.Background(), msg.Header.ID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPinMessageAlreadyPinned(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mom := bm.operations.(*operationmocks.Manager)
+	mtx := bm.txHelper.(*txcommonmocks.Helper)
+
+	msg := newTestPinnableMessage()
+	txID := fftypes.NewUUID()
+	mdi.On("GetMessageByID", context.Background(), "ns1", msg.Header.ID).Return(msg, nil)
+	mtx.On("SubmitNewTransaction", context.Background(), core.TransactionTypeMessagePin, mock.Anything).Return(nil, &sqlcommon.IdempotencyError{
+		ExistingTXID:  txID,
+		OriginalError: i18n.NewError(context.Background(), coremsgs.MsgIdempotencyKeyDuplicateTransaction, "pin", txID)})
+	mom.On("ResubmitOperations", context.Background(), txID).Return(1, nil, nil)
+	mdi.On("GetOperations", context.Background(), "ns1", mock.Anything).Return([]*core.Operation{
+		{ID: fftypes.NewUUID(), Status: core.OpStatusFailed, Retry: fftypes.NewUUID()},
+		{ID: fftypes.NewUUID(), Status: core.OpStatusSucceeded},
+	}, nil, nil)
+
+	pinTX, err := bm.PinMessage(context.Background(), msg.Header.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, txID, pinTX)
+
+	mdi.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestPinMessageRetryFailed(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mom := bm.operations.(*operationmocks.Manager)
+	mtx := bm.txHelper.(*txcommonmocks.Helper)
+
+	msg := newTestPinnableMessage()
+	txID := fftypes.NewUUID()
+	failedOp := &core.Operation{ID: fftypes.NewUUID(), Status: core.OpStatusFailed}
+	mdi.On("GetMessageByID", context.Background(), "ns1", msg.Header.ID).Return(msg, nil)
+	mtx.On("SubmitNewTransaction", context.Background(), core.TransactionTypeMessagePin, mock.Anything).Return(nil, &sqlcommon.IdempotencyError{
+		ExistingTXID:  txID,
+		OriginalError: i18n.NewError(context.Background(), coremsgs.MsgIdempotencyKeyDuplicateTransaction, "pin", txID)})
+	mom.On("ResubmitOperations", context.Background(), txID).Return(1, nil, nil)
+	mdi.On("GetOperations", context.Background(), "ns1", mock.Anything).Return([]*core.Operation{failedOp}, nil, nil)
+	mom.On("RetryOperation", context.Background(), failedOp.ID).Return(&core.Operation{ID: fftypes.NewUUID()}, nil)
+
+	pinTX, err := bm.PinMessage(context.Background(), msg.Header.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, txID, pinTX)
+
+	mdi.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestPinMessageRetryFailedGetOperationsFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mom := bm.operations.(*operationmocks.Manager)
+	mtx := bm.txHelper.(*txcommonmocks.Helper)
+
+	msg := newTestPinnableMessage()
+	txID := fftypes.NewUUID()
+	mdi.On("GetMessageByID", context.Background(), "ns1", msg.Header.ID).Return(msg, nil)
+	mtx.On("SubmitNewTransaction", context.Background(), core.TransactionTypeMessagePin, mock.Anything).Return(nil, &sqlcommon.IdempotencyError{
+		ExistingTXID:  txID,
+		OriginalError: i18n.NewError(context.Background(), coremsgs.MsgIdempotencyKeyDuplicateTransaction, "pin", txID)})
+	mom.On("ResubmitOperations", context.Background(), txID).Return(1, nil, nil)
+	mdi.On("GetOperations", context.Background(), "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := bm.PinMessage(context.Background(), msg.Header.ID.String())
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestPinMessageAlreadyPinnedResubmitted(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mom := bm.operations.(*operationmocks.Manager)
+	mtx := bm.txHelper.(*txcommonmocks.Helper)
+
+	msg := newTestPinnableMessage()
+	txID := fftypes.NewUUID()
+	mdi.On("GetMessageByID", context.Background(), "ns1", msg.Header.ID).Return(msg, nil)
+	mtx.On("SubmitNewTransaction", context.Background(), core.TransactionTypeMessagePin, mock.Anything).Return(nil, &sqlcommon.IdempotencyError{
+		ExistingTXID:  txID,
+		OriginalError: i18n.NewError(context.Background(), coremsgs.MsgIdempotencyKeyDuplicateTransaction, "pin", txID)})
+	mom.On("ResubmitOperations", context.Background(), txID).Return(1, []*core.Operation{{ID: fftypes.NewUUID()}}, nil)
+
+	pinTX, err := bm.PinMessage(context.Background(), msg.Header.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, txID, pinTX)
+
+	mom.AssertExpectations(t)
+}
+
+func TestPinMessageResubmitFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mom := bm.operations.(*operationmocks.Manager)
+	mtx := bm.txHelper.(*txcommonmocks.Helper)
+
+	msg := newTestPinnableMessage()
+	txID := fftypes.NewUUID()
+	mdi.On("GetMessageByID", context.Background(), "ns1", msg.Header.ID).Return(msg, nil)
+	mtx.On("SubmitNewTransaction", context.Background(), core.TransactionTypeMessagePin, mock.Anything).Return(nil, &sqlcommon.IdempotencyError{
+		ExistingTXID:  txID,
+		OriginalError: i18n.NewError(context.Background(), coremsgs.MsgIdempotencyKeyDuplicateTransaction, "pin", txID)})
+	mom.On("ResubmitOperations", context.Background(), txID).Return(0, nil, fmt.Errorf("pop"))
+
+	_, err := bm.PinMessage(context.Background(), msg.Header.ID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPinMessageResubmitWholeTX(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+	mom := bm.operations.(*operationmocks.Manager)
+	mtx := bm.txHelper.(*txcommonmocks.Helper)
+
+	msg := newTestPinnableMessage()
+	txID := fftypes.NewUUID()
+	mdi.On("GetMessageByID", context.Background(), "ns1", msg.Header.ID).Return(msg, nil)
+	mtx.On("SubmitNewTransaction", context.Background(), core.TransactionTypeMessagePin, mock.Anything).Return(nil, &sqlcommon.IdempotencyError{
+		ExistingTXID:  txID,
+		OriginalError: i18n.NewError(context.Background(), coremsgs.MsgIdempotencyKeyDuplicateTransaction, "pin", txID)})
+	mom.On("ResubmitOperations", context.Background(), txID).Return(0, nil, nil)
+	mim.On("ResolveInputSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	mom.On("AddOrReuseOperation", context.Background(), mock.MatchedBy(func(op *core.Operation) bool {
+		return op.Transaction.Equals(txID)
+	})).Return(nil)
+	mom.On("RunOperation", context.Background(), mock.Anything, true).Return(nil, nil)
+
+	pinTX, err := bm.PinMessage(context.Background(), msg.Header.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, txID, pinTX)
+
+	mdi.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestPinMessageResolveKeyFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+	mtx := bm.txHelper.(*txcommonmocks.Helper)
+
+	msg := newTestPinnableMessage()
+	mdi.On("GetMessageByID", context.Background(), "ns1", msg.Header.ID).Return(msg, nil)
+	mtx.On("SubmitNewTransaction", context.Background(), core.TransactionTypeMessagePin, mock.Anything).Return(fftypes.NewUUID(), nil)
+	mim.On("ResolveInputSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("", fmt.Errorf("pop"))
+
+	_, err := bm.PinMessage(context.Background(), msg.Header.ID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPinMessageAddOperationFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+	mom := bm.operations.(*operationmocks.Manager)
+	mtx := bm.txHelper.(*txcommonmocks.Helper)
+
+	msg := newTestPinnableMessage()
+	mdi.On("GetMessageByID", context.Background(), "ns1", msg.Header.ID).Return(msg, nil)
+	mtx.On("SubmitNewTransaction", context.Background(), core.TransactionTypeMessagePin, mock.Anything).Return(fftypes.NewUUID(), nil)
+	mim.On("ResolveInputSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	mom.On("AddOrReuseOperation", context.Background(), mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := bm.PinMessage(context.Background(), msg.Header.ID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPinMessageRunOperationFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+	mom := bm.operations.(*operationmocks.Manager)
+	mtx := bm.txHelper.(*txcommonmocks.Helper)
+
+	msg := newTestPinnableMessage()
+	mdi.On("GetMessageByID", context.Background(), "ns1", msg.Header.ID).Return(msg, nil)
+	mtx.On("SubmitNewTransaction", context.Background(), core.TransactionTypeMessagePin, mock.Anything).Return(fftypes.NewUUID(), nil)
+	mim.On("ResolveInputSigningKey", context.Background(), "", identity.KeyNormalizationBlockchainPlugin).Return("0x12345", nil)
+	mom.On("AddOrReuseOperation", context.Background(), mock.Anything).Return(nil)
+	mom.On("RunOperation", context.Background(), mock.Anything, true).Return(nil, fmt.Errorf("pop"))
+
+	_, err := bm.PinMessage(context.Background(), msg.Header.ID.String())
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertNotCalled(t, "InsertEvent", mock.Anything, mock.Anything)
+}
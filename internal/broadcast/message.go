@@ -18,6 +18,7 @@ package broadcast
 
 import (
 	"context"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
@@ -56,6 +57,15 @@ func (bm *broadcastManager) BroadcastMessage(ctx context.Context, in *core.Messa
 	return &in.Message, err
 }
 
+// RequestReply broadcasts the request, and blocks for up to the supplied timeout until
+// a message is confirmed that sets replyTo to the ID of the request
+func (bm *broadcastManager) RequestReply(ctx context.Context, in *core.MessageInOut, timeout time.Duration) (*core.MessageInOut, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	broadcast := bm.NewBroadcast(in)
+	return bm.syncasync.WaitForReplyTo(ctx, in.Header.ID, broadcast.Send)
+}
+
 type broadcastSender struct {
 	mgr      *broadcastManager
 	msg      *data.NewMessage
@@ -131,11 +141,33 @@ func (s *broadcastSender) resolve(ctx context.Context) error {
 		}
 	}
 
+	// Replies and conversations must reference a message that already exists in this namespace
+	if err := s.checkMessageExists(ctx, msg.Header.ReplyTo, coremsgs.MsgReplyToMessageNotFound); err != nil {
+		return err
+	}
+	if err := s.checkMessageExists(ctx, msg.Header.ConversationID, coremsgs.MsgConversationMessageNotFound); err != nil {
+		return err
+	}
+
 	// The data manager is responsible for the heavy lifting of storing/validating all our in-line data elements
 	err := s.mgr.data.ResolveInlineData(ctx, s.msg)
 	return err
 }
 
+func (s *broadcastSender) checkMessageExists(ctx context.Context, id *fftypes.UUID, notFoundKey i18n.ErrorMessageKey) error {
+	if id == nil {
+		return nil
+	}
+	ref, err := s.mgr.database.GetMessageRef(ctx, s.mgr.namespace.Name, id)
+	if err != nil {
+		return err
+	}
+	if ref == nil {
+		return i18n.NewError(ctx, notFoundKey, id)
+	}
+	return nil
+}
+
 func (s *broadcastSender) sendInternal(ctx context.Context, method sendMethod) (err error) {
 	if method == methodSendAndWait {
 		out, err := s.mgr.syncasync.WaitForMessage(ctx, s.msg.Message.Header.ID, s.Send)
@@ -145,8 +177,15 @@ func (s *broadcastSender) sendInternal(ctx context.Context, method sendMethod) (
 		return err
 	}
 
-	// Seal the message
 	msg := s.msg.Message
+	if method != methodPrepare {
+		// Broadcast data is public, so content that has already been stored is referenced rather than duplicated
+		if err := s.mgr.data.UpsertNewDataByHash(ctx, s.msg); err != nil {
+			return err
+		}
+	}
+
+	// Seal the message
 	if err := msg.Seal(ctx); err != nil {
 		return err
 	}
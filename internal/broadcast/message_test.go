@@ -20,10 +20,12 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/syncasync"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
 	"github.com/hyperledger/firefly/mocks/syncasyncmocks"
@@ -40,6 +42,7 @@ func TestBroadcastMessageOk(t *testing.T) {
 
 	ctx := context.Background()
 	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mdm.On("UpsertNewDataByHash", mock.Anything, mock.Anything).Return(nil)
 	mdm.On("WriteNewMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	mim.On("ResolveInputSigningIdentity", ctx, mock.Anything).Return(nil)
 
@@ -71,6 +74,7 @@ func TestBroadcastMessageWriteFail(t *testing.T) {
 
 	ctx := context.Background()
 	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mdm.On("UpsertNewDataByHash", mock.Anything, mock.Anything).Return(nil)
 	mdm.On("WriteNewMessage", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
 	mim.On("ResolveInputSigningIdentity", ctx, mock.Anything).Return(nil)
 
@@ -94,6 +98,114 @@ func TestBroadcastMessageWriteFail(t *testing.T) {
 	mdm.AssertExpectations(t)
 }
 
+func TestBroadcastMessageReplyToOk(t *testing.T) {
+	bm, cancel := newTestBroadcastWithMetrics(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdm := bm.data.(*datamocks.Manager)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	replyTo := fftypes.NewUUID()
+	mim.On("ResolveInputSigningIdentity", ctx, mock.Anything).Return(nil)
+	mdi.On("GetMessageRef", ctx, "ns1", replyTo).Return(&core.IDAndSequence{ID: *replyTo, Sequence: 1}, nil)
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mdm.On("UpsertNewDataByHash", mock.Anything, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	msg, err := bm.BroadcastMessage(ctx, &core.MessageInOut{
+		Message: core.Message{
+			Header: core.MessageHeader{
+				ReplyTo: replyTo,
+			},
+		},
+		InlineData: core.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"hello": "world"}`)},
+		},
+	}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, replyTo, msg.Header.ReplyTo)
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestBroadcastMessageReplyToNotFound(t *testing.T) {
+	bm, cancel := newTestBroadcastWithMetrics(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	replyTo := fftypes.NewUUID()
+	mim.On("ResolveInputSigningIdentity", ctx, mock.Anything).Return(nil)
+	mdi.On("GetMessageRef", ctx, "ns1", replyTo).Return(nil, nil)
+
+	_, err := bm.BroadcastMessage(ctx, &core.MessageInOut{
+		Message: core.Message{
+			Header: core.MessageHeader{
+				ReplyTo: replyTo,
+			},
+		},
+	}, false)
+	assert.Regexp(t, "FF10481", err)
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestBroadcastMessageConversationNotFound(t *testing.T) {
+	bm, cancel := newTestBroadcastWithMetrics(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	replyTo := fftypes.NewUUID()
+	conversationID := fftypes.NewUUID()
+	mim.On("ResolveInputSigningIdentity", ctx, mock.Anything).Return(nil)
+	mdi.On("GetMessageRef", ctx, "ns1", replyTo).Return(&core.IDAndSequence{ID: *replyTo, Sequence: 1}, nil)
+	mdi.On("GetMessageRef", ctx, "ns1", conversationID).Return(nil, nil)
+
+	_, err := bm.BroadcastMessage(ctx, &core.MessageInOut{
+		Message: core.Message{
+			Header: core.MessageHeader{
+				ReplyTo:        replyTo,
+				ConversationID: conversationID,
+			},
+		},
+	}, false)
+	assert.Regexp(t, "FF10482", err)
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestBroadcastMessageReplyToLookupFail(t *testing.T) {
+	bm, cancel := newTestBroadcastWithMetrics(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	replyTo := fftypes.NewUUID()
+	mim.On("ResolveInputSigningIdentity", ctx, mock.Anything).Return(nil)
+	mdi.On("GetMessageRef", ctx, "ns1", replyTo).Return(nil, fmt.Errorf("pop"))
+
+	_, err := bm.BroadcastMessage(ctx, &core.MessageInOut{
+		Message: core.Message{
+			Header: core.MessageHeader{
+				ReplyTo: replyTo,
+			},
+		},
+	}, false)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
 func TestBroadcastMessageWaitConfirmOk(t *testing.T) {
 	bm, cancel := newTestBroadcast(t)
 	defer cancel()
@@ -117,6 +229,7 @@ func TestBroadcastMessageWaitConfirmOk(t *testing.T) {
 			send(ctx)
 		}).
 		Return(replyMsg, nil)
+	mdm.On("UpsertNewDataByHash", mock.Anything, mock.Anything).Return(nil)
 	mdm.On("WriteNewMessage", ctx, mock.Anything, mock.Anything).Return(nil)
 
 	msg, err := bm.BroadcastMessage(ctx, &core.MessageInOut{
@@ -140,6 +253,66 @@ func TestBroadcastMessageWaitConfirmOk(t *testing.T) {
 	mdm.AssertExpectations(t)
 }
 
+func TestBroadcastRequestReplyOk(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	msa := bm.syncasync.(*syncasyncmocks.Bridge)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", mock.Anything, mock.Anything).Return(nil)
+	mdm.On("UpsertNewDataByHash", mock.Anything, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessage", mock.Anything, mock.Anything).Return(nil)
+	mim.On("ResolveInputSigningIdentity", mock.Anything, mock.Anything).Return(nil)
+
+	request := &core.MessageInOut{
+		InlineData: core.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"hello": "world"}`)},
+		},
+	}
+	reply := &core.MessageInOut{
+		Message: core.Message{
+			Header: core.MessageHeader{
+				ID: fftypes.NewUUID(),
+			},
+		},
+	}
+	msa.On("WaitForReplyTo", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			waitCtx := args[0].(context.Context)
+			_, hasDeadline := waitCtx.Deadline()
+			assert.True(t, hasDeadline)
+			assert.Equal(t, request.Header.ID, args[1])
+			send := args[2].(syncasync.SendFunction)
+			send(waitCtx)
+		}).
+		Return(reply, nil)
+
+	out, err := bm.RequestReply(ctx, request, 1*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, reply, out)
+	assert.Equal(t, core.MessageTypeBroadcast, request.Header.Type)
+
+	msa.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestBroadcastRequestReplyTimeout(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	msa := bm.syncasync.(*syncasyncmocks.Bridge)
+
+	msa.On("WaitForReplyTo", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("FF10260"))
+
+	_, err := bm.RequestReply(context.Background(), &core.MessageInOut{}, 1*time.Millisecond)
+	assert.Regexp(t, "FF10260", err)
+
+	msa.AssertExpectations(t)
+}
+
 func TestBroadcastMessageTooLarge(t *testing.T) {
 	bm, cancel := newTestBroadcast(t)
 	bm.maxBatchPayloadLength = 1000000
@@ -25,6 +25,7 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
 )
@@ -42,6 +43,12 @@ type uploadValue struct {
 	Data *core.Data `json:"data"`
 }
 
+type pinMessageData struct {
+	Transaction *fftypes.UUID `json:"transaction"`
+	Message     *core.Message `json:"message"`
+	Key         string        `json:"key"`
+}
+
 func addUploadBatchInputs(op *core.Operation, batchID *fftypes.UUID) {
 	op.Input = fftypes.JSONObject{
 		"id": batchID.String(),
@@ -66,6 +73,13 @@ func addUploadValueInputs(op *core.Operation, dataID *fftypes.UUID) {
 	}
 }
 
+func addPinMessageInputs(op *core.Operation, msgID *fftypes.UUID, signingKey string) {
+	op.Input = fftypes.JSONObject{
+		"message": msgID.String(),
+		"key":     signingKey,
+	}
+}
+
 func getUploadBlobOutputs(payloadRef string) fftypes.JSONObject {
 	return fftypes.JSONObject{
 		"payloadRef": payloadRef,
@@ -84,6 +98,14 @@ func retrieveUploadValueInputs(ctx context.Context, op *core.Operation) (*fftype
 	return fftypes.ParseUUID(ctx, op.Input.GetString("dataId"))
 }
 
+func retrievePinMessageInputs(ctx context.Context, op *core.Operation) (msgID *fftypes.UUID, signingKey string, err error) {
+	msgID, err = fftypes.ParseUUID(ctx, op.Input.GetString("message"))
+	if err != nil {
+		return nil, "", err
+	}
+	return msgID, op.Input.GetString("key"), nil
+}
+
 func (bm *broadcastManager) PrepareOperation(ctx context.Context, op *core.Operation) (*core.PreparedOperation, error) {
 	switch op.Type {
 	case core.OpTypeSharedStorageUploadBatch:
@@ -135,6 +157,20 @@ func (bm *broadcastManager) PrepareOperation(ctx context.Context, op *core.Opera
 			return nil, i18n.NewError(ctx, coremsgs.Msg404NotFound)
 		}
 		return opUploadValue(op, d), nil
+
+	case core.OpTypeBlockchainPinMessage:
+		msgID, signingKey, err := retrievePinMessageInputs(ctx, op)
+		if err != nil {
+			return nil, err
+		}
+		msg, err := bm.database.GetMessageByID(ctx, bm.namespace.Name, msgID)
+		if err != nil {
+			return nil, err
+		} else if msg == nil {
+			return nil, i18n.NewError(ctx, coremsgs.Msg404NotFound)
+		}
+		return opPinMessage(op, msg, signingKey), nil
+
 	default:
 		return nil, i18n.NewError(ctx, coremsgs.MsgOperationNotSupported, op.Type)
 	}
@@ -148,6 +184,8 @@ func (bm *broadcastManager) RunOperation(ctx context.Context, op *core.PreparedO
 		return bm.uploadBlob(ctx, data)
 	case uploadValue:
 		return bm.uploadValue(ctx, data)
+	case pinMessageData:
+		return bm.submitMessagePin(ctx, op, data)
 	default:
 		return nil, core.OpPhaseInitializing, i18n.NewError(ctx, coremsgs.MsgOperationDataIncorrect, op.Data)
 	}
@@ -216,10 +254,122 @@ func (bm *broadcastManager) uploadValue(ctx context.Context, data uploadValue) (
 	return getUploadBlobOutputs(data.Data.Public), core.OpPhaseComplete, nil
 }
 
+// submitMessagePin submits the hash of a message to the active multiparty contract, in the form of a batch pin
+// with no contexts - so it anchors the message content on-chain, without sequencing anything. The payload
+// reference marks it as a message pin, so other members record the transaction rather than waiting for a batch.
+//
+// A pending receipt is recorded before submission, so the pin status of the message is available straight away,
+// and cannot overwrite an update that the connector sends before the submission returns.
+func (bm *broadcastManager) submitMessagePin(ctx context.Context, op *core.PreparedOperation, data pinMessageData) (outputs fftypes.JSONObject, phase core.OpPhase, err error) {
+	if err := bm.setPinMessageReceiptStatus(ctx, op, data.Message.Header.ID, core.TxStatusPending); err != nil {
+		return nil, core.OpPhaseInitializing, err
+	}
+	contract := bm.namespace.Contracts.Active
+	err = bm.blockchain.SubmitBatchPin(ctx, op.NamespacedIDString(), bm.namespace.NetworkName, data.Key, &blockchain.BatchPin{
+		TransactionID:   data.Transaction,
+		TransactionType: core.TransactionTypeMessagePin,
+		BatchID:         data.Message.Header.ID,
+		BatchHash:       data.Message.Hash,
+		BatchPayloadRef: blockchain.MessagePinPayloadRef,
+	}, contract.Location)
+	if err != nil {
+		if receiptErr := bm.setPinMessageReceiptStatus(ctx, op, data.Message.Header.ID, core.TxStatusFailed); receiptErr != nil {
+			log.L(ctx).Errorf("Failed to record receipt for failed pin operation '%s': %s", op.ID, receiptErr)
+		}
+		return nil, core.OpPhaseInitializing, err
+	}
+	return nil, core.OpPhasePending, nil
+}
+
+// setPinMessageReceiptStatus sets the status of the receipt for a pin operation, creating it if required.
+// A receipt that is already confirmed is left unchanged.
+func (bm *broadcastManager) setPinMessageReceiptStatus(ctx context.Context, op *core.PreparedOperation, msgID *fftypes.UUID, status core.TxStatus) error {
+	receipt, err := bm.database.GetBlockchainReceiptByOpID(ctx, op.Namespace, op.ID)
+	if err != nil {
+		return err
+	}
+	if receipt == nil {
+		receipt = &core.BlockchainReceipt{
+			Namespace: op.Namespace,
+			Operation: op.ID,
+			MessageID: msgID,
+		}
+	} else if receipt.Status == core.TxStatusConfirmed {
+		return nil
+	}
+	receipt.Status = status
+	return bm.database.UpsertBlockchainReceipt(ctx, receipt)
+}
+
 func (bm *broadcastManager) OnOperationUpdate(ctx context.Context, op *core.Operation, update *core.OperationUpdate) error {
+	if op.Type == core.OpTypeBlockchainPinMessage {
+		return bm.onPinMessageUpdate(ctx, op, update)
+	}
 	return nil
 }
 
+// onPinMessageUpdate records the receipt for a message pinning transaction, and emits the message_pinned
+// events the first time the transaction is confirmed
+func (bm *broadcastManager) onPinMessageUpdate(ctx context.Context, op *core.Operation, update *core.OperationUpdate) error {
+	msgID, _, err := retrievePinMessageInputs(ctx, op)
+	if err != nil {
+		log.L(ctx).Warnf("Could not parse pinned message: %s (%+v)", err, op.Input)
+		return nil
+	}
+
+	// Receipts are keyed on the operation, so a repeated confirmation is ignored even if the
+	// connector has not reported a blockchain transaction ID
+	receipt, err := bm.database.GetBlockchainReceiptByOpID(ctx, op.Namespace, op.ID)
+	if err != nil {
+		return err
+	}
+	if receipt != nil && receipt.Status == core.TxStatusConfirmed {
+		log.L(ctx).Debugf("Pinning transaction for operation '%s' already confirmed", op.ID)
+		return nil
+	}
+	if receipt == nil {
+		receipt = &core.BlockchainReceipt{
+			Namespace: op.Namespace,
+			Operation: op.ID,
+			MessageID: msgID,
+		}
+	}
+	// Retain any details reported in an earlier update, that are not repeated in this one
+	if update.BlockchainTXID != "" {
+		receipt.TxID = update.BlockchainTXID
+	}
+	if blockNumber := update.Output.GetString("blockNumber"); blockNumber != "" {
+		receipt.BlockNumber = blockNumber
+	}
+	if blockHash := update.Output.GetString("blockHash"); blockHash != "" {
+		receipt.BlockHash = blockHash
+	}
+	switch update.Status {
+	case core.OpStatusSucceeded:
+		receipt.Status = core.TxStatusConfirmed
+		receipt.ConfirmedAt = fftypes.Now()
+	case core.OpStatusFailed:
+		receipt.Status = core.TxStatusFailed
+	default:
+		receipt.Status = core.TxStatusPending
+	}
+	if err := bm.database.UpsertBlockchainReceipt(ctx, receipt); err != nil {
+		return err
+	}
+	if receipt.Status != core.TxStatusConfirmed {
+		return nil
+	}
+
+	msg, err := bm.database.GetMessageByID(ctx, op.Namespace, msgID)
+	if err != nil {
+		return err
+	} else if msg == nil {
+		log.L(ctx).Warnf("Pinned message '%s' not found", msgID)
+		return nil
+	}
+	return bm.emitMessagePinned(ctx, msg, op.Transaction)
+}
+
 func opUploadBatch(op *core.Operation, batch *core.Batch) *core.PreparedOperation {
 	return &core.PreparedOperation{
 		ID:        op.ID,
@@ -240,6 +390,16 @@ func opUploadBlob(op *core.Operation, data *core.Data, blob *core.Blob) *core.Pr
 	}
 }
 
+func opPinMessage(op *core.Operation, msg *core.Message, signingKey string) *core.PreparedOperation {
+	return &core.PreparedOperation{
+		ID:        op.ID,
+		Namespace: op.Namespace,
+		Plugin:    op.Plugin,
+		Type:      op.Type,
+		Data:      pinMessageData{Transaction: op.Transaction, Message: msg, Key: signingKey},
+	}
+}
+
 func opUploadValue(op *core.Operation, data *core.Data) *core.PreparedOperation {
 	return &core.PreparedOperation{
 		ID:        op.ID,
@@ -24,10 +24,12 @@ import (
 
 	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -644,8 +646,435 @@ func TestRunOperationUploadBlobDownloadFail(t *testing.T) {
 	mdx.AssertExpectations(t)
 }
 
+func TestPrepareAndRunPinMessage(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	location := fftypes.JSONAnyPtr(`{"address":"0x123"}`)
+	bm.namespace.Contracts = &core.MultipartyContracts{
+		Active: &core.MultipartyContract{Location: location},
+	}
+
+	op := &core.Operation{
+		ID:          fftypes.NewUUID(),
+		Namespace:   "ns1",
+		Type:        core.OpTypeBlockchainPinMessage,
+		Transaction: fftypes.NewUUID(),
+	}
+	msg := &core.Message{
+		Header: core.MessageHeader{
+			ID: fftypes.NewUUID(),
+		},
+		Hash: fftypes.NewRandB32(),
+	}
+	addPinMessageInputs(op, msg.Header.ID, "0x12345")
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mbi := bm.blockchain.(*blockchainmocks.Plugin)
+
+	mdi.On("GetMessageByID", context.Background(), "ns1", msg.Header.ID).Return(msg, nil)
+	mdi.On("GetBlockchainReceiptByOpID", context.Background(), "ns1", op.ID).Return(nil, nil)
+	mdi.On("UpsertBlockchainReceipt", context.Background(), mock.MatchedBy(func(r *core.BlockchainReceipt) bool {
+		return r.Operation.Equals(op.ID) && r.MessageID.Equals(msg.Header.ID) && r.Status == core.TxStatusPending
+	})).Return(nil)
+	mbi.On("SubmitBatchPin", context.Background(), "ns1:"+op.ID.String(), "ns1", "0x12345", mock.MatchedBy(func(pin *blockchain.BatchPin) bool {
+		return pin.TransactionID.Equals(op.Transaction) && pin.BatchID.Equals(msg.Header.ID) &&
+			pin.BatchHash.Equals(msg.Hash) && len(pin.Contexts) == 0 &&
+			pin.TransactionType == core.TransactionTypeMessagePin && pin.BatchPayloadRef == blockchain.MessagePinPayloadRef
+	}), location).Return(nil)
+
+	po, err := bm.PrepareOperation(context.Background(), op)
+	assert.NoError(t, err)
+	assert.Equal(t, opPinMessage(op, msg, "0x12345"), po)
+
+	_, phase, err := bm.RunOperation(context.Background(), po)
+	assert.Equal(t, core.OpPhasePending, phase)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+}
+
+func TestRunOperationPinMessageFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	bm.namespace.Contracts = &core.MultipartyContracts{
+		Active: &core.MultipartyContract{},
+	}
+
+	op := &core.Operation{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	msg := &core.Message{
+		Header: core.MessageHeader{
+			ID: fftypes.NewUUID(),
+		},
+	}
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetBlockchainReceiptByOpID", context.Background(), "ns1", op.ID).Return(nil, nil).Once()
+	mdi.On("UpsertBlockchainReceipt", context.Background(), mock.MatchedBy(func(r *core.BlockchainReceipt) bool {
+		return r.Status == core.TxStatusPending
+	})).Return(nil).Once()
+	mdi.On("GetBlockchainReceiptByOpID", context.Background(), "ns1", op.ID).Return(&core.BlockchainReceipt{Status: core.TxStatusPending}, nil).Once()
+	mdi.On("UpsertBlockchainReceipt", context.Background(), mock.MatchedBy(func(r *core.BlockchainReceipt) bool {
+		return r.Status == core.TxStatusFailed
+	})).Return(fmt.Errorf("pop")).Once()
+	mbi := bm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("SubmitBatchPin", context.Background(), mock.Anything, "ns1", "0x12345", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, phase, err := bm.RunOperation(context.Background(), opPinMessage(op, msg, "0x12345"))
+	assert.Equal(t, core.OpPhaseInitializing, phase)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+}
+
+func TestRunOperationPinMessageReceiptFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	op := &core.Operation{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	msg := &core.Message{
+		Header: core.MessageHeader{
+			ID: fftypes.NewUUID(),
+		},
+	}
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetBlockchainReceiptByOpID", context.Background(), "ns1", op.ID).Return(nil, fmt.Errorf("pop"))
+
+	_, phase, err := bm.RunOperation(context.Background(), opPinMessage(op, msg, "0x12345"))
+	assert.Equal(t, core.OpPhaseInitializing, phase)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestRunOperationPinMessageAlreadyConfirmed(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	bm.namespace.Contracts = &core.MultipartyContracts{
+		Active: &core.MultipartyContract{},
+	}
+
+	op := &core.Operation{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	msg := &core.Message{
+		Header: core.MessageHeader{
+			ID: fftypes.NewUUID(),
+		},
+	}
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetBlockchainReceiptByOpID", context.Background(), "ns1", op.ID).Return(&core.BlockchainReceipt{Status: core.TxStatusConfirmed}, nil)
+	mbi := bm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("SubmitBatchPin", context.Background(), mock.Anything, "ns1", "0x12345", mock.Anything, mock.Anything).Return(nil)
+
+	_, phase, err := bm.RunOperation(context.Background(), opPinMessage(op, msg, "0x12345"))
+	assert.Equal(t, core.OpPhasePending, phase)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mdi.AssertNotCalled(t, "UpsertBlockchainReceipt", mock.Anything, mock.Anything)
+}
+
+func TestPreparePinMessageBadID(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	op := &core.Operation{
+		Type:  core.OpTypeBlockchainPinMessage,
+		Input: fftypes.JSONObject{"message": "bad"},
+	}
+
+	_, err := bm.PrepareOperation(context.Background(), op)
+	assert.Regexp(t, "FF00138", err)
+}
+
+func TestPreparePinMessageGetMessageFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	op := &core.Operation{
+		Type: core.OpTypeBlockchainPinMessage,
+	}
+	msgID := fftypes.NewUUID()
+	addPinMessageInputs(op, msgID, "0x12345")
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", context.Background(), "ns1", msgID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := bm.PrepareOperation(context.Background(), op)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestPreparePinMessageNotFound(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	op := &core.Operation{
+		Type: core.OpTypeBlockchainPinMessage,
+	}
+	msgID := fftypes.NewUUID()
+	addPinMessageInputs(op, msgID, "0x12345")
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", context.Background(), "ns1", msgID).Return(nil, nil)
+
+	_, err := bm.PrepareOperation(context.Background(), op)
+	assert.Regexp(t, "FF10109", err)
+
+	mdi.AssertExpectations(t)
+}
+
 func TestOperationUpdate(t *testing.T) {
 	bm, cancel := newTestBroadcast(t)
 	defer cancel()
-	assert.NoError(t, bm.OnOperationUpdate(context.Background(), nil, nil))
+	op := &core.Operation{Type: core.OpTypeSharedStorageUploadBatch}
+	assert.NoError(t, bm.OnOperationUpdate(context.Background(), op, nil))
+}
+
+func newTestPinMessageOp(msgID *fftypes.UUID) *core.Operation {
+	op := &core.Operation{
+		ID:          fftypes.NewUUID(),
+		Namespace:   "ns1",
+		Type:        core.OpTypeBlockchainPinMessage,
+		Transaction: fftypes.NewUUID(),
+	}
+	addPinMessageInputs(op, msgID, "0x12345")
+	return op
+}
+
+func TestPinMessageUpdateConfirmed(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+
+	msg := newTestPinnableMessage()
+	op := newTestPinMessageOp(msg.Header.ID)
+	mdi.On("GetBlockchainReceiptByOpID", context.Background(), "ns1", op.ID).Return(nil, nil)
+	mdi.On("UpsertBlockchainReceipt", context.Background(), mock.MatchedBy(func(r *core.BlockchainReceipt) bool {
+		return r.Namespace == "ns1" && r.Operation.Equals(op.ID) && r.TxID == "0xabcd" && r.MessageID.Equals(msg.Header.ID) && r.BlockNumber == "12" && r.BlockHash == "0xef01" &&
+			r.Status == core.TxStatusConfirmed && r.ConfirmedAt != nil
+	})).Return(nil)
+	mdi.On("GetMessageByID", context.Background(), "ns1", msg.Header.ID).Return(msg, nil)
+	mdi.On("InsertEvent", context.Background(), mock.MatchedBy(func(e *core.Event) bool {
+		return e.Type == core.EventTypeMessagePinned && e.Reference.Equals(msg.Header.ID) &&
+			e.Transaction.Equals(op.Transaction) && e.Correlator.Equals(msg.Header.CID)
+	})).Return(nil).Twice()
+
+	err := bm.OnOperationUpdate(context.Background(), op, &core.OperationUpdate{
+		Status:         core.OpStatusSucceeded,
+		BlockchainTXID: "0xabcd",
+		Output:         fftypes.JSONObject{"blockNumber": "12", "blockHash": "0xef01"},
+	})
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestPinMessageUpdatePending(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+
+	op := newTestPinMessageOp(fftypes.NewUUID())
+	mdi.On("GetBlockchainReceiptByOpID", context.Background(), "ns1", op.ID).Return(nil, nil)
+	mdi.On("UpsertBlockchainReceipt", context.Background(), mock.MatchedBy(func(r *core.BlockchainReceipt) bool {
+		return r.Status == core.TxStatusPending && r.ConfirmedAt == nil
+	})).Return(nil)
+
+	err := bm.OnOperationUpdate(context.Background(), op, &core.OperationUpdate{
+		Status:         core.OpStatusPending,
+		BlockchainTXID: "0xabcd",
+	})
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mdi.AssertNotCalled(t, "InsertEvent", mock.Anything, mock.Anything)
+}
+
+func TestPinMessageUpdateFailedNoTXID(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+
+	op := newTestPinMessageOp(fftypes.NewUUID())
+	mdi.On("GetBlockchainReceiptByOpID", context.Background(), "ns1", op.ID).Return(nil, nil)
+	mdi.On("UpsertBlockchainReceipt", context.Background(), mock.MatchedBy(func(r *core.BlockchainReceipt) bool {
+		return r.Operation.Equals(op.ID) && r.TxID == "" && r.Status == core.TxStatusFailed
+	})).Return(nil)
+
+	err := bm.OnOperationUpdate(context.Background(), op, &core.OperationUpdate{
+		Status:       core.OpStatusFailed,
+		ErrorMessage: "pop",
+	})
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestPinMessageUpdateAlreadyConfirmed(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+
+	op := newTestPinMessageOp(fftypes.NewUUID())
+	mdi.On("GetBlockchainReceiptByOpID", context.Background(), "ns1", op.ID).Return(&core.BlockchainReceipt{Status: core.TxStatusConfirmed}, nil)
+
+	// Deduplicated on the operation, even though no blockchain transaction ID is reported
+	err := bm.OnOperationUpdate(context.Background(), op, &core.OperationUpdate{
+		Status: core.OpStatusSucceeded,
+	})
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mdi.AssertNotCalled(t, "UpsertBlockchainReceipt", mock.Anything, mock.Anything)
+}
+
+func TestPinMessageUpdateRetainsEarlierDetails(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+
+	msg := newTestPinnableMessage()
+	op := newTestPinMessageOp(msg.Header.ID)
+	mdi.On("GetBlockchainReceiptByOpID", context.Background(), "ns1", op.ID).Return(&core.BlockchainReceipt{
+		Namespace: "ns1",
+		Operation: op.ID,
+		TxID:      "0xabcd",
+		MessageID: msg.Header.ID,
+		Status:    core.TxStatusPending,
+	}, nil)
+	mdi.On("UpsertBlockchainReceipt", context.Background(), mock.MatchedBy(func(r *core.BlockchainReceipt) bool {
+		return r.TxID == "0xabcd" && r.BlockNumber == "12" && r.Status == core.TxStatusConfirmed
+	})).Return(nil)
+	mdi.On("GetMessageByID", context.Background(), "ns1", msg.Header.ID).Return(msg, nil)
+	mdi.On("InsertEvent", context.Background(), mock.Anything).Return(nil).Twice()
+
+	err := bm.OnOperationUpdate(context.Background(), op, &core.OperationUpdate{
+		Status: core.OpStatusSucceeded,
+		Output: fftypes.JSONObject{"blockNumber": "12"},
+	})
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestPinMessageUpdateGetReceiptFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+
+	op := newTestPinMessageOp(fftypes.NewUUID())
+	mdi.On("GetBlockchainReceiptByOpID", context.Background(), "ns1", op.ID).Return(nil, fmt.Errorf("pop"))
+
+	err := bm.OnOperationUpdate(context.Background(), op, &core.OperationUpdate{
+		Status:         core.OpStatusSucceeded,
+		BlockchainTXID: "0xabcd",
+	})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestPinMessageUpdateUpsertReceiptFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+
+	op := newTestPinMessageOp(fftypes.NewUUID())
+	mdi.On("GetBlockchainReceiptByOpID", context.Background(), "ns1", op.ID).Return(nil, nil)
+	mdi.On("UpsertBlockchainReceipt", context.Background(), mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := bm.OnOperationUpdate(context.Background(), op, &core.OperationUpdate{
+		Status:         core.OpStatusSucceeded,
+		BlockchainTXID: "0xabcd",
+	})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestPinMessageUpdateBadInput(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+
+	op := newTestPinMessageOp(fftypes.NewUUID())
+	op.Input = fftypes.JSONObject{"message": "bad"}
+
+	err := bm.OnOperationUpdate(context.Background(), op, &core.OperationUpdate{
+		Status: core.OpStatusSucceeded,
+	})
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestPinMessageUpdateGetMessageFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+
+	msgID := fftypes.NewUUID()
+	op := newTestPinMessageOp(msgID)
+	mdi.On("GetBlockchainReceiptByOpID", context.Background(), "ns1", op.ID).Return(nil, nil)
+	mdi.On("UpsertBlockchainReceipt", context.Background(), mock.Anything).Return(nil)
+	mdi.On("GetMessageByID", context.Background(), "ns1", msgID).Return(nil, fmt.Errorf("pop"))
+
+	err := bm.OnOperationUpdate(context.Background(), op, &core.OperationUpdate{
+		Status: core.OpStatusSucceeded,
+	})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestPinMessageUpdateMessageNotFound(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+
+	msgID := fftypes.NewUUID()
+	op := newTestPinMessageOp(msgID)
+	mdi.On("GetBlockchainReceiptByOpID", context.Background(), "ns1", op.ID).Return(nil, nil)
+	mdi.On("UpsertBlockchainReceipt", context.Background(), mock.Anything).Return(nil)
+	mdi.On("GetMessageByID", context.Background(), "ns1", msgID).Return(nil, nil)
+
+	err := bm.OnOperationUpdate(context.Background(), op, &core.OperationUpdate{
+		Status: core.OpStatusSucceeded,
+	})
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestPinMessageUpdateInsertEventFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+
+	msg := newTestPinnableMessage()
+	op := newTestPinMessageOp(msg.Header.ID)
+	mdi.On("GetBlockchainReceiptByOpID", context.Background(), "ns1", op.ID).Return(nil, nil)
+	mdi.On("UpsertBlockchainReceipt", context.Background(), mock.Anything).Return(nil)
+	mdi.On("GetMessageByID", context.Background(), "ns1", msg.Header.ID).Return(msg, nil)
+	mdi.On("InsertEvent", context.Background(), mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := bm.OnOperationUpdate(context.Background(), op, &core.OperationUpdate{
+		Status: core.OpStatusSucceeded,
+	})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
 }
@@ -35,6 +35,8 @@ const (
 	NamespaceName = "name"
 	// NamespaceName is the long description for a pre-defined namespace
 	NamespaceDescription = "description"
+	// NamespaceParent is the name of the parent of a pre-defined namespace, when namespaces are organized in a hierarchy
+	NamespaceParent = "parent"
 	// NamespacePlugins is the list of namespace plugins
 	NamespacePlugins = "plugins"
 	// NamespaceTLSConfigName is the user-supplied name for the TLS Config
@@ -47,6 +49,12 @@ const (
 	NamespaceDefaultKey = "defaultKey"
 	// NamespaceAssetKeyNormalization mechanism to normalize keys before using them. Valid options: "blockchain_plugin" - use blockchain plugin (default), "none" - do not attempt normalization
 	NamespaceAssetKeyNormalization = "asset.manager.keyNormalization"
+	// NamespaceRBACEnabled enables enforcement of the per-namespace roles of authenticated principals
+	NamespaceRBACEnabled = "rbac.enabled"
+	// NamespaceEventAggregatorRateLimitBurst is the number of pins the aggregator of a namespace can process at once, before being limited to the sustained rate
+	NamespaceEventAggregatorRateLimitBurst = "event.aggregator.rateLimit.burst"
+	// NamespaceEventAggregatorRateLimitRate is the sustained number of pins per second the aggregator of a namespace can process. Zero disables rate limiting
+	NamespaceEventAggregatorRateLimitRate = "event.aggregator.rateLimit.rate"
 	// NamespaceMultiparty contains the multiparty configuration for a namespace
 	NamespaceMultiparty = "multiparty"
 	// NamespaceMultipartyEnabled specifies if multi-party mode is enabled for a namespace
@@ -63,6 +71,8 @@ const (
 	NamespaceMultipartyNodeName = "node.name"
 	// NamespaceMultipartyNodeName is a description for the local node within a namespace
 	NamespaceMultipartyNodeDescription = "node.description"
+	// NamespaceMultipartyAutoRegister registers the root org and local node on first startup of a namespace, if they are not already registered
+	NamespaceMultipartyAutoRegister = "autoRegister"
 	// NamespaceMultipartyContract is a list of firefly contract configurations for this namespace
 	NamespaceMultipartyContract = "contract"
 	// NamespaceMultipartyContractFirstEvent is the first event to process for this contract
@@ -203,6 +213,9 @@ var (
 	PrivateMessagingBatchPayloadLimit = ffc("privatemessaging.batch.payloadLimit")
 	// PrivateMessagingBatchTimeout is the timeout to wait for a batch to fill, before sending
 	PrivateMessagingBatchTimeout = ffc("privatemessaging.batch.timeout")
+	// PrivateMessagingDeliveryReceiptsEnabled whether to broadcast delivery receipts for private messages received by local group members.
+	// The receipts are visible to the whole network, revealing which identities received each private message
+	PrivateMessagingDeliveryReceiptsEnabled = ffc("privatemessaging.deliveryReceipts.enabled")
 	// PrivateMessagingRetryFactor the backoff factor to use for retry of database operations
 	PrivateMessagingRetryFactor = ffc("privatemessaging.retry.factor")
 	// PrivateMessagingRetryInitDelay the initial delay to use for retry of data base operations
@@ -253,14 +266,36 @@ var (
 	EventAggregatorRetryFactor = ffc("event.aggregator.retry.factor")
 	// EventAggregatorRetryInitDelay the initial delay to use for retry of data base operations
 	EventAggregatorRetryInitDelay = ffc("event.aggregator.retry.initDelay")
+	// EventAggregatorRetryJitter the fraction of each retry delay to add at random, so nodes sharing a database do not retry in lock-step
+	EventAggregatorRetryJitter = ffc("event.aggregator.retry.jitter")
 	// EventAggregatorRetryMaxDelay the maximum delay to use for retry of data base operations
 	EventAggregatorRetryMaxDelay = ffc("event.aggregator.retry.maxDelay")
+	// EventAggregatorMaxRetries the number of attempts at a page of pins before failing pins are recorded as dead events (0 retries indefinitely)
+	EventAggregatorMaxRetries = ffc("event.aggregator.maxRetries")
+	// EventAggregatorOrderingStrategy whether the aggregator offset is shared by all namespaces ("global"), or stored per namespace ("namespace")
+	EventAggregatorOrderingStrategy = ffc("event.aggregator.orderingStrategy")
+	// EventAggregatorResetOnSchemaMismatch whether to reset a stored offset written at a different schema version to the firstEvent, rather than failing to start
+	EventAggregatorResetOnSchemaMismatch = ffc("event.aggregator.resetOnSchemaMismatch")
+	// EventAggregatorGapDetectionEnabled whether to check each page of pins for gaps in the sequence, which could be pins from transactions that are yet to commit
+	EventAggregatorGapDetectionEnabled = ffc("event.aggregator.gapDetection.enabled")
+	// EventAggregatorGapStallTimeout how long to hold off processing pins after a gap in the sequence, to give late pins a chance to appear
+	EventAggregatorGapStallTimeout = ffc("event.aggregator.gapDetection.stallTimeout")
+	// EventAggregatorTTLScanInterval how often to scan for unconfirmed messages that have passed their TTL, and expire them
+	EventAggregatorTTLScanInterval = ffc("event.aggregator.ttlScanInterval")
+	// EventArchiveRetention how long events are kept in the events table before being moved to the archive table - zero disables archiving
+	EventArchiveRetention = ffc("event.archive.retention")
+	// EventArchiveInterval how often to check for events that have passed the retention period
+	EventArchiveInterval = ffc("event.archive.interval")
+	// EventArchiveBatchSize the maximum number of events to move to the archive table in a single database transaction
+	EventArchiveBatchSize = ffc("event.archive.batchSize")
 	// EventDispatcherPollTimeout the time to wait without a notification of new events, before trying a select on the table
 	EventDispatcherPollTimeout = ffc("event.dispatcher.pollTimeout")
 	// EventDispatcherBufferLength the number of events + attachments an individual dispatcher should hold in memory ready for delivery to the subscription
 	EventDispatcherBufferLength = ffc("event.dispatcher.bufferLength")
 	// EventDispatcherBatchTimeout a short time to wait for new events to arrive before re-polling for new events
 	EventDispatcherBatchTimeout = ffc("event.dispatcher.batchTimeout")
+	// EventDispatcherBatchTimeoutByType shorter batch timeouts for individual event types, so a batch containing them is flushed early
+	EventDispatcherBatchTimeoutByType = ffc("event.dispatcher.batchTimeoutByType")
 	// EventDispatcherRetryFactor the backoff factor to use for retry of database operations
 	EventDispatcherRetryFactor = ffc("event.dispatcher.retry.factor")
 	// EventDispatcherRetryInitDelay he initial delay to use for retry of data base operations
@@ -281,6 +316,26 @@ var (
 	SPIWebSocketReadBufferSize = ffc("spi.ws.readBufferSize")
 	// SPIWebSocketWriteBufferSize is the WebSocket write buffer size for the admin change-event WebSocket
 	SPIWebSocketWriteBufferSize = ffc("spi.ws.writeBufferSize")
+	// MessageExternalDataAllowedHosts is the list of hosts that external data references can be fetched from
+	MessageExternalDataAllowedHosts = ffc("message.externalData.allowedHosts")
+	// MessageExternalDataMaxSize is the maximum size of content that will be fetched for an external data reference
+	MessageExternalDataMaxSize = ffc("message.externalData.maxSize")
+	// MessageExternalDataMaxResults is the maximum number of fetch results held in memory for messages that are not yet confirmed
+	MessageExternalDataMaxResults = ffc("message.externalData.maxResults")
+	// MessageExternalDataRequestTimeout is the timeout when fetching the content of external data references
+	MessageExternalDataRequestTimeout = ffc("message.externalData.requestTimeout")
+	// MessageExternalDataWorkerCount is the number of workers fetching external data references in the background
+	MessageExternalDataWorkerCount = ffc("message.externalData.worker.count")
+	// MessageExternalDataWorkerQueueLength is the length of the work queue in the channel to the workers
+	MessageExternalDataWorkerQueueLength = ffc("message.externalData.worker.queueLength")
+	// MessageExternalDataRetryMaxAttempts is the maximum number of attempts to fetch an external data reference each time it is requested
+	MessageExternalDataRetryMaxAttempts = ffc("message.externalData.retry.maxAttempts")
+	// MessageExternalDataRetryInitDelay is the initial retry delay
+	MessageExternalDataRetryInitDelay = ffc("message.externalData.retry.initialDelay")
+	// MessageExternalDataRetryMaxDelay is the maximum retry delay
+	MessageExternalDataRetryMaxDelay = ffc("message.externalData.retry.maxDelay")
+	// MessageExternalDataRetryFactor is the backoff factor to use for retries
+	MessageExternalDataRetryFactor = ffc("message.externalData.retry.factor")
 	// MessageWriterCount
 	MessageWriterCount = ffc("message.writer.count")
 	// MessageWriterBatchTimeout
@@ -331,6 +386,8 @@ var (
 	SubscriptionDefaultsBatchSize = ffc("subscription.defaults.batchSize")
 	// SubscriptionDefaultsBatchTimeout default batch timeout
 	SubscriptionDefaultsBatchTimeout = ffc("subscription.defaults.batchTimeout")
+	// SubscriptionDrainTimeout the maximum time to wait for in-flight deliveries to complete when deleting a subscription
+	SubscriptionDrainTimeout = ffc("subscription.drainTimeout")
 	// SubscriptionMax maximum number of pre-defined subscriptions that can exist (note for high fan-out consider connecting a dedicated pub/sub broker to the dispatcher)
 	SubscriptionMax = ffc("subscription.max")
 	// SubscriptionsRetryInitialDelay is the initial retry delay
@@ -415,7 +472,17 @@ func setDefaults() {
 	viper.SetDefault(string(EventAggregatorRewindQueryLimit), 1000)
 	viper.SetDefault(string(EventAggregatorRetryFactor), 2.0)
 	viper.SetDefault(string(EventAggregatorRetryInitDelay), "100ms")
+	viper.SetDefault(string(EventAggregatorRetryJitter), 0.0)
 	viper.SetDefault(string(EventAggregatorRetryMaxDelay), "30s")
+	viper.SetDefault(string(EventAggregatorMaxRetries), 0)
+	viper.SetDefault(string(EventAggregatorOrderingStrategy), "global")
+	viper.SetDefault(string(EventAggregatorResetOnSchemaMismatch), false)
+	viper.SetDefault(string(EventAggregatorTTLScanInterval), "1m")
+	viper.SetDefault(string(EventAggregatorGapDetectionEnabled), true)
+	viper.SetDefault(string(EventAggregatorGapStallTimeout), "0s")
+	viper.SetDefault(string(EventArchiveRetention), "0")
+	viper.SetDefault(string(EventArchiveInterval), "1h")
+	viper.SetDefault(string(EventArchiveBatchSize), 1000)
 	viper.SetDefault(string(EventDBEventsBufferSize), 100)
 	viper.SetDefault(string(EventDispatcherBufferLength), 5)
 	viper.SetDefault(string(EventDispatcherBatchTimeout), "0ms")
@@ -433,6 +500,16 @@ func setDefaults() {
 	viper.SetDefault(string(SPIWebSocketEventQueueLength), 250)
 	viper.SetDefault(string(CacheMessageSize), "50Mb")
 	viper.SetDefault(string(CacheMessageTTL), "5m")
+	viper.SetDefault(string(MessageExternalDataAllowedHosts), []string{})
+	viper.SetDefault(string(MessageExternalDataMaxSize), "10Mb")
+	viper.SetDefault(string(MessageExternalDataMaxResults), 1000)
+	viper.SetDefault(string(MessageExternalDataRequestTimeout), "30s")
+	viper.SetDefault(string(MessageExternalDataWorkerCount), 5)
+	viper.SetDefault(string(MessageExternalDataWorkerQueueLength), 1000)
+	viper.SetDefault(string(MessageExternalDataRetryMaxAttempts), 5)
+	viper.SetDefault(string(MessageExternalDataRetryInitDelay), "1s")
+	viper.SetDefault(string(MessageExternalDataRetryMaxDelay), "1m")
+	viper.SetDefault(string(MessageExternalDataRetryFactor), 2.0)
 	viper.SetDefault(string(MessageWriterBatchMaxInserts), 200)
 	viper.SetDefault(string(MessageWriterBatchTimeout), "10ms")
 	viper.SetDefault(string(MessageWriterCount), 5)
@@ -454,9 +531,11 @@ func setDefaults() {
 	viper.SetDefault(string(PrivateMessagingBatchAgentTimeout), "2m")
 	viper.SetDefault(string(PrivateMessagingBatchSize), 200)
 	viper.SetDefault(string(PrivateMessagingBatchTimeout), "1s")
+	viper.SetDefault(string(PrivateMessagingDeliveryReceiptsEnabled), false)
 	viper.SetDefault(string(PrivateMessagingBatchPayloadLimit), "800Kb")
 	viper.SetDefault(string(SubscriptionDefaultsBatchSize), 50)
 	viper.SetDefault(string(SubscriptionDefaultsBatchTimeout), "50ms")
+	viper.SetDefault(string(SubscriptionDrainTimeout), "10s")
 	viper.SetDefault(string(SubscriptionMax), 500)
 	viper.SetDefault(string(SubscriptionsRetryInitialDelay), "250ms")
 	viper.SetDefault(string(SubscriptionsRetryMaxDelay), "30s")
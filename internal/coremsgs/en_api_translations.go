@@ -28,6 +28,9 @@ var (
 	APIParamsContractListenerNameOrID       = ffm("api.params.contractListenerNameOrID", "The contract listener name or ID")
 	APIParamsContractListenerID             = ffm("api.params.contractListenerID", "The contract listener ID")
 	APIParamsSubscriptionID                 = ffm("api.params.subscriptionID", "The subscription ID")
+	APIParamsSubscriptionNamespace          = ffm("api.params.subscriptionNamespace", "Only list subscriptions in this namespace")
+	APIParamsSubscriptionTransport          = ffm("api.params.subscriptionTransport", "Only list subscriptions using this transport")
+	APIParamsSubscriptionDeliveryState      = ffm("api.params.subscriptionDeliveryState", "Only list subscriptions in this delivery state - 'active' or 'paused'")
 	APIParamsBatchID                        = ffm("api.params.batchId", "The batch ID")
 	APIParamsBlockchainEventID              = ffm("api.params.blockchainEventID", "The blockchain event ID")
 	APIParamsCollectionID                   = ffm("api.params.collectionID", "The collection ID")
@@ -38,10 +41,15 @@ var (
 	APIParamsContractInterfaceFetchChildren = ffm("api.params.contractInterfaceFetchChildren", "When set, the API will return the full FireFly Interface document including all methods, events, and parameters")
 	APIParamsNSIncludeInitializing          = ffm("api.params.nsIncludeInitializing", "When set, the API will return namespaces even if they are not yet initialized, including in error cases where an initializationError is included")
 	APIParamsBlobID                         = ffm("api.params.blobID", "The blob ID")
+	APIParamsConversationID                 = ffm("api.params.conversationID", "The conversation ID, which is the ID of the message that started the conversation")
 	APIParamsDataID                         = ffm("api.params.dataID", "The data item ID")
 	APIParamsDatatypeName                   = ffm("api.params.datatypeName", "The name of the datatype")
 	APIParamsDatatypeVersion                = ffm("api.params.datatypeVersion", "The version of the datatype")
 	APIParamsDataParentPath                 = ffm("api.params.dataParentPath", "The parent path to query")
+	APIParamsContextHash                    = ffm("api.params.contextHash", "The hash of the context, as found in the hash field of a pin")
+	APIParamsDeadEventID                    = ffm("api.params.deadEventID", "The dead event ID")
+	APIParamsReplayID                       = ffm("api.params.replayID", "The replay ID")
+	APIParamsPrincipal                      = ffm("api.params.principal", "The authenticated principal, as identified by the auth plugin of the namespace")
 	APIParamsEventID                        = ffm("api.params.eventID", "The event ID")
 	APIParamsFetchReferences                = ffm("api.params.fetchReferences", "When set, the API will return the record that this item references in its 'reference' field")
 	APIParamsFetchReference                 = ffm("api.params.fetchReference", "When set, the API will return the record that this item references in its 'reference' field")
@@ -67,14 +75,24 @@ var (
 	APIParamsContractAPIID                  = ffm("api.params.contractAPIID", "The ID of the contract API")
 	APIParamsFetchStatus                    = ffm("api.params.fetchStatus", "When set, the API will return additional status information if available")
 
-	APIEndpointsAdminGetNamespaceByName = ffm("api.endpoints.adminGetNamespaceByName", "Gets a namespace by name")
-	APIEndpointsAdminGetNamespaces      = ffm("api.endpoints.adminGetNamespaces", "List namespaces")
-	APIEndpointsAdminGetOpByID          = ffm("api.endpoints.adminGetOpByID", "Gets an operation by ID")
-	APIEndpointsAdminGetOps             = ffm("api.endpoints.adminGetOps", "Lists operations")
-	APIEndpointsAdminPostReset          = ffm("api.endpoints.adminPostResetConfig", "Restarts FireFly Core HTTP servers and apply all configuration updates")
-	APIEndpointsAdminPatchOpByID        = ffm("api.endpoints.adminPatchOpByID", "Updates an operation by ID")
-	APIEndpointsAdminGetListenerByID    = ffm("api.endpoints.adminGetListenerByID", "Gets a contract listener by ID")
-	APIEndpointsAdminGetListeners       = ffm("api.endpoints.adminGetListeners", "Lists contract listeners")
+	APIEndpointsAdminGetNamespaceByName   = ffm("api.endpoints.adminGetNamespaceByName", "Gets a namespace by name")
+	APIEndpointsAdminGetNamespaces        = ffm("api.endpoints.adminGetNamespaces", "List namespaces")
+	APIEndpointsAdminGetOpByID            = ffm("api.endpoints.adminGetOpByID", "Gets an operation by ID")
+	APIEndpointsAdminGetOps               = ffm("api.endpoints.adminGetOps", "Lists operations")
+	APIEndpointsAdminPostReset            = ffm("api.endpoints.adminPostResetConfig", "Restarts FireFly Core HTTP servers and apply all configuration updates")
+	APIEndpointsAdminPatchOpByID          = ffm("api.endpoints.adminPatchOpByID", "Updates an operation by ID")
+	APIEndpointsAdminDeleteEventByID      = ffm("api.endpoints.adminDeleteEventByID", "Deletes an event by ID, once it has been delivered to all durable subscriptions. Ephemeral subscriptions are not checked")
+	APIEndpointsAdminPostPurgeEvents      = ffm("api.endpoints.adminPostPurgeEvents", "Deletes all events before a sequence, once they have been delivered to all durable subscriptions. Ephemeral subscriptions are not checked")
+	APIEndpointsAdminPostAggregatorPause  = ffm("api.endpoints.adminPostAggregatorPause", "Pauses the processing of pins into events, once any page of pins in flight is complete")
+	APIEndpointsAdminPostAggregatorResume = ffm("api.endpoints.adminPostAggregatorResume", "Resumes the processing of pins into events, from the offset where it was paused")
+	APIEndpointsAdminPostAggregatorReplay = ffm("api.endpoints.adminPostAggregatorReplay", "Starts a replay that processes any undispatched pins in a range the aggregator has already passed, without moving the aggregator offset")
+	APIEndpointsAdminGetAggregatorReplay  = ffm("api.endpoints.adminGetAggregatorReplay", "Gets the progress of a replay of pins")
+	APIEndpointsAdminGetPermissions       = ffm("api.endpoints.adminGetPermissions", "Gets the roles granted to principals in the namespace")
+	APIEndpointsAdminPostPermission       = ffm("api.endpoints.adminPostPermission", "Grants a role in the namespace to a principal, replacing any role the principal already holds")
+	APIEndpointsAdminDeletePermission     = ffm("api.endpoints.adminDeletePermission", "Revokes the role of a principal in the namespace")
+	APIEndpointsAdminGetListenerByID      = ffm("api.endpoints.adminGetListenerByID", "Gets a contract listener by ID")
+	APIEndpointsAdminGetListeners         = ffm("api.endpoints.adminGetListeners", "Lists contract listeners")
+	APIEndpointsAdminGetSubscriptions     = ffm("api.endpoints.adminGetSubscriptions", "Lists subscriptions across namespaces, with their live delivery state on this node")
 
 	APIEndpointsDeleteContractAPI               = ffm("api.endpoints.deleteContractAPI", "Delete a contract API")
 	APIEndpointsDeleteContractInterface         = ffm("api.endpoints.deleteContractInterface", "Delete a contract interface")
@@ -93,15 +111,21 @@ var (
 	APIEndpointsGetContractInterfaces           = ffm("api.endpoints.getContractInterfaces", "Gets a list of contract interfaces that have been published")
 	APIEndpointsGetContractListenerByNameOrID   = ffm("api.endpoints.getContractListenerByNameOrID", "Gets a contract listener by its name or ID")
 	APIEndpointsGetContractListeners            = ffm("api.endpoints.getContractListeners", "Gets a list of contract listeners")
+	APIEndpointsGetConversationMsgs             = ffm("api.endpoints.getConversationMsgs", "Gets the message that started a conversation, and all messages in the conversation, ordered by sequence")
 	APIEndpointsGetDataBlob                     = ffm("api.endpoints.getDataBlob", "Downloads the original file that was previously uploaded or received")
 	APIEndpointsGetDataValue                    = ffm("api.endpoints.getDataValue", "Downloads the JSON value of the data resource, without the associated metadata")
 	APIEndpointsGetDataByID                     = ffm("api.endpoints.getDataByID", "Gets a data item by its ID, including metadata about this item")
 	APIEndpointsDeleteData                      = ffm("api.endpoints.deleteData", "Deletes a data item by its ID, including metadata about this item")
+	APIEndpointsDeleteMessage                   = ffm("api.endpoints.deleteMessage", "Deletes a rejected, cancelled or expired message, including its references to data")
+	APIEndpointsAdminDeleteContextBlocked       = ffm("api.endpoints.adminDeleteContextBlocked", "Skips the earliest undispatched pin on a broadcast context, so later messages on the context can be processed. The pin is recorded as a dead event, along with the caller, and a context_force_unblocked event is emitted")
+	APIEndpointsGetContextsBlocked              = ffm("api.endpoints.getContextsBlocked", "Gets a list of the contexts the event aggregator has passed over, because the earliest undispatched pin on the context is behind its offset. The message for that pin is included where it has been received")
+	APIEndpointsGetContextsBlockedStats         = ffm("api.endpoints.getContextsBlockedStats", "Gets the number of blocked contexts, how long the oldest has been blocked, and a histogram of how long they have been blocked")
 	APIEndpointsGetDataMsgs                     = ffm("api.endpoints.getDataMsgs", "Gets a list of the messages associated with a data item")
 	APIEndpointsGetData                         = ffm("api.endpoints.getData", "Gets a list of data items")
 	APIEndpointsGetDataSubPaths                 = ffm("api.endpoints.getDataSubPaths", "Gets a list of path names of named blob data, underneath a given parent path ('/' path prefixes are automatically pre-prepended)")
 	APIEndpointsGetDatatypeByName               = ffm("api.endpoints.getDatatypeByName", "Gets a datatype by its name and version")
 	APIEndpointsGetDatatypes                    = ffm("api.endpoints.getDatatypes", "Gets a list of datatypes that have been published")
+	APIEndpointsGetDeadEvents                   = ffm("api.endpoints.getDeadEvents", "Gets the pins the event aggregator gave up retrying, and recorded as dead events so processing could continue")
 	APIEndpointsGetEventByID                    = ffm("api.endpoints.eventID", "Gets an event by its ID")
 	APIEndpointsGetEvents                       = ffm("api.endpoints.getEvents", "Gets a list of events")
 	APIEndpointsGetGroupByHash                  = ffm("api.endpoints.getGroupByHash", "Gets a group by its ID (hash)")
@@ -112,10 +136,14 @@ var (
 	APIEndpointsGetIdentityVerifiers            = ffm("api.endpoints.getIdentityVerifiers", "Gets the verifiers for an identity")
 	APIEndpointsGetMsgByID                      = ffm("api.endpoints.getMsgByID", "Gets a message by its ID")
 	APIEndpointsGetMsgData                      = ffm("api.endpoints.getMsgData", "Gets the list of data items that are attached to a message")
+	APIEndpointsGetMsgDataBlob                  = ffm("api.endpoints.getMsgDataBlob", "Downloads the blob of a data item attached to a message, with the Content-Type of the message")
 	APIEndpointsGetMsgEvents                    = ffm("api.endpoints.getMsgEvents", "Gets the list of events for a message")
+	APIEndpointsGetMsgReceipts                  = ffm("api.endpoints.getMsgReceipts", "Gets the delivery receipts sent by the recipients of a private message")
+	APIEndpointsGetMsgReplies                   = ffm("api.endpoints.getMsgReplies", "Gets the list of messages sent as replies to a message, ordered by sequence")
 	APIEndpointsGetMsgTxn                       = ffm("api.endpoints.getMsgTxn", "Gets the transaction for a message")
 	APIEndpointsGetMsgs                         = ffm("api.endpoints.getMsgs", "Gets a list of messages")
 	APIEndpointsGetNamespace                    = ffm("api.endpoints.getNamespace", "Gets a namespace")
+	APIEndpointsGetNamespaceDescendants         = ffm("api.endpoints.getNamespaceDescendants", "Gets all namespaces below a namespace in the namespace hierarchy")
 	APIEndpointsGetNamespaces                   = ffm("api.endpoints.getNamespaces", "Gets a list of namespaces")
 	APIEndpointsGetNetworkIdentityByDID         = ffm("api.endpoints.getNetworkIdentityByDID", "Gets an identity by its DID (deprecated - use /identities/{did} instead of /network/identities/{did})")
 	APIEndpointsGetIdentityByDID                = ffm("api.endpoints.getIdentityByDID", "Gets an identity by its DID")
@@ -133,6 +161,7 @@ var (
 	APIEndpointsGetWebSockets                   = ffm("api.endpoints.getStatusWebSockets", "Gets a list of the current WebSocket connections to this node")
 	APIEndpointsGetStatus                       = ffm("api.endpoints.getStatus", "Gets the status of this namespace")
 	APIEndpointsGetSubscriptionByID             = ffm("api.endpoints.getSubscriptionByID", "Gets a subscription by its ID")
+	APIEndpointsGetSubscriptionDeadLetters      = ffm("api.endpoints.getSubscriptionDeadLetters", "Gets the events a subscription failed to deliver and recorded as dead letters")
 	APIEndpointsGetSubscriptionEventsFiltered   = ffm("api.endpoints.getSubscriptionEventsFiltered", "Gets a collection of events filtered by the subscription for further filtering")
 	APIEndpointsGetSubscriptions                = ffm("api.endpoints.getSubscriptions", "Gets a list of subscriptions")
 	APIEndpointsGetTokenAccountPools            = ffm("api.endpoints.getTokenAccountPools", "Gets a list of token pools that contain a given token account key")
@@ -165,6 +194,7 @@ var (
 	APIEndpointsPostContractQuery               = ffm("api.endpoints.postContractQuery", "Queries a method on a smart contract. Performs a read-only query.")
 	APIEndpointsPostData                        = ffm("api.endpoints.postData", "Creates a new data item in this FireFly node")
 	APIEndpointsPostDataValuePublish            = ffm("api.endpoints.postDataValuePublish", "Publishes the JSON value from the specified data resource, to shared storage")
+	APIEndpointsPostDataCopy                    = ffm("api.endpoints.postDataCopy", "Copies a data item into another namespace. Any blob attachment is shared with the copy, rather than duplicated")
 	APIEndpointsPostDataBlobPublish             = ffm("api.endpoints.postDataBlobPublish", "Publishes the binary blob attachment stored in your local data exchange, to shared storage")
 	APIEndpointsPostNewContractAPI              = ffm("api.endpoints.postNewContractAPI", "Creates and broadcasts a new custom smart contract API")
 	APIEndpointsPostNewContractInterface        = ffm("api.endpoints.postNewContractInterface", "Creates and broadcasts a new custom smart contract interface")
@@ -179,7 +209,9 @@ var (
 	APIEndpointsPostNewOrganizationSelf         = ffm("api.endpoints.postNewOrganizationSelf", "Instructs this FireFly node to register its org on the network")
 	APIEndpointsPostNewOrganization             = ffm("api.endpoints.postNewOrganization", "Registers a new org in the network")
 	APIEndpointsPostNewSubscription             = ffm("api.endpoints.postNewSubscription", "Creates a new subscription for an application to receive events from FireFly")
+	APIEndpointsPostNewWebhookSubscription      = ffm("api.endpoints.postNewWebhookSubscription", "Creates a new webhook subscription, delivering message_confirmed events by default")
 	APIEndpointsPostOpRetry                     = ffm("api.endpoints.postOpRetry", "Retries a failed operation")
+	APIEndpointsPostDeadEventRequeue            = ffm("api.endpoints.postDeadEventRequeue", "Removes a dead event, and rewinds the event aggregator so the pin is processed again in the background")
 	APIEndpointsPostPinsRewind                  = ffm("api.endpoints.postPinsRewind", "Force a rewind of the event aggregator to a previous position, to re-evaluate (and possibly dispatch) that pin and others after it. Only accepts a sequence or batch ID for a currently undispatched pin")
 	APIEndpointsPostTokenApproval               = ffm("api.endpoints.postTokenApproval", "Creates a token approval")
 	APIEndpointsPostTokenBurn                   = ffm("api.endpoints.postTokenBurn", "Burns some tokens")
@@ -201,11 +233,14 @@ var (
 	APIFilterLimitDesc         = ffm("api.filterLimit", "The maximum number of records to return (max: %d)")
 	APIFilterCountDesc         = ffm("api.filterCount", "Return a total count as well as items (adds extra database processing)")
 	APIFetchDataDesc           = ffm("api.fetchData", "Fetch the data and include it in the messages returned")
+	APIHasTagDesc              = ffm("api.hasTag", "Only return messages that have this exact value in their tags. Uses an index, rather than the substring match of a filter on the tags")
+	APIIncludePinStatusDesc    = ffm("api.includePinStatus", "Include the status of the blockchain transaction that pinned each message (adds extra database processing)")
 	APIConfirmQueryParam       = ffm("api.confirmQueryParam", "When true the HTTP request blocks until the message is confirmed")
 	APIPublishQueryParam       = ffm("api.publishQueryParam", "When true the definition will be published to all other members of the multiparty network")
 	APIHistogramStartTimeParam = ffm("api.histogramStartTime", "Start time of the data to be fetched")
 	APIHistogramEndTimeParam   = ffm("api.histogramEndTime", "End time of the data to be fetched")
 	APIHistogramBucketsParam   = ffm("api.histogramBuckets", "Number of buckets between start time and end time")
+	APIPurgeEventsBeforeParam  = ffm("api.purgeEventsBefore", "Events with a sequence lower than this value are deleted")
 
 	APISmartContractDetails      = ffm("api.smartContractDetails", "Additional smart contract details")
 	APISmartContractDetailsKey   = ffm("api.smartContractDetailsKey", "Key")
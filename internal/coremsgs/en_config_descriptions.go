@@ -271,18 +271,29 @@ var (
 	ConfigDownloadWorkerCount       = ffc("config.download.worker.count", "The number of download workers", i18n.IntType)
 	ConfigDownloadWorkerQueueLength = ffc("config.download.worker.queueLength", "The length of the work queue in the channel to the workers - defaults to 2x the worker count", i18n.IntType)
 
-	ConfigEventAggregatorBatchSize         = ffc("config.event.aggregator.batchSize", "The maximum number of records to read from the DB before performing an aggregation run", i18n.ByteSizeType)
-	ConfigEventAggregatorBatchTimeout      = ffc("config.event.aggregator.batchTimeout", "How long to wait for new events to arrive before performing aggregation on a page of events", i18n.TimeDurationType)
-	ConfigEventAggregatorFirstEvent        = ffc("config.event.aggregator.firstEvent", "The first event the aggregator should process, if no previous offest is stored in the DB. Valid options are `oldest` or `newest`", i18n.StringType)
-	ConfigEventAggregatorPollTimeout       = ffc("config.event.aggregator.pollTimeout", "The time to wait without a notification of new events, before trying a select on the table", i18n.TimeDurationType)
-	ConfigEventAggregatorRewindQueueLength = ffc("config.event.aggregator.rewindQueueLength", "The size of the queue into the rewind dispatcher", i18n.IntType)
-	ConfigEventAggregatorRewindTimout      = ffc("config.event.aggregator.rewindTimeout", "The minimum time to wait for rewinds to accumulate before resolving them", i18n.TimeDurationType)
-	ConfigEventAggregatorRewindQueryLimit  = ffc("config.event.aggregator.rewindQueryLimit", "Safety limit on the maximum number of records to search when performing queries to search for rewinds", i18n.IntType)
-	ConfigEventDbeventsBufferSize          = ffc("config.event.dbevents.bufferSize", "The size of the buffer of change events", i18n.ByteSizeType)
-
-	ConfigEventDispatcherBatchTimeout = ffc("config.event.dispatcher.batchTimeout", "A short time to wait for new events to arrive before re-polling for new events", i18n.TimeDurationType)
-	ConfigEventDispatcherBufferLength = ffc("config.event.dispatcher.bufferLength", "The number of events + attachments an individual dispatcher should hold in memory ready for delivery to the subscription", i18n.IntType)
-	ConfigEventDispatcherPollTimeout  = ffc("config.event.dispatcher.pollTimeout", "The time to wait without a notification of new events, before trying a select on the table", i18n.TimeDurationType)
+	ConfigEventAggregatorBatchSize                = ffc("config.event.aggregator.batchSize", "The maximum number of records to read from the DB before performing an aggregation run", i18n.ByteSizeType)
+	ConfigEventAggregatorBatchTimeout             = ffc("config.event.aggregator.batchTimeout", "How long to wait for new events to arrive before performing aggregation on a page of events", i18n.TimeDurationType)
+	ConfigEventAggregatorFirstEvent               = ffc("config.event.aggregator.firstEvent", "The first event the aggregator should process, if no previous offest is stored in the DB. Valid options are `oldest` or `newest`", i18n.StringType)
+	ConfigEventAggregatorGapDetectionEnabled      = ffc("config.event.aggregator.gapDetection.enabled", "Whether to check each page of pins for gaps in the database sequence, and log a warning when one is found", i18n.BooleanType)
+	ConfigEventAggregatorGapDetectionStallTimeout = ffc("config.event.aggregator.gapDetection.stallTimeout", "How long to hold off processing a page of pins that follows a gap in the sequence, to give pins from transactions that are yet to commit a chance to appear. Zero disables stalling", i18n.TimeDurationType)
+	ConfigEventAggregatorMaxRetries               = ffc("config.event.aggregator.maxRetries", "The number of attempts to process a page of pins before any pin that still fails is recorded as a dead event, and skipped. Zero retries indefinitely", i18n.IntType)
+	ConfigEventAggregatorOrderingStrategy         = ffc("config.event.aggregator.orderingStrategy", "How the aggregator of each namespace records its progress through the pins. `global` shares one offset between all namespaces, and `namespace` stores a separate offset for each namespace, so activity in one namespace does not move the offset of another", i18n.StringType)
+	ConfigEventAggregatorResetOnSchemaMismatch    = ffc("config.event.aggregator.resetOnSchemaMismatch", "Whether to reset the aggregator offset to the configured `firstEvent` when it was stored at a different schema version, for example after a migration that renumbers sequences. When false the namespace fails to start until the offset is dealt with", i18n.BooleanType)
+	ConfigEventAggregatorPollTimeout              = ffc("config.event.aggregator.pollTimeout", "The time to wait without a notification of new events, before trying a select on the table", i18n.TimeDurationType)
+	ConfigEventAggregatorRewindQueueLength        = ffc("config.event.aggregator.rewindQueueLength", "The size of the queue into the rewind dispatcher", i18n.IntType)
+	ConfigEventAggregatorRewindTimout             = ffc("config.event.aggregator.rewindTimeout", "The minimum time to wait for rewinds to accumulate before resolving them", i18n.TimeDurationType)
+	ConfigEventAggregatorRetryJitter              = ffc("config.event.aggregator.retry.jitter", "A fraction between 0 and 1 of each retry delay to add at random, to spread out retries from multiple nodes sharing a database. Zero disables jitter", i18n.FloatType)
+	ConfigEventAggregatorRewindQueryLimit         = ffc("config.event.aggregator.rewindQueryLimit", "Safety limit on the maximum number of records to search when performing queries to search for rewinds", i18n.IntType)
+	ConfigEventAggregatorTTLScanInterval          = ffc("config.event.aggregator.ttlScanInterval", "How often to scan for pending messages received from other nodes that have passed their TTL, and mark them expired. Zero disables expiry", i18n.TimeDurationType)
+	ConfigEventArchiveBatchSize                   = ffc("config.event.archive.batchSize", "The maximum number of events to move to the archive table in a single database transaction", i18n.IntType)
+	ConfigEventArchiveInterval                    = ffc("config.event.archive.interval", "How often to check for events that have passed the retention period", i18n.TimeDurationType)
+	ConfigEventArchiveRetention                   = ffc("config.event.archive.retention", "How long events are kept in the events table before being moved to the events_archive table. Subscriptions can still replay archived events. Zero disables archiving", i18n.TimeDurationType)
+	ConfigEventDbeventsBufferSize                 = ffc("config.event.dbevents.bufferSize", "The size of the buffer of change events", i18n.ByteSizeType)
+
+	ConfigEventDispatcherBatchTimeout       = ffc("config.event.dispatcher.batchTimeout", "A short time to wait for new events to arrive before re-polling for new events", i18n.TimeDurationType)
+	ConfigEventDispatcherBatchTimeoutByType = ffc("config.event.dispatcher.batchTimeoutByType", "A map of event type to batch timeout. A batch containing an event of one of these types is delivered once the timeout for that type has passed, even if the batch timeout of the subscription has not", i18n.MapStringStringType)
+	ConfigEventDispatcherBufferLength       = ffc("config.event.dispatcher.bufferLength", "The number of events + attachments an individual dispatcher should hold in memory ready for delivery to the subscription", i18n.IntType)
+	ConfigEventDispatcherPollTimeout        = ffc("config.event.dispatcher.pollTimeout", "The time to wait without a notification of new events, before trying a select on the table", i18n.TimeDurationType)
 
 	ConfigEventTransportsDefault = ffc("config.event.transports.default", "The default event transport for new subscriptions", i18n.StringType)
 	ConfigEventTransportsEnabled = ffc("config.event.transports.enabled", "Which event interface plugins are enabled", i18n.BooleanType)
@@ -312,9 +323,19 @@ var (
 	ConfigLogTimeFormat = ffc("config.log.timeFormat", "Custom time format for logs", i18n.TimeFormatType)
 	ConfigLogUtc        = ffc("config.log.utc", "Use UTC timestamps for logs", i18n.BooleanType)
 
-	ConfigMessageWriterBatchMaxInserts = ffc("config.message.writer.batchMaxInserts", "The maximum number of database inserts to include when writing a single batch of messages + data", i18n.IntType)
-	ConfigMessageWriterBatchTimeout    = ffc("config.message.writer.batchTimeout", "How long to wait for more messages to arrive before flushing the batch", i18n.TimeDurationType)
-	ConfigMessageWriterCount           = ffc("config.message.writer.count", "The number of message writer workers", i18n.IntType)
+	ConfigMessageExternalDataAllowedHosts      = ffc("config.message.externalData.allowedHosts", "The hosts that external data references are allowed to point to. External data cannot be fetched, so messages that refer to it never become available, unless the host is in this list", i18n.ArrayStringType)
+	ConfigMessageExternalDataMaxSize           = ffc("config.message.externalData.maxSize", "The maximum size of content to download when verifying an external data reference", i18n.ByteSizeType)
+	ConfigMessageExternalDataMaxResults        = ffc("config.message.externalData.maxResults", "The maximum number of external data fetch results held in memory for messages that are not yet confirmed or rejected. The oldest are discarded first, and fetched again when needed", i18n.IntType)
+	ConfigMessageExternalDataRequestTimeout    = ffc("config.message.externalData.requestTimeout", "The maximum amount of time to wait when fetching data held at an external URL", i18n.TimeDurationType)
+	ConfigMessageExternalDataWorkerCount       = ffc("config.message.externalData.worker.count", "The number of workers fetching and verifying external data references in the background", i18n.IntType)
+	ConfigMessageExternalDataWorkerQueueLength = ffc("config.message.externalData.worker.queueLength", "The length of the work queue in the channel to the external data workers", i18n.IntType)
+	ConfigMessageExternalDataRetryMaxAttempts  = ffc("config.message.externalData.retry.maxAttempts", "The maximum number of attempts to fetch an external data reference, each time a message that refers to it is processed", i18n.IntType)
+	ConfigMessageExternalDataRetryInitialDelay = ffc("config.message.externalData.retry.initialDelay", "The initial retry delay when fetching an external data reference", i18n.TimeDurationType)
+	ConfigMessageExternalDataRetryMaxDelay     = ffc("config.message.externalData.retry.maxDelay", "The maximum retry delay when fetching an external data reference", i18n.TimeDurationType)
+	ConfigMessageExternalDataRetryFactor       = ffc("config.message.externalData.retry.factor", "The retry backoff factor when fetching an external data reference", i18n.FloatType)
+	ConfigMessageWriterBatchMaxInserts         = ffc("config.message.writer.batchMaxInserts", "The maximum number of database inserts to include when writing a single batch of messages + data", i18n.IntType)
+	ConfigMessageWriterBatchTimeout            = ffc("config.message.writer.batchTimeout", "How long to wait for more messages to arrive before flushing the batch", i18n.TimeDurationType)
+	ConfigMessageWriterCount                   = ffc("config.message.writer.count", "The number of message writer workers", i18n.IntType)
 
 	ConfigTransactionWriterBatchMaxTransactions = ffc("config.transaction.writer.batchMaxTransactions", "The maximum number of transaction inserts to include in a batch", i18n.IntType)
 	ConfigTransactionWriterBatchTimeout         = ffc("config.transaction.writer.batchTimeout", "How long to wait for more transactions to arrive before flushing the batch", i18n.TimeDurationType)
@@ -328,15 +349,19 @@ var (
 	ConfigMetricsReadTimeout  = ffc("config.metrics.readTimeout", "The maximum time to wait when reading from an HTTP connection", i18n.TimeDurationType)
 	ConfigMetricsWriteTimeout = ffc("config.metrics.writeTimeout", "The maximum time to wait when writing to an HTTP connection", i18n.TimeDurationType)
 
-	ConfigNamespacesDefault                    = ffc("config.namespaces.default", "The default namespace - must be in the predefined list", i18n.StringType)
-	ConfigNamespacesPredefined                 = ffc("config.namespaces.predefined", "A list of namespaces to ensure exists, without requiring a broadcast from the network", "List "+i18n.StringType)
-	ConfigNamespacesPredefinedName             = ffc("config.namespaces.predefined[].name", "The name of the namespace (must be unique)", i18n.StringType)
-	ConfigNamespacesPredefinedDescription      = ffc("config.namespaces.predefined[].description", "A description for the namespace", i18n.StringType)
-	ConfigNamespacesPredefinedPlugins          = ffc("config.namespaces.predefined[].plugins", "The list of plugins for this namespace", i18n.StringType)
-	ConfigNamespacesPredefinedDefaultKey       = ffc("config.namespaces.predefined[].defaultKey", "A default signing key for blockchain transactions within this namespace", i18n.StringType)
-	ConfigNamespacesPredefinedKeyNormalization = ffc("config.namespaces.predefined[].asset.manager.keyNormalization", "Mechanism to normalize keys before using them. Valid options are `blockchain_plugin` - use blockchain plugin (default) or `none` - do not attempt normalization", i18n.StringType)
-	ConfigNamespacesPredefinedTLSConfigs       = ffc("config.namespaces.predefined[].tlsConfigs", "Supply a set of tls certificates to be used by subscriptions for this namespace", "List "+i18n.StringType)
-	ConfigNamespacesPredefinedTLSConfigsName   = ffc("config.namespaces.predefined[].tlsConfigs[].name", "Name of the TLS Config", i18n.StringType)
+	ConfigNamespacesDefault                                 = ffc("config.namespaces.default", "The default namespace - must be in the predefined list", i18n.StringType)
+	ConfigNamespacesPredefined                              = ffc("config.namespaces.predefined", "A list of namespaces to ensure exists, without requiring a broadcast from the network", "List "+i18n.StringType)
+	ConfigNamespacesPredefinedName                          = ffc("config.namespaces.predefined[].name", "The name of the namespace (must be unique)", i18n.StringType)
+	ConfigNamespacesPredefinedDescription                   = ffc("config.namespaces.predefined[].description", "A description for the namespace", i18n.StringType)
+	ConfigNamespacesPredefinedParent                        = ffc("config.namespaces.predefined[].parent", "The name of another predefined namespace that is the parent of this namespace in a hierarchy", i18n.StringType)
+	ConfigNamespacesPredefinedPlugins                       = ffc("config.namespaces.predefined[].plugins", "The list of plugins for this namespace", i18n.StringType)
+	ConfigNamespacesPredefinedDefaultKey                    = ffc("config.namespaces.predefined[].defaultKey", "A default signing key for blockchain transactions within this namespace", i18n.StringType)
+	ConfigNamespacesPredefinedKeyNormalization              = ffc("config.namespaces.predefined[].asset.manager.keyNormalization", "Mechanism to normalize keys before using them. Valid options are `blockchain_plugin` - use blockchain plugin (default) or `none` - do not attempt normalization", i18n.StringType)
+	ConfigNamespacesPredefinedRBACEnabled                   = ffc("config.namespaces.predefined[].rbac.enabled", "Enforces the read, write and admin roles stored in the permissions table for authenticated principals calling the API of this namespace. Requires an auth plugin that identifies the principal of each request, such as basic", i18n.BooleanType)
+	ConfigNamespacesPredefinedEventAggregatorRateLimitBurst = ffc("config.namespaces.predefined[].event.aggregator.rateLimit.burst", "The number of pins the event aggregator of this namespace can process at once, before being limited to the sustained rate. Defaults to one second at the sustained rate", i18n.IntType)
+	ConfigNamespacesPredefinedEventAggregatorRateLimitRate  = ffc("config.namespaces.predefined[].event.aggregator.rateLimit.rate", "The sustained number of pins per second the event aggregator of this namespace can process. Zero disables rate limiting", i18n.FloatType)
+	ConfigNamespacesPredefinedTLSConfigs                    = ffc("config.namespaces.predefined[].tlsConfigs", "Supply a set of tls certificates to be used by subscriptions for this namespace", "List "+i18n.StringType)
+	ConfigNamespacesPredefinedTLSConfigsName                = ffc("config.namespaces.predefined[].tlsConfigs[].name", "Name of the TLS Config", i18n.StringType)
 	// ConfigNamespacesPredefinedTLSConfigsTLS      = ffc("config.namespaces.predefined[].tlsConfigs[].tls", "Specify the path to a CA, Cert and Key for TLS communication", i18n.StringType)
 	ConfigNamespacesMultipartyEnabled            = ffc("config.namespaces.predefined[].multiparty.enabled", "Enables multi-party mode for this namespace (defaults to true if an org name or key is configured, either here or at the root level)", i18n.BooleanType)
 	ConfigNamespacesMultipartyNetworkNamespace   = ffc("config.namespaces.predefined[].multiparty.networknamespace", "The shared namespace name to be sent in multiparty messages, if it differs from the local namespace name", i18n.StringType)
@@ -345,6 +370,7 @@ var (
 	ConfigNamespacesMultipartyOrgKey             = ffc("config.namespaces.predefined[].multiparty.org.key", "The signing key allocated to the root organization within this namespace", i18n.StringType)
 	ConfigNamespacesMultipartyNodeName           = ffc("config.namespaces.predefined[].multiparty.node.name", "The node name for this namespace", i18n.StringType)
 	ConfigNamespacesMultipartyNodeDescription    = ffc("config.namespaces.predefined[].multiparty.node.description", "A description for the node in this namespace", i18n.StringType)
+	ConfigNamespacesMultipartyAutoRegister       = ffc("config.namespaces.predefined[].multiparty.autoRegister", "Register the root org and node identities on the first startup of this namespace, if they are not already registered. Failures are retried using the namespace retry settings", i18n.BooleanType)
 	ConfigNamespacesMultipartyContract           = ffc("config.namespaces.predefined[].contract", "A list containing configuration for the multi-party blockchain contract", i18n.StringType)
 	ConfigNamespacesMultipartyContractFirstEvent = ffc("config.namespaces.predefined[].multiparty.contract[].firstEvent", "The first event the contract should process. Valid options are `oldest` or `newest`", i18n.StringType)
 	ConfigNamespacesMultipartyContractLocation   = ffc("config.namespaces.predefined[].multiparty.contract[].location", "A blockchain-specific contract location. For example, an Ethereum contract address, or a Fabric chaincode name and channel", i18n.StringType)
@@ -364,10 +390,11 @@ var (
 	ConfigOrgKey         = ffc("config.org.key", "The signing key allocated to the organization (deprecated - should be set on each multi-party namespace instead)", i18n.StringType)
 	ConfigOrgName        = ffc("config.org.name", "The name of the organization to which this FireFly node belongs (deprecated - should be set on each multi-party namespace instead)", i18n.StringType)
 
-	ConfigPrivatemessagingBatchAgentTimeout = ffc("config.privatemessaging.batch.agentTimeout", "How long to keep around a batching agent for a sending identity before disposal", i18n.TimeDurationType)
-	ConfigPrivatemessagingBatchPayloadLimit = ffc("config.privatemessaging.batch.payloadLimit", "The maximum payload size of a private message Data Exchange payload", i18n.ByteSizeType)
-	ConfigPrivatemessagingBatchSize         = ffc("config.privatemessaging.batch.size", "The maximum number of messages in a batch for private messages", i18n.IntType)
-	ConfigPrivatemessagingBatchTimeout      = ffc("config.privatemessaging.batch.timeout", "The timeout to wait for a batch to fill, before sending", i18n.TimeDurationType)
+	ConfigPrivatemessagingBatchAgentTimeout       = ffc("config.privatemessaging.batch.agentTimeout", "How long to keep around a batching agent for a sending identity before disposal", i18n.TimeDurationType)
+	ConfigPrivatemessagingBatchPayloadLimit       = ffc("config.privatemessaging.batch.payloadLimit", "The maximum payload size of a private message Data Exchange payload", i18n.ByteSizeType)
+	ConfigPrivatemessagingBatchSize               = ffc("config.privatemessaging.batch.size", "The maximum number of messages in a batch for private messages", i18n.IntType)
+	ConfigPrivatemessagingBatchTimeout            = ffc("config.privatemessaging.batch.timeout", "The timeout to wait for a batch to fill, before sending", i18n.TimeDurationType)
+	ConfigPrivatemessagingDeliveryReceiptsEnabled = ffc("config.privatemessaging.deliveryReceipts.enabled", "Whether to broadcast a delivery receipt, on behalf of each local member of the group, when a private message from another member is confirmed. Receipts are broadcast to the whole network, so every member learns the ID and hash of the message, which identities received it, and when - only the content of the message stays private", i18n.BooleanType)
 
 	ConfigSharedstorageType                = ffc("config.sharedstorage.type", "The Shared Storage plugin to use", i18n.StringType)
 	ConfigSharedstorageIpfsAPIURL          = ffc("config.sharedstorage.ipfs.api.url", "The URL for the IPFS API", urlStringType)
@@ -386,6 +413,7 @@ var (
 	ConfigSubscriptionMax                          = ffc("config.subscription.max", "The maximum number of pre-defined subscriptions that can exist (note for high fan-out consider connecting a dedicated pub/sub broker to the dispatcher)", i18n.IntType)
 	ConfigSubscriptionDefaultsBatchSize            = ffc("config.subscription.defaults.batchSize", "Default read ahead to enable for subscriptions that do not explicitly configure readahead", i18n.IntType)
 	ConfigSubscriptionDefaultsBatchTimeout         = ffc("config.subscription.defaults.batchTimeout", "Default batch timeout", i18n.IntType)
+	ConfigSubscriptionDrainTimeout                 = ffc("config.subscription.drainTimeout", "The maximum time to wait for in-flight deliveries to complete when deleting a subscription, before it is deleted anyway", i18n.TimeDurationType)
 	ConfigSubscriptionMaxHistoricalEventScanLength = ffc("config.subscription.events.maxScanLength", "The maximum number of events a search for historical events matching a subscription will index from the database", i18n.IntType)
 
 	ConfigTokensName     = ffc("config.tokens[].name", "A name to identify this token plugin", i18n.StringType)
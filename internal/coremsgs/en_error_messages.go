@@ -71,8 +71,8 @@ var (
 	MsgEventTypesParseFail                   = ffe("FF10168", "Unable to parse list of event types", 400)
 	MsgUnknownEventType                      = ffe("FF10169", "Unknown event type '%s'", 400)
 	MsgIDMismatch                            = ffe("FF10170", "ID mismatch")
-	MsgRegexpCompileFailed                   = ffe("FF10171", "Unable to compile '%s' regexp '%s'")
-	MsgUnknownEventTransportPlugin           = ffe("FF10172", "Unknown event transport plugin: %s")
+	MsgRegexpCompileFailed                   = ffe("FF10171", "Unable to compile '%s' regexp '%s'", 400)
+	MsgUnknownEventTransportPlugin           = ffe("FF10172", "Unknown event transport plugin: %s", 400)
 	MsgWSConnectionNotActive                 = ffe("FF10173", "Websocket connection '%s' no longer active")
 	MsgWSSubAlreadyInFlight                  = ffe("FF10174", "Websocket subscription '%s' already has a message in flight")
 	MsgWSMsgSubNotMatched                    = ffe("FF10175", "Acknowledgment does not match an inflight event + subscription")
@@ -133,7 +133,7 @@ var (
 	MsgRequestTimeout                        = ffe("FF10260", "The request with id '%s' timed out after %.2fms", 408)
 	MsgRequestReplyTagRequired               = ffe("FF10261", "For request messages 'header.tag' must be set on the request message to route it to a suitable responder", 400)
 	MsgRequestCannotHaveCID                  = ffe("FF10262", "For request messages 'header.cid' must be unset", 400)
-	MsgSystemTransportInternal               = ffe("FF10266", "You cannot create subscriptions on the system events transport")
+	MsgSystemTransportInternal               = ffe("FF10266", "You cannot create subscriptions on the system events transport", 400)
 	MsgFilterCountNotSupported               = ffe("FF10267", "This query does not support generating a count of all results")
 	MsgRejected                              = ffe("FF10269", "Message with ID '%s' was rejected. Please check the FireFly logs for more information")
 	MsgRequestMustBePrivate                  = ffe("FF10271", "For request messages you must specify a group of private recipients", 400)
@@ -307,4 +307,45 @@ var (
 	MsgCannotCancelBatchType                 = ffe("FF10466", "Cannot cancel batch of type: %s", 400)
 	MsgErrorLoadingBatch                     = ffe("FF10467", "Error loading batch messages")
 	MsgBatchNotDispatching                   = ffe("FF10468", "Batch %s is not currently dispatching - current: %s", 400)
+	MsgNamespaceRoleRequired                 = ffe("FF10469", "Principal '%s' requires the '%s' role in namespace '%s'", 403)
+	MsgInvalidExternalDataRef                = ffe("FF10470", "Invalid external data reference: %s", 400)
+	MsgCopyDataSameNamespace                 = ffe("FF10471", "Data cannot be copied into namespace '%s', as it already belongs to that namespace", 400)
+	MsgNotifierSubscriptionNotFound          = ffe("FF10472", "No subscription '%d' registered on the %s notifier")
+	MsgNotifierNilChannel                    = ffe("FF10473", "A channel must be supplied to subscribe to the %s notifier")
+	MsgMessageNotDeletable                   = ffe("FF10474", "Message '%s' is in state '%s' - only rejected, cancelled or expired messages can be deleted", 409)
+	MsgEventsNotDelivered                    = ffe("FF10475", "Events up to sequence %d cannot be deleted, as subscription '%s' has only been delivered events up to sequence %d", 409)
+	MsgNamespaceExportWriteFailed            = ffe("FF10476", "Failed to write namespace export record")
+	MsgNamespaceImportBadRecord              = ffe("FF10477", "Failed to read record %d of namespace import", 400)
+	MsgNamespaceImportUnknownType            = ffe("FF10478", "Unknown type '%s' for record %d of namespace import", 400)
+	MsgNamespaceParentNotFound               = ffe("FF10479", "Parent namespace '%s' of namespace '%s' is not defined")
+	MsgNamespaceParentCycle                  = ffe("FF10480", "Namespace '%s' is its own ancestor")
+	MsgReplyToMessageNotFound                = ffe("FF10481", "Message '%s' referenced by replyTo was not found", 400)
+	MsgConversationMessageNotFound           = ffe("FF10482", "Message '%s' referenced by conversationId was not found", 400)
+	MsgInvalidStartupMode                    = ffe("FF10483", "Invalid startupMode '%s' - must be 'resume', 'skip' or 'replay'", 400)
+	MsgInvalidErrorHandling                  = ffe("FF10484", "Invalid errorHandling '%s' - must be 'block', 'skip' or 'deadletter'", 400)
+	MsgNoMatchingEventType                   = ffe("FF10485", "Subscription filter.events '%s' does not match any known event type", 400)
+	MsgInvalidBatchSize                      = ffe("FF10486", "Subscription batch size (readAhead) must be greater than zero when batch is enabled", 400)
+	MsgInvalidDeliveryState                  = ffe("FF10487", "Invalid subscription status '%s' - must be 'active' or 'paused'", 400)
+	MsgInvalidMigrationsPluginName           = ffe("FF10488", "Invalid plugin name '%s' for migrations - must contain only letters, numbers and underscores")
+	MsgMigrationsAlreadyRegistered           = ffe("FF10489", "Migrations for plugin '%s' are already registered from '%s'")
+	MsgPluginMigrationsNotSupported          = ffe("FF10490", "Database provider '%s' does not support plugin migrations")
+	MsgPluginMigrationFailed                 = ffe("FF10491", "Migrations failed for plugin '%s'")
+	MsgMessageNotConfirmed                   = ffe("FF10492", "Message '%s' has not been confirmed")
+	MsgContextNotBlocked                     = ffe("FF10493", "No undispatched pins found for context '%s'", 404)
+	MsgInvalidOrderingStrategy               = ffe("FF10494", "Invalid event aggregator ordering strategy '%s'")
+	MsgInvalidReplayRange                    = ffe("FF10495", "Invalid replay range %d-%d - fromSequence must not be negative, or greater than toSequence", 400)
+	MsgReplayRangeOverlap                    = ffe("FF10496", "Replay range %d-%d overlaps running replay '%s'", 409)
+	MsgReplayAheadOfAggregator               = ffe("FF10497", "Replay range must end at or before the event aggregator offset %d", 409)
+	MsgWebhookSignFailed                     = ffe("FF10498", "Failed to serialize webhook request body for signing")
+	MsgOffsetSchemaMismatch                  = ffe("FF10499", "Offset '%s:%s' was stored at schema version %d, but the current schema version is %d. Set event.aggregator.resetOnSchemaMismatch to reset the offset to the configured firstEvent")
+	MsgExternalDataFetchFailed               = ffe("FF10500", "External data fetch failed with status %d")
+	MsgExternalDataTooLarge                  = ffe("FF10501", "External data exceeds the maximum size of %d bytes")
+	MsgExternalDataHashMismatch              = ffe("FF10502", "External data hash %s does not match the hash %s in the reference")
+	MsgWebsocketsNoErrorHandling             = ffe("FF10504", "Websockets subscriptions do not support errorHandling '%s', as a delivery failure means the connection has gone and the events must be redelivered on reconnect", 400)
+	MsgEventsNotDeliveredNoOffset            = ffe("FF10503", "Events up to sequence %d cannot be deleted, as subscription '%s' has not yet recorded which events it has been delivered", 409)
+	MsgContextMaskedCannotUnblock            = ffe("FF10505", "Context '%s' is a masked private context, which cannot be force unblocked as the next pin of each member would not be advanced", 400)
+	MsgIdentityClaimPending                  = ffe("FF10506", "An identity claim by '%s' is still waiting to be confirmed")
+	MsgNamespaceRBACNoPrincipalAuth          = ffe("FF10507", "Invalid %s namespace configuration - rbac.enabled requires an auth plugin that identifies the principal of each request")
+	MsgPermissionPrincipalRequired           = ffe("FF10508", "A principal is required to grant a permission", 400)
+	MsgCopyDataDifferentDatabase             = ffe("FF10509", "Data cannot be copied from namespace '%s' to namespace '%s', as they do not share a database plugin", 400)
 )
@@ -59,6 +59,11 @@ var (
 	MessageHeaderTag       = ffm("MessageHeader.tag", "The message tag indicates the purpose of the message to the applications that process it")
 	MessageHeaderDataHash  = ffm("MessageHeader.datahash", "A single hash representing all data in the message. Derived from the array of data ids+hashes attached to this message")
 	MessageTxParent        = ffm("MessageHeader.txparent", "The parent transaction that originally triggered this message")
+	MessageContentType     = ffm("MessageHeader.contentType", "The MIME type of the data payload of the message. Defaults to application/json")
+	MessageReplyTo         = ffm("MessageHeader.replyTo", "The ID of the message this message is a reply to. The referenced message must exist in the same namespace")
+	MessageConversationID  = ffm("MessageHeader.conversationId", "The ID of the message that started the conversation this message belongs to. The referenced message must exist in the same namespace")
+	MessageTTL             = ffm("MessageHeader.ttl", "How long after creation the message must be confirmed by. If it is not confirmed in time, the message moves to the expired state, and no longer blocks the messages that follow it on the same topic")
+	MessagePriority        = ffm("MessageHeader.priority", "The priority of the message, from 0 (normal) to 255. Where messages on different topics are ready to be processed together, higher priority messages are dispatched first")
 
 	// Message field descriptions
 	MessageHeader         = ffm("Message.header", "The message header contains all fields that are used to build the message hash")
@@ -67,10 +72,13 @@ var (
 	MessageBatchID        = ffm("Message.batch", "The UUID of the batch in which the message was pinned/transferred")
 	MessageState          = ffm("Message.state", "The current state of the message")
 	MessageConfirmed      = ffm("Message.confirmed", "The timestamp of when the message was confirmed/rejected")
+	MessageReceivedAt     = ffm("Message.receivedAt", "The timestamp of when the message was first stored by the local node. For messages from other members this is when the message arrived, as opposed to 'created' which is set by the sender. Local only - not transferred to other members of the network")
 	MessageRejectReason   = ffm("Message.rejectReason", "If a message was rejected, provides details on the rejection reason")
 	MessageData           = ffm("Message.data", "The list of data elements attached to the message")
 	MessagePins           = ffm("Message.pins", "For private messages, a unique pin hash:nonce is assigned for each topic")
 	MessageTransactionID  = ffm("Message.txid", "The ID of the transaction used to order/deliver this message")
+	MessageTags           = ffm("Message.tags", "Business tags associated with the message for filtering, such as 'invoice' or 'urgent'. Separate from the header tag. Not part of the message hash, so not covered by the pinned batch hash. Immutable once the message is created")
+	MessagePinStatus      = ffm("Message.pinStatus", "The status of the blockchain transaction that pinned this message, if it has been pinned. Only included when requested with includePinStatus=true")
 	MessageIdempotencyKey = ffm("Message.idempotencyKey", "An optional unique identifier for a message. Cannot be duplicated within a namespace, thus allowing idempotent submission of messages to the API. Local only - not transferred when the message is sent to other members of the network")
 
 	// MessageInOut field descriptions
@@ -86,6 +94,7 @@ var (
 	DataRefOrValueDatatype  = ffm("DataRefOrValue.datatype", "The optional datatype to use for validation of the in-line data")
 	DataRefOrValueValue     = ffm("DataRefOrValue.value", "The in-line value for the data. Can be any JSON type - object, array, string, number or boolean")
 	DataRefOrValueBlob      = ffm("DataRefOrValue.blob", "An optional in-line hash reference to a previously uploaded binary data blob")
+	DataRefOrValueExternal  = ffm("DataRefOrValue.externalRef", "An optional reference to data content held outside of FireFly, which is not stored locally")
 
 	// MessageRef field descriptions
 	MessageRefID   = ffm("MessageRef.id", "The UUID of the referenced message")
@@ -112,6 +121,10 @@ var (
 	DataRefID   = ffm("DataRef.id", "The UUID of the referenced data resource")
 	DataRefHash = ffm("DataRef.hash", "The hash of the referenced data")
 
+	// ExternalDataRef field descriptions
+	ExternalDataRefURL  = ffm("ExternalDataRef.url", "The URL at which the external data content can be retrieved. The host must be in message.externalData.allowedHosts")
+	ExternalDataRefHash = ffm("ExternalDataRef.hash", "The SHA-256 hash of the external data content. Required, and checked against the content when it is fetched before the message is dispatched")
+
 	// BlobRef field descriptions
 	BlobRefHash   = ffm("BlobRef.hash", "The hash of the binary blob data")
 	BlobRefSize   = ffm("BlobRef.size", "The size of the binary data")
@@ -127,9 +140,14 @@ var (
 	DataCreated   = ffm("Data.created", "The creation time of the data resource")
 	DataDatatype  = ffm("Data.datatype", "The optional datatype to use of validation of this data")
 	DataValue     = ffm("Data.value", "The value for the data, stored in the FireFly core database. Can be any JSON type - object, array, string, number or boolean. Can be combined with a binary blob attachment")
+	DataType      = ffm("Data.type", "The type of the data - an inline value, an attached blob, or a reference to external content. Derived from the content, and not part of the data hash")
 	DataBlob      = ffm("Data.blob", "An optional hash reference to a binary blob attachment")
+	DataExternal  = ffm("Data.externalRef", "An optional reference to data content held outside of FireFly, which is checked for availability but not stored locally")
 	DataPublic    = ffm("Data.public", "If the JSON value has been published to shared storage, this field is the id of the data in the shared storage plugin (IPFS hash etc.)")
 
+	// DataCopyInput field descriptions
+	DataCopyInputNamespace = ffm("DataCopyInput.namespace", "The namespace to copy the data into. Must share a database plugin with the namespace of the source data")
+
 	// DatatypeRef field descriptions
 	DatatypeRefName    = ffm("DatatypeRef.name", "The name of the datatype")
 	DatatypeRefVersion = ffm("DatatypeRef.version", "The version of the datatype. Semantic versioning is encouraged, such as v1.0.1")
@@ -343,10 +361,15 @@ var (
 	EventTopic       = ffm("Event.topic", "A stream of information this event relates to. For message confirmation events, a separate event is emitted for each topic in the message. For blockchain events, the listener specifies the topic. Rules exist for how the topic is set for other event types")
 	EventCreated     = ffm("Event.created", "The time the event was emitted. Not guaranteed to be unique, or to increase between events in the same order as the final sequence events are delivered to your application. As such, the 'sequence' field should be used instead of the 'created' field for querying events in the exact order they are delivered to applications")
 
+	// EventPurgeResult field descriptions
+	EventPurgeResultBefore  = ffm("EventPurgeResult.before", "All events with a sequence lower than this value were deleted")
+	EventPurgeResultDeleted = ffm("EventPurgeResult.deleted", "The number of events that were deleted")
+
 	// EnrichedEvent field descriptions
 	EnrichedEventBlockchainEvent   = ffm("EnrichedEvent.blockchainEvent", "A blockchain event if referenced by the FireFly event")
 	EnrichedEventContractAPI       = ffm("EnrichedEvent.contractAPI", "A Contract API if referenced by the FireFly event")
 	EnrichedEventContractInterface = ffm("EnrichedEvent.contractInterface", "A Contract Interface (FFI) if referenced by the FireFly event")
+	EnrichedEventDeadEvent         = ffm("EnrichedEvent.deadEvent", "A dead event if referenced by the FireFly event")
 	EnrichedEventDatatype          = ffm("EnrichedEvent.datatype", "A Datatype if referenced by the FireFly event")
 	EnrichedEventIdentity          = ffm("EnrichedEvent.identity", "An Identity if referenced by the FireFly event")
 	EnrichedEventMessage           = ffm("EnrichedEvent.message", "A Message if  referenced by the FireFly event")
@@ -406,6 +429,7 @@ var (
 	NamespaceName                  = ffm("Namespace.name", "The local namespace name")
 	NamespaceNetworkName           = ffm("Namespace.networkName", "The shared namespace name within the multiparty network")
 	NamespaceDescription           = ffm("Namespace.description", "A description of the namespace")
+	NamespaceParentNamespace       = ffm("Namespace.parentNamespace", "The local name of the parent of this namespace, when namespaces are organized in a hierarchy")
 	NamespaceCreated               = ffm("Namespace.created", "The time the namespace was created")
 	MultipartyContractsActive      = ffm("MultipartyContracts.active", "The currently active FireFly smart contract")
 	MultipartyContractsTerminated  = ffm("MultipartyContracts.terminated", "Previously-terminated FireFly smart contracts")
@@ -497,6 +521,71 @@ var (
 	PinRewindSequence = ffm("PinRewind.sequence", "The sequence of the pin to which the event aggregator should rewind. Either sequence or batch must be specified")
 	PinRewindBatch    = ffm("PinRewind.batch", "The ID of the batch to which the event aggregator should rewind. Either sequence or batch must be specified")
 
+	// DeadEvent field descriptions
+	DeadEventID        = ffm("DeadEvent.id", "The UUID of the dead event")
+	DeadEventNamespace = ffm("DeadEvent.namespace", "The namespace of the dead event")
+	DeadEventPin       = ffm("DeadEvent.pin", "The sequence of the pin the event aggregator failed to process")
+	DeadEventBatch     = ffm("DeadEvent.batch", "The UUID of the batch of messages the pin is part of")
+	DeadEventHash      = ffm("DeadEvent.hash", "The hash of the pin")
+	DeadEventIndex     = ffm("DeadEvent.index", "The index of the pin within the batch")
+	DeadEventAttempts  = ffm("DeadEvent.attempts", "The number of attempts made to process the pin before it was recorded as a dead event")
+	DeadEventError     = ffm("DeadEvent.error", "The error from the last attempt to process the pin")
+	DeadEventCreated   = ffm("DeadEvent.created", "The time the pin was first recorded as a dead event")
+	DeadEventUpdated   = ffm("DeadEvent.updated", "The time the pin was most recently recorded as a dead event")
+
+	// DeliveryReceipt field descriptions
+	DeliveryReceiptID           = ffm("DeliveryReceipt.id", "The UUID of the delivery receipt")
+	DeliveryReceiptNamespace    = ffm("DeliveryReceipt.namespace", "The namespace of the delivery receipt")
+	DeliveryReceiptMessageID    = ffm("DeliveryReceipt.messageId", "The UUID of the private message that was received")
+	DeliveryReceiptMessageHash  = ffm("DeliveryReceipt.messageHash", "The hash of the private message that was received, which must match the hash of the message sent")
+	DeliveryReceiptRecipientDID = ffm("DeliveryReceipt.recipientDid", "The DID of the group member that received the message")
+	DeliveryReceiptReceivedAt   = ffm("DeliveryReceipt.receivedAt", "The time the message was confirmed on the recipient's node")
+	DeliveryReceiptKey          = ffm("DeliveryReceipt.key", "The blockchain signing key of the recipient, which must match the key that signed the broadcast of the receipt")
+	DeliveryReceiptBroadcast    = ffm("DeliveryReceipt.broadcast", "The UUID of the broadcast message that was used to publish the receipt to the network")
+
+	// NamespacedPermission field descriptions
+	NamespacedPermissionNamespace = ffm("NamespacedPermission.namespace", "The namespace the role is granted in")
+	NamespacedPermissionPrincipal = ffm("NamespacedPermission.principal", "The authenticated principal the role is granted to")
+	NamespacedPermissionRole      = ffm("NamespacedPermission.role", "The role granted to the principal - read, write or admin")
+	NamespacedPermissionCreated   = ffm("NamespacedPermission.created", "The time the role was granted")
+
+	// PermissionInput field descriptions
+	PermissionInputPrincipal = ffm("PermissionInput.principal", "The authenticated principal to grant the role to")
+	PermissionInputRole      = ffm("PermissionInput.role", "The role to grant - read, write or admin")
+
+	// PinReplay field descriptions
+	PinReplayID           = ffm("PinReplay.id", "The UUID of the replay")
+	PinReplayNamespace    = ffm("PinReplay.namespace", "The namespace of the replay")
+	PinReplayFromSequence = ffm("PinReplay.fromSequence", "The sequence of the first pin in the range to replay")
+	PinReplayToSequence   = ffm("PinReplay.toSequence", "The sequence of the last pin in the range to replay, which must not be ahead of the event aggregator")
+	PinReplayCurrent      = ffm("PinReplay.current", "The sequence of the last pin processed by the replay")
+	PinReplayStatus       = ffm("PinReplay.status", "The status of the replay")
+	PinReplayCreated      = ffm("PinReplay.created", "The time the replay was started")
+	PinReplayCompleted    = ffm("PinReplay.completed", "The time the replay completed")
+	PinReplayUndispatched = ffm("PinReplay.undispatched", "The number of pins in the range that were still undispatched when the replay completed, for example because their context is still blocked")
+
+	// BlockedContext field descriptions
+	BlockedContextContext = ffm("BlockedContext.context", "The hash of the blocked context")
+	BlockedContextPin     = ffm("BlockedContext.pin", "The sequence of the earliest undispatched pin on the context, which is blocking it")
+	BlockedContextBatch   = ffm("BlockedContext.batch", "The UUID of the batch the blocking pin belongs to")
+	BlockedContextIndex   = ffm("BlockedContext.index", "The index of the blocking pin within the batch")
+	BlockedContextSigner  = ffm("BlockedContext.signer", "The blockchain signing key that submitted the blocking pin")
+	BlockedContextMessage = ffm("BlockedContext.message", "The UUID of the message for the blocking pin, if the batch has been received")
+	BlockedContextAuthor  = ffm("BlockedContext.author", "The author of the message for the blocking pin, if the message has been received")
+	BlockedContextTag     = ffm("BlockedContext.tag", "The tag of the message for the blocking pin, if the message has been received")
+	BlockedContextTopics  = ffm("BlockedContext.topics", "The topics of the message for the blocking pin, if the message has been received")
+	BlockedContextCreated = ffm("BlockedContext.created", "The time the blocking pin was detected")
+
+	// BlockedContextStats field descriptions
+	BlockedContextStatsTotal     = ffm("BlockedContextStats.total", "The number of blocked contexts")
+	BlockedContextStatsOldest    = ffm("BlockedContextStats.oldest", "The time the blocking pin of the longest blocked context was detected")
+	BlockedContextStatsOldestAge = ffm("BlockedContextStats.oldestAge", "How long the longest blocked context has been blocked")
+	BlockedContextStatsHistogram = ffm("BlockedContextStats.histogram", "The number of blocked contexts in each range of ages")
+
+	// BlockedContextAgeBucket field descriptions
+	BlockedContextAgeBucketMinAge = ffm("BlockedContextAgeBucket.minAge", "The minimum age of the blocked contexts in the bucket, which ends at the minimum age of the next bucket")
+	BlockedContextAgeBucketCount  = ffm("BlockedContextAgeBucket.count", "The number of blocked contexts in the bucket")
+
 	// NextPin field descriptions
 	NextPinNamespace = ffm("NextPin.namespace", "The namespace of the next-pin")
 	NextPinContext   = ffm("NextPin.context", "The context the next-pin applies to - the hash of the privacy group-hash + topic. The group-hash is only known to the participants (can itself contain a salt in the group-name). This context is combined with the member and nonce to determine the final hash that is written on-chain")
@@ -515,12 +604,27 @@ var (
 	SubscriptionCreated   = ffm("Subscription.created", "Creation time of the subscription")
 	SubscriptionUpdated   = ffm("Subscription.updated", "Last time the subscription was updated")
 
+	// SubscriptionStatus field descriptions
+	SubscriptionStatusDeliveryState = ffm("SubscriptionStatus.deliveryState", "Whether events are being delivered for the subscription on this node - 'active' when a connected consumer is receiving events, or 'paused' otherwise")
+	SubscriptionStatusDeliveryLag   = ffm("SubscriptionStatus.deliveryLag", "For active subscriptions, the number of event sequences between the latest event in the namespace and the subscription's current delivery offset")
+
+	// DeadLetter field descriptions
+	DeadLetterID            = ffm("DeadLetter.id", "The UUID of the dead letter")
+	DeadLetterNamespace     = ffm("DeadLetter.namespace", "The namespace of the subscription")
+	DeadLetterSubscription  = ffm("DeadLetter.subscription", "The UUID of the subscription that failed to deliver the event")
+	DeadLetterEvent         = ffm("DeadLetter.event", "The UUID of the event that could not be delivered")
+	DeadLetterEventSequence = ffm("DeadLetter.eventSequence", "The sequence of the event that could not be delivered")
+	DeadLetterError         = ffm("DeadLetter.error", "The error returned by the transport when delivery failed")
+	DeadLetterCreated       = ffm("DeadLetter.created", "The time the event was recorded as a dead letter")
+
 	// SubscriptionFilter field descriptions
 	SubscriptionFilterEvents           = ffm("SubscriptionFilter.events", "Regular expression to apply to the event type, to subscribe to a subset of event types")
 	SubscriptionFilterTopic            = ffm("SubscriptionFilter.topic", "Regular expression to apply to the topic of the event, to subscribe to a subset of topics. Note for messages sent with multiple topics, a separate event is emitted for each topic")
 	SubscriptionFilterMessage          = ffm("SubscriptionFilter.message", "Filters specific to message events. If an event is not a message event, these filters are ignored")
+	SubscriptionFilterMessageTag       = ffm("SubscriptionFilter.messageTag", "Only deliver message events where the message has this exact value in its list of tags. Events that are not for a message are not delivered")
 	SubscriptionFilterTransaction      = ffm("SubscriptionFilter.transaction", "Filters specific to events with a transaction. If an event is not associated with a transaction, this filter is ignored")
 	SubscriptionFilterBlockchainEvent  = ffm("SubscriptionFilter.blockchainevent", "Filters specific to blockchain events. If an event is not a blockchain event, these filters are ignored")
+	SubscriptionFilterNamespaces       = ffm("SubscriptionFilter.namespaces", "Additional namespaces to deliver events from, alongside the namespace of the subscription. The subscriber must be authorized to read from each namespace")
 	SubscriptionFilterDeprecatedTopics = ffm("SubscriptionFilter.topics", "Deprecated: Please use 'topic' instead")
 	SubscriptionFilterDeprecatedTag    = ffm("SubscriptionFilter.tag", "Deprecated: Please use 'message.tag' instead")
 	SubscriptionFilterDeprecatedGroup  = ffm("SubscriptionFilter.group", "Deprecated: Please use 'message.group' instead")
@@ -539,11 +643,13 @@ var (
 	SubscriptionBlockchainEventFilterListener = ffm("SubscriptionBlockchainEventFilter.listener", "Regular expression to apply to the blockchain event 'listener' field, which is the UUID of the event listener. So you can restrict your subscription to certain blockchain listeners. Alternatively to avoid your application need to know listener UUIDs you can set the 'topic' field of blockchain event listeners, and use a topic filter on your subscriptions")
 
 	// SubscriptionCoreOptions field descriptions
-	SubscriptionCoreOptionsFirstEvent   = ffm("SubscriptionCoreOptions.firstEvent", "Whether your application would like to receive events from the 'oldest' event emitted by your FireFly node (from the beginning of time), or the 'newest' event (from now), or a specific event sequence. Default is 'newest'")
-	SubscriptionCoreOptionsReadAhead    = ffm("SubscriptionCoreOptions.readAhead", "The number of events to stream ahead to your application, while waiting for confirmation of consumption of those events. At least once delivery semantics are used in FireFly, so if your application crashes/reconnects this is the maximum number of events you would expect to be redelivered after it restarts")
-	SubscriptionCoreOptionsWithData     = ffm("SubscriptionCoreOptions.withData", "Whether message events delivered over the subscription, should be packaged with the full data of those messages in-line as part of the event JSON payload. Or if the application should make separate REST calls to download that data. May not be supported on some transports.")
-	SubscriptionCoreOptionsBatch        = ffm("SubscriptionCoreOptions.batch", "Events are delivered in batches in an ordered array. The batch size is capped to the readAhead limit. The event payload is always an array even if there is a single event in the batch, allowing client-side optimizations when processing the events in a group. Available for both Webhooks and WebSockets.")
-	SubscriptionCoreOptionsBatchTimeout = ffm("SubscriptionCoreOptions.batchTimeout", "When batching is enabled, the optional timeout to send events even when the batch hasn't filled.")
+	SubscriptionCoreOptionsFirstEvent    = ffm("SubscriptionCoreOptions.firstEvent", "Whether your application would like to receive events from the 'oldest' event emitted by your FireFly node (from the beginning of time), or the 'newest' event (from now), or a specific event sequence. Default is 'newest'")
+	SubscriptionCoreOptionsReadAhead     = ffm("SubscriptionCoreOptions.readAhead", "The number of events to stream ahead to your application, while waiting for confirmation of consumption of those events. At least once delivery semantics are used in FireFly, so if your application crashes/reconnects this is the maximum number of events you would expect to be redelivered after it restarts")
+	SubscriptionCoreOptionsWithData      = ffm("SubscriptionCoreOptions.withData", "Whether message events delivered over the subscription, should be packaged with the full data of those messages in-line as part of the event JSON payload. Or if the application should make separate REST calls to download that data. May not be supported on some transports.")
+	SubscriptionCoreOptionsBatch         = ffm("SubscriptionCoreOptions.batch", "Events are delivered in batches in an ordered array. The batch size is capped to the readAhead limit. The event payload is always an array even if there is a single event in the batch, allowing client-side optimizations when processing the events in a group. Available for both Webhooks and WebSockets.")
+	SubscriptionCoreOptionsBatchTimeout  = ffm("SubscriptionCoreOptions.batchTimeout", "When batching is enabled, the optional timeout to send events even when the batch hasn't filled. A batch is sent as soon as it is full, or when this timeout expires. Defaults to subscription.defaults.batchTimeout")
+	SubscriptionCoreOptionsStartupMode   = ffm("SubscriptionCoreOptions.startupMode", "Where an existing durable subscription continues from each time the node starts, or the subscription is updated. Reconnecting does not move the offset. 'resume' continues from the last acknowledged event, 'skip' advances to the newest event, and 'replay' resets to the firstEvent of the subscription. Default is 'resume'")
+	SubscriptionCoreOptionsErrorHandling = ffm("SubscriptionCoreOptions.errorHandling", "What to do with an event the transport fails to deliver. 'block' stops delivery and retries the failed event until it succeeds, 'skip' acknowledges it and continues with the next event, and 'deadletter' records it as a dead letter on the subscription and continues. When unset, webhooks acknowledge failed deliveries with a 502 error response. Websocket subscriptions only support 'block', as failed events are redelivered when the application reconnects")
 
 	// TokenApproval field descriptions
 	TokenApprovalLocalID         = ffm("TokenApproval.localId", "The UUID of this token approval, in the local FireFly node")
@@ -646,6 +752,16 @@ var (
 	TransactionStatusDetailsError     = ffm("TransactionStatusDetails.error", "If an error occurred related to the detail entry, it is included here")
 	TransactionStatusDetailsInfo      = ffm("TransactionStatusDetails.info", "Output details for this entry")
 
+	// BlockchainReceipt field descriptions
+	BlockchainReceiptNamespace   = ffm("BlockchainReceipt.namespace", "The namespace of the receipt")
+	BlockchainReceiptOperation   = ffm("BlockchainReceipt.operation", "The ID of the FireFly operation that submitted the transaction")
+	BlockchainReceiptTxID        = ffm("BlockchainReceipt.txId", "The blockchain transaction ID, in the format specific to the blockchain involved in the transaction")
+	BlockchainReceiptMessageID   = ffm("BlockchainReceipt.messageId", "The ID of the message pinned by the transaction")
+	BlockchainReceiptBlockNumber = ffm("BlockchainReceipt.blockNumber", "The number of the block containing the transaction, if reported by the blockchain connector")
+	BlockchainReceiptBlockHash   = ffm("BlockchainReceipt.blockHash", "The hash of the block containing the transaction, if reported by the blockchain connector")
+	BlockchainReceiptStatus      = ffm("BlockchainReceipt.status", "The status of the transaction - pending, confirmed or failed")
+	BlockchainReceiptConfirmedAt = ffm("BlockchainReceipt.confirmedAt", "The time the FireFly node received confirmation of the transaction")
+
 	// ContractDeployRequest field descriptions
 	ContractDeployRequestKey            = ffm("ContractDeployRequest.key", "The blockchain signing key that will be used to deploy the contract. Defaults to the first signing key of the organization that operates the node")
 	ContractDeployRequestInput          = ffm("ContractDeployRequest.input", "An optional array of inputs passed to the smart contract's constructor, if applicable")
@@ -689,6 +805,7 @@ var (
 	WebhooksOptJSON                     = ffm("WebhookSubOptions.json", "Webhooks only: Whether to assume the response body is JSON, regardless of the returned Content-Type")
 	WebhooksOptReply                    = ffm("WebhookSubOptions.reply", "Webhooks only: Whether to automatically send a reply event, using the body returned by the webhook")
 	WebhooksOptHeaders                  = ffm("WebhookSubOptions.headers", "Webhooks only: Static headers to set on the webhook request")
+	WebhooksOptSecret                   = ffm("WebhookSubOptions.secret", "Webhooks only: A shared secret used to sign each request body with HMAC-SHA256. The signature is sent in the X-FireFly-Signature header as sha256=<hex>. Only the body is signed - there is no timestamp, so a captured request can be replayed and receivers should use the event ID to ignore duplicates. The secret is never returned by the API")
 	WebhooksOptQuery                    = ffm("WebhookSubOptions.query", "Webhooks only: Static query params to set on the webhook request")
 	WebhooksOptInput                    = ffm("WebhookSubOptions.input", "Webhooks only: A set of options to extract data from the first JSON input data in the incoming message. Only applies if withData=true")
 	WebhooksOptFastAck                  = ffm("WebhookSubOptions.fastack", "Webhooks only: When true the event will be acknowledged before the webhook is invoked, allowing parallel invocations")
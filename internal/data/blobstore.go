@@ -128,9 +128,11 @@ func (bs *blobStore) UploadBlob(ctx context.Context, inData *core.DataRefOrValue
 	}
 	log.L(ctx).Infof("Uploaded Blob blobhash=%s hash=%s (%s)", data.Blob.Hash, data.Hash, units.HumanSizeWithPrecision(float64(blobSize), 2))
 
+	dataID := data.ID
 	err = bs.database.RunAsGroup(ctx, func(ctx context.Context) error {
-		err := bs.database.UpsertData(ctx, data, database.UpsertOptimizationNew)
-		if err == nil {
+		// A retried upload of identical content resolves to the data that already exists
+		err := bs.database.UpsertData(ctx, data, database.UpsertOptimizationNew, true)
+		if err == nil && data.ID.Equals(dataID) {
 			err = bs.database.InsertBlob(ctx, blob)
 		}
 		return err
@@ -138,6 +140,12 @@ func (bs *blobStore) UploadBlob(ctx context.Context, inData *core.DataRefOrValue
 	if err != nil {
 		return nil, err
 	}
+	if !data.ID.Equals(dataID) {
+		// The existing data already has a blob with the same content, so the copy just uploaded is not referenced
+		if err := bs.exchange.DeleteBlob(ctx, payloadRef); err != nil {
+			log.L(ctx).Warnf("Failed to delete duplicate blob '%s' uploaded for data %s: %s", payloadRef, dataID, err)
+		}
+	}
 
 	return data, nil
 }
@@ -153,7 +161,8 @@ func (bs *blobStore) DownloadBlob(ctx context.Context, dataID string) (*core.Blo
 		return nil, nil, err
 	}
 
-	data, err := bs.database.GetDataByID(ctx, bs.dm.namespace.Name, id, false)
+	// Read the data and blob records together, so the blob cannot be removed in between
+	data, blob, err := bs.database.GetDataWithBlob(ctx, bs.dm.namespace.Name, id, false)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -163,15 +172,9 @@ func (bs *blobStore) DownloadBlob(ctx context.Context, dataID string) (*core.Blo
 	if data.Blob == nil || data.Blob.Hash == nil {
 		return nil, nil, i18n.NewError(ctx, coremsgs.MsgDataDoesNotHaveBlob)
 	}
-	fb := database.BlobQueryFactory.NewFilter(ctx)
-	blobs, _, err := bs.database.GetBlobs(ctx, bs.dm.namespace.Name, fb.And(fb.Eq("data_id", data.ID), fb.Eq("hash", data.Blob.Hash)))
-	if err != nil {
-		return nil, nil, err
-	}
-	if len(blobs) == 0 || blobs[0] == nil {
+	if blob == nil {
 		return nil, nil, i18n.NewError(ctx, coremsgs.MsgBlobNotFound, data.Blob.Hash)
 	}
-	blob := blobs[0]
 
 	reader, err := bs.exchange.DownloadBlob(ctx, blob.PayloadRef)
 	return blob, reader, err
@@ -182,16 +185,15 @@ func (bs *blobStore) DeleteBlob(ctx context.Context, blob *core.Blob) error {
 		return i18n.NewError(ctx, coremsgs.MsgActionNotSupported)
 	}
 
-	// Compatibility check: Previous versions of FireFly could have had multiple
-	// data items pointing at the same blob. We should NOT delete the blob if other
-	// data items still reference this blob! Look at the payloadRef to determine
-	// uniqueness, as of FireFly 1.2.x this will be unique per data item.
-	fb := database.BlobQueryFactory.NewFilter(ctx)
-	blobs, _, err := bs.database.GetBlobs(ctx, bs.dm.namespace.Name, fb.Eq("payloadref", blob.PayloadRef))
+	// Multiple data items can point at the same blob - from previous versions of FireFly,
+	// or because the data has been copied into other namespaces. We should NOT delete the
+	// blob if other data items still reference this blob! The number of blob records with
+	// the same payloadRef, across all namespaces, is the reference count for the payload.
+	refCount, err := bs.database.CountBlobReferences(ctx, blob.PayloadRef)
 	if err != nil {
 		return err
 	}
-	if len(blobs) <= 1 {
+	if refCount <= 1 {
 
 		err := bs.exchange.DeleteBlob(ctx, blob.PayloadRef)
 		if err != nil {
@@ -54,7 +54,7 @@ func TestUploadBlobOk(t *testing.T) {
 			a[1].(func(context.Context) error)(a[0].(context.Context)),
 		}
 	}
-	mdi.On("UpsertData", mock.Anything, mock.Anything, database.UpsertOptimizationNew).Return(nil)
+	mdi.On("UpsertData", mock.Anything, mock.Anything, database.UpsertOptimizationNew, true).Return(nil)
 	mdi.On("InsertBlob", mock.Anything, mock.Anything).Return(nil)
 
 	dxID := make(chan fftypes.UUID, 1)
@@ -84,6 +84,45 @@ func TestUploadBlobOk(t *testing.T) {
 
 }
 
+func TestUploadBlobExistingByHash(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	b := []byte(`some blob`)
+	existing := &core.Data{ID: fftypes.NewUUID(), Namespace: "ns1", Hash: fftypes.NewRandB32()}
+
+	mdi := dm.database.(*databasemocks.Plugin)
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{
+			a[1].(func(context.Context) error)(a[0].(context.Context)),
+		}
+	}
+	mdi.On("UpsertData", mock.Anything, mock.Anything, database.UpsertOptimizationNew, true).Run(func(args mock.Arguments) {
+		*args[1].(*core.Data) = *existing
+	}).Return(nil)
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	dxUpload := mdx.On("UploadBlob", ctx, "ns1", mock.Anything, mock.Anything)
+	dxUpload.RunFn = func(a mock.Arguments) {
+		_, err := ioutil.ReadAll(a[3].(io.Reader))
+		assert.Nil(t, err)
+		var hash fftypes.Bytes32 = sha256.Sum256(b)
+		dxUpload.ReturnArguments = mock.Arguments{"ns1/duplicate", &hash, int64(len(b)), err}
+	}
+	mdx.On("DeleteBlob", ctx, "ns1/duplicate").Return(fmt.Errorf("pop"))
+
+	data, err := dm.UploadBlob(ctx, &core.DataRefOrValue{}, &ffapi.Multipart{Data: bytes.NewReader(b)}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, existing.ID, data.ID)
+
+	mdi.AssertNotCalled(t, "InsertBlob", mock.Anything, mock.Anything)
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+
+}
+
 func TestUploadBlobDisabled(t *testing.T) {
 
 	dm, ctx, cancel := newTestDataManager(t)
@@ -108,7 +147,7 @@ func TestUploadBlobAutoMetaOk(t *testing.T) {
 			a[1].(func(context.Context) error)(a[0].(context.Context)),
 		}
 	}
-	mdi.On("UpsertData", mock.Anything, mock.Anything, database.UpsertOptimizationNew).Return(nil)
+	mdi.On("UpsertData", mock.Anything, mock.Anything, database.UpsertOptimizationNew, true).Return(nil)
 	mdi.On("InsertBlob", mock.Anything, mock.Anything).Return(nil)
 
 	dxID := make(chan fftypes.UUID, 1)
@@ -269,17 +308,16 @@ func TestDownloadBlobOk(t *testing.T) {
 	dataID := fftypes.NewUUID()
 
 	mdi := dm.database.(*databasemocks.Plugin)
-	mdi.On("GetDataByID", ctx, "ns1", dataID, false).Return(&core.Data{
+	mdi.On("GetDataWithBlob", ctx, "ns1", dataID, false).Return(&core.Data{
 		ID:        dataID,
 		Namespace: "ns1",
 		Blob: &core.BlobRef{
 			Hash: blobHash,
 		},
-	}, nil)
-	mdi.On("GetBlobs", ctx, "ns1", mock.Anything).Return([]*core.Blob{{
+	}, &core.Blob{
 		Hash:       blobHash,
 		PayloadRef: "ns1/blob1",
-	}}, nil, nil)
+	}, nil)
 
 	mdx := dm.exchange.(*dataexchangemocks.Plugin)
 	mdx.On("DownloadBlob", ctx, "ns1/blob1").Return(
@@ -315,43 +353,19 @@ func TestDownloadBlobNotFound(t *testing.T) {
 	dataID := fftypes.NewUUID()
 
 	mdi := dm.database.(*databasemocks.Plugin)
-	mdi.On("GetDataByID", ctx, "ns1", dataID, false).Return(&core.Data{
+	mdi.On("GetDataWithBlob", ctx, "ns1", dataID, false).Return(&core.Data{
 		ID:        dataID,
 		Namespace: "ns1",
 		Blob: &core.BlobRef{
 			Hash: blobHash,
 		},
-	}, nil)
-	mdi.On("GetBlobs", ctx, "ns1", mock.Anything).Return(nil, nil, nil)
+	}, nil, nil)
 
 	_, _, err := dm.DownloadBlob(ctx, dataID.String())
 	assert.Regexp(t, "FF10239", err)
 
 }
 
-func TestDownloadBlobLookupErr(t *testing.T) {
-
-	dm, ctx, cancel := newTestDataManager(t)
-	defer cancel()
-
-	blobHash := fftypes.NewRandB32()
-	dataID := fftypes.NewUUID()
-
-	mdi := dm.database.(*databasemocks.Plugin)
-	mdi.On("GetDataByID", ctx, "ns1", dataID, false).Return(&core.Data{
-		ID:        dataID,
-		Namespace: "ns1",
-		Blob: &core.BlobRef{
-			Hash: blobHash,
-		},
-	}, nil)
-	mdi.On("GetBlobs", ctx, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
-
-	_, _, err := dm.DownloadBlob(ctx, dataID.String())
-	assert.Regexp(t, "pop", err)
-
-}
-
 func TestDownloadBlobNoBlob(t *testing.T) {
 
 	dm, ctx, cancel := newTestDataManager(t)
@@ -360,11 +374,11 @@ func TestDownloadBlobNoBlob(t *testing.T) {
 	dataID := fftypes.NewUUID()
 
 	mdi := dm.database.(*databasemocks.Plugin)
-	mdi.On("GetDataByID", ctx, "ns1", dataID, false).Return(&core.Data{
+	mdi.On("GetDataWithBlob", ctx, "ns1", dataID, false).Return(&core.Data{
 		ID:        dataID,
 		Namespace: "ns1",
 		Blob:      &core.BlobRef{},
-	}, nil)
+	}, nil, nil)
 
 	_, _, err := dm.DownloadBlob(ctx, dataID.String())
 	assert.Regexp(t, "FF10241", err)
@@ -379,7 +393,7 @@ func TestDownloadBlobNoData(t *testing.T) {
 	dataID := fftypes.NewUUID()
 
 	mdi := dm.database.(*databasemocks.Plugin)
-	mdi.On("GetDataByID", ctx, "ns1", dataID, false).Return(nil, nil)
+	mdi.On("GetDataWithBlob", ctx, "ns1", dataID, false).Return(nil, nil, nil)
 
 	_, _, err := dm.DownloadBlob(ctx, dataID.String())
 	assert.Regexp(t, "FF10143", err)
@@ -394,7 +408,7 @@ func TestDownloadBlobDataLookupErr(t *testing.T) {
 	dataID := fftypes.NewUUID()
 
 	mdi := dm.database.(*databasemocks.Plugin)
-	mdi.On("GetDataByID", ctx, "ns1", dataID, false).Return(nil, fmt.Errorf("pop"))
+	mdi.On("GetDataWithBlob", ctx, "ns1", dataID, false).Return(nil, nil, fmt.Errorf("pop"))
 
 	_, _, err := dm.DownloadBlob(ctx, dataID.String())
 	assert.Regexp(t, "pop", err)
@@ -429,7 +443,7 @@ func TestDeleteBlob(t *testing.T) {
 		DataID:     fftypes.NewUUID(),
 	}
 
-	mdb.On("GetBlobs", ctx, "ns1", mock.Anything).Return([]*core.Blob{}, &ffapi.FilterResult{}, nil)
+	mdb.On("CountBlobReferences", ctx, "payloadref").Return(int64(1), nil)
 	mdx.On("DeleteBlob", ctx, "payloadref").Return(nil)
 	mdb.On("DeleteBlob", ctx, int64(1)).Return(nil)
 
@@ -457,7 +471,7 @@ func TestDeleteBlobFailDX(t *testing.T) {
 		DataID:     fftypes.NewUUID(),
 	}
 
-	mdb.On("GetBlobs", ctx, "ns1", mock.Anything).Return([]*core.Blob{}, &ffapi.FilterResult{}, nil)
+	mdb.On("CountBlobReferences", ctx, "payloadref").Return(int64(1), nil)
 	mdx.On("DeleteBlob", ctx, "payloadref").Return(fmt.Errorf("pop"))
 
 	err := dm.DeleteBlob(ctx, blob)
@@ -484,7 +498,7 @@ func TestDeleteBlobFailDB(t *testing.T) {
 		DataID:     fftypes.NewUUID(),
 	}
 
-	mdb.On("GetBlobs", ctx, "ns1", mock.Anything).Return([]*core.Blob{}, &ffapi.FilterResult{}, nil)
+	mdb.On("CountBlobReferences", ctx, "payloadref").Return(int64(1), nil)
 	mdx.On("DeleteBlob", ctx, "payloadref").Return(nil)
 	mdb.On("DeleteBlob", ctx, int64(1)).Return(fmt.Errorf("pop"))
 
@@ -532,7 +546,7 @@ func TestDeleteBlobBackwardCompatibility(t *testing.T) {
 		DataID:     fftypes.NewUUID(),
 	}
 
-	mdb.On("GetBlobs", ctx, "ns1", mock.Anything).Return([]*core.Blob{{}, {}}, &ffapi.FilterResult{}, nil)
+	mdb.On("CountBlobReferences", ctx, "payloadref").Return(int64(2), nil)
 	mdb.On("DeleteBlob", ctx, int64(1)).Return(nil)
 
 	err := dm.DeleteBlob(ctx, blob)
@@ -557,7 +571,7 @@ func TestDeleteBlobBackwardCompatibilityFail(t *testing.T) {
 		DataID:     fftypes.NewUUID(),
 	}
 
-	mdb.On("GetBlobs", ctx, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	mdb.On("CountBlobReferences", ctx, "payloadref").Return(int64(-1), fmt.Errorf("pop"))
 
 	err := dm.DeleteBlob(ctx, blob)
 	assert.Regexp(t, "pop", err)
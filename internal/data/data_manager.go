@@ -20,9 +20,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/url"
+	"strings"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
@@ -37,6 +40,7 @@ import (
 type Manager interface {
 	CheckDatatype(ctx context.Context, datatype *core.Datatype) error
 	ValidateAll(ctx context.Context, data core.DataArray) (valid bool, err error)
+	CheckDataAvailable(ctx context.Context, data core.DataArray) (available bool, err error)
 	GetMessageWithDataCached(ctx context.Context, msgID *fftypes.UUID, options ...CacheReadOption) (msg *core.Message, data core.DataArray, foundAllData bool, err error)
 	GetMessageDataCached(ctx context.Context, msg *core.Message, options ...CacheReadOption) (data core.DataArray, foundAll bool, err error)
 	PeekMessageCache(ctx context.Context, id *fftypes.UUID, options ...CacheReadOption) (msg *core.Message, data core.DataArray)
@@ -45,17 +49,33 @@ type Manager interface {
 	UpdateMessageStateIfCached(ctx context.Context, id *fftypes.UUID, state core.MessageState, confirmed *fftypes.FFTime, rejectReason string)
 	ResolveInlineData(ctx context.Context, msg *NewMessage) error
 	WriteNewMessage(ctx context.Context, newMsg *NewMessage) error
+	UpsertNewDataByHash(ctx context.Context, newMsg *NewMessage) error
 	BlobsEnabled() bool
+	SetExternalDataCallbacks(callbacks ExternalDataCallbacks)
 
 	UploadJSON(ctx context.Context, inData *core.DataRefOrValue) (*core.Data, error)
 	UploadBlob(ctx context.Context, inData *core.DataRefOrValue, blob *ffapi.Multipart, autoMeta bool) (*core.Data, error)
 	DownloadBlob(ctx context.Context, dataID string) (*core.Blob, io.ReadCloser, error)
 	DeleteData(ctx context.Context, dataID string) error
+	DeleteMessage(ctx context.Context, msgID string) error
+	CopyData(ctx context.Context, sourceID string, destNamespace string) (*core.Data, error)
 	HydrateBatch(ctx context.Context, persistedBatch *core.BatchPersisted) (*core.Batch, error)
 	Start()
 	WaitStop()
 }
 
+var deletableMessageStateList = []core.MessageState{
+	core.MessageStateRejected,
+	core.MessageStateCancelled,
+	core.MessageStateExpired,
+}
+
+var deletableMessageStates = map[core.MessageState]bool{
+	core.MessageStateRejected:  true,
+	core.MessageStateCancelled: true,
+	core.MessageStateExpired:   true,
+}
+
 type dataManager struct {
 	blobStore
 	namespace      *core.Namespace
@@ -63,6 +83,7 @@ type dataManager struct {
 	validatorCache cache.CInterface
 	messageCache   cache.CInterface
 	messageWriter  *messageWriter
+	externalData   *externalFetcher
 }
 
 type messageCacheEntry struct {
@@ -97,9 +118,28 @@ func NewDataManager(ctx context.Context, ns *core.Namespace, di database.Plugin,
 	if di == nil {
 		return nil, i18n.NewError(ctx, coremsgs.MsgInitializationNilDepError, "DataManager")
 	}
+	allowedHosts := make(map[string]bool)
+	for _, host := range config.GetStringSlice(coreconfig.MessageExternalDataAllowedHosts) {
+		allowedHosts[strings.ToLower(host)] = true
+	}
 	dm := &dataManager{
 		namespace: ns,
 		database:  di,
+		externalData: newExternalFetcher(ctx, ffresty.NewWithConfig(ctx, ffresty.Config{
+			HTTPConfig: ffresty.HTTPConfig{
+				HTTPRequestTimeout: fftypes.FFDuration(config.GetDuration(coreconfig.MessageExternalDataRequestTimeout)),
+			},
+		}), &externalFetcherConf{
+			allowedHosts:     allowedHosts,
+			maxSize:          config.GetByteSize(coreconfig.MessageExternalDataMaxSize),
+			maxResults:       config.GetInt(coreconfig.MessageExternalDataMaxResults),
+			workerCount:      config.GetInt(coreconfig.MessageExternalDataWorkerCount),
+			queueLength:      config.GetInt(coreconfig.MessageExternalDataWorkerQueueLength),
+			retryMaxAttempts: config.GetInt(coreconfig.MessageExternalDataRetryMaxAttempts),
+			retryInitDelay:   config.GetDuration(coreconfig.MessageExternalDataRetryInitDelay),
+			retryMaxDelay:    config.GetDuration(coreconfig.MessageExternalDataRetryMaxDelay),
+			retryFactor:      config.GetFloat64(coreconfig.MessageExternalDataRetryFactor),
+		}),
 	}
 	dm.blobStore = blobStore{
 		dm:       dm,
@@ -142,6 +182,11 @@ func NewDataManager(ctx context.Context, ns *core.Namespace, di database.Plugin,
 
 func (dm *dataManager) Start() {
 	dm.messageWriter.start()
+	dm.externalData.start()
+}
+
+func (dm *dataManager) SetExternalDataCallbacks(callbacks ExternalDataCallbacks) {
+	dm.externalData.callbacks = callbacks
 }
 
 func (dm *dataManager) BlobsEnabled() bool {
@@ -293,6 +338,9 @@ func (dm *dataManager) UpdateMessageStateIfCached(ctx context.Context, id *fftyp
 		mce.msg.State = state
 		mce.msg.Confirmed = confirmed
 		mce.msg.RejectReason = rejectReason
+		if state == core.MessageStateConfirmed || state == core.MessageStateRejected {
+			dm.externalData.releaseResults(mce.data)
+		}
 	}
 }
 
@@ -317,6 +365,12 @@ func (dm *dataManager) getMessageData(ctx context.Context, msg *core.Message) (d
 
 func (dm *dataManager) ValidateAll(ctx context.Context, data core.DataArray) (valid bool, err error) {
 	for _, d := range data {
+		if d.CalcType() == core.DataTypeReference {
+			if fetchErr := dm.externalData.fetchFailure(d); fetchErr != nil {
+				log.L(ctx).Errorf("External data %s could not be verified: %s", d.ID, fetchErr)
+				return false, nil
+			}
+		}
 		if d.Datatype != nil && d.Validator != core.ValidatorTypeNone {
 			v, err := dm.getValidatorForDatatype(ctx, d.Validator, d.Datatype)
 			if err != nil {
@@ -335,6 +389,56 @@ func (dm *dataManager) ValidateAll(ctx context.Context, data core.DataArray) (va
 	return true, nil
 }
 
+// CheckDataAvailable ensures that the payloads for all the data in the array are available.
+// Inline values are always available, blobs must have been received into the local data exchange blob store
+// (either because of a private transfer, or by downloading them from shared storage), and the content of
+// external references must have been fetched in the background and verified against the hash in the reference.
+// An external reference that failed in a way retrying will not fix is also treated as available, so that
+// ValidateAll can reject the message rather than it blocking its context.
+// The type is derived locally from the hashed content, as the type field is not covered by the data hash.
+func (dm *dataManager) CheckDataAvailable(ctx context.Context, data core.DataArray) (available bool, err error) {
+	for _, d := range data {
+		switch d.CalcType() {
+		case core.DataTypeValue:
+			continue
+		case core.DataTypeBlob:
+			available, err = dm.checkBlobAvailable(ctx, d)
+		case core.DataTypeReference:
+			available = dm.checkExternalAvailable(ctx, d)
+		}
+		if err != nil || !available {
+			// This isn't an error, we just need to wait for it to arrive.
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (dm *dataManager) checkBlobAvailable(ctx context.Context, d *core.Data) (bool, error) {
+	if d.Blob == nil || d.Blob.Hash == nil {
+		return true, nil
+	}
+	fb := database.BlobQueryFactory.NewFilter(ctx)
+	blobs, _, err := dm.database.GetBlobs(ctx, dm.namespace.Name, fb.And(fb.Eq("data_id", d.ID), fb.Eq("hash", d.Blob.Hash)))
+	if err != nil {
+		return false, err
+	}
+	if len(blobs) > 0 && blobs[0] != nil {
+		log.L(ctx).Debugf("Blob '%s' found in local DX with ref '%s'", blobs[0].Hash, blobs[0].PayloadRef)
+		return true, nil
+	}
+	log.L(ctx).Debugf("Blob '%s' not available for data %s", d.Blob.Hash, d.ID)
+	return false, nil
+}
+
+func (dm *dataManager) checkExternalAvailable(ctx context.Context, d *core.Data) bool {
+	if d.ExternalRef.URL == "" || d.ExternalRef.Hash == nil {
+		log.L(ctx).Warnf("Data %s is an external reference without a URL and hash", d.ID)
+		return false
+	}
+	return dm.externalData.checkFetched(ctx, d)
+}
+
 func (dm *dataManager) resolveRef(ctx context.Context, dataRef *core.DataRef) (*core.Data, error) {
 	if dataRef == nil || dataRef.ID == nil {
 		log.L(ctx).Warnf("data is nil")
@@ -406,6 +510,7 @@ func (dm *dataManager) validateInputData(ctx context.Context, inData *core.DataR
 	datatype := inData.Datatype
 	value := inData.Value
 	blobRef := inData.Blob
+	externalRef := inData.ExternalRef
 
 	if err := dm.checkValidation(ctx, validator, datatype, value); err != nil {
 		return nil, err
@@ -416,13 +521,18 @@ func (dm *dataManager) validateInputData(ctx context.Context, inData *core.DataR
 		return nil, err
 	}
 
+	if err := dm.checkExternalRef(ctx, blobRef, externalRef); err != nil {
+		return nil, err
+	}
+
 	// Ok, we're good to generate the full data payload and save it
 	data = &core.Data{
-		Validator: validator,
-		Datatype:  datatype,
-		Namespace: dm.namespace.Name,
-		Value:     value,
-		Blob:      blobRef,
+		Validator:   validator,
+		Datatype:    datatype,
+		Namespace:   dm.namespace.Name,
+		Value:       value,
+		Blob:        blobRef,
+		ExternalRef: externalRef,
 	}
 	err = data.Seal(ctx, blob)
 	if err != nil {
@@ -431,12 +541,30 @@ func (dm *dataManager) validateInputData(ctx context.Context, inData *core.DataR
 	return data, nil
 }
 
+func (dm *dataManager) checkExternalRef(ctx context.Context, blobRef *core.BlobRef, externalRef *core.ExternalDataRef) error {
+	if externalRef == nil {
+		return nil
+	}
+	if blobRef != nil {
+		return i18n.NewError(ctx, coremsgs.MsgInvalidExternalDataRef, "cannot be combined with a blob")
+	}
+	u, err := url.Parse(externalRef.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return i18n.NewError(ctx, coremsgs.MsgInvalidExternalDataRef, "url must be an absolute http or https URL")
+	}
+	if externalRef.Hash == nil {
+		return i18n.NewError(ctx, coremsgs.MsgInvalidExternalDataRef, "hash is required")
+	}
+	return dm.externalData.checkHostAllowed(ctx, u)
+}
+
 func (dm *dataManager) UploadJSON(ctx context.Context, inData *core.DataRefOrValue) (*core.Data, error) {
 	data, err := dm.validateInputData(ctx, inData)
 	if err != nil {
 		return nil, err
 	}
-	if err = dm.messageWriter.WriteData(ctx, data); err != nil {
+	// A retried upload of identical content resolves to the data that already exists
+	if err = dm.database.UpsertData(ctx, data, database.UpsertOptimizationNew, true); err != nil {
 		return nil, err
 	}
 	return data, err
@@ -486,6 +614,28 @@ func (dm *dataManager) ResolveInlineData(ctx context.Context, newMessage *NewMes
 	return nil
 }
 
+// UpsertNewDataByHash writes the new data of a message ahead of the message, resolving any data with
+// the same content as data that already exists to the existing record, and updates the data references
+// of the message to match. The message must not have been sealed yet, as the references are part of its hash.
+// Data that is duplicated within the message itself is written as separate records, so each reference is unique.
+func (dm *dataManager) UpsertNewDataByHash(ctx context.Context, newMsg *NewMessage) error {
+	hashCount := make(map[fftypes.Bytes32]int)
+	for _, d := range newMsg.AllData {
+		if d.Hash != nil {
+			hashCount[*d.Hash]++
+		}
+	}
+	for _, d := range newMsg.NewData {
+		allowExisting := d.Hash != nil && hashCount[*d.Hash] == 1
+		if err := dm.database.UpsertData(ctx, d, database.UpsertOptimizationNew, allowExisting); err != nil {
+			return err
+		}
+	}
+	newMsg.NewData = nil
+	newMsg.Message.Data = newMsg.AllData.Refs()
+	return nil
+}
+
 // HydrateBatch fetches the full messages for a persisted batch, ready for transmission
 func (dm *dataManager) HydrateBatch(ctx context.Context, persistedBatch *core.BatchPersisted) (*core.Batch, error) {
 
@@ -539,8 +689,68 @@ func (dm *dataManager) WriteNewMessage(ctx context.Context, newMsg *NewMessage)
 	return nil
 }
 
+// CopyData creates a new data record in another namespace, with the same content as the source data.
+// Blob bytes are not copied - the new data gets its own blob record pointing at the same payload,
+// which counts as an additional reference to the payload when blobs are deleted.
+func (dm *dataManager) CopyData(ctx context.Context, sourceID string, destNamespace string) (*core.Data, error) {
+	id, err := fftypes.ParseUUID(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := fftypes.ValidateFFNameField(ctx, destNamespace, "namespace"); err != nil {
+		return nil, err
+	}
+	if destNamespace == dm.namespace.Name {
+		return nil, i18n.NewError(ctx, coremsgs.MsgCopyDataSameNamespace, destNamespace)
+	}
+
+	source, sourceBlob, err := dm.database.GetDataWithBlob(ctx, dm.namespace.Name, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, i18n.NewError(ctx, coremsgs.Msg404NoResult)
+	}
+	if source.Blob != nil && source.Blob.Hash != nil && sourceBlob == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgBlobNotFound, source.Blob.Hash)
+	}
+
+	data := *source
+	data.ID = fftypes.NewUUID()
+	data.Namespace = destNamespace
+	data.Created = fftypes.Now()
+	if source.Blob != nil {
+		blobRef := *source.Blob
+		data.Blob = &blobRef
+	}
+
+	err = dm.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		if err := dm.database.UpsertData(ctx, &data, database.UpsertOptimizationNew, false); err != nil {
+			return err
+		}
+		if sourceBlob == nil {
+			return nil
+		}
+		return dm.database.InsertBlob(ctx, &core.Blob{
+			Namespace:  destNamespace,
+			Hash:       sourceBlob.Hash,
+			Size:       sourceBlob.Size,
+			PayloadRef: sourceBlob.PayloadRef,
+			Peer:       sourceBlob.Peer,
+			Created:    data.Created,
+			DataID:     data.ID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.L(ctx).Infof("Copied data %s to namespace '%s' as %s", source.ID, destNamespace, data.ID)
+	return &data, nil
+}
+
 func (dm *dataManager) WaitStop() {
 	dm.messageWriter.close()
+	dm.externalData.close()
 }
 
 func (dm *dataManager) DeleteData(ctx context.Context, dataID string) error {
@@ -583,3 +793,37 @@ func (dm *dataManager) DeleteData(ctx context.Context, dataID string) error {
 
 	return dm.database.DeleteData(ctx, data.Namespace, data.ID)
 }
+
+func (dm *dataManager) DeleteMessage(ctx context.Context, msgID string) error {
+	id, err := fftypes.ParseUUID(ctx, msgID)
+	if err != nil {
+		return err
+	}
+
+	err = dm.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		msg, err := dm.database.GetMessageByID(ctx, dm.namespace.Name, id)
+		if err != nil {
+			return err
+		}
+		if msg == nil {
+			return i18n.NewError(ctx, coremsgs.Msg404NoResult)
+		}
+		// Only messages that can never progress further are deleted. Confirmed messages are part of the
+		// ordered record shared with the network, and in-flight messages are still owned by the batch processors.
+		if !deletableMessageStates[msg.State] {
+			return i18n.NewError(ctx, coremsgs.MsgMessageNotDeletable, msg.Header.ID, msg.State)
+		}
+		err = dm.database.DeleteMessage(ctx, dm.namespace.Name, msg.Header.ID, deletableMessageStateList)
+		if err == fftypes.DeleteRecordNotFound {
+			// The state changed between the read and the delete
+			return i18n.NewError(ctx, coremsgs.MsgMessageNotDeletable, msg.Header.ID, msg.State)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	dm.messageCache.Set(id.String(), nil)
+	return nil
+}
@@ -18,8 +18,12 @@ package data
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
@@ -202,7 +206,7 @@ func TestWriteNewMessageE2E(t *testing.T) {
 		err := args[1].(func(context.Context) error)(ctx)
 		assert.NoError(t, err)
 	}).Return(nil)
-	mdi.On("InsertDataArray", mock.Anything, mock.Anything).Return(nil).Once()
+	mdi.On("UpsertData", mock.Anything, mock.Anything, database.UpsertOptimizationNew, true).Return(nil).Once()
 
 	data1, err := dm.UploadJSON(ctx, &core.DataRefOrValue{
 		Value:     fftypes.JSONAnyPtr(`"message 1 - data A"`),
@@ -585,7 +589,7 @@ func TestResolveInlineDataValueNoValidatorOK(t *testing.T) {
 	defer cancel()
 	mdi := dm.database.(*databasemocks.Plugin)
 
-	mdi.On("UpsertData", ctx, mock.Anything, database.UpsertOptimizationNew).Return(nil)
+	mdi.On("UpsertData", ctx, mock.Anything, database.UpsertOptimizationNew, false).Return(nil)
 
 	_, _, newMsg := testNewMessage()
 	newMsg.Message.InlineData = core.InlineData{
@@ -606,7 +610,7 @@ func TestResolveInlineDataValueWithValidation(t *testing.T) {
 	defer cancel()
 	mdi := dm.database.(*databasemocks.Plugin)
 
-	mdi.On("UpsertData", ctx, mock.Anything, database.UpsertOptimizationNew).Return(nil)
+	mdi.On("UpsertData", ctx, mock.Anything, database.UpsertOptimizationNew, false).Return(nil)
 	mdi.On("GetDatatypeByName", ctx, "ns1", "customer", "0.0.1").Return(&core.Datatype{
 		ID:        fftypes.NewUUID(),
 		Validator: core.ValidatorTypeJSON,
@@ -654,6 +658,29 @@ func TestResolveInlineDataValueWithValidation(t *testing.T) {
 	assert.Regexp(t, "FF10198", err)
 }
 
+func TestResolveInlineDataNonJSONContentStillValidated(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypeByName", ctx, "ns1", "customer", "0.0.1").Return(nil, nil)
+
+	_, _, newMsg := testNewMessage()
+	newMsg.Message.Header.ContentType = "application/octet-stream"
+	newMsg.Message.InlineData = core.InlineData{
+		{
+			Datatype: &core.DatatypeRef{
+				Name:    "customer",
+				Version: "0.0.1",
+			},
+			Value: fftypes.JSONAnyPtr(`"aGVsbG8="`),
+		},
+	}
+
+	err := dm.ResolveInlineData(ctx, newMsg)
+	assert.Regexp(t, "FF10195", err)
+	mdi.AssertExpectations(t)
+}
+
 func TestResolveInlineDataNoRefOrValue(t *testing.T) {
 	dm, ctx, cancel := newTestDataManager(t)
 	defer cancel()
@@ -685,11 +712,73 @@ func TestUploadJSONLoadDatatypeFail(t *testing.T) {
 func TestUploadJSONLoadInsertDataFail(t *testing.T) {
 	dm, ctx, cancel := newTestDataManager(t)
 	defer cancel()
-	dm.messageWriter.close()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("UpsertData", ctx, mock.Anything, database.UpsertOptimizationNew, true).Return(fmt.Errorf("pop"))
 	_, err := dm.UploadJSON(ctx, &core.DataRefOrValue{
 		Value: fftypes.JSONAnyPtr(`{}`),
 	})
-	assert.Regexp(t, "FF00154", err)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestUploadJSONExistingByHash(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	existing := &core.Data{ID: fftypes.NewUUID(), Namespace: "ns1", Hash: fftypes.NewRandB32()}
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("UpsertData", ctx, mock.Anything, database.UpsertOptimizationNew, true).Run(func(args mock.Arguments) {
+		*args[1].(*core.Data) = *existing
+	}).Return(nil)
+	data, err := dm.UploadJSON(ctx, &core.DataRefOrValue{
+		Value: fftypes.JSONAnyPtr(`{}`),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, existing.ID, data.ID)
+}
+
+func TestUpsertNewDataByHash(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	_, _, newMsg := testNewMessage()
+	newMsg.Message.InlineData = core.InlineData{
+		{Value: fftypes.JSONAnyPtr(`"unique"`)},
+		{Value: fftypes.JSONAnyPtr(`"repeated"`)},
+		{Value: fftypes.JSONAnyPtr(`"repeated"`)},
+	}
+	err := dm.ResolveInlineData(ctx, newMsg)
+	assert.NoError(t, err)
+
+	existing := &core.Data{ID: fftypes.NewUUID(), Namespace: "ns1", Hash: newMsg.AllData[0].Hash}
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("UpsertData", ctx, newMsg.AllData[0], database.UpsertOptimizationNew, true).Run(func(args mock.Arguments) {
+		*args[1].(*core.Data) = *existing
+	}).Return(nil).Once()
+	mdi.On("UpsertData", ctx, mock.Anything, database.UpsertOptimizationNew, false).Return(nil).Twice()
+
+	err = dm.UpsertNewDataByHash(ctx, newMsg)
+	assert.NoError(t, err)
+	assert.Empty(t, newMsg.NewData)
+	assert.Equal(t, existing.ID, newMsg.Message.Data[0].ID)
+	assert.NotEqual(t, newMsg.Message.Data[1].ID, newMsg.Message.Data[2].ID)
+	mdi.AssertExpectations(t)
+}
+
+func TestUpsertNewDataByHashFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	_, _, newMsg := testNewMessage()
+	newMsg.Message.InlineData = core.InlineData{
+		{Value: fftypes.JSONAnyPtr(`"value"`)},
+	}
+	err := dm.ResolveInlineData(ctx, newMsg)
+	assert.NoError(t, err)
+
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("UpsertData", ctx, mock.Anything, database.UpsertOptimizationNew, true).Return(fmt.Errorf("pop"))
+
+	err = dm.UpsertNewDataByHash(ctx, newMsg)
+	assert.EqualError(t, err, "pop")
 }
 
 func TestValidateAndStoreLoadNilRef(t *testing.T) {
@@ -1202,6 +1291,7 @@ func TestDeleteData(t *testing.T) {
 
 	mdb.On("GetDataByID", ctx, dm.namespace.Name, dataID, false).Return(data, nil)
 	mdb.On("GetBlobs", ctx, mock.Anything, mock.Anything).Return([]*core.Blob{blob}, &ffapi.FilterResult{}, nil)
+	mdb.On("CountBlobReferences", ctx, payloadRef).Return(int64(1), nil)
 	mdx.On("DeleteBlob", ctx, payloadRef).Return(nil)
 	mdb.On("DeleteBlob", ctx, int64(0)).Return(nil)
 	mdb.On("GetMessagesForData", ctx, dm.namespace.Name, dataID, mock.Anything).Return([]*core.Message{
@@ -1308,6 +1398,7 @@ func TestDeleteDataFailDeleteBlob(t *testing.T) {
 
 	mdb.On("GetDataByID", ctx, dm.namespace.Name, dataID, false).Return(data, nil)
 	mdb.On("GetBlobs", ctx, mock.Anything, mock.Anything).Return([]*core.Blob{blob}, &ffapi.FilterResult{}, nil)
+	mdb.On("CountBlobReferences", ctx, payloadRef).Return(int64(1), nil)
 	mdx.On("DeleteBlob", ctx, payloadRef).Return(nil)
 	mdb.On("DeleteBlob", ctx, int64(0)).Return(fmt.Errorf("pop"))
 
@@ -1370,6 +1461,7 @@ func TestDeleteDataFailGetMessages(t *testing.T) {
 
 	mdb.On("GetDataByID", ctx, dm.namespace.Name, dataID, false).Return(data, nil)
 	mdb.On("GetBlobs", ctx, mock.Anything, mock.Anything).Return([]*core.Blob{blob}, &ffapi.FilterResult{}, nil)
+	mdb.On("CountBlobReferences", ctx, payloadRef).Return(int64(1), nil)
 	mdx.On("DeleteBlob", ctx, payloadRef).Return(nil)
 	mdb.On("DeleteBlob", ctx, int64(0)).Return(nil)
 	mdb.On("GetMessagesForData", ctx, dm.namespace.Name, dataID, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
@@ -1379,3 +1471,548 @@ func TestDeleteDataFailGetMessages(t *testing.T) {
 	assert.Regexp(t, "pop", err)
 	mdb.AssertExpectations(t)
 }
+
+func TestCheckDataAvailableValue(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	available, err := dm.CheckDataAvailable(ctx, core.DataArray{
+		{ID: fftypes.NewUUID(), Blob: &core.BlobRef{}},
+		{ID: fftypes.NewUUID(), Type: core.DataTypeValue, Value: fftypes.JSONAnyPtr(`"test"`)},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, available)
+}
+
+func TestCheckDataAvailableBlobNoHash(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	available, err := dm.CheckDataAvailable(ctx, core.DataArray{
+		{ID: fftypes.NewUUID(), Type: core.DataTypeBlob},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, available)
+}
+
+func TestCheckDataAvailableBlobErrorGettingHash(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetBlobs", ctx, "ns1", mock.Anything).Return([]*core.Blob{}, nil, fmt.Errorf("pop"))
+
+	available, err := dm.CheckDataAvailable(ctx, core.DataArray{
+		{ID: fftypes.NewUUID(), Blob: &core.BlobRef{
+			Hash: fftypes.NewRandB32(),
+		}},
+	})
+
+	assert.EqualError(t, err, "pop")
+	assert.False(t, available)
+}
+
+func TestCheckDataAvailableBlobNotFound(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetBlobs", ctx, "ns1", mock.Anything).Return([]*core.Blob{}, nil, nil)
+
+	available, err := dm.CheckDataAvailable(ctx, core.DataArray{
+		{ID: fftypes.NewUUID(), Blob: &core.BlobRef{
+			Hash: fftypes.NewRandB32(),
+		}},
+	})
+
+	assert.NoError(t, err)
+	assert.False(t, available)
+}
+
+func TestCheckDataAvailableBlobFound(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetBlobs", ctx, "ns1", mock.Anything).Return([]*core.Blob{{}}, nil, nil)
+
+	available, err := dm.CheckDataAvailable(ctx, core.DataArray{
+		{ID: fftypes.NewUUID(), Type: core.DataTypeBlob, Blob: &core.BlobRef{
+			Hash: fftypes.NewRandB32(),
+		}},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, available)
+}
+
+func TestCheckDataAvailableReferenceNoURL(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	available, err := dm.CheckDataAvailable(ctx, core.DataArray{
+		{ID: fftypes.NewUUID(), Type: core.DataTypeReference, ExternalRef: &core.ExternalDataRef{}},
+	})
+
+	assert.NoError(t, err)
+	assert.False(t, available)
+}
+
+func TestCheckDataAvailableIgnoresWireType(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetBlobs", ctx, "ns1", mock.Anything).Return([]*core.Blob{}, nil, nil)
+
+	available, err := dm.CheckDataAvailable(ctx, core.DataArray{
+		{ID: fftypes.NewUUID(), Type: core.DataTypeValue, Blob: &core.BlobRef{
+			Hash: fftypes.NewRandB32(),
+		}},
+	})
+
+	assert.NoError(t, err)
+	assert.False(t, available)
+}
+
+type testExternalDataCallbacks struct {
+	verified chan *fftypes.UUID
+	failed   chan *fftypes.UUID
+}
+
+func (cb *testExternalDataCallbacks) ExternalDataVerified(dataID *fftypes.UUID) {
+	cb.verified <- dataID
+}
+
+func (cb *testExternalDataCallbacks) ExternalDataFailed(dataID *fftypes.UUID) {
+	cb.failed <- dataID
+}
+
+func TestCheckDataAvailableReferenceFetched(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	content := []byte(`some external content`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/data/1", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		w.Write(content)
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	dm.externalData.conf.allowedHosts = map[string]bool{u.Host: true}
+	cb := &testExternalDataCallbacks{verified: make(chan *fftypes.UUID, 1)}
+	dm.SetExternalDataCallbacks(cb)
+
+	hash := fftypes.Bytes32(sha256.Sum256(content))
+	data := core.DataArray{
+		{ID: fftypes.NewUUID(), ExternalRef: &core.ExternalDataRef{
+			URL:  server.URL + "/data/1",
+			Hash: &hash,
+		}},
+	}
+
+	available, err := dm.CheckDataAvailable(ctx, data)
+	assert.NoError(t, err)
+	assert.False(t, available)
+
+	assert.Equal(t, data[0].ID, <-cb.verified)
+
+	available, err = dm.CheckDataAvailable(ctx, data)
+	assert.NoError(t, err)
+	assert.True(t, available)
+
+	valid, err := dm.ValidateAll(ctx, data)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestCheckDataAvailableReferenceHashMismatchRejected(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`tampered content`))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	dm.externalData.conf.allowedHosts = map[string]bool{u.Host: true}
+	cb := &testExternalDataCallbacks{failed: make(chan *fftypes.UUID, 1)}
+	dm.SetExternalDataCallbacks(cb)
+
+	data := core.DataArray{
+		{ID: fftypes.NewUUID(), ExternalRef: &core.ExternalDataRef{
+			URL:  server.URL + "/data/1",
+			Hash: fftypes.NewRandB32(),
+		}},
+	}
+
+	available, err := dm.CheckDataAvailable(ctx, data)
+	assert.NoError(t, err)
+	assert.False(t, available)
+
+	assert.Equal(t, data[0].ID, <-cb.failed)
+
+	// The failure does not block the message - it is reported by validation, so the message is rejected
+	available, err = dm.CheckDataAvailable(ctx, data)
+	assert.NoError(t, err)
+	assert.True(t, available)
+
+	valid, err := dm.ValidateAll(ctx, data)
+	assert.NoError(t, err)
+	assert.False(t, valid)
+}
+
+func TestUpdateMessageStateIfCachedReleasesExternalData(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	d := &core.Data{ID: fftypes.NewUUID(), ExternalRef: &core.ExternalDataRef{Hash: fftypes.NewRandB32()}}
+	dm.externalData.recordResult(&externalFetchResult{dataID: *d.ID, hash: d.ExternalRef.Hash})
+	msg := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	dm.UpdateMessageCache(msg, core.DataArray{d})
+
+	dm.UpdateMessageStateIfCached(ctx, msg.Header.ID, core.MessageStatePending, nil, "")
+	assert.Len(t, dm.externalData.results, 1)
+
+	dm.UpdateMessageStateIfCached(ctx, msg.Header.ID, core.MessageStateConfirmed, fftypes.Now(), "")
+	assert.Empty(t, dm.externalData.results)
+}
+
+func TestValidateInputDataExternalRef(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.externalData.conf.allowedHosts = map[string]bool{"example.com": true}
+
+	extHash := fftypes.NewRandB32()
+	data, err := dm.validateInputData(ctx, &core.DataRefOrValue{
+		ExternalRef: &core.ExternalDataRef{
+			URL:  "https://example.com/data/1",
+			Hash: extHash,
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, core.DataTypeReference, data.Type)
+	assert.Equal(t, extHash, data.Hash)
+	assert.Nil(t, data.Blob)
+}
+
+func TestValidateInputDataExternalRefHostNotAllowed(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	_, err := dm.validateInputData(ctx, &core.DataRefOrValue{
+		ExternalRef: &core.ExternalDataRef{
+			URL:  "http://169.254.169.254/latest/meta-data",
+			Hash: fftypes.NewRandB32(),
+		},
+	})
+	assert.Regexp(t, "FF10470.*not in the allowed list", err)
+}
+
+func TestValidateInputDataExternalRefNoHash(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.externalData.conf.allowedHosts = map[string]bool{"example.com": true}
+
+	_, err := dm.validateInputData(ctx, &core.DataRefOrValue{
+		ExternalRef: &core.ExternalDataRef{
+			URL: "https://example.com/data/1",
+		},
+	})
+	assert.Regexp(t, "FF10470.*hash is required", err)
+}
+
+func TestValidateInputDataExternalRefBadURL(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	_, err := dm.validateInputData(ctx, &core.DataRefOrValue{
+		ExternalRef: &core.ExternalDataRef{
+			URL:  "file:///etc/data",
+			Hash: fftypes.NewRandB32(),
+		},
+	})
+	assert.Regexp(t, "FF10470", err)
+}
+
+func TestValidateInputDataExternalRefWithBlob(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	blobHash := fftypes.NewRandB32()
+	mdi.On("GetBlobs", ctx, "ns1", mock.Anything).Return([]*core.Blob{{Hash: blobHash}}, nil, nil)
+
+	_, err := dm.validateInputData(ctx, &core.DataRefOrValue{
+		Blob: &core.BlobRef{Hash: blobHash},
+		ExternalRef: &core.ExternalDataRef{
+			URL: "https://example.com/data/1",
+		},
+	})
+	assert.Regexp(t, "FF10470", err)
+}
+
+func TestCopyDataValue(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	source := &core.Data{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Hash:      fftypes.NewRandB32(),
+		Value:     fftypes.JSONAnyPtr(`{"some":"data"}`),
+		Type:      core.DataTypeValue,
+	}
+	mdi.On("GetDataWithBlob", ctx, "ns1", source.ID, true).Return(source, nil, nil)
+	mdi.On("RunAsGroup", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		args[1].(func(context.Context) error)(ctx)
+	}).Return(nil)
+	mdi.On("UpsertData", ctx, mock.MatchedBy(func(d *core.Data) bool {
+		return d.Namespace == "ns2" && !d.ID.Equals(source.ID) && d.Hash.Equals(source.Hash)
+	}), database.UpsertOptimizationNew, false).Return(nil)
+
+	copied, err := dm.CopyData(ctx, source.ID.String(), "ns2")
+	assert.NoError(t, err)
+	assert.Equal(t, "ns2", copied.Namespace)
+	assert.Equal(t, "ns1", source.Namespace)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestCopyDataBlob(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	blobHash := fftypes.NewRandB32()
+	source := &core.Data{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Hash:      blobHash,
+		Blob:      &core.BlobRef{Hash: blobHash, Size: 12345},
+		Type:      core.DataTypeBlob,
+	}
+	mdi.On("GetDataWithBlob", ctx, "ns1", source.ID, true).Return(source, &core.Blob{
+		Namespace:  "ns1",
+		Hash:       blobHash,
+		Size:       12345,
+		PayloadRef: "ns1/blob1",
+		DataID:     source.ID,
+	}, nil)
+	mdi.On("RunAsGroup", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		args[1].(func(context.Context) error)(ctx)
+	}).Return(nil)
+	mdi.On("UpsertData", ctx, mock.Anything, database.UpsertOptimizationNew, false).Return(nil)
+	mdi.On("InsertBlob", ctx, mock.MatchedBy(func(b *core.Blob) bool {
+		return b.Namespace == "ns2" && b.PayloadRef == "ns1/blob1" && !b.DataID.Equals(source.ID)
+	})).Return(nil)
+
+	copied, err := dm.CopyData(ctx, source.ID.String(), "ns2")
+	assert.NoError(t, err)
+	assert.Equal(t, "ns2", copied.Namespace)
+	assert.NotSame(t, source.Blob, copied.Blob)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestCopyDataBadID(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	_, err := dm.CopyData(ctx, "!uuid", "ns2")
+	assert.Regexp(t, "FF00138", err)
+}
+
+func TestCopyDataBadNamespace(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	_, err := dm.CopyData(ctx, fftypes.NewUUID().String(), "!bad")
+	assert.Regexp(t, "FF00140", err)
+}
+
+func TestCopyDataSameNamespace(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	_, err := dm.CopyData(ctx, fftypes.NewUUID().String(), "ns1")
+	assert.Regexp(t, "FF10471", err)
+}
+
+func TestCopyDataGetDataFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetDataWithBlob", ctx, "ns1", mock.Anything, true).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := dm.CopyData(ctx, fftypes.NewUUID().String(), "ns2")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCopyDataNotFound(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetDataWithBlob", ctx, "ns1", mock.Anything, true).Return(nil, nil, nil)
+
+	_, err := dm.CopyData(ctx, fftypes.NewUUID().String(), "ns2")
+	assert.Regexp(t, "FF10143", err)
+}
+
+func TestCopyDataBlobNotFound(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetDataWithBlob", ctx, "ns1", mock.Anything, true).Return(&core.Data{
+		ID:   fftypes.NewUUID(),
+		Blob: &core.BlobRef{Hash: fftypes.NewRandB32()},
+	}, nil, nil)
+
+	_, err := dm.CopyData(ctx, fftypes.NewUUID().String(), "ns2")
+	assert.Regexp(t, "FF10239", err)
+}
+
+func TestCopyDataUpsertFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetDataWithBlob", ctx, "ns1", mock.Anything, true).Return(&core.Data{
+		ID:    fftypes.NewUUID(),
+		Value: fftypes.JSONAnyPtr(`"test"`),
+	}, nil, nil)
+	mdi.On("RunAsGroup", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		args[1].(func(context.Context) error)(ctx)
+	}).Return(fmt.Errorf("pop"))
+	mdi.On("UpsertData", ctx, mock.Anything, database.UpsertOptimizationNew, false).Return(fmt.Errorf("pop"))
+
+	_, err := dm.CopyData(ctx, fftypes.NewUUID().String(), "ns2")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDeleteMessage(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdb := dm.database.(*databasemocks.Plugin)
+
+	msgID := fftypes.NewUUID()
+	msg := &core.Message{
+		Header: core.MessageHeader{
+			ID: msgID,
+		},
+		State: core.MessageStateRejected,
+	}
+	dm.UpdateMessageCache(msg, core.DataArray{})
+
+	mdb.On("RunAsGroup", ctx, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	mdb.On("GetMessageByID", ctx, dm.namespace.Name, msgID).Return(msg, nil)
+	mdb.On("DeleteMessage", ctx, dm.namespace.Name, msgID, deletableMessageStateList).Return(nil)
+
+	err := dm.DeleteMessage(ctx, msgID.String())
+	assert.NoError(t, err)
+
+	cached, _ := dm.PeekMessageCache(ctx, msgID)
+	assert.Nil(t, cached)
+	mdb.AssertExpectations(t)
+}
+
+func TestDeleteMessageFailParseUUID(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	err := dm.DeleteMessage(ctx, "NOT_A_UUID")
+	assert.Regexp(t, "FF00138", err)
+}
+
+func TestDeleteMessageFailGetMessage(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdb := dm.database.(*databasemocks.Plugin)
+
+	msgID := fftypes.NewUUID()
+	mdb.On("RunAsGroup", ctx, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	mdb.On("GetMessageByID", ctx, dm.namespace.Name, msgID).Return(nil, fmt.Errorf("pop"))
+
+	err := dm.DeleteMessage(ctx, msgID.String())
+	assert.Regexp(t, "pop", err)
+	mdb.AssertExpectations(t)
+}
+
+func TestDeleteMessageNotFound(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdb := dm.database.(*databasemocks.Plugin)
+
+	msgID := fftypes.NewUUID()
+	mdb.On("RunAsGroup", ctx, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	mdb.On("GetMessageByID", ctx, dm.namespace.Name, msgID).Return(nil, nil)
+
+	err := dm.DeleteMessage(ctx, msgID.String())
+	assert.Regexp(t, "FF10143", err)
+	mdb.AssertExpectations(t)
+}
+
+func TestDeleteMessageNotTerminal(t *testing.T) {
+	for _, state := range []core.MessageState{
+		core.MessageStateStaged,
+		core.MessageStateReady,
+		core.MessageStateSent,
+		core.MessageStatePending,
+		core.MessageStateConfirmed,
+	} {
+		dm, ctx, cancel := newTestDataManager(t)
+		mdb := dm.database.(*databasemocks.Plugin)
+
+		msgID := fftypes.NewUUID()
+		mdb.On("RunAsGroup", ctx, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+		mdb.On("GetMessageByID", ctx, dm.namespace.Name, msgID).Return(&core.Message{
+			Header: core.MessageHeader{
+				ID: msgID,
+			},
+			State: state,
+		}, nil)
+
+		err := dm.DeleteMessage(ctx, msgID.String())
+		assert.Regexp(t, "FF10474", err)
+		mdb.AssertExpectations(t)
+		cancel()
+	}
+}
+
+func TestDeleteMessageStateChanged(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdb := dm.database.(*databasemocks.Plugin)
+
+	msgID := fftypes.NewUUID()
+	mdb.On("RunAsGroup", ctx, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+	mdb.On("GetMessageByID", ctx, dm.namespace.Name, msgID).Return(&core.Message{
+		Header: core.MessageHeader{
+			ID: msgID,
+		},
+		State: core.MessageStateExpired,
+	}, nil)
+	mdb.On("DeleteMessage", ctx, dm.namespace.Name, msgID, deletableMessageStateList).Return(fftypes.DeleteRecordNotFound)
+
+	err := dm.DeleteMessage(ctx, msgID.String())
+	assert.Regexp(t, "FF10474", err)
+	mdb.AssertExpectations(t)
+}
@@ -0,0 +1,315 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// ExternalDataCallbacks is notified when the content of an external data reference has been fetched and verified,
+// or has failed in a way that retrying will not fix
+type ExternalDataCallbacks interface {
+	ExternalDataVerified(dataID *fftypes.UUID)
+	ExternalDataFailed(dataID *fftypes.UUID)
+}
+
+type externalFetchWork struct {
+	dataID   fftypes.UUID
+	ref      core.ExternalDataRef
+	attempts int
+}
+
+// externalFetchResult records the outcome of fetching the content of a reference. The error is set
+// if the content could not be verified, and retrying will not fix it
+type externalFetchResult struct {
+	dataID fftypes.UUID
+	hash   *fftypes.Bytes32
+	err    error
+}
+
+type externalFetcherConf struct {
+	allowedHosts     map[string]bool
+	maxSize          int64
+	maxResults       int
+	workerCount      int
+	queueLength      int
+	retryMaxAttempts int
+	retryInitDelay   time.Duration
+	retryMaxDelay    time.Duration
+	retryFactor      float64
+}
+
+// externalFetcher downloads the content of external data references in the background, and checks it
+// against the hash in the reference. The aggregator never blocks on the network - it asks for a fetch,
+// treats the data as unavailable, and is notified through the callbacks once the content is verified.
+//
+// Results are held in memory until the message that refers to the data is confirmed or rejected, up to
+// a maximum number of results - the oldest are discarded first. After a restart, or if a result has been
+// discarded, the content is fetched again the next time a message that refers to it is processed.
+type externalFetcher struct {
+	ctx         context.Context
+	cancelFunc  func()
+	client      *resty.Client
+	conf        *externalFetcherConf
+	callbacks   ExternalDataCallbacks
+	work        chan *externalFetchWork
+	workersDone []chan struct{}
+	mux         sync.Mutex
+	inflight    map[fftypes.UUID]bool
+	results     map[fftypes.UUID]*list.Element
+	resultOrder *list.List
+}
+
+func newExternalFetcher(ctx context.Context, client *resty.Client, conf *externalFetcherConf) *externalFetcher {
+	if conf.workerCount <= 0 {
+		conf.workerCount = 1
+	}
+	if conf.retryMaxAttempts <= 0 {
+		conf.retryMaxAttempts = 1
+	}
+	if conf.maxResults <= 0 {
+		conf.maxResults = 1
+	}
+	// Following a redirect would allow a reference to reach a host that is not in the allow-list
+	client.SetRedirectPolicy(resty.NoRedirectPolicy())
+	ef := &externalFetcher{
+		client:      client,
+		conf:        conf,
+		work:        make(chan *externalFetchWork, conf.queueLength),
+		inflight:    make(map[fftypes.UUID]bool),
+		results:     make(map[fftypes.UUID]*list.Element),
+		resultOrder: list.New(),
+	}
+	ef.ctx, ef.cancelFunc = context.WithCancel(ctx)
+	return ef
+}
+
+func (ef *externalFetcher) start() {
+	ef.workersDone = make([]chan struct{}, ef.conf.workerCount)
+	for i := 0; i < ef.conf.workerCount; i++ {
+		ef.workersDone[i] = make(chan struct{})
+		go ef.fetchWorkerLoop(i, ef.workersDone[i])
+	}
+}
+
+func (ef *externalFetcher) close() {
+	ef.cancelFunc()
+	for _, workerDone := range ef.workersDone {
+		<-workerDone
+	}
+}
+
+func (ef *externalFetcher) checkHostAllowed(ctx context.Context, u *url.URL) error {
+	if !ef.conf.allowedHosts[strings.ToLower(u.Host)] && !ef.conf.allowedHosts[strings.ToLower(u.Hostname())] {
+		return i18n.NewError(ctx, coremsgs.MsgInvalidExternalDataRef, fmt.Sprintf("host '%s' is not in the allowed list", u.Host))
+	}
+	return nil
+}
+
+// getResult returns the result for the data, if there is one for the hash in its reference. Must be called with the lock held
+func (ef *externalFetcher) getResult(d *core.Data) *externalFetchResult {
+	if elem, ok := ef.results[*d.ID]; ok {
+		if result := elem.Value.(*externalFetchResult); result.hash.Equals(d.ExternalRef.Hash) {
+			return result
+		}
+	}
+	return nil
+}
+
+func (ef *externalFetcher) recordResult(result *externalFetchResult) {
+	ef.mux.Lock()
+	defer ef.mux.Unlock()
+
+	delete(ef.inflight, result.dataID)
+	if elem, ok := ef.results[result.dataID]; ok {
+		ef.resultOrder.Remove(elem)
+	}
+	ef.results[result.dataID] = ef.resultOrder.PushBack(result)
+	for ef.resultOrder.Len() > ef.conf.maxResults {
+		oldest := ef.resultOrder.Remove(ef.resultOrder.Front()).(*externalFetchResult)
+		delete(ef.results, oldest.dataID)
+	}
+}
+
+// releaseResults discards the results for data that is no longer needed, as its message has been confirmed or rejected
+func (ef *externalFetcher) releaseResults(data core.DataArray) {
+	ef.mux.Lock()
+	defer ef.mux.Unlock()
+
+	for _, d := range data {
+		if elem, ok := ef.results[*d.ID]; ok {
+			ef.resultOrder.Remove(elem)
+			delete(ef.results, *d.ID)
+		}
+	}
+}
+
+// checkFetched returns true once the content of the reference has been fetched and matched its hash,
+// or has failed in a way that retrying will not fix - which fetchFailure reports. Otherwise a
+// background fetch is requested (if one is not already running) and false is returned.
+func (ef *externalFetcher) checkFetched(ctx context.Context, d *core.Data) bool {
+	ef.mux.Lock()
+	defer ef.mux.Unlock()
+
+	if ef.getResult(d) != nil {
+		return true
+	}
+	if ef.inflight[*d.ID] {
+		return false
+	}
+
+	work := &externalFetchWork{dataID: *d.ID, ref: *d.ExternalRef}
+	select {
+	case ef.work <- work:
+		ef.inflight[*d.ID] = true
+		log.L(ctx).Debugf("Requested fetch of external data '%s' for data %s", d.ExternalRef.URL, d.ID)
+	default:
+		log.L(ctx).Warnf("External data fetch queue full - data %s will be fetched when its message is next processed", d.ID)
+	}
+	return false
+}
+
+// fetchFailure returns the error if the content of the reference could not be verified
+func (ef *externalFetcher) fetchFailure(d *core.Data) error {
+	ef.mux.Lock()
+	defer ef.mux.Unlock()
+
+	if result := ef.getResult(d); result != nil {
+		return result.err
+	}
+	return nil
+}
+
+func (ef *externalFetcher) fetchWorkerLoop(idx int, done chan struct{}) {
+	defer close(done)
+
+	ctx := log.WithLogField(ef.ctx, "externalfetcher", fmt.Sprintf("ef_%.3d", idx))
+	for {
+		select {
+		case <-ctx.Done():
+			log.L(ctx).Debugf("External data fetch worker shutting down")
+			return
+		case work := <-ef.work:
+			ef.attemptFetch(ctx, work)
+		}
+	}
+}
+
+func (ef *externalFetcher) attemptFetch(ctx context.Context, work *externalFetchWork) {
+	work.attempts++
+	retry, err := ef.fetchAndVerify(ctx, &work.ref)
+	if err == nil {
+		ef.recordResult(&externalFetchResult{dataID: work.dataID, hash: work.ref.Hash})
+		log.L(ctx).Infof("External data '%s' verified for data %s", work.ref.URL, &work.dataID)
+		if ef.callbacks != nil {
+			ef.callbacks.ExternalDataVerified(&work.dataID)
+		}
+		return
+	}
+
+	log.L(ctx).Errorf("Fetch of external data '%s' for data %s attempt=%d/%d failed: %s", work.ref.URL, &work.dataID, work.attempts, ef.conf.retryMaxAttempts, err)
+	if retry && work.attempts < ef.conf.retryMaxAttempts {
+		go ef.waitAndRetryFetch(work)
+		return
+	}
+	// The message that refers to the data is rejected, rather than blocking its context
+	ef.recordResult(&externalFetchResult{dataID: work.dataID, hash: work.ref.Hash, err: err})
+	if ef.callbacks != nil {
+		ef.callbacks.ExternalDataFailed(&work.dataID)
+	}
+}
+
+func (ef *externalFetcher) calcDelay(attempts int) time.Duration {
+	delay := ef.conf.retryInitDelay
+	for i := 0; i < attempts; i++ {
+		delay = time.Duration(math.Ceil(float64(delay) * ef.conf.retryFactor))
+	}
+	if delay > ef.conf.retryMaxDelay {
+		delay = ef.conf.retryMaxDelay
+	}
+	return delay
+}
+
+func (ef *externalFetcher) waitAndRetryFetch(work *externalFetchWork) {
+	select {
+	case <-ef.ctx.Done():
+		return
+	case <-time.After(ef.calcDelay(work.attempts)):
+	}
+	select {
+	case <-ef.ctx.Done():
+	case ef.work <- work:
+	}
+}
+
+// fetchAndVerify downloads the content and checks it against the hash. The returned boolean
+// is false for failures that will not be fixed by retrying.
+func (ef *externalFetcher) fetchAndVerify(ctx context.Context, ref *core.ExternalDataRef) (retry bool, err error) {
+	if ref.Hash == nil {
+		return false, i18n.NewError(ctx, coremsgs.MsgInvalidExternalDataRef, "hash is required")
+	}
+	u, err := url.Parse(ref.URL)
+	if err != nil {
+		return false, i18n.NewError(ctx, coremsgs.MsgInvalidExternalDataRef, "url must be an absolute http or https URL")
+	}
+	if err := ef.checkHostAllowed(ctx, u); err != nil {
+		return false, err
+	}
+
+	res, err := ef.client.R().
+		SetContext(ctx).
+		SetDoNotParseResponse(true).
+		Get(ref.URL)
+	if err != nil {
+		return true, err
+	}
+	body := res.RawBody()
+	defer body.Close()
+	if res.StatusCode() != http.StatusOK {
+		return true, i18n.NewError(ctx, coremsgs.MsgExternalDataFetchFailed, res.StatusCode())
+	}
+
+	hash := sha256.New()
+	n, err := io.Copy(hash, io.LimitReader(body, ef.conf.maxSize+1))
+	if err != nil {
+		return true, err
+	}
+	if n > ef.conf.maxSize {
+		return false, i18n.NewError(ctx, coremsgs.MsgExternalDataTooLarge, ef.conf.maxSize)
+	}
+	if contentHash := fftypes.HashResult(hash); !contentHash.Equals(ref.Hash) {
+		return false, i18n.NewError(ctx, coremsgs.MsgExternalDataHashMismatch, contentHash, ref.Hash)
+	}
+	return false, nil
+}
@@ -0,0 +1,298 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestExternalFetcher(t *testing.T, handler http.HandlerFunc) (*externalFetcher, *httptest.Server, func()) {
+	server := httptest.NewServer(handler)
+	u, _ := url.Parse(server.URL)
+	ef := newExternalFetcher(context.Background(), resty.New(), &externalFetcherConf{
+		allowedHosts:     map[string]bool{u.Host: true},
+		maxSize:          100,
+		maxResults:       10,
+		queueLength:      10,
+		retryMaxAttempts: 2,
+		retryInitDelay:   1 * time.Millisecond,
+		retryMaxDelay:    1 * time.Millisecond,
+		retryFactor:      2.0,
+	})
+	return ef, server, func() {
+		ef.close()
+		server.Close()
+	}
+}
+
+func testExternalData(server *httptest.Server, content []byte) *core.Data {
+	hash := fftypes.Bytes32(sha256.Sum256(content))
+	return &core.Data{
+		ID: fftypes.NewUUID(),
+		ExternalRef: &core.ExternalDataRef{
+			URL:  server.URL + "/data",
+			Hash: &hash,
+		},
+	}
+}
+
+func TestExternalFetcherRequestOnce(t *testing.T) {
+	ef, server, done := newTestExternalFetcher(t, func(w http.ResponseWriter, r *http.Request) {})
+	defer done()
+
+	d := testExternalData(server, []byte("test"))
+	assert.False(t, ef.checkFetched(context.Background(), d))
+	assert.False(t, ef.checkFetched(context.Background(), d))
+	assert.Len(t, ef.work, 1)
+}
+
+func TestExternalFetcherQueueFull(t *testing.T) {
+	ef, server, done := newTestExternalFetcher(t, func(w http.ResponseWriter, r *http.Request) {})
+	defer done()
+	ef.work = make(chan *externalFetchWork)
+
+	d := testExternalData(server, []byte("test"))
+	assert.False(t, ef.checkFetched(context.Background(), d))
+	assert.Empty(t, ef.inflight)
+}
+
+func TestExternalFetcherVerifiedHashChanged(t *testing.T) {
+	ef, server, done := newTestExternalFetcher(t, func(w http.ResponseWriter, r *http.Request) {})
+	defer done()
+
+	d := testExternalData(server, []byte("test"))
+	ef.recordResult(&externalFetchResult{dataID: *d.ID, hash: fftypes.NewRandB32()})
+	assert.False(t, ef.checkFetched(context.Background(), d))
+}
+
+func TestExternalFetcherResultKeptUntilReleased(t *testing.T) {
+	ef, server, done := newTestExternalFetcher(t, func(w http.ResponseWriter, r *http.Request) {})
+	defer done()
+
+	d := testExternalData(server, []byte("test"))
+	ef.recordResult(&externalFetchResult{dataID: *d.ID, hash: d.ExternalRef.Hash})
+	assert.True(t, ef.checkFetched(context.Background(), d))
+	assert.True(t, ef.checkFetched(context.Background(), d))
+	assert.NoError(t, ef.fetchFailure(d))
+
+	ef.releaseResults(core.DataArray{d, testExternalData(server, []byte("other"))})
+	assert.Empty(t, ef.results)
+	assert.Zero(t, ef.resultOrder.Len())
+	assert.False(t, ef.checkFetched(context.Background(), d))
+}
+
+func TestExternalFetcherResultsBounded(t *testing.T) {
+	ef, server, done := newTestExternalFetcher(t, func(w http.ResponseWriter, r *http.Request) {})
+	defer done()
+	ef.conf.maxResults = 2
+
+	d1 := testExternalData(server, []byte("one"))
+	d2 := testExternalData(server, []byte("two"))
+	d3 := testExternalData(server, []byte("three"))
+	ef.recordResult(&externalFetchResult{dataID: *d1.ID, hash: d1.ExternalRef.Hash})
+	ef.recordResult(&externalFetchResult{dataID: *d2.ID, hash: d2.ExternalRef.Hash})
+	ef.recordResult(&externalFetchResult{dataID: *d1.ID, hash: d1.ExternalRef.Hash})
+	ef.recordResult(&externalFetchResult{dataID: *d3.ID, hash: d3.ExternalRef.Hash})
+
+	assert.Len(t, ef.results, 2)
+	assert.Equal(t, 2, ef.resultOrder.Len())
+	assert.NotNil(t, ef.results[*d1.ID])
+	assert.Nil(t, ef.results[*d2.ID])
+	assert.NotNil(t, ef.results[*d3.ID])
+}
+
+type testFetcherCallbacks struct {
+	verified chan *fftypes.UUID
+	failed   chan *fftypes.UUID
+}
+
+func (cb *testFetcherCallbacks) ExternalDataVerified(dataID *fftypes.UUID) {
+	cb.verified <- dataID
+}
+
+func (cb *testFetcherCallbacks) ExternalDataFailed(dataID *fftypes.UUID) {
+	cb.failed <- dataID
+}
+
+func TestExternalFetcherHashMismatchFails(t *testing.T) {
+	ef, server, done := newTestExternalFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("other"))
+	})
+	defer done()
+	cb := &testFetcherCallbacks{failed: make(chan *fftypes.UUID, 1)}
+	ef.callbacks = cb
+
+	d := testExternalData(server, []byte("test"))
+	ef.attemptFetch(context.Background(), &externalFetchWork{dataID: *d.ID, ref: *d.ExternalRef})
+
+	assert.Equal(t, d.ID, <-cb.failed)
+	assert.True(t, ef.checkFetched(context.Background(), d))
+	assert.Regexp(t, "FF10502", ef.fetchFailure(d))
+	assert.Empty(t, ef.inflight)
+}
+
+func TestExternalFetcherHashMismatch(t *testing.T) {
+	ef, server, done := newTestExternalFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("other"))
+	})
+	defer done()
+
+	d := testExternalData(server, []byte("test"))
+	retry, err := ef.fetchAndVerify(context.Background(), d.ExternalRef)
+	assert.Regexp(t, "FF10502", err)
+	assert.False(t, retry)
+}
+
+func TestExternalFetcherTooLarge(t *testing.T) {
+	ef, server, done := newTestExternalFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(make([]byte, 101))
+	})
+	defer done()
+
+	d := testExternalData(server, []byte("test"))
+	retry, err := ef.fetchAndVerify(context.Background(), d.ExternalRef)
+	assert.Regexp(t, "FF10501", err)
+	assert.False(t, retry)
+}
+
+func TestExternalFetcherRedirectNotFollowed(t *testing.T) {
+	ef, server, done := newTestExternalFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusFound)
+	})
+	defer done()
+
+	d := testExternalData(server, []byte("test"))
+	retry, err := ef.fetchAndVerify(context.Background(), d.ExternalRef)
+	assert.Error(t, err)
+	assert.True(t, retry)
+}
+
+func TestExternalFetcherHostNotAllowed(t *testing.T) {
+	ef, server, done := newTestExternalFetcher(t, func(w http.ResponseWriter, r *http.Request) {})
+	defer done()
+	ef.conf.allowedHosts = map[string]bool{}
+
+	d := testExternalData(server, []byte("test"))
+	retry, err := ef.fetchAndVerify(context.Background(), d.ExternalRef)
+	assert.Regexp(t, "FF10470", err)
+	assert.False(t, retry)
+}
+
+func TestExternalFetcherBadURL(t *testing.T) {
+	ef, _, done := newTestExternalFetcher(t, func(w http.ResponseWriter, r *http.Request) {})
+	defer done()
+
+	retry, err := ef.fetchAndVerify(context.Background(), &core.ExternalDataRef{
+		URL:  "::",
+		Hash: fftypes.NewRandB32(),
+	})
+	assert.Regexp(t, "FF10470", err)
+	assert.False(t, retry)
+}
+
+func TestExternalFetcherNoHash(t *testing.T) {
+	ef, server, done := newTestExternalFetcher(t, func(w http.ResponseWriter, r *http.Request) {})
+	defer done()
+
+	retry, err := ef.fetchAndVerify(context.Background(), &core.ExternalDataRef{URL: server.URL})
+	assert.Regexp(t, "FF10470", err)
+	assert.False(t, retry)
+}
+
+func TestExternalFetcherUnreachable(t *testing.T) {
+	ef, server, done := newTestExternalFetcher(t, func(w http.ResponseWriter, r *http.Request) {})
+	defer done()
+	server.Close()
+
+	d := testExternalData(server, []byte("test"))
+	retry, err := ef.fetchAndVerify(context.Background(), d.ExternalRef)
+	assert.Error(t, err)
+	assert.True(t, retry)
+}
+
+func TestExternalFetcherRetryThenGiveUp(t *testing.T) {
+	calls := make(chan struct{}, 2)
+	ef, server, done := newTestExternalFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		calls <- struct{}{}
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer done()
+	ef.start()
+
+	d := testExternalData(server, []byte("test"))
+	assert.False(t, ef.checkFetched(context.Background(), d))
+	<-calls
+	<-calls
+
+	for {
+		ef.mux.Lock()
+		inflight := ef.inflight[*d.ID]
+		ef.mux.Unlock()
+		if !inflight {
+			break
+		}
+		time.Sleep(1 * time.Millisecond)
+	}
+	assert.Regexp(t, "FF10500", ef.fetchFailure(d))
+}
+
+func TestExternalFetcherWaitAndRetryClosed(t *testing.T) {
+	ef, _, done := newTestExternalFetcher(t, func(w http.ResponseWriter, r *http.Request) {})
+	done()
+
+	ef.conf.retryInitDelay = 1 * time.Minute
+	ef.conf.retryMaxDelay = 1 * time.Minute
+	ef.waitAndRetryFetch(&externalFetchWork{attempts: 1})
+	assert.Empty(t, ef.work)
+}
+
+func TestExternalFetcherWaitAndRetryClosedQueueing(t *testing.T) {
+	ef, _, done := newTestExternalFetcher(t, func(w http.ResponseWriter, r *http.Request) {})
+	ef.work = make(chan *externalFetchWork)
+	ef.conf.retryInitDelay = 0
+
+	go done()
+	ef.waitAndRetryFetch(&externalFetchWork{attempts: 1})
+}
+
+func TestExternalFetcherCalcDelay(t *testing.T) {
+	ef, _, done := newTestExternalFetcher(t, func(w http.ResponseWriter, r *http.Request) {})
+	defer done()
+	ef.conf.retryInitDelay = 1 * time.Second
+	ef.conf.retryMaxDelay = 3 * time.Second
+
+	assert.Equal(t, 2*time.Second, ef.calcDelay(1))
+	assert.Equal(t, 3*time.Second, ef.calcDelay(2))
+}
+
+func TestNewExternalFetcherDefaults(t *testing.T) {
+	ef := newExternalFetcher(context.Background(), resty.New(), &externalFetcherConf{})
+	assert.Equal(t, 1, ef.conf.workerCount)
+	assert.Equal(t, 1, ef.conf.retryMaxAttempts)
+	assert.Equal(t, 1, ef.conf.maxResults)
+}
@@ -119,26 +119,6 @@ func (mw *messageWriter) WriteNewMessage(ctx context.Context, newMsg *NewMessage
 	return err
 }
 
-// WriteData writes a piece of data independently of a message
-func (mw *messageWriter) WriteData(ctx context.Context, data *core.Data) error {
-	if mw.conf.workerCount > 0 {
-		// Dispatch to background worker
-		nmi := &writeRequest{
-			id:      data.ID,
-			newData: core.DataArray{data},
-			result:  make(chan error),
-		}
-		select {
-		case mw.workQueue <- nmi:
-		case <-mw.ctx.Done():
-			return i18n.NewError(ctx, coremsgs.MsgContextCanceled)
-		}
-		return <-nmi.result
-	}
-	// Otherwise do it in-line on this context
-	return mw.database.UpsertData(ctx, data, database.UpsertOptimizationNew)
-}
-
 func (mw *messageWriter) start() {
 	if mw.conf.workerCount > 0 {
 		mw.workQueue = make(chan *writeRequest)
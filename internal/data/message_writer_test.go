@@ -64,13 +64,6 @@ func TestWriteNewMessageClosed(t *testing.T) {
 	assert.Regexp(t, "FF00154", err)
 }
 
-func TestWriteDataClosed(t *testing.T) {
-	mw := newTestMessageWriter(t)
-	mw.close()
-	err := mw.WriteData(mw.ctx, &core.Data{})
-	assert.Regexp(t, "FF00154", err)
-}
-
 func TestWriteNewMessageSyncFallback(t *testing.T) {
 	mw := newTestMessageWriterNoConcurrency(t)
 	customCtx := context.WithValue(context.Background(), "dbtx", "on this context")
@@ -100,20 +93,6 @@ func TestWriteNewMessageSyncFallback(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestWriteDataSyncFallback(t *testing.T) {
-	mw := newTestMessageWriterNoConcurrency(t)
-	customCtx := context.WithValue(context.Background(), "dbtx", "on this context")
-
-	data1 := &core.Data{ID: fftypes.NewUUID()}
-
-	mdi := mw.database.(*databasemocks.Plugin)
-	mdi.On("UpsertData", customCtx, data1, database.UpsertOptimizationNew).Return(nil)
-
-	err := mw.WriteData(customCtx, data1)
-
-	assert.NoError(t, err)
-}
-
 func TestWriteMessagesInsertMessagesFail(t *testing.T) {
 	mw := newTestMessageWriterNoConcurrency(t)
 
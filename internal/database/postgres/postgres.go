@@ -99,3 +99,7 @@ func (psql *Postgres) Open(url string) (*sql.DB, error) {
 func (psql *Postgres) GetMigrationDriver(db *sql.DB) (migratedb.Driver, error) {
 	return postgres.WithInstance(db, &postgres.Config{})
 }
+
+func (psql *Postgres) GetMigrationDriverForTable(db *sql.DB, migrationsTable string) (migratedb.Driver, error) {
+	return postgres.WithInstance(db, &postgres.Config{MigrationsTable: migrationsTable})
+}
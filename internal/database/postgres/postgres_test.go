@@ -18,12 +18,15 @@ package postgres
 
 import (
 	"context"
+	"database/sql/driver"
 	"testing"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly/internal/database/sqlcommon"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -37,6 +40,8 @@ func TestPostgresProvider(t *testing.T) {
 	assert.NoError(t, err)
 	_, err = psql.GetMigrationDriver(psql.DB())
 	assert.Error(t, err)
+	_, err = psql.GetMigrationDriverForTable(psql.DB(), "schema_migrations_test")
+	assert.Error(t, err)
 
 	assert.Equal(t, "postgres", psql.Name())
 	assert.Equal(t, "seq", psql.SequenceColumn())
@@ -51,3 +56,112 @@ func TestPostgresProvider(t *testing.T) {
 	assert.Equal(t, "INSERT INTO test (col1) VALUES (?)  ON CONFLICT DO NOTHING RETURNING seq", sql)
 	assert.True(t, query)
 }
+
+func TestPostgresQueryBuilder(t *testing.T) {
+	psql := &Postgres{}
+	conf := config.RootSection("unittest")
+	psql.InitConfig(conf)
+	conf.Set(sqlcommon.SQLConfDatasourceURL, "!bad connection")
+	err := psql.Init(context.Background(), conf)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	testCases := []struct {
+		name   string
+		filter func(fb ffapi.FilterBuilder) ffapi.Filter
+		query  string
+		args   []interface{}
+	}{
+		{
+			name:   "eq",
+			filter: func(fb ffapi.FilterBuilder) ffapi.Filter { return fb.Eq("tag", "tag1") },
+			query:  "SELECT id FROM messages WHERE tag = $1 ORDER BY seq DESC",
+			args:   []interface{}{"tag1"},
+		},
+		{
+			name:   "neq",
+			filter: func(fb ffapi.FilterBuilder) ffapi.Filter { return fb.Neq("tag", "tag1") },
+			query:  "SELECT id FROM messages WHERE tag <> $1 ORDER BY seq DESC",
+			args:   []interface{}{"tag1"},
+		},
+		{
+			name:   "gt",
+			filter: func(fb ffapi.FilterBuilder) ffapi.Filter { return fb.Gt("sequence", 10) },
+			query:  "SELECT id FROM messages WHERE seq > $1 ORDER BY seq DESC",
+			args:   []interface{}{int64(10)},
+		},
+		{
+			name:   "lt",
+			filter: func(fb ffapi.FilterBuilder) ffapi.Filter { return fb.Lt("sequence", 10) },
+			query:  "SELECT id FROM messages WHERE seq < $1 ORDER BY seq DESC",
+			args:   []interface{}{int64(10)},
+		},
+		{
+			name:   "in",
+			filter: func(fb ffapi.FilterBuilder) ffapi.Filter { return fb.In("tag", []driver.Value{"tag1", "tag2"}) },
+			query:  "SELECT id FROM messages WHERE tag IN ($1,$2) ORDER BY seq DESC",
+			args:   []interface{}{"tag1", "tag2"},
+		},
+		{
+			name: "and",
+			filter: func(fb ffapi.FilterBuilder) ffapi.Filter {
+				return fb.And(fb.Eq("tag", "tag1"), fb.Gt("sequence", 10))
+			},
+			query: "SELECT id FROM messages WHERE (tag = $1 AND seq > $2) ORDER BY seq DESC",
+			args:  []interface{}{"tag1", int64(10)},
+		},
+		{
+			name: "or",
+			filter: func(fb ffapi.FilterBuilder) ffapi.Filter {
+				return fb.Or(fb.Eq("tag", "tag1"), fb.Eq("tag", "tag2"))
+			},
+			query: "SELECT id FROM messages WHERE (tag = $1 OR tag = $2) ORDER BY seq DESC",
+			args:  []interface{}{"tag1", "tag2"},
+		},
+		{
+			name: "nested",
+			filter: func(fb ffapi.FilterBuilder) ffapi.Filter {
+				return fb.And(fb.Or(fb.Eq("tag", "tag1"), fb.Eq("tag", "tag2")), fb.Lt("sequence", 10))
+			},
+			query: "SELECT id FROM messages WHERE ((tag = $1 OR tag = $2) AND seq < $3) ORDER BY seq DESC",
+			args:  []interface{}{"tag1", "tag2", int64(10)},
+		},
+		{
+			name:   "sort",
+			filter: func(fb ffapi.FilterBuilder) ffapi.Filter { return fb.Eq("tag", "tag1").Sort("sequence").Ascending() },
+			query:  "SELECT id FROM messages WHERE tag = $1 ORDER BY seq",
+			args:   []interface{}{"tag1"},
+		},
+		{
+			name:   "limit and skip",
+			filter: func(fb ffapi.FilterBuilder) ffapi.Filter { return fb.Eq("tag", "tag1").Limit(10).Skip(20) },
+			query:  "SELECT id FROM messages WHERE tag = $1 ORDER BY seq DESC LIMIT 10 OFFSET 20",
+			args:   []interface{}{"tag1"},
+		},
+		{
+			name:   "injection is parameterized",
+			filter: func(fb ffapi.FilterBuilder) ffapi.Filter { return fb.Eq("tag", "'; DROP TABLE messages; --") },
+			query:  "SELECT id FROM messages WHERE tag = $1 ORDER BY seq DESC",
+			args:   []interface{}{"'; DROP TABLE messages; --"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter := tc.filter(database.MessageQueryFactory.NewFilter(ctx))
+			sel, _, _, err := psql.FilterSelect(ctx, "", sq.Select("id").From("messages"), filter, map[string]string{"sequence": "seq"}, []interface{}{"sequence"})
+			assert.NoError(t, err)
+			query, args, err := sel.PlaceholderFormat(psql.Features().PlaceholderFormat).ToSql()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.query, query)
+			values := make([]interface{}, len(args))
+			for i, a := range args {
+				values[i] = a
+				if v, ok := a.(driver.Valuer); ok {
+					values[i], err = v.Value()
+					assert.NoError(t, err)
+				}
+			}
+			assert.Equal(t, tc.args, values)
+		})
+	}
+}
@@ -166,6 +166,10 @@ func (s *SQLCommon) GetBlobs(ctx context.Context, namespace string, filter ffapi
 
 }
 
+func (s *SQLCommon) CountBlobReferences(ctx context.Context, payloadRef string) (count int64, err error) {
+	return s.CountQuery(ctx, blobsTable, nil, sq.Eq{"payload_ref": payloadRef}, nil, "")
+}
+
 func (s *SQLCommon) DeleteBlob(ctx context.Context, sequence int64) (err error) {
 
 	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
@@ -77,12 +77,25 @@ func TestBlobsE2EWithDB(t *testing.T) {
 	assert.Equal(t, string(blobJson), string(blobReadJson))
 	assert.Equal(t, blob.Sequence, blobRes[0].Sequence)
 
+	// Another namespace referring to the same payload counts as a reference
+	blobCopy := *blob
+	blobCopy.Namespace = "e2e-copy"
+	blobCopy.DataID = fftypes.NewUUID()
+	err = s.InsertBlob(ctx, &blobCopy)
+	assert.NoError(t, err)
+	refCount, err := s.CountBlobReferences(ctx, blob.PayloadRef)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), refCount)
+
 	// Test delete
 	err = s.DeleteBlob(ctx, blob.Sequence)
 	assert.NoError(t, err)
 	blobs, _, err = s.GetBlobs(ctx, namespace, filter)
 	assert.NoError(t, err)
 	assert.Equal(t, 0, len(blobs))
+	refCount, err = s.CountBlobReferences(ctx, blob.PayloadRef)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), refCount)
 
 }
 
@@ -0,0 +1,165 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+var (
+	blockchainReceiptColumns = []string{
+		"namespace",
+		"op_id",
+		"tx_id",
+		"message_id",
+		"block_number",
+		"block_hash",
+		"status",
+		"confirmed_at",
+	}
+)
+
+const blockchainReceiptsTable = "blockchain_receipts"
+
+func (s *SQLCommon) UpsertBlockchainReceipt(ctx context.Context, receipt *core.BlockchainReceipt) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	rows, _, err := s.QueryTx(ctx, blockchainReceiptsTable, tx,
+		sq.Select("seq").
+			From(blockchainReceiptsTable).
+			Where(sq.Eq{
+				"namespace": receipt.Namespace,
+				"op_id":     receipt.Operation,
+			}),
+	)
+	if err != nil {
+		return err
+	}
+	existing := rows.Next()
+	rows.Close()
+
+	if existing {
+		if _, err = s.UpdateTx(ctx, blockchainReceiptsTable, tx,
+			sq.Update(blockchainReceiptsTable).
+				Set("tx_id", receipt.TxID).
+				Set("message_id", receipt.MessageID).
+				Set("block_number", receipt.BlockNumber).
+				Set("block_hash", receipt.BlockHash).
+				Set("status", receipt.Status).
+				Set("confirmed_at", receipt.ConfirmedAt).
+				Where(sq.Eq{
+					"namespace": receipt.Namespace,
+					"op_id":     receipt.Operation,
+				}),
+			nil, // receipts do not have change events
+		); err != nil {
+			return err
+		}
+	} else {
+		if _, err = s.InsertTx(ctx, blockchainReceiptsTable, tx,
+			sq.Insert(blockchainReceiptsTable).
+				Columns(blockchainReceiptColumns...).
+				Values(
+					receipt.Namespace,
+					receipt.Operation,
+					receipt.TxID,
+					receipt.MessageID,
+					receipt.BlockNumber,
+					receipt.BlockHash,
+					receipt.Status,
+					receipt.ConfirmedAt,
+				),
+			nil, // receipts do not have change events
+		); err != nil {
+			return err
+		}
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) blockchainReceiptResult(ctx context.Context, row *sql.Rows) (*core.BlockchainReceipt, error) {
+	var receipt core.BlockchainReceipt
+	err := row.Scan(
+		&receipt.Namespace,
+		&receipt.Operation,
+		&receipt.TxID,
+		&receipt.MessageID,
+		&receipt.BlockNumber,
+		&receipt.BlockHash,
+		&receipt.Status,
+		&receipt.ConfirmedAt,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, blockchainReceiptsTable)
+	}
+	return &receipt, nil
+}
+
+func (s *SQLCommon) GetBlockchainReceiptByOpID(ctx context.Context, namespace string, opID *fftypes.UUID) (*core.BlockchainReceipt, error) {
+	rows, _, err := s.Query(ctx, blockchainReceiptsTable,
+		sq.Select(blockchainReceiptColumns...).
+			From(blockchainReceiptsTable).
+			Where(sq.Eq{"namespace": namespace, "op_id": opID}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Blockchain receipt for operation '%s' not found", opID)
+		return nil, nil
+	}
+
+	return s.blockchainReceiptResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetBlockchainReceiptsForMessages(ctx context.Context, namespace string, msgIDs []*fftypes.UUID) ([]*core.BlockchainReceipt, error) {
+	rows, _, err := s.Query(ctx, blockchainReceiptsTable,
+		sq.Select(blockchainReceiptColumns...).
+			From(blockchainReceiptsTable).
+			Where(sq.Eq{"namespace": namespace, "message_id": msgIDs}).
+			OrderBy("seq"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	receipts := []*core.BlockchainReceipt{}
+	for rows.Next() {
+		receipt, err := s.blockchainReceiptResult(ctx, rows)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, receipt)
+	}
+	return receipts, nil
+}
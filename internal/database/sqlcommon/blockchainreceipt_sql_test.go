@@ -0,0 +1,165 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockchainReceiptsE2EWithDB(t *testing.T) {
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	msgID := fftypes.NewUUID()
+	opID := fftypes.NewUUID()
+	receipt := &core.BlockchainReceipt{
+		Namespace: "ns1",
+		Operation: opID,
+		MessageID: msgID,
+		Status:    core.TxStatusPending,
+	}
+	err := s.UpsertBlockchainReceipt(ctx, receipt)
+	assert.NoError(t, err)
+
+	// Another namespace is not returned
+	err = s.UpsertBlockchainReceipt(ctx, &core.BlockchainReceipt{
+		Namespace: "ns2",
+		Operation: opID,
+		TxID:      "0x12345",
+		Status:    core.TxStatusFailed,
+	})
+	assert.NoError(t, err)
+
+	rRead, err := s.GetBlockchainReceiptByOpID(ctx, "ns1", opID)
+	assert.NoError(t, err)
+	rJson, _ := json.Marshal(receipt)
+	rReadJson, _ := json.Marshal(rRead)
+	assert.Equal(t, string(rJson), string(rReadJson))
+
+	// A later receipt for the same operation replaces the earlier one
+	receiptUpdated := &core.BlockchainReceipt{
+		Namespace:   "ns1",
+		Operation:   opID,
+		TxID:        "0x12345",
+		MessageID:   msgID,
+		BlockNumber: "12",
+		BlockHash:   "0xabcde",
+		Status:      core.TxStatusConfirmed,
+		ConfirmedAt: fftypes.Now(),
+	}
+	err = s.UpsertBlockchainReceipt(ctx, receiptUpdated)
+	assert.NoError(t, err)
+
+	rRead, err = s.GetBlockchainReceiptByOpID(ctx, "ns1", opID)
+	assert.NoError(t, err)
+	rJson, _ = json.Marshal(receiptUpdated)
+	rReadJson, _ = json.Marshal(rRead)
+	assert.Equal(t, string(rJson), string(rReadJson))
+
+	rRead, err = s.GetBlockchainReceiptByOpID(ctx, "ns1", fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, rRead)
+
+	// Query the receipts for a set of messages
+	receipts, err := s.GetBlockchainReceiptsForMessages(ctx, "ns1", []*fftypes.UUID{msgID, fftypes.NewUUID()})
+	assert.NoError(t, err)
+	assert.Len(t, receipts, 1)
+	rReadJson, _ = json.Marshal(receipts[0])
+	assert.Equal(t, string(rJson), string(rReadJson))
+}
+
+func TestUpsertBlockchainReceiptFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertBlockchainReceipt(context.Background(), &core.BlockchainReceipt{})
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertBlockchainReceiptFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertBlockchainReceipt(context.Background(), &core.BlockchainReceipt{})
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertBlockchainReceiptFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"seq"}))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertBlockchainReceipt(context.Background(), &core.BlockchainReceipt{})
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertBlockchainReceiptFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(1))
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertBlockchainReceipt(context.Background(), &core.BlockchainReceipt{})
+	assert.Regexp(t, "FF00178", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBlockchainReceiptByOpIDQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetBlockchainReceiptByOpID(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBlockchainReceiptByOpIDReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"namespace"}).AddRow("only one"))
+	_, err := s.GetBlockchainReceiptByOpID(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBlockchainReceiptsForMessagesQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetBlockchainReceiptsForMessages(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBlockchainReceiptsForMessagesReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"namespace"}).AddRow("only one"))
+	_, err := s.GetBlockchainReceiptsForMessages(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
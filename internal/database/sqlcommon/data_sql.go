@@ -48,6 +48,9 @@ var (
 		"blob_size",
 		"public",
 		"value_size",
+		"dtype",
+		"external_url",
+		"external_hash",
 	}
 	dataColumnsWithValue = append(append([]string{}, dataColumnsNoValue...), "value")
 	dataFilterFieldMap   = map[string]string{
@@ -59,6 +62,9 @@ var (
 		"blob.name":        "blob_name",
 		"blob.path":        "blob_path",
 		"blob.size":        "blob_size",
+		"type":             "dtype",
+		"externalref.url":  "external_url",
+		"externalref.hash": "external_hash",
 	}
 )
 
@@ -73,6 +79,10 @@ func (s *SQLCommon) attemptDataUpdate(ctx context.Context, tx *dbsql.TXWrapper,
 	if blob == nil {
 		blob = &core.BlobRef{}
 	}
+	externalRef := data.ExternalRef
+	if externalRef == nil {
+		externalRef = &core.ExternalDataRef{}
+	}
 	data.CalcPath()
 	return s.UpdateTx(ctx, dataTable, tx,
 		sq.Update(dataTable).
@@ -88,6 +98,9 @@ func (s *SQLCommon) attemptDataUpdate(ctx context.Context, tx *dbsql.TXWrapper,
 			Set("blob_size", blob.Size).
 			Set("public", data.Public).
 			Set("value_size", data.ValueSize).
+			Set("dtype", data.Type).
+			Set("external_url", externalRef.URL).
+			Set("external_hash", externalRef.Hash).
 			Set("value", data.Value).
 			Where(sq.Eq{
 				"id":        data.ID,
@@ -108,6 +121,10 @@ func (s *SQLCommon) setDataInsertValues(query sq.InsertBuilder, data *core.Data)
 	if blob == nil {
 		blob = &core.BlobRef{}
 	}
+	externalRef := data.ExternalRef
+	if externalRef == nil {
+		externalRef = &core.ExternalDataRef{}
+	}
 	data.CalcPath()
 	return query.Values(
 		data.ID,
@@ -124,6 +141,9 @@ func (s *SQLCommon) setDataInsertValues(query sq.InsertBuilder, data *core.Data)
 		blob.Size,
 		data.Public,
 		data.ValueSize,
+		data.Type,
+		externalRef.URL,
+		externalRef.Hash,
 		data.Value,
 	)
 }
@@ -136,13 +156,26 @@ func (s *SQLCommon) attemptDataInsert(ctx context.Context, tx *dbsql.TXWrapper,
 		}, requestConflictEmptyResult)
 }
 
-func (s *SQLCommon) UpsertData(ctx context.Context, data *core.Data, optimization database.UpsertOptimization) (err error) {
+func (s *SQLCommon) UpsertData(ctx context.Context, data *core.Data, optimization database.UpsertOptimization, allowExistingByHash bool) (err error) {
 	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
 	if err != nil {
 		return err
 	}
 	defer s.RollbackTx(ctx, tx, autoCommit)
 
+	if allowExistingByHash {
+		// Re-submissions of identical content resolve to the record that already exists
+		existing, err := s.getDataByHashTx(ctx, tx, data)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			log.L(ctx).Debugf("Data %s has the same hash as existing data %s", data.ID, existing.ID)
+			*data = *existing
+			return s.CommitTx(ctx, tx, autoCommit)
+		}
+	}
+
 	// This is a performance critical function, as we stream data into the database for every message, in every batch.
 	//
 	// First attempt the operation based on the optimization passed in.
@@ -194,6 +227,28 @@ func (s *SQLCommon) UpsertData(ctx context.Context, data *core.Data, optimizatio
 	return s.CommitTx(ctx, tx, autoCommit)
 }
 
+func (s *SQLCommon) getDataByHashTx(ctx context.Context, tx *dbsql.TXWrapper, data *core.Data) (*core.Data, error) {
+	rows, _, err := s.QueryTx(ctx, dataTable, tx,
+		sq.Select(dataColumnsWithValue...).
+			From(dataTable).
+			Where(sq.And{
+				sq.Eq{"namespace": data.Namespace, "hash": data.Hash},
+				sq.NotEq{"id": data.ID},
+			}).
+			OrderBy("seq").
+			Limit(1),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, nil
+	}
+	return s.dataResult(ctx, rows, true)
+}
+
 func (s *SQLCommon) InsertDataArray(ctx context.Context, dataArray core.DataArray) (err error) {
 
 	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
@@ -232,8 +287,9 @@ func (s *SQLCommon) InsertDataArray(ctx context.Context, dataArray core.DataArra
 
 func (s *SQLCommon) dataResult(ctx context.Context, row *sql.Rows, withValue bool) (*core.Data, error) {
 	data := core.Data{
-		Datatype: &core.DatatypeRef{},
-		Blob:     &core.BlobRef{},
+		Datatype:    &core.DatatypeRef{},
+		Blob:        &core.BlobRef{},
+		ExternalRef: &core.ExternalDataRef{},
 	}
 	results := []interface{}{
 		&data.ID,
@@ -250,6 +306,9 @@ func (s *SQLCommon) dataResult(ctx context.Context, row *sql.Rows, withValue boo
 		&data.Blob.Size,
 		&data.Public,
 		&data.ValueSize,
+		&data.Type,
+		&data.ExternalRef.URL,
+		&data.ExternalRef.Hash,
 	}
 	if withValue {
 		results = append(results, &data.Value)
@@ -258,6 +317,9 @@ func (s *SQLCommon) dataResult(ctx context.Context, row *sql.Rows, withValue boo
 	if data.Blob.Hash == nil && data.Blob.Public == "" {
 		data.Blob = nil
 	}
+	if data.ExternalRef.URL == "" && data.ExternalRef.Hash == nil {
+		data.ExternalRef = nil
+	}
 	if data.Datatype.Name == "" && data.Datatype.Version == "" {
 		data.Datatype = nil
 	}
@@ -298,6 +360,63 @@ func (s *SQLCommon) GetDataByID(ctx context.Context, namespace string, id *fftyp
 	return data, nil
 }
 
+func (s *SQLCommon) GetDataWithBlob(ctx context.Context, namespace string, id *fftypes.UUID, withValue bool) (data *core.Data, blob *core.Blob, err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	var cols []string
+	if withValue {
+		cols = dataColumnsWithValue
+	} else {
+		cols = dataColumnsNoValue
+	}
+	dataRows, _, err := s.QueryTx(ctx, dataTable, tx,
+		sq.Select(cols...).
+			From(dataTable).
+			Where(sq.Eq{"id": id, "namespace": namespace}),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	if dataRows.Next() {
+		data, err = s.dataResult(ctx, dataRows, withValue)
+	}
+	dataRows.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	if data == nil {
+		log.L(ctx).Debugf("Data '%s' not found", id)
+		return nil, nil, s.CommitTx(ctx, tx, autoCommit)
+	}
+
+	if data.Blob != nil && data.Blob.Hash != nil {
+		blobCols := append(append([]string{}, blobColumns...), s.SequenceColumn())
+		blobRows, _, err := s.QueryTx(ctx, blobsTable, tx,
+			sq.Select(blobCols...).
+				From(blobsTable).
+				Where(sq.Eq{"namespace": namespace, "data_id": data.ID, "hash": data.Blob.Hash}).
+				OrderBy(s.SequenceColumn()).
+				Limit(1),
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		if blobRows.Next() {
+			blob, err = s.blobResult(ctx, blobRows)
+		}
+		blobRows.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return data, blob, s.CommitTx(ctx, tx, autoCommit)
+}
+
 func (s *SQLCommon) GetData(ctx context.Context, namespace string, filter ffapi.Filter) (message core.DataArray, res *ffapi.FilterResult, err error) {
 
 	query, fop, fi, err := s.FilterSelect(
@@ -60,7 +60,7 @@ func TestDataE2EWithDB(t *testing.T) {
 	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionData, core.ChangeEventTypeCreated, "ns1", dataID, mock.Anything).Return()
 	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionData, core.ChangeEventTypeUpdated, "ns1", dataID, mock.Anything).Return()
 
-	err := s.UpsertData(ctx, data, database.UpsertOptimizationSkip)
+	err := s.UpsertData(ctx, data, database.UpsertOptimizationSkip, false)
 	assert.NoError(t, err)
 
 	// Check we get the exact same data back - we should not to return the value first
@@ -103,17 +103,21 @@ func TestDataE2EWithDB(t *testing.T) {
 			Name:   "path/to/myfile.ext",
 			Size:   12345,
 		},
+		ExternalRef: &core.ExternalDataRef{
+			URL:  "https://example.com/data/1",
+			Hash: fftypes.NewRandB32(),
+		},
 	}
 
 	// Check disallows hash update, regardless of optimization
-	err = s.UpsertData(context.Background(), dataUpdated, database.UpsertOptimizationNew)
+	err = s.UpsertData(context.Background(), dataUpdated, database.UpsertOptimizationNew, false)
 	assert.Equal(t, database.HashMismatch, err)
-	err = s.UpsertData(context.Background(), dataUpdated, database.UpsertOptimizationExisting)
+	err = s.UpsertData(context.Background(), dataUpdated, database.UpsertOptimizationExisting, false)
 	assert.Equal(t, database.HashMismatch, err)
 	assert.Equal(t, "/path/to", dataUpdated.Blob.Path)
 
 	dataUpdated.Hash = data.Hash
-	err = s.UpsertData(context.Background(), dataUpdated, database.UpsertOptimizationSkip)
+	err = s.UpsertData(context.Background(), dataUpdated, database.UpsertOptimizationSkip, false)
 	assert.NoError(t, err)
 
 	// Check we get the exact same message back - note the removal of one of the data elements
@@ -196,19 +200,19 @@ func TestDataSubPaths(t *testing.T) {
 	}
 	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionData, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
 
-	err := s.UpsertData(ctx, newData("dir1/file1.txt"), database.UpsertOptimizationSkip)
+	err := s.UpsertData(ctx, newData("dir1/file1.txt"), database.UpsertOptimizationSkip, false)
 	assert.NoError(t, err)
-	err = s.UpsertData(ctx, newData("/dir1/dir2/file2.txt"), database.UpsertOptimizationSkip)
+	err = s.UpsertData(ctx, newData("/dir1/dir2/file2.txt"), database.UpsertOptimizationSkip, false)
 	assert.NoError(t, err)
-	err = s.UpsertData(ctx, newData("/dir1/dir2/file3.txt"), database.UpsertOptimizationSkip)
+	err = s.UpsertData(ctx, newData("/dir1/dir2/file3.txt"), database.UpsertOptimizationSkip, false)
 	assert.NoError(t, err)
-	err = s.UpsertData(ctx, newData("dir1/dir3/file4.txt"), database.UpsertOptimizationSkip)
+	err = s.UpsertData(ctx, newData("dir1/dir3/file4.txt"), database.UpsertOptimizationSkip, false)
 	assert.NoError(t, err)
-	err = s.UpsertData(ctx, newData("dir2/dir3/file5.txt"), database.UpsertOptimizationSkip)
+	err = s.UpsertData(ctx, newData("dir2/dir3/file5.txt"), database.UpsertOptimizationSkip, false)
 	assert.NoError(t, err)
-	err = s.UpsertData(ctx, newData("dir2/dir3/dir4/file6.txt"), database.UpsertOptimizationSkip)
+	err = s.UpsertData(ctx, newData("dir2/dir3/dir4/file6.txt"), database.UpsertOptimizationSkip, false)
 	assert.NoError(t, err)
-	err = s.UpsertData(ctx, newData("dir2/file7.txt"), database.UpsertOptimizationSkip)
+	err = s.UpsertData(ctx, newData("dir2/file7.txt"), database.UpsertOptimizationSkip, false)
 	assert.NoError(t, err)
 
 	subPaths, err := s.GetDataSubPaths(ctx, "ns1", "")
@@ -238,7 +242,7 @@ func TestDataSubPaths(t *testing.T) {
 func TestUpsertDataFailBegin(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
-	err := s.UpsertData(context.Background(), &core.Data{}, database.UpsertOptimizationSkip)
+	err := s.UpsertData(context.Background(), &core.Data{}, database.UpsertOptimizationSkip, false)
 	assert.Regexp(t, "FF00175", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -249,7 +253,7 @@ func TestUpsertDataFailSelect(t *testing.T) {
 	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
 	mock.ExpectRollback()
 	dataID := fftypes.NewUUID()
-	err := s.UpsertData(context.Background(), &core.Data{ID: dataID}, database.UpsertOptimizationSkip)
+	err := s.UpsertData(context.Background(), &core.Data{ID: dataID}, database.UpsertOptimizationSkip, false)
 	assert.Regexp(t, "FF00176", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -261,7 +265,7 @@ func TestUpsertDataFailInsert(t *testing.T) {
 	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
 	mock.ExpectRollback()
 	dataID := fftypes.NewUUID()
-	err := s.UpsertData(context.Background(), &core.Data{ID: dataID}, database.UpsertOptimizationSkip)
+	err := s.UpsertData(context.Background(), &core.Data{ID: dataID}, database.UpsertOptimizationSkip, false)
 	assert.Regexp(t, "FF00177", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -274,7 +278,7 @@ func TestUpsertDataFailUpdate(t *testing.T) {
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"hash"}).AddRow(dataHash.String()))
 	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
 	mock.ExpectRollback()
-	err := s.UpsertData(context.Background(), &core.Data{ID: dataID, Hash: dataHash}, database.UpsertOptimizationSkip)
+	err := s.UpsertData(context.Background(), &core.Data{ID: dataID, Hash: dataHash}, database.UpsertOptimizationSkip, false)
 	assert.Regexp(t, "FF00178", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -286,11 +290,75 @@ func TestUpsertDataFailCommit(t *testing.T) {
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}))
 	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
-	err := s.UpsertData(context.Background(), &core.Data{ID: dataID}, database.UpsertOptimizationSkip)
+	err := s.UpsertData(context.Background(), &core.Data{ID: dataID}, database.UpsertOptimizationSkip, false)
 	assert.Regexp(t, "FF00180", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUpsertDataAllowExistingByHash(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionData, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+
+	data1 := &core.Data{
+		ID:        fftypes.NewUUID(),
+		Validator: core.ValidatorTypeJSON,
+		Namespace: "ns1",
+		Hash:      fftypes.NewRandB32(),
+		Created:   fftypes.Now(),
+		Value:     fftypes.JSONAnyPtr(`{"some":"data"}`),
+		Type:      core.DataTypeValue,
+	}
+	err := s.UpsertData(ctx, data1, database.UpsertOptimizationNew, true)
+	assert.NoError(t, err)
+
+	// Identical content with a different ID resolves to the existing record
+	data2 := &core.Data{
+		ID:        fftypes.NewUUID(),
+		Validator: core.ValidatorTypeJSON,
+		Namespace: "ns1",
+		Hash:      data1.Hash,
+		Created:   fftypes.Now(),
+		Value:     data1.Value,
+		Type:      core.DataTypeValue,
+	}
+	err = s.UpsertData(ctx, data2, database.UpsertOptimizationNew, true)
+	assert.NoError(t, err)
+	assert.Equal(t, *data1.ID, *data2.ID)
+
+	fb := database.DataQueryFactory.NewFilter(ctx)
+	dataRes, _, err := s.GetData(ctx, "ns1", fb.Eq("hash", data1.Hash))
+	assert.NoError(t, err)
+	assert.Len(t, dataRes, 1)
+
+	// Without the option, a duplicate is created
+	data3 := &core.Data{
+		ID:        fftypes.NewUUID(),
+		Validator: core.ValidatorTypeJSON,
+		Namespace: "ns1",
+		Hash:      data1.Hash,
+		Created:   fftypes.Now(),
+		Value:     data1.Value,
+	}
+	err = s.UpsertData(ctx, data3, database.UpsertOptimizationNew, false)
+	assert.NoError(t, err)
+	dataRes, _, err = s.GetData(ctx, "ns1", fb.Eq("hash", data1.Hash))
+	assert.NoError(t, err)
+	assert.Len(t, dataRes, 2)
+}
+
+func TestUpsertDataAllowExistingByHashFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertData(context.Background(), &core.Data{ID: fftypes.NewUUID()}, database.UpsertOptimizationNew, true)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestInsertDataArrayBeginFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
@@ -376,6 +444,112 @@ func TestGetDataByIDScanFail(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestGetDataWithBlobE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionData, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+
+	blobHash := fftypes.NewRandB32()
+	data := &core.Data{
+		ID:        fftypes.NewUUID(),
+		Validator: core.ValidatorTypeJSON,
+		Namespace: "ns1",
+		Hash:      blobHash,
+		Created:   fftypes.Now(),
+		Blob:      &core.BlobRef{Hash: blobHash, Size: 12345},
+		Type:      core.DataTypeBlob,
+	}
+	err := s.UpsertData(ctx, data, database.UpsertOptimizationNew, false)
+	assert.NoError(t, err)
+
+	// No blob record yet
+	dataRead, blobRead, err := s.GetDataWithBlob(ctx, "ns1", data.ID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, *data.ID, *dataRead.ID)
+	assert.Nil(t, blobRead)
+
+	blob := &core.Blob{
+		Namespace:  "ns1",
+		Hash:       blobHash,
+		Size:       12345,
+		PayloadRef: "ns1/blob1",
+		Created:    fftypes.Now(),
+		DataID:     data.ID,
+	}
+	err = s.InsertBlob(ctx, blob)
+	assert.NoError(t, err)
+
+	dataRead, blobRead, err = s.GetDataWithBlob(ctx, "ns1", data.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, *data.ID, *dataRead.ID)
+	assert.Equal(t, "ns1/blob1", blobRead.PayloadRef)
+
+	dataRead, blobRead, err = s.GetDataWithBlob(ctx, "ns1", fftypes.NewUUID(), false)
+	assert.NoError(t, err)
+	assert.Nil(t, dataRead)
+	assert.Nil(t, blobRead)
+}
+
+func newDataWithBlobRow() *sqlmock.Rows {
+	return sqlmock.NewRows(dataColumnsNoValue).AddRow(
+		fftypes.NewUUID().String(), "json", "ns1", "", "",
+		fftypes.NewRandB32().String(), 0, fftypes.NewRandB32().String(),
+		"", "", "", 0, "", 0, "blob", "", nil,
+	)
+}
+
+func TestGetDataWithBlobBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	_, _, err := s.GetDataWithBlob(context.Background(), "ns1", fftypes.NewUUID(), false)
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDataWithBlobSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, _, err := s.GetDataWithBlob(context.Background(), "ns1", fftypes.NewUUID(), false)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDataWithBlobScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	mock.ExpectRollback()
+	_, _, err := s.GetDataWithBlob(context.Background(), "ns1", fftypes.NewUUID(), true)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDataWithBlobSelectBlobFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(newDataWithBlobRow())
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, _, err := s.GetDataWithBlob(context.Background(), "ns1", fftypes.NewUUID(), false)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDataWithBlobScanBlobFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(newDataWithBlobRow())
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"namespace"}).AddRow("only one"))
+	mock.ExpectRollback()
+	_, _, err := s.GetDataWithBlob(context.Background(), "ns1", fftypes.NewUUID(), false)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetDataQueryFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
@@ -0,0 +1,203 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+var (
+	deadEventColumns = []string{
+		"id",
+		"namespace",
+		"pin_seq",
+		"batch_id",
+		"hash",
+		"pin_index",
+		"attempts",
+		"error",
+		"created",
+		"updated",
+	}
+	deadEventFilterFieldMap = map[string]string{
+		"pin":   "pin_seq",
+		"batch": "batch_id",
+		"index": "pin_index",
+	}
+)
+
+const deadEventsTable = "dead_events"
+
+func (s *SQLCommon) UpsertDeadEvent(ctx context.Context, deadEvent *core.DeadEvent) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	rows, _, err := s.QueryTx(ctx, deadEventsTable, tx,
+		sq.Select("id").
+			From(deadEventsTable).
+			Where(sq.Eq{
+				"namespace": deadEvent.Namespace,
+				"pin_seq":   deadEvent.Pin,
+			}),
+	)
+	if err != nil {
+		return err
+	}
+	var existingID *fftypes.UUID
+	if rows.Next() {
+		if err = rows.Scan(&existingID); err != nil {
+			rows.Close()
+			return i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, deadEventsTable)
+		}
+	}
+	rows.Close()
+
+	deadEvent.Updated = fftypes.Now()
+	if existingID != nil {
+		// The same pin has been given up on before - keep the original ID
+		deadEvent.ID = existingID
+		if _, err = s.UpdateTx(ctx, deadEventsTable, tx,
+			sq.Update(deadEventsTable).
+				Set("batch_id", deadEvent.Batch).
+				Set("hash", deadEvent.Hash).
+				Set("pin_index", deadEvent.Index).
+				Set("attempts", deadEvent.Attempts).
+				Set("error", deadEvent.Error).
+				Set("updated", deadEvent.Updated).
+				Where(sq.Eq{"id": existingID}),
+			nil, // dead events do not have change events
+		); err != nil {
+			return err
+		}
+	} else {
+		deadEvent.Created = deadEvent.Updated
+		if _, err = s.InsertTx(ctx, deadEventsTable, tx,
+			sq.Insert(deadEventsTable).
+				Columns(deadEventColumns...).
+				Values(
+					deadEvent.ID,
+					deadEvent.Namespace,
+					deadEvent.Pin,
+					deadEvent.Batch,
+					deadEvent.Hash,
+					deadEvent.Index,
+					deadEvent.Attempts,
+					deadEvent.Error,
+					deadEvent.Created,
+					deadEvent.Updated,
+				),
+			nil, // dead events do not have change events
+		); err != nil {
+			return err
+		}
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) deadEventResult(ctx context.Context, row *sql.Rows) (*core.DeadEvent, error) {
+	var deadEvent core.DeadEvent
+	err := row.Scan(
+		&deadEvent.ID,
+		&deadEvent.Namespace,
+		&deadEvent.Pin,
+		&deadEvent.Batch,
+		&deadEvent.Hash,
+		&deadEvent.Index,
+		&deadEvent.Attempts,
+		&deadEvent.Error,
+		&deadEvent.Created,
+		&deadEvent.Updated,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, deadEventsTable)
+	}
+	return &deadEvent, nil
+}
+
+func (s *SQLCommon) GetDeadEventByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.DeadEvent, error) {
+	rows, _, err := s.Query(ctx, deadEventsTable,
+		sq.Select(deadEventColumns...).
+			From(deadEventsTable).
+			Where(sq.Eq{"id": id, "namespace": namespace}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Dead event '%s' not found", id)
+		return nil, nil
+	}
+
+	return s.deadEventResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetDeadEvents(ctx context.Context, namespace string, filter ffapi.Filter) (deadEvents []*core.DeadEvent, res *ffapi.FilterResult, err error) {
+	query, fop, fi, err := s.FilterSelect(ctx, "", sq.Select(deadEventColumns...).From(deadEventsTable),
+		filter, deadEventFilterFieldMap, []interface{}{"sequence"}, sq.Eq{"namespace": namespace})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.Query(ctx, deadEventsTable, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	deadEvents = []*core.DeadEvent{}
+	for rows.Next() {
+		deadEvent, err := s.deadEventResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		deadEvents = append(deadEvents, deadEvent)
+	}
+
+	return deadEvents, s.QueryRes(ctx, deadEventsTable, tx, fop, nil, fi), err
+}
+
+func (s *SQLCommon) DeleteDeadEvent(ctx context.Context, namespace string, id *fftypes.UUID) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	err = s.DeleteTx(ctx, deadEventsTable, tx, sq.Delete(deadEventsTable).Where(sq.Eq{"id": id, "namespace": namespace}),
+		nil, // dead events do not have change events
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
@@ -0,0 +1,207 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadEventsE2EWithDB(t *testing.T) {
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	batchID := fftypes.NewUUID()
+	deadEvent := &core.DeadEvent{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Pin:       10,
+		Batch:     batchID,
+		Hash:      fftypes.NewRandB32(),
+		Index:     1,
+		Attempts:  5,
+		Error:     "pop",
+	}
+	err := s.UpsertDeadEvent(ctx, deadEvent)
+	assert.NoError(t, err)
+	assert.NotNil(t, deadEvent.Created)
+
+	// Another namespace is not returned
+	err = s.UpsertDeadEvent(ctx, &core.DeadEvent{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns2",
+		Pin:       10,
+		Attempts:  1,
+	})
+	assert.NoError(t, err)
+
+	deRead, err := s.GetDeadEventByID(ctx, "ns1", deadEvent.ID)
+	assert.NoError(t, err)
+	deJson, _ := json.Marshal(deadEvent)
+	deReadJson, _ := json.Marshal(deRead)
+	assert.Equal(t, string(deJson), string(deReadJson))
+
+	// Failing the same pin again updates the existing record, keeping its ID
+	originalID := deadEvent.ID
+	deadEventUpdated := &core.DeadEvent{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Pin:       10,
+		Batch:     batchID,
+		Hash:      deadEvent.Hash,
+		Index:     1,
+		Attempts:  10,
+		Error:     "pop again",
+	}
+	err = s.UpsertDeadEvent(ctx, deadEventUpdated)
+	assert.NoError(t, err)
+	assert.Equal(t, *originalID, *deadEventUpdated.ID)
+
+	fb := database.DeadEventQueryFactory.NewFilter(ctx)
+	deadEvents, res, err := s.GetDeadEvents(ctx, "ns1", fb.And(fb.Eq("batch", batchID)).Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assert.Len(t, deadEvents, 1)
+	assert.Equal(t, 10, deadEvents[0].Attempts)
+	assert.Equal(t, "pop again", deadEvents[0].Error)
+	assert.Equal(t, deadEvent.Created.String(), deadEvents[0].Created.String())
+
+	err = s.DeleteDeadEvent(ctx, "ns1", originalID)
+	assert.NoError(t, err)
+	deRead, err = s.GetDeadEventByID(ctx, "ns1", originalID)
+	assert.NoError(t, err)
+	assert.Nil(t, deRead)
+}
+
+func TestUpsertDeadEventFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertDeadEvent(context.Background(), &core.DeadEvent{})
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertDeadEventFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertDeadEvent(context.Background(), &core.DeadEvent{})
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertDeadEventFailScan(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("!not a uuid"))
+	mock.ExpectRollback()
+	err := s.UpsertDeadEvent(context.Background(), &core.DeadEvent{})
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertDeadEventFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertDeadEvent(context.Background(), &core.DeadEvent{ID: fftypes.NewUUID()})
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertDeadEventFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(fftypes.NewUUID().String()))
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertDeadEvent(context.Background(), &core.DeadEvent{ID: fftypes.NewUUID()})
+	assert.Regexp(t, "FF00178", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadEventByIDQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetDeadEventByID(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadEventByIDReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetDeadEventByID(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadEventsFilterSelectFail(t *testing.T) {
+	fb := database.DeadEventQueryFactory.NewFilter(context.Background())
+	s, _ := newMockProvider().init()
+	_, _, err := s.GetDeadEvents(context.Background(), "ns1", fb.And(fb.Eq("id", map[bool]bool{true: false})))
+	assert.Error(t, err)
+}
+
+func TestGetDeadEventsQueryFail(t *testing.T) {
+	fb := database.DeadEventQueryFactory.NewFilter(context.Background())
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, _, err := s.GetDeadEvents(context.Background(), "ns1", fb.And())
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadEventsReadFail(t *testing.T) {
+	fb := database.DeadEventQueryFactory.NewFilter(context.Background())
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, _, err := s.GetDeadEvents(context.Background(), "ns1", fb.And())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteDeadEventFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteDeadEvent(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteDeadEventFailDelete(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteDeadEvent(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
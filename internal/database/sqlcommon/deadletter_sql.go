@@ -0,0 +1,116 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+var (
+	deadLetterColumns = []string{
+		"id",
+		"namespace",
+		"subscription_id",
+		"event_id",
+		"event_seq",
+		"error",
+		"created",
+	}
+	deadLetterFilterFieldMap = map[string]string{
+		"subscription":  "subscription_id",
+		"event":         "event_id",
+		"eventsequence": "event_seq",
+	}
+)
+
+const deadLettersTable = "deadletters"
+
+func (s *SQLCommon) InsertDeadLetter(ctx context.Context, deadLetter *core.DeadLetter) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	if _, err = s.InsertTx(ctx, deadLettersTable, tx,
+		sq.Insert(deadLettersTable).
+			Columns(deadLetterColumns...).
+			Values(
+				deadLetter.ID,
+				deadLetter.Namespace,
+				deadLetter.Subscription,
+				deadLetter.Event,
+				deadLetter.EventSequence,
+				deadLetter.Error,
+				deadLetter.Created,
+			),
+		nil, // dead letters do not have change events
+	); err != nil {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) deadLetterResult(ctx context.Context, row *sql.Rows) (*core.DeadLetter, error) {
+	var deadLetter core.DeadLetter
+	err := row.Scan(
+		&deadLetter.ID,
+		&deadLetter.Namespace,
+		&deadLetter.Subscription,
+		&deadLetter.Event,
+		&deadLetter.EventSequence,
+		&deadLetter.Error,
+		&deadLetter.Created,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, deadLettersTable)
+	}
+	return &deadLetter, nil
+}
+
+func (s *SQLCommon) GetDeadLetters(ctx context.Context, namespace string, filter ffapi.Filter) (deadLetters []*core.DeadLetter, res *ffapi.FilterResult, err error) {
+	query, fop, fi, err := s.FilterSelect(ctx, "", sq.Select(deadLetterColumns...).From(deadLettersTable),
+		filter, deadLetterFilterFieldMap, []interface{}{"sequence"}, sq.Eq{"namespace": namespace})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.Query(ctx, deadLettersTable, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	deadLetters = []*core.DeadLetter{}
+	for rows.Next() {
+		deadLetter, err := s.deadLetterResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		deadLetters = append(deadLetters, deadLetter)
+	}
+
+	return deadLetters, s.QueryRes(ctx, deadLettersTable, tx, fop, nil, fi), err
+}
@@ -0,0 +1,135 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadLettersE2EWithDB(t *testing.T) {
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	subID := fftypes.NewUUID()
+	deadLetter1 := &core.DeadLetter{
+		ID:            fftypes.NewUUID(),
+		Namespace:     "ns1",
+		Subscription:  subID,
+		Event:         fftypes.NewUUID(),
+		EventSequence: 20,
+		Error:         "pop",
+		Created:       fftypes.Now(),
+	}
+	deadLetter2 := &core.DeadLetter{
+		ID:            fftypes.NewUUID(),
+		Namespace:     "ns1",
+		Subscription:  subID,
+		Event:         fftypes.NewUUID(),
+		EventSequence: 10,
+		Created:       fftypes.Now(),
+	}
+	err := s.InsertDeadLetter(ctx, deadLetter1)
+	assert.NoError(t, err)
+	err = s.InsertDeadLetter(ctx, deadLetter2)
+	assert.NoError(t, err)
+
+	// Another subscription, and another namespace, are not returned
+	err = s.InsertDeadLetter(ctx, &core.DeadLetter{
+		ID:            fftypes.NewUUID(),
+		Namespace:     "ns1",
+		Subscription:  fftypes.NewUUID(),
+		Event:         fftypes.NewUUID(),
+		EventSequence: 15,
+		Created:       fftypes.Now(),
+	})
+	assert.NoError(t, err)
+	err = s.InsertDeadLetter(ctx, &core.DeadLetter{
+		ID:            fftypes.NewUUID(),
+		Namespace:     "ns2",
+		Subscription:  subID,
+		Event:         fftypes.NewUUID(),
+		EventSequence: 15,
+		Created:       fftypes.Now(),
+	})
+	assert.NoError(t, err)
+
+	fb := database.DeadLetterQueryFactory.NewFilter(ctx)
+	deadLetters, res, err := s.GetDeadLetters(ctx, "ns1", fb.And(fb.Eq("subscription", subID)).Sort("eventsequence").Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), *res.TotalCount)
+	assert.Len(t, deadLetters, 2)
+	dlJson, _ := json.Marshal(deadLetter2)
+	dlReadJson, _ := json.Marshal(deadLetters[0])
+	assert.Equal(t, string(dlJson), string(dlReadJson))
+	dlJson, _ = json.Marshal(deadLetter1)
+	dlReadJson, _ = json.Marshal(deadLetters[1])
+	assert.Equal(t, string(dlJson), string(dlReadJson))
+}
+
+func TestInsertDeadLetterFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertDeadLetter(context.Background(), &core.DeadLetter{})
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertDeadLetterFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertDeadLetter(context.Background(), &core.DeadLetter{ID: fftypes.NewUUID()})
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadLettersFilterSelectFail(t *testing.T) {
+	fb := database.DeadLetterQueryFactory.NewFilter(context.Background())
+	s, _ := newMockProvider().init()
+	_, _, err := s.GetDeadLetters(context.Background(), "ns1", fb.And(fb.Eq("id", map[bool]bool{true: false})))
+	assert.Error(t, err)
+}
+
+func TestGetDeadLettersQueryFail(t *testing.T) {
+	fb := database.DeadLetterQueryFactory.NewFilter(context.Background())
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, _, err := s.GetDeadLetters(context.Background(), "ns1", fb.And())
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadLettersReadFail(t *testing.T) {
+	fb := database.DeadLetterQueryFactory.NewFilter(context.Background())
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, _, err := s.GetDeadLetters(context.Background(), "ns1", fb.And())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,207 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+var (
+	deliveryReceiptColumns = []string{
+		"id",
+		"namespace",
+		"message_id",
+		"message_hash",
+		"recipient",
+		"key",
+		"broadcast_id",
+		"received",
+	}
+	deliveryReceiptFilterFieldMap = map[string]string{
+		"messageid":   "message_id",
+		"messagehash": "message_hash",
+		"broadcast":   "broadcast_id",
+	}
+)
+
+const deliveryReceiptsTable = "delivery_receipts"
+
+func (s *SQLCommon) InsertDeliveryReceipt(ctx context.Context, receipt *core.DeliveryReceipt) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	if _, err = s.InsertTx(ctx, deliveryReceiptsTable, tx,
+		sq.Insert(deliveryReceiptsTable).
+			Columns(deliveryReceiptColumns...).
+			Values(
+				receipt.ID,
+				receipt.Namespace,
+				receipt.MessageID,
+				receipt.MessageHash,
+				receipt.RecipientDID,
+				receipt.Key,
+				receipt.Broadcast,
+				receipt.ReceivedAt,
+			),
+		nil, // delivery receipts do not have change events
+	); err != nil {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) deliveryReceiptResult(ctx context.Context, row *sql.Rows) (*core.DeliveryReceipt, error) {
+	var receipt core.DeliveryReceipt
+	err := row.Scan(
+		&receipt.ID,
+		&receipt.Namespace,
+		&receipt.MessageID,
+		&receipt.MessageHash,
+		&receipt.RecipientDID,
+		&receipt.Key,
+		&receipt.Broadcast,
+		&receipt.ReceivedAt,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, deliveryReceiptsTable)
+	}
+	return &receipt, nil
+}
+
+func (s *SQLCommon) GetDeliveryReceipts(ctx context.Context, namespace string, filter ffapi.Filter) (receipts []*core.DeliveryReceipt, res *ffapi.FilterResult, err error) {
+	query, fop, fi, err := s.FilterSelect(ctx, "", sq.Select(deliveryReceiptColumns...).From(deliveryReceiptsTable),
+		filter, deliveryReceiptFilterFieldMap, []interface{}{&ffapi.SortField{Field: "received"}}, sq.Eq{"namespace": namespace})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.Query(ctx, deliveryReceiptsTable, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	receipts = []*core.DeliveryReceipt{}
+	for rows.Next() {
+		receipt, err := s.deliveryReceiptResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	return receipts, s.QueryRes(ctx, deliveryReceiptsTable, tx, fop, nil, fi), err
+}
+
+var pendingDeliveryReceiptColumns = []string{
+	"id",
+	"namespace",
+	"message_id",
+	"message_hash",
+	"recipient",
+	"received",
+}
+
+const pendingDeliveryReceiptsTable = "pending_delivery_receipts"
+
+func (s *SQLCommon) InsertPendingDeliveryReceipt(ctx context.Context, receipt *core.DeliveryReceipt) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	if _, err = s.InsertTx(ctx, pendingDeliveryReceiptsTable, tx,
+		sq.Insert(pendingDeliveryReceiptsTable).
+			Columns(pendingDeliveryReceiptColumns...).
+			Values(
+				receipt.ID,
+				receipt.Namespace,
+				receipt.MessageID,
+				receipt.MessageHash,
+				receipt.RecipientDID,
+				receipt.ReceivedAt,
+			),
+		nil, // pending delivery receipts do not have change events
+	); err != nil {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) GetPendingDeliveryReceipts(ctx context.Context, namespace string, limit int) (receipts []*core.DeliveryReceipt, err error) {
+	query := sq.Select(pendingDeliveryReceiptColumns...).
+		From(pendingDeliveryReceiptsTable).
+		Where(sq.Eq{"namespace": namespace}).
+		OrderBy(s.SequenceColumn()).
+		Limit(uint64(limit))
+
+	rows, _, err := s.Query(ctx, pendingDeliveryReceiptsTable, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	receipts = []*core.DeliveryReceipt{}
+	for rows.Next() {
+		var receipt core.DeliveryReceipt
+		err := rows.Scan(
+			&receipt.ID,
+			&receipt.Namespace,
+			&receipt.MessageID,
+			&receipt.MessageHash,
+			&receipt.RecipientDID,
+			&receipt.ReceivedAt,
+		)
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, pendingDeliveryReceiptsTable)
+		}
+		receipts = append(receipts, &receipt)
+	}
+	return receipts, nil
+}
+
+func (s *SQLCommon) DeletePendingDeliveryReceipt(ctx context.Context, namespace string, id *fftypes.UUID) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	err = s.DeleteTx(ctx, pendingDeliveryReceiptsTable, tx, sq.Delete(pendingDeliveryReceiptsTable).Where(sq.Eq{
+		"namespace": namespace,
+		"id":        id,
+	}), nil /* pending delivery receipts do not have change events */)
+	if err != nil {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
@@ -0,0 +1,233 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryReceiptsE2EWithDB(t *testing.T) {
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	msgID := fftypes.NewUUID()
+	receipt := &core.DeliveryReceipt{
+		ID:           fftypes.NewUUID(),
+		Namespace:    "ns1",
+		MessageID:    msgID,
+		MessageHash:  fftypes.NewRandB32(),
+		RecipientDID: "did:firefly:org/org2",
+		ReceivedAt:   fftypes.Now(),
+		Key:          "0x12345",
+		Broadcast:    fftypes.NewUUID(),
+	}
+	err := s.InsertDeliveryReceipt(ctx, receipt)
+	assert.NoError(t, err)
+
+	// Another namespace is not returned
+	err = s.InsertDeliveryReceipt(ctx, &core.DeliveryReceipt{
+		ID:           fftypes.NewUUID(),
+		Namespace:    "ns2",
+		MessageID:    msgID,
+		MessageHash:  receipt.MessageHash,
+		RecipientDID: "did:firefly:org/org2",
+		ReceivedAt:   fftypes.Now(),
+	})
+	assert.NoError(t, err)
+
+	// A second receipt from the same recipient is rejected
+	err = s.InsertDeliveryReceipt(ctx, &core.DeliveryReceipt{
+		ID:           fftypes.NewUUID(),
+		Namespace:    "ns1",
+		MessageID:    msgID,
+		MessageHash:  receipt.MessageHash,
+		RecipientDID: "did:firefly:org/org2",
+		ReceivedAt:   fftypes.Now(),
+	})
+	assert.Regexp(t, "FF00177", err)
+
+	fb := database.DeliveryReceiptQueryFactory.NewFilter(ctx)
+	receipts, res, err := s.GetDeliveryReceipts(ctx, "ns1", fb.And(fb.Eq("messageid", msgID)).Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assert.Len(t, receipts, 1)
+	receiptJson, _ := json.Marshal(receipt)
+	receiptReadJson, _ := json.Marshal(receipts[0])
+	assert.Equal(t, string(receiptJson), string(receiptReadJson))
+}
+
+func TestInsertDeliveryReceiptFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertDeliveryReceipt(context.Background(), &core.DeliveryReceipt{})
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertDeliveryReceiptFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertDeliveryReceipt(context.Background(), &core.DeliveryReceipt{})
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeliveryReceiptsFilterSelectFail(t *testing.T) {
+	fb := database.DeliveryReceiptQueryFactory.NewFilter(context.Background())
+	s, _ := newMockProvider().init()
+	_, _, err := s.GetDeliveryReceipts(context.Background(), "ns1", fb.And(fb.Eq("id", map[bool]bool{true: false})))
+	assert.Error(t, err)
+}
+
+func TestGetDeliveryReceiptsQueryFail(t *testing.T) {
+	fb := database.DeliveryReceiptQueryFactory.NewFilter(context.Background())
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, _, err := s.GetDeliveryReceipts(context.Background(), "ns1", fb.And())
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeliveryReceiptsReadFail(t *testing.T) {
+	fb := database.DeliveryReceiptQueryFactory.NewFilter(context.Background())
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, _, err := s.GetDeliveryReceipts(context.Background(), "ns1", fb.And())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPendingDeliveryReceiptsE2EWithDB(t *testing.T) {
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	receipt1 := &core.DeliveryReceipt{
+		ID:           fftypes.NewUUID(),
+		Namespace:    "ns1",
+		MessageID:    fftypes.NewUUID(),
+		MessageHash:  fftypes.NewRandB32(),
+		RecipientDID: "did:firefly:org/org2",
+		ReceivedAt:   fftypes.Now(),
+	}
+	receipt2 := &core.DeliveryReceipt{
+		ID:           fftypes.NewUUID(),
+		Namespace:    "ns1",
+		MessageID:    receipt1.MessageID,
+		MessageHash:  receipt1.MessageHash,
+		RecipientDID: "did:firefly:org/org3",
+		ReceivedAt:   fftypes.Now(),
+	}
+	err := s.InsertPendingDeliveryReceipt(ctx, receipt1)
+	assert.NoError(t, err)
+	err = s.InsertPendingDeliveryReceipt(ctx, receipt2)
+	assert.NoError(t, err)
+	err = s.InsertPendingDeliveryReceipt(ctx, &core.DeliveryReceipt{
+		ID:           fftypes.NewUUID(),
+		Namespace:    "ns2",
+		MessageID:    receipt1.MessageID,
+		MessageHash:  receipt1.MessageHash,
+		RecipientDID: "did:firefly:org/org2",
+		ReceivedAt:   fftypes.Now(),
+	})
+	assert.NoError(t, err)
+
+	receipts, err := s.GetPendingDeliveryReceipts(ctx, "ns1", 1)
+	assert.NoError(t, err)
+	assert.Len(t, receipts, 1)
+	receiptJson, _ := json.Marshal(receipt1)
+	receiptReadJson, _ := json.Marshal(receipts[0])
+	assert.Equal(t, string(receiptJson), string(receiptReadJson))
+
+	err = s.DeletePendingDeliveryReceipt(ctx, "ns1", receipt1.ID)
+	assert.NoError(t, err)
+
+	receipts, err = s.GetPendingDeliveryReceipts(ctx, "ns1", 10)
+	assert.NoError(t, err)
+	assert.Len(t, receipts, 1)
+	assert.Equal(t, receipt2.ID, receipts[0].ID)
+
+	// Another namespace cannot delete the receipt
+	err = s.DeletePendingDeliveryReceipt(ctx, "ns2", receipt2.ID)
+	assert.Regexp(t, "FF00167", err)
+}
+
+func TestInsertPendingDeliveryReceiptFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertPendingDeliveryReceipt(context.Background(), &core.DeliveryReceipt{})
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertPendingDeliveryReceiptFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertPendingDeliveryReceipt(context.Background(), &core.DeliveryReceipt{})
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPendingDeliveryReceiptsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetPendingDeliveryReceipts(context.Background(), "ns1", 10)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPendingDeliveryReceiptsReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetPendingDeliveryReceipts(context.Background(), "ns1", 10)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeletePendingDeliveryReceiptFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeletePendingDeliveryReceipt(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeletePendingDeliveryReceiptFailDelete(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeletePendingDeliveryReceipt(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
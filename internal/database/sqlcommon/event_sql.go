@@ -79,7 +79,11 @@ func (s *SQLCommon) InsertEvent(ctx context.Context, event *core.Event) (err err
 }
 
 func (s *SQLCommon) setEventInsertValues(query sq.InsertBuilder, event *core.Event) sq.InsertBuilder {
-	return query.Values(
+	return query.Values(s.eventInsertValues(event)...)
+}
+
+func (s *SQLCommon) eventInsertValues(event *core.Event) []interface{} {
+	return []interface{}{
 		event.ID,
 		string(event.Type),
 		event.Namespace,
@@ -88,13 +92,16 @@ func (s *SQLCommon) setEventInsertValues(query sq.InsertBuilder, event *core.Eve
 		event.Transaction,
 		event.Topic,
 		event.Created,
-	)
+	}
 }
 
 const eventsTable = "events"
 
+const eventsArchiveTable = "events_archive"
+
 func (s *SQLCommon) eventInserted(ctx context.Context, event *core.Event) {
 	s.callbacks.OrderedUUIDCollectionNSEvent(database.CollectionEvents, core.ChangeEventTypeCreated, event.Namespace, event.ID, event.Sequence)
+	s.callbacks.EventCreated(event)
 	log.L(ctx).Infof("Emitted %s event %s for %s:%s (correlator=%v,topic=%s)", event.Type, event.ID, event.Namespace, event.Reference, event.Correlator, event.Topic)
 }
 
@@ -196,7 +203,8 @@ func (s *SQLCommon) GetEventByID(ctx context.Context, namespace string, id *ffty
 	return event, nil
 }
 
-func (s *SQLCommon) getEventsGeneric(ctx context.Context, namespace string, sql sq.SelectBuilder, filter ffapi.Filter) (message []*core.Event, res *ffapi.FilterResult, err error) {
+// getEventsGeneric queries a single namespace when passed a string, or uses an IN clause when passed a slice of namespaces
+func (s *SQLCommon) getEventsGeneric(ctx context.Context, table string, namespace interface{}, sql sq.SelectBuilder, filter ffapi.Filter) (message []*core.Event, res *ffapi.FilterResult, err error) {
 	query, fop, fi, err := s.FilterSelect(
 		ctx, "", sql,
 		filter, eventFilterFieldMap, []interface{}{"sequence"}, sq.Eq{"namespace": namespace})
@@ -204,7 +212,7 @@ func (s *SQLCommon) getEventsGeneric(ctx context.Context, namespace string, sql
 		return nil, nil, err
 	}
 
-	rows, tx, err := s.Query(ctx, eventsTable, query)
+	rows, tx, err := s.Query(ctx, table, query)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -219,7 +227,49 @@ func (s *SQLCommon) getEventsGeneric(ctx context.Context, namespace string, sql
 		events = append(events, event)
 	}
 
-	return events, s.QueryRes(ctx, eventsTable, tx, fop, nil, fi), err
+	return events, s.QueryRes(ctx, table, tx, fop, nil, fi), err
+}
+
+func (s *SQLCommon) DeleteEvent(ctx context.Context, namespace string, id *fftypes.UUID) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	err = s.DeleteTx(ctx, eventsTable, tx, sq.Delete(eventsTable).Where(sq.Eq{"id": id, "namespace": namespace}),
+		nil /* no change events for purged events */)
+	if err != nil {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) DeleteEventsBefore(ctx context.Context, namespace string, sequence int64) (count int64, err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return -1, err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	where := sq.And{
+		sq.Eq{"namespace": namespace},
+		sq.Lt{s.SequenceColumn(): sequence},
+	}
+	count, err = s.CountQuery(ctx, eventsTable, tx, where, nil, "")
+	if err != nil {
+		return -1, err
+	}
+	if count > 0 {
+		err = s.DeleteTx(ctx, eventsTable, tx, sq.Delete(eventsTable).Where(where),
+			nil /* no change events for purged events */)
+		if err != nil {
+			return -1, err
+		}
+	}
+
+	return count, s.CommitTx(ctx, tx, autoCommit)
 }
 
 func (s *SQLCommon) GetEvents(ctx context.Context, namespace string, filter ffapi.Filter) (message []*core.Event, res *ffapi.FilterResult, err error) {
@@ -229,7 +279,101 @@ func (s *SQLCommon) GetEvents(ctx context.Context, namespace string, filter ffap
 
 	query := sq.Select(cols...).From(eventsTable)
 
-	return s.getEventsGeneric(ctx, namespace, query, filter)
+	return s.getEventsGeneric(ctx, eventsTable, namespace, query, filter)
+}
+
+func (s *SQLCommon) GetArchivedEvents(ctx context.Context, namespace string, filter ffapi.Filter) (message []*core.Event, res *ffapi.FilterResult, err error) {
+
+	cols := append([]string{}, eventColumns...)
+	cols = append(cols, s.SequenceColumn())
+
+	query := sq.Select(cols...).From(eventsArchiveTable)
+
+	return s.getEventsGeneric(ctx, eventsArchiveTable, namespace, query, filter)
+}
+
+func (s *SQLCommon) GetEventsInNamespaces(ctx context.Context, namespaces []string, filter ffapi.Filter) (message []*core.Event, res *ffapi.FilterResult, err error) {
+
+	cols := append([]string{}, eventColumns...)
+	cols = append(cols, s.SequenceColumn())
+
+	query := sq.Select(cols...).From(eventsTable)
+
+	return s.getEventsGeneric(ctx, eventsTable, namespaces, query, filter)
+}
+
+func (s *SQLCommon) GetArchivedEventsInNamespaces(ctx context.Context, namespaces []string, filter ffapi.Filter) (message []*core.Event, res *ffapi.FilterResult, err error) {
+
+	cols := append([]string{}, eventColumns...)
+	cols = append(cols, s.SequenceColumn())
+
+	query := sq.Select(cols...).From(eventsArchiveTable)
+
+	return s.getEventsGeneric(ctx, eventsArchiveTable, namespaces, query, filter)
+}
+
+func (s *SQLCommon) ArchiveEvents(ctx context.Context, namespace string, createdBefore *fftypes.FFTime, limit int) (count int64, err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return -1, err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	fb := database.EventQueryFactory.NewFilterLimit(ctx, uint64(limit))
+	oldest, _, err := s.GetEvents(ctx, namespace, fb.And().Sort("sequence").Ascending())
+	if err != nil {
+		return -1, err
+	}
+
+	// The created time is not guaranteed to increase with the sequence, so we stop at the first
+	// event that is not old enough. This means every archived event has a lower sequence than every
+	// event left in the events table, which is what allows readers to check the two tables in turn.
+	var toArchive []*core.Event
+	for _, event := range oldest {
+		if event.Created.UnixNano() >= createdBefore.UnixNano() {
+			break
+		}
+		toArchive = append(toArchive, event)
+	}
+	if len(toArchive) == 0 {
+		return 0, s.CommitTx(ctx, tx, autoCommit)
+	}
+
+	archiveColumns := append(append([]string{}, eventColumns...), s.SequenceColumn())
+	if s.Features().MultiRowInsert {
+		query := sq.Insert(eventsArchiveTable).Columns(archiveColumns...)
+		for _, event := range toArchive {
+			query = query.Values(append(s.eventInsertValues(event), event.Sequence)...)
+		}
+		sequences := make([]int64, len(toArchive))
+		if err = s.InsertTxRows(ctx, eventsArchiveTable, tx, query, nil, sequences, false); err != nil {
+			return -1, err
+		}
+	} else {
+		for _, event := range toArchive {
+			query := sq.Insert(eventsArchiveTable).Columns(archiveColumns...).
+				Values(append(s.eventInsertValues(event), event.Sequence)...)
+			if _, err = s.InsertTx(ctx, eventsArchiveTable, tx, query, nil); err != nil {
+				return -1, err
+			}
+		}
+	}
+
+	// Only delete the rows that were copied, as events can be committed out of sequence order
+	// and we must not remove one that was not visible to the query above
+	archivedIDs := make([]string, len(toArchive))
+	for i, event := range toArchive {
+		archivedIDs[i] = event.ID.String()
+	}
+	err = s.DeleteTx(ctx, eventsTable, tx, sq.Delete(eventsTable).Where(sq.And{
+		sq.Eq{"namespace": namespace},
+		sq.Eq{"id": archivedIDs},
+	}), nil /* no change events for archived events */)
+	if err != nil {
+		return -1, err
+	}
+
+	return int64(len(toArchive)), s.CommitTx(ctx, tx, autoCommit)
 }
 
 func (s *SQLCommon) GetEventsInSequenceRange(ctx context.Context, namespace string, filter ffapi.Filter, startSequence int, endSequence int) (message []*core.Event, res *ffapi.FilterResult, err error) {
@@ -244,5 +388,5 @@ func (s *SQLCommon) GetEventsInSequenceRange(ctx context.Context, namespace stri
 		"seq": endSequence,
 	})
 
-	return s.getEventsGeneric(ctx, namespace, query, filter)
+	return s.getEventsGeneric(ctx, eventsTable, namespace, query, filter)
 }
@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
@@ -50,6 +51,7 @@ func TestEventE2EWithDB(t *testing.T) {
 	}
 
 	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeCreated, "ns1", eventID, mock.Anything).Return()
+	s.eventCallbacks.On("EventCreated", mock.MatchedBy(func(e *core.Event) bool { return e.ID.Equals(eventID) })).Return()
 
 	err := s.InsertEvent(ctx, event)
 	assert.NoError(t, err)
@@ -87,6 +89,7 @@ func TestEventE2EWithDB(t *testing.T) {
 	assert.Equal(t, 0, len(events))
 
 	s.callbacks.AssertExpectations(t)
+	s.eventCallbacks.AssertExpectations(t)
 }
 
 func TestGetEventsInSequenceRangeE2EWithDB(t *testing.T) {
@@ -96,6 +99,7 @@ func TestGetEventsInSequenceRangeE2EWithDB(t *testing.T) {
 	ctx := context.Background()
 
 	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+	s.eventCallbacks.On("EventCreated", mock.Anything).Return()
 
 	numberOfEvents := 1000
 	var eventID *fftypes.UUID
@@ -192,6 +196,8 @@ func TestInsertEventsPreCommitMultiRowOK(t *testing.T) {
 	ev2 := &core.Event{ID: fftypes.NewUUID(), Namespace: "ns1"}
 	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeCreated, "ns1", ev1.ID, int64(1001))
 	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeCreated, "ns1", ev2.ID, int64(1002))
+	s.eventCallbacks.On("EventCreated", ev1)
+	s.eventCallbacks.On("EventCreated", ev2)
 
 	mock.ExpectBegin()
 	mock.ExpectExec("<acquire lock ns1>").WillReturnResult(driver.ResultNoRows)
@@ -210,6 +216,7 @@ func TestInsertEventsPreCommitMultiRowOK(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 	s.callbacks.AssertExpectations(t)
+	s.eventCallbacks.AssertExpectations(t)
 }
 
 func TestInsertEventsPreCommitMultiRowFail(t *testing.T) {
@@ -329,3 +336,329 @@ func TestGetEventsInSequenceRangeShouldCallGetEventsWhenNoSequencedProvidedAndTh
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestInsertEventDuplicateIDKeepsOriginal(t *testing.T) {
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	eventID := fftypes.NewUUID()
+	newEvent := func() *core.Event {
+		return &core.Event{
+			ID:        eventID,
+			Namespace: "ns1",
+			Type:      core.EventTypeMessageConfirmed,
+			Reference: fftypes.NewUUID(),
+			Topic:     "topic1",
+			Created:   fftypes.Now(),
+		}
+	}
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeCreated, "ns1", eventID, mock.Anything).Return().Once()
+	s.eventCallbacks.On("EventCreated", mock.MatchedBy(func(e *core.Event) bool { return e.ID.Equals(eventID) })).Return().Once()
+
+	event := newEvent()
+	err := s.InsertEvent(ctx, event)
+	assert.NoError(t, err)
+	originalSeq := event.Sequence
+	assert.Greater(t, originalSeq, int64(0))
+
+	// There is no upsert for events - the unique index on the ID rejects the
+	// duplicate, and no second event is emitted to listeners
+	err = s.InsertEvent(ctx, newEvent())
+	assert.Regexp(t, "FF00177", err)
+
+	fb := database.EventQueryFactory.NewFilter(ctx)
+	events, res, err := s.GetEvents(ctx, "ns1", fb.Eq("id", eventID.String()).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assert.Equal(t, originalSeq, events[0].Sequence)
+
+	s.callbacks.AssertExpectations(t)
+	s.eventCallbacks.AssertExpectations(t)
+}
+
+func TestDeleteEventsE2EWithDB(t *testing.T) {
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+	s.eventCallbacks.On("EventCreated", mock.Anything).Return()
+
+	events := make([]*core.Event, 4)
+	for i := range events {
+		events[i] = core.NewEvent(core.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "topic1")
+		err := s.InsertEvent(ctx, events[i])
+		assert.NoError(t, err)
+	}
+
+	// Delete a single event
+	err := s.DeleteEvent(ctx, "ns1", events[3].ID)
+	assert.NoError(t, err)
+	eventRead, err := s.GetEventByID(ctx, "ns1", events[3].ID)
+	assert.NoError(t, err)
+	assert.Nil(t, eventRead)
+	err = s.DeleteEvent(ctx, "ns1", events[3].ID)
+	assert.Regexp(t, "FF00167", err)
+
+	// Delete everything before the third event
+	count, err := s.DeleteEventsBefore(ctx, "ns1", events[2].Sequence)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	remaining, _, err := s.GetEvents(ctx, "ns1", database.EventQueryFactory.NewFilter(ctx).And())
+	assert.NoError(t, err)
+	assert.Len(t, remaining, 1)
+	assert.Equal(t, *events[2].ID, *remaining[0].ID)
+
+	count, err = s.DeleteEventsBefore(ctx, "ns1", events[2].Sequence)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestDeleteEventFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteEvent(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteEventFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteEvent(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteEventsBeforeFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	_, err := s.DeleteEventsBefore(context.Background(), "ns1", 100)
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteEventsBeforeFailCount(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT COUNT.*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.DeleteEventsBefore(context.Background(), "ns1", 100)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteEventsBeforeFailDelete(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT COUNT.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.DeleteEventsBefore(context.Background(), "ns1", 100)
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestArchiveEventsE2EWithDB(t *testing.T) {
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+	s.eventCallbacks.On("EventCreated", mock.Anything).Return()
+
+	// The third event is recent, so archiving must stop there even though the fourth is old
+	old := fftypes.FFTime(time.Now().Add(-1 * time.Hour))
+	events := make([]*core.Event, 4)
+	for i := range events {
+		events[i] = core.NewEvent(core.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "topic1")
+		if i != 2 {
+			events[i].Created = &old
+		}
+		err := s.InsertEvent(ctx, events[i])
+		assert.NoError(t, err)
+	}
+	cutoff := fftypes.FFTime(time.Now().Add(-1 * time.Minute))
+
+	count, err := s.ArchiveEvents(ctx, "ns1", &cutoff, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	count, err = s.ArchiveEvents(ctx, "ns1", &cutoff, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	count, err = s.ArchiveEvents(ctx, "ns1", &cutoff, 10)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	fb := database.EventQueryFactory.NewFilter(ctx)
+	archived, _, err := s.GetArchivedEvents(ctx, "ns1", fb.And().Sort("sequence").Ascending())
+	assert.NoError(t, err)
+	assert.Len(t, archived, 2)
+	for i, event := range archived {
+		eventJson, _ := json.Marshal(events[i])
+		archivedJson, _ := json.Marshal(event)
+		assert.Equal(t, string(eventJson), string(archivedJson))
+	}
+
+	remaining, _, err := s.GetEvents(ctx, "ns1", fb.And().Sort("sequence").Ascending())
+	assert.NoError(t, err)
+	assert.Len(t, remaining, 2)
+	assert.Equal(t, *events[2].ID, *remaining[0].ID)
+	assert.Equal(t, *events[3].ID, *remaining[1].ID)
+}
+
+func archiveTestEventRows(s *mockProvider, events ...*core.Event) *sqlmock.Rows {
+	rows := sqlmock.NewRows(append(append([]string{}, eventColumns...), s.SequenceColumn()))
+	for _, e := range events {
+		rows.AddRow(e.ID.String(), string(e.Type), e.Namespace, nil, nil, nil, e.Topic, e.Created.String(), e.Sequence)
+	}
+	return rows
+}
+
+func TestArchiveEventsMultiRowOK(t *testing.T) {
+	s := newMockProvider()
+	s.multiRowInsert = true
+	s.fakePSQLInsert = true
+	s, mock := s.init()
+	old := fftypes.FFTime(time.Now().Add(-1 * time.Hour))
+	ev1 := &core.Event{ID: fftypes.NewUUID(), Namespace: "ns1", Created: &old, Sequence: 1001}
+	ev2 := &core.Event{ID: fftypes.NewUUID(), Namespace: "ns1", Created: &old, Sequence: 1002}
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(archiveTestEventRows(s, ev1, ev2))
+	mock.ExpectQuery("INSERT INTO events_archive.*").WillReturnRows(sqlmock.NewRows([]string{s.SequenceColumn()}).
+		AddRow(int64(1001)).
+		AddRow(int64(1002)),
+	)
+	mock.ExpectExec("DELETE FROM events .*id IN .*").WithArgs("ns1", ev1.ID.String(), ev2.ID.String()).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	count, err := s.ArchiveEvents(context.Background(), "ns1", fftypes.Now(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestArchiveEventsMultiRowFail(t *testing.T) {
+	s := newMockProvider()
+	s.multiRowInsert = true
+	s.fakePSQLInsert = true
+	s, mock := s.init()
+	old := fftypes.FFTime(time.Now().Add(-1 * time.Hour))
+	ev1 := &core.Event{ID: fftypes.NewUUID(), Namespace: "ns1", Created: &old, Sequence: 1001}
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(archiveTestEventRows(s, ev1))
+	mock.ExpectQuery("INSERT INTO events_archive.*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.ArchiveEvents(context.Background(), "ns1", fftypes.Now(), 10)
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestArchiveEventsFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	_, err := s.ArchiveEvents(context.Background(), "ns1", fftypes.Now(), 10)
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestArchiveEventsFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.ArchiveEvents(context.Background(), "ns1", fftypes.Now(), 10)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestArchiveEventsFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	old := fftypes.FFTime(time.Now().Add(-1 * time.Hour))
+	ev1 := &core.Event{ID: fftypes.NewUUID(), Namespace: "ns1", Created: &old, Sequence: 1001}
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(archiveTestEventRows(s, ev1))
+	mock.ExpectExec("INSERT INTO events_archive.*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.ArchiveEvents(context.Background(), "ns1", fftypes.Now(), 10)
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestArchiveEventsFailDelete(t *testing.T) {
+	s, mock := newMockProvider().init()
+	old := fftypes.FFTime(time.Now().Add(-1 * time.Hour))
+	ev1 := &core.Event{ID: fftypes.NewUUID(), Namespace: "ns1", Created: &old, Sequence: 1001}
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(archiveTestEventRows(s, ev1))
+	mock.ExpectExec("INSERT INTO events_archive.*").WillReturnResult(sqlmock.NewResult(1001, 1))
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.ArchiveEvents(context.Background(), "ns1", fftypes.Now(), 10)
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetArchivedEventsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.EventQueryFactory.NewFilter(context.Background()).Eq("id", "")
+	_, _, err := s.GetArchivedEvents(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetEventsInNamespacesE2EWithDB(t *testing.T) {
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeCreated, mock.Anything, mock.Anything, mock.Anything).Return()
+	s.eventCallbacks.On("EventCreated", mock.Anything).Return()
+
+	old := fftypes.FFTime(time.Now().Add(-1 * time.Hour))
+	events := make([]*core.Event, 3)
+	for i, ns := range []string{"ns1", "ns2", "ns3"} {
+		events[i] = core.NewEvent(core.EventTypeMessageConfirmed, ns, fftypes.NewUUID(), nil, "topic1")
+		events[i].Created = &old
+		err := s.InsertEvent(ctx, events[i])
+		assert.NoError(t, err)
+	}
+
+	fb := database.EventQueryFactory.NewFilter(ctx)
+	found, _, err := s.GetEventsInNamespaces(ctx, []string{"ns1", "ns2"}, fb.And().Sort("sequence").Ascending())
+	assert.NoError(t, err)
+	assert.Len(t, found, 2)
+	assert.Equal(t, *events[0].ID, *found[0].ID)
+	assert.Equal(t, *events[1].ID, *found[1].ID)
+
+	cutoff := fftypes.FFTime(time.Now().Add(-1 * time.Minute))
+	for _, ns := range []string{"ns1", "ns2", "ns3"} {
+		_, err = s.ArchiveEvents(ctx, ns, &cutoff, 10)
+		assert.NoError(t, err)
+	}
+
+	archived, _, err := s.GetArchivedEventsInNamespaces(ctx, []string{"ns2", "ns3"}, fb.And().Sort("sequence").Ascending())
+	assert.NoError(t, err)
+	assert.Len(t, archived, 2)
+	assert.Equal(t, *events[1].ID, *archived[0].ID)
+	assert.Equal(t, *events[2].ID, *archived[1].ID)
+}
+
+func TestGetEventsInNamespacesQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.EventQueryFactory.NewFilter(context.Background()).Eq("id", "")
+	_, _, err := s.GetEventsInNamespaces(context.Background(), []string{"ns1", "ns2"}, f)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/hyperledger/firefly-common/pkg/dbsql"
@@ -57,6 +58,14 @@ var (
 		"tx_parent_id",
 		"batch_id",
 		"idempotency_key",
+		"content_type",
+		"tags",
+		"reply_to",
+		"conversation_id",
+		"received",
+		"ttl",
+		"expires",
+		"priority",
 	}
 	msgFilterFieldMap = map[string]string{
 		"type":           "mtype",
@@ -68,11 +77,16 @@ var (
 		"group":          "group_hash",
 		"idempotencykey": "idempotency_key",
 		"rejectreason":   "reject_reason",
+		"contenttype":    "content_type",
+		"replyto":        "reply_to",
+		"conversationid": "conversation_id",
+		"receivedat":     "received",
 	}
 )
 
 const messagesTable = "messages"
 const messagesDataJoinTable = "messages_data"
+const messagesTagsJoinTable = "messages_tags"
 
 func (s *SQLCommon) attemptMessageUpdate(ctx context.Context, tx *dbsql.TXWrapper, message *core.Message) (int64, error) {
 	var txParentID *fftypes.UUID
@@ -104,6 +118,12 @@ func (s *SQLCommon) attemptMessageUpdate(ctx context.Context, tx *dbsql.TXWrappe
 			Set("tx_parent_id", txParentID).
 			Set("batch_id", message.BatchID).
 			Set("idempotency_key", message.IdempotencyKey).
+			Set("content_type", message.Header.ContentType).
+			Set("reply_to", message.Header.ReplyTo).
+			Set("conversation_id", message.Header.ConversationID).
+			Set("ttl", message.Header.TTL).
+			Set("expires", messageExpiry(message)).
+			Set("priority", message.Header.Priority).
 			Where(sq.Eq{
 				"id":              message.Header.ID,
 				"hash":            message.Hash,
@@ -122,6 +142,9 @@ func (s *SQLCommon) setMessageInsertValues(query sq.InsertBuilder, message *core
 		txParentID = message.Header.TxParent.ID
 		txParentType = message.Header.TxParent.Type
 	}
+	if message.ReceivedAt == nil {
+		message.ReceivedAt = fftypes.Now()
+	}
 
 	return query.Values(
 		message.Header.ID,
@@ -147,9 +170,27 @@ func (s *SQLCommon) setMessageInsertValues(query sq.InsertBuilder, message *core
 		txParentID,
 		message.BatchID,
 		message.IdempotencyKey,
+		message.Header.ContentType,
+		message.Tags,
+		message.Header.ReplyTo,
+		message.Header.ConversationID,
+		message.ReceivedAt,
+		message.Header.TTL,
+		messageExpiry(message),
+		message.Header.Priority,
 	)
 }
 
+// messageExpiry is the time a message with a TTL expires, which is stored alongside the TTL so that
+// messages past their TTL can be queried
+func messageExpiry(message *core.Message) *fftypes.FFTime {
+	if message.Header.TTL == nil || message.Header.Created == nil {
+		return nil
+	}
+	expires := fftypes.FFTime(time.Time(*message.Header.Created).Add(time.Duration(*message.Header.TTL)))
+	return &expires
+}
+
 func (s *SQLCommon) attemptMessageInsert(ctx context.Context, tx *dbsql.TXWrapper, message *core.Message, requestConflictEmptyResult bool) (err error) {
 	message.Sequence, err = s.InsertTxExt(ctx, messagesTable, tx,
 		s.setMessageInsertValues(sq.Insert(messagesTable).Columns(msgColumns...), message),
@@ -173,9 +214,11 @@ func (s *SQLCommon) UpsertMessage(ctx context.Context, message *core.Message, op
 	// as only recovery paths require us to go down the un-optimized route.
 	optimized := false
 	recreateDatarefs := false
+	inserted := false
 	if optimization == database.UpsertOptimizationNew {
 		opErr := s.attemptMessageInsert(ctx, tx, message, true /* we want a failure here we can progress past */)
 		optimized = opErr == nil
+		inserted = optimized
 	} else if optimization == database.UpsertOptimizationExisting {
 		rowsAffected, opErr := s.attemptMessageUpdate(ctx, tx, message)
 		optimized = opErr == nil && rowsAffected == 1
@@ -214,6 +257,7 @@ func (s *SQLCommon) UpsertMessage(ctx context.Context, message *core.Message, op
 			if err = s.attemptMessageInsert(ctx, tx, message, false); err != nil {
 				return err
 			}
+			inserted = true
 		}
 	}
 
@@ -226,6 +270,13 @@ func (s *SQLCommon) UpsertMessage(ctx context.Context, message *core.Message, op
 		}
 	}
 
+	// The tags are immutable, so are only indexed when the message is first inserted
+	if inserted {
+		if err = s.insertMessageTags(ctx, tx, message); err != nil {
+			return err
+		}
+	}
+
 	for _, hook := range hooks {
 		tx.AddPostCommitHook(hook)
 	}
@@ -249,13 +300,23 @@ func (s *SQLCommon) InsertMessages(ctx context.Context, messages []*core.Message
 			"data_hash",
 			"data_idx",
 		)
+		tagQuery := sq.Insert(messagesTagsJoinTable).Columns(
+			"namespace",
+			"message_id",
+			"tag",
+		)
 		dataRefCount := 0
+		tagCount := 0
 		for _, message := range messages {
 			msgQuery = s.setMessageInsertValues(msgQuery, message)
 			for idx, dataRef := range message.Data {
 				dataRefQuery = dataRefQuery.Values(message.LocalNamespace, message.Header.ID, dataRef.ID, dataRef.Hash, idx)
 				dataRefCount++
 			}
+			for _, tag := range message.Tags {
+				tagQuery = tagQuery.Values(message.LocalNamespace, message.Header.ID, tag)
+				tagCount++
+			}
 		}
 		sequences := make([]int64, len(messages))
 
@@ -278,6 +339,15 @@ func (s *SQLCommon) InsertMessages(ctx context.Context, messages []*core.Message
 				return err
 			}
 		}
+
+		// Use a single multi-row insert for the tags
+		if tagCount > 0 {
+			tagSeqs := make([]int64, tagCount)
+			err = s.InsertTxRows(ctx, messagesTagsJoinTable, tx, tagQuery, nil, tagSeqs, false)
+			if err != nil {
+				return err
+			}
+		}
 	} else {
 		// Fall back to individual inserts grouped in a TX
 		for _, message := range messages {
@@ -289,6 +359,10 @@ func (s *SQLCommon) InsertMessages(ctx context.Context, messages []*core.Message
 			if err != nil {
 				return err
 			}
+			err = s.insertMessageTags(ctx, tx, message)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -377,6 +451,30 @@ func (s *SQLCommon) updateMessageDataRefs(ctx context.Context, tx *dbsql.TXWrapp
 
 }
 
+// insertMessageTags indexes each of the tags of a message in the join table, so that messages
+// can be looked up by an exact tag without scanning the tags column of every message
+func (s *SQLCommon) insertMessageTags(ctx context.Context, tx *dbsql.TXWrapper, message *core.Message) error {
+	for _, tag := range message.Tags {
+		if _, err := s.InsertTx(ctx, messagesTagsJoinTable, tx,
+			sq.Insert(messagesTagsJoinTable).
+				Columns(
+					"namespace",
+					"message_id",
+					"tag",
+				).
+				Values(
+					message.LocalNamespace,
+					message.Header.ID,
+					tag,
+				),
+			nil, // no change event
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Why not a LEFT JOIN you ask? ... well we need to be able to reliably perform a LIMIT on
 // the number of messages, and it seems there isn't a clean and cross-database
 // way for a single-query option. So a two-query option ended up being simplest.
@@ -437,6 +535,7 @@ func (s *SQLCommon) loadDataRefs(ctx context.Context, namespace string, msgs []*
 func (s *SQLCommon) msgResult(ctx context.Context, row *sql.Rows) (*core.Message, error) {
 	var msg core.Message
 	var txParent core.TransactionRef
+	var expires *fftypes.FFTime // derived from the TTL
 	err := row.Scan(
 		&msg.Header.ID,
 		&msg.Header.CID,
@@ -461,6 +560,14 @@ func (s *SQLCommon) msgResult(ctx context.Context, row *sql.Rows) (*core.Message
 		&txParent.ID,
 		&msg.BatchID,
 		&msg.IdempotencyKey,
+		&msg.Header.ContentType,
+		&msg.Tags,
+		&msg.Header.ReplyTo,
+		&msg.Header.ConversationID,
+		&msg.ReceivedAt,
+		&msg.Header.TTL,
+		&expires,
+		&msg.Header.Priority,
 		// Must be added to the list of columns in all selects
 		&msg.Sequence,
 	)
@@ -505,6 +612,76 @@ func (s *SQLCommon) GetMessageByID(ctx context.Context, namespace string, id *ff
 	return msg, nil
 }
 
+func (s *SQLCommon) GetMessageRef(ctx context.Context, namespace string, id *fftypes.UUID) (ref *core.IDAndSequence, err error) {
+
+	rows, _, err := s.Query(ctx, messagesTable,
+		sq.Select("id", s.SequenceColumn()).
+			From(messagesTable).
+			Where(sq.Eq{"id": id, "namespace_local": namespace}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Message '%s' not found", id)
+		return nil, nil
+	}
+
+	var idAndSeq core.IDAndSequence
+	if err = rows.Scan(&idAndSeq.ID, &idAndSeq.Sequence); err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, messagesTable)
+	}
+	return &idAndSeq, nil
+}
+
+func (s *SQLCommon) DeleteMessage(ctx context.Context, namespace string, id *fftypes.UUID, states []core.MessageState) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	msgRef, err := s.GetMessageRef(ctx, namespace, id)
+	if err != nil {
+		return err
+	}
+	if msgRef == nil {
+		return fftypes.DeleteRecordNotFound
+	}
+
+	// The state predicate ensures we never delete a message that has moved on since the caller checked it
+	err = s.DeleteTx(ctx, messagesTable, tx,
+		sq.Delete(messagesTable).
+			Where(sq.Eq{"id": id, "namespace_local": namespace, "state": states}),
+		func() {
+			s.callbacks.OrderedUUIDCollectionNSEvent(database.CollectionMessages, core.ChangeEventTypeDeleted, namespace, id, msgRef.Sequence)
+		},
+	)
+	if err != nil {
+		return err
+	}
+	err = s.DeleteTx(ctx, messagesDataJoinTable, tx,
+		sq.Delete(messagesDataJoinTable).
+			Where(sq.Eq{"message_id": id, "namespace": namespace}),
+		nil, // no change event
+	)
+	if err != nil && err != fftypes.DeleteRecordNotFound {
+		return err
+	}
+	err = s.DeleteTx(ctx, messagesTagsJoinTable, tx,
+		sq.Delete(messagesTagsJoinTable).
+			Where(sq.Eq{"message_id": id, "namespace": namespace}),
+		nil, // no change event
+	)
+	if err != nil && err != fftypes.DeleteRecordNotFound {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
 func (s *SQLCommon) getMessagesQuery(ctx context.Context, namespace string, query sq.SelectBuilder, fop sq.Sqlizer, fi *ffapi.FilterInfo, allowCount bool) (message []*core.Message, fr *ffapi.FilterResult, err error) {
 	if fi.Count && !allowCount {
 		return nil, nil, i18n.NewError(ctx, coremsgs.MsgFilterCountNotSupported)
@@ -596,19 +773,44 @@ func (s *SQLCommon) queryBatchIDs(ctx context.Context, query sq.SelectBuilder) (
 }
 
 func (s *SQLCommon) GetMessages(ctx context.Context, namespace string, filter ffapi.Filter) (message []*core.Message, fr *ffapi.FilterResult, err error) {
+	return s.getMessagesFiltered(ctx, namespace, filter, sq.Eq{"namespace_local": namespace})
+}
+
+func (s *SQLCommon) GetMessagesForTag(ctx context.Context, namespace, tag string, filter ffapi.Filter) (message []*core.Message, fr *ffapi.FilterResult, err error) {
+	return s.getMessagesFiltered(ctx, namespace, filter, sq.And{
+		sq.Eq{"namespace_local": namespace},
+		sq.Expr("id IN (SELECT message_id FROM "+messagesTagsJoinTable+" WHERE namespace = ? AND tag = ?)", namespace, tag),
+	})
+}
+
+func (s *SQLCommon) getMessagesFiltered(ctx context.Context, namespace string, filter ffapi.Filter, conditions sq.Sqlizer) (message []*core.Message, fr *ffapi.FilterResult, err error) {
 	cols := append([]string{}, msgColumns...)
 	cols = append(cols, s.SequenceColumn())
 	query, fop, fi, err := s.FilterSelect(ctx, "", sq.Select(cols...).From(messagesTable), filter, msgFilterFieldMap,
 		[]interface{}{
 			&ffapi.SortField{Field: "confirmed", Descending: true, Nulls: ffapi.NullsFirst},
 			&ffapi.SortField{Field: "created", Descending: true},
-		}, sq.Eq{"namespace_local": namespace})
+		}, conditions)
 	if err != nil {
 		return nil, nil, err
 	}
 	return s.getMessagesQuery(ctx, namespace, query, fop, fi, true)
 }
 
+func (s *SQLCommon) GetMessagesPastTTL(ctx context.Context, namespace string, now *fftypes.FFTime, limit int) (message []*core.Message, err error) {
+	cols := append([]string{}, msgColumns...)
+	cols = append(cols, s.SequenceColumn())
+	query := sq.Select(cols...).From(messagesTable).
+		Where(sq.And{
+			sq.Eq{"namespace_local": namespace, "confirmed": nil, "state": core.MessageStatePending},
+			sq.Lt{"expires": now},
+		}).
+		OrderBy("expires").
+		Limit(uint64(limit))
+	message, _, err = s.getMessagesQuery(ctx, namespace, query, nil, &ffapi.FilterInfo{}, false)
+	return message, err
+}
+
 func (s *SQLCommon) GetMessagesForData(ctx context.Context, namespace string, dataID *fftypes.UUID, filter ffapi.Filter) (message []*core.Message, fr *ffapi.FilterResult, err error) {
 	cols := make([]string, len(msgColumns)+1)
 	for i, col := range msgColumns {
@@ -656,3 +858,29 @@ func (s *SQLCommon) UpdateMessages(ctx context.Context, namespace string, filter
 
 	return s.CommitTx(ctx, tx, autoCommit)
 }
+
+func (s *SQLCommon) ExpireMessage(ctx context.Context, namespace string, id *fftypes.UUID) (expired bool, err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	// Conditional on the state, so a message confirmed after it was read as past its TTL is left alone
+	updated, err := s.UpdateTx(ctx, messagesTable, tx,
+		sq.Update(messagesTable).
+			Set("state", core.MessageStateExpired).
+			Where(sq.Eq{
+				"namespace_local": namespace,
+				"id":              id,
+				"confirmed":       nil,
+				"state":           core.MessageStatePending,
+			}),
+		nil, // no change events for state updates
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return updated > 0, s.CommitTx(ctx, tx, autoCommit)
+}
@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
@@ -55,12 +56,13 @@ func TestUpsertE2EWithDB(t *testing.T) {
 				Key:    "0x12345",
 				Author: "did:firefly:org/abcd",
 			},
-			Created:   fftypes.Now(),
-			Namespace: "ns12345",
-			Topics:    []string{"test1"},
-			Group:     nil,
-			DataHash:  fftypes.NewRandB32(),
-			TxType:    core.TransactionTypeUnpinned,
+			Created:     fftypes.Now(),
+			Namespace:   "ns12345",
+			Topics:      []string{"test1"},
+			Group:       nil,
+			DataHash:    fftypes.NewRandB32(),
+			TxType:      core.TransactionTypeUnpinned,
+			ContentType: "application/octet-stream",
 			TxParent: &core.TransactionRef{
 				Type: core.TransactionTypeTokenTransfer,
 				ID:   fftypes.NewUUID(),
@@ -69,6 +71,7 @@ func TestUpsertE2EWithDB(t *testing.T) {
 		Hash:      fftypes.NewRandB32(),
 		State:     core.MessageStateStaged,
 		Confirmed: nil,
+		Tags:      []string{"invoice", "urgent"},
 		Data: []*core.DataRef{
 			{ID: dataID1, Hash: rand1},
 			{ID: dataID2, Hash: rand2},
@@ -91,6 +94,15 @@ func TestUpsertE2EWithDB(t *testing.T) {
 	msgReadJson, _ := json.Marshal(&msgRead)
 	assert.Equal(t, string(msgJson), string(msgReadJson))
 
+	// Check the lightweight reference lookup matches
+	msgRef, err := s.GetMessageRef(ctx, "ns12345", msgID)
+	assert.NoError(t, err)
+	assert.Equal(t, *msgID, msgRef.ID)
+	assert.Equal(t, msgRead.Sequence, msgRef.Sequence)
+	msgRef, err = s.GetMessageRef(ctx, "ns12345", fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, msgRef)
+
 	// Update the message (this is testing what's possible at the database layer,
 	// and does not account for the verification that happens at the higher level)
 	cid := fftypes.NewUUID()
@@ -106,13 +118,16 @@ func TestUpsertE2EWithDB(t *testing.T) {
 				Key:    "0x12345",
 				Author: "did:firefly:org/abcd",
 			},
-			Created:   fftypes.Now(),
-			Namespace: "ns12345",
-			Topics:    []string{"topic1", "topic2"},
-			Tag:       "tag_1",
-			Group:     gid,
-			DataHash:  fftypes.NewRandB32(),
-			TxType:    core.TransactionTypeBatchPin,
+			Created:        fftypes.Now(),
+			Namespace:      "ns12345",
+			Topics:         []string{"topic1", "topic2"},
+			Tag:            "tag_1",
+			Group:          gid,
+			ReplyTo:        fftypes.NewUUID(),
+			ConversationID: fftypes.NewUUID(),
+			DataHash:       fftypes.NewRandB32(),
+			TxType:         core.TransactionTypeBatchPin,
+			Priority:       200,
 			TxParent: &core.TransactionRef{
 				Type: core.TransactionTypeTokenTransfer,
 				ID:   fftypes.NewUUID(),
@@ -124,6 +139,7 @@ func TestUpsertE2EWithDB(t *testing.T) {
 		Confirmed:      fftypes.Now(),
 		BatchID:        bid,
 		IdempotencyKey: "myBusinessIdentifier",
+		Tags:           msg.Tags,
 		Data: []*core.DataRef{
 			{ID: dataID1, Hash: rand1},
 			{ID: dataID2, Hash: rand2}, // Note the data refs cannot change, as it would affect the hash, and the hash is immutable
@@ -139,6 +155,7 @@ func TestUpsertE2EWithDB(t *testing.T) {
 	assert.Equal(t, database.HashMismatch, err)
 
 	msgUpdated.Hash = msg.Hash
+	msgUpdated.ReceivedAt = msg.ReceivedAt // received time is not changed by an update
 	hookCalled := make(chan struct{}, 1)
 	err = s.UpsertMessage(context.Background(), msgUpdated, database.UpsertOptimizationExisting, func() {
 		close(hookCalled)
@@ -155,6 +172,15 @@ func TestUpsertE2EWithDB(t *testing.T) {
 	msgReadJson, _ = json.Marshal(&msgRead)
 	assert.Equal(t, string(msgJson), string(msgReadJson))
 
+	// Check the tags are immutable once the message is created
+	msgChangedTags := *msgUpdated
+	msgChangedTags.Tags = []string{"changed"}
+	err = s.UpsertMessage(context.Background(), &msgChangedTags, database.UpsertOptimizationExisting)
+	assert.NoError(t, err)
+	msgRead, err = s.GetMessageByID(ctx, "ns12345", msgID)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.FFStringArray{"invoice", "urgent"}, msgRead.Tags)
+
 	// Query back the message
 	fb := database.MessageQueryFactory.NewFilter(ctx)
 	filter := fb.And(
@@ -164,7 +190,10 @@ func TestUpsertE2EWithDB(t *testing.T) {
 		fb.Eq("topics", msgUpdated.Header.Topics),
 		fb.Eq("group", msgUpdated.Header.Group),
 		fb.Eq("cid", msgUpdated.Header.CID),
+		fb.Eq("replyto", msgUpdated.Header.ReplyTo),
+		fb.Eq("conversationid", msgUpdated.Header.ConversationID),
 		fb.Eq("idempotencykey", msgUpdated.IdempotencyKey),
+		fb.Eq("priority", 200),
 		fb.Gt("created", "0"),
 		fb.Gt("confirmed", "0"),
 	)
@@ -175,6 +204,15 @@ func TestUpsertE2EWithDB(t *testing.T) {
 	msgReadJson, _ = json.Marshal(msgs[0])
 	assert.Equal(t, string(msgJson), string(msgReadJson))
 
+	// Tags match exactly, through the join table
+	msgs, res, err = s.GetMessagesForTag(ctx, "ns12345", "invoice", filter.Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(msgs))
+	assert.Equal(t, int64(1), *res.TotalCount)
+	msgs, _, err = s.GetMessagesForTag(ctx, "ns12345", "inv", fb.And())
+	assert.NoError(t, err)
+	assert.Empty(t, msgs)
+
 	msgIDs, err := s.GetMessageIDs(ctx, "ns12345", filter)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(msgIDs))
@@ -200,6 +238,16 @@ func TestUpsertE2EWithDB(t *testing.T) {
 	msgReadJson, _ = json.Marshal(msgs[0])
 	assert.Equal(t, string(msgJson), string(msgReadJson))
 
+	// Check we can get it as part of its conversation, or as the start of a conversation
+	msgs, _, err = s.GetMessages(ctx, "ns12345", fb.And(fb.Eq("conversationid", msgUpdated.Header.ConversationID)))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(msgs))
+	msgReadJson, _ = json.Marshal(msgs[0])
+	assert.Equal(t, string(msgJson), string(msgReadJson))
+	msgs, _, err = s.GetMessages(ctx, "ns12345", fb.And(fb.Eq("conversationid", fftypes.NewUUID())))
+	assert.NoError(t, err)
+	assert.Empty(t, msgs)
+
 	// Negative test on filter
 	filter = fb.And(
 		fb.Eq("id", msgUpdated.Header.ID.String()),
@@ -307,6 +355,19 @@ func TestUpsertMessageFailUpdateRefs(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUpsertMessageFailInsertTags(t *testing.T) {
+	s, mock := newMockProvider().init()
+	msgID := fftypes.NewUUID()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec("INSERT .*messages").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT .*messages_tags").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertMessage(context.Background(), &core.Message{Header: core.MessageHeader{ID: msgID}, Tags: []string{"invoice"}}, database.UpsertOptimizationSkip)
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUpsertMessageFailCommit(t *testing.T) {
 	s, mock := newMockProvider().init()
 	msgID := fftypes.NewUUID()
@@ -376,6 +437,23 @@ func TestInsertMessagesMultiRowDataRefsFail(t *testing.T) {
 	s.callbacks.AssertExpectations(t)
 }
 
+func TestInsertMessagesMultiRowTagsFail(t *testing.T) {
+	s := newMockProvider()
+	s.multiRowInsert = true
+	s.fakePSQLInsert = true
+	s, mock := s.init()
+
+	msg1 := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID(), Namespace: "ns1"}, Tags: []string{"invoice"}}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT.*messages").WillReturnRows(sqlmock.NewRows([]string{s.SequenceColumn()}).AddRow(int64(1001)))
+	mock.ExpectQuery("INSERT.*messages_tags").WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertMessages(context.Background(), []*core.Message{msg1})
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	s.callbacks.AssertExpectations(t)
+}
+
 func TestInsertMessagesMultiRowFail(t *testing.T) {
 	s := newMockProvider()
 	s.multiRowInsert = true
@@ -413,6 +491,18 @@ func TestInsertMessagesSingleRowFailDataRefs(t *testing.T) {
 	s.callbacks.AssertExpectations(t)
 }
 
+func TestInsertMessagesSingleRowFailTags(t *testing.T) {
+	s, mock := newMockProvider().init()
+	msg1 := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID(), Namespace: "ns1"}, Tags: []string{"invoice"}}
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT.*messages").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT.*messages_tags").WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertMessages(context.Background(), []*core.Message{msg1})
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	s.callbacks.AssertExpectations(t)
+}
+
 func TestReplaceMessageFailBegin(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
@@ -576,13 +666,146 @@ func TestGetMessageByIDLoadRefsFail(t *testing.T) {
 	cols := append([]string{}, msgColumns...)
 	cols = append(cols, "id()")
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(cols).
-		AddRow(msgID.String(), nil, core.MessageTypeBroadcast, "author1", "0x12345", 0, "ns1", "ns1", "t1", "c1", nil, b32.String(), b32.String(), b32.String(), "confirmed", 0, "", "pin", nil, "", nil, nil, "bob", 0))
+		AddRow(msgID.String(), nil, core.MessageTypeBroadcast, "author1", "0x12345", 0, "ns1", "ns1", "t1", "c1", nil, b32.String(), b32.String(), b32.String(), "confirmed", 0, "", "pin", nil, "", nil, nil, "bob", "", "", nil, nil, nil, nil, nil, 0, 0))
 	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
 	_, err := s.GetMessageByID(context.Background(), "ns1", msgID)
 	assert.Regexp(t, "FF00176", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestGetMessageRefSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	msgID := fftypes.NewUUID()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetMessageRef(context.Background(), "ns1", msgID)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMessageRefScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	msgID := fftypes.NewUUID()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetMessageRef(context.Background(), "ns1", msgID)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMessageE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	msgID := fftypes.NewUUID()
+	dataID := fftypes.NewUUID()
+	msg := &core.Message{
+		LocalNamespace: "ns1",
+		Header: core.MessageHeader{
+			ID:        msgID,
+			Type:      core.MessageTypeBroadcast,
+			Namespace: "ns1",
+			Topics:    []string{"test1"},
+			Created:   fftypes.Now(),
+			DataHash:  fftypes.NewRandB32(),
+		},
+		Hash:  fftypes.NewRandB32(),
+		State: core.MessageStateRejected,
+		Tags:  []string{"invoice"},
+		Data: []*core.DataRef{
+			{ID: dataID, Hash: fftypes.NewRandB32()},
+		},
+	}
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, core.ChangeEventTypeCreated, "ns1", msgID, mock.Anything).Return()
+	err := s.UpsertMessage(ctx, msg, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+
+	// Not deleted when the state does not match
+	err = s.DeleteMessage(ctx, "ns1", msgID, []core.MessageState{core.MessageStateConfirmed})
+	assert.Equal(t, fftypes.DeleteRecordNotFound, err)
+	msgRead, err := s.GetMessageByID(ctx, "ns1", msgID)
+	assert.NoError(t, err)
+	assert.NotNil(t, msgRead)
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, core.ChangeEventTypeDeleted, "ns1", msgID, msg.Sequence).Return()
+	err = s.DeleteMessage(ctx, "ns1", msgID, []core.MessageState{core.MessageStateRejected})
+	assert.NoError(t, err)
+
+	msgRead, err = s.GetMessageByID(ctx, "ns1", msgID)
+	assert.NoError(t, err)
+	assert.Nil(t, msgRead)
+	var refCount int
+	err = s.DB().QueryRow("SELECT COUNT(*) FROM messages_data WHERE message_id = $1", msgID).Scan(&refCount)
+	assert.NoError(t, err)
+	assert.Zero(t, refCount)
+	err = s.DB().QueryRow("SELECT COUNT(*) FROM messages_tags WHERE message_id = $1", msgID).Scan(&refCount)
+	assert.NoError(t, err)
+	assert.Zero(t, refCount)
+
+	// Deleting a message that does not exist returns not found
+	err = s.DeleteMessage(ctx, "ns1", msgID, []core.MessageState{core.MessageStateRejected})
+	assert.Equal(t, fftypes.DeleteRecordNotFound, err)
+
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestDeleteMessageFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteMessage(context.Background(), "ns1", fftypes.NewUUID(), []core.MessageState{core.MessageStateRejected})
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMessageFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteMessage(context.Background(), "ns1", fftypes.NewUUID(), []core.MessageState{core.MessageStateRejected})
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMessageFailDeleteMessage(t *testing.T) {
+	s, mock := newMockProvider().init()
+	msgID := fftypes.NewUUID()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "seq"}).AddRow(msgID.String(), 12345))
+	mock.ExpectExec("DELETE .*messages.*state").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteMessage(context.Background(), "ns1", msgID, []core.MessageState{core.MessageStateRejected})
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMessageFailDeleteDataRefs(t *testing.T) {
+	s, mock := newMockProvider().init()
+	msgID := fftypes.NewUUID()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "seq"}).AddRow(msgID.String(), 12345))
+	mock.ExpectExec("DELETE .*messages").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE .*messages_data").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteMessage(context.Background(), "ns1", msgID, []core.MessageState{core.MessageStateRejected})
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMessageFailDeleteTags(t *testing.T) {
+	s, mock := newMockProvider().init()
+	msgID := fftypes.NewUUID()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "seq"}).AddRow(msgID.String(), 12345))
+	mock.ExpectExec("DELETE .*messages").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE .*messages_data").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectExec("DELETE .*messages_tags").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteMessage(context.Background(), "ns1", msgID, []core.MessageState{core.MessageStateRejected})
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetMessagesBuildQueryFail(t *testing.T) {
 	s, _ := newMockProvider().init()
 	f := database.MessageQueryFactory.NewFilter(context.Background()).Eq("id", map[bool]bool{true: false})
@@ -623,7 +846,7 @@ func TestGetMessagesLoadRefsFail(t *testing.T) {
 	cols := append([]string{}, msgColumns...)
 	cols = append(cols, "id()")
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(cols).
-		AddRow(msgID.String(), nil, core.MessageTypeBroadcast, "author1", "0x12345", 0, "ns1", "ns1", "t1", "c1", nil, b32.String(), b32.String(), b32.String(), "confirmed", 0, "", "pin", nil, "", nil, nil, "bob", 0))
+		AddRow(msgID.String(), nil, core.MessageTypeBroadcast, "author1", "0x12345", 0, "ns1", "ns1", "t1", "c1", nil, b32.String(), b32.String(), b32.String(), "confirmed", 0, "", "pin", nil, "", nil, nil, "bob", "", "", nil, nil, nil, nil, nil, 0, 0))
 	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
 	f := database.MessageQueryFactory.NewFilter(context.Background()).Gt("confirmed", "0")
 	_, _, err := s.GetMessages(context.Background(), "ns1", f)
@@ -709,3 +932,107 @@ func TestGetBatchIDsForMessagesScanFail(t *testing.T) {
 	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestGetMessagesPastTTL(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	ttl := fftypes.FFDuration(1 * time.Minute)
+	created := fftypes.FFTime(time.Now().Add(-5 * time.Minute))
+	msgExpired := &core.Message{
+		Header: core.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Type:      core.MessageTypeBroadcast,
+			Namespace: "ns1",
+			Created:   &created,
+			DataHash:  fftypes.NewRandB32(),
+			TTL:       &ttl,
+		},
+		Hash:           fftypes.NewRandB32(),
+		LocalNamespace: "ns1",
+		State:          core.MessageStatePending,
+	}
+	msgSent := &core.Message{
+		Header: core.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Type:      core.MessageTypeBroadcast,
+			Namespace: "ns1",
+			Created:   &created,
+			DataHash:  fftypes.NewRandB32(),
+			TTL:       &ttl,
+		},
+		Hash:           fftypes.NewRandB32(),
+		LocalNamespace: "ns1",
+		State:          core.MessageStateSent,
+	}
+	msgNoTTL := &core.Message{
+		Header: core.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Type:      core.MessageTypeBroadcast,
+			Namespace: "ns1",
+			Created:   &created,
+			DataHash:  fftypes.NewRandB32(),
+		},
+		Hash:           fftypes.NewRandB32(),
+		LocalNamespace: "ns1",
+		State:          core.MessageStateSent,
+	}
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, core.ChangeEventTypeUpdated, "ns1", msgExpired.Header.ID, mock.Anything).Return()
+	err := s.InsertMessages(ctx, []*core.Message{msgExpired, msgSent, msgNoTTL})
+	assert.NoError(t, err)
+
+	msgs, err := s.GetMessagesPastTTL(ctx, "ns1", fftypes.Now(), 10)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+	assert.Equal(t, msgExpired.Header.ID, msgs[0].Header.ID)
+	assert.Equal(t, ttl, *msgs[0].Header.TTL)
+
+	msgs, err = s.GetMessagesPastTTL(ctx, "ns1", &created, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, msgs)
+
+	// Messages sent by this node are never expired
+	expired, err := s.ExpireMessage(ctx, "ns1", msgSent.Header.ID)
+	assert.NoError(t, err)
+	assert.False(t, expired)
+
+	expired, err = s.ExpireMessage(ctx, "ns1", msgExpired.Header.ID)
+	assert.NoError(t, err)
+	assert.True(t, expired)
+	msgs, err = s.GetMessagesPastTTL(ctx, "ns1", fftypes.Now(), 10)
+	assert.NoError(t, err)
+	assert.Empty(t, msgs)
+
+	// A second expiry is a no-op
+	expired, err = s.ExpireMessage(ctx, "ns1", msgExpired.Header.ID)
+	assert.NoError(t, err)
+	assert.False(t, expired)
+}
+
+func TestExpireMessageFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	_, err := s.ExpireMessage(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExpireMessageFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.ExpireMessage(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00178", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMessagesPastTTLQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetMessagesPastTTL(context.Background(), "ns1", fftypes.Now(), 10)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,117 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	"github.com/hyperledger/firefly-common/pkg/dbsql"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+// Plugins that need their own tables register a directory of migrations, laid out the same way as
+// the core migrations with a sub-directory for each database provider (such as "postgres" and "sqlite").
+// Each plugin's migrations are tracked in a separate migrations table, so the version numbers of
+// one plugin never collide with the core migrations, or with those of any other plugin.
+
+// MigrationsTableProvider is implemented by database providers that can apply registered plugin migrations
+type MigrationsTableProvider interface {
+	// GetMigrationDriverForTable returns a migration driver that tracks versions in the given table
+	GetMigrationDriverForTable(db *sql.DB, migrationsTable string) (migratedb.Driver, error)
+}
+
+type pluginMigrations struct {
+	pluginName    string
+	migrationsDir string
+}
+
+var (
+	migrationRegistryLock sync.Mutex
+	migrationRegistry     = map[string]string{}
+	migrationsPluginName  = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+)
+
+// RegisterMigrations registers a directory of migrations for a plugin. All registered migrations
+// are applied after the core migrations, in alphabetical order of plugin name, when automatic
+// migrations are enabled.
+func RegisterMigrations(pluginName, migrationsDir string) error {
+	if !migrationsPluginName.MatchString(pluginName) {
+		return i18n.NewError(context.Background(), coremsgs.MsgInvalidMigrationsPluginName, pluginName)
+	}
+	migrationRegistryLock.Lock()
+	defer migrationRegistryLock.Unlock()
+	if existing, ok := migrationRegistry[pluginName]; ok && existing != migrationsDir {
+		return i18n.NewError(context.Background(), coremsgs.MsgMigrationsAlreadyRegistered, pluginName, existing)
+	}
+	migrationRegistry[pluginName] = migrationsDir
+	return nil
+}
+
+func registeredMigrations() []*pluginMigrations {
+	migrationRegistryLock.Lock()
+	defer migrationRegistryLock.Unlock()
+	registered := make([]*pluginMigrations, 0, len(migrationRegistry))
+	for pluginName, migrationsDir := range migrationRegistry {
+		registered = append(registered, &pluginMigrations{
+			pluginName:    pluginName,
+			migrationsDir: migrationsDir,
+		})
+	}
+	sort.Slice(registered, func(i, j int) bool {
+		return registered[i].pluginName < registered[j].pluginName
+	})
+	return registered
+}
+
+func (s *SQLCommon) applyRegisteredMigrations(ctx context.Context, provider dbsql.Provider) error {
+	registered := registeredMigrations()
+	if len(registered) == 0 {
+		return nil
+	}
+	tp, ok := provider.(MigrationsTableProvider)
+	if !ok {
+		return i18n.NewError(ctx, coremsgs.MsgPluginMigrationsNotSupported, provider.Name())
+	}
+	for _, pm := range registered {
+		driver, err := tp.GetMigrationDriverForTable(s.DB(), fmt.Sprintf("schema_migrations_%s", pm.pluginName))
+		if err == nil {
+			fileURL := "file://" + path.Join(pm.migrationsDir, provider.MigrationsDir())
+			log.L(ctx).Infof("Running migrations for plugin '%s' in: %s", pm.pluginName, fileURL)
+			var m *migrate.Migrate
+			m, err = migrate.NewWithDatabaseInstance(fileURL, provider.MigrationsDir(), driver)
+			if err == nil {
+				err = m.Up()
+				version, dirty, _ := m.Version()
+				log.L(ctx).Infof("Migrations for plugin '%s' now at: v=%d dirty=%t", pm.pluginName, version, dirty)
+			}
+		}
+		if err != nil && err != migrate.ErrNoChange {
+			return i18n.WrapError(ctx, err, coremsgs.MsgPluginMigrationFailed, pm.pluginName)
+		}
+	}
+	return nil
+}
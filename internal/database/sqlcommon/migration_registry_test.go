@@ -0,0 +1,118 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func resetMigrationRegistry() {
+	migrationRegistryLock.Lock()
+	defer migrationRegistryLock.Unlock()
+	migrationRegistry = map[string]string{}
+}
+
+func writeTestMigration(t *testing.T, dir, name, sql string) {
+	err := os.MkdirAll(path.Join(dir, "sqlite"), 0755)
+	assert.NoError(t, err)
+	err = os.WriteFile(path.Join(dir, "sqlite", name), []byte(sql), 0644)
+	assert.NoError(t, err)
+}
+
+func TestRegisterMigrationsBadName(t *testing.T) {
+	defer resetMigrationRegistry()
+	err := RegisterMigrations("my-plugin", "/some/dir")
+	assert.Regexp(t, "FF10488", err)
+}
+
+func TestRegisterMigrationsDuplicate(t *testing.T) {
+	defer resetMigrationRegistry()
+	err := RegisterMigrations("plugin_a", "/some/dir")
+	assert.NoError(t, err)
+	err = RegisterMigrations("plugin_a", "/some/dir")
+	assert.NoError(t, err)
+	err = RegisterMigrations("plugin_a", "/other/dir")
+	assert.Regexp(t, "FF10489", err)
+}
+
+func TestApplyRegisteredMigrationsE2EWithDB(t *testing.T) {
+	defer resetMigrationRegistry()
+
+	// Both plugins start at version 1, which would collide in a shared migrations table
+	dirA := t.TempDir()
+	writeTestMigration(t, dirA, "000001_create_widgets_table.up.sql", "CREATE TABLE widgets (id INTEGER);")
+	writeTestMigration(t, dirA, "000001_create_widgets_table.down.sql", "DROP TABLE widgets;")
+	dirB := t.TempDir()
+	writeTestMigration(t, dirB, "000001_add_widget_colors.up.sql", "CREATE TABLE widget_colors (widget_id INTEGER, color VARCHAR(64));")
+	writeTestMigration(t, dirB, "000001_add_widget_colors.down.sql", "DROP TABLE widget_colors;")
+	writeTestMigration(t, dirB, "000002_add_widget_colors_index.up.sql", "CREATE INDEX widget_colors_widget ON widget_colors(widget_id);")
+	writeTestMigration(t, dirB, "000002_add_widget_colors_index.down.sql", "DROP INDEX widget_colors_widget;")
+	assert.NoError(t, RegisterMigrations("plugin_b", dirB))
+	assert.NoError(t, RegisterMigrations("plugin_a", dirA))
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+
+	var version int
+	err := s.DB().QueryRow("SELECT version FROM schema_migrations_plugin_a").Scan(&version)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, version)
+	err = s.DB().QueryRow("SELECT version FROM schema_migrations_plugin_b").Scan(&version)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, version)
+	_, err = s.DB().Exec("INSERT INTO widget_colors (widget_id, color) VALUES (1, 'blue')")
+	assert.NoError(t, err)
+
+	// Re-applying is a no-op
+	err = s.applyRegisteredMigrations(context.Background(), s)
+	assert.NoError(t, err)
+}
+
+func TestApplyRegisteredMigrationsFail(t *testing.T) {
+	defer resetMigrationRegistry()
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	writeTestMigration(t, dir, "000001_bad.up.sql", "NOT VALID SQL;")
+	writeTestMigration(t, dir, "000001_bad.down.sql", "")
+	assert.NoError(t, RegisterMigrations("plugin_a", dir))
+
+	err := s.applyRegisteredMigrations(context.Background(), s)
+	assert.Regexp(t, "FF10491.*plugin_a", err)
+}
+
+func TestApplyRegisteredMigrationsNotSupported(t *testing.T) {
+	defer resetMigrationRegistry()
+	assert.NoError(t, RegisterMigrations("plugin_a", "/some/dir"))
+
+	mp := newMockProvider()
+	err := mp.applyRegisteredMigrations(context.Background(), mp)
+	assert.Regexp(t, "FF10490.*mockdb", err)
+}
+
+func TestApplyRegisteredMigrationsNone(t *testing.T) {
+	mp := newMockProvider()
+	err := mp.applyRegisteredMigrations(context.Background(), mp)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,156 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// cloneID derives the UUID of a cloned object as a UUIDv5, using the original UUID as the
+// namespace and the destination namespace as the name, so repeated clones are idempotent
+func cloneID(id *fftypes.UUID, dest string) *fftypes.UUID {
+	if id == nil {
+		return nil
+	}
+	u := fftypes.UUID(uuid.NewSHA1(uuid.UUID(*id), []byte(dest)))
+	return &u
+}
+
+func (s *SQLCommon) cloneDatatypes(ctx context.Context, src, dest string) error {
+	datatypes, _, err := s.GetDatatypes(ctx, src, database.DatatypeQueryFactory.NewFilter(ctx).And())
+	if err != nil {
+		return err
+	}
+	for _, dt := range datatypes {
+		dt.ID = cloneID(dt.ID, dest)
+		dt.Message = cloneID(dt.Message, dest)
+		dt.Namespace = dest
+		if err := s.UpsertDatatype(ctx, dt, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLCommon) cloneData(ctx context.Context, src, dest string) error {
+	data, _, err := s.GetData(ctx, src, database.DataQueryFactory.NewFilter(ctx).And())
+	if err != nil {
+		return err
+	}
+	for _, d := range data {
+		d.ID = cloneID(d.ID, dest)
+		d.Namespace = dest
+		if err := s.UpsertData(ctx, d, database.UpsertOptimizationSkip, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLCommon) cloneIdentities(ctx context.Context, src, dest string) error {
+	identities, _, err := s.GetIdentities(ctx, src, database.IdentityQueryFactory.NewFilter(ctx).And())
+	if err != nil {
+		return err
+	}
+	for _, identity := range identities {
+		identity.ID = cloneID(identity.ID, dest)
+		identity.Parent = cloneID(identity.Parent, dest)
+		identity.Namespace = dest
+		identity.Messages.Claim = cloneID(identity.Messages.Claim, dest)
+		identity.Messages.Verification = cloneID(identity.Messages.Verification, dest)
+		identity.Messages.Update = cloneID(identity.Messages.Update, dest)
+		if err := s.UpsertIdentity(ctx, identity, database.UpsertOptimizationSkip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cloneGroups returns a map from the hash of each source group to the hash of its clone,
+// as the namespace and member nodes contribute to the group hash
+func (s *SQLCommon) cloneGroups(ctx context.Context, src, dest string) (map[fftypes.Bytes32]*fftypes.Bytes32, error) {
+	groups, _, err := s.GetGroups(ctx, src, database.GroupQueryFactory.NewFilter(ctx).And())
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[fftypes.Bytes32]*fftypes.Bytes32, len(groups))
+	for _, group := range groups {
+		srcHash := *group.Hash
+		group.Namespace = dest
+		group.LocalNamespace = dest
+		group.Message = cloneID(group.Message, dest)
+		for _, member := range group.Members {
+			member.Node = cloneID(member.Node, dest)
+		}
+		group.Seal()
+		if err := s.UpsertGroup(ctx, group, database.UpsertOptimizationSkip); err != nil {
+			return nil, err
+		}
+		hashes[srcHash] = group.Hash
+	}
+	return hashes, nil
+}
+
+func (s *SQLCommon) cloneMessages(ctx context.Context, src, dest string, groupHashes map[fftypes.Bytes32]*fftypes.Bytes32) error {
+	msgs, _, err := s.GetMessages(ctx, src, database.MessageQueryFactory.NewFilter(ctx).And())
+	if err != nil {
+		return err
+	}
+	for _, msg := range msgs {
+		msg.Header.ID = cloneID(msg.Header.ID, dest)
+		msg.Header.CID = cloneID(msg.Header.CID, dest)
+		msg.Header.ReplyTo = cloneID(msg.Header.ReplyTo, dest)
+		msg.Header.ConversationID = cloneID(msg.Header.ConversationID, dest)
+		msg.Header.Namespace = dest
+		msg.LocalNamespace = dest
+		if msg.Header.Group != nil && groupHashes[*msg.Header.Group] != nil {
+			msg.Header.Group = groupHashes[*msg.Header.Group]
+		}
+		for _, dr := range msg.Data {
+			dr.ID = cloneID(dr.ID, dest)
+		}
+		msg.Header.DataHash = msg.Data.Hash()
+		msg.Hash = msg.Header.Hash()
+		if err := s.UpsertMessage(ctx, msg, database.UpsertOptimizationSkip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLCommon) CloneNamespace(ctx context.Context, src, dest string) error {
+	return s.RunAsGroup(ctx, func(ctx context.Context) error {
+		if err := s.cloneDatatypes(ctx, src, dest); err != nil {
+			return err
+		}
+		if err := s.cloneData(ctx, src, dest); err != nil {
+			return err
+		}
+		if err := s.cloneIdentities(ctx, src, dest); err != nil {
+			return err
+		}
+		groupHashes, err := s.cloneGroups(ctx, src, dest)
+		if err != nil {
+			return err
+		}
+		return s.cloneMessages(ctx, src, dest, groupHashes)
+	})
+}
@@ -0,0 +1,283 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCloneNamespaceE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	s.callbacks.On("HashCollectionNSEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	datatype := &core.Datatype{
+		ID:        fftypes.NewUUID(),
+		Message:   fftypes.NewUUID(),
+		Validator: core.ValidatorTypeJSON,
+		Namespace: "ns1",
+		Name:      "dt1",
+		Version:   "1.0",
+		Value:     fftypes.JSONAnyPtr(`{}`),
+		Hash:      fftypes.NewRandB32(),
+		Created:   fftypes.Now(),
+	}
+	err := s.UpsertDatatype(ctx, datatype, false)
+	assert.NoError(t, err)
+
+	data := &core.Data{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Validator: core.ValidatorTypeJSON,
+		Value:     fftypes.JSONAnyPtr(`{"some":"data"}`),
+		Created:   fftypes.Now(),
+	}
+	data.Hash = data.Value.Hash()
+	err = s.UpsertData(ctx, data, database.UpsertOptimizationNew, false)
+	assert.NoError(t, err)
+
+	node := &core.Identity{
+		IdentityBase: core.IdentityBase{
+			ID:        fftypes.NewUUID(),
+			DID:       "did:firefly:node/node1",
+			Type:      core.IdentityTypeNode,
+			Namespace: "ns1",
+			Name:      "node1",
+		},
+		Created: fftypes.Now(),
+	}
+	err = s.UpsertIdentity(ctx, node, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+
+	group := &core.Group{
+		GroupIdentity: core.GroupIdentity{
+			Namespace: "ns1",
+			Members:   core.Members{{Identity: "did:firefly:org/org1", Node: node.ID}},
+		},
+		LocalNamespace: "ns1",
+		Created:        fftypes.Now(),
+	}
+	group.Seal()
+	err = s.UpsertGroup(ctx, group, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+
+	msg := &core.Message{
+		LocalNamespace: "ns1",
+		Header: core.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Type:      core.MessageTypePrivate,
+			Namespace: "ns1",
+			Group:     group.Hash,
+			Topics:    []string{"topic1"},
+			Created:   fftypes.Now(),
+		},
+		State: core.MessageStateConfirmed,
+		Data:  core.DataRefs{{ID: data.ID, Hash: data.Hash}},
+	}
+	err = msg.Seal(ctx)
+	assert.NoError(t, err)
+	err = s.UpsertMessage(ctx, msg, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+
+	// Cloning twice is safe, as the UUIDs are deterministic
+	err = s.CloneNamespace(ctx, "ns1", "ns2")
+	assert.NoError(t, err)
+	err = s.CloneNamespace(ctx, "ns1", "ns2")
+	assert.NoError(t, err)
+
+	dt2, err := s.GetDatatypeByName(ctx, "ns2", "dt1", "1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, cloneID(datatype.ID, "ns2"), dt2.ID)
+	assert.Equal(t, cloneID(datatype.Message, "ns2"), dt2.Message)
+
+	data2, err := s.GetDataByID(ctx, "ns2", cloneID(data.ID, "ns2"), true)
+	assert.NoError(t, err)
+	assert.Equal(t, data.Value.String(), data2.Value.String())
+
+	node2, err := s.GetIdentityByDID(ctx, "ns2", node.DID)
+	assert.NoError(t, err)
+	assert.Equal(t, cloneID(node.ID, "ns2"), node2.ID)
+
+	msg2, err := s.GetMessageByID(ctx, "ns2", cloneID(msg.Header.ID, "ns2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ns2", msg2.Header.Namespace)
+	assert.Equal(t, cloneID(data.ID, "ns2"), msg2.Data[0].ID)
+	assert.Equal(t, msg2.Header.Hash(), msg2.Hash)
+	assert.NotEqual(t, group.Hash, msg2.Header.Group)
+
+	group2, err := s.GetGroupByHash(ctx, "ns2", msg2.Header.Group)
+	assert.NoError(t, err)
+	assert.Equal(t, node2.ID, group2.Members[0].Node)
+
+	// The source namespace is unchanged
+	msgs, _, err := s.GetMessages(ctx, "ns1", database.MessageQueryFactory.NewFilter(ctx).And())
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+	assert.Equal(t, msg.Header.ID, msgs[0].Header.ID)
+}
+
+func TestCloneIDDeterministic(t *testing.T) {
+	id := fftypes.NewUUID()
+	assert.Nil(t, cloneID(nil, "ns2"))
+	assert.Equal(t, cloneID(id, "ns2"), cloneID(id, "ns2"))
+	assert.NotEqual(t, cloneID(id, "ns2"), cloneID(id, "ns3"))
+	assert.NotEqual(t, id, cloneID(id, "ns2"))
+}
+
+func TestCloneNamespaceDatatypesFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.CloneNamespace(context.Background(), "ns1", "ns2")
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCloneNamespaceDataFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datatypeColumns))
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.CloneNamespace(context.Background(), "ns1", "ns2")
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCloneNamespaceIdentitiesFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datatypeColumns))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(dataColumnsWithValue))
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.CloneNamespace(context.Background(), "ns1", "ns2")
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCloneNamespaceGroupsFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datatypeColumns))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(dataColumnsWithValue))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(identityColumns))
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.CloneNamespace(context.Background(), "ns1", "ns2")
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCloneNamespaceMessagesFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datatypeColumns))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(dataColumnsWithValue))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(identityColumns))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(groupColumns))
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.CloneNamespace(context.Background(), "ns1", "ns2")
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCloneNamespaceUpsertDatatypeFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datatypeColumns).
+		AddRow(fftypes.NewUUID().String(), nil, "json", "ns1", "dt1", "1.0", fftypes.NewRandB32().String(), nil, nil))
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.CloneNamespace(context.Background(), "ns1", "ns2")
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCloneNamespaceUpsertDataFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datatypeColumns))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(dataColumnsWithValue).
+		AddRow(fftypes.NewUUID().String(), "json", "ns1", "", "", fftypes.NewRandB32().String(), nil, nil, "", "", "", 0, "", 0, "", "", nil, nil))
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.CloneNamespace(context.Background(), "ns1", "ns2")
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCloneNamespaceUpsertIdentityFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datatypeColumns))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(dataColumnsWithValue))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(identityColumns).
+		AddRow(fftypes.NewUUID().String(), "did:firefly:org/org1", nil, "org", "ns1", "org1", "", nil, nil, nil, nil, nil, nil))
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.CloneNamespace(context.Background(), "ns1", "ns2")
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCloneNamespaceUpsertGroupFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datatypeColumns))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(dataColumnsWithValue))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(identityColumns))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(groupColumns).
+		AddRow(nil, "ns1", "ns1", "", fftypes.NewRandB32().String(), nil))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"group_hash", "identity", "node_id", "idx"}))
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.CloneNamespace(context.Background(), "ns1", "ns2")
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCloneNamespaceUpsertMessageFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datatypeColumns))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(dataColumnsWithValue))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(identityColumns))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(groupColumns))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(append(append([]string{}, msgColumns...), "seq")).
+		AddRow(fftypes.NewUUID().String(), nil, "broadcast", "", "", nil, "ns1", "ns1", "", "", fftypes.NewRandB32().String(), nil, nil, "", "confirmed", nil, "", "batch_pin", nil, "", nil, nil, "", "", "", nil, nil, nil, nil, nil, 0, 1))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"message_id", "data_id", "data_hash"}))
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.CloneNamespace(context.Background(), "ns1", "ns2")
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
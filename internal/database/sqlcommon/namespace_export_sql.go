@@ -0,0 +1,198 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// Messages are read in pages, as the data references for each page are loaded with a separate query
+const exportMessagePageSize = 100
+
+type namespaceExporter struct {
+	ctx context.Context
+	enc *json.Encoder
+}
+
+func (ne *namespaceExporter) write(recordType core.NamespaceExportRecordType, obj interface{}) error {
+	b, _ := json.Marshal(obj) // all core types can be marshalled
+	if err := ne.enc.Encode(&core.NamespaceExportRecord{Type: recordType, Record: b}); err != nil {
+		return i18n.WrapError(ne.ctx, err, coremsgs.MsgNamespaceExportWriteFailed)
+	}
+	return nil
+}
+
+func (s *SQLCommon) exportRows(ctx context.Context, table string, query sq.SelectBuilder, export func(rows *sql.Rows) error) error {
+	rows, _, err := s.Query(ctx, table, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := export(rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLCommon) exportMessages(ctx context.Context, namespace string, ne *namespaceExporter) error {
+	cols := append([]string{}, msgColumns...)
+	cols = append(cols, s.SequenceColumn())
+	lastSequence := int64(-1)
+	for {
+		query := sq.Select(cols...).From(messagesTable).
+			Where(sq.And{
+				sq.Eq{"namespace_local": namespace},
+				sq.Gt{s.SequenceColumn(): lastSequence},
+			}).
+			OrderBy(s.SequenceColumn()).
+			Limit(exportMessagePageSize)
+		msgs, _, err := s.getMessagesQuery(ctx, namespace, query, nil, &ffapi.FilterInfo{}, false)
+		if err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			if err := ne.write(core.NamespaceExportRecordTypeMessage, msg); err != nil {
+				return err
+			}
+			lastSequence = msg.Sequence
+		}
+		if len(msgs) < exportMessagePageSize {
+			return nil
+		}
+	}
+}
+
+func (s *SQLCommon) ExportNamespace(ctx context.Context, namespace string, w io.Writer) error {
+	ne := &namespaceExporter{ctx: ctx, enc: json.NewEncoder(w)}
+
+	// Data is written before the messages that refer to it, so an import re-creates records in a sensible order
+	err := s.exportRows(ctx, dataTable,
+		sq.Select(dataColumnsWithValue...).From(dataTable).Where(sq.Eq{"namespace": namespace}).OrderBy(s.SequenceColumn()),
+		func(rows *sql.Rows) error {
+			data, err := s.dataResult(ctx, rows, true)
+			if err != nil {
+				return err
+			}
+			return ne.write(core.NamespaceExportRecordTypeData, data)
+		})
+	if err != nil {
+		return err
+	}
+
+	if err := s.exportMessages(ctx, namespace, ne); err != nil {
+		return err
+	}
+
+	eventCols := append([]string{}, eventColumns...)
+	eventCols = append(eventCols, s.SequenceColumn())
+	err = s.exportRows(ctx, eventsTable,
+		sq.Select(eventCols...).From(eventsTable).Where(sq.Eq{"namespace": namespace}).OrderBy(s.SequenceColumn()),
+		func(rows *sql.Rows) error {
+			event, err := s.eventResult(ctx, rows)
+			if err != nil {
+				return err
+			}
+			return ne.write(core.NamespaceExportRecordTypeEvent, event)
+		})
+	if err != nil {
+		return err
+	}
+
+	return s.exportRows(ctx, subscriptionsTable,
+		sq.Select(subscriptionColumns...).From(subscriptionsTable).Where(sq.Eq{"namespace": namespace}).OrderBy(s.SequenceColumn()),
+		func(rows *sql.Rows) error {
+			sub, err := s.subscriptionResult(ctx, rows)
+			if err != nil {
+				return err
+			}
+			return ne.write(core.NamespaceExportRecordTypeSubscription, sub)
+		})
+}
+
+func (s *SQLCommon) importRecord(ctx context.Context, namespace string, idx int, record *core.NamespaceExportRecord) error {
+	var target interface{}
+	var apply func() error
+	switch record.Type {
+	case core.NamespaceExportRecordTypeData:
+		data := &core.Data{}
+		target, apply = data, func() error {
+			data.Namespace = namespace
+			return s.UpsertData(ctx, data, database.UpsertOptimizationSkip, false)
+		}
+	case core.NamespaceExportRecordTypeMessage:
+		msg := &core.Message{}
+		target, apply = msg, func() error {
+			msg.LocalNamespace = namespace
+			return s.UpsertMessage(ctx, msg, database.UpsertOptimizationSkip)
+		}
+	case core.NamespaceExportRecordTypeEvent:
+		event := &core.Event{}
+		target, apply = event, func() error {
+			// Events cannot be updated, so skip any that were imported previously
+			existing, err := s.GetEventByID(ctx, namespace, event.ID)
+			if err != nil || existing != nil {
+				return err
+			}
+			event.Namespace = namespace
+			return s.InsertEvent(ctx, event)
+		}
+	case core.NamespaceExportRecordTypeSubscription:
+		sub := &core.Subscription{}
+		target, apply = sub, func() error {
+			sub.Namespace = namespace
+			return s.UpsertSubscription(ctx, sub, true)
+		}
+	default:
+		return i18n.NewError(ctx, coremsgs.MsgNamespaceImportUnknownType, record.Type, idx)
+	}
+	if err := json.Unmarshal(record.Record, target); err != nil {
+		return i18n.WrapError(ctx, err, coremsgs.MsgNamespaceImportBadRecord, idx)
+	}
+	return apply()
+}
+
+func (s *SQLCommon) ImportNamespace(ctx context.Context, namespace string, r io.Reader) error {
+	dec := json.NewDecoder(r)
+	return s.RunAsGroup(ctx, func(ctx context.Context) error {
+		for idx := 0; ; idx++ {
+			var record core.NamespaceExportRecord
+			err := dec.Decode(&record)
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return i18n.WrapError(ctx, err, coremsgs.MsgNamespaceImportBadRecord, idx)
+			}
+			if err := s.importRecord(ctx, namespace, idx, &record); err != nil {
+				return err
+			}
+		}
+	})
+}
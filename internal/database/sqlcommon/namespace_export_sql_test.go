@@ -0,0 +1,226 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type errorWriter struct{}
+
+func (ew *errorWriter) Write(p []byte) (int, error) {
+	return 0, fmt.Errorf("pop")
+}
+
+func mockExportCallbacks(s *sqliteGoTestProvider) {
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", mock.Anything, mock.Anything, "ns1", mock.Anything, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", mock.Anything, mock.Anything, "ns1", mock.Anything).Return()
+	s.eventCallbacks.On("EventCreated", mock.Anything).Return()
+}
+
+func TestExportImportNamespaceE2EWithDB(t *testing.T) {
+	s1, cleanup1 := newSQLiteTestProvider(t)
+	defer cleanup1()
+	s2, cleanup2 := newSQLiteTestProvider(t)
+	defer cleanup2()
+	ctx := context.Background()
+	mockExportCallbacks(s1)
+	mockExportCallbacks(s2)
+
+	data := &core.Data{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Validator: core.ValidatorTypeJSON,
+		Value:     fftypes.JSONAnyPtr(`{"some":"data"}`),
+		Created:   fftypes.Now(),
+	}
+	data.Hash = data.Value.Hash()
+	err := s1.UpsertData(ctx, data, database.UpsertOptimizationNew, false)
+	assert.NoError(t, err)
+
+	// Enough messages to need more than one page
+	for i := 0; i < exportMessagePageSize+1; i++ {
+		msg := &core.Message{
+			LocalNamespace: "ns1",
+			Header: core.MessageHeader{
+				ID:        fftypes.NewUUID(),
+				Type:      core.MessageTypeBroadcast,
+				Namespace: "ns1",
+				Topics:    []string{"topic1"},
+				Created:   fftypes.Now(),
+				DataHash:  fftypes.NewRandB32(),
+			},
+			Hash:  fftypes.NewRandB32(),
+			State: core.MessageStateConfirmed,
+			Data:  core.DataRefs{{ID: data.ID, Hash: data.Hash}},
+		}
+		err = s1.UpsertMessage(ctx, msg, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+	}
+
+	event := core.NewEvent(core.EventTypeMessageConfirmed, "ns1", data.ID, nil, "topic1")
+	err = s1.InsertEvent(ctx, event)
+	assert.NoError(t, err)
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Name:      "sub1",
+		},
+		Transport: "websockets",
+		Created:   fftypes.Now(),
+	}
+	err = s1.UpsertSubscription(ctx, sub, false)
+	assert.NoError(t, err)
+
+	var export1 bytes.Buffer
+	err = s1.ExportNamespace(ctx, "ns1", &export1)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(export1.String()), "\n")
+	assert.Len(t, lines, exportMessagePageSize+4)
+	assert.Contains(t, lines[0], `"type":"data"`)
+	assert.Contains(t, lines[1], `"type":"message"`)
+	assert.Contains(t, lines[len(lines)-2], `"type":"event"`)
+	assert.Contains(t, lines[len(lines)-1], `"type":"subscription"`)
+
+	// Importing twice is safe, and the result exports identically
+	err = s2.ImportNamespace(ctx, "ns1", bytes.NewReader(export1.Bytes()))
+	assert.NoError(t, err)
+	err = s2.ImportNamespace(ctx, "ns1", bytes.NewReader(export1.Bytes()))
+	assert.NoError(t, err)
+	var export2 bytes.Buffer
+	err = s2.ExportNamespace(ctx, "ns1", &export2)
+	assert.NoError(t, err)
+	assert.Equal(t, export1.String(), export2.String())
+}
+
+func TestExportNamespaceWriteFail(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+	mockExportCallbacks(s)
+
+	msg := &core.Message{
+		LocalNamespace: "ns1",
+		Header: core.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Type:      core.MessageTypeBroadcast,
+			Namespace: "ns1",
+			Created:   fftypes.Now(),
+			DataHash:  fftypes.NewRandB32(),
+		},
+		Hash:  fftypes.NewRandB32(),
+		State: core.MessageStateConfirmed,
+	}
+	err := s.UpsertMessage(ctx, msg, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+
+	err = s.ExportNamespace(ctx, "ns1", &errorWriter{})
+	assert.Regexp(t, "FF10476", err)
+}
+
+func TestExportNamespaceDataQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.ExportNamespace(context.Background(), "ns1", &bytes.Buffer{})
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportNamespaceDataScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	err := s.ExportNamespace(context.Background(), "ns1", &bytes.Buffer{})
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportNamespaceMessagesQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(dataColumnsWithValue))
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.ExportNamespace(context.Background(), "ns1", &bytes.Buffer{})
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportNamespaceEventScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(dataColumnsWithValue))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(msgColumns))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	err := s.ExportNamespace(context.Background(), "ns1", &bytes.Buffer{})
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportNamespaceSubscriptionScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(dataColumnsWithValue))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(msgColumns))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(eventColumns))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	err := s.ExportNamespace(context.Background(), "ns1", &bytes.Buffer{})
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportNamespaceBadJSON(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	err := s.ImportNamespace(context.Background(), "ns1", strings.NewReader("!bad"))
+	assert.Regexp(t, "FF10477.*0", err)
+}
+
+func TestImportNamespaceBadRecord(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	err := s.ImportNamespace(context.Background(), "ns1", strings.NewReader(`{"type":"event","record":{"id":false}}`))
+	assert.Regexp(t, "FF10477.*0", err)
+}
+
+func TestImportNamespaceUnknownType(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	err := s.ImportNamespace(context.Background(), "ns1", strings.NewReader(`{"type":"wrong","record":{}}`))
+	assert.Regexp(t, "FF10478.*wrong", err)
+}
+
+func TestImportNamespaceEventLookupFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.ImportNamespace(context.Background(), "ns1", strings.NewReader(`{"type":"event","record":{"id":"`+fftypes.NewUUID().String()+`"}}`))
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
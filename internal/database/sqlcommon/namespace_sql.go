@@ -19,6 +19,8 @@ package sqlcommon
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
@@ -34,6 +36,7 @@ var (
 		"description",
 		"created",
 		"firefly_contracts",
+		"parent",
 	}
 )
 
@@ -69,6 +72,7 @@ func (s *SQLCommon) UpsertNamespace(ctx context.Context, namespace *core.Namespa
 				Set("description", namespace.Description).
 				Set("created", namespace.Created).
 				Set("firefly_contracts", namespace.Contracts).
+				Set("parent", namespace.ParentNamespace).
 				Where(sq.Eq{"name": namespace.Name}),
 			nil,
 		); err != nil {
@@ -84,6 +88,7 @@ func (s *SQLCommon) UpsertNamespace(ctx context.Context, namespace *core.Namespa
 					namespace.Description,
 					namespace.Created,
 					namespace.Contracts,
+					namespace.ParentNamespace,
 				),
 			nil,
 		); err != nil {
@@ -102,6 +107,7 @@ func (s *SQLCommon) namespaceResult(ctx context.Context, row *sql.Rows) (*core.N
 		&namespace.Description,
 		&namespace.Created,
 		&namespace.Contracts,
+		&namespace.ParentNamespace,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, namespacesTable)
@@ -136,3 +142,35 @@ func (s *SQLCommon) getNamespaceEq(ctx context.Context, eq sq.Eq, textName strin
 func (s *SQLCommon) GetNamespace(ctx context.Context, name string) (message *core.Namespace, err error) {
 	return s.getNamespaceEq(ctx, sq.Eq{"name": name}, name)
 }
+
+func (s *SQLCommon) GetDescendantNamespaces(ctx context.Context, root string) (namespaces []*core.Namespace, err error) {
+	childCols := make([]string, len(namespaceColumns))
+	for i, col := range namespaceColumns {
+		childCols[i] = "n." + col
+	}
+	// Walk down the hierarchy with a recursive common table expression. The UNION (rather than UNION ALL)
+	// ensures the walk terminates even if the stored hierarchy contains a cycle.
+	rows, _, err := s.Query(ctx, namespacesTable,
+		sq.Select(namespaceColumns...).
+			Prefix(fmt.Sprintf(
+				"WITH RECURSIVE descendants AS (SELECT %s FROM %s WHERE parent = ? UNION SELECT %s FROM %s n INNER JOIN descendants d ON n.parent = d.name)",
+				strings.Join(namespaceColumns, ","), namespacesTable, strings.Join(childCols, ","), namespacesTable,
+			), root).
+			From("descendants").
+			OrderBy("name"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	namespaces = []*core.Namespace{}
+	for rows.Next() {
+		namespace, err := s.namespaceResult(ctx, rows)
+		if err != nil {
+			return nil, err
+		}
+		namespaces = append(namespaces, namespace)
+	}
+	return namespaces, nil
+}
@@ -155,3 +155,59 @@ func TestGetNamespaceByNameScanFail(t *testing.T) {
 	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestGetDescendantNamespacesE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// org -> dept1 -> team1, org -> dept2, and an unrelated namespace
+	org, dept1 := "org", "dept1"
+	for _, ns := range []*core.Namespace{
+		{Name: "org", NetworkName: "org"},
+		{Name: "dept1", NetworkName: "dept1", ParentNamespace: &org},
+		{Name: "dept2", NetworkName: "dept2", ParentNamespace: &org},
+		{Name: "team1", NetworkName: "team1", ParentNamespace: &dept1},
+		{Name: "other", NetworkName: "other"},
+	} {
+		ns.Created = fftypes.Now()
+		err := s.UpsertNamespace(ctx, ns, true)
+		assert.NoError(t, err)
+	}
+
+	nsRead, err := s.GetNamespace(ctx, "team1")
+	assert.NoError(t, err)
+	assert.Equal(t, "dept1", *nsRead.ParentNamespace)
+
+	descendants, err := s.GetDescendantNamespaces(ctx, "org")
+	assert.NoError(t, err)
+	assert.Len(t, descendants, 3)
+	assert.Equal(t, "dept1", descendants[0].Name)
+	assert.Equal(t, "dept2", descendants[1].Name)
+	assert.Equal(t, "team1", descendants[2].Name)
+
+	descendants, err = s.GetDescendantNamespaces(ctx, "dept1")
+	assert.NoError(t, err)
+	assert.Len(t, descendants, 1)
+	assert.Equal(t, "team1", descendants[0].Name)
+
+	descendants, err = s.GetDescendantNamespaces(ctx, "team1")
+	assert.NoError(t, err)
+	assert.Empty(t, descendants)
+}
+
+func TestGetDescendantNamespacesSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("WITH RECURSIVE .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetDescendantNamespaces(context.Background(), "name1")
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDescendantNamespacesScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("WITH RECURSIVE .*").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("only one"))
+	_, err := s.GetDescendantNamespaces(context.Background(), "name1")
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
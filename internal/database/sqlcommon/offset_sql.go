@@ -33,9 +33,11 @@ var (
 		"otype",
 		"name",
 		"current",
+		"schema_version",
 	}
 	offsetFilterFieldMap = map[string]string{
-		"type": "otype",
+		"type":          "otype",
+		"schemaversion": "schema_version",
 	}
 )
 
@@ -80,6 +82,7 @@ func (s *SQLCommon) UpsertOffset(ctx context.Context, offset *core.Offset, allow
 				Set("otype", string(offset.Type)).
 				Set("name", offset.Name).
 				Set("current", offset.Current).
+				Set("schema_version", offset.SchemaVersion).
 				Where(sq.Eq{s.SequenceColumn(): offset.RowID}),
 			nil, // offsets do not have events
 		); err != nil {
@@ -93,6 +96,7 @@ func (s *SQLCommon) UpsertOffset(ctx context.Context, offset *core.Offset, allow
 					string(offset.Type),
 					offset.Name,
 					offset.Current,
+					offset.SchemaVersion,
 				),
 			nil, // offsets do not have events
 		); err != nil {
@@ -109,6 +113,7 @@ func (s *SQLCommon) offsetResult(ctx context.Context, row *sql.Rows) (*core.Offs
 		&offset.Type,
 		&offset.Name,
 		&offset.Current,
+		&offset.SchemaVersion,
 		&offset.RowID, // must include s.SequenceColumn() in colum list
 	)
 	if err != nil {
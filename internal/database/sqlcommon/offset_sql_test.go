@@ -42,9 +42,10 @@ func TestOffsetsE2EWithDB(t *testing.T) {
 	// Create a new offset entry
 	rand1, _ := rand.Int(rand.Reader, big.NewInt(10000000000000))
 	offset := &core.Offset{
-		Type:    core.OffsetTypeBatch,
-		Name:    "offset1",
-		Current: rand1.Int64(),
+		Type:          core.OffsetTypeBatch,
+		Name:          "offset1",
+		Current:       rand1.Int64(),
+		SchemaVersion: core.OffsetSchemaVersion,
 	}
 
 	err := s.UpsertOffset(ctx, offset, true)
@@ -276,7 +277,7 @@ func TestOffsetDeleteFail(t *testing.T) {
 	mock.ExpectBegin()
 	cols := append(append([]string{}, offsetColumns...), s.SequenceColumn())
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(cols).AddRow(
-		core.OffsetTypeSubscription, "sub1", int64(12345), int64(12345),
+		core.OffsetTypeSubscription, "sub1", int64(12345), core.OffsetSchemaVersion, int64(12345),
 	))
 	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
 	mock.ExpectRollback()
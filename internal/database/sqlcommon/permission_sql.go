@@ -0,0 +1,176 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+var (
+	permissionColumns = []string{
+		"namespace",
+		"principal",
+		"role",
+		"created",
+	}
+	permissionFilterFieldMap = map[string]string{}
+)
+
+const permissionsTable = "permissions"
+
+func (s *SQLCommon) UpsertPermission(ctx context.Context, permission *core.NamespacedPermission) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	// Do a select within the transaction to detemine if the principal already has a role
+	permissionRows, _, err := s.QueryTx(ctx, permissionsTable, tx,
+		sq.Select("principal").
+			From(permissionsTable).
+			Where(sq.Eq{
+				"namespace": permission.Namespace,
+				"principal": permission.Principal,
+			}),
+	)
+	if err != nil {
+		return err
+	}
+	existing := permissionRows.Next()
+	permissionRows.Close()
+
+	if existing {
+		// Update the role
+		if _, err = s.UpdateTx(ctx, permissionsTable, tx,
+			sq.Update(permissionsTable).
+				Set("role", permission.Role).
+				Where(sq.Eq{
+					"namespace": permission.Namespace,
+					"principal": permission.Principal,
+				}),
+			nil, // no change events for permissions
+		); err != nil {
+			return err
+		}
+	} else {
+		permission.Created = fftypes.Now()
+		if _, err = s.InsertTx(ctx, permissionsTable, tx,
+			sq.Insert(permissionsTable).
+				Columns(permissionColumns...).
+				Values(
+					permission.Namespace,
+					permission.Principal,
+					permission.Role,
+					permission.Created,
+				),
+			nil, // no change events for permissions
+		); err != nil {
+			return err
+		}
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) permissionResult(ctx context.Context, row *sql.Rows) (*core.NamespacedPermission, error) {
+	permission := core.NamespacedPermission{}
+	err := row.Scan(
+		&permission.Namespace,
+		&permission.Principal,
+		&permission.Role,
+		&permission.Created,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, permissionsTable)
+	}
+	return &permission, nil
+}
+
+func (s *SQLCommon) GetPermission(ctx context.Context, namespace, principal string) (permission *core.NamespacedPermission, err error) {
+
+	rows, _, err := s.Query(ctx, permissionsTable,
+		sq.Select(permissionColumns...).
+			From(permissionsTable).
+			Where(sq.Eq{"namespace": namespace, "principal": principal}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Permission for '%s' not found in namespace '%s'", principal, namespace)
+		return nil, nil
+	}
+
+	return s.permissionResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetPermissions(ctx context.Context, namespace string, filter ffapi.Filter) (permissions []*core.NamespacedPermission, fr *ffapi.FilterResult, err error) {
+
+	query, fop, fi, err := s.FilterSelect(ctx, "", sq.Select(permissionColumns...).From(permissionsTable), filter, permissionFilterFieldMap, []interface{}{"sequence"}, sq.Eq{"namespace": namespace})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.Query(ctx, permissionsTable, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	permissions = []*core.NamespacedPermission{}
+	for rows.Next() {
+		p, err := s.permissionResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		permissions = append(permissions, p)
+	}
+
+	return permissions, s.QueryRes(ctx, permissionsTable, tx, fop, nil, fi), err
+
+}
+
+func (s *SQLCommon) DeletePermission(ctx context.Context, namespace, principal string) (err error) {
+
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	err = s.DeleteTx(ctx, permissionsTable, tx, sq.Delete(permissionsTable).Where(sq.Eq{
+		"namespace": namespace,
+		"principal": principal,
+	}), nil /* no change events for permissions */)
+	if err != nil {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
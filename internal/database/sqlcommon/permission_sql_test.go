@@ -0,0 +1,184 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermissionsE2EWithDB(t *testing.T) {
+	log.SetLevel("trace")
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Grant a new permission
+	permission := &core.NamespacedPermission{
+		Namespace: "ns1",
+		Principal: "user1",
+		Role:      core.PermissionRoleRead,
+	}
+	err := s.UpsertPermission(ctx, permission)
+	assert.NoError(t, err)
+	assert.NotNil(t, permission.Created)
+
+	// Check we get the exact same permission back
+	permissionRead, err := s.GetPermission(ctx, "ns1", "user1")
+	assert.NoError(t, err)
+	assert.NotNil(t, permissionRead)
+	permissionJson, _ := json.Marshal(&permission)
+	permissionReadJson, _ := json.Marshal(&permissionRead)
+	assert.Equal(t, string(permissionJson), string(permissionReadJson))
+
+	// Not visible in another namespace
+	permissionRead, err = s.GetPermission(ctx, "ns2", "user1")
+	assert.NoError(t, err)
+	assert.Nil(t, permissionRead)
+
+	// Update the role
+	permission.Role = core.PermissionRoleAdmin
+	err = s.UpsertPermission(ctx, permission)
+	assert.NoError(t, err)
+
+	// Query back the permission
+	fb := database.PermissionQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("principal", "user1"),
+		fb.Eq("role", core.PermissionRoleAdmin),
+	)
+	permissions, res, err := s.GetPermissions(ctx, "ns1", filter.Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(permissions))
+	assert.Equal(t, int64(1), *res.TotalCount)
+	permissionJson, _ = json.Marshal(&permission)
+	permissionReadJson, _ = json.Marshal(permissions[0])
+	assert.Equal(t, string(permissionJson), string(permissionReadJson))
+
+	// Test delete
+	err = s.DeletePermission(ctx, "ns1", "user1")
+	assert.NoError(t, err)
+	permissions, _, err = s.GetPermissions(ctx, "ns1", filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(permissions))
+}
+
+func TestUpsertPermissionFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertPermission(context.Background(), &core.NamespacedPermission{})
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertPermissionFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertPermission(context.Background(), &core.NamespacedPermission{})
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertPermissionFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertPermission(context.Background(), &core.NamespacedPermission{})
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertPermissionFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"principal"}).AddRow("user1"))
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertPermission(context.Background(), &core.NamespacedPermission{})
+	assert.Regexp(t, "FF00178", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPermissionSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetPermission(context.Background(), "ns1", "user1")
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPermissionScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"namespace"}).AddRow("only one"))
+	_, err := s.GetPermission(context.Background(), "ns1", "user1")
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPermissionsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.PermissionQueryFactory.NewFilter(context.Background()).Eq("principal", "")
+	_, _, err := s.GetPermissions(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPermissionsBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.PermissionQueryFactory.NewFilter(context.Background()).Eq("principal", map[bool]bool{true: false})
+	_, _, err := s.GetPermissions(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF00143.*principal", err)
+}
+
+func TestGetPermissionsReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"namespace"}).AddRow("only one"))
+	f := database.PermissionQueryFactory.NewFilter(context.Background()).Eq("principal", "")
+	_, _, err := s.GetPermissions(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPermissionDeleteBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeletePermission(context.Background(), "ns1", "user1")
+	assert.Regexp(t, "FF00175", err)
+}
+
+func TestPermissionDeleteFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeletePermission(context.Background(), "ns1", "user1")
+	assert.Regexp(t, "FF00179", err)
+}
@@ -19,10 +19,12 @@ package sqlcommon
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/hyperledger/firefly-common/pkg/dbsql"
 	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly/internal/coremsgs"
@@ -201,6 +203,95 @@ func (s *SQLCommon) GetPins(ctx context.Context, namespace string, filter ffapi.
 
 }
 
+// CountPinsInSequenceRange counts the pins of every namespace in the sequence range (after, upTo].
+// The sequence is shared across namespaces, so this distinguishes a genuine gap from pins that belong
+// to other namespaces (or are already dispatched).
+func (s *SQLCommon) CountPinsInSequenceRange(ctx context.Context, after, upTo int64) (count int64, err error) {
+	seqCol := s.SequenceColumn()
+	return s.CountQuery(ctx, pinsTable, nil, sq.And{sq.Gt{seqCol: after}, sq.LtOrEq{seqCol: upTo}}, nil, "")
+}
+
+// blockedContextsWhere selects the earliest undispatched pin of each context, where that pin is at or before
+// the given sequence. For the aggregator's committed offset, these are the contexts it has passed over.
+func (s *SQLCommon) blockedContextsWhere(namespace string, upToSequence int64) sq.Sqlizer {
+	seqCol := s.SequenceColumn()
+	return sq.And{
+		sq.Eq{"namespace": namespace, "dispatched": false},
+		sq.LtOrEq{seqCol: upToSequence},
+		sq.Expr(fmt.Sprintf("%[1]s = (SELECT MIN(p2.%[1]s) FROM %[2]s p2 WHERE p2.namespace = %[2]s.namespace AND p2.hash = %[2]s.hash AND p2.dispatched = ?)", seqCol, pinsTable), false),
+	}
+}
+
+func (s *SQLCommon) GetBlockedContexts(ctx context.Context, namespace string, upToSequence int64, filter ffapi.Filter) (pins []*core.Pin, res *ffapi.FilterResult, err error) {
+
+	cols := append([]string{}, pinColumns...)
+	cols = append(cols, s.SequenceColumn())
+	query, fop, fi, err := s.FilterSelect(
+		ctx, "", sq.Select(cols...).From(pinsTable),
+		filter, pinFilterFieldMap, []interface{}{&ffapi.SortField{Field: "created"}}, s.blockedContextsWhere(namespace, upToSequence))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.Query(ctx, pinsTable, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	pins = []*core.Pin{}
+	for rows.Next() {
+		d, err := s.pinResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		pins = append(pins, d)
+	}
+
+	return pins, s.QueryRes(ctx, pinsTable, tx, fop, nil, fi), err
+}
+
+func (s *SQLCommon) GetBlockedStats(ctx context.Context, namespace string, upToSequence int64, cutoffs []*fftypes.FFTime) (stats *core.BlockedContextStats, err error) {
+	query := sq.Select("COUNT(*)", "MIN(created)")
+	for i, cutoff := range cutoffs {
+		if i < len(cutoffs)-1 {
+			query = query.Column(sq.Expr("SUM(CASE WHEN created <= ? AND created > ? THEN 1 ELSE 0 END)", cutoff, cutoffs[i+1]))
+		} else {
+			query = query.Column(sq.Expr("SUM(CASE WHEN created <= ? THEN 1 ELSE 0 END)", cutoff))
+		}
+	}
+	query = query.From(pinsTable).Where(s.blockedContextsWhere(namespace, upToSequence))
+
+	rows, _, err := s.Query(ctx, pinsTable, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats = &core.BlockedContextStats{
+		Histogram: make([]*core.BlockedContextAgeBucket, len(cutoffs)),
+	}
+	var oldest fftypes.FFTime
+	// The sums are NULL when there are no blocked contexts
+	counts := make([]sql.NullInt64, len(cutoffs))
+	dest := []interface{}{&stats.Total, &oldest}
+	for i := range counts {
+		dest = append(dest, &counts[i])
+	}
+	if rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, pinsTable)
+		}
+	}
+	if stats.Total > 0 {
+		stats.Oldest = &oldest
+	}
+	for i, count := range counts {
+		stats.Histogram[i] = &core.BlockedContextAgeBucket{Count: count.Int64}
+	}
+	return stats, nil
+}
+
 func (s *SQLCommon) UpdatePins(ctx context.Context, namespace string, filter ffapi.Filter, update ffapi.Update) (err error) {
 
 	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
@@ -68,6 +69,11 @@ func TestPinsE2EWithDB(t *testing.T) {
 	assert.Equal(t, 1, len(pinRes))
 	assert.Equal(t, int64(1), *res.TotalCount)
 
+	// Count across the sequence range, including a sequence that does not exist
+	count, err := s.CountPinsInSequenceRange(ctx, pin.Sequence-1, pin.Sequence+1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
 	// Set it dispatched
 	err = s.UpdatePins(ctx, "ns", database.PinQueryFactory.NewFilter(ctx).Eq("sequence", pin.Sequence), database.PinQueryFactory.NewUpdate(ctx).Set("dispatched", true))
 	assert.NoError(t, err)
@@ -218,6 +224,123 @@ func TestGetPinReadMessageFail(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestCountPinsInSequenceRangeQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.CountPinsInSequenceRange(context.Background(), 0, 10)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBlockedContextsE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("OrderedCollectionNSEvent", database.CollectionPins, core.ChangeEventTypeCreated, mock.Anything, mock.Anything).Return()
+
+	now := time.Now()
+	ago := func(d time.Duration) *fftypes.FFTime {
+		t := fftypes.FFTime(now.Add(-d))
+		return &t
+	}
+	newPin := func(ns string, hash *fftypes.Bytes32, dispatched bool, created *fftypes.FFTime) *core.Pin {
+		pin := &core.Pin{
+			Namespace:  ns,
+			Hash:       hash,
+			Batch:      fftypes.NewUUID(),
+			BatchHash:  fftypes.NewRandB32(),
+			Dispatched: dispatched,
+			Created:    created,
+		}
+		err := s.UpsertPin(ctx, pin)
+		assert.NoError(t, err)
+		return pin
+	}
+
+	// Context A is blocked by its first pin, and context B by its second as the first is dispatched
+	ctxA, ctxB := fftypes.NewRandB32(), fftypes.NewRandB32()
+	blockedB1 := newPin("ns1", ctxB, true, ago(10*time.Hour))
+	blockedA := newPin("ns1", ctxA, false, ago(2*time.Hour))
+	newPin("ns1", ctxA, false, ago(time.Hour))
+	blockedB2 := newPin("ns1", ctxB, false, ago(5*time.Minute))
+	newPin("ns2", fftypes.NewRandB32(), false, ago(time.Hour))
+	upTo := blockedB2.Sequence + 1
+	// Context C is not blocked, as its pin is ahead of the sequence
+	newPin("ns1", fftypes.NewRandB32(), false, ago(time.Minute))
+	assert.True(t, blockedB1.Dispatched)
+
+	fb := database.PinQueryFactory.NewFilter(ctx)
+	pins, res, err := s.GetBlockedContexts(ctx, "ns1", upTo, fb.And().Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), *res.TotalCount)
+	assert.Len(t, pins, 2)
+	assert.Equal(t, blockedA.Sequence, pins[0].Sequence)
+	assert.Equal(t, blockedB2.Sequence, pins[1].Sequence)
+
+	pins, _, err = s.GetBlockedContexts(ctx, "ns1", upTo, fb.And().Skip(1).Limit(10))
+	assert.NoError(t, err)
+	assert.Len(t, pins, 1)
+	assert.Equal(t, blockedB2.Sequence, pins[0].Sequence)
+
+	stats, err := s.GetBlockedStats(ctx, "ns1", upTo, []*fftypes.FFTime{ago(0), ago(time.Minute), ago(10 * time.Minute), ago(time.Hour), ago(24 * time.Hour)})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Total)
+	assert.Equal(t, blockedA.Created.UnixNano(), stats.Oldest.UnixNano())
+	counts := make([]int64, len(stats.Histogram))
+	for i, b := range stats.Histogram {
+		counts[i] = b.Count
+	}
+	assert.Equal(t, []int64{0, 1, 0, 1, 0}, counts)
+
+	stats, err = s.GetBlockedStats(ctx, "ns3", upTo, []*fftypes.FFTime{ago(0)})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), stats.Total)
+	assert.Nil(t, stats.Oldest)
+	assert.Equal(t, int64(0), stats.Histogram[0].Count)
+}
+
+func TestGetBlockedContextsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.PinQueryFactory.NewFilter(context.Background()).And()
+	_, _, err := s.GetBlockedContexts(context.Background(), "ns", 10, f)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBlockedContextsBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.PinQueryFactory.NewFilter(context.Background()).Eq("hash", map[bool]bool{true: false})
+	_, _, err := s.GetBlockedContexts(context.Background(), "ns", 10, f)
+	assert.Regexp(t, "FF00143.*type", err)
+}
+
+func TestGetBlockedContextsReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"pin"}).AddRow("only one"))
+	f := database.PinQueryFactory.NewFilter(context.Background()).And()
+	_, _, err := s.GetBlockedContexts(context.Background(), "ns", 10, f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBlockedStatsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetBlockedStats(context.Background(), "ns", 10, []*fftypes.FFTime{fftypes.Now()})
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBlockedStatsReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow("only one"))
+	_, err := s.GetBlockedStats(context.Background(), "ns", 10, []*fftypes.FFTime{fftypes.Now()})
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUpdatePinsBeginFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
@@ -34,9 +34,10 @@ import (
 // testProvider uses the datadog mocking framework
 type mockProvider struct {
 	SQLCommon
-	callbacks    *databasemocks.Callbacks
-	capabilities *database.Capabilities
-	config       config.Section
+	callbacks      *databasemocks.Callbacks
+	eventCallbacks *databasemocks.EventCallbacks
+	capabilities   *database.Capabilities
+	config         config.Section
 
 	mockDB *sql.DB
 	mdb    sqlmock.Sqlmock
@@ -53,9 +54,10 @@ func newMockProvider() *mockProvider {
 	conf := config.RootSection("unittest.db")
 	conf.AddKnownKey("url", "test")
 	mp := &mockProvider{
-		capabilities: &database.Capabilities{},
-		callbacks:    &databasemocks.Callbacks{},
-		config:       conf,
+		capabilities:   &database.Capabilities{},
+		callbacks:      &databasemocks.Callbacks{},
+		eventCallbacks: &databasemocks.EventCallbacks{},
+		config:         conf,
 	}
 	mp.SQLCommon.InitConfig(mp, mp.config)
 	mp.config.Set(SQLConfMaxConnections, 10)
@@ -67,7 +69,7 @@ func newMockProvider() *mockProvider {
 // init is a convenience to init for tests that aren't testing init itself
 func (mp *mockProvider) init() (*mockProvider, sqlmock.Sqlmock) {
 	_ = mp.Init(context.Background(), mp, mp.config, mp.capabilities)
-	mp.SetHandler(database.GlobalHandler, mp.callbacks)
+	mp.SetHandler(database.GlobalHandler, &testCallbacks{mp.callbacks, mp.eventCallbacks})
 	return mp, mp.mdb
 }
 
@@ -40,10 +40,17 @@ import (
 type sqliteGoTestProvider struct {
 	SQLCommon
 
-	config       config.Section
-	t            *testing.T
-	callbacks    *databasemocks.Callbacks
-	capabilities *database.Capabilities
+	config         config.Section
+	t              *testing.T
+	callbacks      *databasemocks.Callbacks
+	eventCallbacks *databasemocks.EventCallbacks
+	capabilities   *database.Capabilities
+}
+
+// testCallbacks is the handler the test providers register, so tests are passed the full events as well as the change events
+type testCallbacks struct {
+	*databasemocks.Callbacks
+	*databasemocks.EventCallbacks
 }
 
 // newTestProvider creates a real in-memory database provider for e2e testing
@@ -51,10 +58,11 @@ func newSQLiteTestProvider(t *testing.T) (*sqliteGoTestProvider, func()) {
 	conf := config.RootSection("unittest.db")
 	conf.AddKnownKey("url", "test")
 	tp := &sqliteGoTestProvider{
-		t:            t,
-		callbacks:    &databasemocks.Callbacks{},
-		capabilities: &database.Capabilities{},
-		config:       conf,
+		t:              t,
+		callbacks:      &databasemocks.Callbacks{},
+		eventCallbacks: &databasemocks.EventCallbacks{},
+		capabilities:   &database.Capabilities{},
+		config:         conf,
 	}
 	tp.SQLCommon.InitConfig(tp, tp.config)
 	dir, err := ioutil.TempDir("", "")
@@ -66,7 +74,7 @@ func newSQLiteTestProvider(t *testing.T) (*sqliteGoTestProvider, func()) {
 
 	err = tp.Init(context.Background(), tp, tp.config, tp.capabilities)
 	assert.NoError(tp.t, err)
-	tp.SetHandler(database.GlobalHandler, tp.callbacks)
+	tp.SetHandler(database.GlobalHandler, &testCallbacks{tp.callbacks, tp.eventCallbacks})
 
 	return tp, func() {
 		tp.Close()
@@ -105,3 +113,7 @@ func (tp *sqliteGoTestProvider) Open(url string) (*sql.DB, error) {
 func (tp *sqliteGoTestProvider) GetMigrationDriver(db *sql.DB) (migratedb.Driver, error) {
 	return sqlite3.WithInstance(db, &sqlite3.Config{})
 }
+
+func (tp *sqliteGoTestProvider) GetMigrationDriverForTable(db *sql.DB, migrationsTable string) (migratedb.Driver, error) {
+	return sqlite3.WithInstance(db, &sqlite3.Config{MigrationsTable: migrationsTable})
+}
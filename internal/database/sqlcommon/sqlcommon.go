@@ -50,6 +50,16 @@ func (cb *callbacks) OrderedUUIDCollectionNSEvent(resType database.OrderedUUIDCo
 	}
 }
 
+// EventCreated is only passed to the handlers that implement the optional database.EventCallbacks interface
+func (cb *callbacks) EventCreated(event *core.Event) {
+	if ecb, ok := cb.handlers[event.Namespace].(database.EventCallbacks); ok {
+		ecb.EventCreated(event)
+	}
+	if ecb, ok := cb.handlers[database.GlobalHandler].(database.EventCallbacks); ok {
+		ecb.EventCreated(event)
+	}
+}
+
 func (cb *callbacks) OrderedCollectionNSEvent(resType database.OrderedCollectionNS, eventType core.ChangeEventType, ns string, sequence int64) {
 	if cb, ok := cb.handlers[ns]; ok {
 		cb.OrderedCollectionNSEvent(resType, eventType, ns, sequence)
@@ -79,7 +89,13 @@ func (cb *callbacks) HashCollectionNSEvent(resType database.HashCollectionNS, ev
 
 func (s *SQLCommon) Init(ctx context.Context, provider dbsql.Provider, config config.Section, capabilities *database.Capabilities) (err error) {
 	s.capabilities = capabilities
-	return s.Database.Init(ctx, provider, config)
+	if err = s.Database.Init(ctx, provider, config); err != nil {
+		return err
+	}
+	if config.GetBool(SQLConfMigrationsAuto) {
+		err = s.applyRegisteredMigrations(ctx, provider)
+	}
+	return err
 }
 
 func (s *SQLCommon) SetHandler(namespace string, handler database.Callbacks) {
@@ -46,6 +46,38 @@ func TestMigrationUpDown(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestMigrationUpTwice(t *testing.T) {
+	tp, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	err := tp.UpsertNamespace(ctx, &core.Namespace{Name: "ns1", Created: fftypes.Now()}, true)
+	assert.NoError(t, err)
+
+	driver, err := tp.GetMigrationDriver(tp.DB())
+	assert.NoError(t, err)
+	var m *migrate.Migrate
+	m, err = migrate.NewWithDatabaseInstance(
+		"file://../../../db/migrations/sqlite",
+		tp.MigrationsDir(), driver)
+	assert.NoError(t, err)
+	version, dirty, err := m.Version()
+	assert.NoError(t, err)
+	assert.False(t, dirty)
+
+	// Init already applied every migration, so a second run must be a no-op
+	err = m.Up()
+	assert.Equal(t, migrate.ErrNoChange, err)
+	version2, dirty, err := m.Version()
+	assert.NoError(t, err)
+	assert.False(t, dirty)
+	assert.Equal(t, version, version2)
+
+	ns, err := tp.GetNamespace(ctx, "ns1")
+	assert.NoError(t, err)
+	assert.NotNil(t, ns)
+}
+
 func TestTXConcurrency(t *testing.T) {
 	s, cleanup := newSQLiteTestProvider(t)
 	defer cleanup()
@@ -105,11 +137,20 @@ func TestNamespaceCallbacks(t *testing.T) {
 	tcb.On("OrderedCollectionNSEvent", database.CollectionPins, core.ChangeEventTypeCreated, "ns1", int64(1)).Return()
 	tcb.On("UUIDCollectionNSEvent", database.CollectionOperations, core.ChangeEventTypeCreated, "ns1", id).Return()
 	tcb.On("HashCollectionNSEvent", database.CollectionGroups, core.ChangeEventTypeUpdated, "ns1", hash).Return()
+	event := &core.Event{Namespace: "ns1", ID: id}
 
 	s.callbacks.OrderedUUIDCollectionNSEvent(database.CollectionMessages, core.ChangeEventTypeCreated, "ns1", id, 1)
 	s.callbacks.OrderedCollectionNSEvent(database.CollectionPins, core.ChangeEventTypeCreated, "ns1", 1)
 	s.callbacks.UUIDCollectionNSEvent(database.CollectionOperations, core.ChangeEventTypeCreated, "ns1", id)
 	s.callbacks.HashCollectionNSEvent(database.CollectionGroups, core.ChangeEventTypeUpdated, "ns1", hash)
+	// The handler does not implement database.EventCallbacks, so is not passed the full event
+	s.callbacks.EventCreated(event)
+
+	ecb := &databasemocks.EventCallbacks{}
+	s.SetHandler("ns1", &testCallbacks{tcb, ecb})
+	ecb.On("EventCreated", event).Return()
+	s.callbacks.EventCreated(event)
+	ecb.AssertExpectations(t)
 
 	s.SetHandler("ns1", nil)
 	assert.Empty(t, s.callbacks.handlers)
@@ -96,3 +96,7 @@ func (sqlite *SQLite3) Open(url string) (*sql.DB, error) {
 func (sqlite *SQLite3) GetMigrationDriver(db *sql.DB) (migratedb.Driver, error) {
 	return migratesqlite3.WithInstance(db, &migratesqlite3.Config{})
 }
+
+func (sqlite *SQLite3) GetMigrationDriverForTable(db *sql.DB, migrationsTable string) (migratedb.Driver, error) {
+	return migratesqlite3.WithInstance(db, &migratesqlite3.Config{MigrationsTable: migrationsTable})
+}
@@ -43,6 +43,8 @@ func TestSQLite3GoProvider(t *testing.T) {
 	assert.NoError(t, err)
 	_, err = sqlite.GetMigrationDriver(sqlite.DB())
 	assert.Error(t, err)
+	_, err = sqlite.GetMigrationDriverForTable(sqlite.DB(), "schema_migrations_test")
+	assert.Error(t, err)
 
 	db, err := sqlite.Open("file::memory:")
 	assert.NoError(t, err)
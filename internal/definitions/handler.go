@@ -97,6 +97,8 @@ func (dh *definitionHandler) HandleDefinitionBroadcast(ctx context.Context, stat
 		return dh.handleFFIBroadcast(ctx, state, msg, data, tx)
 	case core.SystemTagDefineContractAPI:
 		return dh.handleContractAPIBroadcast(ctx, state, msg, data, tx)
+	case core.SystemTagDeliveryReceipt:
+		return dh.handleDeliveryReceiptBroadcast(ctx, state, msg, data)
 	default:
 		return HandlerResult{Action: core.ActionReject}, fmt.Errorf("unknown system tag '%s' for definition ID '%s'", msg.Header.Tag, msg.Header.ID)
 	}
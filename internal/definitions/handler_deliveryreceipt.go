@@ -0,0 +1,110 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package definitions
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+func (dh *definitionHandler) handleDeliveryReceiptBroadcast(ctx context.Context, state *core.BatchState, msg *core.Message, data core.DataArray) (HandlerResult, error) {
+	var receipt core.DeliveryReceipt
+	valid := dh.getSystemBroadcastPayload(ctx, msg, data, &receipt)
+	if !valid {
+		return HandlerResult{Action: core.ActionReject}, i18n.NewError(ctx, coremsgs.MsgDefRejectedBadPayload, "delivery receipt", msg.Header.ID)
+	}
+	receipt.Namespace = dh.namespace.Name
+	if err := receipt.Validate(ctx); err != nil {
+		return HandlerResult{Action: core.ActionReject}, i18n.NewError(ctx, coremsgs.MsgDefRejectedValidateFail, "delivery receipt", receipt.ID, err)
+	}
+	if msg.Header.Author != receipt.RecipientDID {
+		return HandlerResult{Action: core.ActionReject}, i18n.NewError(ctx, coremsgs.MsgDefRejectedWrongAuthor, "delivery receipt", receipt.ID, msg.Header.Author)
+	}
+	if msg.Header.Key != receipt.Key {
+		return HandlerResult{Action: core.ActionReject}, i18n.NewError(ctx, coremsgs.MsgDefRejectedSignatureMismatch, "delivery receipt", msg.Header.ID)
+	}
+
+	// Receipts are broadcast, so most nodes will not be party to the message. Where we are, we can check the receipt matches.
+	message, err := dh.database.GetMessageByID(ctx, receipt.Namespace, receipt.MessageID)
+	if err != nil {
+		return HandlerResult{Action: core.ActionRetry}, err
+	}
+	if message != nil && !message.Hash.Equals(receipt.MessageHash) {
+		return HandlerResult{Action: core.ActionReject}, i18n.NewError(ctx, coremsgs.MsgDefRejectedHashMismatch, "delivery receipt", receipt.ID, receipt.MessageHash, message.Hash)
+	}
+
+	fb := database.DeliveryReceiptQueryFactory.NewFilter(ctx)
+	existing, _, err := dh.database.GetDeliveryReceipts(ctx, receipt.Namespace, fb.And(fb.Eq("messageid", receipt.MessageID)))
+	if err != nil {
+		return HandlerResult{Action: core.ActionRetry}, err
+	}
+	for _, r := range existing {
+		if r.RecipientDID == receipt.RecipientDID {
+			return HandlerResult{Action: core.ActionReject}, i18n.NewError(ctx, coremsgs.MsgDefRejectedConflict, "delivery receipt", receipt.ID, r.ID)
+		}
+	}
+
+	if err = dh.database.InsertDeliveryReceipt(ctx, &receipt); err != nil {
+		return HandlerResult{Action: core.ActionRetry}, err
+	}
+
+	if message != nil && message.Header.Group != nil {
+		delivered, err := dh.allRecipientsAcknowledged(ctx, message, append(existing, &receipt))
+		if err != nil {
+			return HandlerResult{Action: core.ActionRetry}, err
+		}
+		if delivered {
+			log.L(ctx).Infof("Message '%s' delivered to all recipients", message.Header.ID)
+			state.AddFinalize(func(ctx context.Context) error {
+				// One event per topic, matching the confirmation events for the message
+				for _, topic := range message.Header.Topics {
+					event := core.NewEvent(core.EventTypeMessageDelivered, receipt.Namespace, message.Header.ID, message.TransactionID, topic)
+					event.Correlator = message.Header.CID
+					if err := dh.database.InsertEvent(ctx, event); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+	}
+
+	return HandlerResult{Action: core.ActionConfirm}, nil
+}
+
+// allRecipientsAcknowledged checks every member of the message's group, other than the author, has sent a receipt
+func (dh *definitionHandler) allRecipientsAcknowledged(ctx context.Context, message *core.Message, receipts []*core.DeliveryReceipt) (bool, error) {
+	group, err := dh.database.GetGroupByHash(ctx, dh.namespace.Name, message.Header.Group)
+	if err != nil || group == nil {
+		return false, err
+	}
+	acknowledged := make(map[string]bool, len(receipts))
+	for _, r := range receipts {
+		acknowledged[r.RecipientDID] = true
+	}
+	for _, member := range group.Members {
+		if member.Identity != message.Header.Author && !acknowledged[member.Identity] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
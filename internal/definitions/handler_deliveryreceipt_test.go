@@ -0,0 +1,314 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package definitions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestDeliveryReceipt(t *testing.T, hash *fftypes.Bytes32) (*core.DeliveryReceipt, *core.Message, core.DataArray) {
+	receipt := &core.DeliveryReceipt{
+		ID:           fftypes.NewUUID(),
+		MessageID:    fftypes.NewUUID(),
+		MessageHash:  hash,
+		RecipientDID: "did:firefly:org/org2",
+		ReceivedAt:   fftypes.Now(),
+		Key:          "0x23456",
+	}
+	b, err := json.Marshal(&receipt)
+	assert.NoError(t, err)
+	msg := &core.Message{
+		Header: core.MessageHeader{
+			ID:  fftypes.NewUUID(),
+			Tag: core.SystemTagDeliveryReceipt,
+			SignerRef: core.SignerRef{
+				Author: "did:firefly:org/org2",
+				Key:    "0x23456",
+			},
+		},
+	}
+	return receipt, msg, core.DataArray{{Value: fftypes.JSONAnyPtrBytes(b)}}
+}
+
+func newTestPrivateMessage(receipt *core.DeliveryReceipt) *core.Message {
+	return &core.Message{
+		Header: core.MessageHeader{
+			ID:     receipt.MessageID,
+			Group:  fftypes.NewRandB32(),
+			Topics: fftypes.FFStringArray{"topic1", "topic2"},
+			SignerRef: core.SignerRef{
+				Author: "did:firefly:org/org1",
+			},
+		},
+		Hash: receipt.MessageHash,
+	}
+}
+
+func newTestReceiptGroup(msg *core.Message) *core.Group {
+	return &core.Group{
+		GroupIdentity: core.GroupIdentity{
+			Members: core.Members{
+				{Identity: "did:firefly:org/org1", Node: fftypes.NewUUID()},
+				{Identity: "did:firefly:org/org2", Node: fftypes.NewUUID()},
+				{Identity: "did:firefly:org/org3", Node: fftypes.NewUUID()},
+			},
+		},
+		Hash: msg.Header.Group,
+	}
+}
+
+func TestHandleDeliveryReceiptAllDelivered(t *testing.T) {
+	dh, bs := newTestDefinitionHandler(t)
+	defer dh.cleanup(t)
+
+	receipt, msg, data := newTestDeliveryReceipt(t, fftypes.NewRandB32())
+	privateMsg := newTestPrivateMessage(receipt)
+
+	dh.mdi.On("GetMessageByID", mock.Anything, "ns1", receipt.MessageID).Return(privateMsg, nil)
+	dh.mdi.On("GetDeliveryReceipts", mock.Anything, "ns1", mock.Anything).Return([]*core.DeliveryReceipt{
+		{ID: fftypes.NewUUID(), RecipientDID: "did:firefly:org/org3"},
+	}, nil, nil)
+	dh.mdi.On("InsertDeliveryReceipt", mock.Anything, mock.MatchedBy(func(r *core.DeliveryReceipt) bool {
+		return r.ID.Equals(receipt.ID) && r.Namespace == "ns1" && r.Broadcast.Equals(msg.Header.ID)
+	})).Return(nil)
+	dh.mdi.On("GetGroupByHash", mock.Anything, "ns1", privateMsg.Header.Group).Return(newTestReceiptGroup(privateMsg), nil)
+	dh.mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *core.Event) bool {
+		return e.Type == core.EventTypeMessageDelivered && e.Reference.Equals(receipt.MessageID)
+	})).Return(nil).Twice()
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), &bs.BatchState, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: core.ActionConfirm}, action)
+	assert.NoError(t, err)
+	err = bs.RunFinalize(context.Background())
+	assert.NoError(t, err)
+
+	dh.mdi.AssertExpectations(t)
+}
+
+func TestHandleDeliveryReceiptAwaitingOthers(t *testing.T) {
+	dh, bs := newTestDefinitionHandler(t)
+	defer dh.cleanup(t)
+
+	receipt, msg, data := newTestDeliveryReceipt(t, fftypes.NewRandB32())
+	privateMsg := newTestPrivateMessage(receipt)
+
+	dh.mdi.On("GetMessageByID", mock.Anything, "ns1", receipt.MessageID).Return(privateMsg, nil)
+	dh.mdi.On("GetDeliveryReceipts", mock.Anything, "ns1", mock.Anything).Return([]*core.DeliveryReceipt{}, nil, nil)
+	dh.mdi.On("InsertDeliveryReceipt", mock.Anything, mock.Anything).Return(nil)
+	dh.mdi.On("GetGroupByHash", mock.Anything, "ns1", privateMsg.Header.Group).Return(newTestReceiptGroup(privateMsg), nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), &bs.BatchState, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: core.ActionConfirm}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+
+	dh.mdi.AssertExpectations(t)
+}
+
+func TestHandleDeliveryReceiptMessageUnknown(t *testing.T) {
+	dh, bs := newTestDefinitionHandler(t)
+	defer dh.cleanup(t)
+
+	receipt, msg, data := newTestDeliveryReceipt(t, fftypes.NewRandB32())
+
+	dh.mdi.On("GetMessageByID", mock.Anything, "ns1", receipt.MessageID).Return(nil, nil)
+	dh.mdi.On("GetDeliveryReceipts", mock.Anything, "ns1", mock.Anything).Return([]*core.DeliveryReceipt{}, nil, nil)
+	dh.mdi.On("InsertDeliveryReceipt", mock.Anything, mock.Anything).Return(nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), &bs.BatchState, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: core.ActionConfirm}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+
+	dh.mdi.AssertExpectations(t)
+}
+
+func TestHandleDeliveryReceiptEventFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandler(t)
+	defer dh.cleanup(t)
+
+	receipt, msg, data := newTestDeliveryReceipt(t, fftypes.NewRandB32())
+	privateMsg := newTestPrivateMessage(receipt)
+
+	dh.mdi.On("GetMessageByID", mock.Anything, "ns1", receipt.MessageID).Return(privateMsg, nil)
+	dh.mdi.On("GetDeliveryReceipts", mock.Anything, "ns1", mock.Anything).Return([]*core.DeliveryReceipt{
+		{ID: fftypes.NewUUID(), RecipientDID: "did:firefly:org/org3"},
+	}, nil, nil)
+	dh.mdi.On("InsertDeliveryReceipt", mock.Anything, mock.Anything).Return(nil)
+	dh.mdi.On("GetGroupByHash", mock.Anything, "ns1", privateMsg.Header.Group).Return(newTestReceiptGroup(privateMsg), nil)
+	dh.mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), &bs.BatchState, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: core.ActionConfirm}, action)
+	assert.NoError(t, err)
+	err = bs.RunFinalize(context.Background())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestHandleDeliveryReceiptGroupFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandler(t)
+	defer dh.cleanup(t)
+
+	receipt, msg, data := newTestDeliveryReceipt(t, fftypes.NewRandB32())
+	privateMsg := newTestPrivateMessage(receipt)
+
+	dh.mdi.On("GetMessageByID", mock.Anything, "ns1", receipt.MessageID).Return(privateMsg, nil)
+	dh.mdi.On("GetDeliveryReceipts", mock.Anything, "ns1", mock.Anything).Return([]*core.DeliveryReceipt{}, nil, nil)
+	dh.mdi.On("InsertDeliveryReceipt", mock.Anything, mock.Anything).Return(nil)
+	dh.mdi.On("GetGroupByHash", mock.Anything, "ns1", privateMsg.Header.Group).Return(nil, fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), &bs.BatchState, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: core.ActionRetry}, action)
+	assert.EqualError(t, err, "pop")
+	bs.assertNoFinalizers()
+}
+
+func TestHandleDeliveryReceiptInsertFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandler(t)
+	defer dh.cleanup(t)
+
+	receipt, msg, data := newTestDeliveryReceipt(t, fftypes.NewRandB32())
+
+	dh.mdi.On("GetMessageByID", mock.Anything, "ns1", receipt.MessageID).Return(nil, nil)
+	dh.mdi.On("GetDeliveryReceipts", mock.Anything, "ns1", mock.Anything).Return([]*core.DeliveryReceipt{}, nil, nil)
+	dh.mdi.On("InsertDeliveryReceipt", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), &bs.BatchState, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: core.ActionRetry}, action)
+	assert.EqualError(t, err, "pop")
+	bs.assertNoFinalizers()
+}
+
+func TestHandleDeliveryReceiptDuplicate(t *testing.T) {
+	dh, bs := newTestDefinitionHandler(t)
+	defer dh.cleanup(t)
+
+	receipt, msg, data := newTestDeliveryReceipt(t, fftypes.NewRandB32())
+
+	dh.mdi.On("GetMessageByID", mock.Anything, "ns1", receipt.MessageID).Return(nil, nil)
+	dh.mdi.On("GetDeliveryReceipts", mock.Anything, "ns1", mock.Anything).Return([]*core.DeliveryReceipt{
+		{ID: fftypes.NewUUID(), RecipientDID: "did:firefly:org/org2"},
+	}, nil, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), &bs.BatchState, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: core.ActionReject}, action)
+	assert.Regexp(t, "FF10407", err)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleDeliveryReceiptGetReceiptsFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandler(t)
+	defer dh.cleanup(t)
+
+	receipt, msg, data := newTestDeliveryReceipt(t, fftypes.NewRandB32())
+
+	dh.mdi.On("GetMessageByID", mock.Anything, "ns1", receipt.MessageID).Return(nil, nil)
+	dh.mdi.On("GetDeliveryReceipts", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), &bs.BatchState, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: core.ActionRetry}, action)
+	assert.EqualError(t, err, "pop")
+	bs.assertNoFinalizers()
+}
+
+func TestHandleDeliveryReceiptHashMismatch(t *testing.T) {
+	dh, bs := newTestDefinitionHandler(t)
+	defer dh.cleanup(t)
+
+	receipt, msg, data := newTestDeliveryReceipt(t, fftypes.NewRandB32())
+	privateMsg := newTestPrivateMessage(receipt)
+	privateMsg.Hash = fftypes.NewRandB32()
+
+	dh.mdi.On("GetMessageByID", mock.Anything, "ns1", receipt.MessageID).Return(privateMsg, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), &bs.BatchState, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: core.ActionReject}, action)
+	assert.Regexp(t, "FF10410", err)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleDeliveryReceiptGetMessageFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandler(t)
+	defer dh.cleanup(t)
+
+	receipt, msg, data := newTestDeliveryReceipt(t, fftypes.NewRandB32())
+
+	dh.mdi.On("GetMessageByID", mock.Anything, "ns1", receipt.MessageID).Return(nil, fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), &bs.BatchState, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: core.ActionRetry}, action)
+	assert.EqualError(t, err, "pop")
+	bs.assertNoFinalizers()
+}
+
+func TestHandleDeliveryReceiptWrongKey(t *testing.T) {
+	dh, bs := newTestDefinitionHandler(t)
+	defer dh.cleanup(t)
+
+	_, msg, data := newTestDeliveryReceipt(t, fftypes.NewRandB32())
+	msg.Header.Key = "0x99999"
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), &bs.BatchState, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: core.ActionReject}, action)
+	assert.Regexp(t, "FF10402", err)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleDeliveryReceiptWrongAuthor(t *testing.T) {
+	dh, bs := newTestDefinitionHandler(t)
+	defer dh.cleanup(t)
+
+	_, msg, data := newTestDeliveryReceipt(t, fftypes.NewRandB32())
+	msg.Header.Author = "did:firefly:org/org3"
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), &bs.BatchState, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: core.ActionReject}, action)
+	assert.Regexp(t, "FF10409", err)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleDeliveryReceiptInvalid(t *testing.T) {
+	dh, bs := newTestDefinitionHandler(t)
+	defer dh.cleanup(t)
+
+	_, msg, data := newTestDeliveryReceipt(t, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), &bs.BatchState, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: core.ActionReject}, action)
+	assert.Regexp(t, "FF10403", err)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleDeliveryReceiptBadPayload(t *testing.T) {
+	dh, bs := newTestDefinitionHandler(t)
+	defer dh.cleanup(t)
+
+	_, msg, _ := newTestDeliveryReceipt(t, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), &bs.BatchState, msg, core.DataArray{}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: core.ActionReject}, action)
+	assert.Regexp(t, "FF10400", err)
+	bs.assertNoFinalizers()
+}
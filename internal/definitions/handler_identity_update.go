@@ -84,6 +84,11 @@ func (dh *definitionHandler) handleIdentityUpdate(ctx context.Context, state *co
 	}
 
 	state.AddFinalize(func(ctx context.Context) error {
+		// Evicted as the batch completes, rather than when the identity is written, to keep the window in which
+		// another lookup could re-cache the profile from before the update as short as possible
+		if err := dh.identity.InvalidateCachedIdentity(ctx, identity); err != nil {
+			return err
+		}
 		event := core.NewEvent(core.EventTypeIdentityUpdated, identity.Namespace, identity.ID, nil, core.SystemTopicDefinitions)
 		return dh.database.InsertEvent(ctx, event)
 	})
@@ -78,6 +78,7 @@ func TestHandleDefinitionIdentityUpdateOk(t *testing.T) {
 		assert.Equal(t, iu.Updates, identity.IdentityProfile)
 		return true
 	}), database.UpsertOptimizationExisting).Return(nil)
+	dh.mim.On("InvalidateCachedIdentity", ctx, org1).Return(nil)
 	dh.mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(event *core.Event) bool {
 		return event.Type == core.EventTypeIdentityUpdated
 	})).Return(nil)
@@ -106,6 +107,26 @@ func TestHandleDefinitionIdentityUpdateUpsertFail(t *testing.T) {
 	bs.assertNoFinalizers()
 }
 
+func TestHandleDefinitionIdentityUpdateInvalidateFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandler(t)
+	ctx := context.Background()
+
+	org1, updateMsg, updateData, _ := testIdentityUpdate(t)
+
+	dh.mim.On("CachedIdentityLookupByID", ctx, org1.ID).Return(org1, nil)
+	dh.mdi.On("UpsertIdentity", ctx, mock.Anything, database.UpsertOptimizationExisting).Return(nil)
+	dh.mim.On("InvalidateCachedIdentity", ctx, org1).Return(fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(ctx, &bs.BatchState, updateMsg, core.DataArray{updateData}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: core.ActionConfirm}, action)
+	assert.NoError(t, err)
+
+	// The cache is only evicted once the batch is finalized
+	dh.mim.AssertNotCalled(t, "InvalidateCachedIdentity", mock.Anything, mock.Anything)
+	err = bs.RunFinalize(ctx)
+	assert.Regexp(t, "pop", err)
+}
+
 func TestHandleDefinitionIdentityInvalidIdentity(t *testing.T) {
 	dh, bs := newTestDefinitionHandler(t)
 	ctx := context.Background()
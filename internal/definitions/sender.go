@@ -47,6 +47,7 @@ type Sender interface {
 	PublishFFI(ctx context.Context, name, version, networkName string, waitConfirm bool) (*fftypes.FFI, error)
 	DefineContractAPI(ctx context.Context, httpServerURL string, api *core.ContractAPI, waitConfirm bool) error
 	PublishContractAPI(ctx context.Context, httpServerURL, name, networkName string, waitConfirm bool) (api *core.ContractAPI, err error)
+	SendDeliveryReceipt(ctx context.Context, receipt *core.DeliveryReceipt) error
 }
 
 type definitionSender struct {
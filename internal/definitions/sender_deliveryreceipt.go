@@ -0,0 +1,58 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package definitions
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+func (ds *definitionSender) SendDeliveryReceipt(ctx context.Context, receipt *core.DeliveryReceipt) error {
+	if !ds.multiparty {
+		return i18n.NewError(ctx, coremsgs.MsgActionNotSupported)
+	}
+
+	if receipt.ID == nil {
+		receipt.ID = fftypes.NewUUID()
+	}
+	if receipt.ReceivedAt == nil {
+		receipt.ReceivedAt = fftypes.Now()
+	}
+
+	// The receipt is not signed itself - it carries the key of the recipient, so other members can check it against
+	// the key that signed the blockchain transaction of the broadcast
+	signer := &core.SignerRef{Author: receipt.RecipientDID}
+	if err := ds.identity.ResolveInputSigningIdentity(ctx, signer); err != nil {
+		return err
+	}
+	receipt.Key = signer.Key
+	if err := receipt.Validate(ctx); err != nil {
+		return err
+	}
+
+	receipt.Namespace = ""
+	msg, err := ds.getSenderResolved(ctx, receipt, signer, core.SystemTagDeliveryReceipt).send(ctx, false)
+	if msg != nil {
+		receipt.Broadcast = msg.Header.ID
+	}
+	receipt.Namespace = ds.namespace
+	return err
+}
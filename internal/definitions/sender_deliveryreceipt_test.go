@@ -0,0 +1,97 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package definitions
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/syncasyncmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSendDeliveryReceiptOk(t *testing.T) {
+	ds := newTestDefinitionSender(t)
+	defer ds.cleanup(t)
+	ds.multiparty = true
+	mms := &syncasyncmocks.Sender{}
+
+	ds.mim.On("ResolveInputSigningIdentity", mock.Anything, mock.MatchedBy(func(signer *core.SignerRef) bool {
+		return signer.Author == "did:firefly:org/org2"
+	})).Run(func(args mock.Arguments) {
+		args[1].(*core.SignerRef).Key = "0x12345"
+	}).Return(nil)
+	ds.mbm.On("NewBroadcast", mock.MatchedBy(func(msg *core.MessageInOut) bool {
+		return msg.Header.Tag == core.SystemTagDeliveryReceipt && msg.Header.Key == "0x12345"
+	})).Return(mms)
+	mms.On("Send", context.Background()).Return(nil)
+
+	receipt := &core.DeliveryReceipt{
+		MessageID:    fftypes.NewUUID(),
+		MessageHash:  fftypes.NewRandB32(),
+		RecipientDID: "did:firefly:org/org2",
+	}
+	err := ds.SendDeliveryReceipt(context.Background(), receipt)
+	assert.NoError(t, err)
+	assert.NotNil(t, receipt.ID)
+	assert.NotNil(t, receipt.ReceivedAt)
+	assert.Equal(t, "0x12345", receipt.Key)
+	assert.Equal(t, "ns1", receipt.Namespace)
+
+	mms.AssertExpectations(t)
+}
+
+func TestSendDeliveryReceiptResolveFail(t *testing.T) {
+	ds := newTestDefinitionSender(t)
+	defer ds.cleanup(t)
+	ds.multiparty = true
+
+	ds.mim.On("ResolveInputSigningIdentity", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := ds.SendDeliveryReceipt(context.Background(), &core.DeliveryReceipt{
+		MessageID:    fftypes.NewUUID(),
+		MessageHash:  fftypes.NewRandB32(),
+		RecipientDID: "did:firefly:org/org2",
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestSendDeliveryReceiptInvalid(t *testing.T) {
+	ds := newTestDefinitionSender(t)
+	defer ds.cleanup(t)
+	ds.multiparty = true
+
+	ds.mim.On("ResolveInputSigningIdentity", mock.Anything, mock.Anything).Return(nil)
+
+	err := ds.SendDeliveryReceipt(context.Background(), &core.DeliveryReceipt{
+		RecipientDID: "did:firefly:org/org2",
+	})
+	assert.Regexp(t, "FF00112.*messageId", err)
+}
+
+func TestSendDeliveryReceiptNonMultiparty(t *testing.T) {
+	ds := newTestDefinitionSender(t)
+	defer ds.cleanup(t)
+	ds.multiparty = false
+
+	err := ds.SendDeliveryReceipt(context.Background(), &core.DeliveryReceipt{})
+	assert.Regexp(t, "FF10414", err)
+}
@@ -21,7 +21,10 @@ import (
 	"crypto/sha256"
 	"encoding/binary"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/ffapi"
@@ -37,6 +40,7 @@ import (
 	"github.com/hyperledger/firefly/internal/identity"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/privatemessaging"
+	"github.com/hyperledger/firefly/internal/ratelimit"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -54,12 +58,36 @@ type aggregator struct {
 	definitions  definitions.Handler
 	identity     identity.Manager
 	data         data.Manager
-	eventPoller  *eventPoller
+	eventPoller  EventPoller
 	verifierType core.VerifierType
 	retry        *retry.Retry
 	metrics      metrics.Manager
+	pinNotifier  *eventNotifier
 	batchCache   cache.CInterface
 	rewinder     *rewinder
+	rateLimiter  ratelimit.RateLimiter
+
+	ttlScanInterval  time.Duration
+	ttlScanBatchSize int
+	ttlScanCancel    context.CancelFunc
+	ttlScanDone      chan struct{}
+
+	receipts            definitions.Sender // optional - only set if delivery receipts are enabled
+	receiptsBatchSize   int
+	receiptsShoulderTap chan bool
+	receiptsCancel      context.CancelFunc
+	receiptsDone        chan struct{}
+
+	gapDetection    bool
+	gapStallTimeout time.Duration
+	gapStallStart   *time.Time
+	gapCheckedTo    int64 // highest sequence already checked, as the polling offset rewinds
+	pollOffset      int64
+
+	offsetName  string
+	processLock sync.Mutex
+	replayLock  sync.Mutex
+	replays     map[fftypes.UUID]*pinReplay
 }
 
 type batchCacheEntry struct {
@@ -91,7 +119,7 @@ func privatePinHash(topic string, group *fftypes.Bytes32, identity string, nonce
 	return fftypes.HashResult(h)
 }
 
-func newAggregator(ctx context.Context, ns string, di database.Plugin, bi blockchain.Plugin, pm privatemessaging.Manager, sh definitions.Handler, im identity.Manager, dm data.Manager, en *eventNotifier, mm metrics.Manager, cacheManager cache.Manager) (*aggregator, error) {
+func newAggregator(ctx context.Context, ns string, di database.Plugin, bi blockchain.Plugin, pm privatemessaging.Manager, sh definitions.Handler, ds definitions.Sender, im identity.Manager, dm data.Manager, en *eventNotifier, mm metrics.Manager, cacheManager cache.Manager, rl ratelimit.RateLimiter) (*aggregator, error) {
 	batchSize := config.GetInt(coreconfig.EventAggregatorBatchSize)
 	ag := &aggregator{
 		ctx:          log.WithLogField(ctx, "role", "aggregator"),
@@ -103,6 +131,21 @@ func newAggregator(ctx context.Context, ns string, di database.Plugin, bi blockc
 		data:         dm,
 		verifierType: bi.VerifierType(),
 		metrics:      mm,
+		pinNotifier:  en,
+		rateLimiter:  rl,
+
+		ttlScanInterval:  config.GetDuration(coreconfig.EventAggregatorTTLScanInterval),
+		ttlScanBatchSize: batchSize,
+
+		gapDetection:    config.GetBool(coreconfig.EventAggregatorGapDetectionEnabled),
+		gapStallTimeout: config.GetDuration(coreconfig.EventAggregatorGapStallTimeout),
+
+		replays: make(map[fftypes.UUID]*pinReplay),
+	}
+	if config.GetBool(coreconfig.PrivateMessagingDeliveryReceiptsEnabled) {
+		ag.receipts = ds
+		ag.receiptsBatchSize = batchSize
+		ag.receiptsShoulderTap = make(chan bool, 1)
 	}
 
 	batchCache, err := cacheManager.GetCache(
@@ -117,8 +160,13 @@ func newAggregator(ctx context.Context, ns string, di database.Plugin, bi blockc
 		return nil, err
 	}
 	ag.batchCache = batchCache
+	ordering, err := newOrderingStrategy(ctx, config.GetString(coreconfig.EventAggregatorOrderingStrategy))
+	if err != nil {
+		return nil, err
+	}
+	ag.offsetName = ordering.offsetName(ns)
 	firstEvent := core.SubOptsFirstEvent(config.GetString(coreconfig.EventAggregatorFirstEvent))
-	ag.eventPoller = newEventPoller(ctx, di, en, &eventPollerConf{
+	pollerConf := &eventPollerConf{
 		eventBatchSize:             batchSize,
 		eventBatchTimeout:          config.GetDuration(coreconfig.EventAggregatorBatchTimeout),
 		eventPollTimeout:           config.GetDuration(coreconfig.EventAggregatorPollTimeout),
@@ -128,27 +176,70 @@ func newAggregator(ctx context.Context, ns string, di database.Plugin, bi blockc
 			MaximumDelay: config.GetDuration(coreconfig.EventAggregatorRetryMaxDelay),
 			Factor:       config.GetFloat64(coreconfig.EventAggregatorRetryFactor),
 		},
-		firstEvent:       &firstEvent,
-		namespace:        ns,
-		offsetType:       core.OffsetTypeAggregator,
-		offsetName:       aggregatorOffsetName,
-		newEventsHandler: ag.processPinsEventsHandler,
-		getItems:         ag.getPins,
-		queryFactory:     database.PinQueryFactory,
+		retryJitter:           config.GetFloat64(coreconfig.EventAggregatorRetryJitter),
+		firstEvent:            &firstEvent,
+		namespace:             ns,
+		offsetType:            core.OffsetTypeAggregator,
+		offsetName:            ag.offsetName,
+		schemaVersion:         core.OffsetSchemaVersion,
+		resetOnSchemaMismatch: config.GetBool(coreconfig.EventAggregatorResetOnSchemaMismatch),
+		newEventsHandler:      ag.processPinsEventsHandler,
+		maxAttempts:           config.GetInt(coreconfig.EventAggregatorMaxRetries),
+		retriesExhausted:      ag.processPinsRetriesExhausted,
+		getItems:              ag.getPins,
+		queryFactory:          database.PinQueryFactory,
 		addCriteria: func(af ffapi.AndFilter) ffapi.AndFilter {
 			fb := af.Builder()
 			return af.Condition(fb.Eq("dispatched", false))
 		},
 		maybeRewind: ag.rewindOffchainBatches,
-	})
-	ag.retry = &ag.eventPoller.conf.retry
+	}
+	ag.eventPoller = newEventPoller(ctx, di, en, pollerConf)
+	ag.retry = &pollerConf.retry
 	ag.rewinder = newRewinder(ag)
 	return ag, nil
 }
 
-func (ag *aggregator) start() {
+func (ag *aggregator) start() error {
+	ag.deleteOrphanedReplayOffsets()
 	ag.rewinder.start()
-	ag.eventPoller.start()
+	if ag.ttlScanInterval > 0 {
+		var ctx context.Context
+		ctx, ag.ttlScanCancel = context.WithCancel(ag.ctx)
+		ag.ttlScanDone = make(chan struct{})
+		go ag.ttlScanLoop(ctx, ag.ttlScanInterval)
+	}
+	if ag.receipts != nil {
+		var ctx context.Context
+		ctx, ag.receiptsCancel = context.WithCancel(ag.ctx)
+		ag.receiptsDone = make(chan struct{})
+		go ag.deliveryReceiptLoop(ctx)
+	}
+	return ag.eventPoller.Start()
+}
+
+func (ag *aggregator) stop() {
+	ag.eventPoller.Stop()
+	if ag.ttlScanDone != nil {
+		ag.ttlScanCancel()
+		<-ag.ttlScanDone
+	}
+	if ag.receiptsDone != nil {
+		ag.receiptsCancel()
+		<-ag.receiptsDone
+	}
+}
+
+// pause stops the aggregator processing pins, once the page of pins in flight (if any) is complete.
+// Pins that arrive while paused are processed in sequence after resume, as the offset is retained.
+func (ag *aggregator) pause(ctx context.Context) error {
+	log.L(ag.ctx).Infof("Pausing aggregator")
+	return ag.eventPoller.Pause(ctx)
+}
+
+func (ag *aggregator) resume() {
+	log.L(ag.ctx).Infof("Resuming aggregator")
+	ag.eventPoller.Resume()
 }
 
 func (ag *aggregator) queueBatchRewind(batchID *fftypes.UUID) {
@@ -175,6 +266,14 @@ func (ag *aggregator) queueBlobRewind(hash *fftypes.Bytes32) {
 	}
 }
 
+func (ag *aggregator) queueDataRewind(dataID *fftypes.UUID) {
+	log.L(ag.ctx).Debugf("Queuing rewind for data %s", dataID)
+	ag.rewinder.rewindRequests <- rewind{
+		rewindType: rewindData,
+		uuid:       *dataID,
+	}
+}
+
 func (ag *aggregator) queueDIDRewind(did string) {
 	log.L(ag.ctx).Debugf("Queuing rewind for author DID %s", did)
 	ag.rewinder.rewindRequests <- rewind{
@@ -216,6 +315,9 @@ func (ag *aggregator) rewindOffchainBatches() (bool, int64) {
 }
 
 func (ag *aggregator) processWithBatchState(callback func(ctx context.Context, state *batchState) error) error {
+	// Replays process pins concurrently with the aggregator, so each run of batch state is serialized
+	ag.processLock.Lock()
+	defer ag.processLock.Unlock()
 	state := newBatchState(ag)
 
 	err := ag.database.RunAsGroup(ag.ctx, func(ctx context.Context) (err error) {
@@ -243,22 +345,213 @@ func (ag *aggregator) processWithBatchState(callback func(ctx context.Context, s
 		}
 	}
 	state.queueRewinds(ag)
+	if state.deliveryReceiptsRecorded {
+		ag.shoulderTapDeliveryReceipts()
+	}
 	return nil
 }
 
 func (ag *aggregator) processPinsEventsHandler(items []core.LocallySequenced) (repoll bool, err error) {
+	if ag.rateLimiter != nil {
+		items, repoll = ag.applyRateLimit(items)
+		if len(items) == 0 {
+			return repoll, nil
+		}
+	}
+
+	if ag.gapDetection {
+		stall, err := ag.checkSequenceGap(items)
+		if err != nil || stall {
+			return stall, err
+		}
+	}
+
 	pins := make([]*core.Pin, len(items))
 	for i, item := range items {
 		pins[i] = item.(*core.Pin)
 	}
 
-	return false, ag.processWithBatchState(func(ctx context.Context, state *batchState) error {
-		return ag.processPins(ctx, pins, state)
+	startTime := time.Now()
+	blockedContexts := 0
+	err = ag.processWithBatchState(func(ctx context.Context, state *batchState) error {
+		if err := ag.processPins(ctx, pins, state); err != nil {
+			return err
+		}
+		blockedContexts = state.blockedContextCount()
+		return nil
+	})
+	if err != nil {
+		if ag.metrics.IsMetricsEnabled() {
+			ag.metrics.AggregatorRetry(ag.namespace)
+		}
+		return false, err
+	}
+	// Only move the offset forwards once every phase of the batch has succeeded, so that on
+	// failure the same pins are processed again
+	offset := pins[len(pins)-1].Sequence
+	if err := ag.eventPoller.CommitOffset(ag.ctx, offset); err != nil {
+		return false, err
+	}
+	if ag.metrics.IsMetricsEnabled() {
+		ag.updatePageMetrics(startTime, blockedContexts, offset)
+	}
+	return repoll, nil
+}
+
+// applyRateLimit trims the page to the pins the namespace's rate limiter allows. The rest are left for the
+// next poll, which happens straight away - so the offset only moves past the pins actually processed.
+// Once the limiter is exhausted we wait for the next token, rather than re-polling in a tight loop.
+func (ag *aggregator) applyRateLimit(items []core.LocallySequenced) ([]core.LocallySequenced, bool) {
+	allowed := ag.rateLimiter.Take(len(items))
+	if allowed == 0 {
+		delay := ag.rateLimiter.Delay()
+		log.L(ag.ctx).Debugf("Rate limit reached - waiting %s before processing %d pins", delay, len(items))
+		select {
+		case <-time.After(delay):
+		case <-ag.ctx.Done():
+		}
+		return nil, true
+	}
+	if allowed < len(items) {
+		log.L(ag.ctx).Debugf("Rate limit reached - processing %d of %d pins", allowed, len(items))
+		return items[:allowed], true
+	}
+	return items, false
+}
+
+// checkSequenceGap looks for sequences missing between the polling offset and the end of the page.
+// Pins of other namespaces (and already dispatched pins) are not returned by the poll, so the pins table
+// is counted across all namespaces - any sequence still missing belongs to a transaction that rolled back,
+// or one that is yet to commit. For the latter, we can stall for a while to give the pins a chance to appear.
+//
+// Rewinds move the polling offset backwards, so only the part of the page beyond the highest sequence
+// already checked is counted - otherwise the same gap would be reported again after every rewind.
+func (ag *aggregator) checkSequenceGap(items []core.LocallySequenced) (stall bool, err error) {
+	after := ag.pollOffset
+	if after < ag.gapCheckedTo {
+		after = ag.gapCheckedTo
+	}
+	upTo := items[len(items)-1].LocalSequence()
+	if upTo <= after {
+		return false, nil
+	}
+	count, err := ag.database.CountPinsInSequenceRange(ag.ctx, after, upTo)
+	if err != nil {
+		return false, err
+	}
+	missing := (upTo - after) - count
+	if missing <= 0 {
+		ag.gapStallStart = nil
+		ag.gapCheckedTo = upTo
+		return false, nil
+	}
+
+	if ag.gapStallStart == nil {
+		log.L(ag.ctx).Warnf("Detected %d missing pin sequence(s) between %d and %d", missing, after, upTo)
+		if ag.metrics.IsMetricsEnabled() {
+			ag.metrics.AggregatorSequenceGap(ag.namespace)
+		}
+		if ag.gapStallTimeout <= 0 {
+			ag.gapCheckedTo = upTo
+			return false, nil
+		}
+		now := time.Now()
+		ag.gapStallStart = &now
+	}
+
+	remaining := ag.gapStallTimeout - time.Since(*ag.gapStallStart)
+	if remaining <= 0 {
+		log.L(ag.ctx).Warnf("Continuing past %d missing pin sequence(s) between %d and %d after %s", missing, after, upTo, ag.gapStallTimeout)
+		ag.gapStallStart = nil
+		ag.gapCheckedTo = upTo
+		return false, nil
+	}
+	if remaining > ag.retry.InitialDelay {
+		remaining = ag.retry.InitialDelay
+	}
+	select {
+	case <-time.After(remaining):
+	case <-ag.ctx.Done():
+	}
+	return true, nil
+}
+
+func (ag *aggregator) updatePageMetrics(startTime time.Time, blockedContexts int, offset int64) {
+	ag.metrics.AggregatorBatchProcessed(ag.namespace, time.Since(startTime))
+	ag.metrics.AggregatorBlockedContexts(ag.namespace, blockedContexts)
+	// The notifier only knows about pins inserted since startup, so the lag reads as zero until new pins arrive
+	lag := ag.pinNotifier.getLatestSequence() - offset
+	if lag < 0 {
+		lag = 0
+	}
+	ag.metrics.AggregatorLag(ag.namespace, lag)
+}
+
+// processPinsRetriesExhausted is called once a page of pins has failed the configured number of attempts.
+// The pins are retried a batch at a time, so that only the pins of batches that still fail are recorded as
+// dead events - then the offset moves past them, rather than the whole aggregator stalling.
+// Dead pins are marked dispatched, so later messages on the same unmasked context are not blocked behind them.
+func (ag *aggregator) processPinsRetriesExhausted(items []core.LocallySequenced, attempts int, lastErr error) (repoll bool, err error) {
+	log.L(ag.ctx).Warnf("Pins %d-%d failed after %d attempts (%s) - retrying each batch separately", items[0].LocalSequence(), items[len(items)-1].LocalSequence(), attempts, lastErr)
+
+	var batchPins []*core.Pin
+	for i, item := range items {
+		pin := item.(*core.Pin)
+		batchPins = append(batchPins, pin)
+		if i < len(items)-1 && items[i+1].(*core.Pin).Batch.Equals(pin.Batch) {
+			continue
+		}
+		err := ag.processWithBatchState(func(ctx context.Context, state *batchState) error {
+			return ag.processPins(ctx, batchPins, state)
+		})
+		if err != nil {
+			for _, failedPin := range batchPins {
+				if err := ag.recordDeadEvent(failedPin, attempts, err); err != nil {
+					return false, err
+				}
+			}
+		}
+		batchPins = nil
+	}
+	return false, ag.eventPoller.CommitOffset(ag.ctx, items[len(items)-1].LocalSequence())
+}
+
+func (ag *aggregator) recordDeadEvent(pin *core.Pin, attempts int, pinErr error) error {
+	deadEvent := &core.DeadEvent{
+		ID:        fftypes.NewUUID(),
+		Namespace: ag.namespace,
+		Pin:       pin.Sequence,
+		Batch:     pin.Batch,
+		Hash:      pin.Hash,
+		Index:     pin.Index,
+		Attempts:  attempts,
+		Error:     pinErr.Error(),
+	}
+	log.L(ag.ctx).Errorf("Recording pin %.10d batch=%s pinIndex=%d as a dead event: %s", pin.Sequence, pin.Batch, pin.Index, pinErr)
+	return ag.database.RunAsGroup(ag.ctx, func(ctx context.Context) error {
+		// Requeuing the dead event marks the pin undispatched again
+		ub := database.PinQueryFactory.NewFilter(ctx)
+		update := database.PinQueryFactory.NewUpdate(ctx).Set("dispatched", true)
+		if err := ag.database.UpdatePins(ctx, ag.namespace, ub.Eq("sequence", pin.Sequence), update); err != nil {
+			return err
+		}
+		if err := ag.database.UpsertDeadEvent(ctx, deadEvent); err != nil {
+			return err
+		}
+		event := core.NewEvent(core.EventTypeDeadEvent, ag.namespace, deadEvent.ID, nil, "")
+		if err := ag.database.InsertEvent(ctx, event); err != nil {
+			return err
+		}
+		if ag.metrics.IsMetricsEnabled() {
+			ag.metrics.AggregatorEvent(ag.namespace, core.EventTypeDeadEvent)
+		}
+		return nil
 	})
 }
 
 func (ag *aggregator) getPins(ctx context.Context, filter ffapi.Filter, offset int64) ([]core.LocallySequenced, error) {
 	log.L(ctx).Tracef("Reading page of pins > %d (first pin would be %d)", offset, offset+1)
+	ag.pollOffset = offset
 	pins, _, err := ag.database.GetPins(ctx, ag.namespace, filter)
 	ls := make([]core.LocallySequenced, len(pins))
 	for i, p := range pins {
@@ -357,6 +650,16 @@ func (ag *aggregator) cacheBatch(cacheKey string, batch *core.BatchPersisted, ma
 	log.L(ag.ctx).Debugf("Cached batch %s", cacheKey)
 }
 
+// pendingMessage is a message referred to by a pin in the page being processed, waiting to be dispatched
+type pendingMessage struct {
+	pin          *core.Pin
+	batch        *core.BatchPersisted
+	manifest     *core.BatchManifest
+	msgEntry     *core.MessageManifestEntry
+	msgBaseIndex int64
+	priority     uint8
+}
+
 func (ag *aggregator) processPins(ctx context.Context, pins []*core.Pin, state *batchState) (err error) {
 	l := log.L(ctx)
 
@@ -367,6 +670,7 @@ func (ag *aggregator) processPins(ctx context.Context, pins []*core.Pin, state *
 	// As messages can have multiple topics, we need to avoid processing the message twice in the same poll loop.
 	// We must check all the contexts in the message, and mark them dispatched together.
 	dupMsgCheck := make(map[fftypes.UUID]bool)
+	pending := make([]*pendingMessage, 0, len(pins))
 	for _, pin := range pins {
 		found, ok := localCache[*pin.Batch] // avoid trying to fetch the same batch repeatedly (mainly for cache misses)
 		if ok {
@@ -396,15 +700,74 @@ func (ag *aggregator) processPins(ctx context.Context, pins []*core.Pin, state *
 			continue
 		}
 		dupMsgCheck[*msgEntry.ID] = true
+		pending = append(pending, &pendingMessage{
+			pin:          pin,
+			batch:        batch,
+			manifest:     manifest,
+			msgEntry:     msgEntry,
+			msgBaseIndex: msgBaseIndex,
+		})
+	}
 
+	if len(pending) > 1 {
+		if err := ag.prioritizeMessages(ctx, pending); err != nil {
+			return err
+		}
+	}
+
+	for _, pm := range pending {
 		// Attempt to process the message (only returns errors for database persistence issues)
-		err := ag.processMessage(ctx, manifest, pin, msgBaseIndex, msgEntry, batch, state)
+		err := ag.processMessage(ctx, pm.manifest, pm.pin, pm.msgBaseIndex, pm.msgEntry, pm.batch, state)
 		if err != nil {
 			return err
 		}
 	}
 
-	ag.eventPoller.commitOffset(pins[len(pins)-1].Sequence)
+	return nil
+}
+
+// prioritizeMessages re-orders the messages in a page so that higher priority messages are dispatched first.
+// A message must never overtake an earlier message on any of its contexts, as it would be blocked by it,
+// so each message takes the lowest priority of the messages ahead of it on its contexts.
+// Messages are cached by the data manager, so loading them here does not add to the cost of dispatching them.
+func (ag *aggregator) prioritizeMessages(ctx context.Context, pending []*pendingMessage) error {
+	contextPriorities := make(map[fftypes.Bytes32]uint8)
+	prioritized := false
+	for _, pm := range pending {
+		cro := data.CRORequirePublicBlobRefs
+		if pm.pin.Masked {
+			cro = data.CRORequirePins
+		}
+		msg, _, _, err := ag.data.GetMessageWithDataCached(ctx, pm.msgEntry.ID, cro)
+		if err != nil {
+			return err
+		}
+		if msg == nil {
+			// Left at normal priority - it cannot be dispatched until it arrives
+			continue
+		}
+		contexts := make([]*fftypes.Bytes32, len(msg.Header.Topics))
+		pm.priority = msg.Header.Priority
+		for i, topic := range msg.Header.Topics {
+			if pm.pin.Masked {
+				contexts[i] = privateContext(topic, msg.Header.Group)
+			} else {
+				contexts[i] = broadcastContext(topic)
+			}
+			if p, seen := contextPriorities[*contexts[i]]; seen && p < pm.priority {
+				pm.priority = p
+			}
+		}
+		for _, c := range contexts {
+			contextPriorities[*c] = pm.priority
+		}
+		prioritized = prioritized || pm.priority > 0
+	}
+	if prioritized {
+		sort.SliceStable(pending, func(i, j int) bool {
+			return pending[i].priority > pending[j].priority
+		})
+	}
 	return nil
 }
 
@@ -470,16 +833,23 @@ func (ag *aggregator) processMessage(ctx context.Context, manifest *core.BatchMa
 		cro = data.CRORequirePublicBlobRefs
 	}
 	msg, data, dataAvailable, err := ag.data.GetMessageWithDataCached(ctx, msgEntry.ID, cro)
+	// A message that expired before its pins were processed still consumes its place in each context,
+	// but is never dispatched - so it does not block the messages that follow it
+	expired := msg != nil && msg.State == core.MessageStateExpired
 	switch {
 	case err != nil:
 		return err
 	case msg == nil:
 		l.Debugf("Message '%s' in batch '%s' is not yet available", msgEntry.ID, manifest.ID)
-	case !dataAvailable:
+	case !dataAvailable && !expired:
 		l.Errorf("Message '%s' in batch '%s' is missing data", msgEntry.ID, manifest.ID)
 	default:
-		// Check the pin signer is valid for the message
-		action, err = ag.checkOnchainConsistency(ctx, msg, pin)
+		if expired {
+			action = core.ActionConfirm
+		} else {
+			// Check the pin signer is valid for the message
+			action, err = ag.checkOnchainConsistency(ctx, msg, pin)
+		}
 		if action == core.ActionWait || action == core.ActionRetry {
 			break
 		}
@@ -523,7 +893,7 @@ func (ag *aggregator) processMessage(ctx context.Context, manifest *core.BatchMa
 			}
 		}
 
-		if action == core.ActionConfirm {
+		if action == core.ActionConfirm && !expired {
 			l.Debugf("Attempt dispatch msg=%s broadcastContexts=%v privatePins=%v", msg.Header.ID, unmaskedContexts, msg.Pins)
 			action, correlator, err = ag.readyForDispatch(ctx, msg, data, manifest.TX.ID, state)
 		}
@@ -544,7 +914,12 @@ func (ag *aggregator) processMessage(ctx context.Context, manifest *core.BatchMa
 		msg.RejectReason = err.Error()
 	}
 
-	newState := ag.completeDispatch(action, correlator, msg, manifest.TX.ID, state)
+	newState := core.MessageStateExpired
+	if !expired {
+		newState = ag.completeDispatch(action, correlator, msg, manifest.TX.ID, state)
+	} else {
+		l.Infof("Skipping expired message '%s' in batch '%s'", msg.Header.ID, manifest.ID)
+	}
 
 	// Mark all message pins dispatched, and increment all nextPins
 	for _, np := range nextPins {
@@ -576,8 +951,8 @@ func needsTokenApproval(msg *core.Message) bool {
 }
 
 func (ag *aggregator) readyForDispatch(ctx context.Context, msg *core.Message, data core.DataArray, tx *fftypes.UUID, state *batchState) (action core.MessageAction, correlator *fftypes.UUID, err error) {
-	// Verify we have all the blobs for the data
-	if resolved, err := ag.resolveBlobs(ctx, data); err != nil {
+	// Verify we have all the payloads for the data
+	if resolved, err := ag.data.CheckDataAvailable(ctx, data); err != nil {
 		return core.ActionRetry, nil, err
 	} else if !resolved {
 		return core.ActionWait, nil, nil
@@ -660,6 +1035,12 @@ func (ag *aggregator) completeDispatch(action core.MessageAction, correlator *ff
 	eventType := core.EventTypeMessageConfirmed
 	if action == core.ActionConfirm {
 		state.AddPendingConfirm(msg.Header.ID, msg)
+		if ag.receipts != nil && msg.Header.Group != nil && msg.Header.Type != core.MessageTypeGroupInit {
+			state.deliveryReceiptsRecorded = true
+			state.AddFinalize(func(ctx context.Context) error {
+				return ag.recordDeliveryReceipts(ctx, msg)
+			})
+		}
 	} else {
 		newState = core.MessageStateRejected
 		eventType = core.EventTypeMessageRejected
@@ -677,6 +1058,9 @@ func (ag *aggregator) completeDispatch(action core.MessageAction, correlator *ff
 			if err := ag.database.InsertEvent(ctx, event); err != nil {
 				return err
 			}
+			if ag.metrics.IsMetricsEnabled() {
+				ag.metrics.AggregatorEvent(ag.namespace, eventType)
+			}
 		}
 		return nil
 	})
@@ -685,35 +1069,3 @@ func (ag *aggregator) completeDispatch(action core.MessageAction, correlator *ff
 	}
 	return newState
 }
-
-// resolveBlobs ensures that the blobs for all the attachments in the data array, have been received into the
-// local data exchange blob store. Either because of a private transfer, or by downloading them from the shared storage
-func (ag *aggregator) resolveBlobs(ctx context.Context, data core.DataArray) (resolved bool, err error) {
-	l := log.L(ctx)
-
-	for _, d := range data {
-		if d.Blob == nil || d.Blob.Hash == nil {
-			continue
-		}
-
-		// See if we already have the data
-		fb := database.BlobQueryFactory.NewFilter(ctx)
-		blobs, _, err := ag.database.GetBlobs(ctx, ag.namespace, fb.And(fb.Eq("data_id", d.ID), fb.Eq("hash", d.Blob.Hash)))
-		if err != nil {
-			return false, err
-		}
-		if len(blobs) > 0 && blobs[0] != nil {
-			l.Debugf("Blob '%s' found in local DX with ref '%s'", blobs[0].Hash, blobs[0].PayloadRef)
-			continue
-		}
-
-		// If we've reached here, the data isn't available yet.
-		// This isn't an error, we just need to wait for it to arrive.
-		l.Debugf("Blob '%s' not available for data %s", d.Blob.Hash, d.ID)
-		return false, nil
-
-	}
-
-	return true, nil
-
-}
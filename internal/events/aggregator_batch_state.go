@@ -103,6 +103,9 @@ type batchState struct {
 	maskedContexts     map[fftypes.Bytes32]*nextPinGroupState
 	unmaskedContexts   map[fftypes.Bytes32]*contextState
 	dispatchedMessages []*dispatchedMessage
+
+	// Whether delivery receipts were recorded for private messages confirmed in this batch, to be sent once it is committed
+	deliveryReceiptsRecorded bool
 }
 
 func (bs *batchState) RunPreFinalize(ctx context.Context) error {
@@ -219,6 +222,17 @@ func (bs *batchState) SetContextBlockedBy(ctx context.Context, unmaskedContext f
 	}
 }
 
+// blockedContextCount returns the number of contexts that are blocked by an undispatched pin
+func (bs *batchState) blockedContextCount() int {
+	count := 0
+	for _, ucs := range bs.unmaskedContexts {
+		if ucs.blockedBy >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
 func (bs *batchState) confirmMessages(ctx context.Context, msgIDs []*fftypes.UUID, msgState core.MessageState, confirmTime *fftypes.FFTime, rejectReason string) error {
 	values := make([]driver.Value, len(msgIDs))
 	for i, msgID := range msgIDs {
@@ -280,7 +294,8 @@ func (bs *batchState) flushPins(ctx context.Context) error {
 			if err := bs.confirmMessages(ctx, []*fftypes.UUID{dm.msgID}, dm.newState, confirmTime, dm.rejectReason); err != nil {
 				return err
 			}
-		} else {
+		} else if dm.newState != core.MessageStateExpired {
+			// Expired messages already have their final state
 			msgStateUpdates[dm.newState] = append(msgStateUpdates[dm.newState], dm.msgID)
 		}
 	}
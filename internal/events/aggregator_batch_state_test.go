@@ -17,9 +17,12 @@
 package events
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/stretchr/testify/assert"
@@ -29,7 +32,7 @@ import (
 func TestFlushPinsFailUpdatePins(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 
 	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
 
@@ -48,7 +51,7 @@ func TestFlushPinsFailUpdatePins(t *testing.T) {
 func TestFlushPinsFailUpdateMessages(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 	msgID := fftypes.NewUUID()
 
 	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
@@ -70,7 +73,7 @@ func TestFlushPinsFailUpdateMessages(t *testing.T) {
 func TestSetContextBlockedByNoState(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 
 	unmaskedContext := fftypes.NewRandB32()
 	bs.SetContextBlockedBy(ag.ctx, *unmaskedContext, 10)
@@ -79,3 +82,169 @@ func TestSetContextBlockedByNoState(t *testing.T) {
 	assert.NoError(t, err)
 	assert.False(t, ready)
 }
+
+func TestBlockedContextCount(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	bs := newBatchState(ag.aggregator)
+
+	bs.unmaskedContexts[*fftypes.NewRandB32()] = &contextState{blockedBy: -1}
+	bs.SetContextBlockedBy(ag.ctx, *fftypes.NewRandB32(), 10)
+	bs.SetContextBlockedBy(ag.ctx, *fftypes.NewRandB32(), 11)
+
+	assert.Equal(t, 2, bs.blockedContextCount())
+}
+
+func TestAggregatorContextBlockingOrdering(t *testing.T) {
+	group1 := fftypes.NewRandB32()
+	ctxA := broadcastContext("topicA")
+	ctxB := broadcastContext("topicB")
+	ctxAGroup := privateContext("topicA", group1)
+
+	// Each step either checks a message pinned at a sequence on a context is ready,
+	// or records that the message at that sequence could not be dispatched (for example
+	// because its data has not arrived yet), and so blocks the context.
+	type step struct {
+		context     *fftypes.Bytes32
+		sequence    int64
+		block       bool
+		expectReady bool
+	}
+	testCases := []struct {
+		name            string
+		earlierPins     map[fftypes.Bytes32]int64
+		noLookup        bool
+		steps           []step
+		expectBlockedBy map[fftypes.Bytes32]int64
+	}{
+		{
+			name: "first message on context with nothing earlier",
+			steps: []step{
+				{context: ctxA, sequence: 10, expectReady: true},
+			},
+			expectBlockedBy: map[fftypes.Bytes32]int64{*ctxA: -1},
+		},
+		{
+			name:        "first message on context behind an undispatched pin",
+			earlierPins: map[fftypes.Bytes32]int64{*ctxA: 5},
+			steps: []step{
+				{context: ctxA, sequence: 10, expectReady: false},
+			},
+			expectBlockedBy: map[fftypes.Bytes32]int64{*ctxA: 5},
+		},
+		{
+			name: "consecutive messages on an unblocked context",
+			steps: []step{
+				{context: ctxA, sequence: 10, expectReady: true},
+				{context: ctxA, sequence: 11, expectReady: true},
+				{context: ctxA, sequence: 12, expectReady: true},
+			},
+			expectBlockedBy: map[fftypes.Bytes32]int64{*ctxA: -1},
+		},
+		{
+			name: "message A waits on data, so later message B on the same context is blocked",
+			steps: []step{
+				{context: ctxA, sequence: 10, expectReady: true},
+				{context: ctxA, sequence: 10, block: true},
+				{context: ctxA, sequence: 11, expectReady: false},
+			},
+			expectBlockedBy: map[fftypes.Bytes32]int64{*ctxA: 10},
+		},
+		{
+			name: "the earliest block is kept",
+			steps: []step{
+				{context: ctxA, sequence: 10, block: true},
+				{context: ctxA, sequence: 11, block: true},
+				{context: ctxA, sequence: 12, expectReady: false},
+			},
+			expectBlockedBy: map[fftypes.Bytes32]int64{*ctxA: 10},
+		},
+		{
+			name:     "block recorded before the context is first checked avoids a lookup",
+			noLookup: true,
+			steps: []step{
+				{context: ctxA, sequence: 10, block: true},
+				{context: ctxA, sequence: 11, expectReady: false},
+			},
+			expectBlockedBy: map[fftypes.Bytes32]int64{*ctxA: 10},
+		},
+		{
+			name: "blocked context does not affect a different topic",
+			steps: []step{
+				{context: ctxA, sequence: 10, block: true},
+				{context: ctxB, sequence: 11, expectReady: true},
+				{context: ctxA, sequence: 12, expectReady: false},
+			},
+			expectBlockedBy: map[fftypes.Bytes32]int64{*ctxA: 10, *ctxB: -1},
+		},
+		{
+			name:        "out of order arrival on one context only blocks that context",
+			earlierPins: map[fftypes.Bytes32]int64{*ctxB: 3},
+			steps: []step{
+				{context: ctxA, sequence: 10, expectReady: true},
+				{context: ctxB, sequence: 11, expectReady: false},
+				{context: ctxA, sequence: 12, expectReady: true},
+			},
+			expectBlockedBy: map[fftypes.Bytes32]int64{*ctxA: -1, *ctxB: 3},
+		},
+		{
+			name: "same topic without a group and with a group are separate contexts",
+			steps: []step{
+				{context: ctxA, sequence: 10, block: true},
+				{context: ctxAGroup, sequence: 11, expectReady: true},
+				{context: ctxA, sequence: 12, expectReady: false},
+			},
+			expectBlockedBy: map[fftypes.Bytes32]int64{*ctxA: 10, *ctxAGroup: -1},
+		},
+		{
+			name: "blocks on multiple contexts from one waiting message",
+			steps: []step{
+				{context: ctxA, sequence: 10, expectReady: true},
+				{context: ctxB, sequence: 10, expectReady: true},
+				{context: ctxA, sequence: 10, block: true},
+				{context: ctxB, sequence: 10, block: true},
+				{context: ctxA, sequence: 11, expectReady: false},
+				{context: ctxB, sequence: 12, expectReady: false},
+			},
+			expectBlockedBy: map[fftypes.Bytes32]int64{*ctxA: 10, *ctxB: 10},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ag := newTestAggregator()
+			defer ag.cleanup(t)
+			bs := newBatchState(ag.aggregator)
+
+			ag.mdi.On("GetPins", ag.ctx, "ns1", mock.Anything).Return(func(ctx context.Context, ns string, filter ffapi.Filter) []*core.Pin {
+				fi, err := filter.Finalize()
+				assert.NoError(t, err)
+				for context, seq := range tc.earlierPins {
+					if strings.Contains(fi.String(), context.String()) {
+						return []*core.Pin{{Sequence: seq}}
+					}
+				}
+				return []*core.Pin{}
+			}, nil, nil).Maybe()
+
+			for i, s := range tc.steps {
+				msg := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+				if s.block {
+					bs.SetContextBlockedBy(ag.ctx, *s.context, s.sequence)
+					continue
+				}
+				ready, err := bs.checkUnmaskedContextReady(ag.ctx, s.context, msg, s.sequence)
+				assert.NoError(t, err)
+				assert.Equal(t, s.expectReady, ready, "step %d", i)
+			}
+
+			if tc.noLookup {
+				ag.mdi.AssertNotCalled(t, "GetPins", mock.Anything, mock.Anything, mock.Anything)
+			}
+			assert.Len(t, bs.unmaskedContexts, len(tc.expectBlockedBy))
+			for context, blockedBy := range tc.expectBlockedBy {
+				assert.Equal(t, blockedBy, bs.unmaskedContexts[context].blockedBy)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/cache"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/cachemocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/definitionsmocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
+)
+
+// newBenchAggregator builds an aggregator over a recorded database, with a single batch of
+// broadcast messages on distinct topics - so every pin in the batch is dispatched
+func newBenchAggregator(b *testing.B, batchSize int) (*aggregator, []core.LocallySequenced, func()) {
+	coreconfig.Reset()
+	logrus.SetLevel(logrus.ErrorLevel)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	org1 := newTestOrg("org1")
+	batchID := fftypes.NewUUID()
+	messages := make([]*core.Message, batchSize)
+	byID := make(map[fftypes.UUID]*core.Message, batchSize)
+	pins := make([]core.LocallySequenced, batchSize)
+	for i := 0; i < batchSize; i++ {
+		topic := fmt.Sprintf("topic%d", i)
+		msg := &core.Message{
+			Header: core.MessageHeader{
+				ID:        fftypes.NewUUID(),
+				Topics:    []string{topic},
+				Namespace: "ns1",
+				SignerRef: core.SignerRef{Author: org1.DID, Key: "0x12345"},
+			},
+			Data: core.DataRefs{{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32()}},
+		}
+		messages[i] = msg
+		byID[*msg.Header.ID] = msg
+		pins[i] = &core.Pin{Sequence: int64(i + 1), Hash: broadcastContext(topic), Batch: batchID, Index: int64(i), Signer: "0x12345"}
+	}
+	bp, _ := (&core.Batch{
+		BatchHeader: core.BatchHeader{ID: batchID},
+		Payload:     core.BatchPayload{Messages: messages},
+	}).Confirmed()
+
+	// A recorded database keeps mock call matching out of the measurements
+	mdi := databasemocks.NewRecordedPlugin(map[string]interface{}{
+		"Capabilities":             &database.Capabilities{},
+		"GetBatchByID":             bp,
+		"GetPins":                  []*core.Pin{},
+		"CountPinsInSequenceRange": int64(batchSize),
+	})
+	mdm := &datamocks.Manager{}
+	mdm.On("CheckDataAvailable", mock.Anything, mock.Anything).Return(true, nil)
+	mdm.On("ValidateAll", mock.Anything, mock.Anything).Return(true, nil)
+	mdm.On("UpdateMessageStateIfCached", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	mdm.On("GetMessageWithDataCached", mock.Anything, mock.Anything, data.CRORequirePublicBlobRefs).Return(
+		func(ctx context.Context, msgID *fftypes.UUID, options ...data.CacheReadOption) *core.Message {
+			return byID[*msgID]
+		},
+		func(ctx context.Context, msgID *fftypes.UUID, options ...data.CacheReadOption) core.DataArray {
+			return core.DataArray{}
+		},
+		true, nil)
+	mim := &identitymanagermocks.Manager{}
+	mim.On("FindIdentityForVerifier", mock.Anything, mock.Anything, mock.Anything).Return(org1, nil)
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(false)
+	mbi := &blockchainmocks.Plugin{}
+	mbi.On("VerifierType").Return(core.VerifierTypeEthAddress)
+	cmi := &cachemocks.Manager{}
+	cmi.On("GetCache", mock.Anything).Return(cache.NewUmanagedCache(ctx, 100, 5*time.Minute), nil)
+
+	ag, err := newAggregator(ctx, "ns1", mdi, mbi, &privatemessagingmocks.Manager{}, &definitionsmocks.Handler{}, nil, mim, mdm, newEventNotifier(ctx, "bench"), mmi, cmi, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return ag, pins, cancel
+}
+
+func BenchmarkAggregatorProcessEvents(b *testing.B) {
+	for _, batchSize := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("batch%d", batchSize), func(b *testing.B) {
+			ag, pins, cancel := newBenchAggregator(b, batchSize)
+			defer cancel()
+			ep := ag.eventPoller.(*eventPoller)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := ag.processPinsEventsHandler(pins); err != nil {
+					b.Fatal(err)
+				}
+				// Drain the offset commit, as there is no commit loop running
+				select {
+				case <-ep.offsetCommitted:
+				default:
+				}
+			}
+		})
+	}
+}
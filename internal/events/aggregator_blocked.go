@@ -0,0 +1,113 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// blockedAgeBuckets are the minimum ages of the buckets in the histogram of blocked context ages
+var blockedAgeBuckets = []time.Duration{0, time.Minute, 10 * time.Minute, time.Hour, 24 * time.Hour}
+
+// committedOffset returns the sequence of the last pin the aggregator has committed, or -1 if it has not started
+func (ag *aggregator) committedOffset(ctx context.Context) (int64, error) {
+	offset, err := ag.database.GetOffset(ctx, core.OffsetTypeAggregator, ag.offsetName)
+	if err != nil || offset == nil {
+		return -1, err
+	}
+	return offset.Current, nil
+}
+
+// getBlockedContexts lists the contexts the aggregator has passed over, because their earliest
+// undispatched pin is behind its offset, along with the message for that pin where it is known
+func (ag *aggregator) getBlockedContexts(ctx context.Context, filter ffapi.AndFilter) ([]*core.BlockedContext, *ffapi.FilterResult, error) {
+	upTo, err := ag.committedOffset(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	pins, fr, err := ag.database.GetBlockedContexts(ctx, ag.namespace, upTo, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	blocked := make([]*core.BlockedContext, len(pins))
+	for i, pin := range pins {
+		blocked[i] = &core.BlockedContext{
+			Context: pin.Hash,
+			Pin:     pin.Sequence,
+			Batch:   pin.Batch,
+			Index:   pin.Index,
+			Signer:  pin.Signer,
+			Created: pin.Created,
+		}
+		if err := ag.resolveBlockingMessage(ctx, pin, blocked[i]); err != nil {
+			return nil, nil, err
+		}
+	}
+	return blocked, fr, nil
+}
+
+func (ag *aggregator) resolveBlockingMessage(ctx context.Context, pin *core.Pin, blocked *core.BlockedContext) error {
+	// The batch, or the message, might be what the context is waiting for
+	_, manifest, err := ag.GetBatchForPin(ctx, pin)
+	if err != nil || manifest == nil {
+		return err
+	}
+	_, msgEntry, _ := ag.extractBatchMessagePin(manifest, pin.Index)
+	if msgEntry == nil {
+		return nil
+	}
+	blocked.Message = msgEntry.ID
+	msg, err := ag.database.GetMessageByID(ctx, ag.namespace, msgEntry.ID)
+	if err != nil || msg == nil {
+		return err
+	}
+	blocked.Author = msg.Header.Author
+	blocked.Tag = msg.Header.Tag
+	blocked.Topics = msg.Header.Topics
+	return nil
+}
+
+func (ag *aggregator) getBlockedStats(ctx context.Context) (*core.BlockedContextStats, error) {
+	upTo, err := ag.committedOffset(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Time(*fftypes.Now())
+	cutoffs := make([]*fftypes.FFTime, len(blockedAgeBuckets))
+	for i, age := range blockedAgeBuckets {
+		cutoff := fftypes.FFTime(now.Add(-age))
+		cutoffs[i] = &cutoff
+	}
+	stats, err := ag.database.GetBlockedStats(ctx, ag.namespace, upTo, cutoffs)
+	if err != nil {
+		return nil, err
+	}
+	for i, bucket := range stats.Histogram {
+		minAge := fftypes.FFDuration(blockedAgeBuckets[i])
+		bucket.MinAge = &minAge
+	}
+	if stats.Oldest != nil {
+		oldestAge := fftypes.FFDuration(now.Sub(time.Time(*stats.Oldest)))
+		stats.OldestAge = &oldestAge
+	}
+	return stats, nil
+}
@@ -0,0 +1,170 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetBlockedContextsResolvesMessage(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	msg1, msg2, _, manifest := newTestManifest(core.MessageTypeBroadcast, nil)
+	msg1.Header.Tag = "tag1"
+	batch := &core.BatchPersisted{BatchHeader: core.BatchHeader{ID: manifest.ID}, Hash: fftypes.NewRandB32()}
+	ag.cacheBatch(ag.getBatchCacheKey(batch.ID, batch.Hash), batch, manifest)
+	pins := []*core.Pin{
+		{Sequence: 10, Hash: fftypes.NewRandB32(), Batch: batch.ID, BatchHash: batch.Hash, Index: 0},
+		{Sequence: 11, Hash: fftypes.NewRandB32(), Batch: batch.ID, BatchHash: batch.Hash, Index: 1},
+		{Sequence: 12, Hash: fftypes.NewRandB32(), Batch: batch.ID, BatchHash: batch.Hash, Index: 2},
+		{Sequence: 13, Hash: fftypes.NewRandB32(), Batch: fftypes.NewUUID(), BatchHash: fftypes.NewRandB32(), Index: 0},
+	}
+
+	ag.mdi.On("GetOffset", ag.ctx, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{Current: 100}, nil)
+	ag.mdi.On("GetBlockedContexts", ag.ctx, "ns1", int64(100), mock.Anything).Return(pins, nil, nil)
+	ag.mdi.On("GetMessageByID", ag.ctx, "ns1", msg1.Header.ID).Return(msg1, nil)
+	ag.mdi.On("GetMessageByID", ag.ctx, "ns1", msg2.Header.ID).Return(nil, nil)
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", pins[3].Batch).Return(nil, nil)
+
+	fb := database.PinQueryFactory.NewFilter(ag.ctx)
+	blocked, _, err := ag.getBlockedContexts(ag.ctx, fb.And())
+	assert.NoError(t, err)
+	assert.Len(t, blocked, 4)
+	assert.Equal(t, pins[0].Hash, blocked[0].Context)
+	assert.Equal(t, int64(10), blocked[0].Pin)
+	assert.Equal(t, msg1.Header.ID, blocked[0].Message)
+	assert.Equal(t, msg1.Header.Author, blocked[0].Author)
+	assert.Equal(t, "tag1", blocked[0].Tag)
+	assert.Equal(t, msg1.Header.Topics, blocked[0].Topics)
+	// The message has not arrived
+	assert.Equal(t, msg2.Header.ID, blocked[1].Message)
+	assert.Empty(t, blocked[1].Author)
+	// The pin is beyond the messages in the batch
+	assert.Nil(t, blocked[2].Message)
+	// The batch has not arrived
+	assert.Nil(t, blocked[3].Message)
+}
+
+func TestGetBlockedContextsOffsetFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ag.mdi.On("GetOffset", ag.ctx, core.OffsetTypeAggregator, aggregatorOffsetName).Return(nil, fmt.Errorf("pop"))
+
+	fb := database.PinQueryFactory.NewFilter(ag.ctx)
+	_, _, err := ag.getBlockedContexts(ag.ctx, fb.And())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetBlockedContextsQueryFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ag.mdi.On("GetOffset", ag.ctx, core.OffsetTypeAggregator, aggregatorOffsetName).Return(nil, nil)
+	ag.mdi.On("GetBlockedContexts", ag.ctx, "ns1", int64(-1), mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	fb := database.PinQueryFactory.NewFilter(ag.ctx)
+	_, _, err := ag.getBlockedContexts(ag.ctx, fb.And())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetBlockedContextsMessageFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	msg1, _, _, manifest := newTestManifest(core.MessageTypeBroadcast, nil)
+	batch := &core.BatchPersisted{BatchHeader: core.BatchHeader{ID: manifest.ID}, Hash: fftypes.NewRandB32()}
+	ag.cacheBatch(ag.getBatchCacheKey(batch.ID, batch.Hash), batch, manifest)
+
+	ag.mdi.On("GetOffset", ag.ctx, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{Current: 100}, nil)
+	ag.mdi.On("GetBlockedContexts", ag.ctx, "ns1", int64(100), mock.Anything).Return([]*core.Pin{
+		{Sequence: 10, Hash: fftypes.NewRandB32(), Batch: batch.ID, BatchHash: batch.Hash, Index: 0},
+	}, nil, nil)
+	ag.mdi.On("GetMessageByID", ag.ctx, "ns1", msg1.Header.ID).Return(nil, fmt.Errorf("pop"))
+
+	fb := database.PinQueryFactory.NewFilter(ag.ctx)
+	_, _, err := ag.getBlockedContexts(ag.ctx, fb.And())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetBlockedStats(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	oldest := fftypes.FFTime(time.Now().Add(-2 * time.Hour))
+	ag.mdi.On("GetOffset", ag.ctx, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{Current: 100}, nil)
+	ag.mdi.On("GetBlockedStats", ag.ctx, "ns1", int64(100), mock.MatchedBy(func(cutoffs []*fftypes.FFTime) bool {
+		return len(cutoffs) == len(blockedAgeBuckets) && time.Time(*cutoffs[1]).Before(time.Time(*cutoffs[0]))
+	})).Return(&core.BlockedContextStats{
+		Total:  1,
+		Oldest: &oldest,
+		Histogram: []*core.BlockedContextAgeBucket{
+			{Count: 0}, {Count: 0}, {Count: 0}, {Count: 1}, {Count: 0},
+		},
+	}, nil)
+
+	stats, err := ag.getBlockedStats(ag.ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Total)
+	assert.GreaterOrEqual(t, time.Duration(*stats.OldestAge), 2*time.Hour)
+	assert.Equal(t, time.Hour, time.Duration(*stats.Histogram[3].MinAge))
+}
+
+func TestGetBlockedStatsNone(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ag.mdi.On("GetOffset", ag.ctx, core.OffsetTypeAggregator, aggregatorOffsetName).Return(nil, nil)
+	ag.mdi.On("GetBlockedStats", ag.ctx, "ns1", int64(-1), mock.Anything).Return(&core.BlockedContextStats{
+		Histogram: []*core.BlockedContextAgeBucket{},
+	}, nil)
+
+	stats, err := ag.getBlockedStats(ag.ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), stats.Total)
+	assert.Nil(t, stats.OldestAge)
+}
+
+func TestGetBlockedStatsOffsetFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ag.mdi.On("GetOffset", ag.ctx, core.OffsetTypeAggregator, aggregatorOffsetName).Return(nil, fmt.Errorf("pop"))
+
+	_, err := ag.getBlockedStats(ag.ctx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetBlockedStatsQueryFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ag.mdi.On("GetOffset", ag.ctx, core.OffsetTypeAggregator, aggregatorOffsetName).Return(nil, nil)
+	ag.mdi.On("GetBlockedStats", ag.ctx, "ns1", int64(-1), mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := ag.getBlockedStats(ag.ctx)
+	assert.EqualError(t, err, "pop")
+}
@@ -0,0 +1,57 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+// orderingStrategy determines which offset the aggregator of a namespace commits its progress
+// through the pin sequence to. The pins themselves are always queried within the namespace.
+type orderingStrategy interface {
+	offsetName(namespace string) string
+}
+
+// globalSequence shares a single offset between the aggregators of all namespaces
+type globalSequence struct{}
+
+func (globalSequence) offsetName(namespace string) string {
+	return aggregatorOffsetName
+}
+
+// namespacePartitioned stores a separate offset for each namespace, so a burst of pins in one
+// namespace cannot move the committed offset of another. When first enabled the new offset is
+// initialized according to event.aggregator.firstEvent.
+type namespacePartitioned struct{}
+
+func (namespacePartitioned) offsetName(namespace string) string {
+	return aggregatorOffsetName + "_" + namespace
+}
+
+func newOrderingStrategy(ctx context.Context, name string) (orderingStrategy, error) {
+	switch name {
+	case "global":
+		return globalSequence{}, nil
+	case "namespace":
+		return namespacePartitioned{}, nil
+	default:
+		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidOrderingStrategy, name)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly/internal/cache"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/cachemocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/definitionsmocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestAggregatorWithOrdering(strategy string) (*aggregator, error) {
+	coreconfig.Reset()
+	config.Set(coreconfig.EventAggregatorOrderingStrategy, strategy)
+	ctx := context.Background()
+	cmi := &cachemocks.Manager{}
+	cmi.On("GetCache", mock.Anything).Return(cache.NewUmanagedCache(ctx, 100, 5*time.Minute), nil)
+	mbi := &blockchainmocks.Plugin{}
+	mbi.On("VerifierType").Return(core.VerifierTypeEthAddress)
+	return newAggregator(ctx, "ns1", &databasemocks.Plugin{}, mbi, &privatemessagingmocks.Manager{}, &definitionsmocks.Handler{}, nil, &identitymanagermocks.Manager{}, &datamocks.Manager{}, newEventNotifier(ctx, "ut"), &metricsmocks.Manager{}, cmi, nil)
+}
+
+func TestOrderingStrategyGlobal(t *testing.T) {
+	ag, err := newTestAggregatorWithOrdering("global")
+	assert.NoError(t, err)
+	assert.Equal(t, "ff_aggregator", ag.eventPoller.(*eventPoller).conf.offsetName)
+}
+
+func TestOrderingStrategyNamespace(t *testing.T) {
+	ag, err := newTestAggregatorWithOrdering("namespace")
+	assert.NoError(t, err)
+	assert.Equal(t, "ff_aggregator_ns1", ag.eventPoller.(*eventPoller).conf.offsetName)
+}
+
+func TestOrderingStrategyInvalid(t *testing.T) {
+	_, err := newTestAggregatorWithOrdering("wrong")
+	assert.Regexp(t, "FF10494.*wrong", err)
+}
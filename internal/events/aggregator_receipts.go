@@ -0,0 +1,111 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// recordDeliveryReceipts records a pending delivery receipt for a private message, on behalf of each member of
+// the message's group that is registered to this node (other than the author of the message).
+// This is called within the database transaction that confirms the message, and the receipts are broadcast
+// from deliveryReceiptLoop after it commits - so a failure to send is retried, including after a restart.
+func (ag *aggregator) recordDeliveryReceipts(ctx context.Context, msg *core.Message) error {
+	node, err := ag.identity.GetLocalNode(ctx)
+	if err != nil {
+		return err
+	}
+	group, err := ag.messaging.GetGroupByHashCached(ctx, msg.Header.Group)
+	if err != nil {
+		return err
+	}
+	if group == nil {
+		log.L(ctx).Errorf("Unable to record delivery receipts for message '%s' - group '%s' not found", msg.Header.ID, msg.Header.Group)
+		return nil
+	}
+	for _, member := range group.Members {
+		if !member.Node.Equals(node.ID) || member.Identity == msg.Header.Author {
+			continue
+		}
+		receipt := &core.DeliveryReceipt{
+			ID:           fftypes.NewUUID(),
+			Namespace:    ag.namespace,
+			MessageID:    msg.Header.ID,
+			MessageHash:  msg.Hash,
+			RecipientDID: member.Identity,
+			ReceivedAt:   fftypes.Now(),
+		}
+		if err := ag.database.InsertPendingDeliveryReceipt(ctx, receipt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ag *aggregator) shoulderTapDeliveryReceipts() {
+	select {
+	case ag.receiptsShoulderTap <- true:
+	default:
+	}
+}
+
+// deliveryReceiptLoop broadcasts the pending delivery receipts, when started and each time more are recorded.
+// Failures are retried with backoff until every pending receipt has been sent.
+func (ag *aggregator) deliveryReceiptLoop(ctx context.Context) {
+	defer close(ag.receiptsDone)
+	for {
+		// Only returns an error once the context is cancelled
+		_ = ag.retry.Do(ctx, "send delivery receipts", func(attempt int) (retry bool, err error) {
+			return true, ag.sendPendingDeliveryReceipts(ctx)
+		})
+		select {
+		case <-ag.receiptsShoulderTap:
+		case <-ctx.Done():
+			log.L(ctx).Debugf("Delivery receipt loop stopping")
+			return
+		}
+	}
+}
+
+// sendPendingDeliveryReceipts broadcasts each pending receipt, and removes it once sent. A receipt that is
+// broadcast again (because the removal failed) is rejected as a duplicate by the definition handler.
+func (ag *aggregator) sendPendingDeliveryReceipts(ctx context.Context) error {
+	for {
+		receipts, err := ag.database.GetPendingDeliveryReceipts(ctx, ag.namespace, ag.receiptsBatchSize)
+		if err != nil {
+			return err
+		}
+		var sendErr error
+		for _, receipt := range receipts {
+			if err := ag.receipts.SendDeliveryReceipt(ctx, receipt); err != nil {
+				log.L(ctx).Errorf("Failed to send delivery receipt for message '%s' from '%s': %s", receipt.MessageID, receipt.RecipientDID, err)
+				sendErr = err
+				continue
+			}
+			if err := ag.database.DeletePendingDeliveryReceipt(ctx, ag.namespace, receipt.ID); err != nil {
+				return err
+			}
+		}
+		if sendErr != nil || len(receipts) < ag.receiptsBatchSize {
+			return sendErr
+		}
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/cache"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/events/testmocks"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/cachemocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/definitionsmocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestReceiptMessage() (*core.Message, *core.Group, *core.Identity) {
+	localNode := &core.Identity{IdentityBase: core.IdentityBase{ID: fftypes.NewUUID()}}
+	msg := &core.Message{
+		Header: core.MessageHeader{
+			ID:    fftypes.NewUUID(),
+			Type:  core.MessageTypePrivate,
+			Group: fftypes.NewRandB32(),
+			SignerRef: core.SignerRef{
+				Author: "did:firefly:org/org1",
+			},
+		},
+		Hash: fftypes.NewRandB32(),
+	}
+	group := &core.Group{
+		GroupIdentity: core.GroupIdentity{
+			Members: core.Members{
+				{Identity: "did:firefly:org/org1", Node: fftypes.NewUUID()},
+				{Identity: "did:firefly:org/org2", Node: localNode.ID},
+				{Identity: "did:firefly:org/org3", Node: localNode.ID},
+				{Identity: "did:firefly:org/org4", Node: fftypes.NewUUID()},
+			},
+		},
+		Hash: msg.Header.Group,
+	}
+	return msg, group, localNode
+}
+
+func TestNewAggregatorDeliveryReceiptsEnabled(t *testing.T) {
+	coreconfig.Reset()
+	config.Set(coreconfig.PrivateMessagingDeliveryReceiptsEnabled, true)
+	ctx := context.Background()
+	cmi := &cachemocks.Manager{}
+	cmi.On("GetCache", mock.Anything).Return(cache.NewUmanagedCache(ctx, 100, 5*time.Minute), nil)
+	mbi := &blockchainmocks.Plugin{}
+	mbi.On("VerifierType").Return(core.VerifierTypeEthAddress)
+	mds := &definitionsmocks.Sender{}
+	ag, err := newAggregator(ctx, "ns1", &databasemocks.Plugin{}, mbi, &privatemessagingmocks.Manager{}, &definitionsmocks.Handler{}, mds, &identitymanagermocks.Manager{}, &datamocks.Manager{}, newEventNotifier(ctx, "ut"), &metricsmocks.Manager{}, cmi, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, mds, ag.receipts)
+}
+
+func TestCompleteDispatchRecordsDeliveryReceipts(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.receipts = &definitionsmocks.Sender{}
+	bs := newBatchState(ag.aggregator)
+
+	msg, group, localNode := newTestReceiptMessage()
+	ag.mim.On("GetLocalNode", mock.Anything).Return(localNode, nil)
+	ag.mpm.On("GetGroupByHashCached", mock.Anything, msg.Header.Group).Return(group, nil)
+	ag.mdi.On("InsertPendingDeliveryReceipt", mock.Anything, mock.MatchedBy(func(r *core.DeliveryReceipt) bool {
+		return r.ID != nil && r.Namespace == "ns1" && r.MessageID.Equals(msg.Header.ID) && r.MessageHash.Equals(msg.Hash) && r.RecipientDID == "did:firefly:org/org2"
+	})).Return(nil).Once()
+	ag.mdi.On("InsertPendingDeliveryReceipt", mock.Anything, mock.MatchedBy(func(r *core.DeliveryReceipt) bool {
+		return r.RecipientDID == "did:firefly:org/org3"
+	})).Return(nil).Once()
+
+	ag.completeDispatch(core.ActionConfirm, nil, msg, nil, bs)
+	ag.completeDispatch(core.ActionReject, nil, &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID(), Group: msg.Header.Group}}, nil, bs)
+	ag.completeDispatch(core.ActionConfirm, nil, &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID(), Type: core.MessageTypeGroupInit, Group: msg.Header.Group}}, nil, bs)
+	ag.completeDispatch(core.ActionConfirm, nil, &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID(), Type: core.MessageTypeBroadcast}}, nil, bs)
+
+	assert.True(t, bs.deliveryReceiptsRecorded)
+	err := bs.RunFinalize(ag.ctx)
+	assert.NoError(t, err)
+
+	ag.mdi.AssertExpectations(t)
+}
+
+func TestProcessWithBatchStateShoulderTapsDeliveryReceipts(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.receipts = &definitionsmocks.Sender{}
+	ag.receiptsShoulderTap = make(chan bool, 1)
+	mockRunAsGroupPassthrough(ag.mdi)
+
+	for i := 0; i < 2; i++ {
+		err := ag.processWithBatchState(func(ctx context.Context, state *batchState) error {
+			state.deliveryReceiptsRecorded = true
+			return nil
+		})
+		assert.NoError(t, err)
+	}
+
+	assert.Len(t, ag.receiptsShoulderTap, 1)
+}
+
+func TestRecordDeliveryReceiptsLocalNodeFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	msg, _, _ := newTestReceiptMessage()
+	ag.mim.On("GetLocalNode", mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	err := ag.recordDeliveryReceipts(ag.ctx, msg)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRecordDeliveryReceiptsGroupFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	msg, _, localNode := newTestReceiptMessage()
+	ag.mim.On("GetLocalNode", mock.Anything).Return(localNode, nil)
+	ag.mpm.On("GetGroupByHashCached", mock.Anything, msg.Header.Group).Return(nil, fmt.Errorf("pop"))
+
+	err := ag.recordDeliveryReceipts(ag.ctx, msg)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRecordDeliveryReceiptsGroupNotFound(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	msg, _, localNode := newTestReceiptMessage()
+	ag.mim.On("GetLocalNode", mock.Anything).Return(localNode, nil)
+	ag.mpm.On("GetGroupByHashCached", mock.Anything, msg.Header.Group).Return(nil, nil)
+
+	err := ag.recordDeliveryReceipts(ag.ctx, msg)
+	assert.NoError(t, err)
+}
+
+func TestRecordDeliveryReceiptsInsertFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	msg, group, localNode := newTestReceiptMessage()
+	ag.mim.On("GetLocalNode", mock.Anything).Return(localNode, nil)
+	ag.mpm.On("GetGroupByHashCached", mock.Anything, msg.Header.Group).Return(group, nil)
+	ag.mdi.On("InsertPendingDeliveryReceipt", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := ag.recordDeliveryReceipts(ag.ctx, msg)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDeliveryReceiptLoopSendsPending(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	mds := &definitionsmocks.Sender{}
+	ag.receipts = mds
+	ag.receiptsBatchSize = 2
+	ag.receiptsShoulderTap = make(chan bool, 1)
+	ag.retry.InitialDelay = 1 * time.Microsecond
+
+	receipt1 := &core.DeliveryReceipt{ID: fftypes.NewUUID(), RecipientDID: "did:firefly:org/org2"}
+	receipt2 := &core.DeliveryReceipt{ID: fftypes.NewUUID(), RecipientDID: "did:firefly:org/org3"}
+	receipt3 := &core.DeliveryReceipt{ID: fftypes.NewUUID(), RecipientDID: "did:firefly:org/org4"}
+
+	// First attempt fails to send receipt2, so is retried
+	ag.mdi.On("GetPendingDeliveryReceipts", mock.Anything, "ns1", 2).Return([]*core.DeliveryReceipt{receipt1, receipt2}, nil).Once()
+	mds.On("SendDeliveryReceipt", mock.Anything, receipt1).Return(nil).Once()
+	ag.mdi.On("DeletePendingDeliveryReceipt", mock.Anything, "ns1", receipt1.ID).Return(nil).Once()
+	mds.On("SendDeliveryReceipt", mock.Anything, receipt2).Return(fmt.Errorf("pop")).Once()
+	// Retry pages through the remaining receipts
+	ag.mdi.On("GetPendingDeliveryReceipts", mock.Anything, "ns1", 2).Return([]*core.DeliveryReceipt{receipt2, receipt3}, nil).Once()
+	mds.On("SendDeliveryReceipt", mock.Anything, receipt2).Return(nil).Once()
+	ag.mdi.On("DeletePendingDeliveryReceipt", mock.Anything, "ns1", receipt2.ID).Return(nil).Once()
+	mds.On("SendDeliveryReceipt", mock.Anything, receipt3).Return(nil).Once()
+	ag.mdi.On("DeletePendingDeliveryReceipt", mock.Anything, "ns1", receipt3.ID).Return(nil).Once()
+	done := make(chan struct{})
+	ag.mdi.On("GetPendingDeliveryReceipts", mock.Anything, "ns1", 2).Return([]*core.DeliveryReceipt{}, nil).Once().Run(func(args mock.Arguments) {
+		close(done)
+	})
+
+	ctx, cancel := context.WithCancel(ag.ctx)
+	ag.receiptsDone = make(chan struct{})
+	go ag.deliveryReceiptLoop(ctx)
+	<-done
+	cancel()
+	<-ag.receiptsDone
+
+	mds.AssertExpectations(t)
+}
+
+func TestDeliveryReceiptLoopShoulderTap(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.receipts = &definitionsmocks.Sender{}
+	ag.receiptsBatchSize = 10
+	ag.receiptsShoulderTap = make(chan bool, 1)
+
+	polled := make(chan bool)
+	ag.mdi.On("GetPendingDeliveryReceipts", mock.Anything, "ns1", 10).Return([]*core.DeliveryReceipt{}, nil).Run(func(args mock.Arguments) {
+		polled <- true
+	})
+
+	ctx, cancel := context.WithCancel(ag.ctx)
+	ag.receiptsDone = make(chan struct{})
+	go ag.deliveryReceiptLoop(ctx)
+	<-polled
+	ag.shoulderTapDeliveryReceipts()
+	<-polled
+	cancel()
+	<-ag.receiptsDone
+}
+
+func TestSendPendingDeliveryReceiptsQueryFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.receiptsBatchSize = 10
+
+	ag.mdi.On("GetPendingDeliveryReceipts", mock.Anything, "ns1", 10).Return(nil, fmt.Errorf("pop"))
+
+	err := ag.sendPendingDeliveryReceipts(ag.ctx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestSendPendingDeliveryReceiptsDeleteFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	mds := &definitionsmocks.Sender{}
+	ag.receipts = mds
+	ag.receiptsBatchSize = 10
+
+	receipt := &core.DeliveryReceipt{ID: fftypes.NewUUID()}
+	ag.mdi.On("GetPendingDeliveryReceipts", mock.Anything, "ns1", 10).Return([]*core.DeliveryReceipt{receipt}, nil)
+	mds.On("SendDeliveryReceipt", mock.Anything, receipt).Return(nil)
+	ag.mdi.On("DeletePendingDeliveryReceipt", mock.Anything, "ns1", receipt.ID).Return(fmt.Errorf("pop"))
+
+	err := ag.sendPendingDeliveryReceipts(ag.ctx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestStartStopDeliveryReceiptLoop(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.receipts = &definitionsmocks.Sender{}
+	ag.receiptsBatchSize = 10
+	ag.receiptsShoulderTap = make(chan bool, 1)
+
+	mep := &testmocks.MockEventPoller{}
+	ag.eventPoller = mep
+	mep.On("Start").Return(nil)
+	mep.On("Stop").Return()
+	ag.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*core.Offset{}, nil, nil)
+	ag.mdi.On("GetPendingDeliveryReceipts", mock.Anything, "ns1", 10).Return([]*core.DeliveryReceipt{}, nil).Maybe()
+
+	err := ag.start()
+	assert.NoError(t, err)
+	ag.stop()
+}
@@ -0,0 +1,267 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// maxFinishedReplays is the number of finished replays kept in memory, so their final status can still be queried
+const maxFinishedReplays = 25
+
+// pinReplay runs a second event poller over a range of pins the aggregator has already passed,
+// processing any that are still undispatched. It has its own offset, so the offset of the
+// aggregator is never moved, and page processing is serialized with the aggregator.
+//
+// The poller reads every pin in the range, dispatched or not, up to the last pin that existed in the
+// range when the replay started. Pins are never deleted, so the page containing that pin is always
+// delivered to the handler, which completes the replay - even if other pins are dispatched meanwhile.
+//
+// Replays are only held in memory, so the offset of any replay that was running when the node
+// stopped is orphaned, and is deleted when the aggregator next starts.
+type pinReplay struct {
+	ag           *aggregator
+	poller       *eventPoller
+	status       core.PinReplay
+	lastSequence int64
+}
+
+func (ag *aggregator) startReplay(ctx context.Context, input *core.PinReplayInput) (*core.PinReplay, error) {
+	if input.FromSequence < 0 || input.FromSequence > input.ToSequence {
+		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidReplayRange, input.FromSequence, input.ToSequence)
+	}
+
+	// Only pins the aggregator has committed past can be replayed, so the two never process the same pin
+	aggregatorOffset, err := ag.committedOffset(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if input.ToSequence > aggregatorOffset {
+		return nil, i18n.NewError(ctx, coremsgs.MsgReplayAheadOfAggregator, aggregatorOffset)
+	}
+
+	fb := database.PinQueryFactory.NewFilterLimit(ctx, 1)
+	lastPins, _, err := ag.database.GetPins(ctx, ag.namespace, fb.And(
+		fb.Gte("sequence", input.FromSequence),
+		fb.Lte("sequence", input.ToSequence),
+	).Sort("sequence").Descending())
+	if err != nil {
+		return nil, err
+	}
+
+	ag.replayLock.Lock()
+	defer ag.replayLock.Unlock()
+	for _, r := range ag.replays {
+		if r.status.Status == core.PinReplayStatusRunning &&
+			input.FromSequence <= r.status.ToSequence && input.ToSequence >= r.status.FromSequence {
+			return nil, i18n.NewError(ctx, coremsgs.MsgReplayRangeOverlap, input.FromSequence, input.ToSequence, r.status.ID)
+		}
+	}
+
+	r := &pinReplay{
+		ag: ag,
+		status: core.PinReplay{
+			ID:           fftypes.NewUUID(),
+			Namespace:    ag.namespace,
+			FromSequence: input.FromSequence,
+			ToSequence:   input.ToSequence,
+			Current:      input.FromSequence - 1,
+			Status:       core.PinReplayStatusRunning,
+			Created:      fftypes.Now(),
+		},
+	}
+	if len(lastPins) == 0 {
+		// There are no pins in the range, so there is nothing to replay
+		r.status.Current = input.ToSequence
+		r.status.Status = core.PinReplayStatusComplete
+		r.status.Completed = r.status.Created
+		ag.replays[*r.status.ID] = r
+		ag.pruneFinishedReplays()
+		status := r.status
+		return &status, nil
+	}
+	r.lastSequence = lastPins[0].Sequence
+	firstEvent := core.SubOptsFirstEvent(strconv.FormatInt(input.FromSequence-1, 10))
+	r.poller = newEventPoller(ag.ctx, ag.database, ag.pinNotifier, &eventPollerConf{
+		eventBatchSize:   config.GetInt(coreconfig.EventAggregatorBatchSize),
+		eventPollTimeout: config.GetDuration(coreconfig.EventAggregatorPollTimeout),
+		retry:            *ag.retry,
+		firstEvent:       &firstEvent,
+		namespace:        ag.namespace,
+		offsetType:       core.OffsetTypeReplay,
+		offsetName:       ag.replayOffsetName(r.status.ID),
+		newEventsHandler: r.processPins,
+		getItems:         r.getPins,
+		queryFactory:     database.PinQueryFactory,
+		addCriteria: func(af ffapi.AndFilter) ffapi.AndFilter {
+			return af.Condition(af.Builder().Lte("sequence", r.lastSequence))
+		},
+	})
+	log.L(ctx).Infof("Starting replay %s of pins %d-%d", r.status.ID, input.FromSequence, input.ToSequence)
+	if err := r.poller.Start(); err != nil {
+		return nil, err
+	}
+	ag.replays[*r.status.ID] = r
+	status := r.status
+	return &status, nil
+}
+
+func (ag *aggregator) replayOffsetName(id *fftypes.UUID) string {
+	return ag.namespace + ":" + id.String()
+}
+
+// deleteOrphanedReplayOffsets removes the offsets of replays in this namespace that did not complete before
+// the node last stopped. Failures are only logged, as a stale offset row does not affect processing.
+func (ag *aggregator) deleteOrphanedReplayOffsets() {
+	fb := database.OffsetQueryFactory.NewFilter(ag.ctx)
+	offsets, _, err := ag.database.GetOffsets(ag.ctx, fb.And(
+		fb.Eq("type", core.OffsetTypeReplay),
+		fb.StartsWith("name", ag.namespace+":"),
+	))
+	if err != nil {
+		log.L(ag.ctx).Warnf("Failed to query orphaned replay offsets: %s", err)
+		return
+	}
+	for _, offset := range offsets {
+		log.L(ag.ctx).Infof("Deleting offset '%s' of a replay that did not complete", offset.Name)
+		if err := ag.database.DeleteOffset(ag.ctx, core.OffsetTypeReplay, offset.Name); err != nil {
+			log.L(ag.ctx).Warnf("Failed to delete offset '%s': %s", offset.Name, err)
+		}
+	}
+}
+
+// pruneFinishedReplays removes the oldest finished replays beyond maxFinishedReplays. Must be called with replayLock held.
+func (ag *aggregator) pruneFinishedReplays() {
+	var finished []*pinReplay
+	for _, r := range ag.replays {
+		if r.status.Status != core.PinReplayStatusRunning {
+			finished = append(finished, r)
+		}
+	}
+	if len(finished) <= maxFinishedReplays {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].status.Completed.Time().Before(*finished[j].status.Completed.Time())
+	})
+	for _, r := range finished[:len(finished)-maxFinishedReplays] {
+		delete(ag.replays, *r.status.ID)
+	}
+}
+
+func (ag *aggregator) getReplay(ctx context.Context, id *fftypes.UUID) (*core.PinReplay, error) {
+	ag.replayLock.Lock()
+	defer ag.replayLock.Unlock()
+	r, ok := ag.replays[*id]
+	if !ok {
+		return nil, i18n.NewError(ctx, coremsgs.Msg404NotFound)
+	}
+	status := r.status
+	return &status, nil
+}
+
+func (r *pinReplay) getPins(ctx context.Context, filter ffapi.Filter, offset int64) ([]core.LocallySequenced, error) {
+	pins, _, err := r.ag.database.GetPins(ctx, r.ag.namespace, filter)
+	ls := make([]core.LocallySequenced, len(pins))
+	for i, p := range pins {
+		ls[i] = p
+	}
+	return ls, err
+}
+
+func (r *pinReplay) processPins(items []core.LocallySequenced) (repoll bool, err error) {
+	var pins []*core.Pin
+	for _, item := range items {
+		if pin := item.(*core.Pin); !pin.Dispatched {
+			pins = append(pins, pin)
+		}
+	}
+	if len(pins) > 0 {
+		err = r.ag.processWithBatchState(func(ctx context.Context, state *batchState) error {
+			return r.ag.processPins(ctx, pins, state)
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+	offset := items[len(items)-1].LocalSequence()
+	r.ag.replayLock.Lock()
+	r.status.Current = offset
+	r.ag.replayLock.Unlock()
+	if offset >= r.lastSequence {
+		return false, r.complete()
+	}
+	return true, r.poller.CommitOffset(r.poller.ctx, offset)
+}
+
+// countUndispatched returns the number of pins in the range that are still undispatched
+func (r *pinReplay) countUndispatched() (count int64, err error) {
+	err = r.poller.retryDo("count undispatched pins", func(attempt int) (retry bool, err error) {
+		fb := database.PinQueryFactory.NewFilterLimit(r.poller.ctx, 1)
+		_, res, err := r.ag.database.GetPins(r.poller.ctx, r.ag.namespace, fb.And(
+			fb.Gte("sequence", r.status.FromSequence),
+			fb.Lte("sequence", r.status.ToSequence),
+			fb.Eq("dispatched", false),
+		).Count(true))
+		if err != nil {
+			return true, err
+		}
+		if res != nil && res.TotalCount != nil {
+			count = *res.TotalCount
+		}
+		return false, nil
+	})
+	return count, err
+}
+
+// complete is called by the handler of the replay poller, once it has processed the last pin in the range.
+// Pins in the range that could not be dispatched when the replay reached them (for example because their
+// context is still blocked) are left undispatched, and counted in the final status.
+// The poller is cancelled rather than stopped, as it cannot wait for its own event loop to exit.
+func (r *pinReplay) complete() error {
+	undispatched, err := r.countUndispatched()
+	if err != nil {
+		return err
+	}
+	r.ag.replayLock.Lock()
+	r.status.Status = core.PinReplayStatusComplete
+	r.status.Completed = fftypes.Now()
+	r.status.Undispatched = undispatched
+	r.ag.pruneFinishedReplays()
+	r.ag.replayLock.Unlock()
+	log.L(r.ag.ctx).Infof("Replay %s of pins %d-%d complete (undispatched=%d)", r.status.ID, r.status.FromSequence, r.status.ToSequence, undispatched)
+	r.poller.cancelCtx()
+	go func() {
+		<-r.poller.closed
+		if err := r.ag.database.DeleteOffset(r.ag.ctx, core.OffsetTypeReplay, r.ag.replayOffsetName(r.status.ID)); err != nil {
+			log.L(r.ag.ctx).Warnf("Failed to delete offset for replay %s: %s", r.status.ID, err)
+		}
+	}()
+	return nil
+}
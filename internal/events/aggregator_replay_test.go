@@ -0,0 +1,337 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReplayPinsToCompletion(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	count := int64(2)
+	pin1 := &core.Pin{Sequence: 10, Batch: fftypes.NewUUID(), BatchHash: fftypes.NewRandB32()}
+	pin2 := &core.Pin{Sequence: 11, Batch: fftypes.NewUUID(), BatchHash: fftypes.NewRandB32(), Dispatched: true}
+	pin3 := &core.Pin{Sequence: 12, Batch: fftypes.NewUUID(), BatchHash: fftypes.NewRandB32()}
+	mockRunAsGroupPassthrough(ag.mdi)
+	ag.mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{Current: 100}, nil)
+	ag.mdi.On("GetOffset", mock.Anything, core.OffsetTypeReplay, mock.Anything).Return(&core.Offset{Current: 9, RowID: 1}, nil)
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{pin3}, nil, nil).Once()
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{pin1, pin2, pin3}, nil, nil).Once()
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return(nil, &ffapi.FilterResult{TotalCount: &count}, nil).Once()
+	ag.mdi.On("GetBatchByID", mock.Anything, "ns1", pin1.Batch).Return(nil, nil)
+	ag.mdi.On("GetBatchByID", mock.Anything, "ns1", pin3.Batch).Return(nil, nil)
+	deleted := make(chan struct{})
+	ag.mdi.On("DeleteOffset", mock.Anything, core.OffsetTypeReplay, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		close(deleted)
+	})
+
+	replay, err := ag.startReplay(ag.ctx, &core.PinReplayInput{FromSequence: 10, ToSequence: 20})
+	assert.NoError(t, err)
+	assert.Equal(t, core.PinReplayStatusRunning, replay.Status)
+	assert.Equal(t, int64(9), replay.Current)
+
+	<-deleted
+	replay, err = ag.getReplay(ag.ctx, replay.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, core.PinReplayStatusComplete, replay.Status)
+	assert.Equal(t, int64(12), replay.Current)
+	assert.Equal(t, int64(2), replay.Undispatched)
+	assert.NotNil(t, replay.Completed)
+	ag.mdi.AssertNotCalled(t, "GetBatchByID", mock.Anything, "ns1", pin2.Batch)
+}
+
+func TestReplayPinsEmptyRange(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ag.mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{Current: 100}, nil)
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
+
+	replay, err := ag.startReplay(ag.ctx, &core.PinReplayInput{FromSequence: 10, ToSequence: 20})
+	assert.NoError(t, err)
+	assert.Equal(t, core.PinReplayStatusComplete, replay.Status)
+	assert.Equal(t, int64(20), replay.Current)
+	assert.Zero(t, replay.Undispatched)
+	assert.NotNil(t, replay.Completed)
+
+	replay, err = ag.getReplay(ag.ctx, replay.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, core.PinReplayStatusComplete, replay.Status)
+	ag.mdi.AssertNotCalled(t, "GetOffset", mock.Anything, core.OffsetTypeReplay, mock.Anything)
+}
+
+func TestReplayPinsGetLastPinFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ag.mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{Current: 100}, nil)
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := ag.startReplay(ag.ctx, &core.PinReplayInput{FromSequence: 10, ToSequence: 20})
+	assert.EqualError(t, err, "pop")
+	assert.Empty(t, ag.replays)
+}
+
+func TestReplayPinsPageBeforeLastPin(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ep, cancel := newTestEventPoller(ag.mdi, nil, nil)
+	defer cancel()
+	r := &pinReplay{ag: ag.aggregator, poller: ep, lastSequence: 20}
+
+	repoll, err := r.processPins([]core.LocallySequenced{&core.Pin{Sequence: 10, Dispatched: true}})
+	assert.NoError(t, err)
+	assert.True(t, repoll)
+	assert.Equal(t, int64(10), r.status.Current)
+	assert.Equal(t, core.PinReplayStatus(""), r.status.Status)
+}
+
+func TestReplayPinsCountUndispatchedRetry(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	count := int64(1)
+	ep, cancel := newTestEventPoller(ag.mdi, nil, nil)
+	defer cancel()
+	r := &pinReplay{ag: ag.aggregator, poller: ep}
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Count && strings.Contains(fi.String(), "dispatched == false")
+	})).Return(nil, nil, fmt.Errorf("pop")).Once()
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return(nil, &ffapi.FilterResult{TotalCount: &count}, nil).Once()
+
+	undispatched, err := r.countUndispatched()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), undispatched)
+}
+
+func TestReplayPinsCompleteCountFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ep, cancel := newTestEventPoller(ag.mdi, nil, nil)
+	cancel()
+	r := &pinReplay{ag: ag.aggregator, poller: ep, status: core.PinReplay{Status: core.PinReplayStatusRunning}}
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	repoll, err := r.processPins([]core.LocallySequenced{&core.Pin{Sequence: 10, Dispatched: true}})
+	assert.Regexp(t, "FF00154", err)
+	assert.False(t, repoll)
+	assert.Equal(t, core.PinReplayStatusRunning, r.status.Status)
+}
+
+func TestReplayPinsDeleteOffsetFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	count := int64(0)
+	pin := &core.Pin{Sequence: 10, Dispatched: true}
+	ag.mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{Current: 100}, nil)
+	ag.mdi.On("GetOffset", mock.Anything, core.OffsetTypeReplay, mock.Anything).Return(&core.Offset{Current: 9, RowID: 1}, nil)
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{pin}, nil, nil).Twice()
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return(nil, &ffapi.FilterResult{TotalCount: &count}, nil).Once()
+	deleted := make(chan struct{})
+	ag.mdi.On("DeleteOffset", mock.Anything, core.OffsetTypeReplay, mock.Anything).Return(fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		close(deleted)
+	})
+
+	_, err := ag.startReplay(ag.ctx, &core.PinReplayInput{FromSequence: 10, ToSequence: 20})
+	assert.NoError(t, err)
+	<-deleted
+}
+
+func TestReplayPinsProcessFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	r := &pinReplay{ag: ag.aggregator, lastSequence: 10}
+	ag.mdi.On("RunAsGroup", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := r.processPins([]core.LocallySequenced{&core.Pin{Sequence: 10}})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestReplayPinsGetPinsFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	r := &pinReplay{ag: ag.aggregator}
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := r.getPins(ag.ctx, nil, 0)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestReplayPinsInvalidRange(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	_, err := ag.startReplay(ag.ctx, &core.PinReplayInput{FromSequence: 20, ToSequence: 10})
+	assert.Regexp(t, "FF10495", err)
+	_, err = ag.startReplay(ag.ctx, &core.PinReplayInput{FromSequence: -1, ToSequence: 10})
+	assert.Regexp(t, "FF10495", err)
+}
+
+func TestReplayPinsAheadOfAggregator(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ag.mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{Current: 15}, nil)
+
+	_, err := ag.startReplay(ag.ctx, &core.PinReplayInput{FromSequence: 10, ToSequence: 20})
+	assert.Regexp(t, "FF10497.*15", err)
+}
+
+func TestReplayPinsNoAggregatorOffset(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ag.mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(nil, nil)
+
+	_, err := ag.startReplay(ag.ctx, &core.PinReplayInput{FromSequence: 0, ToSequence: 0})
+	assert.Regexp(t, "FF10497.*-1", err)
+}
+
+func TestReplayPinsGetOffsetFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ag.mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(nil, fmt.Errorf("pop"))
+
+	_, err := ag.startReplay(ag.ctx, &core.PinReplayInput{FromSequence: 10, ToSequence: 20})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestReplayPinsOverlap(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	running := &pinReplay{status: core.PinReplay{
+		ID:           fftypes.NewUUID(),
+		FromSequence: 15,
+		ToSequence:   30,
+		Status:       core.PinReplayStatusRunning,
+	}}
+	ag.replays[*running.status.ID] = running
+	ag.mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{Current: 100}, nil)
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{{Sequence: 20}}, nil, nil)
+
+	_, err := ag.startReplay(ag.ctx, &core.PinReplayInput{FromSequence: 10, ToSequence: 20})
+	assert.Regexp(t, "FF10496", err)
+}
+
+func TestReplayPinsStartFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.cancel()
+
+	ag.mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{Current: 100}, nil)
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{{Sequence: 20}}, nil, nil)
+	ag.mdi.On("GetOffset", mock.Anything, core.OffsetTypeReplay, mock.Anything).Return(nil, fmt.Errorf("pop")).Maybe()
+
+	_, err := ag.startReplay(ag.ctx, &core.PinReplayInput{FromSequence: 10, ToSequence: 20})
+	assert.Regexp(t, "FF00154", err)
+	assert.Empty(t, ag.replays)
+}
+
+func TestGetReplayNotFound(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	_, err := ag.getReplay(ag.ctx, fftypes.NewUUID())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestProcessWithBatchStateSerialized(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ag.processLock.Lock()
+	done := make(chan struct{})
+	go func() {
+		mockRunAsGroupPassthrough(ag.mdi)
+		_ = ag.processWithBatchState(func(ctx context.Context, state *batchState) error { return nil })
+		close(done)
+	}()
+	select {
+	case <-done:
+		assert.Fail(t, "processed while locked")
+	case <-time.After(10 * time.Millisecond):
+	}
+	ag.processLock.Unlock()
+	<-done
+}
+
+func TestDeleteOrphanedReplayOffsets(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ag.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*core.Offset{
+		{Type: core.OffsetTypeReplay, Name: "ns1:replay1"},
+		{Type: core.OffsetTypeReplay, Name: "ns1:replay2"},
+	}, nil, nil)
+	ag.mdi.On("DeleteOffset", mock.Anything, core.OffsetTypeReplay, "ns1:replay1").Return(fmt.Errorf("pop"))
+	ag.mdi.On("DeleteOffset", mock.Anything, core.OffsetTypeReplay, "ns1:replay2").Return(nil)
+
+	ag.deleteOrphanedReplayOffsets()
+}
+
+func TestDeleteOrphanedReplayOffsetsQueryFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ag.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	ag.deleteOrphanedReplayOffsets()
+	ag.mdi.AssertNotCalled(t, "DeleteOffset", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPruneFinishedReplays(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	running := &pinReplay{status: core.PinReplay{ID: fftypes.NewUUID(), Status: core.PinReplayStatusRunning}}
+	ag.replays[*running.status.ID] = running
+	base := time.Now()
+	var oldest *pinReplay
+	for i := 0; i <= maxFinishedReplays; i++ {
+		completed := fftypes.FFTime(base.Add(time.Duration(i) * time.Second))
+		r := &pinReplay{status: core.PinReplay{ID: fftypes.NewUUID(), Status: core.PinReplayStatusComplete, Completed: &completed}}
+		ag.replays[*r.status.ID] = r
+		if i == 0 {
+			oldest = r
+		}
+	}
+
+	ag.pruneFinishedReplays()
+	assert.Len(t, ag.replays, maxFinishedReplays+1)
+	assert.NotContains(t, ag.replays, *oldest.status.ID)
+	assert.Contains(t, ag.replays, *running.status.ID)
+}
@@ -39,6 +39,7 @@ const (
 	rewindMessage
 	rewindBlob
 	rewindDIDConfirmed
+	rewindData
 )
 
 type rewind struct {
@@ -104,7 +105,7 @@ func (rw *rewinder) rewindReceiveLoop() {
 
 			// Shoulder tap at this point, to get the event loop to pop and tell us
 			// we can move the queued rewinds to staged
-			rw.aggregator.eventPoller.shoulderTap()
+			rw.aggregator.eventPoller.ShoulderTap()
 		case <-rw.ctx.Done():
 			log.L(rw.ctx).Debugf("Rewind Receiver Loop stopping")
 			return
@@ -141,7 +142,7 @@ func (rw *rewinder) rewindProcessLoop() {
 			if rw.processStagedRewinds() {
 				// Shoulder tap at this point, to get the event loop to pop and retrieve
 				// the rewinds we have just derived batch IDs from
-				rw.aggregator.eventPoller.shoulderTap()
+				rw.aggregator.eventPoller.ShoulderTap()
 			}
 		case <-rw.loop2ShoulderTap:
 			if timerCtx == nil {
@@ -158,6 +159,7 @@ func (rw *rewinder) processStagedRewinds() bool {
 	var msgRewinds []*fftypes.UUID
 	var newBlobHashes []driver.Value
 	var identityRewinds []driver.Value
+	var dataRewinds []*fftypes.UUID
 
 	// Pop the current batch of rewinds out of the staging area
 	rw.mux.Lock()
@@ -171,6 +173,9 @@ func (rw *rewinder) processStagedRewinds() bool {
 			msgRewinds = append(msgRewinds, &rewind.uuid)
 		case rewindDIDConfirmed:
 			identityRewinds = append(identityRewinds, rewind.did)
+		case rewindData:
+			dataID := rewind.uuid
+			dataRewinds = append(dataRewinds, &dataID)
 		}
 	}
 	rw.stagedRewinds = rw.stagedRewinds[:0] // truncate
@@ -194,6 +199,11 @@ func (rw *rewinder) processStagedRewinds() bool {
 					return err
 				}
 			}
+			if len(dataRewinds) > 0 {
+				if err := rw.getRewindsForData(ctx, dataRewinds, batchIDs); err != nil {
+					return err
+				}
+			}
 			return nil
 		})
 	})
@@ -297,6 +307,18 @@ func (rw *rewinder) getRewindsForBlobs(ctx context.Context, newHashes []driver.V
 	return nil
 }
 
+func (rw *rewinder) getRewindsForData(ctx context.Context, dataIDs []*fftypes.UUID, batchIDs map[fftypes.UUID]bool) error {
+	msgBatchIDs, err := rw.database.GetBatchIDsForDataAttachments(ctx, rw.aggregator.namespace, dataIDs)
+	if err != nil {
+		return err
+	}
+	for _, batchID := range msgBatchIDs {
+		log.L(ctx).Debugf("Data %v caused rewind for batch %s", dataIDs, batchID)
+		batchIDs[*batchID] = true
+	}
+	return nil
+}
+
 func (rw *rewinder) getRewindsForDIDs(ctx context.Context, dids []driver.Value, batchIDs map[fftypes.UUID]bool) error {
 
 	// We need to find all pending messages, that are authored by this DID
@@ -38,12 +38,16 @@ func TestRewinderE2E(t *testing.T) {
 	batchID3 := fftypes.NewUUID()
 	batchID4 := fftypes.NewUUID()
 	batchID5 := fftypes.NewUUID()
+	batchID6 := fftypes.NewUUID()
+	externalDataID := fftypes.NewUUID()
 
 	mockRunAsGroupPassthrough(ag.mdi)
 	ag.mdi.On("GetDataRefs", mock.Anything, "ns1", mock.Anything).
 		Return(core.DataRefs{{ID: dataID}}, nil, nil)
 	ag.mdi.On("GetBatchIDsForDataAttachments", mock.Anything, "ns1", []*fftypes.UUID{dataID}).
 		Return([]*fftypes.UUID{batchID2}, nil)
+	ag.mdi.On("GetBatchIDsForDataAttachments", mock.Anything, "ns1", []*fftypes.UUID{externalDataID}).
+		Return([]*fftypes.UUID{batchID6}, nil)
 	ag.mdm.On("PeekMessageCache", mock.Anything, mock.Anything, data.CRORequireBatchID).Return(nil, nil)
 	ag.mdi.On("GetBatchIDsForMessages", mock.Anything, "ns1", mock.Anything).
 		Return([]*fftypes.UUID{batchID3}, nil).Once()
@@ -59,9 +63,10 @@ func TestRewinderE2E(t *testing.T) {
 	ag.queueMessageRewind(fftypes.NewUUID())
 	ag.queueDIDRewind("did:firefly:org/bob")
 	ag.queueBatchRewind(batchID5)
+	ag.queueDataRewind(externalDataID)
 
 	allReceived := make(map[fftypes.UUID]bool)
-	for len(allReceived) < 4 {
+	for len(allReceived) < 6 {
 		time.Sleep(1 * time.Millisecond)
 		batchIDs := ag.rewinder.popRewinds()
 		for _, bid := range batchIDs {
@@ -73,6 +78,7 @@ func TestRewinderE2E(t *testing.T) {
 	assert.True(t, allReceived[*batchID3])
 	assert.True(t, allReceived[*batchID4])
 	assert.True(t, allReceived[*batchID5])
+	assert.True(t, allReceived[*batchID6])
 
 	ag.cancel()
 	<-ag.rewinder.loop1Done
@@ -153,6 +159,23 @@ func TestProcessStagedRewindsErrorBlobDataRefs(t *testing.T) {
 
 }
 
+func TestProcessStagedRewindsErrorData(t *testing.T) {
+
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.cancel()
+
+	mockRunAsGroupPassthrough(ag.mdi)
+	ag.mdi.On("GetBatchIDsForDataAttachments", mock.Anything, "ns1", mock.Anything).
+		Return(nil, fmt.Errorf("pop"))
+
+	ag.rewinder.stagedRewinds = []*rewind{
+		{rewindType: rewindData},
+	}
+	ag.rewinder.processStagedRewinds()
+
+}
+
 func TestProcessStagedRewindsErrorDIDs(t *testing.T) {
 
 	ag := newTestAggregator()
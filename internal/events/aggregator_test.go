@@ -28,10 +28,13 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly-common/pkg/retry"
 	"github.com/hyperledger/firefly/internal/cache"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/definitions"
+	"github.com/hyperledger/firefly/internal/events/testmocks"
+	"github.com/hyperledger/firefly/internal/ratelimit"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
 	"github.com/hyperledger/firefly/mocks/cachemocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
@@ -40,6 +43,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
 	"github.com/hyperledger/firefly/mocks/metricsmocks"
 	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
+	"github.com/hyperledger/firefly/mocks/ratelimitmocks"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/sirupsen/logrus"
@@ -48,7 +52,7 @@ import (
 )
 
 type testAggregator struct {
-	aggregator
+	*aggregator
 
 	cancel func()
 	mdi    *databasemocks.Plugin
@@ -73,6 +77,8 @@ func (tag *testAggregator) cleanup(t *testing.T) {
 
 func newTestAggregatorCommon(metrics bool) *testAggregator {
 	coreconfig.Reset()
+	// Gap detection is covered by its own tests, so the pin sequences in other tests do not need to be contiguous
+	config.Set(coreconfig.EventAggregatorGapDetectionEnabled, false)
 	ctx, ctxCancel := context.WithCancel(context.Background())
 	logrus.SetLevel(logrus.DebugLevel)
 	mdi := &databasemocks.Plugin{}
@@ -86,10 +92,11 @@ func newTestAggregatorCommon(metrics bool) *testAggregator {
 	mbi := &blockchainmocks.Plugin{}
 	if metrics {
 		mmi.On("MessageConfirmed", mock.Anything, core.EventTypeMessageConfirmed).Return()
+		mmi.On("AggregatorEvent", "ns1", mock.Anything).Return().Maybe()
 	}
 	mmi.On("IsMetricsEnabled").Return(metrics).Maybe()
 	mbi.On("VerifierType").Return(core.VerifierTypeEthAddress)
-	ag, _ := newAggregator(ctx, "ns1", mdi, mbi, mpm, mdh, mim, mdm, newEventNotifier(ctx, "ut"), mmi, cmi)
+	ag, _ := newAggregator(ctx, "ns1", mdi, mbi, mpm, mdh, nil, mim, mdm, newEventNotifier(ctx, "ut"), mmi, cmi, nil)
 	cancel := func() {
 		ctxCancel()
 		if ag.batchCache != nil {
@@ -97,7 +104,7 @@ func newTestAggregatorCommon(metrics bool) *testAggregator {
 		}
 	}
 	return &testAggregator{
-		aggregator: *ag,
+		aggregator: ag,
 		cancel:     cancel,
 		mdi:        mdi,
 		mdm:        mdm,
@@ -173,6 +180,34 @@ func newTestManifest(mType core.MessageType, groupID *fftypes.Bytes32) (*core.Me
 	}
 }
 
+func TestNewAggregatorRetryJitter(t *testing.T) {
+	coreconfig.Reset()
+	config.Set(coreconfig.EventAggregatorRetryJitter, 0.5)
+	ctx := context.Background()
+	cmi := &cachemocks.Manager{}
+	cmi.On("GetCache", mock.Anything).Return(cache.NewUmanagedCache(ctx, 100, 5*time.Minute), nil)
+	mbi := &blockchainmocks.Plugin{}
+	mbi.On("VerifierType").Return(core.VerifierTypeEthAddress)
+	ag, err := newAggregator(ctx, "ns1", &databasemocks.Plugin{}, mbi, &privatemessagingmocks.Manager{}, &definitionsmocks.Handler{}, nil, &identitymanagermocks.Manager{}, &datamocks.Manager{}, newEventNotifier(ctx, "ut"), &metricsmocks.Manager{}, cmi, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, ag.eventPoller.(*eventPoller).conf.retryJitter)
+}
+
+func TestNewAggregatorResetOnSchemaMismatch(t *testing.T) {
+	coreconfig.Reset()
+	config.Set(coreconfig.EventAggregatorResetOnSchemaMismatch, true)
+	ctx := context.Background()
+	cmi := &cachemocks.Manager{}
+	cmi.On("GetCache", mock.Anything).Return(cache.NewUmanagedCache(ctx, 100, 5*time.Minute), nil)
+	mbi := &blockchainmocks.Plugin{}
+	mbi.On("VerifierType").Return(core.VerifierTypeEthAddress)
+	ag, err := newAggregator(ctx, "ns1", &databasemocks.Plugin{}, mbi, &privatemessagingmocks.Manager{}, &definitionsmocks.Handler{}, nil, &identitymanagermocks.Manager{}, &datamocks.Manager{}, newEventNotifier(ctx, "ut"), &metricsmocks.Manager{}, cmi, nil)
+	assert.NoError(t, err)
+	conf := ag.eventPoller.(*eventPoller).conf
+	assert.Equal(t, core.OffsetSchemaVersion, conf.schemaVersion)
+	assert.True(t, conf.resetOnSchemaMismatch)
+}
+
 func TestNewAggregator(t *testing.T) {
 	coreconfig.Reset()
 	ctx := context.Background()
@@ -188,7 +223,7 @@ func TestNewAggregator(t *testing.T) {
 	mbi := &blockchainmocks.Plugin{}
 	mbi.On("VerifierType").Return(core.VerifierTypeEthAddress)
 	ns := "ns1"
-	_, err := newAggregator(ctx, ns, mdi, mbi, mpm, mdh, mim, mdm, newEventNotifier(ctx, "ut"), mmi, cmi)
+	_, err := newAggregator(ctx, ns, mdi, mbi, mpm, mdh, nil, mim, mdm, newEventNotifier(ctx, "ut"), mmi, cmi, nil)
 	assert.NoError(t, err)
 	cmi.AssertCalled(t, "GetCache", cache.NewCacheConfig(
 		ctx,
@@ -213,7 +248,7 @@ func TestCacheInitFail(t *testing.T) {
 	mbi := &blockchainmocks.Plugin{}
 	mbi.On("VerifierType").Return(core.VerifierTypeEthAddress)
 	ns := "ns1"
-	_, err := newAggregator(ctx, ns, mdi, mbi, mpm, mdh, mim, mdm, newEventNotifier(ctx, "ut"), mmi, cmi)
+	_, err := newAggregator(ctx, ns, mdi, mbi, mpm, mdh, nil, mim, mdm, newEventNotifier(ctx, "ut"), mmi, cmi, nil)
 	assert.Equal(t, cacheInitError, err)
 }
 
@@ -221,7 +256,8 @@ func TestAggregationMaskedZeroNonceMatch(t *testing.T) {
 
 	ag := newTestAggregatorWithMetrics()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
+	bs := newBatchState(ag.aggregator)
 
 	// Generate some pin data
 	member1org := newTestOrg("org1")
@@ -350,9 +386,6 @@ func TestAggregationMaskedZeroNonceMatch(t *testing.T) {
 
 	assert.NotNil(t, bs.PendingConfirms[*msgID])
 
-	// Confirm the offset
-	assert.Equal(t, int64(10001), <-ag.eventPoller.offsetCommitted)
-
 }
 
 func TestAggregationMaskedNextSequenceMatch(t *testing.T) {
@@ -360,6 +393,7 @@ func TestAggregationMaskedNextSequenceMatch(t *testing.T) {
 
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
 
 	// Generate some pin data
 	member1org := newTestOrg("org1")
@@ -461,7 +495,7 @@ func TestAggregationMaskedNextSequenceMatch(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Confirm the offset
-	assert.Equal(t, int64(10001), <-ag.eventPoller.offsetCommitted)
+	assert.Equal(t, int64(10001), <-ag.eventPoller.(*eventPoller).offsetCommitted)
 
 }
 
@@ -469,7 +503,8 @@ func TestAggregationBroadcast(t *testing.T) {
 
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
+	bs := newBatchState(ag.aggregator)
 
 	// Generate some pin data
 	member1org := newTestOrg("org1")
@@ -541,16 +576,203 @@ func TestAggregationBroadcast(t *testing.T) {
 	err = bs.RunFinalize(ag.ctx)
 	assert.NoError(t, err)
 
-	// Confirm the offset
-	assert.Equal(t, int64(10001), <-ag.eventPoller.offsetCommitted)
+}
+
+func TestAggregatorOffsetAdvancesOnlyAfterSuccess(t *testing.T) {
+
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
+	ep := ag.eventPoller.(*eventPoller)
+	ep.pollingOffset = 10000
+
+	member1org := newTestOrg("org1")
+	member1key := "0x12345"
+	topic := "some-topic"
+	batchID := fftypes.NewUUID()
+	msgID := fftypes.NewUUID()
 
+	ag.mim.On("FindIdentityForVerifier", ag.ctx, []core.IdentityType{core.IdentityTypeOrg, core.IdentityTypeCustom}, &core.VerifierRef{
+		Type:  core.VerifierTypeEthAddress,
+		Value: member1key,
+	}).Return(member1org, nil)
+
+	rag := ag.mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+
+	batch := &core.Batch{
+		BatchHeader: core.BatchHeader{
+			ID: batchID,
+		},
+		Payload: core.BatchPayload{
+			Messages: []*core.Message{
+				{
+					Header: core.MessageHeader{
+						ID:        msgID,
+						Topics:    []string{topic},
+						Namespace: "ns1",
+						SignerRef: core.SignerRef{
+							Author: member1org.DID,
+							Key:    member1key,
+						},
+					},
+					Data: core.DataRefs{
+						{ID: fftypes.NewUUID()},
+					},
+				},
+			},
+		},
+	}
+	bp, _ := batch.Confirmed()
+
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", batchID).Return(bp, nil)
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
+	ag.mdm.On("GetMessageWithDataCached", ag.ctx, msgID, data.CRORequirePublicBlobRefs).Return(batch.Payload.Messages[0], core.DataArray{}, true, nil)
+	ag.mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, nil)
+	ag.mdm.On("UpdateMessageStateIfCached", ag.ctx, mock.Anything, core.MessageStateConfirmed, mock.Anything, "").Return()
+	ag.mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(e *core.Event) bool {
+		return *e.Reference == *msgID && e.Type == core.EventTypeMessageConfirmed
+	})).Return(nil)
+	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
+	// Fail the first attempt to update the message, when finalizing the batch
+	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(fmt.Errorf("pop")).Once()
+	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil).Once()
+
+	pins := []core.LocallySequenced{
+		&core.Pin{
+			Sequence:   10001,
+			Hash:       broadcastContext(topic),
+			Batch:      batchID,
+			Index:      0,
+			Signer:     member1key,
+			Dispatched: false,
+		},
+	}
+
+	// The failure must not move the offset
+	_, err := ag.processPinsEventsHandler(pins)
+	assert.EqualError(t, err, "pop")
+	assert.Equal(t, int64(10000), ep.getPollingOffset())
+	assert.Empty(t, ep.offsetCommitted)
+
+	// The retry succeeds, and commits the offset of the processed pin
+	_, err = ag.processPinsEventsHandler(pins)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10001), ep.getPollingOffset())
+	assert.Equal(t, int64(10001), <-ep.offsetCommitted)
+
+	ag.mdi.AssertNumberOfCalls(t, "UpdateMessages", 2)
+}
+
+func TestAggregatorRecoversFromCommitOffsetFailure(t *testing.T) {
+
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
+	ep := ag.eventPoller.(*eventPoller)
+	ep.pollingOffset = 10000
+	ep.offsetID = 12345
+	ep.conf.retry.InitialDelay = 1 * time.Millisecond
+
+	member1org := newTestOrg("org1")
+	member1key := "0x12345"
+	topic := "some-topic"
+	batchID := fftypes.NewUUID()
+	msgID := fftypes.NewUUID()
+
+	ag.mim.On("FindIdentityForVerifier", ag.ctx, []core.IdentityType{core.IdentityTypeOrg, core.IdentityTypeCustom}, &core.VerifierRef{
+		Type:  core.VerifierTypeEthAddress,
+		Value: member1key,
+	}).Return(member1org, nil)
+
+	rag := ag.mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+
+	batch := &core.Batch{
+		BatchHeader: core.BatchHeader{
+			ID: batchID,
+		},
+		Payload: core.BatchPayload{
+			Messages: []*core.Message{
+				{
+					Header: core.MessageHeader{
+						ID:        msgID,
+						Topics:    []string{topic},
+						Namespace: "ns1",
+						SignerRef: core.SignerRef{
+							Author: member1org.DID,
+							Key:    member1key,
+						},
+					},
+					Data: core.DataRefs{
+						{ID: fftypes.NewUUID()},
+					},
+				},
+			},
+		},
+	}
+	bp, _ := batch.Confirmed()
+
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", batchID).Return(bp, nil)
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
+	ag.mdm.On("GetMessageWithDataCached", ag.ctx, msgID, data.CRORequirePublicBlobRefs).Return(batch.Payload.Messages[0], core.DataArray{}, true, nil)
+	ag.mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, nil)
+	ag.mdm.On("UpdateMessageStateIfCached", ag.ctx, mock.Anything, core.MessageStateConfirmed, mock.Anything, "").Return()
+	ag.mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(e *core.Event) bool {
+		return *e.Reference == *msgID && e.Type == core.EventTypeMessageConfirmed
+	})).Return(nil)
+	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
+	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
+
+	// Persisting the offset fails once, then succeeds on the retry
+	committed := make(chan int64, 1)
+	ag.mdi.On("UpdateOffset", mock.Anything, int64(12345), mock.Anything).Return(fmt.Errorf("pop")).Once()
+	ag.mdi.On("UpdateOffset", mock.Anything, int64(12345), mock.Anything).Return(nil).Once().Run(func(args mock.Arguments) {
+		info, _ := args[2].(ffapi.Update).Finalize()
+		v, _ := info.SetOperations[0].Value.Value()
+		committed <- v.(int64)
+	})
+
+	commitLoopDone := make(chan struct{})
+	go func() {
+		defer close(commitLoopDone)
+		ep.offsetCommitLoop()
+	}()
+
+	pins := []core.LocallySequenced{
+		&core.Pin{
+			Sequence:   10001,
+			Hash:       broadcastContext(topic),
+			Batch:      batchID,
+			Index:      0,
+			Signer:     member1key,
+			Dispatched: false,
+		},
+	}
+	_, err := ag.processPinsEventsHandler(pins)
+	assert.NoError(t, err)
+
+	assert.Equal(t, int64(10001), <-committed)
+	close(ep.offsetCommitted)
+	<-commitLoopDone
+
+	// The failed offset write is retried in the background, without the batch itself being
+	// processed again - so the message is only updated once
+	assert.Equal(t, int64(10001), ep.getPollingOffset())
+	ag.mdi.AssertNumberOfCalls(t, "UpdateOffset", 2)
+	ag.mdi.AssertNumberOfCalls(t, "UpdateMessages", 1)
 }
 
 func TestAggregationMigratedBroadcast(t *testing.T) {
 
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
+	bs := newBatchState(ag.aggregator)
 
 	// Generate some pin data
 	member1org := newTestOrg("org1")
@@ -628,16 +850,13 @@ func TestAggregationMigratedBroadcast(t *testing.T) {
 	err = bs.RunFinalize(ag.ctx)
 	assert.NoError(t, err)
 
-	// Confirm the offset
-	assert.Equal(t, int64(10001), <-ag.eventPoller.offsetCommitted)
-
 }
 
 func TestAggregationMigratedBroadcastNilMessageID(t *testing.T) {
 
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 
 	// Generate some pin data
 	member1key := "0x12345"
@@ -682,136 +901,766 @@ func TestAggregationMigratedBroadcastNilMessageID(t *testing.T) {
 	err = bs.RunFinalize(ag.ctx)
 	assert.NoError(t, err)
 
-	// Confirm the offset
-	assert.Equal(t, int64(10001), <-ag.eventPoller.offsetCommitted)
-
 }
 
 func TestAggregationMigratedBroadcastInvalid(t *testing.T) {
 
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
+
+	// Generate some pin data
+	member1key := "0x12345"
+	topic := "some-topic"
+	batchID := fftypes.NewUUID()
+	contextUnmasked := broadcastContext(topic)
+
+	batch := &core.Batch{
+		BatchHeader: core.BatchHeader{
+			ID: batchID,
+		},
+		Payload: core.BatchPayload{
+			Messages: []*core.Message{{
+				Header: core.MessageHeader{
+					Topics: fftypes.FFStringArray{"topic1"},
+				},
+			}},
+		},
+	}
+	bp := &core.BatchPersisted{
+		TX:          batch.Payload.TX,
+		BatchHeader: batch.BatchHeader,
+		Manifest:    fftypes.JSONAnyPtr("{}"),
+	}
+
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", batchID).Return(bp, nil)
+
+	err := ag.processPins(ag.ctx, []*core.Pin{
+		{
+			Sequence:   10001,
+			Hash:       contextUnmasked,
+			Batch:      batchID,
+			Index:      0,
+			Signer:     member1key,
+			Dispatched: false,
+		},
+	}, bs)
+	assert.NoError(t, err)
+
+	err = bs.RunFinalize(ag.ctx)
+	assert.NoError(t, err)
+
+}
+
+func TestAggregatorGracefulShutdownDuringRetry(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*core.Offset{}, nil, nil)
+	ep := ag.eventPoller.(*eventPoller)
+	ep.conf.retry.InitialDelay = 1 * time.Millisecond
+	ep.conf.retry.MaximumDelay = 1 * time.Minute
+	ep.conf.retry.Factor = 100000
+
+	ag.mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{
+		Type:          core.OffsetTypeAggregator,
+		Name:          aggregatorOffsetName,
+		Current:       12345,
+		SchemaVersion: core.OffsetSchemaVersion,
+		RowID:         333333,
+	}, nil)
+	secondFailure := make(chan struct{})
+	attempts := 0
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		attempts++
+		if attempts == 2 {
+			close(secondFailure)
+		}
+	})
+
+	err := ag.start()
+	assert.NoError(t, err)
+
+	// The second failure is followed by a wait of over a minute, which must be interrupted
+	<-secondFailure
+	ag.cancel()
+	select {
+	case <-ep.closed:
+	case <-time.After(100 * time.Millisecond):
+		assert.Fail(t, "aggregator did not exit while waiting to retry")
+	}
+}
+
+func TestShutdownOnCancel(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*core.Offset{}, nil, nil)
+	ag.mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{
+		Type:          core.OffsetTypeAggregator,
+		Name:          aggregatorOffsetName,
+		Current:       12345,
+		SchemaVersion: core.OffsetSchemaVersion,
+		RowID:         333333,
+	}, nil)
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
+	ag.start()
+	assert.Equal(t, int64(12345), ag.eventPoller.(*eventPoller).pollingOffset)
+	ag.eventPoller.(*eventPoller).eventNotifier.newEvents <- 12345
+	ag.cancel()
+	<-ag.eventPoller.(*eventPoller).closed
+	<-ag.rewinder.loop1Done
+	<-ag.rewinder.loop2Done
+}
+
+func TestProcessPinsDBGroupFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	rag := ag.mdi.On("RunAsGroup", ag.ctx, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{
+			a[1].(func(context.Context) error)(a[0].(context.Context)),
+		}
+	}
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := ag.processPinsEventsHandler([]core.LocallySequenced{
+		&core.Pin{
+			Batch: fftypes.NewUUID(),
+		},
+	})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestGetPins(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ag.mdi.On("GetPins", ag.ctx, "ns1", mock.Anything).Return([]*core.Pin{
+		{Sequence: 12345},
+	}, nil, nil)
+
+	lc, err := ag.getPins(ag.ctx, database.EventQueryFactory.NewFilter(ag.ctx).Gte("sequence", 12345), 12345)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12345), lc[0].LocalSequence())
+}
+
+func TestProcessPinsMissingBatch(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	bs := newBatchState(ag.aggregator)
+
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", mock.Anything).Return(nil, nil)
+
+	err := ag.processPins(ag.ctx, []*core.Pin{
+		{Sequence: 12345, Batch: fftypes.NewUUID()},
+	}, bs)
+	assert.NoError(t, err)
+
+}
+
+func TestProcessPinsEventsHandlerCommitOffsetFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	mep := &testmocks.MockEventPoller{}
+	ag.eventPoller = mep
+
+	rag := ag.mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", mock.Anything).Return(nil, nil)
+	mep.On("CommitOffset", ag.ctx, int64(12345)).Return(fmt.Errorf("pop"))
+
+	_, err := ag.processPinsEventsHandler([]core.LocallySequenced{
+		&core.Pin{Sequence: 12345, Batch: fftypes.NewUUID()},
+	})
+	assert.EqualError(t, err, "pop")
+
+	mep.AssertExpectations(t)
+}
+
+func TestProcessPinsEventsHandlerMetrics(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(true)
+	ag.metrics = mmi
+	mep := &testmocks.MockEventPoller{}
+	ag.eventPoller = mep
+	ag.pinNotifier.latestSequence = 12350
+
+	rag := ag.mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", mock.Anything).Return(nil, nil)
+	mep.On("CommitOffset", ag.ctx, int64(12345)).Return(nil)
+	mmi.On("AggregatorBatchProcessed", "ns1", mock.Anything).Return()
+	mmi.On("AggregatorBlockedContexts", "ns1", 0).Return()
+	mmi.On("AggregatorLag", "ns1", int64(5)).Return()
+
+	_, err := ag.processPinsEventsHandler([]core.LocallySequenced{
+		&core.Pin{Sequence: 12345, Batch: fftypes.NewUUID()},
+	})
+	assert.NoError(t, err)
+
+	mep.AssertExpectations(t)
+	mmi.AssertExpectations(t)
+}
+
+func TestProcessPinsEventsHandlerMetricsNoLag(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(true)
+	ag.metrics = mmi
+	mep := &testmocks.MockEventPoller{}
+	ag.eventPoller = mep
+
+	rag := ag.mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", mock.Anything).Return(nil, nil)
+	mep.On("CommitOffset", ag.ctx, int64(12345)).Return(nil)
+	mmi.On("AggregatorBatchProcessed", "ns1", mock.Anything).Return()
+	mmi.On("AggregatorBlockedContexts", "ns1", 0).Return()
+	mmi.On("AggregatorLag", "ns1", int64(0)).Return()
+
+	_, err := ag.processPinsEventsHandler([]core.LocallySequenced{
+		&core.Pin{Sequence: 12345, Batch: fftypes.NewUUID()},
+	})
+	assert.NoError(t, err)
+
+	mep.AssertExpectations(t)
+	mmi.AssertExpectations(t)
+}
+
+func TestProcessPinsEventsHandlerRetryMetrics(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(true)
+	ag.metrics = mmi
+
+	rag := ag.mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", mock.Anything).Return(nil, fmt.Errorf("pop"))
+	mmi.On("AggregatorRetry", "ns1").Return()
+
+	_, err := ag.processPinsEventsHandler([]core.LocallySequenced{
+		&core.Pin{Sequence: 12345, Batch: fftypes.NewUUID()},
+	})
+	assert.EqualError(t, err, "pop")
+	mmi.AssertExpectations(t)
+}
+
+func TestProcessPinsEventsHandlerRateLimited(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	mep := &testmocks.MockEventPoller{}
+	ag.eventPoller = mep
+	mrl := &ratelimitmocks.RateLimiter{}
+	ag.rateLimiter = mrl
+
+	rag := ag.mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", mock.Anything).Return(nil, nil)
+	mrl.On("Take", 3).Return(2)
+	// The offset only moves past the pins within the limit
+	mep.On("CommitOffset", ag.ctx, int64(101)).Return(nil)
+
+	repoll, err := ag.processPinsEventsHandler([]core.LocallySequenced{
+		&core.Pin{Sequence: 100, Batch: fftypes.NewUUID()},
+		&core.Pin{Sequence: 101, Batch: fftypes.NewUUID()},
+		&core.Pin{Sequence: 102, Batch: fftypes.NewUUID()},
+	})
+	assert.NoError(t, err)
+	assert.True(t, repoll)
+
+	mep.AssertExpectations(t)
+	mrl.AssertExpectations(t)
+}
+
+func TestProcessPinsEventsHandlerWithinRateLimit(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	mep := &testmocks.MockEventPoller{}
+	ag.eventPoller = mep
+	mrl := &ratelimitmocks.RateLimiter{}
+	ag.rateLimiter = mrl
+
+	rag := ag.mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", mock.Anything).Return(nil, nil)
+	mrl.On("Take", 1).Return(1)
+	mep.On("CommitOffset", ag.ctx, int64(100)).Return(nil)
+
+	repoll, err := ag.processPinsEventsHandler([]core.LocallySequenced{
+		&core.Pin{Sequence: 100, Batch: fftypes.NewUUID()},
+	})
+	assert.NoError(t, err)
+	assert.False(t, repoll)
+
+	mep.AssertExpectations(t)
+	mrl.AssertExpectations(t)
+}
+
+func TestProcessPinsEventsHandlerRateLimitExhausted(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	mrl := &ratelimitmocks.RateLimiter{}
+	ag.rateLimiter = mrl
+
+	mrl.On("Take", 1).Return(0)
+	mrl.On("Delay").Return(1 * time.Millisecond)
+
+	repoll, err := ag.processPinsEventsHandler([]core.LocallySequenced{
+		&core.Pin{Sequence: 100, Batch: fftypes.NewUUID()},
+	})
+	assert.NoError(t, err)
+	assert.True(t, repoll)
+
+	mrl.AssertExpectations(t)
+}
+
+func TestProcessPinsEventsHandlerRateLimitExhaustedClosed(t *testing.T) {
+	ag := newTestAggregator()
+	ag.cleanup(t)
+	mrl := &ratelimitmocks.RateLimiter{}
+	ag.rateLimiter = mrl
+
+	mrl.On("Take", 1).Return(0)
+	mrl.On("Delay").Return(1 * time.Hour)
+
+	repoll, err := ag.processPinsEventsHandler([]core.LocallySequenced{
+		&core.Pin{Sequence: 100, Batch: fftypes.NewUUID()},
+	})
+	assert.NoError(t, err)
+	assert.True(t, repoll)
+
+	mrl.AssertExpectations(t)
+}
+
+func TestAggregatorRateLimitFairnessAcrossNamespaces(t *testing.T) {
+	ag1 := newTestAggregator()
+	defer ag1.cleanup(t)
+	ag1.rateLimiter = ratelimit.NewTokenBucket(1, 0.001)
+	ag2 := newTestAggregator()
+	defer ag2.cleanup(t)
+	ag2.rateLimiter = ratelimit.NewTokenBucket(10, 0.001)
+
+	pins := []core.LocallySequenced{
+		&core.Pin{Sequence: 100, Batch: fftypes.NewUUID()},
+		&core.Pin{Sequence: 101, Batch: fftypes.NewUUID()},
+		&core.Pin{Sequence: 102, Batch: fftypes.NewUUID()},
+	}
+
+	// Exhausting the limiter of one namespace does not limit the other
+	limited, repoll := ag1.applyRateLimit(pins)
+	assert.Len(t, limited, 1)
+	assert.True(t, repoll)
+	limited, repoll = ag2.applyRateLimit(pins)
+	assert.Len(t, limited, 3)
+	assert.False(t, repoll)
+	limited, repoll = ag2.applyRateLimit(pins)
+	assert.Len(t, limited, 3)
+	assert.False(t, repoll)
+}
+
+func TestCheckSequenceGapNone(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.gapDetection = true
+	ag.pollOffset = 99
+	stallStart := time.Now()
+	ag.gapStallStart = &stallStart
+
+	ag.mdi.On("CountPinsInSequenceRange", ag.ctx, int64(99), int64(102)).Return(int64(3), nil)
+
+	stall, err := ag.checkSequenceGap([]core.LocallySequenced{
+		&core.Pin{Sequence: 100},
+		&core.Pin{Sequence: 102},
+	})
+	assert.NoError(t, err)
+	assert.False(t, stall)
+	assert.Nil(t, ag.gapStallStart)
+}
+
+func TestCheckSequenceGapWarnOnly(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.gapDetection = true
+	ag.pollOffset = -1
+
+	ag.mdi.On("CountPinsInSequenceRange", ag.ctx, int64(0), int64(3)).Return(int64(2), nil)
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("AggregatorSequenceGap", "ns1").Return().Once()
+	ag.metrics = mmi
+
+	pins := []core.LocallySequenced{
+		&core.Pin{Sequence: 1},
+		&core.Pin{Sequence: 3},
+	}
+	stall, err := ag.checkSequenceGap(pins)
+	assert.NoError(t, err)
+	assert.False(t, stall)
+	assert.Nil(t, ag.gapStallStart)
+	assert.Equal(t, int64(3), ag.gapCheckedTo)
+
+	// A rewind re-polls the same range, but the gap is not counted or reported again
+	stall, err = ag.checkSequenceGap(pins)
+	assert.NoError(t, err)
+	assert.False(t, stall)
+
+	mmi.AssertExpectations(t)
+	ag.mdi.AssertNumberOfCalls(t, "CountPinsInSequenceRange", 1)
+}
+
+func TestCheckSequenceGapAfterRewind(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.gapDetection = true
+	ag.pollOffset = 10
+	ag.gapCheckedTo = 100
+
+	// Only the part of the page beyond what was already checked is counted
+	ag.mdi.On("CountPinsInSequenceRange", ag.ctx, int64(100), int64(102)).Return(int64(2), nil)
+
+	stall, err := ag.checkSequenceGap([]core.LocallySequenced{
+		&core.Pin{Sequence: 11},
+		&core.Pin{Sequence: 101},
+		&core.Pin{Sequence: 102},
+	})
+	assert.NoError(t, err)
+	assert.False(t, stall)
+	assert.Equal(t, int64(102), ag.gapCheckedTo)
+
+	ag.mdi.AssertExpectations(t)
+}
+
+func TestCheckSequenceGapStall(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.gapDetection = true
+	ag.gapStallTimeout = 1 * time.Hour
+	ag.retry = &retry.Retry{InitialDelay: 1 * time.Millisecond}
+	ag.pollOffset = 99
+	pins := []core.LocallySequenced{
+		&core.Pin{Sequence: 100},
+		&core.Pin{Sequence: 102},
+	}
+
+	ag.mdi.On("CountPinsInSequenceRange", ag.ctx, int64(99), int64(102)).Return(int64(2), nil)
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("AggregatorSequenceGap", "ns1").Return().Once()
+	ag.metrics = mmi
+
+	// The gap is only reported once, while we stall waiting for it to fill
+	stall, err := ag.checkSequenceGap(pins)
+	assert.NoError(t, err)
+	assert.True(t, stall)
+	assert.NotNil(t, ag.gapStallStart)
+	stall, err = ag.checkSequenceGap(pins)
+	assert.NoError(t, err)
+	assert.True(t, stall)
+
+	// Once the stall timeout passes we continue past the gap
+	stallStart := time.Now().Add(-2 * time.Hour)
+	ag.gapStallStart = &stallStart
+	stall, err = ag.checkSequenceGap(pins)
+	assert.NoError(t, err)
+	assert.False(t, stall)
+	assert.Nil(t, ag.gapStallStart)
+
+	mmi.AssertExpectations(t)
+}
+
+func TestCheckSequenceGapStallClosed(t *testing.T) {
+	ag := newTestAggregator()
+	ag.cleanup(t)
+	ag.gapDetection = true
+	ag.gapStallTimeout = 1 * time.Hour
+	ag.pollOffset = 99
+
+	ag.mdi.On("CountPinsInSequenceRange", ag.ctx, int64(99), int64(102)).Return(int64(2), nil)
+
+	stall, err := ag.checkSequenceGap([]core.LocallySequenced{
+		&core.Pin{Sequence: 102},
+	})
+	assert.NoError(t, err)
+	assert.True(t, stall)
+}
+
+func TestProcessPinsEventsHandlerGapStall(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.gapDetection = true
+	ag.gapStallTimeout = 1 * time.Hour
+	ag.retry = &retry.Retry{InitialDelay: 1 * time.Millisecond}
+	ag.pollOffset = 99
+
+	ag.mdi.On("CountPinsInSequenceRange", ag.ctx, int64(99), int64(101)).Return(int64(1), nil)
+
+	repoll, err := ag.processPinsEventsHandler([]core.LocallySequenced{
+		&core.Pin{Sequence: 101, Batch: fftypes.NewUUID()},
+	})
+	assert.NoError(t, err)
+	assert.True(t, repoll)
+}
+
+func TestProcessPinsEventsHandlerGapCountFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.gapDetection = true
+
+	ag.mdi.On("CountPinsInSequenceRange", ag.ctx, int64(0), int64(101)).Return(int64(0), fmt.Errorf("pop"))
+
+	_, err := ag.processPinsEventsHandler([]core.LocallySequenced{
+		&core.Pin{Sequence: 101, Batch: fftypes.NewUUID()},
+	})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestProcessPinsRetriesExhaustedRecordsDeadEvents(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	mep := &testmocks.MockEventPoller{}
+	ag.eventPoller = mep
+
+	rag := ag.mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	badBatch := fftypes.NewUUID()
+	goodBatch := fftypes.NewUUID()
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", badBatch).Return(nil, fmt.Errorf("pop"))
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", goodBatch).Return(nil, nil)
+	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil).Twice()
+	ag.mdi.On("UpsertDeadEvent", ag.ctx, mock.MatchedBy(func(de *core.DeadEvent) bool {
+		return de.Batch.Equals(badBatch) && de.Attempts == 5 && de.Error == "pop" && de.Namespace == "ns1"
+	})).Return(nil).Twice()
+	ag.mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(e *core.Event) bool {
+		return e.Type == core.EventTypeDeadEvent && e.Reference != nil
+	})).Return(nil).Twice()
+	mep.On("CommitOffset", ag.ctx, int64(3)).Return(nil)
+
+	repoll, err := ag.processPinsRetriesExhausted([]core.LocallySequenced{
+		&core.Pin{Sequence: 1, Batch: badBatch, Index: 0},
+		&core.Pin{Sequence: 2, Batch: badBatch, Index: 1},
+		&core.Pin{Sequence: 3, Batch: goodBatch},
+	}, 5, fmt.Errorf("pop"))
+	assert.NoError(t, err)
+	assert.False(t, repoll)
+
+	mep.AssertExpectations(t)
+	ag.mdi.AssertNumberOfCalls(t, "GetBatchByID", 2)
+}
+
+func TestProcessPinsRetriesExhaustedRecordFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	mep := &testmocks.MockEventPoller{}
+	ag.eventPoller = mep
+
+	rag := ag.mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", mock.Anything).Return(nil, fmt.Errorf("pop"))
+	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
+	ag.mdi.On("UpsertDeadEvent", ag.ctx, mock.Anything).Return(fmt.Errorf("bang"))
+
+	_, err := ag.processPinsRetriesExhausted([]core.LocallySequenced{
+		&core.Pin{Sequence: 1, Batch: fftypes.NewUUID()},
+	}, 5, fmt.Errorf("pop"))
+	assert.EqualError(t, err, "bang")
+
+	mep.AssertExpectations(t)
+}
+
+func TestRecordDeadEventInsertEventFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	rag := ag.mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
+	ag.mdi.On("UpsertDeadEvent", ag.ctx, mock.Anything).Return(nil)
+	ag.mdi.On("InsertEvent", ag.ctx, mock.Anything).Return(fmt.Errorf("bang"))
+
+	err := ag.recordDeadEvent(&core.Pin{Sequence: 1, Batch: fftypes.NewUUID()}, 1, fmt.Errorf("pop"))
+	assert.EqualError(t, err, "bang")
+}
+
+func TestRecordDeadEventUpdatePinsFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	rag := ag.mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(fmt.Errorf("bang"))
+
+	err := ag.recordDeadEvent(&core.Pin{Sequence: 1, Batch: fftypes.NewUUID()}, 1, fmt.Errorf("pop"))
+	assert.EqualError(t, err, "bang")
+}
+
+func TestRecordDeadEventMetrics(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	mmi := &metricsmocks.Manager{}
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("AggregatorEvent", "ns1", core.EventTypeDeadEvent).Return()
+	ag.metrics = mmi
+
+	rag := ag.mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
+	ag.mdi.On("UpsertDeadEvent", ag.ctx, mock.Anything).Return(nil)
+	ag.mdi.On("InsertEvent", ag.ctx, mock.Anything).Return(nil)
+
+	err := ag.recordDeadEvent(&core.Pin{Sequence: 1, Batch: fftypes.NewUUID()}, 1, fmt.Errorf("pop"))
+	assert.NoError(t, err)
+
+	mmi.AssertExpectations(t)
+}
+
+func TestProcessPinsRetriesExhaustedUnblocksContext(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	mep := &testmocks.MockEventPoller{}
+	ag.eventPoller = mep
+	ag.mdm.On("CheckDataAvailable", mock.Anything, mock.Anything).Return(true, nil)
 
-	// Generate some pin data
+	rag := ag.mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+
+	member1org := newTestOrg("org1")
 	member1key := "0x12345"
 	topic := "some-topic"
-	batchID := fftypes.NewUUID()
 	contextUnmasked := broadcastContext(topic)
+	badBatch := fftypes.NewUUID()
+	goodBatch := fftypes.NewUUID()
+	msgID := fftypes.NewUUID()
+	deadPin := &core.Pin{Sequence: 1, Hash: contextUnmasked, Batch: badBatch, Signer: member1key}
+	laterPin := &core.Pin{Sequence: 2, Hash: contextUnmasked, Batch: goodBatch, Signer: member1key}
+
+	ag.mim.On("FindIdentityForVerifier", ag.ctx, []core.IdentityType{core.IdentityTypeOrg, core.IdentityTypeCustom}, &core.VerifierRef{
+		Type:  core.VerifierTypeEthAddress,
+		Value: member1key,
+	}).Return(member1org, nil)
 
 	batch := &core.Batch{
-		BatchHeader: core.BatchHeader{
-			ID: batchID,
-		},
+		BatchHeader: core.BatchHeader{ID: goodBatch},
 		Payload: core.BatchPayload{
 			Messages: []*core.Message{{
 				Header: core.MessageHeader{
-					Topics: fftypes.FFStringArray{"topic1"},
+					ID:        msgID,
+					Topics:    []string{topic},
+					Namespace: "ns1",
+					SignerRef: core.SignerRef{Author: member1org.DID, Key: member1key},
 				},
+				Data: core.DataRefs{{ID: fftypes.NewUUID()}},
 			}},
 		},
 	}
-	bp := &core.BatchPersisted{
-		TX:          batch.Payload.TX,
-		BatchHeader: batch.BatchHeader,
-		Manifest:    fftypes.JSONAnyPtr("{}"),
-	}
-
-	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", batchID).Return(bp, nil)
+	bp, _ := batch.Confirmed()
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", badBatch).Return(nil, fmt.Errorf("pop"))
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", goodBatch).Return(bp, nil)
+
+	// The dead pin blocks the context until it is marked dispatched
+	deadPinDispatched := false
+	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.String() == "sequence == 1"
+	}), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		deadPinDispatched = true
+	}).Once()
+	ag.mdi.On("GetPins", ag.ctx, "ns1", mock.Anything).Return(func(ctx context.Context, ns string, f ffapi.Filter) []*core.Pin {
+		if deadPinDispatched {
+			return []*core.Pin{}
+		}
+		return []*core.Pin{deadPin}
+	}, nil, nil)
+	ag.mdi.On("UpsertDeadEvent", ag.ctx, mock.Anything).Return(nil)
+	ag.mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(e *core.Event) bool {
+		return e.Type == core.EventTypeDeadEvent
+	})).Return(nil)
 
-	err := ag.processPins(ag.ctx, []*core.Pin{
-		{
-			Sequence:   10001,
-			Hash:       contextUnmasked,
-			Batch:      batchID,
-			Index:      0,
-			Signer:     member1key,
-			Dispatched: false,
-		},
-	}, bs)
-	assert.NoError(t, err)
+	// The later message on the context is dispatched
+	ag.mdm.On("GetMessageWithDataCached", ag.ctx, msgID, data.CRORequirePublicBlobRefs).Return(batch.Payload.Messages[0], core.DataArray{}, true, nil)
+	ag.mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, nil)
+	ag.mdm.On("UpdateMessageStateIfCached", ag.ctx, msgID, core.MessageStateConfirmed, mock.Anything, "").Return()
+	ag.mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(e *core.Event) bool {
+		return e.Type == core.EventTypeMessageConfirmed && e.Reference.Equals(msgID)
+	})).Return(nil).Once()
+	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil).Once()
+	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
+	mep.On("CommitOffset", ag.ctx, int64(2)).Return(nil)
 
-	err = bs.RunFinalize(ag.ctx)
+	repoll, err := ag.processPinsRetriesExhausted([]core.LocallySequenced{deadPin, laterPin}, 5, fmt.Errorf("pop"))
 	assert.NoError(t, err)
+	assert.False(t, repoll)
+	assert.True(t, deadPinDispatched)
 
-	// Confirm the offset
-	assert.Equal(t, int64(10001), <-ag.eventPoller.offsetCommitted)
-
+	mep.AssertExpectations(t)
+	ag.mdi.AssertExpectations(t)
+	ag.mdm.AssertExpectations(t)
 }
 
-func TestShutdownOnCancel(t *testing.T) {
-	ag := newTestAggregator()
-	defer ag.cleanup(t)
-	ag.mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{
-		Type:    core.OffsetTypeAggregator,
-		Name:    aggregatorOffsetName,
-		Current: 12345,
-		RowID:   333333,
-	}, nil)
-	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
-	ag.start()
-	assert.Equal(t, int64(12345), ag.eventPoller.pollingOffset)
-	ag.eventPoller.eventNotifier.newEvents <- 12345
-	ag.cancel()
-	<-ag.eventPoller.closed
-	<-ag.rewinder.loop1Done
-	<-ag.rewinder.loop2Done
-}
-
-func TestProcessPinsDBGroupFail(t *testing.T) {
+func TestAggregatorStartStop(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
+	ag.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*core.Offset{}, nil, nil)
+	mep := &testmocks.MockEventPoller{}
+	ag.eventPoller = mep
 
-	rag := ag.mdi.On("RunAsGroup", ag.ctx, mock.Anything)
-	rag.RunFn = func(a mock.Arguments) {
-		rag.ReturnArguments = mock.Arguments{
-			a[1].(func(context.Context) error)(a[0].(context.Context)),
-		}
-	}
-	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", mock.Anything).Return(nil, fmt.Errorf("pop"))
-
-	_, err := ag.processPinsEventsHandler([]core.LocallySequenced{
-		&core.Pin{
-			Batch: fftypes.NewUUID(),
-		},
-	})
-	assert.Regexp(t, "pop", err)
-}
-
-func TestGetPins(t *testing.T) {
-	ag := newTestAggregator()
-	defer ag.cleanup(t)
+	mep.On("Start").Return(fmt.Errorf("pop"))
+	mep.On("Stop").Return()
 
-	ag.mdi.On("GetPins", ag.ctx, "ns1", mock.Anything).Return([]*core.Pin{
-		{Sequence: 12345},
-	}, nil, nil)
+	err := ag.start()
+	assert.EqualError(t, err, "pop")
+	ag.stop()
 
-	lc, err := ag.getPins(ag.ctx, database.EventQueryFactory.NewFilter(ag.ctx).Gte("sequence", 12345), 12345)
-	assert.NoError(t, err)
-	assert.Equal(t, int64(12345), lc[0].LocalSequence())
+	mep.AssertExpectations(t)
 }
 
-func TestProcessPinsMissingBatch(t *testing.T) {
+func TestAggregatorPauseResume(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	mep := &testmocks.MockEventPoller{}
+	ag.eventPoller = mep
 
-	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", mock.Anything).Return(nil, nil)
-
-	err := ag.processPins(ag.ctx, []*core.Pin{
-		{Sequence: 12345, Batch: fftypes.NewUUID()},
-	}, bs)
-	assert.NoError(t, err)
+	mep.On("Pause", ag.ctx).Return(fmt.Errorf("pop"))
+	mep.On("Resume").Return()
 
-	// Confirm the offset
-	assert.Equal(t, int64(12345), <-ag.eventPoller.offsetCommitted)
+	err := ag.pause(ag.ctx)
+	assert.EqualError(t, err, "pop")
+	ag.resume()
 
+	mep.AssertExpectations(t)
 }
 
 func TestProcessPinsMissingNoMsg(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 
 	batch := &core.Batch{
 		BatchHeader: core.BatchHeader{
@@ -832,15 +1681,12 @@ func TestProcessPinsMissingNoMsg(t *testing.T) {
 	}, bs)
 	assert.NoError(t, err)
 
-	// Confirm the offset
-	assert.Equal(t, int64(12345), <-ag.eventPoller.offsetCommitted)
-
 }
 
 func TestProcessPinsBadMsgHeader(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 
 	batch := &core.Batch{
 		BatchHeader: core.BatchHeader{
@@ -864,15 +1710,12 @@ func TestProcessPinsBadMsgHeader(t *testing.T) {
 	}, bs)
 	assert.NoError(t, err)
 
-	// Confirm the offset
-	assert.Equal(t, int64(12345), <-ag.eventPoller.offsetCommitted)
-
 }
 
 func TestProcessSkipDupMsg(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 	org1 := newTestOrg("org1")
 
 	batchID := fftypes.NewUUID()
@@ -910,15 +1753,12 @@ func TestProcessSkipDupMsg(t *testing.T) {
 	}, bs)
 	assert.NoError(t, err)
 
-	// Confirm the offset
-	assert.Equal(t, int64(12345), <-ag.eventPoller.offsetCommitted)
-
 }
 
 func TestProcessMsgFailGetPins(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 	org1 := newTestOrg("org1")
 
 	batchID := fftypes.NewUUID()
@@ -1101,7 +1941,7 @@ func TestProcessMsgFailBadPin(t *testing.T) {
 				Hash: msg.Hash,
 			},
 			Topics: len(msg.Header.Topics),
-		}, &core.BatchPersisted{}, newBatchState(&ag.aggregator))
+		}, &core.BatchPersisted{}, newBatchState(ag.aggregator))
 	assert.NoError(t, err)
 
 }
@@ -1139,7 +1979,7 @@ func TestProcessMsgFailGetNextPins(t *testing.T) {
 				Hash: msg.Hash,
 			},
 			Topics: len(msg.Header.Topics),
-		}, &core.BatchPersisted{}, newBatchState(&ag.aggregator))
+		}, &core.BatchPersisted{}, newBatchState(ag.aggregator))
 	assert.EqualError(t, err, "pop")
 
 }
@@ -1147,7 +1987,8 @@ func TestProcessMsgFailGetNextPins(t *testing.T) {
 func TestProcessMsgFailPinUpdate(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
+	bs := newBatchState(ag.aggregator)
 	pin := fftypes.NewRandB32()
 	org1 := newTestOrg("org1")
 
@@ -1196,7 +2037,8 @@ func TestProcessMsgFailPinUpdate(t *testing.T) {
 func TestProcessMsgGapFill(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
+	bs := newBatchState(ag.aggregator)
 	pin := fftypes.NewRandB32()
 	org1 := newTestOrg("org1")
 
@@ -1259,7 +2101,7 @@ func TestCheckMaskedContextReadyMismatchedAuthor(t *testing.T) {
 		{Context: fftypes.NewRandB32(), Hash: pin},
 	}, nil)
 
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 	_, err := bs.checkMaskedContextReady(ag.ctx, &core.Message{
 		Header: core.MessageHeader{
 			ID:     fftypes.NewUUID(),
@@ -1297,7 +2139,7 @@ func TestAttemptContextInitGetGroupByIDFail(t *testing.T) {
 
 	ag.mpm.On("ResolveInitGroup", ag.ctx, mock.Anything, creator).Return(nil, fmt.Errorf("pop"))
 
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 	_, err := bs.attemptContextInit(ag.ctx, &core.Message{
 		Header: core.MessageHeader{
 			ID:        fftypes.NewUUID(),
@@ -1330,7 +2172,7 @@ func TestAttemptContextInitGroupNotFound(t *testing.T) {
 
 	ag.mpm.On("ResolveInitGroup", ag.ctx, mock.Anything, creator).Return(nil, nil)
 
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 	_, err := bs.attemptContextInit(ag.ctx, &core.Message{
 		Header: core.MessageHeader{
 			ID:        fftypes.NewUUID(),
@@ -1372,7 +2214,7 @@ func TestAttemptContextInitAuthorMismatch(t *testing.T) {
 		},
 	}, nil)
 
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 	_, err := bs.attemptContextInit(ag.ctx, &core.Message{
 		Header: core.MessageHeader{
 			ID:        fftypes.NewUUID(),
@@ -1412,7 +2254,7 @@ func TestAttemptContextInitNoMatch(t *testing.T) {
 		},
 	}, nil)
 
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 	_, err := bs.attemptContextInit(ag.ctx, &core.Message{
 		Header: core.MessageHeader{
 			ID:        fftypes.NewUUID(),
@@ -1455,7 +2297,7 @@ func TestAttemptContextInitGetPinsFail(t *testing.T) {
 	}, nil)
 	ag.mdi.On("GetPins", ag.ctx, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
 
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 	_, err := bs.attemptContextInit(ag.ctx, &core.Message{
 		Header: core.MessageHeader{
 			ID:        fftypes.NewUUID(),
@@ -1500,7 +2342,7 @@ func TestAttemptContextInitGetPinsBlocked(t *testing.T) {
 		{Sequence: 12345},
 	}, nil, nil)
 
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 	np, err := bs.attemptContextInit(ag.ctx, &core.Message{
 		Header: core.MessageHeader{
 			ID:        fftypes.NewUUID(),
@@ -1545,7 +2387,7 @@ func TestAttemptContextInitInsertPinsFail(t *testing.T) {
 	ag.mdi.On("GetPins", ag.ctx, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
 	ag.mdi.On("InsertNextPin", ag.ctx, mock.Anything).Return(fmt.Errorf("pop"))
 
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 	np, err := bs.attemptContextInit(ag.ctx, &core.Message{
 		Header: core.MessageHeader{
 			ID:        fftypes.NewUUID(),
@@ -1563,6 +2405,7 @@ func TestAttemptContextInitInsertPinsFail(t *testing.T) {
 func TestReadyForDispatchFailValidateData(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
 
 	org1 := newTestOrg("org1")
 	ag.mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(false, fmt.Errorf("pop"))
@@ -1577,6 +2420,30 @@ func TestReadyForDispatchFailValidateData(t *testing.T) {
 
 }
 
+func TestReadyForDispatchNonJSONContentStillValidated(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
+	ag.mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(false, nil)
+
+	org1 := newTestOrg("org1")
+
+	action, _, err := ag.readyForDispatch(ag.ctx, &core.Message{
+		Header: core.MessageHeader{
+			ID:          fftypes.NewUUID(),
+			SignerRef:   core.SignerRef{Key: "0x12345", Author: org1.DID},
+			ContentType: "application/octet-stream",
+		},
+		Data: core.DataRefs{
+			{ID: fftypes.NewUUID()},
+		},
+	}, core.DataArray{}, nil, &batchState{})
+	assert.NoError(t, err)
+	assert.Equal(t, core.ActionReject, action)
+	ag.mdm.AssertExpectations(t)
+
+}
+
 func TestReadyForDispatchMissingBlobs(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
@@ -1585,7 +2452,7 @@ func TestReadyForDispatchMissingBlobs(t *testing.T) {
 
 	org1 := newTestOrg("org1")
 
-	ag.mdi.On("GetBlobs", ag.ctx, mock.Anything, mock.Anything).Return([]*core.Blob{}, nil, nil)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(false, nil)
 
 	action, _, err := ag.readyForDispatch(ag.ctx, &core.Message{
 		Header: core.MessageHeader{ID: fftypes.NewUUID(), SignerRef: core.SignerRef{Key: "0x12345", Author: org1.DID}},
@@ -1600,6 +2467,39 @@ func TestReadyForDispatchMissingBlobs(t *testing.T) {
 
 }
 
+func TestReadyForDispatchDataAvailabilityAlternates(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	org1 := newTestOrg("org1")
+	msg := &core.Message{
+		Header: core.MessageHeader{
+			ID:          fftypes.NewUUID(),
+			SignerRef:   core.SignerRef{Key: "0x12345", Author: org1.DID},
+			ContentType: "application/octet-stream",
+		},
+	}
+	data := core.DataArray{
+		{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32(), Blob: &core.BlobRef{
+			Hash:   fftypes.NewRandB32(),
+			Public: "public-ref",
+		}},
+	}
+
+	ag.mdm.On("CheckDataAvailable", ag.ctx, data).Return(false, nil).Once()
+	ag.mdm.On("CheckDataAvailable", ag.ctx, data).Return(true, nil).Once()
+	ag.mdm.On("CheckDataAvailable", ag.ctx, data).Return(false, nil).Once()
+	ag.mdm.On("CheckDataAvailable", ag.ctx, data).Return(true, nil).Once()
+
+	// Each check is independent, so the action follows the availability on that call
+	for _, expected := range []core.MessageAction{core.ActionWait, core.ActionConfirm, core.ActionWait, core.ActionConfirm} {
+		action, _, err := ag.readyForDispatch(ag.ctx, msg, data, nil, &batchState{})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, action)
+	}
+
+}
+
 func TestReadyForDispatchBlobsError(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
@@ -1608,7 +2508,7 @@ func TestReadyForDispatchBlobsError(t *testing.T) {
 
 	org1 := newTestOrg("org1")
 
-	ag.mdi.On("GetBlobs", ag.ctx, mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(false, fmt.Errorf("pop"))
 
 	action, _, err := ag.readyForDispatch(ag.ctx, &core.Message{
 		Header: core.MessageHeader{ID: fftypes.NewUUID(), SignerRef: core.SignerRef{Key: "0x12345", Author: org1.DID}},
@@ -1626,6 +2526,7 @@ func TestReadyForDispatchBlobsError(t *testing.T) {
 func TestReadyForDispatchMissingTransfers(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
 
 	org1 := newTestOrg("org1")
 	ag.mdi.On("GetTokenTransfers", ag.ctx, "ns1", mock.Anything).Return([]*core.TokenTransfer{}, nil, nil)
@@ -1650,6 +2551,7 @@ func TestReadyForDispatchMissingTransfers(t *testing.T) {
 func TestReadyForDispatchGetTransfersFail(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
 
 	org1 := newTestOrg("org1")
 
@@ -1672,6 +2574,7 @@ func TestReadyForDispatchGetTransfersFail(t *testing.T) {
 func TestReadyForDispatchTransferMismatch(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
 
 	org1 := newTestOrg("org1")
 
@@ -1700,6 +2603,7 @@ func TestReadyForDispatchTransferMismatch(t *testing.T) {
 func TestReadyForDispatchGetApprovalsFail(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
 
 	org1 := newTestOrg("org1")
 
@@ -1722,6 +2626,7 @@ func TestReadyForDispatchGetApprovalsFail(t *testing.T) {
 func TestReadyForDispatchGetApprovalsMissing(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
 
 	org1 := newTestOrg("org1")
 
@@ -1744,6 +2649,7 @@ func TestReadyForDispatchGetApprovalsMissing(t *testing.T) {
 func TestReadyForDispatchApprovalMismatch(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
 
 	org1 := newTestOrg("org1")
 
@@ -1772,7 +2678,7 @@ func TestReadyForDispatchApprovalMismatch(t *testing.T) {
 func TestDefinitionBroadcastActionRejectFailUpdate(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 	org1 := newTestOrg("org1")
 
 	msg := &core.Message{
@@ -1808,7 +2714,8 @@ func TestDefinitionBroadcastActionRejectFailUpdate(t *testing.T) {
 func TestDispatchBroadcastQueuesLaterDispatch(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
+	bs := newBatchState(ag.aggregator)
 
 	msg1, msg2, org1, manifest := newTestManifest(core.MessageTypeDefinition, nil)
 
@@ -1847,7 +2754,7 @@ func TestDispatchBroadcastQueuesLaterDispatch(t *testing.T) {
 func TestDispatchPrivateQueuesLaterDispatch(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 
 	groupID := fftypes.NewRandB32()
 	msg1, msg2, org1, manifest := newTestManifest(core.MessageTypePrivate, groupID)
@@ -1868,7 +2775,8 @@ func TestDispatchPrivateQueuesLaterDispatch(t *testing.T) {
 	ag.mdi.On("GetNextPinsForContext", ag.ctx, "ns1", mock.Anything).Return([]*core.NextPin{
 		{Context: context, Nonce: 1 /* match member1NonceOne */, Identity: org1.DID, Hash: member1NonceOne},
 	}, nil).Once()
-	ag.mdi.On("GetBlobs", ag.ctx, "ns1", mock.Anything).Return([]*core.Blob{}, nil, nil)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, data1).Return(true, nil)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, data2).Return(false, nil)
 
 	msg1.Pins = fftypes.FFStringArray{member1NonceOne.String()}
 	msg2.Pins = fftypes.FFStringArray{member1NonceTwo.String()}
@@ -1891,7 +2799,8 @@ func TestDispatchPrivateQueuesLaterDispatch(t *testing.T) {
 func TestDispatchPrivateNextPinIncremented(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
+	bs := newBatchState(ag.aggregator)
 
 	groupID := fftypes.NewRandB32()
 	msg1, msg2, org1, manifest := newTestManifest(core.MessageTypePrivate, groupID)
@@ -1927,6 +2836,7 @@ func TestDispatchPrivateNextPinIncremented(t *testing.T) {
 func TestDefinitionBroadcastActionRetry(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
 
 	msg1, _, _, _ := newTestManifest(core.MessageTypeDefinition, nil)
 
@@ -1938,10 +2848,36 @@ func TestDefinitionBroadcastActionRetry(t *testing.T) {
 
 }
 
+func TestDefinitionBroadcastRetryThenConfirm(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
+
+	msg1, _, _, _ := newTestManifest(core.MessageTypeDefinition, nil)
+
+	ag.mdh.On("HandleDefinitionBroadcast", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(definitions.HandlerResult{Action: core.ActionRetry}, fmt.Errorf("timeout")).Times(3)
+	ag.mdh.On("HandleDefinitionBroadcast", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(definitions.HandlerResult{Action: core.ActionConfirm}, nil).Once()
+
+	// Retryable errors are returned, so the batch is retried - until the handler succeeds
+	for i := 0; i < 3; i++ {
+		action, _, err := ag.readyForDispatch(ag.ctx, msg1, nil, nil, &batchState{})
+		assert.EqualError(t, err, "timeout")
+		assert.Equal(t, core.ActionRetry, action)
+	}
+	action, _, err := ag.readyForDispatch(ag.ctx, msg1, nil, nil, &batchState{})
+	assert.NoError(t, err)
+	assert.Equal(t, core.ActionConfirm, action)
+
+	ag.mdh.AssertNumberOfCalls(t, "HandleDefinitionBroadcast", 4)
+}
+
 func TestDefinitionBroadcastActionReject(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
+	bs := newBatchState(ag.aggregator)
 
 	msg1, _, org1, manifest := newTestManifest(core.MessageTypeDefinition, nil)
 
@@ -2034,6 +2970,7 @@ func TestDefinitionBroadcastRootUnregistered(t *testing.T) {
 func TestDefinitionBroadcastActionWait(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
 
 	msg1, _, _, _ := newTestManifest(core.MessageTypeDefinition, nil)
 
@@ -2062,7 +2999,7 @@ func TestPrivateMessageUnregisteredSigner(t *testing.T) {
 func TestCompleteDispatchEventFail(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 	msg1, _, _, _ := newTestManifest(core.MessageTypeBroadcast, nil)
 
 	ag.mdi.On("InsertEvent", ag.ctx, mock.Anything).Return(fmt.Errorf("pop"))
@@ -2077,7 +3014,8 @@ func TestCompleteDispatchEventFail(t *testing.T) {
 func TestReadyForDispatchGroupInit(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
+	bs := newBatchState(ag.aggregator)
 	org1 := newTestOrg("org1")
 
 	action, _, err := ag.readyForDispatch(ag.ctx, &core.Message{
@@ -2158,73 +3096,13 @@ func TestRewindOffchainBatchesError(t *testing.T) {
 
 }
 
-func TestResolveBlobsNoop(t *testing.T) {
-	ag := newTestAggregator()
-	defer ag.cleanup(t)
-
-	resolved, err := ag.resolveBlobs(ag.ctx, core.DataArray{
-		{ID: fftypes.NewUUID(), Blob: &core.BlobRef{}},
-	})
-
-	assert.NoError(t, err)
-	assert.True(t, resolved)
-}
-
-func TestResolveBlobsErrorGettingHash(t *testing.T) {
-	ag := newTestAggregator()
-	defer ag.cleanup(t)
-
-	ag.mdi.On("GetBlobs", ag.ctx, mock.Anything, mock.Anything).Return([]*core.Blob{}, nil, fmt.Errorf("pop"))
-
-	resolved, err := ag.resolveBlobs(ag.ctx, core.DataArray{
-		{ID: fftypes.NewUUID(), Blob: &core.BlobRef{
-			Hash: fftypes.NewRandB32(),
-		}},
-	})
-
-	assert.EqualError(t, err, "pop")
-	assert.False(t, resolved)
-}
-
-func TestResolveBlobsNotFoundPrivate(t *testing.T) {
-	ag := newTestAggregator()
-	defer ag.cleanup(t)
-
-	ag.mdi.On("GetBlobs", ag.ctx, mock.Anything, mock.Anything).Return([]*core.Blob{}, nil, nil)
-
-	resolved, err := ag.resolveBlobs(ag.ctx, core.DataArray{
-		{ID: fftypes.NewUUID(), Blob: &core.BlobRef{
-			Hash: fftypes.NewRandB32(),
-		}},
-	})
-
-	assert.NoError(t, err)
-	assert.False(t, resolved)
-}
-
-func TestResolveBlobsFoundPrivate(t *testing.T) {
-	ag := newTestAggregator()
-	defer ag.cleanup(t)
-
-	ag.mdi.On("GetBlobs", ag.ctx, mock.Anything, mock.Anything).Return([]*core.Blob{{}}, nil, nil)
-
-	resolved, err := ag.resolveBlobs(ag.ctx, core.DataArray{
-		{ID: fftypes.NewUUID(), Blob: &core.BlobRef{
-			Hash: fftypes.NewRandB32(),
-		}},
-	})
-
-	assert.NoError(t, err)
-	assert.True(t, resolved)
-}
-
 func TestBatchActions(t *testing.T) {
 	prefinalizeCalled := false
 	finalizeCalled := false
 
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 
 	bs.AddPreFinalize(func(ctx context.Context) error {
 		prefinalizeCalled = true
@@ -2247,7 +3125,7 @@ func TestBatchActions(t *testing.T) {
 func TestBatchActionsError(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
-	bs := newBatchState(&ag.aggregator)
+	bs := newBatchState(ag.aggregator)
 
 	bs.AddPreFinalize(func(ctx context.Context) error {
 		return fmt.Errorf("pop")
@@ -2409,3 +3287,137 @@ func TestGetBatchForPinHashMismatch(t *testing.T) {
 	assert.Nil(t, err)
 
 }
+
+func newTestPriorityBatch(org1 *core.Identity, msgs ...*core.Message) (*core.BatchPersisted, []*core.Pin) {
+	batch := &core.Batch{
+		BatchHeader: core.BatchHeader{
+			ID: fftypes.NewUUID(),
+		},
+		Payload: core.BatchPayload{
+			Messages: msgs,
+		},
+	}
+	pins := make([]*core.Pin, len(msgs))
+	for i, msg := range msgs {
+		msg.Header.ID = fftypes.NewUUID()
+		msg.Header.Type = core.MessageTypeBroadcast
+		msg.Header.SignerRef = core.SignerRef{Author: org1.DID, Key: "key1"}
+		pins[i] = &core.Pin{Sequence: int64(1000 + i), Batch: batch.ID, Index: int64(i), Hash: fftypes.NewRandB32(), Signer: "key1"}
+	}
+	bp, _ := batch.Confirmed()
+	return bp, pins
+}
+
+func TestProcessPinsPriorityDispatchedFirst(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	bs := newBatchState(ag.aggregator)
+	org1 := newTestOrg("org1")
+
+	// The priority message is behind a normal priority message in the batch, but on a different context
+	msgNormal := &core.Message{Header: core.MessageHeader{Topics: fftypes.FFStringArray{"topic1"}}}
+	msgPriority := &core.Message{Header: core.MessageHeader{Topics: fftypes.FFStringArray{"topic2"}, Priority: 200}}
+	bp, pins := newTestPriorityBatch(org1, msgNormal, msgPriority)
+
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", mock.Anything).Return(bp, nil).Once()
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
+	ag.mdm.On("GetMessageWithDataCached", ag.ctx, msgNormal.Header.ID, data.CRORequirePublicBlobRefs).Return(msgNormal, core.DataArray{}, true, nil)
+	ag.mdm.On("GetMessageWithDataCached", ag.ctx, msgPriority.Header.ID, data.CRORequirePublicBlobRefs).Return(msgPriority, core.DataArray{}, true, nil)
+	ag.mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything).Return(org1, nil)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
+
+	err := ag.processPins(ag.ctx, pins, bs)
+	assert.NoError(t, err)
+
+	assert.Len(t, bs.dispatchedMessages, 2)
+	assert.Equal(t, msgPriority.Header.ID, bs.dispatchedMessages[0].msgID)
+	assert.Equal(t, msgNormal.Header.ID, bs.dispatchedMessages[1].msgID)
+}
+
+func TestProcessPinsPrioritySameContextKeepsOrder(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	bs := newBatchState(ag.aggregator)
+	org1 := newTestOrg("org1")
+
+	// The priority message shares a context with the normal message ahead of it, so cannot overtake it -
+	// but it still overtakes the message after it on another context, as the normal message ahead of it does not
+	msgNormal := &core.Message{Header: core.MessageHeader{Topics: fftypes.FFStringArray{"topic1"}}}
+	msgPriority := &core.Message{Header: core.MessageHeader{Topics: fftypes.FFStringArray{"topic2", "topic1"}, Priority: 200}}
+	msgOther := &core.Message{Header: core.MessageHeader{Topics: fftypes.FFStringArray{"topic3"}, Priority: 100}}
+	bp, pins := newTestPriorityBatch(org1, msgNormal, msgPriority, msgOther)
+	pins = []*core.Pin{pins[0], pins[1], {Sequence: 1002, Batch: bp.ID, Index: 2, Hash: fftypes.NewRandB32(), Signer: "key1"}, {Sequence: 1003, Batch: bp.ID, Index: 3, Hash: fftypes.NewRandB32(), Signer: "key1"}}
+
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", mock.Anything).Return(bp, nil).Once()
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
+	ag.mdm.On("GetMessageWithDataCached", ag.ctx, msgNormal.Header.ID, data.CRORequirePublicBlobRefs).Return(msgNormal, core.DataArray{}, true, nil)
+	ag.mdm.On("GetMessageWithDataCached", ag.ctx, msgPriority.Header.ID, data.CRORequirePublicBlobRefs).Return(msgPriority, core.DataArray{}, true, nil)
+	ag.mdm.On("GetMessageWithDataCached", ag.ctx, msgOther.Header.ID, data.CRORequirePublicBlobRefs).Return(msgOther, core.DataArray{}, true, nil)
+	ag.mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything).Return(org1, nil)
+	ag.mdm.On("CheckDataAvailable", ag.ctx, mock.Anything).Return(true, nil)
+
+	err := ag.processPins(ag.ctx, pins, bs)
+	assert.NoError(t, err)
+
+	assert.Len(t, bs.dispatchedMessages, 3)
+	assert.Equal(t, msgOther.Header.ID, bs.dispatchedMessages[0].msgID)
+	assert.Equal(t, msgNormal.Header.ID, bs.dispatchedMessages[1].msgID)
+	assert.Equal(t, msgPriority.Header.ID, bs.dispatchedMessages[2].msgID)
+}
+
+func TestPrioritizeMessagesMasked(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	groupID := fftypes.NewRandB32()
+	msg1 := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID(), Group: groupID, Topics: fftypes.FFStringArray{"topic1"}}}
+	msg2 := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID(), Group: groupID, Topics: fftypes.FFStringArray{"topic1"}, Priority: 10}}
+	msg3ID := fftypes.NewUUID()
+	pending := []*pendingMessage{
+		{pin: &core.Pin{Masked: true}, msgEntry: &core.MessageManifestEntry{MessageRef: core.MessageRef{ID: msg1.Header.ID}}},
+		{pin: &core.Pin{Masked: true}, msgEntry: &core.MessageManifestEntry{MessageRef: core.MessageRef{ID: msg2.Header.ID}}},
+		{pin: &core.Pin{Masked: true}, msgEntry: &core.MessageManifestEntry{MessageRef: core.MessageRef{ID: msg3ID}}},
+	}
+
+	ag.mdm.On("GetMessageWithDataCached", ag.ctx, msg1.Header.ID, data.CRORequirePins).Return(msg1, core.DataArray{}, true, nil)
+	ag.mdm.On("GetMessageWithDataCached", ag.ctx, msg2.Header.ID, data.CRORequirePins).Return(msg2, core.DataArray{}, true, nil)
+	ag.mdm.On("GetMessageWithDataCached", ag.ctx, msg3ID, data.CRORequirePins).Return(nil, nil, false, nil)
+
+	err := ag.prioritizeMessages(ag.ctx, pending)
+	assert.NoError(t, err)
+	assert.Equal(t, msg1.Header.ID, pending[0].msgEntry.ID)
+	assert.Equal(t, uint8(0), pending[1].priority)
+	assert.Equal(t, msg3ID, pending[2].msgEntry.ID)
+}
+
+func TestPrioritizeMessagesFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	pending := []*pendingMessage{
+		{pin: &core.Pin{}, msgEntry: &core.MessageManifestEntry{MessageRef: core.MessageRef{ID: fftypes.NewUUID()}}},
+	}
+
+	ag.mdm.On("GetMessageWithDataCached", ag.ctx, mock.Anything, data.CRORequirePublicBlobRefs).Return(nil, nil, false, fmt.Errorf("pop"))
+
+	err := ag.prioritizeMessages(ag.ctx, pending)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestProcessPinsPrioritizeFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	bs := newBatchState(ag.aggregator)
+	org1 := newTestOrg("org1")
+
+	bp, pins := newTestPriorityBatch(org1,
+		&core.Message{Header: core.MessageHeader{Topics: fftypes.FFStringArray{"topic1"}}},
+		&core.Message{Header: core.MessageHeader{Topics: fftypes.FFStringArray{"topic2"}}},
+	)
+
+	ag.mdi.On("GetBatchByID", ag.ctx, "ns1", mock.Anything).Return(bp, nil).Once()
+	ag.mdm.On("GetMessageWithDataCached", ag.ctx, mock.Anything, data.CRORequirePublicBlobRefs).Return(nil, nil, false, fmt.Errorf("pop"))
+
+	err := ag.processPins(ag.ctx, pins, bs)
+	assert.EqualError(t, err, "pop")
+}
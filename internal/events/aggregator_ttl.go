@@ -0,0 +1,91 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// ttlScanLoop periodically looks for messages that were never confirmed within their TTL, and expires them
+func (ag *aggregator) ttlScanLoop(ctx context.Context, interval time.Duration) {
+	defer close(ag.ttlScanDone)
+	for {
+		select {
+		case <-time.After(interval):
+			if err := ag.expireMessages(); err != nil {
+				log.L(ag.ctx).Errorf("TTL scan failed: %s", err)
+			}
+		case <-ctx.Done():
+			log.L(ag.ctx).Debugf("TTL scan loop stopping")
+			return
+		}
+	}
+}
+
+func (ag *aggregator) expireMessages() error {
+	msgs, err := ag.database.GetMessagesPastTTL(ag.ctx, ag.namespace, fftypes.Now(), ag.ttlScanBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, msg := range msgs {
+		if err := ag.expireMessage(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expireMessage marks a pending message expired, and emits an event for each of its topics.
+// Any pins for the message that arrive later are then skipped, rather than blocking the context,
+// and a rewind is queued in case the pins have already arrived and are waiting on the message.
+//
+// Only messages received from other nodes are expired - messages sent by this node are never pending.
+// The process lock is held so the expiry cannot interleave with the aggregator confirming the message.
+func (ag *aggregator) expireMessage(msg *core.Message) error {
+	ag.processLock.Lock()
+	defer ag.processLock.Unlock()
+
+	expired := false
+	err := ag.database.RunAsGroup(ag.ctx, func(ctx context.Context) (err error) {
+		expired, err = ag.database.ExpireMessage(ctx, ag.namespace, msg.Header.ID)
+		if err != nil || !expired {
+			return err
+		}
+		for _, topic := range msg.Header.Topics {
+			event := core.NewEvent(core.EventTypeMessageExpired, ag.namespace, msg.Header.ID, msg.TransactionID, topic)
+			event.Correlator = msg.Header.CID
+			if err := ag.database.InsertEvent(ctx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil || !expired {
+		return err
+	}
+	log.L(ag.ctx).Infof("Message '%s' expired after TTL %s", msg.Header.ID, msg.Header.TTL)
+	ag.data.UpdateMessageStateIfCached(ag.ctx, msg.Header.ID, core.MessageStateExpired, nil, "")
+	if msg.BatchID != nil {
+		ag.queueBatchRewind(msg.BatchID)
+	}
+	return nil
+}
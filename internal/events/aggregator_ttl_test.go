@@ -0,0 +1,187 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/internal/events/testmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestExpiredMessage() *core.Message {
+	ttl := fftypes.FFDuration(1 * time.Minute)
+	return &core.Message{
+		Header: core.MessageHeader{
+			ID:     fftypes.NewUUID(),
+			CID:    fftypes.NewUUID(),
+			Topics: fftypes.FFStringArray{"topic1", "topic2"},
+			TTL:    &ttl,
+		},
+		BatchID:       fftypes.NewUUID(),
+		TransactionID: fftypes.NewUUID(),
+		State:         core.MessageStatePending,
+	}
+}
+
+func TestExpireMessages(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	msg := newTestExpiredMessage()
+	mockRunAsGroupPassthrough(ag.mdi)
+	ag.mdi.On("GetMessagesPastTTL", ag.ctx, "ns1", mock.Anything, 200).Return([]*core.Message{msg}, nil)
+	ag.mdi.On("ExpireMessage", ag.ctx, "ns1", msg.Header.ID).Return(true, nil)
+	ag.mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(e *core.Event) bool {
+		return e.Type == core.EventTypeMessageExpired &&
+			e.Reference.Equals(msg.Header.ID) &&
+			e.Transaction.Equals(msg.TransactionID) &&
+			e.Correlator.Equals(msg.Header.CID)
+	})).Return(nil).Twice()
+	ag.mdm.On("UpdateMessageStateIfCached", ag.ctx, msg.Header.ID, core.MessageStateExpired, (*fftypes.FFTime)(nil), "").Return()
+
+	err := ag.expireMessages()
+	assert.NoError(t, err)
+
+	rw := <-ag.rewinder.rewindRequests
+	assert.Equal(t, rewindBatch, rw.rewindType)
+	assert.Equal(t, *msg.BatchID, rw.uuid)
+}
+
+func TestExpireMessagesQueryFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ag.mdi.On("GetMessagesPastTTL", ag.ctx, "ns1", mock.Anything, 200).Return(nil, fmt.Errorf("pop"))
+
+	err := ag.expireMessages()
+	assert.Regexp(t, "pop", err)
+}
+
+func TestExpireMessagesUpdateFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	msg := newTestExpiredMessage()
+	mockRunAsGroupPassthrough(ag.mdi)
+	ag.mdi.On("GetMessagesPastTTL", ag.ctx, "ns1", mock.Anything, 200).Return([]*core.Message{msg}, nil)
+	ag.mdi.On("ExpireMessage", ag.ctx, "ns1", msg.Header.ID).Return(false, fmt.Errorf("pop"))
+
+	err := ag.expireMessages()
+	assert.Regexp(t, "pop", err)
+}
+
+func TestExpireMessagesInsertEventFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	msg := newTestExpiredMessage()
+	mockRunAsGroupPassthrough(ag.mdi)
+	ag.mdi.On("GetMessagesPastTTL", ag.ctx, "ns1", mock.Anything, 200).Return([]*core.Message{msg}, nil)
+	ag.mdi.On("ExpireMessage", ag.ctx, "ns1", msg.Header.ID).Return(true, nil)
+	ag.mdi.On("InsertEvent", ag.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := ag.expireMessages()
+	assert.Regexp(t, "pop", err)
+}
+
+func TestTTLScanLoop(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ag.mdi.On("GetMessagesPastTTL", ag.ctx, "ns1", mock.Anything, 200).Return(nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		ag.cancel()
+	}).Once()
+
+	ag.ttlScanDone = make(chan struct{})
+	go ag.ttlScanLoop(ag.ctx, 1*time.Millisecond)
+	<-ag.ttlScanDone
+}
+
+func TestExpireMessagesAlreadyConfirmed(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	msg := newTestExpiredMessage()
+	mockRunAsGroupPassthrough(ag.mdi)
+	ag.mdi.On("GetMessagesPastTTL", ag.ctx, "ns1", mock.Anything, 200).Return([]*core.Message{msg}, nil)
+	ag.mdi.On("ExpireMessage", ag.ctx, "ns1", msg.Header.ID).Return(false, nil)
+
+	err := ag.expireMessages()
+	assert.NoError(t, err)
+
+	// No event is emitted, and no rewind is queued
+	ag.mdi.AssertNotCalled(t, "InsertEvent", mock.Anything, mock.Anything)
+	assert.Empty(t, ag.rewinder.rewindRequests)
+}
+
+func TestStopWaitsForTTLScanLoop(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*core.Offset{}, nil, nil)
+	ag.ttlScanInterval = 1 * time.Hour
+
+	mep := &testmocks.MockEventPoller{}
+	ag.eventPoller = mep
+	mep.On("Start").Return(nil)
+	mep.On("Stop").Return()
+
+	err := ag.start()
+	assert.NoError(t, err)
+	ag.stop()
+
+	select {
+	case <-ag.ttlScanDone:
+	default:
+		assert.Fail(t, "TTL scan loop still running")
+	}
+	mep.AssertExpectations(t)
+}
+
+func TestProcessMsgExpired(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	bs := newBatchState(ag.aggregator)
+
+	msg := newTestExpiredMessage()
+	msg.State = core.MessageStateExpired
+	ag.mdm.On("GetMessageWithDataCached", ag.ctx, msg.Header.ID, data.CRORequirePublicBlobRefs).Return(msg, nil, false, nil)
+	ag.mdi.On("GetPins", ag.ctx, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
+	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
+
+	err := ag.processMessage(ag.ctx, &core.BatchManifest{
+		ID: fftypes.NewUUID(),
+	}, &core.Pin{Sequence: 12345}, 10, &core.MessageManifestEntry{
+		MessageRef: core.MessageRef{ID: msg.Header.ID},
+		Topics:     len(msg.Header.Topics),
+	}, &core.BatchPersisted{}, bs)
+	assert.NoError(t, err)
+	assert.Len(t, bs.dispatchedMessages, 1)
+	assert.Equal(t, core.MessageStateExpired, bs.dispatchedMessages[0].newState)
+
+	err = bs.RunFinalize(ag.ctx)
+	assert.NoError(t, err)
+
+	ag.mdi.AssertNotCalled(t, "InsertEvent", mock.Anything, mock.Anything)
+	ag.mdi.AssertNotCalled(t, "UpdateMessages", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,90 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// forceUnblockContext is an administrative action to skip the earliest undispatched pin on a context,
+// for example where the message or data for that pin will never arrive, so later messages on the
+// context can be processed. The skipped pin is recorded as a dead event, along with the principal
+// that requested the action, so it is auditable.
+//
+// Masked (private) contexts are rejected. Each pin on those is specific to the sender and nonce, so skipping
+// one would leave the next pin of the sender unchanged, and the context would stay blocked.
+func (ag *aggregator) forceUnblockContext(ctx context.Context, contextHash *fftypes.Bytes32, principal string) error {
+	// Serialize with the aggregator, so the pin cannot be dispatched while we are skipping it
+	ag.processLock.Lock()
+	defer ag.processLock.Unlock()
+
+	fb := database.PinQueryFactory.NewFilterLimit(ctx, 2)
+	pins, _, err := ag.database.GetPins(ctx, ag.namespace, fb.And(
+		fb.Eq("hash", contextHash),
+		fb.Eq("dispatched", false),
+	).Sort("sequence"))
+	if err != nil {
+		return err
+	}
+	if len(pins) == 0 {
+		return i18n.NewError(ctx, coremsgs.MsgContextNotBlocked, contextHash)
+	}
+	blocking := pins[0]
+	if blocking.Masked {
+		return i18n.NewError(ctx, coremsgs.MsgContextMaskedCannotUnblock, contextHash)
+	}
+
+	log.L(ctx).Infof("Force unblocking context %s by skipping pin %.10d batch=%s pinIndex=%d principal='%s'", contextHash, blocking.Sequence, blocking.Batch, blocking.Index, principal)
+	deadEvent := &core.DeadEvent{
+		ID:        fftypes.NewUUID(),
+		Namespace: ag.namespace,
+		Pin:       blocking.Sequence,
+		Batch:     blocking.Batch,
+		Hash:      blocking.Hash,
+		Index:     blocking.Index,
+		Error:     fmt.Sprintf("Force unblocked by principal '%s'", principal),
+	}
+	err = ag.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		ub := database.PinQueryFactory.NewFilter(ctx)
+		update := database.PinQueryFactory.NewUpdate(ctx).Set("dispatched", true)
+		if err := ag.database.UpdatePins(ctx, ag.namespace, ub.Eq("sequence", blocking.Sequence), update); err != nil {
+			return err
+		}
+		if err := ag.database.UpsertDeadEvent(ctx, deadEvent); err != nil {
+			return err
+		}
+		event := core.NewEvent(core.EventTypeContextForceUnblocked, ag.namespace, deadEvent.ID, nil, contextHash.String())
+		return ag.database.InsertEvent(ctx, event)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Pins behind the skipped one might have already been passed over by the poller, so rewind to them
+	if len(pins) > 1 {
+		ag.queueBatchRewind(pins[1].Batch)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestForceUnblockContextRewindsToNextPin(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	hash := fftypes.NewRandB32()
+	blocking := &core.Pin{Sequence: 10, Hash: hash, Batch: fftypes.NewUUID(), Index: 0}
+	next := &core.Pin{Sequence: 20, Hash: hash, Batch: fftypes.NewUUID(), Index: 1}
+	mockRunAsGroupPassthrough(ag.mdi)
+	ag.mdi.On("GetPins", ag.ctx, "ns1", mock.Anything).Return([]*core.Pin{blocking, next}, nil, nil)
+	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
+	var deadEventID *fftypes.UUID
+	ag.mdi.On("UpsertDeadEvent", ag.ctx, mock.MatchedBy(func(de *core.DeadEvent) bool {
+		deadEventID = de.ID
+		return de.Pin == 10 && de.Batch.Equals(blocking.Batch) && de.Hash.Equals(hash) &&
+			de.Error == "Force unblocked by principal 'admin1'"
+	})).Return(nil)
+	ag.mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(e *core.Event) bool {
+		return e.Type == core.EventTypeContextForceUnblocked &&
+			e.Reference.Equals(deadEventID) &&
+			e.Topic == hash.String()
+	})).Return(nil)
+
+	err := ag.forceUnblockContext(ag.ctx, hash, "admin1")
+	assert.NoError(t, err)
+
+	rw := <-ag.rewinder.rewindRequests
+	assert.Equal(t, rewindBatch, rw.rewindType)
+	assert.Equal(t, *next.Batch, rw.uuid)
+}
+
+func TestForceUnblockContextLastPin(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	hash := fftypes.NewRandB32()
+	blocking := &core.Pin{Sequence: 10, Hash: hash, Batch: fftypes.NewUUID()}
+	mockRunAsGroupPassthrough(ag.mdi)
+	ag.mdi.On("GetPins", ag.ctx, "ns1", mock.Anything).Return([]*core.Pin{blocking}, nil, nil)
+	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
+	ag.mdi.On("UpsertDeadEvent", ag.ctx, mock.Anything).Return(nil)
+	ag.mdi.On("InsertEvent", ag.ctx, mock.Anything).Return(nil)
+
+	err := ag.forceUnblockContext(ag.ctx, hash, "admin1")
+	assert.NoError(t, err)
+	assert.Empty(t, ag.rewinder.rewindRequests)
+}
+
+func TestForceUnblockContextNotFound(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ag.mdi.On("GetPins", ag.ctx, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
+
+	err := ag.forceUnblockContext(ag.ctx, fftypes.NewRandB32(), "admin1")
+	assert.Regexp(t, "FF10493", err)
+}
+
+func TestForceUnblockContextUpdateFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	hash := fftypes.NewRandB32()
+	mockRunAsGroupPassthrough(ag.mdi)
+	ag.mdi.On("GetPins", ag.ctx, "ns1", mock.Anything).Return([]*core.Pin{{Sequence: 10, Hash: hash, Batch: fftypes.NewUUID()}}, nil, nil)
+	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := ag.forceUnblockContext(ag.ctx, hash, "admin1")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestForceUnblockContextMasked(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	hash := fftypes.NewRandB32()
+	ag.mdi.On("GetPins", ag.ctx, "ns1", mock.Anything).Return([]*core.Pin{{Sequence: 10, Hash: hash, Masked: true, Batch: fftypes.NewUUID()}}, nil, nil)
+
+	err := ag.forceUnblockContext(ag.ctx, hash, "admin1")
+	assert.Regexp(t, "FF10505", err)
+	ag.mdi.AssertNotCalled(t, "UpdatePins", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestForceUnblockContextDeadEventFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	hash := fftypes.NewRandB32()
+	mockRunAsGroupPassthrough(ag.mdi)
+	ag.mdi.On("GetPins", ag.ctx, "ns1", mock.Anything).Return([]*core.Pin{{Sequence: 10, Hash: hash, Batch: fftypes.NewUUID()}}, nil, nil)
+	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
+	ag.mdi.On("UpsertDeadEvent", ag.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := ag.forceUnblockContext(ag.ctx, hash, "admin1")
+	assert.EqualError(t, err, "pop")
+}
@@ -69,6 +69,10 @@ func (em *eventManager) handleBlockchainBatchPinEvent(ctx context.Context, event
 	bc.addEventToInsert(chainEvent, em.getTopicForChainListener(nil))
 	bc.postInsert = append(bc.postInsert, func() error {
 		em.emitBlockchainEventMetric(&batchPin.Event)
+		if batchPin.TransactionType == core.TransactionTypeMessagePin {
+			// A message pin only anchors the hash of a message - there are no contexts to sequence, and no batch
+			return nil
+		}
 		return em.postBlockchainBatchPinEventInsert(ctx, event)
 	})
 	return nil
@@ -260,6 +260,47 @@ func TestBatchPinCompleteOkPrivate(t *testing.T) {
 
 }
 
+func TestBatchPinCompleteMessagePin(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+
+	batchPin := &blockchain.BatchPin{
+		TransactionID:   fftypes.NewUUID(),
+		TransactionType: core.TransactionTypeMessagePin,
+		BatchID:         fftypes.NewUUID(),
+		BatchHash:       fftypes.NewRandB32(),
+		BatchPayloadRef: blockchain.MessagePinPayloadRef,
+		Event: blockchain.Event{
+			BlockchainTXID: "0x12345",
+			ProtocolID:     "10/20/30",
+		},
+	}
+
+	em.mth.On("PersistTransaction", mock.Anything, batchPin.TransactionID, core.TransactionTypeMessagePin, "0x12345").Return(true, nil)
+	em.mth.On("InsertNewBlockchainEvents", mock.Anything, mock.Anything).Return([]*core.BlockchainEvent{{ID: fftypes.NewUUID()}}, nil)
+	em.mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(nil)
+
+	err := em.BlockchainEventBatch([]*blockchain.EventToDispatch{
+		{
+			Type: blockchain.EventTypeBatchPinComplete,
+			BatchPinComplete: &blockchain.BatchPinCompleteEvent{
+				Namespace: "ns1",
+				Batch:     batchPin,
+				SigningKey: &core.VerifierRef{
+					Type:  core.VerifierTypeEthAddress,
+					Value: "0xffffeeee",
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	// No pins are persisted, and no batch is looked up or downloaded
+	em.mdi.AssertNotCalled(t, "InsertPins", mock.Anything, mock.Anything)
+	em.mdi.AssertNotCalled(t, "GetBatchByID", mock.Anything, mock.Anything, mock.Anything)
+	em.mth.AssertExpectations(t)
+}
+
 func TestBatchPinCompleteInsertPinsFail(t *testing.T) {
 	em := newTestEventManager(t)
 	defer em.cleanup(t)
@@ -642,7 +683,7 @@ func TestPersistBatchGoodDataUpsertOptimizeFail(t *testing.T) {
 
 	em.mdi.On("InsertOrGetBatch", mock.Anything, mock.Anything).Return(nil, nil)
 	em.mdi.On("InsertDataArray", mock.Anything, mock.Anything).Return(fmt.Errorf("optimzation miss"))
-	em.mdi.On("UpsertData", mock.Anything, mock.Anything, database.UpsertOptimizationExisting).Return(fmt.Errorf("pop"))
+	em.mdi.On("UpsertData", mock.Anything, mock.Anything, database.UpsertOptimizationExisting, false).Return(fmt.Errorf("pop"))
 
 	em.mim.On("GetLocalNode", mock.Anything).Return(testNode, nil)
 
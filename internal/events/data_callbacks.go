@@ -0,0 +1,36 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/log"
+)
+
+// ExternalDataVerified is called by the data manager when the content of an external data
+// reference has been fetched and verified, so any batch waiting on it can be re-processed
+func (em *eventManager) ExternalDataVerified(dataID *fftypes.UUID) {
+	log.L(em.ctx).Debugf("External data %s verified", dataID)
+	em.aggregator.queueDataRewind(dataID)
+}
+
+// ExternalDataFailed is called by the data manager when the content of an external data reference
+// could not be verified, so any batch waiting on it can be re-processed and the message rejected
+func (em *eventManager) ExternalDataFailed(dataID *fftypes.UUID) {
+	log.L(em.ctx).Debugf("External data %s failed verification", dataID)
+	em.aggregator.queueDataRewind(dataID)
+}
@@ -0,0 +1,48 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExternalDataVerified(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+
+	dataID := fftypes.NewUUID()
+	em.ExternalDataVerified(dataID)
+
+	rw := <-em.aggregator.rewinder.rewindRequests
+	assert.Equal(t, rewindData, rw.rewindType)
+	assert.Equal(t, *dataID, rw.uuid)
+}
+
+func TestExternalDataFailed(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+
+	dataID := fftypes.NewUUID()
+	em.ExternalDataFailed(dataID)
+
+	rw := <-em.aggregator.rewinder.rewindRequests
+	assert.Equal(t, rewindData, rw.rewindType)
+	assert.Equal(t, *dataID, rw.uuid)
+}
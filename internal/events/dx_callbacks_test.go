@@ -649,7 +649,7 @@ func TestMessageReceiveMessagePersistDataFail(t *testing.T) {
 
 	em.mdi.On("InsertOrGetBatch", em.ctx, mock.Anything).Return(nil, nil)
 	em.mdi.On("InsertDataArray", em.ctx, mock.Anything).Return(fmt.Errorf("optimization miss"))
-	em.mdi.On("UpsertData", em.ctx, mock.Anything, database.UpsertOptimizationExisting).Return(fmt.Errorf("pop"))
+	em.mdi.On("UpsertData", em.ctx, mock.Anything, database.UpsertOptimizationExisting, false).Return(fmt.Errorf("pop"))
 
 	// no ack as we are simulating termination mid retry
 	mde := newMessageReceivedNoAck("peer1", tw)
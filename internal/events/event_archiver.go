@@ -0,0 +1,96 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// eventArchiver periodically moves events older than the retention period into the archive table
+type eventArchiver struct {
+	ctx        context.Context
+	cancelFunc func()
+	namespace  string
+	database   database.Plugin
+	retention  time.Duration
+	interval   time.Duration
+	batchSize  int
+	done       chan struct{}
+}
+
+func newEventArchiver(ctx context.Context, ns string, di database.Plugin) *eventArchiver {
+	ea := &eventArchiver{
+		namespace: ns,
+		database:  di,
+		retention: config.GetDuration(coreconfig.EventArchiveRetention),
+		interval:  config.GetDuration(coreconfig.EventArchiveInterval),
+		batchSize: config.GetInt(coreconfig.EventArchiveBatchSize),
+		done:      make(chan struct{}),
+	}
+	ea.ctx, ea.cancelFunc = context.WithCancel(log.WithLogField(ctx, "role", "event-archiver"))
+	return ea
+}
+
+func (ea *eventArchiver) start() {
+	go ea.archiveLoop()
+}
+
+func (ea *eventArchiver) stop() {
+	ea.cancelFunc()
+	<-ea.done
+}
+
+func (ea *eventArchiver) archiveLoop() {
+	defer close(ea.done)
+	l := log.L(ea.ctx)
+	for {
+		ea.archiveExpired()
+		select {
+		case <-time.After(ea.interval):
+		case <-ea.ctx.Done():
+			l.Debugf("Event archiver stopping")
+			return
+		}
+	}
+}
+
+// archiveExpired moves batches of events until there are none left older than the retention period.
+// Failures are logged and retried on the next interval.
+func (ea *eventArchiver) archiveExpired() {
+	l := log.L(ea.ctx)
+	cutoff := fftypes.FFTime(time.Now().Add(-ea.retention))
+	for ea.ctx.Err() == nil {
+		count, err := ea.database.ArchiveEvents(ea.ctx, ea.namespace, &cutoff, ea.batchSize)
+		if err != nil {
+			l.Errorf("Failed to archive events: %s", err)
+			return
+		}
+		if count > 0 {
+			l.Infof("Archived %d events created before %s", count, cutoff.String())
+		}
+		if count < int64(ea.batchSize) {
+			return
+		}
+	}
+}
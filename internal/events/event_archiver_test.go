@@ -0,0 +1,80 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestEventArchiver(t *testing.T) (*eventArchiver, *databasemocks.Plugin) {
+	coreconfig.Reset()
+	mdi := &databasemocks.Plugin{}
+	ea := newEventArchiver(context.Background(), "ns1", mdi)
+	assert.Equal(t, 1*time.Hour, ea.interval)
+	assert.Equal(t, 1000, ea.batchSize)
+	ea.retention = 24 * time.Hour
+	ea.batchSize = 2
+	return ea, mdi
+}
+
+func TestArchiveExpiredRepeatsFullBatches(t *testing.T) {
+	ea, mdi := newTestEventArchiver(t)
+	defer mdi.AssertExpectations(t)
+
+	cutoffMatcher := mock.MatchedBy(func(cutoff *fftypes.FFTime) bool {
+		return time.Since(*cutoff.Time()) >= ea.retention
+	})
+	mdi.On("ArchiveEvents", mock.Anything, "ns1", cutoffMatcher, 2).Return(int64(2), nil).Once()
+	mdi.On("ArchiveEvents", mock.Anything, "ns1", cutoffMatcher, 2).Return(int64(1), nil).Once()
+
+	ea.archiveExpired()
+}
+
+func TestArchiveExpiredFail(t *testing.T) {
+	ea, mdi := newTestEventArchiver(t)
+	defer mdi.AssertExpectations(t)
+
+	mdi.On("ArchiveEvents", mock.Anything, "ns1", mock.Anything, 2).Return(int64(-1), fmt.Errorf("pop")).Once()
+
+	ea.archiveExpired()
+}
+
+func TestArchiveLoopRunsEachInterval(t *testing.T) {
+	ea, mdi := newTestEventArchiver(t)
+	defer mdi.AssertExpectations(t)
+	ea.interval = 1 * time.Millisecond
+
+	twice := make(chan struct{})
+	mdi.On("ArchiveEvents", mock.Anything, "ns1", mock.Anything, 2).Return(int64(0), nil).Once()
+	mdi.On("ArchiveEvents", mock.Anything, "ns1", mock.Anything, 2).Return(int64(0), nil).Run(func(args mock.Arguments) {
+		close(twice)
+	}).Once()
+	mdi.On("ArchiveEvents", mock.Anything, "ns1", mock.Anything, 2).Return(int64(0), nil).Maybe()
+
+	ea.start()
+	<-twice
+	ea.stop()
+}
@@ -19,6 +19,7 @@ package events
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -51,27 +52,31 @@ type ackNack struct {
 }
 
 type eventDispatcher struct {
-	acksNacks     chan ackNack
-	cancelCtx     func()
-	closed        chan struct{}
-	connID        string
-	ctx           context.Context
-	enricher      *eventEnricher
-	data          data.Manager
-	database      database.Plugin
-	transport     events.Plugin
-	broadcast     broadcast.Manager        // optional
-	messaging     privatemessaging.Manager // optional
-	elected       bool
-	eventPoller   *eventPoller
-	inflight      map[fftypes.UUID]*core.Event
-	eventDelivery chan []*core.EventDelivery
-	mux           sync.Mutex
-	namespace     string
-	readAhead     int
-	batch         bool
-	subscription  *subscription
-	txHelper      txcommon.Helper
+	acksNacks        chan ackNack
+	cancelCtx        func()
+	closed           chan struct{}
+	connID           string
+	ctx              context.Context
+	enricher         *eventEnricher
+	data             data.Manager
+	database         database.Plugin
+	transport        events.Plugin
+	broadcast        broadcast.Manager        // optional
+	messaging        privatemessaging.Manager // optional
+	elected          bool
+	eventPoller      *eventPoller
+	inflight         map[fftypes.UUID]*core.Event
+	drained          chan struct{} // set while draining ahead of deletion, and closed once nothing is in flight
+	eventDelivery    chan []*core.EventDelivery
+	mux              sync.Mutex
+	namespace        string
+	namespaces       []string // set when the subscription delivers events from additional namespaces
+	readAhead        int
+	batch            bool
+	archiveEnabled   bool
+	archiveHighWater int64 // the highest sequence last seen in the archive, only accessed by the event poller
+	subscription     *subscription
+	txHelper         txcommon.Helper
 }
 
 func newEventDispatcher(ctx context.Context, enricher *eventEnricher, ei events.Plugin, di database.Plugin, dm data.Manager, bm broadcast.Manager, pm privatemessaging.Manager, connID string, sub *subscription, en *eventNotifier, txHelper txcommon.Helper) *eventDispatcher {
@@ -93,28 +98,33 @@ func newEventDispatcher(ctx context.Context, enricher *eventEnricher, ei events.
 		ctx: log.WithLogField(log.WithLogField(ctx,
 			"role", fmt.Sprintf("ed[%s]", connID)),
 			"sub", fmt.Sprintf("%s/%s:%s", sub.definition.ID, sub.definition.Namespace, sub.definition.Name)),
-		enricher:      enricher,
-		database:      di,
-		transport:     ei,
-		broadcast:     bm,
-		messaging:     pm,
-		data:          dm,
-		connID:        connID,
-		cancelCtx:     cancelCtx,
-		subscription:  sub,
-		namespace:     sub.definition.Namespace,
-		inflight:      make(map[fftypes.UUID]*core.Event),
-		eventDelivery: make(chan []*core.EventDelivery, readAhead+1),
-		readAhead:     int(readAhead),
-		acksNacks:     make(chan ackNack),
-		closed:        make(chan struct{}),
-		txHelper:      txHelper,
-		batch:         batch,
+		enricher:         enricher,
+		database:         di,
+		transport:        ei,
+		broadcast:        bm,
+		messaging:        pm,
+		data:             dm,
+		connID:           connID,
+		cancelCtx:        cancelCtx,
+		subscription:     sub,
+		namespace:        sub.definition.Namespace,
+		namespaces:       subscriptionNamespaces(sub.definition),
+		inflight:         make(map[fftypes.UUID]*core.Event),
+		eventDelivery:    make(chan []*core.EventDelivery, readAhead+1),
+		readAhead:        int(readAhead),
+		acksNacks:        make(chan ackNack),
+		closed:           make(chan struct{}),
+		txHelper:         txHelper,
+		batch:            batch,
+		archiveEnabled:   config.GetDuration(coreconfig.EventArchiveRetention) > 0,
+		archiveHighWater: -1,
 	}
 
 	pollerConf := &eventPollerConf{
 		eventBatchSize:             config.GetInt(coreconfig.EventDispatcherBufferLength),
 		eventBatchTimeout:          config.GetDuration(coreconfig.EventDispatcherBatchTimeout),
+		eventBatchTimeoutOverrides: batchTimeoutOverrides(ctx),
+		eventTypeFilter:            matchingEventTypes(sub.eventMatcher),
 		eventPollTimeout:           config.GetDuration(coreconfig.EventDispatcherPollTimeout),
 		startupOffsetRetryAttempts: 0, // We need to keep trying to start indefinitely
 		retry: retry.Retry{
@@ -129,6 +139,7 @@ func newEventDispatcher(ctx context.Context, enricher *eventEnricher, ei events.
 		queryFactory:     database.EventQueryFactory,
 		getItems:         ed.getEvents,
 		newEventsHandler: ed.bufferedDelivery,
+		notifyFilter:     sub.MatchesNotification,
 		ephemeral:        sub.definition.Ephemeral,
 		firstEvent:       sub.definition.Options.FirstEvent,
 	}
@@ -151,6 +162,20 @@ func newEventDispatcher(ctx context.Context, enricher *eventEnricher, ei events.
 	return ed
 }
 
+// batchTimeoutOverrides reads the batch timeouts configured for individual event types
+func batchTimeoutOverrides(ctx context.Context) map[core.EventType]time.Duration {
+	overrides := make(map[core.EventType]time.Duration)
+	for eventType, value := range config.GetObject(coreconfig.EventDispatcherBatchTimeoutByType) {
+		timeout, err := fftypes.ParseDurationString(fmt.Sprint(value), time.Millisecond)
+		if err != nil || timeout <= 0 {
+			log.L(ctx).Warnf("Ignoring invalid batch timeout '%v' for event type '%s'", value, eventType)
+			continue
+		}
+		overrides[core.EventType(eventType)] = time.Duration(timeout)
+	}
+	return overrides
+}
+
 func (ed *eventDispatcher) start() {
 	go ed.electAndStart()
 }
@@ -172,7 +197,14 @@ func (ed *eventDispatcher) electAndStart() {
 	}
 	// We're ready to go
 	ed.elected = true
-	ed.eventPoller.start()
+	err := ed.eventPoller.retryDo("apply startup mode", func(attempt int) (retry bool, err error) {
+		return true, ed.applyStartupMode()
+	})
+	if err == nil {
+		_ = ed.eventPoller.Start()
+	} else {
+		close(ed.eventPoller.closed)
+	}
 
 	go ed.deliverEvents()
 
@@ -180,9 +212,89 @@ func (ed *eventDispatcher) electAndStart() {
 	<-ed.eventPoller.closed
 }
 
+// applyStartupMode moves the stored offset of a durable subscription according to its
+// startup mode, before the event poller restores it. This is done once each time the
+// subscription is loaded, not each time a dispatcher is elected for a new connection.
+// Only the elected dispatcher gets here, so the flag on the subscription needs no lock.
+func (ed *eventDispatcher) applyStartupMode() error {
+	def := ed.subscription.definition
+	if def.Ephemeral || def.Options.StartupMode == nil || ed.subscription.startupModeApplied {
+		return nil
+	}
+	var firstEvent *core.SubOptsFirstEvent
+	switch *def.Options.StartupMode {
+	case core.SubOptsStartupModeSkip:
+		newest := core.SubOptsFirstEventNewest
+		firstEvent = &newest
+	case core.SubOptsStartupModeReplay:
+		firstEvent = def.Options.FirstEvent
+	default:
+		ed.subscription.startupModeApplied = true
+		return nil
+	}
+	existing, err := ed.database.GetOffset(ed.ctx, core.OffsetTypeSubscription, def.ID.String())
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		// A new subscription - the event poller creates the offset from its firstEvent
+		ed.subscription.startupModeApplied = true
+		return nil
+	}
+	offset, err := calcFirstOffset(ed.ctx, ed.namespace, ed.database, firstEvent)
+	if err != nil {
+		return err
+	}
+	log.L(ed.ctx).Infof("Subscription startup mode '%s' set offset to %d", *def.Options.StartupMode, offset)
+	err = ed.database.UpsertOffset(ed.ctx, &core.Offset{
+		Type:    core.OffsetTypeSubscription,
+		Name:    def.ID.String(),
+		Current: offset,
+	}, true)
+	if err != nil {
+		return err
+	}
+	ed.subscription.startupModeApplied = true
+	return nil
+}
+
+// subscriptionNamespaces returns the de-duplicated list of namespaces a subscription delivers events from,
+// or nil if it only delivers events from its own namespace
+func subscriptionNamespaces(def *core.Subscription) []string {
+	if len(def.Filter.Namespaces) == 0 {
+		return nil
+	}
+	namespaces := []string{def.Namespace}
+	for _, ns := range def.Filter.Namespaces {
+		found := false
+		for _, existing := range namespaces {
+			if existing == ns {
+				found = true
+				break
+			}
+		}
+		if !found {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
 func (ed *eventDispatcher) getEvents(ctx context.Context, filter ffapi.Filter, offset int64) ([]core.LocallySequenced, error) {
 	log.L(ctx).Tracef("Reading page of events > %d (first events would be %d)", offset, offset+1)
-	events, _, err := ed.database.GetEvents(ctx, ed.namespace, filter)
+	var events []*core.Event
+	var err error
+	if ed.namespaces != nil {
+		events, _, err = ed.database.GetEventsInNamespaces(ctx, ed.namespaces, filter)
+	} else {
+		events, _, err = ed.database.GetEvents(ctx, ed.namespace, filter)
+	}
+	if err == nil && ed.archiveEnabled && (len(events) == 0 || events[0].Sequence > offset+1) {
+		// Events are archived strictly in sequence order, so anything we are missing before the
+		// first live event can only be in the archive table. We check the live table first, so
+		// that events archived while we are querying cannot be missed.
+		events, err = ed.addArchivedEvents(ctx, filter, offset, events)
+	}
 	ls := make([]core.LocallySequenced, len(events))
 	for i, e := range events {
 		ls[i] = e
@@ -190,6 +302,63 @@ func (ed *eventDispatcher) getEvents(ctx context.Context, filter ffapi.Filter, o
 	return ls, err
 }
 
+// addArchivedEvents merges any archived events between the offset and the first live event into the page
+func (ed *eventDispatcher) addArchivedEvents(ctx context.Context, filter ffapi.Filter, offset int64, events []*core.Event) ([]*core.Event, error) {
+	inArchive, err := ed.archiveHasEventsAfter(ctx, offset)
+	if err != nil || !inArchive {
+		return events, err
+	}
+	archiveFilter := filter
+	if len(events) > 0 {
+		fb := filter.Builder()
+		archiveFilter = fb.And(filter, fb.Lt("sequence", events[0].Sequence))
+	}
+	var archived []*core.Event
+	if ed.namespaces != nil {
+		archived, _, err = ed.database.GetArchivedEventsInNamespaces(ctx, ed.namespaces, archiveFilter)
+	} else {
+		archived, _, err = ed.database.GetArchivedEvents(ctx, ed.namespace, archiveFilter)
+	}
+	if err != nil || len(archived) == 0 {
+		return events, err
+	}
+	log.L(ctx).Debugf("Read %d archived events > %d", len(archived), offset)
+	fi, err := filter.Finalize()
+	if err != nil {
+		return nil, err
+	}
+	merged := append(archived, events...)
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Sequence < merged[j].Sequence })
+	if fi.Limit > 0 && uint64(len(merged)) > fi.Limit {
+		merged = merged[:fi.Limit]
+	}
+	return merged, nil
+}
+
+// archiveHasEventsAfter checks the offset against the highest sequence in the archive. The archive only
+// grows, so the cached high-water mark is good until the offset reaches it - then it is read again, after
+// the live query, so that events archived since the last read are not missed.
+func (ed *eventDispatcher) archiveHasEventsAfter(ctx context.Context, offset int64) (bool, error) {
+	if offset < ed.archiveHighWater {
+		return true, nil
+	}
+	filter := database.EventQueryFactory.NewFilterLimit(ctx, 1).And().Sort("sequence").Descending()
+	var latest []*core.Event
+	var err error
+	if ed.namespaces != nil {
+		latest, _, err = ed.database.GetArchivedEventsInNamespaces(ctx, ed.namespaces, filter)
+	} else {
+		latest, _, err = ed.database.GetArchivedEvents(ctx, ed.namespace, filter)
+	}
+	if err != nil {
+		return false, err
+	}
+	if len(latest) > 0 {
+		ed.archiveHighWater = latest[0].Sequence
+	}
+	return offset < ed.archiveHighWater, nil
+}
+
 func (ed *eventDispatcher) enrichEvents(events []core.LocallySequenced) ([]*core.EventDelivery, error) {
 	enriched := make([]*core.EventDelivery, len(events))
 	for i, ls := range events {
@@ -257,8 +426,15 @@ func (ed *eventDispatcher) bufferedDelivery(events []core.LocallySequenced) (boo
 		l.Debugf("Dispatcher event state: readahead=%d candidates=%d matched=%d inflight=%d queued=%d dispatched=%d dispatchable=%d lastAck=%d nacks=%d highest=%d",
 			ed.readAhead, len(candidates), matchCount, inflightCount, len(matching), dispatched, len(dispatchable), lastAck, nacks, highestOffset)
 
-		for _, event := range dispatchable {
+		for i, event := range dispatchable {
 			ed.mux.Lock()
+			if ed.drained != nil {
+				// We are draining ahead of deletion, so nothing new can go in flight
+				ed.mux.Unlock()
+				dispatchable = dispatchable[0:i]
+				matching = nil
+				break
+			}
 			ed.inflight[*event.ID] = &event.Event
 			inflightCount = len(ed.inflight)
 			ed.mux.Unlock()
@@ -294,12 +470,61 @@ func (ed *eventDispatcher) bufferedDelivery(events []core.LocallySequenced) (boo
 			}
 		}
 	}
+	if ed.isDraining() {
+		// Do not move the offset past events we did not deliver, or poll again straight away
+		return false, nil
+	}
 	if nacks == 0 && lastAck != highestOffset {
 		ed.eventPoller.commitOffset(highestOffset)
 	}
 	return true, nil // poll again straight away for more messages
 }
 
+// startDrain stops any new events being dispatched, and returns a channel that is
+// closed once all in-flight deliveries have been acknowledged or rejected
+func (ed *eventDispatcher) startDrain() <-chan struct{} {
+	ed.mux.Lock()
+	defer ed.mux.Unlock()
+	if ed.drained == nil {
+		ed.drained = make(chan struct{})
+		ed.checkDrainedLocked()
+	}
+	return ed.drained
+}
+
+func (ed *eventDispatcher) cancelDrain() {
+	ed.mux.Lock()
+	defer ed.mux.Unlock()
+	ed.drained = nil
+}
+
+func (ed *eventDispatcher) isDraining() bool {
+	ed.mux.Lock()
+	defer ed.mux.Unlock()
+	return ed.drained != nil
+}
+
+func (ed *eventDispatcher) checkDrainedLocked() {
+	if ed.drained != nil && len(ed.inflight) == 0 {
+		select {
+		case <-ed.drained:
+		default:
+			close(ed.drained)
+		}
+	}
+}
+
+func (ed *eventDispatcher) inflightIDs() []*fftypes.UUID {
+	ed.mux.Lock()
+	defer ed.mux.Unlock()
+	ids := make([]*fftypes.UUID, 0, len(ed.inflight))
+	for id := range ed.inflight {
+		id := id
+		ids = append(ids, &id)
+	}
+	return ids
+}
+
 func (ed *eventDispatcher) handleNackOffsetUpdate(nack ackNack) {
 	ed.mux.Lock()
 	defer ed.mux.Unlock()
@@ -311,6 +536,7 @@ func (ed *eventDispatcher) handleNackOffsetUpdate(nack ackNack) {
 		ed.eventPoller.rewindPollingOffset(nack.offset - 1)
 	}
 	ed.inflight = map[fftypes.UUID]*core.Event{}
+	ed.checkDrainedLocked()
 }
 
 func (ed *eventDispatcher) handleAckOffsetUpdate(ack ackNack) {
@@ -323,6 +549,7 @@ func (ed *eventDispatcher) handleAckOffsetUpdate(ack ackNack) {
 			lowestInflight = inflight.Sequence
 		}
 	}
+	ed.checkDrainedLocked()
 	ed.mux.Unlock()
 	if (lowestInflight == -1 || lowestInflight > ack.offset) && ack.offset > oldOffset {
 		// This was the lowest in flight, and we can move the offset forwards
@@ -353,7 +580,11 @@ func (ed *eventDispatcher) deliverEvents() {
 				if err == nil {
 					log.L(ed.ctx).Debugf("Dispatching %s event: %.10d/%s [%s]: ref=%s/%s", ed.transport.Name(), e.Event.Sequence, e.Event.ID, e.Event.Type, e.Event.Namespace, e.Event.Reference)
 					if withData && e.Event.Message != nil {
-						e.Data, _, err = ed.data.GetMessageDataCached(ed.ctx, e.Event.Message)
+						if ed.namespaces != nil && e.Event.Namespace != ed.namespace {
+							e.Data, err = ed.enricher.getForeignMessageData(ed.ctx, e.Event.Namespace, e.Event.Message)
+						} else {
+							e.Data, _, err = ed.data.GetMessageDataCached(ed.ctx, e.Event.Message)
+						}
 					}
 				}
 				// If we are non-batched, we have to deliver each event individually...
@@ -361,6 +592,11 @@ func (ed *eventDispatcher) deliverEvents() {
 					// .. only attempt to deliver if we've not triggered into an error scenario for one of the events already
 					if err == nil {
 						err = ed.transport.DeliveryRequest(ed.ctx, ed.connID, ed.subscription.definition, e.Event, e.Data)
+						if err != nil && ed.handleDeliveryFailure(e.Event, err) {
+							// The error handling policy acknowledged the failed event, so we carry on with the rest
+							err = nil
+							continue
+						}
 					}
 					// ... if we've triggered into an error scenario, we need to nack immediately for this and all the rest of the events
 					if err != nil {
@@ -372,13 +608,18 @@ func (ed *eventDispatcher) deliverEvents() {
 			// In batch mode we do one dispatch of the whole set as one
 			if ed.batch {
 				// Only attempt to deliver if we're in a non error case (enrich might have failed above)
+				deliveryFailed := false
 				if err == nil {
 					err = ed.transport.BatchDeliveryRequest(ed.ctx, ed.connID, ed.subscription.definition, eventsWithData)
+					deliveryFailed = err != nil
 				}
-				// If we're in an error case we have to nack everything immediately
+				// If we're in an error case we have to nack everything immediately, unless the error
+				// handling policy acknowledges the events that failed delivery
 				if err != nil {
 					for _, e := range events {
-						ed.deliveryResponse(&core.EventDeliveryResponse{ID: e.Event.ID, Rejected: true})
+						if !deliveryFailed || !ed.handleDeliveryFailure(e, err) {
+							ed.deliveryResponse(&core.EventDeliveryResponse{ID: e.Event.ID, Rejected: true})
+						}
 					}
 				}
 			}
@@ -389,6 +630,44 @@ func (ed *eventDispatcher) deliverEvents() {
 	}
 }
 
+// handleDeliveryFailure applies the error handling policy of the subscription to an event the transport
+// failed to deliver, returning true if the event has been acknowledged so delivery can continue.
+// Transports reject the skip and deadletter policies in ValidateOptions unless a failure from them means
+// the event could not be processed, rather than the connection to the application being lost.
+func (ed *eventDispatcher) handleDeliveryFailure(event *core.EventDelivery, deliveryErr error) bool {
+	l := log.L(ed.ctx)
+	def := ed.subscription.definition
+	if def.Options.ErrorHandling == nil {
+		return false
+	}
+
+	switch *def.Options.ErrorHandling {
+	case core.SubOptsErrorHandlingSkip:
+		l.Warnf("Skipping %s event %.10d/%s after delivery failure: %s", ed.transport.Name(), event.Sequence, event.ID, deliveryErr)
+	case core.SubOptsErrorHandlingDeadLetter:
+		err := ed.database.InsertDeadLetter(ed.ctx, &core.DeadLetter{
+			ID:            fftypes.NewUUID(),
+			Namespace:     ed.namespace,
+			Subscription:  def.ID,
+			Event:         event.ID,
+			EventSequence: event.Sequence,
+			Error:         deliveryErr.Error(),
+			Created:       fftypes.Now(),
+		})
+		if err != nil {
+			// We must not lose the event, so we block until the dead letter can be recorded
+			l.Errorf("Failed to record dead letter for %s event %.10d/%s: %s", ed.transport.Name(), event.Sequence, event.ID, err)
+			return false
+		}
+		l.Warnf("Recorded dead letter for %s event %.10d/%s after delivery failure: %s", ed.transport.Name(), event.Sequence, event.ID, deliveryErr)
+	default:
+		return false
+	}
+
+	ed.deliveryResponse(&core.EventDeliveryResponse{ID: event.ID, Rejected: false, Info: deliveryErr.Error()})
+	return true
+}
+
 func (ed *eventDispatcher) deliveryResponse(response *core.EventDeliveryResponse) {
 	l := log.L(ed.ctx)
 
@@ -23,6 +23,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly/internal/cache"
@@ -96,6 +98,28 @@ func TestEventDispatcherStartStop(t *testing.T) {
 	ed.close()
 }
 
+func TestEventDispatcherBatchTimeoutByType(t *testing.T) {
+	coreconfig.Reset()
+	config.Set(coreconfig.EventDispatcherBatchTimeoutByType, map[string]interface{}{
+		"message_confirmed":         "100ms",
+		"transaction_submitted":     50,
+		"token_pool_confirmed":      "bad",
+		"blockchain_event_received": "0s",
+	})
+	ed, cancel := newTestEventDispatcher(&subscription{
+		dispatcherElection: make(chan bool, 1),
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{Namespace: "ns1", Name: "sub1"},
+		},
+	})
+	defer cancel()
+
+	assert.Equal(t, map[core.EventType]time.Duration{
+		core.EventTypeMessageConfirmed:     100 * time.Millisecond,
+		core.EventTypeTransactionSubmitted: 50 * time.Millisecond,
+	}, ed.eventPoller.conf.eventBatchTimeoutOverrides)
+}
+
 func TestEventDispatcherStartStopBatched(t *testing.T) {
 	ten := uint16(10)
 	oldest := core.SubOptsFirstEventOldest
@@ -768,6 +792,44 @@ func TestFilterEventsMatch(t *testing.T) {
 	assert.Equal(t, *id6, *matched[0].ID)
 }
 
+func TestFilterEventsMessageTag(t *testing.T) {
+
+	sub := &subscription{
+		definition: &core.Subscription{},
+		messageFilter: &messageFilter{
+			messageTag: "invoice",
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	id1 := fftypes.NewUUID()
+	id2 := fftypes.NewUUID()
+	id3 := fftypes.NewUUID()
+	matched := ed.filterEvents([]*core.EventDelivery{
+		{
+			EnrichedEvent: core.EnrichedEvent{
+				Event:   core.Event{ID: id1, Type: core.EventTypeMessageConfirmed},
+				Message: &core.Message{Tags: fftypes.FFStringArray{"order", "invoice"}},
+			},
+		},
+		{
+			EnrichedEvent: core.EnrichedEvent{
+				Event:   core.Event{ID: id2, Type: core.EventTypeMessageConfirmed},
+				Message: &core.Message{Header: core.MessageHeader{Tag: "invoice"}, Tags: fftypes.FFStringArray{"invoices"}},
+			},
+		},
+		{
+			EnrichedEvent: core.EnrichedEvent{
+				Event:       core.Event{ID: id3, Type: core.EventTypeTransactionSubmitted},
+				Transaction: &core.Transaction{Type: core.TransactionTypeBatchPin},
+			},
+		},
+	})
+	assert.Equal(t, 1, len(matched))
+	assert.Equal(t, *id1, *matched[0].ID)
+}
+
 func TestEnrichTransactionEvents(t *testing.T) {
 	log.SetLevel("debug")
 	sub := &subscription{
@@ -1046,6 +1108,68 @@ func TestBufferedDeliveryNackRewind(t *testing.T) {
 	assert.Equal(t, int64(100000), ed.eventPoller.pollingOffset)
 }
 
+func TestBufferedDeliveryDrainWaitsForInflight(t *testing.T) {
+
+	sub := &subscription{
+		definition: &core.Subscription{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	go ed.deliverEvents()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mei := ed.transport.(*eventsmocks.Plugin)
+	mdi.On("GetDataRefs", mock.Anything, mock.Anything).Return(nil, nil, nil)
+
+	delivered := make(chan struct{})
+	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliver.RunFn = func(a mock.Arguments) {
+		close(delivered) // only the first event should be delivered
+	}
+
+	bdDone := make(chan struct{})
+	ev1 := fftypes.NewUUID()
+	ev2 := fftypes.NewUUID()
+	go func() {
+		repoll, err := ed.bufferedDelivery([]core.LocallySequenced{
+			&core.Event{ID: ev1, Sequence: 100001},
+			&core.Event{ID: ev2, Sequence: 100002},
+		})
+		assert.NoError(t, err)
+		assert.False(t, repoll)
+		close(bdDone)
+	}()
+
+	<-delivered
+	drained := ed.startDrain()
+	select {
+	case <-drained:
+		assert.Fail(t, "drained with a delivery in flight")
+	default:
+	}
+	ed.deliveryResponse(&core.EventDeliveryResponse{
+		ID: ev1,
+	})
+
+	<-drained
+	<-bdDone
+	assert.Empty(t, ed.inflightIDs())
+	assert.Equal(t, int64(100001), ed.eventPoller.pollingOffset)
+}
+
+func TestStartDrainNothingInflight(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	<-ed.startDrain()
+	assert.True(t, ed.isDraining())
+	ed.cancelDrain()
+	assert.False(t, ed.isDraining())
+}
+
 func TestBufferedDeliveryFailNack(t *testing.T) {
 	log.SetLevel("trace")
 
@@ -1129,6 +1253,158 @@ func TestAckClosed(t *testing.T) {
 	ed.deliveryResponse(&core.EventDeliveryResponse{ID: id1})
 }
 
+func newTestStartupModeSub(mode core.SubOptsStartupMode, firstEvent core.SubOptsFirstEvent) *subscription {
+	return &subscription{
+		dispatcherElection: make(chan bool, 1),
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{Namespace: "ns1", Name: "sub1", ID: fftypes.NewUUID()},
+			Options: core.SubscriptionOptions{
+				SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+					FirstEvent:  &firstEvent,
+					StartupMode: &mode,
+				},
+			},
+		},
+	}
+}
+
+func TestApplyStartupModeSkip(t *testing.T) {
+	sub := newTestStartupModeSub(core.SubOptsStartupModeSkip, "0")
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.definition.ID.String()).Return(&core.Offset{Current: 100}, nil).Once()
+	mdi.On("GetEvents", mock.Anything, "ns1", mock.Anything).Return([]*core.Event{
+		{Sequence: 12345},
+	}, nil, nil).Once()
+	mdi.On("UpsertOffset", mock.Anything, mock.MatchedBy(func(offset *core.Offset) bool {
+		return offset.Type == core.OffsetTypeSubscription &&
+			offset.Name == sub.definition.ID.String() &&
+			offset.Current == 12345
+	}), true).Return(nil).Once()
+
+	err := ed.applyStartupMode()
+	assert.NoError(t, err)
+
+	// A later election, for example on reconnect, leaves the offset alone
+	err = ed.applyStartupMode()
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestApplyStartupModeNewSubscription(t *testing.T) {
+	sub := newTestStartupModeSub(core.SubOptsStartupModeSkip, "0")
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.definition.ID.String()).Return(nil, nil).Once()
+
+	err := ed.applyStartupMode()
+	assert.NoError(t, err)
+	assert.True(t, sub.startupModeApplied)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestApplyStartupModeGetOffsetFail(t *testing.T) {
+	sub := newTestStartupModeSub(core.SubOptsStartupModeSkip, "0")
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.definition.ID.String()).Return(nil, fmt.Errorf("pop"))
+
+	err := ed.applyStartupMode()
+	assert.EqualError(t, err, "pop")
+	assert.False(t, sub.startupModeApplied)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestApplyStartupModeUpsertFail(t *testing.T) {
+	sub := newTestStartupModeSub(core.SubOptsStartupModeReplay, "42")
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.definition.ID.String()).Return(&core.Offset{Current: 100}, nil)
+	mdi.On("UpsertOffset", mock.Anything, mock.Anything, true).Return(fmt.Errorf("pop"))
+
+	err := ed.applyStartupMode()
+	assert.EqualError(t, err, "pop")
+	assert.False(t, sub.startupModeApplied)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestApplyStartupModeReplay(t *testing.T) {
+	sub := newTestStartupModeSub(core.SubOptsStartupModeReplay, "42")
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.definition.ID.String()).Return(&core.Offset{Current: 100}, nil)
+	mdi.On("UpsertOffset", mock.Anything, mock.MatchedBy(func(offset *core.Offset) bool {
+		return offset.Current == 42
+	}), true).Return(nil)
+
+	err := ed.applyStartupMode()
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestApplyStartupModeResume(t *testing.T) {
+	sub := newTestStartupModeSub(core.SubOptsStartupModeResume, "42")
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	err := ed.applyStartupMode()
+	assert.NoError(t, err)
+
+	ed.subscription.definition.Options.StartupMode = nil
+	err = ed.applyStartupMode()
+	assert.NoError(t, err)
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.AssertExpectations(t)
+}
+
+func TestApplyStartupModeFail(t *testing.T) {
+	sub := newTestStartupModeSub(core.SubOptsStartupModeSkip, "0")
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.definition.ID.String()).Return(&core.Offset{Current: 100}, nil)
+	mdi.On("GetEvents", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := ed.applyStartupMode()
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestEventDispatcherStartupModeFailClosed(t *testing.T) {
+	sub := newTestStartupModeSub(core.SubOptsStartupModeSkip, "0")
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.definition.ID.String()).Return(&core.Offset{Current: 100}, nil)
+	mdi.On("GetEvents", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		ed.cancelCtx()
+	})
+
+	ed.start()
+	<-ed.closed
+
+	mdi.AssertExpectations(t)
+}
+
 func TestGetEvents(t *testing.T) {
 	ag := newTestAggregator()
 	defer ag.cleanup(t)
@@ -1155,6 +1431,212 @@ func TestGetEvents(t *testing.T) {
 	assert.Equal(t, int64(12345), lc[0].LocalSequence())
 }
 
+func TestGetEventsFromArchive(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{
+				Namespace: "ns1",
+			},
+		},
+	}
+
+	ed, cancel := newTestEventDispatcher(sub)
+	cancel()
+	ed.archiveEnabled = true
+	ctx := context.Background()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetEvents", ctx, "ns1", mock.Anything).Return([]*core.Event{
+		{Sequence: 200},
+		{Sequence: 201},
+	}, nil, nil)
+	mdi.On("GetArchivedEvents", ctx, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.String() == " sort=-sequence limit=1"
+	})).Return([]*core.Event{
+		{Sequence: 150},
+	}, nil, nil).Once()
+	mdi.On("GetArchivedEvents", ctx, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.String() == "( ( sequence >> 100 ) ) && ( sequence << 200 ) sort=sequence limit=3"
+	})).Return([]*core.Event{
+		{Sequence: 101},
+		{Sequence: 102},
+	}, nil, nil).Once()
+
+	fb := database.EventQueryFactory.NewFilter(ctx)
+	lc, err := ed.getEvents(ctx, fb.And(fb.Gt("sequence", 100)).Sort("sequence").Limit(3), 100)
+	assert.NoError(t, err)
+	assert.Len(t, lc, 3)
+	assert.Equal(t, int64(101), lc[0].LocalSequence())
+	assert.Equal(t, int64(102), lc[1].LocalSequence())
+	assert.Equal(t, int64(200), lc[2].LocalSequence())
+	assert.Equal(t, int64(150), ed.archiveHighWater)
+	mdi.AssertExpectations(t)
+}
+
+func TestGetEventsFromArchiveCachedHighWater(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{
+				Namespace: "ns1",
+			},
+		},
+	}
+
+	ed, cancel := newTestEventDispatcher(sub)
+	cancel()
+	ed.archiveEnabled = true
+	ed.archiveHighWater = 150
+	ctx := context.Background()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetEvents", ctx, "ns1", mock.Anything).Return([]*core.Event{}, nil, nil)
+	mdi.On("GetArchivedEvents", ctx, "ns1", mock.Anything).Return([]*core.Event{
+		{Sequence: 101},
+	}, nil, nil).Once()
+
+	lc, err := ed.getEvents(ctx, database.EventQueryFactory.NewFilter(ctx).Gt("sequence", 100), 100)
+	assert.NoError(t, err)
+	assert.Len(t, lc, 1)
+	assert.Equal(t, int64(101), lc[0].LocalSequence())
+	mdi.AssertExpectations(t)
+}
+
+func TestGetEventsPastArchiveHighWater(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{
+				Namespace: "ns1",
+			},
+		},
+	}
+
+	ed, cancel := newTestEventDispatcher(sub)
+	cancel()
+	ed.archiveEnabled = true
+	ctx := context.Background()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetEvents", ctx, "ns1", mock.Anything).Return([]*core.Event{
+		{Sequence: 200},
+	}, nil, nil)
+	mdi.On("GetArchivedEvents", ctx, "ns1", mock.Anything).Return([]*core.Event{
+		{Sequence: 50},
+	}, nil, nil).Once()
+
+	lc, err := ed.getEvents(ctx, database.EventQueryFactory.NewFilter(ctx).Gt("sequence", 100), 100)
+	assert.NoError(t, err)
+	assert.Len(t, lc, 1)
+	assert.Equal(t, int64(200), lc[0].LocalSequence())
+	mdi.AssertExpectations(t)
+}
+
+func TestGetEventsFromArchiveBadFilter(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{
+				Namespace: "ns1",
+			},
+		},
+	}
+
+	ed, cancel := newTestEventDispatcher(sub)
+	cancel()
+	ed.archiveEnabled = true
+	ed.archiveHighWater = 150
+	ctx := context.Background()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetEvents", ctx, "ns1", mock.Anything).Return([]*core.Event{}, nil, nil)
+	mdi.On("GetArchivedEvents", ctx, "ns1", mock.Anything).Return([]*core.Event{
+		{Sequence: 101},
+	}, nil, nil).Once()
+
+	_, err := ed.getEvents(ctx, database.EventQueryFactory.NewFilter(ctx).Gt("wrong", 100), 100)
+	assert.Regexp(t, "FF00142", err)
+	mdi.AssertExpectations(t)
+}
+
+func TestGetEventsArchiveEmpty(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{
+				Namespace: "ns1",
+			},
+		},
+	}
+
+	ed, cancel := newTestEventDispatcher(sub)
+	cancel()
+	ed.archiveEnabled = true
+	ctx := context.Background()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetEvents", ctx, "ns1", mock.Anything).Return([]*core.Event{
+		{Sequence: 200},
+	}, nil, nil)
+	mdi.On("GetArchivedEvents", ctx, "ns1", mock.Anything).Return([]*core.Event{}, nil, nil).Once()
+
+	lc, err := ed.getEvents(ctx, database.EventQueryFactory.NewFilter(ctx).Gt("sequence", 100), 100)
+	assert.NoError(t, err)
+	assert.Len(t, lc, 1)
+	assert.Equal(t, int64(200), lc[0].LocalSequence())
+	mdi.AssertExpectations(t)
+}
+
+func TestGetEventsNoGapSkipsArchive(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{
+				Namespace: "ns1",
+			},
+		},
+	}
+
+	ed, cancel := newTestEventDispatcher(sub)
+	cancel()
+	ed.archiveEnabled = true
+	ctx := context.Background()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetEvents", ctx, "ns1", mock.Anything).Return([]*core.Event{
+		{Sequence: 101},
+	}, nil, nil)
+
+	lc, err := ed.getEvents(ctx, database.EventQueryFactory.NewFilter(ctx).Gt("sequence", 100), 100)
+	assert.NoError(t, err)
+	assert.Len(t, lc, 1)
+	mdi.AssertExpectations(t)
+}
+
+func TestGetEventsArchiveFail(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{
+				Namespace: "ns1",
+			},
+		},
+	}
+
+	ed, cancel := newTestEventDispatcher(sub)
+	cancel()
+	ed.archiveEnabled = true
+	ctx := context.Background()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetEvents", ctx, "ns1", mock.Anything).Return([]*core.Event{}, nil, nil)
+	mdi.On("GetArchivedEvents", ctx, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := ed.getEvents(ctx, database.EventQueryFactory.NewFilter(ctx).Gt("sequence", 100), 100)
+	assert.EqualError(t, err, "pop")
+
+	ed.archiveHighWater = 150
+	_, err = ed.getEvents(ctx, database.EventQueryFactory.NewFilter(ctx).Gt("sequence", 100), 100)
+	assert.EqualError(t, err, "pop")
+	mdi.AssertExpectations(t)
+}
+
 func TestDeliverEventsWithDataFail(t *testing.T) {
 	yes := true
 	sub := &subscription{
@@ -1303,3 +1785,238 @@ func TestEventDeliveryBatch(t *testing.T) {
 	mbm.AssertExpectations(t)
 	mms.AssertExpectations(t)
 }
+
+func TestMatchesNotification(t *testing.T) {
+	sub := &subscription{
+		definition:   &core.Subscription{},
+		eventMatcher: regexp.MustCompile(fmt.Sprintf("^%s$", core.EventTypeMessageConfirmed)),
+		topicFilter:  regexp.MustCompile("topic1"),
+	}
+	assert.True(t, sub.MatchesNotification(&core.Event{Type: core.EventTypeMessageConfirmed, Topic: "topic1"}))
+	assert.False(t, sub.MatchesNotification(&core.Event{Type: core.EventTypeMessageRejected, Topic: "topic1"}))
+	assert.False(t, sub.MatchesNotification(&core.Event{Type: core.EventTypeMessageConfirmed, Topic: "topic2"}))
+	assert.True(t, (&subscription{}).MatchesNotification(&core.Event{Type: core.EventTypeMessageRejected}))
+}
+
+func newTestErrorHandlingDispatcher(t *testing.T, errorHandling core.SubOptsErrorHandling, batch bool) (*eventDispatcher, []*core.EventDelivery, func()) {
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+			Options: core.SubscriptionOptions{
+				SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+					Batch:         &batch,
+					ErrorHandling: &errorHandling,
+				},
+			},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+
+	mei := ed.transport.(*eventsmocks.Plugin)
+	if batch {
+		mei.On("BatchDeliveryRequest", ed.ctx, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	} else {
+		mei.On("DeliveryRequest", ed.ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	}
+
+	events := []*core.EventDelivery{
+		{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID(), Sequence: 1}}},
+		{EnrichedEvent: core.EnrichedEvent{Event: core.Event{ID: fftypes.NewUUID(), Sequence: 2}}},
+	}
+	for _, e := range events {
+		ed.inflight[*e.ID] = &e.Event
+	}
+	return ed, events, func() {
+		cancel()
+		mei.AssertExpectations(t)
+	}
+}
+
+func TestDeliverEventsErrorHandlingBlock(t *testing.T) {
+	ed, events, cancel := newTestErrorHandlingDispatcher(t, core.SubOptsErrorHandlingBlock, false)
+	defer cancel()
+
+	ed.eventDelivery <- events
+	go ed.deliverEvents()
+
+	an := <-ed.acksNacks
+	assert.True(t, an.isNack)
+	assert.Equal(t, *events[0].ID, an.id)
+	an = <-ed.acksNacks
+	assert.True(t, an.isNack)
+	assert.Equal(t, *events[1].ID, an.id)
+}
+
+func TestDeliverEventsErrorHandlingSkip(t *testing.T) {
+	ed, events, cancel := newTestErrorHandlingDispatcher(t, core.SubOptsErrorHandlingSkip, false)
+	defer cancel()
+
+	ed.eventDelivery <- events
+	go ed.deliverEvents()
+
+	an := <-ed.acksNacks
+	assert.False(t, an.isNack)
+	assert.Equal(t, *events[0].ID, an.id)
+	an = <-ed.acksNacks
+	assert.False(t, an.isNack)
+	assert.Equal(t, *events[1].ID, an.id)
+}
+
+func TestDeliverEventsErrorHandlingDeadLetter(t *testing.T) {
+	ed, events, cancel := newTestErrorHandlingDispatcher(t, core.SubOptsErrorHandlingDeadLetter, false)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	for _, e := range events {
+		event := e
+		mdi.On("InsertDeadLetter", ed.ctx, mock.MatchedBy(func(dl *core.DeadLetter) bool {
+			return dl.Event.Equals(event.ID) &&
+				dl.EventSequence == event.Sequence &&
+				dl.Subscription.Equals(ed.subscription.definition.ID) &&
+				dl.Namespace == "ns1" &&
+				dl.Error == "pop"
+		})).Return(nil)
+	}
+
+	ed.eventDelivery <- events
+	go ed.deliverEvents()
+
+	an := <-ed.acksNacks
+	assert.False(t, an.isNack)
+	an = <-ed.acksNacks
+	assert.False(t, an.isNack)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestDeliverEventsErrorHandlingDeadLetterFail(t *testing.T) {
+	ed, events, cancel := newTestErrorHandlingDispatcher(t, core.SubOptsErrorHandlingDeadLetter, false)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("InsertDeadLetter", ed.ctx, mock.Anything).Return(fmt.Errorf("pop")).Once()
+
+	ed.eventDelivery <- events
+	go ed.deliverEvents()
+
+	an := <-ed.acksNacks
+	assert.True(t, an.isNack)
+	an = <-ed.acksNacks
+	assert.True(t, an.isNack)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestDeliverEventsErrorHandlingDeadLetterBatch(t *testing.T) {
+	ed, events, cancel := newTestErrorHandlingDispatcher(t, core.SubOptsErrorHandlingDeadLetter, true)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("InsertDeadLetter", ed.ctx, mock.Anything).Return(nil).Once()
+	mdi.On("InsertDeadLetter", ed.ctx, mock.Anything).Return(fmt.Errorf("pop")).Once()
+
+	ed.eventDelivery <- events
+	go ed.deliverEvents()
+
+	an := <-ed.acksNacks
+	assert.False(t, an.isNack)
+	assert.Equal(t, *events[0].ID, an.id)
+	an = <-ed.acksNacks
+	assert.True(t, an.isNack)
+	assert.Equal(t, *events[1].ID, an.id)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetEventsInNamespaces(t *testing.T) {
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{
+				Namespace: "ns1",
+			},
+			Filter: core.SubscriptionFilter{
+				Namespaces: []string{"ns2", "ns1", "ns2", "ns3"},
+			},
+		},
+	}
+
+	ed, cancel := newTestEventDispatcher(sub)
+	cancel()
+	ed.archiveEnabled = true
+	ctx := context.Background()
+	assert.Equal(t, []string{"ns1", "ns2", "ns3"}, ed.namespaces)
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetEventsInNamespaces", ctx, []string{"ns1", "ns2", "ns3"}, mock.Anything).Return([]*core.Event{
+		{Sequence: 200, Namespace: "ns2"},
+	}, nil, nil)
+	mdi.On("GetArchivedEventsInNamespaces", ctx, []string{"ns1", "ns2", "ns3"}, mock.Anything).Return([]*core.Event{
+		{Sequence: 101, Namespace: "ns3"},
+	}, nil, nil).Twice()
+
+	lc, err := ed.getEvents(ctx, database.EventQueryFactory.NewFilter(ctx).Gt("sequence", 100), 100)
+	assert.NoError(t, err)
+	assert.Len(t, lc, 2)
+	assert.Equal(t, int64(101), lc[0].LocalSequence())
+	assert.Equal(t, int64(200), lc[1].LocalSequence())
+	mdi.AssertExpectations(t)
+}
+
+func TestDeliverEventsWithDataForeignNamespace(t *testing.T) {
+	yes := true
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{
+				Namespace: "ns1",
+			},
+			Filter: core.SubscriptionFilter{
+				Namespaces: []string{"ns2"},
+			},
+			Options: core.SubscriptionOptions{
+				SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+					WithData: &yes,
+				},
+			},
+		},
+	}
+
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	dataID := fftypes.NewUUID()
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetDataByID", ed.ctx, "ns2", dataID, true).Return(&core.Data{ID: dataID}, nil)
+
+	id1 := fftypes.NewUUID()
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:        id1,
+				Namespace: "ns2",
+			},
+			Message: &core.Message{
+				Header: core.MessageHeader{
+					ID: fftypes.NewUUID(),
+				},
+				Data: core.DataRefs{
+					{ID: dataID},
+				},
+			},
+		},
+	}
+	delivered := make(chan struct{})
+	mei := ed.transport.(*eventsmocks.Plugin)
+	mei.On("DeliveryRequest", ed.ctx, mock.Anything, mock.Anything, mock.Anything, mock.MatchedBy(func(data core.DataArray) bool {
+		return len(data) == 1 && data[0].ID.Equals(dataID)
+	})).Return(nil).Run(func(args mock.Arguments) {
+		close(delivered)
+	})
+
+	ed.eventDelivery <- []*core.EventDelivery{event}
+	ed.inflight[*id1] = &core.Event{ID: id1}
+	go ed.deliverEvents()
+
+	<-delivered
+	mdi.AssertExpectations(t)
+	mei.AssertExpectations(t)
+}
@@ -61,67 +61,91 @@ func (em *eventEnricher) enrichEvent(ctx context.Context, event *core.Event) (*c
 		Event: *event,
 	}
 
+	// Subscriptions can span multiple namespaces. The caches only hold objects from this namespace,
+	// so objects referenced by events from other namespaces are read directly from the database.
+	ns := em.namespace
+	foreign := event.Namespace != "" && event.Namespace != em.namespace
+	if foreign {
+		ns = event.Namespace
+	}
+
+	var err error
 	switch event.Type {
 	case core.EventTypeTransactionSubmitted:
-		tx, err := em.txHelper.GetTransactionByIDCached(ctx, event.Reference)
+		if foreign {
+			e.Transaction, err = em.database.GetTransactionByID(ctx, ns, event.Reference)
+		} else {
+			e.Transaction, err = em.txHelper.GetTransactionByIDCached(ctx, event.Reference)
+		}
 		if err != nil {
 			return nil, err
 		}
-		e.Transaction = tx
-	case core.EventTypeMessageConfirmed, core.EventTypeMessageRejected:
-		msg, _, _, err := em.data.GetMessageWithDataCached(ctx, event.Reference)
+	case core.EventTypeMessageConfirmed, core.EventTypeMessageRejected, core.EventTypeMessagePinned, core.EventTypeMessageExpired, core.EventTypeMessageDelivered:
+		if foreign {
+			e.Message, err = em.database.GetMessageByID(ctx, ns, event.Reference)
+		} else {
+			e.Message, _, _, err = em.data.GetMessageWithDataCached(ctx, event.Reference)
+		}
 		if err != nil {
 			return nil, err
 		}
-		e.Message = msg
 	case core.EventTypeBlockchainEventReceived:
-		be, err := em.txHelper.GetBlockchainEventByIDCached(ctx, event.Reference)
+		if foreign {
+			e.BlockchainEvent, err = em.database.GetBlockchainEventByID(ctx, ns, event.Reference)
+		} else {
+			e.BlockchainEvent, err = em.txHelper.GetBlockchainEventByIDCached(ctx, event.Reference)
+		}
 		if err != nil {
 			return nil, err
 		}
-		e.BlockchainEvent = be
 	case core.EventTypeContractAPIConfirmed:
-		contractAPI, err := em.database.GetContractAPIByID(ctx, em.namespace, event.Reference)
+		contractAPI, err := em.database.GetContractAPIByID(ctx, ns, event.Reference)
 		if err != nil {
 			return nil, err
 		}
 		e.ContractAPI = contractAPI
 	case core.EventTypeContractInterfaceConfirmed:
-		contractInterface, err := em.database.GetFFIByID(ctx, em.namespace, event.Reference)
+		contractInterface, err := em.database.GetFFIByID(ctx, ns, event.Reference)
 		if err != nil {
 			return nil, err
 		}
 		e.ContractInterface = contractInterface
 	case core.EventTypeDatatypeConfirmed:
-		dt, err := em.database.GetDatatypeByID(ctx, em.namespace, event.Reference)
+		dt, err := em.database.GetDatatypeByID(ctx, ns, event.Reference)
 		if err != nil {
 			return nil, err
 		}
 		e.Datatype = dt
 	case core.EventTypeIdentityConfirmed, core.EventTypeIdentityUpdated:
-		identity, err := em.database.GetIdentityByID(ctx, em.namespace, event.Reference)
+		identity, err := em.database.GetIdentityByID(ctx, ns, event.Reference)
 		if err != nil {
 			return nil, err
 		}
 		e.Identity = identity
 	case core.EventTypePoolConfirmed:
-		tokenPool, err := em.database.GetTokenPoolByID(ctx, em.namespace, event.Reference)
+		tokenPool, err := em.database.GetTokenPoolByID(ctx, ns, event.Reference)
 		if err != nil {
 			return nil, err
 		}
 		e.TokenPool = tokenPool
 	case core.EventTypeApprovalConfirmed:
-		approval, err := em.database.GetTokenApprovalByID(ctx, em.namespace, event.Reference)
+		approval, err := em.database.GetTokenApprovalByID(ctx, ns, event.Reference)
 		if err != nil {
 			return nil, err
 		}
 		e.TokenApproval = approval
 	case core.EventTypeTransferConfirmed:
-		transfer, err := em.database.GetTokenTransferByID(ctx, em.namespace, event.Reference)
+		transfer, err := em.database.GetTokenTransferByID(ctx, ns, event.Reference)
 		if err != nil {
 			return nil, err
 		}
 		e.TokenTransfer = transfer
+	case core.EventTypeDeadEvent, core.EventTypeContextForceUnblocked:
+		deadEvent, err := em.database.GetDeadEventByID(ctx, ns, event.Reference)
+		if err != nil {
+			return nil, err
+		}
+		e.DeadEvent = deadEvent
 	case core.EventTypeApprovalOpFailed,
 		core.EventTypeTransferOpFailed,
 		core.EventTypePoolOpFailed,
@@ -129,11 +153,33 @@ func (em *eventEnricher) enrichEvent(ctx context.Context, event *core.Event) (*c
 		core.EventTypeBlockchainInvokeOpSucceeded,
 		core.EventTypeBlockchainContractDeployOpFailed,
 		core.EventTypeBlockchainContractDeployOpSucceeded:
-		operation, err := em.operations.GetOperationByIDCached(ctx, event.Reference)
+		if foreign {
+			e.Operation, err = em.database.GetOperationByID(ctx, ns, event.Reference)
+		} else {
+			e.Operation, err = em.operations.GetOperationByIDCached(ctx, event.Reference)
+		}
 		if err != nil {
 			return nil, err
 		}
-		e.Operation = operation
 	}
 	return e, nil
 }
+
+// getForeignMessageData reads the data of a message from another namespace directly from the database,
+// as the data manager only resolves data within this namespace
+func (em *eventEnricher) getForeignMessageData(ctx context.Context, ns string, msg *core.Message) (core.DataArray, error) {
+	data := make(core.DataArray, 0, len(msg.Data))
+	for _, dataRef := range msg.Data {
+		if dataRef == nil || dataRef.ID == nil {
+			continue
+		}
+		d, err := em.database.GetDataByID(ctx, ns, dataRef.ID, true)
+		if err != nil {
+			return nil, err
+		}
+		if d != nil {
+			data = append(data, d)
+		}
+	}
+	return data, nil
+}
@@ -144,6 +144,61 @@ func TestEnrichMessageRejected(t *testing.T) {
 	assert.Equal(t, ref1, enriched.Message.Header.ID)
 }
 
+func TestEnrichMessageExpired(t *testing.T) {
+	em := newTestEventEnricher()
+	ctx := context.Background()
+
+	// Setup the IDs
+	ref1 := fftypes.NewUUID()
+	ev1 := fftypes.NewUUID()
+
+	// Setup enrichment
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, ref1).Return(&core.Message{
+		Header: core.MessageHeader{ID: ref1},
+		State:  core.MessageStateExpired,
+	}, nil, true, nil)
+
+	event := &core.Event{
+		ID:        ev1,
+		Type:      core.EventTypeMessageExpired,
+		Reference: ref1,
+	}
+
+	enriched, err := em.enrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Equal(t, ref1, enriched.Message.Header.ID)
+	assert.Equal(t, core.MessageStateExpired, enriched.Message.State)
+}
+
+func TestEnrichMessagePinned(t *testing.T) {
+	em := newTestEventEnricher()
+	ctx := context.Background()
+
+	// Setup the IDs
+	ref1 := fftypes.NewUUID()
+	ev1 := fftypes.NewUUID()
+	tx1 := fftypes.NewUUID()
+
+	// Setup enrichment
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, ref1).Return(&core.Message{
+		Header: core.MessageHeader{ID: ref1},
+	}, nil, true, nil)
+
+	event := &core.Event{
+		ID:          ev1,
+		Type:        core.EventTypeMessagePinned,
+		Reference:   ref1,
+		Transaction: tx1,
+	}
+
+	enriched, err := em.enrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Equal(t, ref1, enriched.Message.Header.ID)
+	assert.Equal(t, tx1, enriched.Event.Transaction)
+}
+
 func TestEnrichTxSubmitted(t *testing.T) {
 	em := newTestEventEnricher()
 	ctx := context.Background()
@@ -619,6 +674,75 @@ func TestEnrichTokenTransferConfirmedFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestEnrichDeadEvent(t *testing.T) {
+	em := newTestEventEnricher()
+	ctx := context.Background()
+
+	// Setup the IDs
+	ref1 := fftypes.NewUUID()
+	ev1 := fftypes.NewUUID()
+
+	// Setup enrichment
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetDeadEventByID", mock.Anything, "ns1", ref1).Return(&core.DeadEvent{
+		ID: ref1,
+	}, nil)
+
+	event := &core.Event{
+		ID:        ev1,
+		Type:      core.EventTypeDeadEvent,
+		Reference: ref1,
+	}
+
+	enriched, err := em.enrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Equal(t, ref1, enriched.DeadEvent.ID)
+}
+
+func TestEnrichContextForceUnblocked(t *testing.T) {
+	em := newTestEventEnricher()
+	ctx := context.Background()
+
+	ref1 := fftypes.NewUUID()
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetDeadEventByID", mock.Anything, "ns1", ref1).Return(&core.DeadEvent{
+		ID:    ref1,
+		Error: "Force unblocked by principal 'admin1'",
+	}, nil)
+
+	event := &core.Event{
+		ID:        fftypes.NewUUID(),
+		Type:      core.EventTypeContextForceUnblocked,
+		Reference: ref1,
+	}
+
+	enriched, err := em.enrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Equal(t, ref1, enriched.DeadEvent.ID)
+}
+
+func TestEnrichDeadEventFail(t *testing.T) {
+	em := newTestEventEnricher()
+	ctx := context.Background()
+
+	// Setup the IDs
+	ref1 := fftypes.NewUUID()
+	ev1 := fftypes.NewUUID()
+
+	// Setup enrichment
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetDeadEventByID", mock.Anything, "ns1", ref1).Return(nil, fmt.Errorf("pop"))
+
+	event := &core.Event{
+		ID:        ev1,
+		Type:      core.EventTypeDeadEvent,
+		Reference: ref1,
+	}
+
+	_, err := em.enrichEvent(ctx, event)
+	assert.EqualError(t, err, "pop")
+}
+
 func TestEnrichOperationFail(t *testing.T) {
 	em := newTestEventEnricher()
 	ctx := context.Background()
@@ -700,3 +824,97 @@ func TestEnrichEventsOK(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 2, len(result))
 }
+
+func TestEnrichForeignNamespace(t *testing.T) {
+	em := newTestEventEnricher()
+	ctx := context.Background()
+	mdi := em.database.(*databasemocks.Plugin)
+
+	ref1 := fftypes.NewUUID()
+	mdi.On("GetMessageByID", ctx, "ns2", ref1).Return(&core.Message{Header: core.MessageHeader{ID: ref1}}, nil)
+	enriched, err := em.enrichEvent(ctx, &core.Event{Namespace: "ns2", Type: core.EventTypeMessageConfirmed, Reference: ref1})
+	assert.NoError(t, err)
+	assert.Equal(t, ref1, enriched.Message.Header.ID)
+
+	ref2 := fftypes.NewUUID()
+	mdi.On("GetTransactionByID", ctx, "ns2", ref2).Return(&core.Transaction{ID: ref2}, nil)
+	enriched, err = em.enrichEvent(ctx, &core.Event{Namespace: "ns2", Type: core.EventTypeTransactionSubmitted, Reference: ref2})
+	assert.NoError(t, err)
+	assert.Equal(t, ref2, enriched.Transaction.ID)
+
+	ref3 := fftypes.NewUUID()
+	mdi.On("GetBlockchainEventByID", ctx, "ns2", ref3).Return(&core.BlockchainEvent{ID: ref3}, nil)
+	enriched, err = em.enrichEvent(ctx, &core.Event{Namespace: "ns2", Type: core.EventTypeBlockchainEventReceived, Reference: ref3})
+	assert.NoError(t, err)
+	assert.Equal(t, ref3, enriched.BlockchainEvent.ID)
+
+	ref4 := fftypes.NewUUID()
+	mdi.On("GetOperationByID", ctx, "ns2", ref4).Return(&core.Operation{ID: ref4}, nil)
+	enriched, err = em.enrichEvent(ctx, &core.Event{Namespace: "ns2", Type: core.EventTypeTransferOpFailed, Reference: ref4})
+	assert.NoError(t, err)
+	assert.Equal(t, ref4, enriched.Operation.ID)
+
+	ref5 := fftypes.NewUUID()
+	mdi.On("GetDatatypeByID", ctx, "ns2", ref5).Return(&core.Datatype{ID: ref5}, nil)
+	enriched, err = em.enrichEvent(ctx, &core.Event{Namespace: "ns2", Type: core.EventTypeDatatypeConfirmed, Reference: ref5})
+	assert.NoError(t, err)
+	assert.Equal(t, ref5, enriched.Datatype.ID)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestEnrichForeignNamespaceFail(t *testing.T) {
+	em := newTestEventEnricher()
+	ctx := context.Background()
+	mdi := em.database.(*databasemocks.Plugin)
+
+	mdi.On("GetMessageByID", ctx, "ns2", mock.Anything).Return(nil, fmt.Errorf("pop"))
+	mdi.On("GetTransactionByID", ctx, "ns2", mock.Anything).Return(nil, fmt.Errorf("pop"))
+	mdi.On("GetBlockchainEventByID", ctx, "ns2", mock.Anything).Return(nil, fmt.Errorf("pop"))
+	mdi.On("GetOperationByID", ctx, "ns2", mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	for _, eventType := range []core.EventType{
+		core.EventTypeMessageConfirmed,
+		core.EventTypeTransactionSubmitted,
+		core.EventTypeBlockchainEventReceived,
+		core.EventTypeTransferOpFailed,
+	} {
+		_, err := em.enrichEvent(ctx, &core.Event{Namespace: "ns2", Type: eventType, Reference: fftypes.NewUUID()})
+		assert.EqualError(t, err, "pop")
+	}
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetForeignMessageData(t *testing.T) {
+	em := newTestEventEnricher()
+	ctx := context.Background()
+	mdi := em.database.(*databasemocks.Plugin)
+
+	dataID1 := fftypes.NewUUID()
+	dataID2 := fftypes.NewUUID()
+	mdi.On("GetDataByID", ctx, "ns2", dataID1, true).Return(&core.Data{ID: dataID1}, nil)
+	mdi.On("GetDataByID", ctx, "ns2", dataID2, true).Return(nil, nil)
+
+	data, err := em.getForeignMessageData(ctx, "ns2", &core.Message{
+		Data: core.DataRefs{{ID: dataID1}, {ID: dataID2}, nil},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, data, 1)
+	assert.Equal(t, dataID1, data[0].ID)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetForeignMessageDataFail(t *testing.T) {
+	em := newTestEventEnricher()
+	ctx := context.Background()
+	mdi := em.database.(*databasemocks.Plugin)
+
+	mdi.On("GetDataByID", ctx, "ns2", mock.Anything, true).Return(nil, fmt.Errorf("pop"))
+
+	_, err := em.getForeignMessageData(ctx, "ns2", &core.Message{
+		Data: core.DataRefs{{ID: fftypes.NewUUID()}},
+	})
+	assert.EqualError(t, err, "pop")
+}
@@ -23,6 +23,7 @@ import (
 	"strconv"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
@@ -41,6 +42,7 @@ import (
 	"github.com/hyperledger/firefly/internal/multiparty"
 	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/internal/privatemessaging"
+	"github.com/hyperledger/firefly/internal/ratelimit"
 	"github.com/hyperledger/firefly/internal/shareddownload"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/blockchain"
@@ -54,16 +56,24 @@ import (
 
 type EventManager interface {
 	NewPins() chan<- int64
-	NewEvents() chan<- int64
+	NewEvents() chan<- *core.Event
 	NewSubscriptions() chan<- *fftypes.UUID
 	SubscriptionUpdates() chan<- *fftypes.UUID
 	DeletedSubscriptions() chan<- *fftypes.UUID
 	DeleteDurableSubscription(ctx context.Context, subDef *core.Subscription) (err error)
+	ListSubscriptions(ctx context.Context, filter *core.SubscriptionListFilter) ([]*core.SubscriptionWithStatus, error)
 	CreateUpdateDurableSubscription(ctx context.Context, subDef *core.Subscription, mustNew bool) (err error)
 	EnrichEvent(ctx context.Context, event *core.Event) (*core.EnrichedEvent, error)
 	EnrichEvents(ctx context.Context, events []*core.Event) ([]*core.EnrichedEvent, error)
 	FilterHistoricalEventsOnSubscription(ctx context.Context, events []*core.EnrichedEvent, sub *core.Subscription) ([]*core.EnrichedEvent, error)
 	QueueBatchRewind(batchID *fftypes.UUID)
+	PauseAggregator(ctx context.Context) error
+	ResumeAggregator(ctx context.Context) error
+	ForceUnblockContext(ctx context.Context, contextHash *fftypes.Bytes32, principal string) error
+	StartPinReplay(ctx context.Context, input *core.PinReplayInput) (*core.PinReplay, error)
+	GetPinReplay(ctx context.Context, id *fftypes.UUID) (*core.PinReplay, error)
+	GetBlockedContexts(ctx context.Context, filter ffapi.AndFilter) ([]*core.BlockedContext, *ffapi.FilterResult, error)
+	GetBlockedContextStats(ctx context.Context) (*core.BlockedContextStats, error)
 	ResolveTransportAndCapabilities(ctx context.Context, transportName string) (string, *events.Capabilities, error)
 	Start() error
 	WaitStop()
@@ -107,6 +117,7 @@ type eventManager struct {
 	assets             assets.Manager
 	sharedDownload     shareddownload.Manager // optional
 	blobReceiver       *blobReceiver          // optional
+	eventArchiver      *eventArchiver         // optional
 	newEventNotifier   *eventNotifier
 	newPinNotifier     *eventNotifier
 	defaultTransport   string
@@ -116,7 +127,7 @@ type eventManager struct {
 	multiparty         multiparty.Manager // optional
 }
 
-func NewEventManager(ctx context.Context, ns *core.Namespace, di database.Plugin, bi blockchain.Plugin, im identity.Manager, dh definitions.Handler, dm data.Manager, ds definitions.Sender, bm broadcast.Manager, pm privatemessaging.Manager, am assets.Manager, sd shareddownload.Manager, mm metrics.Manager, om operations.Manager, txHelper txcommon.Helper, transports map[string]events.Plugin, mp multiparty.Manager, cacheManager cache.Manager) (EventManager, error) {
+func NewEventManager(ctx context.Context, ns *core.Namespace, di database.Plugin, bi blockchain.Plugin, im identity.Manager, dh definitions.Handler, dm data.Manager, ds definitions.Sender, bm broadcast.Manager, pm privatemessaging.Manager, am assets.Manager, sd shareddownload.Manager, mm metrics.Manager, om operations.Manager, txHelper txcommon.Helper, transports map[string]events.Plugin, mp multiparty.Manager, cacheManager cache.Manager, aggregatorLimiter ratelimit.RateLimiter) (EventManager, error) {
 	if di == nil || im == nil || dh == nil || dm == nil || om == nil || ds == nil || am == nil {
 		return nil, i18n.NewError(ctx, coremsgs.MsgInitializationNilDepError, "EventManager")
 	}
@@ -163,12 +174,17 @@ func NewEventManager(ctx context.Context, ns *core.Namespace, di database.Plugin
 	ie, _ := eifactory.GetPlugin(ctx, system.SystemEventsTransport)
 	em.internalEvents = ie.(*system.Events)
 	if bi != nil {
-		aggregator, err := newAggregator(ctx, ns.Name, di, bi, pm, dh, im, dm, newPinNotifier, mm, cacheManager)
+		aggregator, err := newAggregator(ctx, ns.Name, di, bi, pm, dh, ds, im, dm, newPinNotifier, mm, cacheManager, aggregatorLimiter)
 		if err != nil {
 			return nil, err
 		}
 		em.aggregator = aggregator
 		em.blobReceiver = newBlobReceiver(ctx, em.aggregator)
+		dm.SetExternalDataCallbacks(em)
+	}
+
+	if config.GetDuration(coreconfig.EventArchiveRetention) > 0 {
+		em.eventArchiver = newEventArchiver(ctx, ns.Name, di)
 	}
 
 	em.enricher = newEventEnricher(ns.Name, di, dm, om, txHelper)
@@ -184,15 +200,18 @@ func (em *eventManager) Start() (err error) {
 	err = em.subManager.start()
 	if err == nil {
 		if em.aggregator != nil {
-			em.aggregator.start()
+			err = em.aggregator.start()
 			em.blobReceiver.start()
 		}
+		if em.eventArchiver != nil {
+			em.eventArchiver.start()
+		}
 	}
 	return err
 }
 
-func (em *eventManager) NewEvents() chan<- int64 {
-	return em.newEventNotifier.newEvents
+func (em *eventManager) NewEvents() chan<- *core.Event {
+	return em.newEventNotifier.newEventDetails
 }
 
 func (em *eventManager) NewPins() chan<- int64 {
@@ -228,8 +247,12 @@ func (em *eventManager) WaitStop() {
 		em.blobReceiver.stop()
 		em.blobReceiver = nil
 	}
+	if em.eventArchiver != nil {
+		em.eventArchiver.stop()
+		em.eventArchiver = nil
+	}
 	if em.aggregator != nil {
-		<-em.aggregator.eventPoller.closed
+		em.aggregator.stop()
 	}
 }
 
@@ -277,8 +300,13 @@ func (em *eventManager) CreateUpdateDurableSubscription(ctx context.Context, sub
 }
 
 func (em *eventManager) DeleteDurableSubscription(ctx context.Context, subDef *core.Subscription) (err error) {
+	// The subscription manager drains any local in-flight deliveries before deleting it.
 	// The event in the database for the deletion of the susbscription, will asynchronously update the submanager
-	return em.database.DeleteSubscriptionByID(ctx, em.namespace.Name, subDef.ID)
+	return em.subManager.deleteSubscription(ctx, subDef.ID)
+}
+
+func (em *eventManager) ListSubscriptions(ctx context.Context, filter *core.SubscriptionListFilter) ([]*core.SubscriptionWithStatus, error) {
+	return em.subManager.listSubscriptions(ctx, filter)
 }
 
 func (em *eventManager) AddSystemEventListener(ns string, el system.EventListener) error {
@@ -310,6 +338,56 @@ func (em *eventManager) QueueBatchRewind(batchID *fftypes.UUID) {
 	em.aggregator.queueBatchRewind(batchID)
 }
 
+func (em *eventManager) PauseAggregator(ctx context.Context) error {
+	if em.aggregator == nil {
+		return i18n.NewError(ctx, coremsgs.MsgActionNotSupported)
+	}
+	return em.aggregator.pause(ctx)
+}
+
+func (em *eventManager) ResumeAggregator(ctx context.Context) error {
+	if em.aggregator == nil {
+		return i18n.NewError(ctx, coremsgs.MsgActionNotSupported)
+	}
+	em.aggregator.resume()
+	return nil
+}
+
+func (em *eventManager) ForceUnblockContext(ctx context.Context, contextHash *fftypes.Bytes32, principal string) error {
+	if em.aggregator == nil {
+		return i18n.NewError(ctx, coremsgs.MsgActionNotSupported)
+	}
+	return em.aggregator.forceUnblockContext(ctx, contextHash, principal)
+}
+
+func (em *eventManager) StartPinReplay(ctx context.Context, input *core.PinReplayInput) (*core.PinReplay, error) {
+	if em.aggregator == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgActionNotSupported)
+	}
+	return em.aggregator.startReplay(ctx, input)
+}
+
+func (em *eventManager) GetPinReplay(ctx context.Context, id *fftypes.UUID) (*core.PinReplay, error) {
+	if em.aggregator == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgActionNotSupported)
+	}
+	return em.aggregator.getReplay(ctx, id)
+}
+
+func (em *eventManager) GetBlockedContexts(ctx context.Context, filter ffapi.AndFilter) ([]*core.BlockedContext, *ffapi.FilterResult, error) {
+	if em.aggregator == nil {
+		return nil, nil, i18n.NewError(ctx, coremsgs.MsgActionNotSupported)
+	}
+	return em.aggregator.getBlockedContexts(ctx, filter)
+}
+
+func (em *eventManager) GetBlockedContextStats(ctx context.Context) (*core.BlockedContextStats, error) {
+	if em.aggregator == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgActionNotSupported)
+	}
+	return em.aggregator.getBlockedStats(ctx)
+}
+
 func (em *eventManager) FilterHistoricalEventsOnSubscription(ctx context.Context, events []*core.EnrichedEvent, sub *core.Subscription) ([]*core.EnrichedEvent, error) {
 	// Transport must be provided for validation, but we're not using it for event delivery so fake the transport
 	sub.Transport = "websockets"
@@ -28,6 +28,7 @@ import (
 	"github.com/hyperledger/firefly/internal/cache"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/events/system"
+	"github.com/hyperledger/firefly/internal/events/testmocks"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/mocks/assetmocks"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
@@ -102,19 +103,26 @@ func (tem *testEventManager) cleanup(t *testing.T) {
 }
 
 func newTestEventManager(t *testing.T) *testEventManager {
-	return newTestEventManagerCommon(t, false, false)
+	return newTestEventManagerCommon(t, false, false, false)
 }
 
 func newTestEventManagerWithMetrics(t *testing.T) *testEventManager {
-	return newTestEventManagerCommon(t, true, false)
+	return newTestEventManagerCommon(t, true, false, false)
 }
 
 func newTestEventManagerWithDBConcurrency(t *testing.T) *testEventManager {
-	return newTestEventManagerCommon(t, false, true)
+	return newTestEventManagerCommon(t, false, true, false)
 }
 
-func newTestEventManagerCommon(t *testing.T, metrics, dbconcurrency bool) *testEventManager {
+func newTestEventManagerWithArchiving(t *testing.T) *testEventManager {
+	return newTestEventManagerCommon(t, false, false, true)
+}
+
+func newTestEventManagerCommon(t *testing.T, metrics, dbconcurrency, archiving bool) *testEventManager {
 	coreconfig.Reset()
+	if archiving {
+		config.Set(coreconfig.EventArchiveRetention, "24h")
+	}
 	config.Set(coreconfig.BlobReceiverWorkerCount, 1)
 	config.Set(coreconfig.BlobReceiverWorkerBatchTimeout, "1s")
 	logrus.SetLevel(logrus.DebugLevel)
@@ -144,11 +152,12 @@ func newTestEventManagerCommon(t *testing.T, metrics, dbconcurrency bool) *testE
 	}
 	met.On("Name").Return("ut").Maybe()
 	mbi.On("VerifierType").Return(core.VerifierTypeEthAddress).Maybe()
+	mdm.On("SetExternalDataCallbacks", mock.Anything).Maybe()
 	mdi.On("Capabilities").Return(&database.Capabilities{Concurrency: dbconcurrency}).Maybe()
 	mev.On("SetHandler", "ns1", mock.Anything).Return(nil).Maybe()
 	mev.On("ValidateOptions", mock.Anything, mock.Anything).Return(nil).Maybe()
 	ns := &core.Namespace{Name: "ns1", NetworkName: "ns1"}
-	emi, err := NewEventManager(ctx, ns, mdi, mbi, mim, msh, mdm, mds, mbm, mpm, mam, msd, mmi, mom, txHelper, events, mmp, cmi)
+	emi, err := NewEventManager(ctx, ns, mdi, mbi, mim, msh, mdm, mds, mbm, mpm, mam, msd, mmi, mom, txHelper, events, mmp, cmi, nil)
 	em := emi.(*eventManager)
 	mockRunAsGroupPassthrough(mdi)
 	assert.NoError(t, err)
@@ -191,23 +200,50 @@ func mockRunAsGroupPassthrough(mdi *databasemocks.Plugin) {
 func TestStartStop(t *testing.T) {
 	em := newTestEventManager(t)
 	defer em.cleanup(t)
+	em.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*core.Offset{}, nil, nil)
 	em.mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{
-		Type:    core.OffsetTypeAggregator,
-		Name:    aggregatorOffsetName,
-		Current: 12345,
-		RowID:   333333,
+		Type:          core.OffsetTypeAggregator,
+		Name:          aggregatorOffsetName,
+		Current:       12345,
+		SchemaVersion: core.OffsetSchemaVersion,
+		RowID:         333333,
 	}, nil)
 	em.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
 	em.mdi.On("GetSubscriptions", mock.Anything, mock.Anything, mock.Anything).Return([]*core.Subscription{}, nil, nil)
 	assert.NoError(t, em.Start())
-	em.NewEvents() <- 12345
+	em.NewEvents() <- &core.Event{Sequence: 12345}
 	em.NewPins() <- 12345
 	em.cancel()
 	em.WaitStop()
 }
 
+func TestStartStopWithArchiving(t *testing.T) {
+	em := newTestEventManagerWithArchiving(t)
+	defer em.cleanup(t)
+	em.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*core.Offset{}, nil, nil)
+	archived := make(chan struct{})
+	em.mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{
+		Type:          core.OffsetTypeAggregator,
+		Name:          aggregatorOffsetName,
+		Current:       12345,
+		SchemaVersion: core.OffsetSchemaVersion,
+		RowID:         333333,
+	}, nil)
+	em.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
+	em.mdi.On("GetSubscriptions", mock.Anything, mock.Anything, mock.Anything).Return([]*core.Subscription{}, nil, nil)
+	em.mdi.On("ArchiveEvents", mock.Anything, "ns1", mock.Anything, 1000).Return(int64(0), nil).Run(func(args mock.Arguments) {
+		close(archived)
+	}).Once()
+	assert.NotNil(t, em.eventArchiver)
+	assert.NoError(t, em.Start())
+	<-archived
+	em.cancel()
+	em.WaitStop()
+	assert.Nil(t, em.eventArchiver)
+}
+
 func TestStartStopBadDependencies(t *testing.T) {
-	_, err := NewEventManager(context.Background(), &core.Namespace{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	_, err := NewEventManager(context.Background(), &core.Namespace{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	assert.Regexp(t, "FF10128", err)
 
 }
@@ -261,9 +297,10 @@ func TestAggregatorCacheInitFail(t *testing.T) {
 	txHelper, _ := txcommon.NewTransactionHelper(ctx, ns.Name, mdi, mdm, cmi)
 	mdi.On("Capabilities").Return(&database.Capabilities{Concurrency: false})
 	mbi.On("VerifierType").Return(core.VerifierTypeEthAddress)
+	mdm.On("SetExternalDataCallbacks", mock.Anything).Maybe()
 	mev.On("SetHandler", "ns1", mock.Anything).Return(nil).Maybe()
 	mev.On("ValidateOptions", mock.Anything).Return(nil).Maybe()
-	_, err := NewEventManager(context.Background(), ns, mdi, mbi, mim, msh, mdm, mds, mbm, mpm, mam, msd, mm, mom, txHelper, events, mmp, cmi)
+	_, err := NewEventManager(context.Background(), ns, mdi, mbi, mim, msh, mdm, mds, mbm, mpm, mam, msd, mm, mom, txHelper, events, mmp, cmi, nil)
 	assert.Equal(t, cacheInitError, err)
 }
 
@@ -310,9 +347,10 @@ func TestEventCacheInitFail(t *testing.T) {
 	txHelper, _ := txcommon.NewTransactionHelper(ctx, ns.Name, mdi, mdm, cmi)
 	mdi.On("Capabilities").Return(&database.Capabilities{Concurrency: false})
 	mbi.On("VerifierType").Return(core.VerifierTypeEthAddress)
+	mdm.On("SetExternalDataCallbacks", mock.Anything).Maybe()
 	mev.On("SetHandler", "ns1", mock.Anything).Return(nil).Maybe()
 	mev.On("ValidateOptions", mock.Anything).Return(nil).Maybe()
-	_, err := NewEventManager(context.Background(), ns, mdi, mbi, mim, msh, mdm, mds, mbm, mpm, mam, msd, mm, mom, txHelper, events, mmp, cmi)
+	_, err := NewEventManager(context.Background(), ns, mdi, mbi, mim, msh, mdm, mds, mbm, mpm, mam, msd, mm, mom, txHelper, events, mmp, cmi, nil)
 	assert.Equal(t, cacheInitError, err)
 }
 
@@ -340,20 +378,105 @@ func TestStartStopEventListenerFail(t *testing.T) {
 	txHelper, _ := txcommon.NewTransactionHelper(ctx, "ns1", mdi, mdm, cmi)
 	mdi.On("Capabilities").Return(&database.Capabilities{Concurrency: false})
 	mbi.On("VerifierType").Return(core.VerifierTypeEthAddress)
+	mdm.On("SetExternalDataCallbacks", mock.Anything).Maybe()
 	mev.On("SetHandler", "ns1", mock.Anything).Return(fmt.Errorf("pop"))
 	ns := &core.Namespace{Name: "ns1", NetworkName: "ns1"}
-	_, err := NewEventManager(context.Background(), ns, mdi, mbi, mim, msh, mdm, mds, mbm, mpm, mam, msd, mm, mom, txHelper, events, mmp, cmi)
+	_, err := NewEventManager(context.Background(), ns, mdi, mbi, mim, msh, mdm, mds, mbm, mpm, mam, msd, mm, mom, txHelper, events, mmp, cmi, nil)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPauseResumeAggregator(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+	mep := &testmocks.MockEventPoller{}
+	em.aggregator.eventPoller = mep
+
+	mep.On("Pause", em.ctx).Return(nil)
+	mep.On("Resume").Return()
+
+	err := em.PauseAggregator(em.ctx)
+	assert.NoError(t, err)
+	err = em.ResumeAggregator(em.ctx)
+	assert.NoError(t, err)
+
+	mep.AssertExpectations(t)
+}
+
+func TestPauseResumeAggregatorNotSupported(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+	em.aggregator = nil
+
+	err := em.PauseAggregator(em.ctx)
+	assert.Regexp(t, "FF10414", err)
+	err = em.ResumeAggregator(em.ctx)
+	assert.Regexp(t, "FF10414", err)
+}
+
+func TestForceUnblockContext(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+	hash := fftypes.NewRandB32()
+
+	em.mdi.On("GetPins", em.ctx, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := em.ForceUnblockContext(em.ctx, hash, "admin1")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPinReplayNotSupported(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+	em.aggregator = nil
+
+	_, err := em.StartPinReplay(em.ctx, &core.PinReplayInput{})
+	assert.Regexp(t, "FF10414", err)
+	_, err = em.GetPinReplay(em.ctx, fftypes.NewUUID())
+	assert.Regexp(t, "FF10414", err)
+}
+
+func TestGetBlockedContexts(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+
+	em.mdi.On("GetOffset", em.ctx, core.OffsetTypeAggregator, aggregatorOffsetName).Return(nil, fmt.Errorf("pop"))
+
+	_, _, err := em.GetBlockedContexts(em.ctx, database.PinQueryFactory.NewFilter(em.ctx).And())
+	assert.EqualError(t, err, "pop")
+	_, err = em.GetBlockedContextStats(em.ctx)
 	assert.EqualError(t, err, "pop")
 }
 
+func TestGetBlockedContextsNotSupported(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+	em.aggregator = nil
+
+	_, _, err := em.GetBlockedContexts(em.ctx, database.PinQueryFactory.NewFilter(em.ctx).And())
+	assert.Regexp(t, "FF10414", err)
+	_, err = em.GetBlockedContextStats(em.ctx)
+	assert.Regexp(t, "FF10414", err)
+}
+
+func TestForceUnblockContextNotSupported(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+	em.aggregator = nil
+
+	err := em.ForceUnblockContext(em.ctx, fftypes.NewRandB32(), "admin1")
+	assert.Regexp(t, "FF10414", err)
+}
+
 func TestEmitSubscriptionEventsNoops(t *testing.T) {
 	em := newTestEventManager(t)
 	defer em.cleanup(t)
+	em.mdi.On("GetOffsets", mock.Anything, mock.Anything).Return([]*core.Offset{}, nil, nil)
 	em.mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{
-		Type:    core.OffsetTypeAggregator,
-		Name:    aggregatorOffsetName,
-		Current: 12345,
-		RowID:   333333,
+		Type:          core.OffsetTypeAggregator,
+		Name:          aggregatorOffsetName,
+		Current:       12345,
+		SchemaVersion: core.OffsetSchemaVersion,
+		RowID:         333333,
 	}, nil)
 	em.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil).Maybe()
 	em.mdi.On("GetSubscriptions", mock.Anything, mock.Anything, mock.Anything).Return([]*core.Subscription{}, nil, nil)
@@ -579,6 +702,15 @@ func TestCreateDeleteDurableSubscriptionOk(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestListSubscriptions(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+	em.mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{}, nil, nil)
+	subs, err := em.ListSubscriptions(em.ctx, &core.SubscriptionListFilter{})
+	assert.NoError(t, err)
+	assert.Empty(t, subs)
+}
+
 func TestAddInternalListener(t *testing.T) {
 	em := newTestEventManager(t)
 	defer em.cleanup(t)
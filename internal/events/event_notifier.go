@@ -19,34 +19,82 @@ package events
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
 )
 
+// eventFilter is a predicate a consumer can supply, to only be woken for events it is interested in
+type eventFilter func(event *core.Event) bool
+
+// SubscriptionID identifies a channel registration on an event notifier
+type SubscriptionID int64
+
 type eventNotifier struct {
-	ctx            context.Context
-	desc           string
-	newEvents      chan int64
-	latestSequence int64
-	cond           *sync.Cond
-	closed         bool
+	ctx             context.Context
+	desc            string
+	newEvents       chan int64
+	newEventDetails chan *core.Event
+	latestSequence  int64
+	subscriptions   sync.Map // SubscriptionID -> *notifierSubscription
+	nextSubID       int64
+	cond            *sync.Cond
+	closed          bool
+}
+
+// notifierSubscription is a channel registration, which receives a value each time a
+// matching event is notified. Notifications without the event detail (just a sequence)
+// match every subscription. Sends never block - if the channel is full the notification
+// is dropped, so the receiver must use a buffered channel and treat each receive as a
+// hint to re-check for new events.
+type notifierSubscription struct {
+	filter   eventFilter
+	ch       chan<- struct{}
+	dropping int32 // set while notifications are being dropped, so we only warn once per run of drops
 }
 
 func newEventNotifier(ctx context.Context, desc string) *eventNotifier {
 	mux := &sync.Mutex{}
 	en := &eventNotifier{
-		ctx:            ctx,
-		newEvents:      make(chan int64),
-		latestSequence: -1,
-		cond:           sync.NewCond(mux),
-		desc:           desc,
+		ctx:             ctx,
+		newEvents:       make(chan int64),
+		newEventDetails: make(chan *core.Event),
+		latestSequence:  -1,
+		cond:            sync.NewCond(mux),
+		desc:            desc,
 	}
 	go en.newEventLoop()
 	return en
 }
 
+// Subscribe registers a channel that is sent a value for each notified event matching the filter.
+// A nil filter matches all events.
+func (en *eventNotifier) Subscribe(filter eventFilter, ch chan<- struct{}) (SubscriptionID, error) {
+	if ch == nil {
+		return -1, i18n.NewError(en.ctx, coremsgs.MsgNotifierNilChannel, en.desc)
+	}
+	en.cond.L.Lock()
+	closed := en.closed
+	en.cond.L.Unlock()
+	if closed {
+		return -1, i18n.NewError(en.ctx, coremsgs.MsgEventListenerClosing)
+	}
+	id := SubscriptionID(atomic.AddInt64(&en.nextSubID, 1))
+	en.subscriptions.Store(id, &notifierSubscription{filter: filter, ch: ch})
+	return id, nil
+}
+
+// Unsubscribe removes a channel registration. A dispatch already in progress might still send to the channel.
+func (en *eventNotifier) Unsubscribe(id SubscriptionID) error {
+	if _, ok := en.subscriptions.LoadAndDelete(id); !ok {
+		return i18n.NewError(en.ctx, coremsgs.MsgNotifierSubscriptionNotFound, id, en.desc)
+	}
+	return nil
+}
+
 func (en *eventNotifier) waitNext(lastSequence int64) error {
 	var seq int64
 	en.cond.L.Lock()
@@ -63,6 +111,12 @@ func (en *eventNotifier) waitNext(lastSequence int64) error {
 	return nil
 }
 
+func (en *eventNotifier) getLatestSequence() int64 {
+	en.cond.L.Lock()
+	defer en.cond.L.Unlock()
+	return en.latestSequence
+}
+
 func (en *eventNotifier) close() {
 	en.cond.L.Lock()
 	en.closed = true
@@ -70,6 +124,30 @@ func (en *eventNotifier) close() {
 	en.cond.L.Unlock()
 }
 
+// notify must be called with the lock held
+func (en *eventNotifier) notify(seq int64) {
+	en.latestSequence = seq
+	en.cond.Broadcast()
+}
+
+// dispatchSubscriptions must be called without the lock held. If the event is nil, then all subscriptions are notified
+func (en *eventNotifier) dispatchSubscriptions(seq int64, event *core.Event) {
+	en.subscriptions.Range(func(key, value interface{}) bool {
+		sub := value.(*notifierSubscription)
+		if event == nil || sub.filter == nil || sub.filter(event) {
+			select {
+			case sub.ch <- struct{}{}:
+				atomic.StoreInt32(&sub.dropping, 0)
+			default:
+				if atomic.CompareAndSwapInt32(&sub.dropping, 0, 1) {
+					log.L(en.ctx).Warnf("Dropping %s notifications for subscription %d from %d (channel full)", en.desc, key, seq)
+				}
+			}
+		}
+		return true
+	})
+}
+
 func (en *eventNotifier) newEventLoop() {
 	l := log.L(en.ctx)
 	defer en.close()
@@ -85,9 +163,19 @@ func (en *eventNotifier) newEventLoop() {
 			}
 			log.L(en.ctx).Tracef("Notifying new %s %d", en.desc, seq)
 			en.cond.L.Lock()
-			en.latestSequence = seq
-			en.cond.Broadcast()
+			en.notify(seq)
+			en.cond.L.Unlock()
+			en.dispatchSubscriptions(seq, nil)
+		case event, ok := <-en.newEventDetails:
+			if !ok {
+				l.Debugf("New event notifier loop ending (closed channel)")
+				return
+			}
+			log.L(en.ctx).Tracef("Notifying new %s %d (%s)", en.desc, event.Sequence, event.Type)
+			en.cond.L.Lock()
+			en.notify(event.Sequence)
 			en.cond.L.Unlock()
+			en.dispatchSubscriptions(event.Sequence, event)
 		}
 	}
 }
@@ -19,6 +19,9 @@ package events
 import (
 	"context"
 	"testing"
+
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestEventNotifier(t *testing.T) {
@@ -71,3 +74,61 @@ func TestEventNotifierClosedChannel(t *testing.T) {
 	close(en.newEvents)
 	<-events
 }
+
+func TestEventNotifierSubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	en := newEventNotifier(ctx, "ut")
+
+	all := make(chan struct{}, 1)
+	allID, err := en.Subscribe(nil, all)
+	assert.NoError(t, err)
+	filtered := make(chan struct{}, 1)
+	filteredID, err := en.Subscribe(func(event *core.Event) bool {
+		return event.Type == core.EventTypeMessageConfirmed
+	}, filtered)
+	assert.NoError(t, err)
+	assert.NotEqual(t, allID, filteredID)
+
+	en.newEventDetails <- &core.Event{Sequence: 1, Type: core.EventTypeTransactionSubmitted}
+	<-all
+	assert.NoError(t, en.waitNext(0))
+	assert.Empty(t, filtered)
+
+	en.newEventDetails <- &core.Event{Sequence: 2, Type: core.EventTypeMessageConfirmed}
+	<-filtered
+	<-all
+
+	// Notifications without detail match every subscription, and full channels are dropped rather than blocking
+	en.newEvents <- 3
+	en.newEvents <- 4
+	en.newEvents <- 5
+	assert.NoError(t, en.waitNext(4))
+	<-filtered
+	<-all
+
+	assert.NoError(t, en.Unsubscribe(allID))
+	assert.NoError(t, en.Unsubscribe(filteredID))
+	err = en.Unsubscribe(allID)
+	assert.Regexp(t, "FF10472", err)
+}
+
+func TestEventNotifierSubscribeNilChannel(t *testing.T) {
+	en := newEventNotifier(context.Background(), "ut")
+	_, err := en.Subscribe(nil, nil)
+	assert.Regexp(t, "FF10473", err)
+}
+
+func TestEventNotifierSubscribeClosed(t *testing.T) {
+	en := newEventNotifier(context.Background(), "ut")
+	en.close()
+	_, err := en.Subscribe(nil, make(chan struct{}, 1))
+	assert.Regexp(t, "FF10186", err)
+}
+
+func TestEventNotifierClosedDetailChannel(t *testing.T) {
+	en := newEventNotifier(context.Background(), "ut")
+	close(en.newEventDetails)
+	for en.waitNext(0) == nil {
+	}
+}
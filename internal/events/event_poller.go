@@ -18,36 +18,59 @@ package events
 
 import (
 	"context"
+	"database/sql/driver"
 	"fmt"
+	"math/rand"
 	"sync"
 	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly-common/pkg/retry"
+	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
 )
 
+// EventPoller is the interface to an event poller, that allows it to be mocked by its users.
+// The methods are exported so that mocks can be provided from outside this package.
+type EventPoller interface {
+	Start() error
+	CommitOffset(ctx context.Context, offset int64) error
+	ShoulderTap()
+	Pause(ctx context.Context) error
+	Resume()
+	Stop()
+}
+
 type eventPoller struct {
 	ctx             context.Context
+	cancelCtx       context.CancelFunc
 	database        database.Plugin
-	shoulderTaps    chan bool
+	shoulderTaps    chan struct{}
 	eventNotifier   *eventNotifier
+	subscriptionID  SubscriptionID
 	closed          chan struct{}
 	offsetCommitted chan int64
 	offsetID        int64
 	pollingOffset   int64
+	resume          chan struct{} // non-nil while paused, closed on resume
+	paused          chan struct{} // closed by the event loop once it has stopped dispatching for a pause
 	mux             sync.Mutex
 	conf            *eventPollerConf
 }
 
 type newEventsHandler func(events []core.LocallySequenced) (bool, error)
 
+type retriesExhaustedHandler func(events []core.LocallySequenced, attempts int, lastErr error) (bool, error)
+
 type eventPollerConf struct {
 	ephemeral                  bool
 	eventBatchSize             int
 	eventBatchTimeout          time.Duration
+	eventBatchTimeoutOverrides map[core.EventType]time.Duration
+	eventTypeFilter            []core.EventType
 	eventPollTimeout           time.Duration
 	firstEvent                 *core.SubOptsFirstEvent
 	queryFactory               ffapi.QueryFactory
@@ -55,18 +78,26 @@ type eventPollerConf struct {
 	getItems                   func(context.Context, ffapi.Filter, int64) ([]core.LocallySequenced, error)
 	maybeRewind                func() (bool, int64)
 	newEventsHandler           newEventsHandler
+	maxAttempts                int
+	retriesExhausted           retriesExhaustedHandler
+	notifyFilter               eventFilter
 	namespace                  string
 	offsetName                 string
 	offsetType                 core.OffsetType
 	retry                      retry.Retry
+	retryJitter                float64
 	startupOffsetRetryAttempts int
+	schemaVersion              int  // non-zero to stamp stored offsets with a schema version, and check it on restore
+	resetOnSchemaMismatch      bool // reset to firstEvent on a schema version mismatch, rather than failing
 }
 
 func newEventPoller(ctx context.Context, di database.Plugin, en *eventNotifier, conf *eventPollerConf) *eventPoller {
+	ctx, cancelCtx := context.WithCancel(ctx)
 	ep := &eventPoller{
 		ctx:             log.WithLogField(ctx, "role", fmt.Sprintf("ep[%s:%s]", conf.namespace, conf.offsetName)),
+		cancelCtx:       cancelCtx,
 		database:        di,
-		shoulderTaps:    make(chan bool, 1),
+		shoulderTaps:    make(chan struct{}, 1),
 		offsetCommitted: make(chan int64, 1),
 		eventNotifier:   en,
 		closed:          make(chan struct{}),
@@ -79,7 +110,7 @@ func newEventPoller(ctx context.Context, di database.Plugin, en *eventNotifier,
 }
 
 func (ep *eventPoller) restoreOffset() error {
-	return ep.conf.retry.Do(ep.ctx, "restore offset", func(attempt int) (retry bool, err error) {
+	return ep.retryDo("restore offset", func(attempt int) (retry bool, err error) {
 		retry = ep.conf.startupOffsetRetryAttempts == 0 || attempt <= ep.conf.startupOffsetRetryAttempts
 		var offset *core.Offset
 		if ep.conf.ephemeral {
@@ -97,15 +128,24 @@ func (ep *eventPoller) restoreOffset() error {
 					return retry, err
 				}
 				err = ep.database.UpsertOffset(ep.ctx, &core.Offset{
-					Type:    ep.conf.offsetType,
-					Name:    ep.conf.offsetName,
-					Current: firstOffset,
+					Type:          ep.conf.offsetType,
+					Name:          ep.conf.offsetName,
+					Current:       firstOffset,
+					SchemaVersion: ep.conf.schemaVersion,
 				}, false)
 				if err != nil {
 					return retry, err
 				}
 			}
 		}
+		if ep.conf.schemaVersion > 0 && offset.SchemaVersion != ep.conf.schemaVersion {
+			if !ep.conf.resetOnSchemaMismatch {
+				return false, i18n.NewError(ep.ctx, coremsgs.MsgOffsetSchemaMismatch, ep.conf.offsetType, ep.conf.offsetName, offset.SchemaVersion, ep.conf.schemaVersion)
+			}
+			if err = ep.resetOffsetSchemaVersion(offset); err != nil {
+				return retry, err
+			}
+		}
 		ep.offsetID = offset.RowID
 		ep.pollingOffset = offset.Current
 		log.L(ep.ctx).Infof("Event offset restored %d", ep.pollingOffset)
@@ -113,18 +153,118 @@ func (ep *eventPoller) restoreOffset() error {
 	})
 }
 
-func (ep *eventPoller) start() {
-	err := ep.conf.retry.Do(ep.ctx, "restore offset", func(attempt int) (retry bool, err error) {
+// resetOffsetSchemaVersion moves an offset stored at a different schema version back to the first event,
+// as the sequence it refers to might not mean the same thing (or even exist) after the migration.
+func (ep *eventPoller) resetOffsetSchemaVersion(offset *core.Offset) error {
+	firstOffset, err := calcFirstOffset(ep.ctx, ep.conf.namespace, ep.database, ep.conf.firstEvent)
+	if err != nil {
+		return err
+	}
+	log.L(ep.ctx).Warnf("Offset '%s:%s' was stored at schema version %d (current=%d) - resetting from %d to %d",
+		offset.Type, offset.Name, offset.SchemaVersion, ep.conf.schemaVersion, offset.Current, firstOffset)
+	u := database.OffsetQueryFactory.NewUpdate(ep.ctx).
+		Set("current", firstOffset).
+		Set("schemaversion", ep.conf.schemaVersion)
+	if err := ep.database.UpdateOffset(ep.ctx, offset.RowID, u); err != nil {
+		return err
+	}
+	offset.Current = firstOffset
+	offset.SchemaVersion = ep.conf.schemaVersion
+	return nil
+}
+
+func (ep *eventPoller) Start() error {
+	err := ep.retryDo("restore offset", func(attempt int) (retry bool, err error) {
 		return true, ep.restoreOffset()
 	})
 	if err != nil {
 		log.L(ep.ctx).Errorf("Event poller context closed before we successfully restored offset: %s", err)
 		close(ep.closed)
-		return
+		return err
+	}
+	// The notifier taps our shoulder directly for new events that match our filter
+	if ep.subscriptionID, err = ep.eventNotifier.Subscribe(ep.conf.notifyFilter, ep.shoulderTaps); err != nil {
+		close(ep.closed)
+		return err
 	}
-	go ep.newEventNotifications()
 	go ep.eventLoop()
 	go ep.offsetCommitLoop()
+	return nil
+}
+
+// Stop cancels the poller, and waits for the event loop to exit. The poller must have been started
+func (ep *eventPoller) Stop() {
+	ep.cancelCtx()
+	<-ep.closed
+}
+
+// Pause stops the poller dispatching events, and returns once any page of events being dispatched is complete.
+// The polling offset is retained, so on Resume the poller carries on in sequence from where it stopped.
+func (ep *eventPoller) Pause(ctx context.Context) error {
+	ep.mux.Lock()
+	if ep.resume == nil {
+		ep.resume = make(chan struct{})
+		ep.paused = make(chan struct{})
+	}
+	resume, paused := ep.resume, ep.paused
+	ep.mux.Unlock()
+
+	// Wake the event loop, in case it is waiting for new events
+	ep.ShoulderTap()
+	select {
+	case <-paused:
+		log.L(ep.ctx).Infof("Event poller paused")
+		return nil
+	case <-resume:
+		// Resumed before the pause took effect
+		return nil
+	case <-ep.closed:
+		ep.cancelPause(resume)
+		return i18n.NewError(ctx, coremsgs.MsgEventListenerClosing)
+	case <-ctx.Done():
+		ep.cancelPause(resume)
+		return i18n.NewError(ctx, coremsgs.MsgContextCanceled)
+	}
+}
+
+// cancelPause backs out a Pause that failed, so the poller is not left paused after an error has been returned
+func (ep *eventPoller) cancelPause(resume chan struct{}) {
+	ep.mux.Lock()
+	defer ep.mux.Unlock()
+	if ep.resume == resume {
+		close(ep.resume)
+		ep.resume = nil
+	}
+}
+
+// Resume restarts dispatching after a Pause. It is a no-op if the poller is not paused
+func (ep *eventPoller) Resume() {
+	ep.mux.Lock()
+	defer ep.mux.Unlock()
+	if ep.resume != nil {
+		close(ep.resume)
+		ep.resume = nil
+	}
+}
+
+// waitWhilePaused is called by the event loop between pages, and blocks for as long as the poller is paused.
+// Returns false if the poller is closed while paused
+func (ep *eventPoller) waitWhilePaused() bool {
+	ep.mux.Lock()
+	resume, paused := ep.resume, ep.paused
+	ep.mux.Unlock()
+	if resume == nil {
+		return true
+	}
+
+	close(paused)
+	select {
+	case <-resume:
+	case <-ep.ctx.Done():
+		return false
+	}
+	log.L(ep.ctx).Infof("Event poller resumed")
+	return true
 }
 
 func (ep *eventPoller) rewindPollingOffset(offset int64) int64 {
@@ -159,6 +299,15 @@ func (ep *eventPoller) commitOffset(offset int64) {
 	}
 }
 
+// CommitOffset moves the polling offset forwards, and persists it in the background
+func (ep *eventPoller) CommitOffset(ctx context.Context, offset int64) error {
+	if ctx.Err() != nil {
+		return i18n.NewError(ctx, coremsgs.MsgContextCanceled)
+	}
+	ep.commitOffset(offset)
+	return nil
+}
+
 func (ep *eventPoller) readPage() ([]core.LocallySequenced, error) {
 
 	var items []core.LocallySequenced
@@ -173,11 +322,27 @@ func (ep *eventPoller) readPage() ([]core.LocallySequenced, error) {
 		pollingOffset = ep.getPollingOffset()
 	}
 
-	err := ep.conf.retry.Do(ep.ctx, "retrieve events", func(attempt int) (retry bool, err error) {
+	// A query filtered by event type only returns matching events, so the offset would never move past the rest.
+	// The latest sequence notified before the query runs has been committed, so if nothing matches up to it,
+	// the offset can be moved past everything that was filtered out.
+	scannedTo := int64(-1)
+	if len(ep.conf.eventTypeFilter) > 0 {
+		scannedTo = ep.eventNotifier.getLatestSequence()
+	}
+
+	err := ep.retryDo("retrieve events", func(attempt int) (retry bool, err error) {
 		fb := ep.conf.queryFactory.NewFilter(ep.ctx)
 		filter := fb.And(
 			fb.Gt("sequence", pollingOffset),
 		)
+		if len(ep.conf.eventTypeFilter) > 0 {
+			// Only fetch the event types the consumer would act on, rather than discarding the rest after the read
+			eventTypes := make([]driver.Value, len(ep.conf.eventTypeFilter))
+			for i, eventType := range ep.conf.eventTypeFilter {
+				eventTypes[i] = string(eventType)
+			}
+			filter.Condition(fb.In("type", eventTypes))
+		}
 		filter = ep.conf.addCriteria(filter)
 		items, err = ep.conf.getItems(ep.ctx, filter.Sort("sequence").Limit(uint64(ep.conf.eventBatchSize)), pollingOffset)
 		if err != nil {
@@ -185,6 +350,10 @@ func (ep *eventPoller) readPage() ([]core.LocallySequenced, error) {
 		}
 		return false, nil
 	})
+	if err == nil && len(items) == 0 && scannedTo > pollingOffset {
+		log.L(ep.ctx).Debugf("No events matched the event type filter up to sequence %d", scannedTo)
+		ep.commitOffset(scannedTo)
+	}
 	return items, err
 }
 
@@ -192,14 +361,21 @@ func (ep *eventPoller) eventLoop() {
 	l := log.L(ep.ctx)
 	l.Debugf("Started event detector")
 	defer func() {
+		_ = ep.eventNotifier.Unsubscribe(ep.subscriptionID)
 		close(ep.closed)
 		close(ep.offsetCommitted)
 	}()
 
-	doBatchDelay := false
+	var batchTimer, typeTimer *time.Timer
+	var typeDeadline time.Time
+	batchTimedOut := false
 	for {
-		if doBatchDelay {
-			ep.waitForBatchTimeout()
+		if !ep.waitWhilePaused() {
+			l.Debugf("Exiting while paused")
+			return
+		}
+		if batchTimer != nil {
+			batchTimedOut = ep.waitForBatchFillOrTimeout(batchTimer, typeTimer)
 		}
 
 		// Read messages from the DB - in an error condition we retry until success, or a closed context
@@ -211,13 +387,37 @@ func (ep *eventPoller) eventLoop() {
 
 		eventCount := len(events)
 
-		// We might want to wait for the batch to fill - so we delay and re-poll
-		if ep.conf.eventBatchTimeout > 0 && !doBatchDelay && eventCount < ep.conf.eventBatchSize {
-			doBatchDelay = true
-			l.Tracef("Batch delay: detected=%d, batchSize=%d batchTimeout=%s", eventCount, ep.conf.eventBatchSize, ep.conf.eventBatchTimeout)
+		// We might want to wait for the batch to fill - so we start a timer, and re-poll each time we are
+		// woken for new events, until either the batch is full or the timer fires
+		if ep.conf.eventBatchTimeout > 0 && !batchTimedOut && eventCount < ep.conf.eventBatchSize {
+			if batchTimer == nil {
+				l.Tracef("Batch delay: detected=%d, batchSize=%d batchTimeout=%s", eventCount, ep.conf.eventBatchSize, ep.conf.eventBatchTimeout)
+				batchTimer = time.NewTimer(ep.conf.eventBatchTimeout)
+			}
+			// Event types with their own timeout start a separate timer when they first join the batch,
+			// which flushes the batch if it fires before the batch timer
+			if typeTimeout, ok := ep.typeBatchTimeout(events); ok {
+				deadline := time.Now().Add(typeTimeout)
+				if typeTimer == nil || deadline.Before(typeDeadline) {
+					if typeTimer != nil {
+						typeTimer.Stop()
+					}
+					l.Tracef("Batch delay for event type: batchTimeout=%s", typeTimeout)
+					typeTimer, typeDeadline = time.NewTimer(typeTimeout), deadline
+				}
+			}
 			continue
 		}
-		doBatchDelay = false // clear any batch delay for next iteration
+		// Reset the batch timers for the next batch
+		if batchTimer != nil {
+			batchTimer.Stop()
+			batchTimer = nil
+		}
+		if typeTimer != nil {
+			typeTimer.Stop()
+			typeTimer = nil
+		}
+		batchTimedOut = false
 
 		repoll := false
 		if eventCount > 0 {
@@ -243,7 +443,7 @@ func (ep *eventPoller) eventLoop() {
 func (ep *eventPoller) offsetCommitLoop() {
 	l := log.L(ep.ctx)
 	for range ep.offsetCommitted {
-		_ = ep.conf.retry.Do(ep.ctx, "process events", func(attempt int) (retry bool, err error) {
+		_ = ep.retryDo("process events", func(attempt int) (retry bool, err error) {
 			ep.mux.Lock()
 			pollingOffset := ep.pollingOffset
 			ep.mux.Unlock()
@@ -257,50 +457,98 @@ func (ep *eventPoller) offsetCommitLoop() {
 	}
 }
 
+// retryDo follows the back-off of the configured retry, but the wait between attempts is interrupted
+// when the poller context is cancelled - so the poller exits promptly on shutdown, rather than
+// sleeping out a (potentially long) retry delay.
+func (ep *eventPoller) retryDo(logDescription string, f func(attempt int) (retry bool, err error)) error {
+	r := &ep.conf.retry
+	delay := r.InitialDelay
+	factor := r.Factor
+	if factor < 1 {
+		factor = 2.0
+	}
+	for attempt := 1; ; attempt++ {
+		retry, err := f(attempt)
+		if err != nil {
+			log.L(ep.ctx).Errorf("%s attempt %d: %s", logDescription, attempt, err)
+		}
+		if err == nil || !retry {
+			return err
+		}
+		if delay > r.MaximumDelay {
+			delay = r.MaximumDelay
+		}
+		select {
+		case <-ep.ctx.Done():
+			return i18n.NewError(ep.ctx, coremsgs.MsgContextCanceled)
+		case <-time.After(jitterDelay(delay, ep.conf.retryJitter)):
+		}
+		delay = time.Duration(float64(delay) * factor)
+	}
+}
+
+// jitterDelay adds a random amount of up to the jitter fraction (capped at 1) of the delay.
+// The top level math/rand source is randomly seeded, so separate nodes do not share a sequence.
+func jitterDelay(delay time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 {
+		return delay
+	}
+	if jitter > 1 {
+		jitter = 1
+	}
+	return delay + time.Duration(rand.Float64()*jitter*float64(delay))
+}
+
 func (ep *eventPoller) dispatchEventsRetry(events []core.LocallySequenced) (repoll bool, err error) {
-	err = ep.conf.retry.Do(ep.ctx, "process events", func(attempt int) (retry bool, err error) {
+	err = ep.retryDo("process events", func(attempt int) (retry bool, err error) {
 		repoll, err = ep.conf.newEventsHandler(events)
+		if err != nil && ep.conf.retriesExhausted != nil && ep.conf.maxAttempts > 0 && attempt >= ep.conf.maxAttempts {
+			// Give the owner of the poller the chance to set aside the events that are failing, so we can move on
+			repoll, err = ep.conf.retriesExhausted(events, attempt, err)
+		}
 		return err != nil, err // always retry (retry will end on cancelled context)
 	})
 	return repoll, err
 }
 
-// newEventNotifications just consumes new events, logs them, then ensures there's a shoulderTap
-// in the channel - without blocking. This is important as we must not block the notifier
-// - which might be our own eventLoop
-func (ep *eventPoller) newEventNotifications() {
-	defer close(ep.shoulderTaps)
-	var lastNotified int64 = -1
-	for {
-		latestSequence := ep.getPollingOffset()
-		if latestSequence <= lastNotified {
-			latestSequence = lastNotified + 1
-		}
-		err := ep.eventNotifier.waitNext(latestSequence)
-		if err != nil {
-			log.L(ep.ctx).Debugf("event notifier closing")
-			return
-		}
-		ep.shoulderTap()
-		lastNotified = latestSequence
-	}
-}
-
-func (ep *eventPoller) shoulderTap() {
+func (ep *eventPoller) ShoulderTap() {
 	// Do not block sending to the shoulderTap - as it can only contain one
 	select {
-	case ep.shoulderTaps <- true:
+	case ep.shoulderTaps <- struct{}{}:
 	default:
 	}
 }
 
-func (ep *eventPoller) waitForBatchTimeout() {
+// typeBatchTimeout returns the shortest batch timeout configured for the type of any of the events in the page
+func (ep *eventPoller) typeBatchTimeout(items []core.LocallySequenced) (timeout time.Duration, ok bool) {
+	for _, item := range items {
+		if event, isEvent := item.(*core.Event); isEvent {
+			if d, found := ep.conf.eventBatchTimeoutOverrides[event.Type]; found && (!ok || d < timeout) {
+				timeout, ok = d, true
+			}
+		}
+	}
+	return timeout, ok
+}
+
+func (ep *eventPoller) waitForBatchFillOrTimeout(batchTimer, typeTimer *time.Timer) (timedOut bool) {
 	// For throughput optimized environments, we can set an eventBatchingTimeout to allow
 	// dispatching of incomplete batches at a shorter timeout than the
-	// long timeout between polling cycles (at the cost of some dispatch latency)
+	// long timeout between polling cycles (at the cost of some dispatch latency).
+	// We are woken early for new events, as they might fill the batch.
+	var typeTimeout <-chan time.Time
+	if typeTimer != nil {
+		typeTimeout = typeTimer.C
+	}
 	select {
-	case <-time.After(ep.conf.eventBatchTimeout):
+	case <-batchTimer.C:
+		return true
+	case <-typeTimeout:
+		return true
+	case <-ep.shoulderTaps:
+		return false
 	case <-ep.ctx.Done():
+		return true
 	}
 }
 
@@ -73,13 +73,92 @@ func TestStartStopEventPoller(t *testing.T) {
 		Current: 12345,
 	}, nil)
 	mdi.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).Return([]*core.Event{}, nil, nil)
-	ep.start()
+	err := ep.Start()
+	assert.NoError(t, err)
 	assert.Equal(t, int64(12345), ep.pollingOffset)
 	ep.eventNotifier.newEvents <- 12345
 	cancel()
 	<-ep.closed
 }
 
+func TestStopEventPoller(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	defer cancel()
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, "test").Return(&core.Offset{
+		Type:    core.OffsetTypeAggregator,
+		Name:    aggregatorOffsetName,
+		RowID:   3333333,
+		Current: 12345,
+	}, nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).Return([]*core.Event{}, nil, nil)
+	err := ep.Start()
+	assert.NoError(t, err)
+	ep.Stop()
+}
+
+func TestEventPollerWokenByNotifier(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	defer cancel()
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, "test").Return(&core.Offset{
+		Type:    core.OffsetTypeAggregator,
+		Name:    aggregatorOffsetName,
+		RowID:   3333333,
+		Current: 12345,
+	}, nil)
+	polled := make(chan struct{}, 2)
+	mdi.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).Return([]*core.Event{}, nil, nil).Run(func(args mock.Arguments) {
+		polled <- struct{}{}
+	})
+	err := ep.Start()
+	assert.NoError(t, err)
+	<-polled
+
+	// The poll timeout is long, so only the notifier can wake the poller for the second poll
+	ep.eventNotifier.newEventDetails <- &core.Event{Sequence: 12346}
+	<-polled
+
+	ep.Stop()
+	ep.eventNotifier.subscriptions.Range(func(key, value interface{}) bool {
+		assert.Fail(t, "subscription not removed")
+		return true
+	})
+}
+
+func TestStartEventPollerNotifierClosed(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	defer cancel()
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, "test").Return(&core.Offset{
+		Type:    core.OffsetTypeAggregator,
+		Name:    aggregatorOffsetName,
+		RowID:   3333333,
+		Current: 12345,
+	}, nil)
+	ep.eventNotifier.close()
+	err := ep.Start()
+	assert.Regexp(t, "FF10186", err)
+	<-ep.closed
+}
+
+func TestCommitOffsetContextCancelled(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	cancel()
+	err := ep.CommitOffset(ep.ctx, 12345)
+	assert.Regexp(t, "FF00154", err)
+}
+
+func TestCommitOffsetOk(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	defer cancel()
+	err := ep.CommitOffset(ep.ctx, 12345)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12345), <-ep.offsetCommitted)
+}
+
 func TestRestoreOffsetNewestOK(t *testing.T) {
 	mdi := &databasemocks.Plugin{}
 	ep, cancel := newTestEventPoller(mdi, nil, nil)
@@ -163,7 +242,7 @@ func TestRestoreOffsetFailRead(t *testing.T) {
 	ep, cancel := newTestEventPoller(mdi, nil, nil)
 	cancel() // to avoid infinite retry
 	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, "test").Return(nil, fmt.Errorf("pop"))
-	ep.start()
+	ep.Start()
 	mdi.AssertExpectations(t)
 }
 
@@ -192,6 +271,94 @@ func TestRestoreOffsetEphemeral(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestRestoreOffsetSchemaVersionMatch(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	ep.conf.schemaVersion = core.OffsetSchemaVersion
+	defer cancel()
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, "test").Return(&core.Offset{Current: 12345, SchemaVersion: core.OffsetSchemaVersion}, nil)
+	err := ep.restoreOffset()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12345), ep.pollingOffset)
+	mdi.AssertExpectations(t)
+}
+
+func TestRestoreOffsetNewStampsSchemaVersion(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	firstEvent := core.SubOptsFirstEventOldest
+	ep.conf.firstEvent = &firstEvent
+	ep.conf.schemaVersion = core.OffsetSchemaVersion
+	defer cancel()
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, "test").Return(nil, nil).Once()
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, "test").Return(&core.Offset{Current: -1, SchemaVersion: core.OffsetSchemaVersion}, nil).Once()
+	mdi.On("UpsertOffset", mock.Anything, mock.MatchedBy(func(offset *core.Offset) bool {
+		return offset.Current == -1 && offset.SchemaVersion == core.OffsetSchemaVersion
+	}), false).Return(nil)
+	err := ep.restoreOffset()
+	assert.NoError(t, err)
+	mdi.AssertExpectations(t)
+}
+
+func TestRestoreOffsetSchemaMismatchFail(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	ep.conf.schemaVersion = core.OffsetSchemaVersion
+	ep.conf.startupOffsetRetryAttempts = 0 // would retry forever, if the error was retryable
+	defer cancel()
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, "test").Return(&core.Offset{RowID: 1, Current: 12345}, nil).Once()
+	err := ep.restoreOffset()
+	assert.Regexp(t, "FF10499", err)
+	mdi.AssertExpectations(t)
+}
+
+func TestRestoreOffsetSchemaMismatchReset(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	firstEvent := core.SubOptsFirstEventOldest
+	ep.conf.firstEvent = &firstEvent
+	ep.conf.schemaVersion = core.OffsetSchemaVersion
+	ep.conf.resetOnSchemaMismatch = true
+	defer cancel()
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, "test").Return(&core.Offset{RowID: 1, Current: 12345}, nil)
+	mdi.On("UpdateOffset", mock.Anything, int64(1), mock.MatchedBy(func(u ffapi.Update) bool {
+		info, _ := u.Finalize()
+		return len(info.SetOperations) == 2
+	})).Return(nil)
+	err := ep.restoreOffset()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), ep.pollingOffset)
+	assert.Equal(t, int64(1), ep.offsetID)
+	mdi.AssertExpectations(t)
+}
+
+func TestRestoreOffsetSchemaMismatchResetFail(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	ep.conf.schemaVersion = core.OffsetSchemaVersion
+	ep.conf.resetOnSchemaMismatch = true
+	defer cancel()
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, "test").Return(&core.Offset{RowID: 1, Current: 12345}, nil)
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{{Sequence: 23456}}, nil, nil)
+	mdi.On("UpdateOffset", mock.Anything, int64(1), mock.Anything).Return(fmt.Errorf("pop"))
+	err := ep.restoreOffset()
+	assert.EqualError(t, err, "pop")
+	mdi.AssertExpectations(t)
+}
+
+func TestRestoreOffsetSchemaMismatchResetFirstEventFail(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	ep.conf.schemaVersion = core.OffsetSchemaVersion
+	ep.conf.resetOnSchemaMismatch = true
+	defer cancel()
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, "test").Return(&core.Offset{RowID: 1, Current: 12345}, nil)
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := ep.restoreOffset()
+	assert.EqualError(t, err, "pop")
+	mdi.AssertExpectations(t)
+}
+
 func TestReadPageExit(t *testing.T) {
 	mdi := &databasemocks.Plugin{}
 	ep, cancel := newTestEventPoller(mdi, nil, nil)
@@ -232,7 +399,7 @@ func TestReadPageBatchTimeoutNotFull(t *testing.T) {
 	ev2 := core.NewEvent(core.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "")
 	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{ev1}, nil, nil).Once() // half batch
 	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{ev1, ev2}, nil, nil).Run(func(args mock.Arguments) {
-		ep.shoulderTap()
+		ep.ShoulderTap()
 	}).Once()
 	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return(nil, nil, fmt.Errorf("context done")).Run(func(args mock.Arguments) {
 		cancel()
@@ -257,7 +424,7 @@ func TestReadPageBatchFull(t *testing.T) {
 	ev1 := core.NewEvent(core.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "")
 	ev2 := core.NewEvent(core.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "")
 	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{ev1, ev2}, nil, nil).Run(func(args mock.Arguments) {
-		ep.shoulderTap()
+		ep.ShoulderTap()
 	}).Once()
 	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return(nil, nil, fmt.Errorf("context done")).Run(func(args mock.Arguments) {
 		cancel()
@@ -270,6 +437,174 @@ func TestReadPageBatchFull(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestReadPageBatchTimeoutSendsPartialBatch(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	processEventCalled := make(chan []core.LocallySequenced, 1)
+	ep, cancel := newTestEventPoller(mdi, func(events []core.LocallySequenced) (bool, error) {
+		processEventCalled <- events
+		return true, nil
+	}, nil)
+	ep.conf.eventBatchTimeout = 10 * time.Millisecond
+	ep.conf.eventBatchSize = 3
+	ev1 := core.NewEvent(core.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "")
+	ev2 := core.NewEvent(core.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "")
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{ev1}, nil, nil).Run(func(args mock.Arguments) {
+		ep.ShoulderTap() // woken for a second event, but the batch is not yet full
+	}).Once()
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{ev1, ev2}, nil, nil).Twice()
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return(nil, nil, fmt.Errorf("context done")).Run(func(args mock.Arguments) {
+		cancel()
+	})
+	go ep.eventLoop()
+
+	events := <-processEventCalled
+	assert.Len(t, events, 2)
+	<-ep.closed
+	mdi.AssertExpectations(t)
+}
+
+func TestReadPageBatchTimeoutByTypeFlushesEarly(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	processEventCalled := make(chan []core.LocallySequenced, 1)
+	ep, cancel := newTestEventPoller(mdi, func(events []core.LocallySequenced) (bool, error) {
+		processEventCalled <- events
+		return true, nil
+	}, nil)
+	ep.conf.eventBatchTimeout = 1 * time.Minute // the test would hang if the batch waited for this
+	ep.conf.eventBatchSize = 3
+	ep.conf.eventBatchTimeoutOverrides = map[core.EventType]time.Duration{
+		core.EventTypeMessageConfirmed: 10 * time.Millisecond,
+	}
+	ev1 := core.NewEvent(core.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "")
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{ev1}, nil, nil).Twice()
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return(nil, nil, fmt.Errorf("context done")).Run(func(args mock.Arguments) {
+		cancel()
+	})
+	go ep.eventLoop()
+
+	events := <-processEventCalled
+	assert.Len(t, events, 1)
+	<-ep.closed
+	mdi.AssertExpectations(t)
+}
+
+func TestReadPageBatchTimeoutByTypeShorterTypeJoins(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	processEventCalled := make(chan []core.LocallySequenced, 1)
+	ep, cancel := newTestEventPoller(mdi, func(events []core.LocallySequenced) (bool, error) {
+		processEventCalled <- events
+		return true, nil
+	}, nil)
+	ep.conf.eventBatchTimeout = 1 * time.Minute
+	ep.conf.eventBatchSize = 3
+	ep.conf.eventBatchTimeoutOverrides = map[core.EventType]time.Duration{
+		core.EventTypeMessageConfirmed:     30 * time.Second,
+		core.EventTypeTransactionSubmitted: 10 * time.Millisecond,
+	}
+	ev1 := core.NewEvent(core.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "")
+	ev2 := core.NewEvent(core.EventTypeTransactionSubmitted, "ns1", fftypes.NewUUID(), nil, "")
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{ev1}, nil, nil).Run(func(args mock.Arguments) {
+		ep.ShoulderTap() // woken for an event with a shorter timeout
+	}).Once()
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{ev1, ev2}, nil, nil).Twice()
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return(nil, nil, fmt.Errorf("context done")).Run(func(args mock.Arguments) {
+		cancel()
+	})
+	go ep.eventLoop()
+
+	events := <-processEventCalled
+	assert.Len(t, events, 2)
+	<-ep.closed
+	mdi.AssertExpectations(t)
+}
+
+func TestReadPageBatchTimeoutByTypeOtherTypesWait(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	processEventCalled := make(chan []core.LocallySequenced, 1)
+	ep, cancel := newTestEventPoller(mdi, func(events []core.LocallySequenced) (bool, error) {
+		processEventCalled <- events
+		return true, nil
+	}, nil)
+	ep.conf.eventBatchTimeout = 1 * time.Minute
+	ep.conf.eventBatchSize = 3
+	ep.conf.eventBatchTimeoutOverrides = map[core.EventType]time.Duration{
+		core.EventTypeMessageConfirmed: 10 * time.Millisecond,
+	}
+	ev1 := core.NewEvent(core.EventTypeTransactionSubmitted, "ns1", fftypes.NewUUID(), nil, "")
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{ev1}, nil, nil).Once()
+	// Only read again once the poller is cancelled
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return(nil, nil, fmt.Errorf("context done"))
+	go ep.eventLoop()
+
+	// The batch is not flushed, as only the global batch timer is running
+	select {
+	case <-processEventCalled:
+		assert.Fail(t, "batch flushed early")
+	case <-time.After(50 * time.Millisecond):
+	}
+	cancel()
+	<-ep.closed
+	mdi.AssertExpectations(t)
+}
+
+func TestTypeBatchTimeout(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	defer cancel()
+	ep.conf.eventBatchTimeoutOverrides = map[core.EventType]time.Duration{
+		core.EventTypeMessageConfirmed:     20 * time.Millisecond,
+		core.EventTypeTransactionSubmitted: 10 * time.Millisecond,
+	}
+
+	_, ok := ep.typeBatchTimeout([]core.LocallySequenced{
+		&core.Pin{Sequence: 1},
+		core.NewEvent(core.EventTypeMessageRejected, "ns1", fftypes.NewUUID(), nil, ""),
+	})
+	assert.False(t, ok)
+
+	timeout, ok := ep.typeBatchTimeout([]core.LocallySequenced{
+		core.NewEvent(core.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, ""),
+		core.NewEvent(core.EventTypeTransactionSubmitted, "ns1", fftypes.NewUUID(), nil, ""),
+		core.NewEvent(core.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, ""),
+	})
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Millisecond, timeout)
+}
+
+func TestWaitForBatchFillOrTimeoutTypeTimer(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	defer cancel()
+	assert.True(t, ep.waitForBatchFillOrTimeout(time.NewTimer(1*time.Minute), time.NewTimer(1*time.Microsecond)))
+}
+
+func TestReadPageBatchFilledBeforeTimeout(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	processEventCalled := make(chan []core.LocallySequenced, 1)
+	ep, cancel := newTestEventPoller(mdi, func(events []core.LocallySequenced) (bool, error) {
+		processEventCalled <- events
+		return false, nil
+	}, nil)
+	ep.conf.eventBatchTimeout = 1 * time.Minute // the batch fills long before this
+	ep.conf.eventBatchSize = 2
+	ev1 := core.NewEvent(core.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "")
+	ev2 := core.NewEvent(core.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "")
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{ev1}, nil, nil).Run(func(args mock.Arguments) {
+		ep.ShoulderTap()
+	}).Once()
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{ev1, ev2}, nil, nil).Run(func(args mock.Arguments) {
+		ep.ShoulderTap()
+	}).Once()
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return(nil, nil, fmt.Errorf("context done")).Run(func(args mock.Arguments) {
+		cancel()
+	})
+	ep.eventLoop()
+
+	events := <-processEventCalled
+	assert.Len(t, events, 2)
+	mdi.AssertExpectations(t)
+}
+
 func TestReadPageRewind(t *testing.T) {
 	mdi := &databasemocks.Plugin{}
 	processEventCalled := make(chan core.LocallySequenced, 1)
@@ -298,6 +633,69 @@ func TestReadPageRewind(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestReadPageEventTypeFilter(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	processEventCalled := make(chan core.LocallySequenced, 1)
+	ep, cancel := newTestEventPoller(mdi, func(events []core.LocallySequenced) (bool, error) {
+		processEventCalled <- events[0]
+		return false, nil
+	}, nil)
+	ep.conf.eventTypeFilter = []core.EventType{core.EventTypeMessageConfirmed, core.EventTypeMessageRejected}
+	cancel()
+	ev1 := core.NewEvent(core.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "")
+	mdi.On("GetEvents", mock.Anything, "unit", mock.MatchedBy(func(filter ffapi.Filter) bool {
+		f, err := filter.Finalize()
+		assert.NoError(t, err)
+		assert.Len(t, f.Children, 2)
+		assert.Equal(t, "type", f.Children[1].Field)
+		assert.Equal(t, ffapi.FilterOpIn, f.Children[1].Op)
+		assert.Len(t, f.Children[1].Values, 2)
+		return true
+	})).Return([]*core.Event{ev1}, nil, nil).Once()
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{}, nil, nil)
+	ep.eventLoop()
+
+	event := <-processEventCalled
+	assert.Equal(t, *ev1.ID, *event.(*core.Event).ID)
+	mdi.AssertExpectations(t)
+}
+
+func TestReadPageEventTypeFilterAdvancesOffset(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	defer cancel()
+	ep.conf.eventTypeFilter = []core.EventType{core.EventTypeMessageConfirmed}
+	ep.pollingOffset = 10
+	ep.eventNotifier.latestSequence = 50
+
+	// Nothing matches, so the offset moves to the latest sequence notified before the query
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{}, nil, nil).Once()
+	items, err := ep.readPage()
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+	assert.Equal(t, int64(50), ep.getPollingOffset())
+	assert.Equal(t, int64(50), <-ep.offsetCommitted)
+
+	// A matching event leaves the offset to be moved by dispatch
+	ep.eventNotifier.latestSequence = 60
+	ev1 := core.NewEvent(core.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "")
+	ev1.Sequence = 55
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{ev1}, nil, nil).Once()
+	items, err = ep.readPage()
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, int64(50), ep.getPollingOffset())
+
+	// Nothing new has been notified
+	ep.eventNotifier.latestSequence = 50
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{}, nil, nil).Once()
+	_, err = ep.readPage()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(50), ep.getPollingOffset())
+
+	mdi.AssertExpectations(t)
+}
+
 func TestReadPageProcessEventsRetryExit(t *testing.T) {
 	mdi := &databasemocks.Plugin{}
 	ep, cancel := newTestEventPoller(mdi, func(events []core.LocallySequenced) (bool, error) { return false, fmt.Errorf("pop") }, nil)
@@ -322,6 +720,56 @@ func TestProcessEventsFail(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestDispatchEventsRetriesExhausted(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	handlerCalls := 0
+	ep, cancel := newTestEventPoller(mdi, func(events []core.LocallySequenced) (bool, error) {
+		handlerCalls++
+		return false, fmt.Errorf("pop")
+	}, nil)
+	defer cancel()
+	ep.conf.maxAttempts = 3
+	exhaustedCalls := 0
+	ep.conf.retriesExhausted = func(events []core.LocallySequenced, attempts int, lastErr error) (bool, error) {
+		exhaustedCalls++
+		assert.Equal(t, 3, attempts)
+		assert.EqualError(t, lastErr, "pop")
+		return true, nil
+	}
+	repoll, err := ep.dispatchEventsRetry([]core.LocallySequenced{
+		core.NewEvent(core.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, ""),
+	})
+	assert.NoError(t, err)
+	assert.True(t, repoll)
+	assert.Equal(t, 3, handlerCalls)
+	assert.Equal(t, 1, exhaustedCalls)
+}
+
+func TestDispatchEventsRetriesExhaustedFailRetries(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	handlerCalls := 0
+	ep, cancel := newTestEventPoller(mdi, func(events []core.LocallySequenced) (bool, error) {
+		handlerCalls++
+		return false, fmt.Errorf("pop")
+	}, nil)
+	defer cancel()
+	ep.conf.maxAttempts = 1
+	exhaustedCalls := 0
+	ep.conf.retriesExhausted = func(events []core.LocallySequenced, attempts int, lastErr error) (bool, error) {
+		exhaustedCalls++
+		if exhaustedCalls == 1 {
+			return false, fmt.Errorf("bang")
+		}
+		return false, nil
+	}
+	_, err := ep.dispatchEventsRetry([]core.LocallySequenced{
+		core.NewEvent(core.EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, ""),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, handlerCalls)
+	assert.Equal(t, 2, exhaustedCalls)
+}
+
 func TestWaitForShoulderTapOrExitCloseBatch(t *testing.T) {
 	mdi := &databasemocks.Plugin{}
 	ep, cancel := newTestEventPoller(mdi, nil, nil)
@@ -364,7 +812,7 @@ func TestWaitForShoulderTapOrPollTimeoutTap(t *testing.T) {
 	ep, cancel := newTestEventPoller(mdi, nil, nil)
 	defer cancel()
 	ep.conf.eventPollTimeout = 10 * time.Second
-	ep.shoulderTap()
+	ep.ShoulderTap()
 	assert.True(t, ep.waitForShoulderTapOrPollTimeout(0))
 }
 
@@ -372,16 +820,23 @@ func TestDoubleTap(t *testing.T) {
 	mdi := &databasemocks.Plugin{}
 	ep, cancel := newTestEventPoller(mdi, nil, nil)
 	defer cancel()
-	ep.shoulderTap()
-	ep.shoulderTap() // this should not block
+	ep.ShoulderTap()
+	ep.ShoulderTap() // this should not block
 }
 
-func TestWaitForBatchTimeoutClosedContext(t *testing.T) {
+func TestWaitForBatchFillOrTimeoutClosedContext(t *testing.T) {
 	mdi := &databasemocks.Plugin{}
 	ep, cancel := newTestEventPoller(mdi, nil, nil)
-	ep.conf.eventBatchTimeout = 1 * time.Minute
 	cancel()
-	ep.waitForBatchTimeout()
+	assert.True(t, ep.waitForBatchFillOrTimeout(time.NewTimer(1*time.Minute), nil))
+}
+
+func TestWaitForBatchFillOrTimeoutTap(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	defer cancel()
+	ep.ShoulderTap()
+	assert.False(t, ep.waitForBatchFillOrTimeout(time.NewTimer(1*time.Minute), nil))
 }
 
 func TestDoubleConfirm(t *testing.T) {
@@ -421,3 +876,145 @@ func TestOffsetCommitLoopFail(t *testing.T) {
 
 	mdi.AssertExpectations(t)
 }
+
+func TestPauseResumeProcessesEventsInSequence(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	processed := make(chan core.LocallySequenced, 10)
+	var ep *eventPoller
+	ep, cancel := newTestEventPoller(mdi, func(events []core.LocallySequenced) (bool, error) {
+		for _, e := range events {
+			processed <- e
+		}
+		return false, ep.CommitOffset(ep.ctx, events[len(events)-1].LocalSequence())
+	}, nil)
+	defer cancel()
+
+	ev1 := &core.Event{ID: fftypes.NewUUID(), Sequence: 1}
+	ev2 := &core.Event{ID: fftypes.NewUUID(), Sequence: 2}
+	ev3 := &core.Event{ID: fftypes.NewUUID(), Sequence: 3}
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, "test").Return(&core.Offset{RowID: 1, Current: 0}, nil)
+	mdi.On("UpdateOffset", mock.Anything, int64(1), mock.Anything).Return(nil).Maybe()
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{ev1}, nil, nil).Once()
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{ev2, ev3}, nil, nil).Once()
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{}, nil, nil)
+
+	err := ep.Start()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), (<-processed).LocalSequence())
+
+	err = ep.Pause(context.Background())
+	assert.NoError(t, err)
+
+	// Events arriving while paused are not read
+	ep.ShoulderTap()
+	mdi.AssertNumberOfCalls(t, "GetEvents", 1)
+	assert.Empty(t, processed)
+
+	// ... until we resume, when they are processed in sequence
+	ep.Resume()
+	assert.Equal(t, int64(2), (<-processed).LocalSequence())
+	assert.Equal(t, int64(3), (<-processed).LocalSequence())
+
+	ep.Stop()
+}
+
+func TestPauseAlreadyPaused(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	defer cancel()
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, "test").Return(&core.Offset{RowID: 1, Current: 0}, nil)
+	mdi.On("GetEvents", mock.Anything, "unit", mock.Anything).Return([]*core.Event{}, nil, nil)
+
+	err := ep.Start()
+	assert.NoError(t, err)
+	assert.NoError(t, ep.Pause(context.Background()))
+	assert.NoError(t, ep.Pause(context.Background()))
+
+	// Closing while paused exits the event loop
+	ep.Stop()
+}
+
+func TestPauseClosed(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	defer cancel()
+	close(ep.closed)
+
+	err := ep.Pause(context.Background())
+	assert.Regexp(t, "FF10186", err)
+	assert.Nil(t, ep.resume)
+}
+
+func TestPauseContextCancelled(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	defer cancel()
+
+	ctx, ctxCancel := context.WithCancel(context.Background())
+	ctxCancel()
+	err := ep.Pause(ctx)
+	assert.Regexp(t, "FF00154", err)
+	assert.Nil(t, ep.resume)
+	assert.True(t, ep.waitWhilePaused())
+}
+
+func TestPauseResumedBeforePaused(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	defer cancel()
+
+	pauseErr := make(chan error)
+	go func() {
+		pauseErr <- ep.Pause(context.Background())
+	}()
+	for paused := false; !paused; {
+		ep.mux.Lock()
+		paused = ep.resume != nil
+		ep.mux.Unlock()
+	}
+	ep.Resume()
+	assert.NoError(t, <-pauseErr)
+}
+
+func TestResumeNotPaused(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	defer cancel()
+
+	ep.Resume()
+	assert.Nil(t, ep.resume)
+}
+
+func TestJitterDelay(t *testing.T) {
+	assert.Equal(t, 100*time.Millisecond, jitterDelay(100*time.Millisecond, 0))
+
+	last := time.Duration(0)
+	for i := 0; i < 100; i++ {
+		d := jitterDelay(100*time.Millisecond, 1.0)
+		assert.GreaterOrEqual(t, d, 100*time.Millisecond)
+		assert.LessOrEqual(t, d, 200*time.Millisecond)
+		assert.NotEqual(t, last, d)
+		last = d
+	}
+
+	d := jitterDelay(100*time.Millisecond, 5.0)
+	assert.LessOrEqual(t, d, 200*time.Millisecond)
+}
+
+func TestRetryDoJitter(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	ep, cancel := newTestEventPoller(mdi, nil, nil)
+	defer cancel()
+	ep.conf.retryJitter = 1.0
+
+	attempts := 0
+	err := ep.retryDo("test", func(attempt int) (bool, error) {
+		attempts = attempt
+		if attempt < 3 {
+			return true, fmt.Errorf("pop")
+		}
+		return false, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
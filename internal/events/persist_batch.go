@@ -159,6 +159,13 @@ func (em *eventManager) validateBatchData(ctx context.Context, batch *core.Batch
 		log.L(ctx).Errorf("Invalid data entry %d in batch '%s': Hash=%v Expected=%v", i, batch.ID, data.Hash, hash)
 		return false
 	}
+	// The type is not covered by the hash, so it must match the content (older nodes do not send it)
+	calcType := data.CalcType()
+	if data.Type != "" && data.Type != calcType {
+		log.L(ctx).Errorf("Invalid data entry %d in batch '%s': Type=%s Expected=%s", i, batch.ID, data.Type, calcType)
+		return false
+	}
+	data.Type = calcType
 
 	return true
 }
@@ -273,7 +280,7 @@ func (em *eventManager) persistBatchContent(ctx context.Context, batch *core.Bat
 		log.L(ctx).Debugf("Batch data insert optimization failed for batch '%s': %s", batch.ID, err)
 		// Fall back to individual upserts
 		for i, data := range batch.Payload.Data {
-			if err := em.database.UpsertData(ctx, data, database.UpsertOptimizationExisting); err != nil {
+			if err := em.database.UpsertData(ctx, data, database.UpsertOptimizationExisting, false); err != nil {
 				if err == database.HashMismatch {
 					log.L(ctx).Errorf("Invalid data entry %d in batch '%s'. Hash mismatch with existing record with same UUID '%s' Hash=%s", i, batch.ID, data.ID, data.Hash)
 					return false, nil
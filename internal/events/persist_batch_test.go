@@ -308,7 +308,7 @@ func TestPersistBatchContentDataHashMismatch(t *testing.T) {
 	batch := sampleBatch(t, core.BatchTypeBroadcast, core.TransactionTypeBatchPin, core.DataArray{data})
 
 	em.mdi.On("InsertDataArray", mock.Anything, mock.Anything).Return(fmt.Errorf("optimization miss"))
-	em.mdi.On("UpsertData", mock.Anything, mock.Anything, database.UpsertOptimizationExisting).Return(database.HashMismatch)
+	em.mdi.On("UpsertData", mock.Anything, mock.Anything, database.UpsertOptimizationExisting, false).Return(database.HashMismatch)
 
 	em.mim.On("GetLocalNode", mock.Anything).Return(testNode, nil)
 
@@ -318,6 +318,35 @@ func TestPersistBatchContentDataHashMismatch(t *testing.T) {
 
 }
 
+func TestPersistBatchContentDataTypeMismatch(t *testing.T) {
+
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+
+	data := &core.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"test"`)}
+	batch := sampleBatch(t, core.BatchTypeBroadcast, core.TransactionTypeBatchPin, core.DataArray{data})
+	batch.Payload.Data[0].Type = core.DataTypeBlob
+
+	valid := em.validateBatchData(em.ctx, batch, 0, batch.Payload.Data[0])
+	assert.False(t, valid)
+
+}
+
+func TestPersistBatchContentDataNoType(t *testing.T) {
+
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+
+	data := &core.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"test"`)}
+	batch := sampleBatch(t, core.BatchTypeBroadcast, core.TransactionTypeBatchPin, core.DataArray{data})
+	batch.Payload.Data[0].Type = ""
+
+	valid := em.validateBatchData(em.ctx, batch, 0, batch.Payload.Data[0])
+	assert.True(t, valid)
+	assert.Equal(t, core.DataTypeValue, batch.Payload.Data[0].Type)
+
+}
+
 func TestPersistBatchContentDataMissingBlobRef(t *testing.T) {
 
 	em := newTestEventManager(t)
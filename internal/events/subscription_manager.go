@@ -42,6 +42,7 @@ type subscription struct {
 	definition *core.Subscription
 
 	dispatcherElection chan bool
+	startupModeApplied bool
 	eventMatcher       *regexp.Regexp
 	messageFilter      *messageFilter
 	blockchainFilter   *blockchainFilter
@@ -53,6 +54,7 @@ type messageFilter struct {
 	groupFilter  *regexp.Regexp
 	tagFilter    *regexp.Regexp
 	authorFilter *regexp.Regexp
+	messageTag   string
 }
 
 type blockchainFilter struct {
@@ -94,6 +96,7 @@ type subscriptionManager struct {
 
 	defaultBatchSize    uint16
 	defaultBatchTimeout time.Duration
+	drainTimeout        time.Duration
 }
 
 func newSubscriptionManager(ctx context.Context, ns *core.Namespace, enricher *eventEnricher, di database.Plugin, dm data.Manager, en *eventNotifier, bm broadcast.Manager, pm privatemessaging.Manager, txHelper txcommon.Helper, transports map[string]events.Plugin) (*subscriptionManager, error) {
@@ -122,6 +125,7 @@ func newSubscriptionManager(ctx context.Context, ns *core.Namespace, enricher *e
 		},
 		defaultBatchSize:    uint16(config.GetInt(coreconfig.SubscriptionDefaultsBatchSize)),
 		defaultBatchTimeout: config.GetDuration(coreconfig.SubscriptionDefaultsBatchTimeout),
+		drainTimeout:        config.GetDuration(coreconfig.SubscriptionDrainTimeout),
 	}
 
 	for _, ei := range sm.transports {
@@ -255,6 +259,100 @@ func (sm *subscriptionManager) deletedDurableSubscription(id *fftypes.UUID) {
 	}
 }
 
+// deleteSubscription stops the local dispatchers for a subscription sending any new events, and waits
+// for their in-flight deliveries to complete before deleting it. If the drain times out, the
+// subscription is deleted anyway and the unacknowledged deliveries are logged.
+func (sm *subscriptionManager) deleteSubscription(ctx context.Context, id *fftypes.UUID) error {
+	sm.mux.Lock()
+	var dispatchers []*eventDispatcher
+	for _, conn := range sm.connections {
+		if dispatcher, ok := conn.dispatchers[*id]; ok {
+			dispatchers = append(dispatchers, dispatcher)
+		}
+	}
+	sm.mux.Unlock()
+
+	drained := make([]<-chan struct{}, len(dispatchers))
+	for i, dispatcher := range dispatchers {
+		drained[i] = dispatcher.startDrain()
+	}
+
+	drainCtx, cancel := context.WithTimeout(ctx, sm.drainTimeout)
+	defer cancel()
+	for i, dispatcher := range dispatchers {
+		select {
+		case <-drained[i]:
+		case <-drainCtx.Done():
+			log.L(ctx).Warnf("Timed out draining subscription %s on connection %s - deleting with unacknowledged deliveries: %v", id, dispatcher.connID, dispatcher.inflightIDs())
+		}
+	}
+
+	err := sm.database.DeleteSubscriptionByID(ctx, sm.namespace.Name, id)
+	if err != nil {
+		// The subscription still exists, so let the dispatchers carry on
+		for _, dispatcher := range dispatchers {
+			dispatcher.cancelDrain()
+		}
+	}
+	return err
+}
+
+// listSubscriptions returns the durable subscriptions in the namespace, with the live delivery
+// state of any dispatchers on this node
+func (sm *subscriptionManager) listSubscriptions(ctx context.Context, filter *core.SubscriptionListFilter) ([]*core.SubscriptionWithStatus, error) {
+	fb := database.SubscriptionQueryFactory.NewFilter(ctx)
+	dbFilter := fb.And()
+	if filter.Transport != "" {
+		dbFilter = dbFilter.Condition(fb.Eq("transport", filter.Transport))
+	}
+	subDefs, _, err := sm.database.GetSubscriptions(ctx, sm.namespace.Name, dbFilter.Limit(sm.maxSubs))
+	if err != nil {
+		return nil, err
+	}
+
+	latestSequence := sm.eventNotifier.getLatestSequence()
+	sm.mux.Lock()
+	defer sm.mux.Unlock()
+	results := make([]*core.SubscriptionWithStatus, 0, len(subDefs))
+	for _, subDef := range subDefs {
+		status := sm.subscriptionStatusLocked(subDef.ID, latestSequence)
+		if filter.DeliveryState != "" && status.DeliveryState != filter.DeliveryState {
+			continue
+		}
+		results = append(results, &core.SubscriptionWithStatus{
+			Subscription: *subDef.WithoutSecrets(),
+			Status:       *status,
+		})
+	}
+	return results, nil
+}
+
+func (sm *subscriptionManager) subscriptionStatusLocked(id *fftypes.UUID, latestSequence int64) *core.SubscriptionStatus {
+	status := &core.SubscriptionStatus{
+		DeliveryState: core.SubscriptionDeliveryStatePaused,
+	}
+	for _, conn := range sm.connections {
+		dispatcher, ok := conn.dispatchers[*id]
+		if !ok || dispatcher.isDraining() {
+			continue
+		}
+		// Only one dispatcher is elected to deliver, and it will have the highest offset
+		offset := dispatcher.eventPoller.getPollingOffset()
+		if status.DeliveryState != core.SubscriptionDeliveryStateActive || offset > status.CurrentOffset {
+			status.CurrentOffset = offset
+		}
+		status.DeliveryState = core.SubscriptionDeliveryStateActive
+	}
+	if status.DeliveryState == core.SubscriptionDeliveryStateActive {
+		lag := latestSequence - status.CurrentOffset
+		if lag < 0 {
+			lag = 0
+		}
+		status.DeliveryLag = &lag
+	}
+	return status
+}
+
 func (sm *subscriptionManager) getTransport(ctx context.Context, transportName string) (events.Plugin, error) {
 	transport, ok := sm.transports[transportName]
 	if !ok {
@@ -263,6 +361,36 @@ func (sm *subscriptionManager) getTransport(ctx context.Context, transportName s
 	return transport, nil
 }
 
+// matchesKnownEventType checks an events filter matches at least one registered event type,
+// as a filter that matches none would never deliver an event
+func matchesKnownEventType(eventFilter *regexp.Regexp) bool {
+	for _, eventType := range fftypes.FFEnumValues("eventtype") {
+		if eventFilter.MatchString(eventType.(string)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchingEventTypes returns the registered event types an events filter matches, or nil
+// if there is no filter or it matches every type - in which case there is nothing to narrow
+func matchingEventTypes(eventFilter *regexp.Regexp) []core.EventType {
+	if eventFilter == nil {
+		return nil
+	}
+	allTypes := fftypes.FFEnumValues("eventtype")
+	eventTypes := make([]core.EventType, 0, len(allTypes))
+	for _, eventType := range allTypes {
+		if eventFilter.MatchString(eventType.(string)) {
+			eventTypes = append(eventTypes, core.EventType(eventType.(string)))
+		}
+	}
+	if len(eventTypes) == len(allTypes) {
+		return nil
+	}
+	return eventTypes
+}
+
 // nolint: gocyclo
 func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef *core.Subscription) (sub *subscription, err error) {
 	filter := subDef.Filter
@@ -286,6 +414,32 @@ func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef
 			defaultBatchTimeout := sm.defaultBatchTimeout.String()
 			subDef.Options.BatchTimeout = &defaultBatchTimeout
 		}
+		// The default batch size comes from config, so could still be zero
+		if *subDef.Options.ReadAhead == 0 {
+			return nil, i18n.NewError(ctx, coremsgs.MsgInvalidBatchSize)
+		}
+	}
+
+	if subDef.Options.StartupMode != nil {
+		switch *subDef.Options.StartupMode {
+		case core.SubOptsStartupModeResume, core.SubOptsStartupModeSkip, core.SubOptsStartupModeReplay:
+		default:
+			return nil, i18n.NewError(ctx, coremsgs.MsgInvalidStartupMode, *subDef.Options.StartupMode)
+		}
+	}
+
+	if subDef.Options.ErrorHandling != nil {
+		switch *subDef.Options.ErrorHandling {
+		case core.SubOptsErrorHandlingBlock, core.SubOptsErrorHandlingSkip, core.SubOptsErrorHandlingDeadLetter:
+		default:
+			return nil, i18n.NewError(ctx, coremsgs.MsgInvalidErrorHandling, *subDef.Options.ErrorHandling)
+		}
+	}
+
+	for _, ns := range filter.Namespaces {
+		if err := fftypes.ValidateFFNameField(ctx, ns, "filter.namespaces"); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := transport.ValidateOptions(ctx, &subDef.Options); err != nil {
@@ -298,6 +452,9 @@ func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef
 		if err != nil {
 			return nil, i18n.WrapError(ctx, err, coremsgs.MsgRegexpCompileFailed, "filter.events", filter.Events)
 		}
+		if !matchesKnownEventType(eventFilter) {
+			return nil, i18n.NewError(ctx, coremsgs.MsgNoMatchingEventType, filter.Events)
+		}
 	}
 
 	var tagFilter *regexp.Regexp
@@ -376,6 +533,7 @@ func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef
 			tagFilter:    tagFilter,
 			groupFilter:  groupFilter,
 			authorFilter: authorFilter,
+			messageTag:   filter.MessageTag,
 		},
 	}
 
@@ -574,6 +732,28 @@ func (sm *subscriptionManager) deliveryResponse(ei events.Plugin, connID string,
 	dispatcher.deliveryResponse(inflight)
 }
 
+// MatchesNotification is a cheap check on the un-enriched event, used to avoid waking
+// the dispatcher for events it will definitely filter out. Only the fields available
+// on the event itself can be checked, so a match here is not a guarantee of delivery.
+func (sub *subscription) MatchesNotification(event *core.Event) bool {
+	if sub.eventMatcher != nil && !sub.eventMatcher.MatchString(string(event.Type)) {
+		return false
+	}
+	if sub.topicFilter != nil && !sub.topicFilter.MatchString(event.Topic) {
+		return false
+	}
+	return true
+}
+
+func hasTag(tags fftypes.FFStringArray, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
 func (sub *subscription) MatchesEvent(event *core.EnrichedEvent) bool {
 	if sub.eventMatcher != nil && !sub.eventMatcher.MatchString(string(event.Type)) {
 		return false
@@ -627,6 +807,9 @@ func (sub *subscription) MatchesEvent(event *core.EnrichedEvent) bool {
 		if sub.messageFilter.groupFilter != nil && !sub.messageFilter.groupFilter.MatchString(group) {
 			return false
 		}
+		if sub.messageFilter.messageTag != "" && (msg == nil || !hasTag(msg.Tags, sub.messageFilter.messageTag)) {
+			return false
+		}
 	}
 
 	if sub.transactionFilter != nil {
@@ -20,10 +20,12 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"regexp"
 	"testing"
 	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/ffresty"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/internal/cache"
@@ -311,6 +313,154 @@ func TestCreateSubscriptionBadEventilter(t *testing.T) {
 	assert.Regexp(t, "FF10171.*events", err)
 }
 
+func TestCreateSubscriptionMessageTag(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything, mock.Anything).Return(nil)
+	sub, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Filter: core.SubscriptionFilter{
+			MessageTag: "invoice",
+		},
+		Transport: "ut",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "invoice", sub.messageFilter.messageTag)
+}
+
+func TestCreateSubscriptionUnknownEventType(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything, mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Filter: core.SubscriptionFilter{
+			Events: "^message_confirmd$",
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF10485.*message_confirmd", err)
+}
+
+func TestCreateSubscriptionKnownEventType(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything, mock.Anything).Return(nil)
+	sub, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Filter: core.SubscriptionFilter{
+			Events: "^message_(confirmed|rejected)$",
+		},
+		Transport: "ut",
+	})
+	assert.NoError(t, err)
+	assert.True(t, sub.eventMatcher.MatchString(core.EventTypeMessageRejected.String()))
+	assert.Equal(t, []core.EventType{core.EventTypeMessageConfirmed, core.EventTypeMessageRejected}, matchingEventTypes(sub.eventMatcher))
+}
+
+func TestMatchingEventTypesUnfiltered(t *testing.T) {
+	assert.Nil(t, matchingEventTypes(nil))
+	assert.Nil(t, matchingEventTypes(regexp.MustCompile(".*")))
+}
+
+func TestCreateSubscriptionZeroBatchSize(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	sm.defaultBatchSize = 0
+	truthy := true
+	_, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				Batch: &truthy,
+			},
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF10486", err)
+}
+
+func TestCreateSubscriptionBadStartupMode(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	badMode := core.SubOptsStartupMode("rewind")
+	_, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				StartupMode: &badMode,
+			},
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF10483.*rewind", err)
+}
+
+func TestCreateSubscriptionStartupModeOk(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything, mock.Anything).Return(nil)
+	replay := core.SubOptsStartupModeReplay
+	sub, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				StartupMode: &replay,
+			},
+		},
+		Transport: "ut",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, core.SubOptsStartupModeReplay, *sub.definition.Options.StartupMode)
+}
+
+func TestCreateSubscriptionBadErrorHandling(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	badPolicy := core.SubOptsErrorHandling("retry")
+	_, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				ErrorHandling: &badPolicy,
+			},
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF10484.*retry", err)
+}
+
+func TestCreateSubscriptionErrorHandlingOk(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything, mock.Anything).Return(nil)
+	deadLetter := core.SubOptsErrorHandlingDeadLetter
+	sub, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				ErrorHandling: &deadLetter,
+			},
+		},
+		Transport: "ut",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, core.SubOptsErrorHandlingDeadLetter, *sub.definition.Options.ErrorHandling)
+}
+
+func TestCreateSubscriptionBadNamespacesFilter(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	_, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Filter: core.SubscriptionFilter{
+			Namespaces: []string{"ns2", "!bad"},
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF00140.*filter.namespaces", err)
+}
+
 func TestCreateSubscriptionBadTopicFilter(t *testing.T) {
 	mei := &eventsmocks.Plugin{}
 	sm, cancel := newTestSubManager(t, mei)
@@ -907,3 +1057,123 @@ func TestDeleteDurableSubscriptionOk(t *testing.T) {
 	assert.Empty(t, sm.durableSubs)
 	<-ed.closed
 }
+
+func TestDeleteSubscriptionDrainTimeout(t *testing.T) {
+	subID := fftypes.NewUUID()
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{ID: subID, Namespace: "ns1", Name: "sub1"},
+		},
+	}
+	ed, edCancel := newTestEventDispatcher(sub)
+	defer edCancel()
+	ev1 := fftypes.NewUUID()
+	ed.inflight[*ev1] = &core.Event{ID: ev1, Sequence: 100001}
+
+	mei := ed.transport.(*eventsmocks.Plugin)
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	sm.drainTimeout = 1 * time.Millisecond
+	sm.connections["conn1"] = &connection{
+		ei:          mei,
+		id:          "conn1",
+		dispatchers: map[fftypes.UUID]*eventDispatcher{*subID: ed},
+	}
+	mdi := sm.database.(*databasemocks.Plugin)
+	mdi.On("DeleteSubscriptionByID", mock.Anything, "ns1", subID).Return(nil)
+
+	err := sm.deleteSubscription(sm.ctx, subID)
+	assert.NoError(t, err)
+	assert.True(t, ed.isDraining())
+	assert.Equal(t, []*fftypes.UUID{ev1}, ed.inflightIDs())
+	mdi.AssertExpectations(t)
+}
+
+func TestDeleteSubscriptionFailResumesDispatch(t *testing.T) {
+	subID := fftypes.NewUUID()
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{ID: subID, Namespace: "ns1", Name: "sub1"},
+		},
+	}
+	ed, edCancel := newTestEventDispatcher(sub)
+	defer edCancel()
+
+	mei := ed.transport.(*eventsmocks.Plugin)
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	sm.connections["conn1"] = &connection{
+		ei:          mei,
+		id:          "conn1",
+		dispatchers: map[fftypes.UUID]*eventDispatcher{*subID: ed},
+	}
+	mdi := sm.database.(*databasemocks.Plugin)
+	mdi.On("DeleteSubscriptionByID", mock.Anything, "ns1", subID).Return(fmt.Errorf("pop"))
+
+	err := sm.deleteSubscription(sm.ctx, subID)
+	assert.Regexp(t, "pop", err)
+	assert.False(t, ed.isDraining())
+	mdi.AssertExpectations(t)
+}
+
+func TestListSubscriptionsDeliveryState(t *testing.T) {
+	sub1 := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"}}
+	sub2 := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub2"}}
+	sub3 := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub3"}}
+	sub3.Options.Secret = "shh"
+	ed1, ed1Cancel := newTestEventDispatcher(&subscription{definition: sub1})
+	defer ed1Cancel()
+	ed1.eventPoller.pollingOffset = 10
+	ed3, ed3Cancel := newTestEventDispatcher(&subscription{definition: sub3})
+	defer ed3Cancel()
+	ed3.startDrain()
+
+	mei := ed1.transport.(*eventsmocks.Plugin)
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	sm.eventNotifier.latestSequence = 15
+	sm.connections["conn1"] = &connection{
+		ei: mei,
+		id: "conn1",
+		dispatchers: map[fftypes.UUID]*eventDispatcher{
+			*sub1.ID: ed1,
+			*sub3.ID: ed3,
+		},
+	}
+	mdi := sm.database.(*databasemocks.Plugin)
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{sub1, sub2, sub3}, nil, nil)
+
+	subs, err := sm.listSubscriptions(sm.ctx, &core.SubscriptionListFilter{Transport: "websockets"})
+	assert.NoError(t, err)
+	fi, err := mdi.Calls[0].Arguments[2].(ffapi.Filter).Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, "( transport == 'websockets' ) limit=500", fi.String())
+	assert.Len(t, subs, 3)
+	assert.Equal(t, core.SubscriptionDeliveryStateActive, subs[0].Status.DeliveryState)
+	assert.Equal(t, int64(10), subs[0].Status.CurrentOffset)
+	assert.Equal(t, int64(5), *subs[0].Status.DeliveryLag)
+	assert.Equal(t, core.SubscriptionDeliveryStatePaused, subs[1].Status.DeliveryState)
+	assert.Nil(t, subs[1].Status.DeliveryLag)
+	assert.Equal(t, core.SubscriptionDeliveryStatePaused, subs[2].Status.DeliveryState)
+	assert.Empty(t, subs[2].Options.Secret)
+
+	subs, err = sm.listSubscriptions(sm.ctx, &core.SubscriptionListFilter{
+		Transport:     "websockets",
+		DeliveryState: core.SubscriptionDeliveryStatePaused,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, subs, 2)
+	assert.Equal(t, "sub2", subs[0].Name)
+	assert.Equal(t, "sub3", subs[1].Name)
+}
+
+func TestListSubscriptionsFail(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := sm.listSubscriptions(sm.ctx, &core.SubscriptionListFilter{})
+	assert.Regexp(t, "pop", err)
+}
@@ -19,7 +19,10 @@ package webhooks
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -53,12 +56,15 @@ type callbacks struct {
 	handlers  map[string]events.Callbacks
 }
 
+const signatureHeader = "X-FireFly-Signature"
+
 type whRequest struct {
 	r         *resty.Request
 	url       string
 	method    string
 	forceJSON bool
 	replyTx   string
+	secret    string
 }
 
 type whPayload struct {
@@ -192,6 +198,7 @@ func (wh *WebHooks) buildRequest(ctx context.Context, restyClient *resty.Client,
 		method:    options.GetString("method"),
 		forceJSON: options.GetBool("json"),
 		replyTx:   options.GetString("replytx"),
+		secret:    options.GetString("secret"),
 	}
 	if req.url == "" {
 		return nil, i18n.NewError(ctx, coremsgs.MsgWebhookURLEmpty)
@@ -263,6 +270,31 @@ func (wh *WebHooks) buildRequest(ctx context.Context, restyClient *resty.Client,
 	return req, err
 }
 
+// sign serializes the body exactly as it will be sent, so the receiver can verify the
+// HMAC-SHA256 in the signature header against the raw bytes it receives.
+// Only the body is signed, with no timestamp or nonce, so the signature does not protect against
+// a captured request being replayed - receivers are expected to ignore event IDs they have already seen.
+func (req *whRequest) sign(ctx context.Context) error {
+	var body []byte
+	switch b := req.r.Body.(type) {
+	case nil:
+	case string:
+		body = []byte(b)
+	default:
+		var err error
+		if body, err = json.Marshal(b); err != nil {
+			return i18n.WrapError(ctx, err, coremsgs.MsgWebhookSignFailed)
+		}
+	}
+	if body != nil {
+		req.r.SetBody(body)
+	}
+	mac := hmac.New(sha256.New, []byte(req.secret))
+	mac.Write(body)
+	req.r.SetHeader(signatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return nil
+}
+
 func (wh *WebHooks) ValidateOptions(ctx context.Context, options *core.SubscriptionOptions) error {
 	if options.WithData == nil {
 		defaultTrue := true
@@ -391,6 +423,12 @@ func (wh *WebHooks) attemptRequest(ctx context.Context, sub *core.Subscription,
 		req.r.SetBody(requestBody)
 	}
 
+	if req.secret != "" {
+		if err := req.sign(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	resp, err := req.r.Execute(req.method, req.url)
 	if err != nil {
 		log.L(ctx).Errorf("Webhook<- %s %s on subscription %s failed: %s", req.method, req.url, sub.ID, err)
@@ -435,12 +473,16 @@ func (wh *WebHooks) attemptRequest(ctx context.Context, sub *core.Subscription,
 	return req, res, nil
 }
 
-func (wh *WebHooks) doDelivery(ctx context.Context, connID string, reply bool, sub *core.Subscription, events []*core.CombinedEventDataDelivery, fastAck, batched bool) {
+func (wh *WebHooks) doDelivery(ctx context.Context, connID string, reply bool, sub *core.Subscription, events []*core.CombinedEventDataDelivery, fastAck, batched bool) error {
 	req, res, gwErr := wh.attemptRequest(ctx, sub, events, batched)
 	if gwErr != nil {
+		log.L(wh.ctx).Errorf("Failed to invoke webhook: %s", gwErr)
+		if !fastAck && sub.Options.ErrorHandling != nil {
+			// The dispatcher applies the error handling policy of the subscription to the failure
+			return gwErr
+		}
 		// Generate a bad-gateway error response - we always want to send something back,
 		// rather than just causing timeouts
-		log.L(wh.ctx).Errorf("Failed to invoke webhook: %s", gwErr)
 		b, _ := json.Marshal(&fftypes.RESTError{
 			Error: gwErr.Error(),
 		})
@@ -498,6 +540,7 @@ func (wh *WebHooks) doDelivery(ctx context.Context, connID string, reply bool, s
 		}
 	}
 
+	return nil
 }
 
 func (wh *WebHooks) DeliveryRequest(ctx context.Context, connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
@@ -534,9 +577,7 @@ func (wh *WebHooks) DeliveryRequest(ctx context.Context, connID string, sub *cor
 
 	// NOTE: We could check here for batching and accumulate but we can't return because this causes the offset to jump...
 
-	// TODO we don't look at the error here?
-	wh.doDelivery(ctx, connID, reply, sub, []*core.CombinedEventDataDelivery{{Event: event, Data: data}}, false, false)
-	return nil
+	return wh.doDelivery(ctx, connID, reply, sub, []*core.CombinedEventDataDelivery{{Event: event, Data: data}}, false, false)
 }
 
 func (wh *WebHooks) BatchDeliveryRequest(ctx context.Context, connID string, sub *core.Subscription, events []*core.CombinedEventDataDelivery) error {
@@ -584,8 +625,7 @@ func (wh *WebHooks) BatchDeliveryRequest(ctx context.Context, connID string, sub
 		return nil
 	}
 
-	wh.doDelivery(ctx, connID, reply, sub, events, false, true)
-	return nil
+	return wh.doDelivery(ctx, connID, reply, sub, events, false, true)
 }
 
 func (wh *WebHooks) NamespaceRestarted(ns string, startTime time.Time) {
@@ -18,14 +18,18 @@ package webhooks
 
 import (
 	"context"
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"io"
 	"log"
 	"math/big"
 	"net"
@@ -1474,3 +1478,256 @@ func TestRequestWithBodyReplyEndToEndWithBatch(t *testing.T) {
 func TestFirstDataNeverNil(t *testing.T) {
 	assert.NotNil(t, (&whPayload{}).firstData())
 }
+
+func TestWebhookFailErrorHandling(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	r := mux.NewRouter()
+	server := httptest.NewServer(r)
+	server.Close()
+
+	errorHandling := core.SubOptsErrorHandlingSkip
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			Namespace: "ns1",
+		},
+	}
+	sub.Options.ErrorHandling = &errorHandling
+	sub.Options.TransportOptions()["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	sub.Options.TransportOptions()["reply"] = true
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID: fftypes.NewUUID(),
+			},
+			Message: &core.Message{
+				Header: core.MessageHeader{
+					ID:   fftypes.NewUUID(),
+					Type: core.MessageTypeBroadcast,
+				},
+			},
+		},
+		Subscription: core.SubscriptionRef{
+			ID: sub.ID,
+		},
+	}
+
+	// No response is sent for the failed event, as the dispatcher applies the error handling policy
+	mcb := wh.callbacks.handlers["ns1"].(*eventsmocks.Callbacks)
+
+	err := wh.DeliveryRequest(wh.ctx, mock.Anything, sub, event, core.DataArray{})
+	assert.Error(t, err)
+
+	err = wh.BatchDeliveryRequest(wh.ctx, mock.Anything, sub, []*core.CombinedEventDataDelivery{{Event: event}})
+	assert.Error(t, err)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestRequestSignedWithSecret(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	msgID := fftypes.NewUUID()
+
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		b, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write(b)
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), req.Header.Get("X-FireFly-Signature"))
+		var body fftypes.JSONObject
+		err = json.Unmarshal(b, &body)
+		assert.NoError(t, err)
+		assert.Equal(t, msgID.String(), body.GetObject("message").GetObject("header").GetString("id"))
+		res.WriteHeader(200)
+		called = true
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			Namespace: "ns1",
+		},
+	}
+	to := sub.Options.TransportOptions()
+	to["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	to["secret"] = "s3cret"
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:   fftypes.NewUUID(),
+				Type: core.EventTypeMessageConfirmed,
+			},
+			Message: &core.Message{
+				Header: core.MessageHeader{
+					ID: msgID,
+				},
+			},
+		},
+		Subscription: core.SubscriptionRef{
+			ID:        sub.ID,
+			Namespace: "ns1",
+		},
+	}
+
+	mcb := wh.callbacks.handlers["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return !response.Rejected
+	})).Return(nil)
+
+	err := wh.DeliveryRequest(wh.ctx, mock.Anything, sub, event, nil)
+	assert.NoError(t, err)
+	assert.True(t, called)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestRequestSignedStringBody(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		b, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"inputfield":"inputvalue"}`, string(b))
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write(b)
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), req.Header.Get("X-FireFly-Signature"))
+		res.WriteHeader(200)
+		called = true
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	yes := true
+	dataID := fftypes.NewUUID()
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			Namespace: "ns1",
+		},
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				WithData: &yes,
+			},
+		},
+	}
+	to := sub.Options.TransportOptions()
+	to["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	to["secret"] = "s3cret"
+	to["input"] = map[string]interface{}{
+		"body": "in_body",
+	}
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID: fftypes.NewUUID(),
+			},
+		},
+		Subscription: core.SubscriptionRef{
+			ID:        sub.ID,
+			Namespace: "ns1",
+		},
+	}
+	data := &core.Data{
+		ID:    dataID,
+		Value: fftypes.JSONAnyPtr(`{"in_body": {"inputfield": "inputvalue"}}`),
+	}
+
+	mcb := wh.callbacks.handlers["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.Anything).Return(nil)
+
+	err := wh.DeliveryRequest(wh.ctx, mock.Anything, sub, event, core.DataArray{data})
+	assert.NoError(t, err)
+	assert.True(t, called)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestRequestSignedNoBody(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), req.Header.Get("X-FireFly-Signature"))
+		res.WriteHeader(200)
+		called = true
+	}).Methods(http.MethodGet)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			Namespace: "ns1",
+		},
+	}
+	to := sub.Options.TransportOptions()
+	to["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	to["method"] = http.MethodGet
+	to["secret"] = "s3cret"
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID: fftypes.NewUUID(),
+			},
+		},
+		Subscription: core.SubscriptionRef{
+			ID:        sub.ID,
+			Namespace: "ns1",
+		},
+	}
+
+	mcb := wh.callbacks.handlers["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.Anything).Return(nil)
+
+	err := wh.DeliveryRequest(wh.ctx, mock.Anything, sub, event, nil)
+	assert.NoError(t, err)
+	assert.True(t, called)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestRequestSignFail(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	yes := true
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			Namespace: "ns1",
+		},
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				WithData: &yes,
+			},
+		},
+	}
+	to := sub.Options.TransportOptions()
+	to["url"] = "http://localhost:12345/myapi"
+	to["secret"] = "s3cret"
+	event := &core.CombinedEventDataDelivery{
+		Event: &core.EventDelivery{
+			EnrichedEvent: core.EnrichedEvent{
+				Event: core.Event{
+					ID: fftypes.NewUUID(),
+				},
+			},
+		},
+		Data: core.DataArray{
+			{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`{}`)},
+			{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`!json`)},
+		},
+	}
+
+	_, _, err := wh.attemptRequest(wh.ctx, sub, []*core.CombinedEventDataDelivery{event}, false)
+	assert.Regexp(t, "FF10498", err)
+}
@@ -215,6 +215,12 @@ func (wc *websocketConnection) receiveLoop() {
 				if err == nil {
 					err = wc.authorizeMessage(msg.Namespace)
 				}
+				// The subscriber must also be authorized for any additional namespaces in the filter
+				for _, ns := range msg.Filter.Namespaces {
+					if err == nil {
+						err = wc.authorizeMessage(ns)
+					}
+				}
 				if err == nil {
 					err = wc.handleStart(&msg)
 				}
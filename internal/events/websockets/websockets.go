@@ -98,6 +98,11 @@ func (ws *WebSockets) ValidateOptions(ctx context.Context, options *core.Subscri
 	if options.WithData != nil && *options.WithData {
 		return i18n.NewError(ctx, coremsgs.MsgWebsocketsNoData)
 	}
+	// A failed delivery on a websocket means the connection has gone, not that the event cannot be
+	// processed - so the event must always be redelivered, rather than skipped or dead lettered
+	if options.ErrorHandling != nil && *options.ErrorHandling != core.SubOptsErrorHandlingBlock {
+		return i18n.NewError(ctx, coremsgs.MsgWebsocketsNoErrorHandling, *options.ErrorHandling)
+	}
 	forceFalse := false
 	options.WithData = &forceFalse
 	return nil
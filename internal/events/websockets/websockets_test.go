@@ -122,6 +122,28 @@ func TestValidateOptionsFail(t *testing.T) {
 	assert.Regexp(t, "FF10244", err)
 }
 
+func TestValidateOptionsErrorHandlingFail(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	ws, _, cancel := newTestWebsockets(t, cbs, nil)
+	defer cancel()
+
+	skip := core.SubOptsErrorHandlingSkip
+	err := ws.ValidateOptions(ws.ctx, &core.SubscriptionOptions{
+		SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+			ErrorHandling: &skip,
+		},
+	})
+	assert.Regexp(t, "FF10504.*skip", err)
+
+	block := core.SubOptsErrorHandlingBlock
+	err = ws.ValidateOptions(ws.ctx, &core.SubscriptionOptions{
+		SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+			ErrorHandling: &block,
+		},
+	})
+	assert.NoError(t, err)
+}
+
 func TestValidateOptionsOk(t *testing.T) {
 	cbs := &eventsmocks.Callbacks{}
 	ws, _, cancel := newTestWebsockets(t, cbs, nil)
@@ -572,6 +594,21 @@ func TestStartReceiveDurableUnauthorized(t *testing.T) {
 	assert.Regexp(t, "FF00169", res.GetString("error"))
 }
 
+func TestStartReceiveEphemeralUnauthorizedNamespace(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	_, wsc, cancel := newTestWebsockets(t, cbs, &testAuthorizer{})
+	defer cancel()
+
+	err := wsc.Send(context.Background(), []byte(`{"type":"start","namespace":"ns1","ephemeral":true,"filter":{"namespaces":["ns2"]}}`))
+	assert.NoError(t, err)
+
+	b := <-wsc.Receive()
+	var res fftypes.JSONObject
+	err = json.Unmarshal(b, &res)
+	assert.NoError(t, err)
+	assert.Regexp(t, "FF00169", res.GetString("error"))
+}
+
 func TestAutoStartReceiveAckEphemeral(t *testing.T) {
 	var connID string
 	cbs := &eventsmocks.Callbacks{}
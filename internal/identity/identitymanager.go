@@ -50,6 +50,7 @@ type Manager interface {
 	CachedIdentityLookupByID(ctx context.Context, id *fftypes.UUID) (identity *core.Identity, err error)
 	CachedIdentityLookupMustExist(ctx context.Context, did string) (identity *core.Identity, retryable bool, err error)
 	CachedIdentityLookupNilOK(ctx context.Context, did string) (identity *core.Identity, retryable bool, err error)
+	InvalidateCachedIdentity(ctx context.Context, identity *core.Identity) error
 	GetLocalNode(ctx context.Context) (node *core.Identity, err error)
 	GetRootOrgDID(ctx context.Context) (string, error)
 	GetRootOrg(ctx context.Context) (org *core.Identity, err error)
@@ -433,8 +434,21 @@ func (im *identityManager) validateParentType(ctx context.Context, child *core.I
 
 }
 
+// The identity cache is keyed by each of the ways an identity can be looked up
+func verifierCacheKey(namespace string, verifierType core.VerifierType, value string) string {
+	return fmt.Sprintf("ns=%s,type=%s,verifier=%s", namespace, verifierType, value)
+}
+
+func didCacheKey(namespace, didLookupStr string) string {
+	return fmt.Sprintf("ns=%s,did=%s", namespace, didLookupStr)
+}
+
+func idCacheKey(namespace string, id *fftypes.UUID) string {
+	return fmt.Sprintf("ns=%s,id=%s", namespace, id)
+}
+
 func (im *identityManager) cachedIdentityLookupByVerifierRef(ctx context.Context, namespace string, verifierRef *core.VerifierRef) (*core.Identity, error) {
-	cacheKey := fmt.Sprintf("ns=%s,type=%s,verifier=%s", namespace, verifierRef.Type, verifierRef.Value)
+	cacheKey := verifierCacheKey(namespace, verifierRef.Type, verifierRef.Value)
 	if cachedValue := im.identityCache.Get(cacheKey); cachedValue != nil {
 		return cachedValue.(*core.Identity), nil
 	}
@@ -463,7 +477,7 @@ func (im *identityManager) cachedIdentityLookupByVerifierRef(ctx context.Context
 
 func (im *identityManager) cachedIdentityLookup(ctx context.Context, namespace, didLookupStr string) (identity *core.Identity, retryable bool, err error) {
 	// Use an LRU cache for the author identity, as it's likely for the same identity to be re-used over and over
-	cacheKey := fmt.Sprintf("ns=%s,did=%s", namespace, didLookupStr)
+	cacheKey := didCacheKey(namespace, didLookupStr)
 	defer func() {
 		didResolved := ""
 		var uuidResolved *fftypes.UUID
@@ -529,7 +543,7 @@ func (im *identityManager) CachedIdentityLookupMustExist(ctx context.Context, di
 
 func (im *identityManager) cachedIdentityLookupByID(ctx context.Context, namespace string, id *fftypes.UUID) (identity *core.Identity, err error) {
 	// Use an LRU cache for the author identity, as it's likely for the same identity to be re-used over and over
-	cacheKey := fmt.Sprintf("ns=%s,id=%s", namespace, id)
+	cacheKey := idCacheKey(namespace, id)
 	if cachedValue := im.identityCache.Get(cacheKey); cachedValue != nil {
 		identity = cachedValue.(*core.Identity)
 	} else {
@@ -555,6 +569,28 @@ func (im *identityManager) CachedIdentityLookupByID(ctx context.Context, id *fft
 	return im.cachedIdentityLookupByID(ctx, im.namespace, id)
 }
 
+// InvalidateCachedIdentity evicts every cache entry that resolves to the given identity, so an update
+// to the identity is seen by the next lookup. Each node processes the same identity updates, so each
+// node evicts its own entries.
+func (im *identityManager) InvalidateCachedIdentity(ctx context.Context, identity *core.Identity) error {
+	fb := database.VerifierQueryFactory.NewFilter(ctx)
+	verifiers, _, err := im.database.GetVerifiers(ctx, identity.Namespace, fb.Eq("identity", identity.ID))
+	if err != nil {
+		return err
+	}
+	for _, verifier := range verifiers {
+		im.identityCache.Delete(verifierCacheKey(identity.Namespace, verifier.Type, verifier.Value))
+	}
+	im.identityCache.Delete(idCacheKey(identity.Namespace, identity.ID))
+	im.identityCache.Delete(didCacheKey(identity.Namespace, identity.DID))
+	if identity.Type == core.IdentityTypeOrg {
+		// Orgs can also be looked up by plain name, or by the UUID alias of the DID
+		im.identityCache.Delete(didCacheKey(identity.Namespace, identity.Name))
+		im.identityCache.Delete(didCacheKey(identity.Namespace, core.FireFlyOrgDIDPrefix+identity.ID.String()))
+	}
+	return nil
+}
+
 // Validate that the given identity or one of its ancestors owns the given node.
 func (im *identityManager) ValidateNodeOwner(ctx context.Context, node *core.Identity, identity *core.Identity) (valid bool, err error) {
 	l := log.L(ctx)
@@ -1040,6 +1040,73 @@ func TestCachedIdentityLookupByIDCaching(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestInvalidateCachedIdentity(t *testing.T) {
+
+	ctx, im := newTestIdentityManager(t)
+
+	id := &core.Identity{
+		IdentityBase: core.IdentityBase{
+			ID:        fftypes.NewUUID(),
+			DID:       "did:firefly:org/org1",
+			Namespace: "ns1",
+			Name:      "org1",
+			Type:      core.IdentityTypeOrg,
+		},
+	}
+	verifier := &core.Verifier{
+		Identity:  id.ID,
+		Namespace: "ns1",
+		VerifierRef: core.VerifierRef{
+			Type:  core.VerifierTypeEthAddress,
+			Value: "0x12345",
+		},
+	}
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", ctx, "ns1", id.ID).Return(id, nil).Times(4)
+	mdi.On("GetIdentityByDID", ctx, "ns1", id.DID).Return(id, nil).Twice()
+	mdi.On("GetIdentityByName", ctx, core.IdentityTypeOrg, "ns1", "org1").Return(id, nil).Twice()
+	mdi.On("GetVerifierByValue", ctx, core.VerifierTypeEthAddress, "ns1", "0x12345").Return(verifier, nil).Twice()
+	mdi.On("GetVerifiers", ctx, "ns1", mock.Anything).Return([]*core.Verifier{verifier}, nil, nil)
+
+	lookupAll := func() {
+		_, err := im.CachedIdentityLookupByID(ctx, id.ID)
+		assert.NoError(t, err)
+		_, _, err = im.CachedIdentityLookupNilOK(ctx, id.DID)
+		assert.NoError(t, err)
+		_, _, err = im.CachedIdentityLookupNilOK(ctx, "org1")
+		assert.NoError(t, err)
+		_, err = im.cachedIdentityLookupByVerifierRef(ctx, "ns1", &verifier.VerifierRef)
+		assert.NoError(t, err)
+	}
+
+	// The second round of lookups is served from the cache, and the third must go back to the DB
+	lookupAll()
+	lookupAll()
+	err := im.InvalidateCachedIdentity(ctx, id)
+	assert.NoError(t, err)
+	lookupAll()
+
+	mdi.AssertExpectations(t)
+}
+
+func TestInvalidateCachedIdentityFail(t *testing.T) {
+
+	ctx, im := newTestIdentityManager(t)
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetVerifiers", ctx, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := im.InvalidateCachedIdentity(ctx, &core.Identity{
+		IdentityBase: core.IdentityBase{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+		},
+	})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
 func TestVerifyIdentityChainCustomOrgOrgOk(t *testing.T) {
 
 	ctx, im := newTestIdentityManager(t)
@@ -0,0 +1,85 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var AggregatorEventsCounter *prometheus.CounterVec
+var AggregatorRetriesCounter *prometheus.CounterVec
+var AggregatorBatchHistogram *prometheus.HistogramVec
+var AggregatorBlockedContextsGauge *prometheus.GaugeVec
+var AggregatorLagGauge *prometheus.GaugeVec
+var AggregatorSequenceGapsCounter *prometheus.CounterVec
+
+// AggregatorEventsCounterName is the prometheus metric for tracking the total number of events emitted by the aggregator
+var AggregatorEventsCounterName = "ff_aggregator_events_total"
+
+// AggregatorRetriesCounterName is the prometheus metric for tracking the total number of times the aggregator retried a page of pins
+var AggregatorRetriesCounterName = "ff_aggregator_retries_total"
+
+// AggregatorBatchHistogramName is the prometheus metric for tracking the time taken to process each page of pins - histogram
+var AggregatorBatchHistogramName = "ff_aggregator_batch_histogram"
+
+// AggregatorBlockedContextsGaugeName is the prometheus metric for tracking the number of contexts blocked in the last page of pins
+var AggregatorBlockedContextsGaugeName = "ff_aggregator_blocked_contexts"
+
+// AggregatorLagGaugeName is the prometheus metric for tracking the number of pins between the latest pin and the committed aggregator offset
+var AggregatorLagGaugeName = "ff_aggregator_lag"
+
+// AggregatorSequenceGapsCounterName is the prometheus metric for tracking the total number of gaps the aggregator detected in the pin sequence
+var AggregatorSequenceGapsCounterName = "ff_aggregator_sequence_gaps_total"
+
+var NamespaceLabelName = "namespace"
+var EventTypeLabelName = "type"
+
+func InitAggregatorMetrics() {
+	AggregatorEventsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: AggregatorEventsCounterName,
+		Help: "Number of events emitted by the aggregator",
+	}, []string{NamespaceLabelName, EventTypeLabelName})
+	AggregatorRetriesCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: AggregatorRetriesCounterName,
+		Help: "Number of pages of pins the aggregator failed to process, and retried",
+	}, []string{NamespaceLabelName})
+	AggregatorBatchHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: AggregatorBatchHistogramName,
+		Help: "Histogram of pages of pins processed by the aggregator, bucketed by seconds to process",
+	}, []string{NamespaceLabelName})
+	AggregatorBlockedContextsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: AggregatorBlockedContextsGaugeName,
+		Help: "Number of contexts blocked in the last page of pins processed by the aggregator",
+	}, []string{NamespaceLabelName})
+	AggregatorLagGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: AggregatorLagGaugeName,
+		Help: "Number of pins between the latest pin and the committed aggregator offset",
+	}, []string{NamespaceLabelName})
+	AggregatorSequenceGapsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: AggregatorSequenceGapsCounterName,
+		Help: "Number of gaps detected by the aggregator in the sequence of pins",
+	}, []string{NamespaceLabelName})
+}
+
+func RegisterAggregatorMetrics() {
+	registry.MustRegister(AggregatorEventsCounter)
+	registry.MustRegister(AggregatorRetriesCounter)
+	registry.MustRegister(AggregatorBatchHistogram)
+	registry.MustRegister(AggregatorBlockedContextsGauge)
+	registry.MustRegister(AggregatorLagGauge)
+	registry.MustRegister(AggregatorSequenceGapsCounter)
+}
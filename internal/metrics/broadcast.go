@@ -24,6 +24,7 @@ var BroadcastSubmittedCounter prometheus.Counter
 var BroadcastConfirmedCounter prometheus.Counter
 var BroadcastRejectedCounter prometheus.Counter
 var BroadcastHistogram prometheus.Histogram
+var BroadcastReceiveLatencyHistogram prometheus.Histogram
 
 // BroadcastSubmittedCounterName is the prometheus metric for tracking the total number of broadcasts submitted
 var BroadcastSubmittedCounterName = "ff_broadcast_submitted_total"
@@ -37,6 +38,9 @@ var BroadcastRejectedCounterName = "ff_broadcast_rejected_total"
 // BroadcastHistogramName is the prometheus metric for tracking the total number of broadcast messages - histogram
 var BroadcastHistogramName = "ff_broadcast_histogram"
 
+// BroadcastReceiveLatencyHistogramName is the prometheus metric for tracking the time between a broadcast being created by the sender, and received by this node - histogram
+var BroadcastReceiveLatencyHistogramName = "ff_broadcast_receive_latency_histogram"
+
 func InitBroadcastMetrics() {
 	BroadcastSubmittedCounter = prometheus.NewCounter(prometheus.CounterOpts{
 		Name: BroadcastSubmittedCounterName,
@@ -54,6 +58,10 @@ func InitBroadcastMetrics() {
 		Name: BroadcastHistogramName,
 		Help: "Histogram of broadcasts, bucketed by time to finished",
 	})
+	BroadcastReceiveLatencyHistogram = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: BroadcastReceiveLatencyHistogramName,
+		Help: "Histogram of confirmed broadcasts, bucketed by seconds from creation by the sender to being received by this node",
+	})
 }
 
 func RegisterBroadcastMetrics() {
@@ -61,4 +69,5 @@ func RegisterBroadcastMetrics() {
 	registry.MustRegister(BroadcastConfirmedCounter)
 	registry.MustRegister(BroadcastRejectedCounter)
 	registry.MustRegister(BroadcastHistogram)
+	registry.MustRegister(BroadcastReceiveLatencyHistogram)
 }
@@ -0,0 +1,40 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+var dbStatsCollectors = map[string]prometheus.Collector{}
+
+// RegisterDatabaseStats exports the connection pool statistics (sql.DBStats) of a database plugin,
+// labelled with the plugin name. A collector previously registered for the same plugin, such as
+// before a config reload, is replaced.
+func RegisterDatabaseStats(name string, db *sql.DB) {
+	regMux.Lock()
+	defer regMux.Unlock()
+	if existing, ok := dbStatsCollectors[name]; ok {
+		Registry().Unregister(existing)
+	}
+	collector := collectors.NewDBStatsCollector(db, name)
+	Registry().MustRegister(collector)
+	dbStatsCollectors[name] = collector
+}
@@ -0,0 +1,52 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterDatabaseStats(t *testing.T) {
+	Clear()
+	defer Clear()
+
+	db1, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db1.Close()
+	db2, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db2.Close()
+
+	RegisterDatabaseStats("database0", db1)
+	// Registering again for the same plugin replaces the collector, rather than panicking
+	RegisterDatabaseStats("database0", db2)
+
+	families, err := Registry().Gather()
+	assert.NoError(t, err)
+	found := false
+	for _, f := range families {
+		if f.GetName() == "go_sql_open_connections" {
+			found = true
+			assert.Len(t, f.GetMetric(), 1)
+			assert.Equal(t, "database0", f.GetMetric()[0].GetLabel()[0].GetValue())
+		}
+	}
+	assert.True(t, found)
+}
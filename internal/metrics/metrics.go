@@ -39,6 +39,12 @@ type Manager interface {
 	BlockchainTransaction(location, methodName string)
 	BlockchainQuery(location, methodName string)
 	BlockchainEvent(location, signature string)
+	AggregatorEvent(namespace string, eventType fftypes.FFEnum)
+	AggregatorRetry(namespace string)
+	AggregatorBatchProcessed(namespace string, elapsed time.Duration)
+	AggregatorBlockedContexts(namespace string, count int)
+	AggregatorLag(namespace string, lag int64)
+	AggregatorSequenceGap(namespace string)
 	AddTime(id string)
 	GetTime(id string) time.Time
 	DeleteTime(id string)
@@ -91,6 +97,9 @@ func (mm *metricsManager) MessageConfirmed(msg *core.Message, eventType fftypes.
 		}
 		if eventType == core.EventTypeMessageConfirmed { // Broadcast Confirmed
 			BroadcastConfirmedCounter.Inc()
+			if msg.ReceivedAt != nil && msg.Header.Created != nil {
+				BroadcastReceiveLatencyHistogram.Observe(msg.ReceivedAt.Time().Sub(*msg.Header.Created.Time()).Seconds())
+			}
 		} else if eventType == core.EventTypeMessageRejected { // Broadcast Rejected
 			BroadcastRejectedCounter.Inc()
 		}
@@ -162,6 +171,30 @@ func (mm *metricsManager) BlockchainEvent(location, signature string) {
 	BlockchainEventsCounter.WithLabelValues(location, signature).Inc()
 }
 
+func (mm *metricsManager) AggregatorEvent(namespace string, eventType fftypes.FFEnum) {
+	AggregatorEventsCounter.WithLabelValues(namespace, eventType.String()).Inc()
+}
+
+func (mm *metricsManager) AggregatorRetry(namespace string) {
+	AggregatorRetriesCounter.WithLabelValues(namespace).Inc()
+}
+
+func (mm *metricsManager) AggregatorBatchProcessed(namespace string, elapsed time.Duration) {
+	AggregatorBatchHistogram.WithLabelValues(namespace).Observe(elapsed.Seconds())
+}
+
+func (mm *metricsManager) AggregatorBlockedContexts(namespace string, count int) {
+	AggregatorBlockedContextsGauge.WithLabelValues(namespace).Set(float64(count))
+}
+
+func (mm *metricsManager) AggregatorLag(namespace string, lag int64) {
+	AggregatorLagGauge.WithLabelValues(namespace).Set(float64(lag))
+}
+
+func (mm *metricsManager) AggregatorSequenceGap(namespace string) {
+	AggregatorSequenceGapsCounter.WithLabelValues(namespace).Inc()
+}
+
 func (mm *metricsManager) AddTime(id string) {
 	mutex.Lock()
 	mm.timeMap[id] = time.Now()
@@ -26,6 +26,7 @@ import (
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -92,6 +93,26 @@ func TestMessageConfirmedBroadcastSuccess(t *testing.T) {
 	assert.Equal(t, len(mm.timeMap), 0)
 }
 
+func TestMessageConfirmedBroadcastReceiveLatency(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	created := fftypes.FFTime(time.Now().Add(-2 * time.Second))
+	msg := &core.Message{
+		Header: core.MessageHeader{
+			ID:      fftypes.NewUUID(),
+			Type:    core.MessageTypeBroadcast,
+			Created: &created,
+		},
+		ReceivedAt: fftypes.Now(),
+	}
+	mm.MessageConfirmed(msg, core.EventTypeMessageConfirmed)
+	var m dto.Metric
+	err := BroadcastReceiveLatencyHistogram.Write(&m)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), m.Histogram.GetSampleCount())
+	assert.GreaterOrEqual(t, m.Histogram.GetSampleSum(), float64(2))
+}
+
 func TestMessageConfirmedBroadcastRejected(t *testing.T) {
 	mm, cancel := newTestMetricsManager(t)
 	defer cancel()
@@ -215,6 +236,70 @@ func TestBlockchainEvents(t *testing.T) {
 	assert.Equal(t, float64(1), v)
 }
 
+func TestAggregatorEvent(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.AggregatorEvent("ns1", core.EventTypeMessageConfirmed)
+	m, err := AggregatorEventsCounter.GetMetricWith(prometheus.Labels{NamespaceLabelName: "ns1", EventTypeLabelName: "message_confirmed"})
+	assert.NoError(t, err)
+	v := testutil.ToFloat64(m)
+	assert.Equal(t, float64(1), v)
+}
+
+func TestAggregatorRetry(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.AggregatorRetry("ns1")
+	mm.AggregatorRetry("ns1")
+	m, err := AggregatorRetriesCounter.GetMetricWith(prometheus.Labels{NamespaceLabelName: "ns1"})
+	assert.NoError(t, err)
+	v := testutil.ToFloat64(m)
+	assert.Equal(t, float64(2), v)
+}
+
+func TestAggregatorBatchProcessed(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.AggregatorBatchProcessed("ns1", 1500*time.Millisecond)
+	m := &dto.Metric{}
+	o, err := AggregatorBatchHistogram.GetMetricWith(prometheus.Labels{NamespaceLabelName: "ns1"})
+	assert.NoError(t, err)
+	err = o.(prometheus.Histogram).Write(m)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), m.Histogram.GetSampleCount())
+	assert.Equal(t, 1.5, m.Histogram.GetSampleSum())
+}
+
+func TestAggregatorBlockedContexts(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.AggregatorBlockedContexts("ns1", 3)
+	m, err := AggregatorBlockedContextsGauge.GetMetricWith(prometheus.Labels{NamespaceLabelName: "ns1"})
+	assert.NoError(t, err)
+	v := testutil.ToFloat64(m)
+	assert.Equal(t, float64(3), v)
+}
+
+func TestAggregatorLag(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.AggregatorLag("ns1", 42)
+	m, err := AggregatorLagGauge.GetMetricWith(prometheus.Labels{NamespaceLabelName: "ns1"})
+	assert.NoError(t, err)
+	v := testutil.ToFloat64(m)
+	assert.Equal(t, float64(42), v)
+}
+
+func TestAggregatorSequenceGap(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.AggregatorSequenceGap("ns1")
+	m, err := AggregatorSequenceGapsCounter.GetMetricWith(prometheus.Labels{NamespaceLabelName: "ns1"})
+	assert.NoError(t, err)
+	v := testutil.ToFloat64(m)
+	assert.Equal(t, float64(1), v)
+}
+
 func TestIsMetricsEnabledTrue(t *testing.T) {
 	mm, cancel := newTestMetricsManager(t)
 	defer cancel()
@@ -78,6 +78,7 @@ func Clear() {
 	registry = nil
 	adminInstrumentation = nil
 	restInstrumentation = nil
+	dbStatsCollectors = map[string]prometheus.Collector{}
 }
 
 func initMetricsCollectors() {
@@ -88,6 +89,7 @@ func initMetricsCollectors() {
 	InitTokenBurnMetrics()
 	InitBatchPinMetrics()
 	InitBlockchainMetrics()
+	InitAggregatorMetrics()
 }
 
 func registerMetricsCollectors() {
@@ -101,4 +103,5 @@ func registerMetricsCollectors() {
 	RegisterTokenTransferMetrics()
 	RegisterTokenBurnMetrics()
 	RegisterBlockchainMetrics()
+	RegisterAggregatorMetrics()
 }
@@ -67,10 +67,11 @@ type Manager interface {
 }
 
 type Config struct {
-	Enabled   bool
-	Org       RootOrg
-	Node      LocalNode
-	Contracts []blockchain.MultipartyContract
+	Enabled      bool
+	Org          RootOrg
+	Node         LocalNode
+	Contracts    []blockchain.MultipartyContract
+	AutoRegister bool
 }
 
 type RootOrg struct {
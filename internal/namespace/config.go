@@ -54,9 +54,13 @@ var (
 func InitConfig() {
 	namespacePredefined.AddKnownKey(coreconfig.NamespaceName)
 	namespacePredefined.AddKnownKey(coreconfig.NamespaceDescription)
+	namespacePredefined.AddKnownKey(coreconfig.NamespaceParent)
 	namespacePredefined.AddKnownKey(coreconfig.NamespacePlugins)
 	namespacePredefined.AddKnownKey(coreconfig.NamespaceDefaultKey)
 	namespacePredefined.AddKnownKey(coreconfig.NamespaceAssetKeyNormalization)
+	namespacePredefined.AddKnownKey(coreconfig.NamespaceRBACEnabled, false)
+	namespacePredefined.AddKnownKey(coreconfig.NamespaceEventAggregatorRateLimitBurst, 0)
+	namespacePredefined.AddKnownKey(coreconfig.NamespaceEventAggregatorRateLimitRate, 0)
 
 	multipartyConf := namespacePredefined.SubSection(coreconfig.NamespaceMultiparty)
 	multipartyConf.AddKnownKey(coreconfig.NamespaceMultipartyEnabled)
@@ -66,6 +70,7 @@ func InitConfig() {
 	multipartyConf.AddKnownKey(coreconfig.NamespaceMultipartyOrgKey)
 	multipartyConf.AddKnownKey(coreconfig.NamespaceMultipartyNodeName)
 	multipartyConf.AddKnownKey(coreconfig.NamespaceMultipartyNodeDescription)
+	multipartyConf.AddKnownKey(coreconfig.NamespaceMultipartyAutoRegister, false)
 
 	contractConf := multipartyConf.SubArray(coreconfig.NamespaceMultipartyContract)
 	contractConf.AddKnownKey(coreconfig.NamespaceMultipartyContractFirstEvent, string(core.SubOptsFirstEventOldest))
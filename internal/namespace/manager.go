@@ -19,7 +19,9 @@ package namespace
 import (
 	"context"
 	"crypto/tls"
+	"database/sql"
 	"fmt"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -69,6 +71,8 @@ type Manager interface {
 	GetNamespaces(ctx context.Context, includeInitializing bool) ([]*core.NamespaceWithInitStatus, error)
 	GetOperationByNamespacedID(ctx context.Context, nsOpID string) (*core.Operation, error)
 	ResolveOperationByNamespacedID(ctx context.Context, nsOpID string, op *core.OperationUpdateDTO) error
+	ListSubscriptions(ctx context.Context, filter *core.SubscriptionListFilter) ([]*core.SubscriptionWithStatus, error)
+	CopyData(ctx context.Context, ns, dataID, destNamespace string) (*core.Data, error)
 	Authorize(ctx context.Context, authReq *fftypes.AuthReq) error
 }
 
@@ -686,6 +690,11 @@ func (nm *namespaceManager) getSharedStoragePlugins(ctx context.Context, plugins
 	return nil
 }
 
+// sqlDatabase is implemented by database plugins built on a SQL connection pool
+type sqlDatabase interface {
+	DB() *sql.DB
+}
+
 func (nm *namespaceManager) initPlugins(pluginsToStart map[string]*plugin) (err error) {
 	for name, p := range nm.plugins {
 		if pluginsToStart[name] == nil {
@@ -697,6 +706,9 @@ func (nm *namespaceManager) initPlugins(pluginsToStart map[string]*plugin) (err
 				return err
 			}
 			p.database.SetHandler(database.GlobalHandler, nm)
+			if sqlDB, ok := p.database.(sqlDatabase); ok && nm.metricsEnabled {
+				metrics.RegisterDatabaseStats(name, sqlDB.DB())
+			}
 		case pluginCategoryBlockchain:
 			if err = p.blockchain.Init(p.ctx, nm.cancelCtx /* allow plugin to stop whole process */, p.config, nm.metrics, nm.cacheManager); err != nil {
 				return err
@@ -758,9 +770,28 @@ func (nm *namespaceManager) loadNamespaces(ctx context.Context, rawConfig fftype
 	if !foundDefault && size > 0 {
 		return nil, i18n.NewError(ctx, coremsgs.MsgDefaultNamespaceNotFound, defaultName)
 	}
+	if err = nm.validateNamespaceHierarchy(ctx, newNS); err != nil {
+		return nil, err
+	}
 	return newNS, err
 }
 
+func (nm *namespaceManager) validateNamespaceHierarchy(ctx context.Context, newNS map[string]*namespace) error {
+	for name, ns := range newNS {
+		visited := map[string]bool{name: true}
+		for parent := ns.ParentNamespace; parent != nil; parent = newNS[*parent].ParentNamespace {
+			if newNS[*parent] == nil {
+				return i18n.NewError(ctx, coremsgs.MsgNamespaceParentNotFound, *parent, name)
+			}
+			if visited[*parent] {
+				return i18n.NewError(ctx, coremsgs.MsgNamespaceParentCycle, *parent)
+			}
+			visited[*parent] = true
+		}
+	}
+	return nil
+}
+
 func (nm *namespaceManager) loadTLSConfig(ctx context.Context, tlsConfigs map[string]*tls.Config, conf config.ArraySection) (err error) {
 	tlsConfigArraySize := conf.ArraySize()
 
@@ -886,6 +917,9 @@ func (nm *namespaceManager) loadNamespace(ctx context.Context, name string, inde
 		TokenBroadcastNames:         nm.tokenBroadcastNames,
 		KeyNormalization:            keyNormalization,
 		MaxHistoricalEventScanLimit: config.GetInt(coreconfig.SubscriptionMaxHistoricalEventScanLength),
+		RBACEnabled:                 conf.GetBool(coreconfig.NamespaceRBACEnabled),
+		AggregatorRateLimitBurst:    conf.GetInt(coreconfig.NamespaceEventAggregatorRateLimitBurst),
+		AggregatorRateLimitRate:     conf.GetFloat64(coreconfig.NamespaceEventAggregatorRateLimitRate),
 	}
 	if multipartyEnabled.(bool) {
 		contractsConf := multipartyConf.SubArray(coreconfig.NamespaceMultipartyContract)
@@ -911,6 +945,7 @@ func (nm *namespaceManager) loadNamespace(ctx context.Context, name string, inde
 		config.Multiparty.Contracts = contracts
 		config.Multiparty.Node.Name = nodeName
 		config.Multiparty.Node.Description = nodeDesc
+		config.Multiparty.AutoRegister = multipartyConf.GetBool(coreconfig.NamespaceMultipartyAutoRegister)
 	}
 
 	ns = &namespace{
@@ -925,6 +960,9 @@ func (nm *namespaceManager) loadNamespace(ctx context.Context, name string, inde
 		configHash:  nm.configHash(rawNSConfig),
 		pluginNames: pluginNames,
 	}
+	if parent := conf.GetString(coreconfig.NamespaceParent); parent != "" {
+		ns.ParentNamespace = &parent
+	}
 	log.L(ctx).Tracef("Namespace %s config: %s", name, rawNSConfig.String())
 
 	if ns.plugins, err = nm.validateNSPlugins(ctx, ns, availablePlugins); err != nil {
@@ -940,6 +978,10 @@ func (nm *namespaceManager) loadNamespace(ctx context.Context, name string, inde
 		return nil, err
 	}
 
+	if ns.config.RBACEnabled && orchestrator.NewPrincipalAuthorizer(ns.plugins.Auth.Plugin) == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgNamespaceRBACNoPrincipalAuth, ns.Name)
+	}
+
 	ns.plugins.Events = make(map[string]events.Plugin)
 	for name, p := range nm.plugins {
 		if p.category == pluginCategoryEvents {
@@ -1104,6 +1146,67 @@ func (nm *namespaceManager) ResolveOperationByNamespacedID(ctx context.Context,
 	return or.Operations().ResolveOperationByID(ctx, u, op)
 }
 
+func (nm *namespaceManager) ListSubscriptions(ctx context.Context, filter *core.SubscriptionListFilter) ([]*core.SubscriptionWithStatus, error) {
+	switch filter.DeliveryState {
+	case "", core.SubscriptionDeliveryStateActive, core.SubscriptionDeliveryStatePaused:
+	default:
+		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidDeliveryState, filter.DeliveryState)
+	}
+
+	var orchestrators []orchestrator.Orchestrator
+	if filter.Namespace != "" {
+		or, err := nm.Orchestrator(ctx, filter.Namespace, false)
+		if err != nil {
+			return nil, err
+		}
+		orchestrators = append(orchestrators, or)
+	} else {
+		nm.nsMux.Lock()
+		names := make([]string, 0, len(nm.namespaces))
+		for name, ns := range nm.namespaces {
+			if ns.started {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			orchestrators = append(orchestrators, nm.namespaces[name].orchestrator)
+		}
+		nm.nsMux.Unlock()
+	}
+
+	results := []*core.SubscriptionWithStatus{}
+	for _, or := range orchestrators {
+		subs, err := or.Events().ListSubscriptions(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, subs...)
+	}
+	return results, nil
+}
+
+// CopyData copies a data record into another started namespace. The copy is written through the
+// database plugin of the source namespace, so both namespaces must share the same database plugin.
+func (nm *namespaceManager) CopyData(ctx context.Context, ns, dataID, destNamespace string) (*core.Data, error) {
+	or, err := nm.Orchestrator(ctx, ns, false)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := nm.Orchestrator(ctx, destNamespace, false); err != nil {
+		return nil, err
+	}
+
+	nm.nsMux.Lock()
+	sourceDB, destDB := nm.namespaces[ns].plugins.Database.Name, nm.namespaces[destNamespace].plugins.Database.Name
+	nm.nsMux.Unlock()
+	if sourceDB != destDB {
+		return nil, i18n.NewError(ctx, coremsgs.MsgCopyDataDifferentDatabase, ns, destNamespace)
+	}
+
+	return or.Data().CopyData(ctx, dataID, destNamespace)
+}
+
 func (nm *namespaceManager) getEventPlugins(ctx context.Context, plugins map[string]*plugin, rawConfig fftypes.JSONObject) (err error) {
 	enabledTransports := config.GetStringSlice(coreconfig.EventTransportsEnabled)
 	uniqueTransports := make(map[string]bool)
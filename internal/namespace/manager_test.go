@@ -23,6 +23,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"database/sql"
 	"encoding/pem"
 	"fmt"
 	"log"
@@ -33,9 +34,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/hyperledger/firefly-common/mocks/authmocks"
 	"github.com/hyperledger/firefly-common/pkg/auth"
 	"github.com/hyperledger/firefly-common/pkg/auth/authfactory"
+	"github.com/hyperledger/firefly-common/pkg/auth/basic"
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/retry"
@@ -54,6 +57,8 @@ import (
 	"github.com/hyperledger/firefly/mocks/cachemocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/eventmocks"
 	"github.com/hyperledger/firefly/mocks/eventsmocks"
 	"github.com/hyperledger/firefly/mocks/identitymocks"
 	"github.com/hyperledger/firefly/mocks/metricsmocks"
@@ -335,6 +340,46 @@ func TestInitComponentsPluginsFail(t *testing.T) {
 	assert.Regexp(t, "pop", err)
 }
 
+type testSQLDatabase struct {
+	*databasemocks.Plugin
+	db *sql.DB
+}
+
+func (tdb *testSQLDatabase) DB() *sql.DB {
+	return tdb.db
+}
+
+func TestInitDatabaseStatsMetrics(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+	metrics.Clear()
+	defer metrics.Clear()
+	nm.metricsEnabled = true
+
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	nmm.mdi.On("Init", mock.Anything, mock.Anything).Return(nil)
+	nmm.mdi.On("SetHandler", database.GlobalHandler, mock.Anything).Return()
+	nm.plugins["postgres"].database = &testSQLDatabase{Plugin: nmm.mdi, db: db}
+
+	err = nm.initPlugins(map[string]*plugin{
+		"postgres": nm.plugins["postgres"],
+	})
+	assert.NoError(t, err)
+
+	families, err := metrics.Registry().Gather()
+	assert.NoError(t, err)
+	found := false
+	for _, f := range families {
+		if f.GetName() == "go_sql_max_open_connections" {
+			found = true
+			assert.Equal(t, "postgres", f.GetMetric()[0].GetLabel()[0].GetValue())
+		}
+	}
+	assert.True(t, found)
+}
+
 func TestInitDatabaseFail(t *testing.T) {
 	nm, nmm, cleanup := newTestNamespaceManager(t, true)
 	defer cleanup()
@@ -1287,6 +1332,77 @@ func TestLoadNamespacesNoDefault(t *testing.T) {
 	assert.Regexp(t, "FF10166", err)
 }
 
+func TestLoadNamespacesParent(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	coreconfig.Reset()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+  namespaces:
+    default: ns1
+    predefined:
+    - name: ns1
+      plugins: [postgres]
+    - name: ns2
+      parent: ns1
+      plugins: [postgres]
+    - name: ns3
+      parent: ns2
+      plugins: [postgres]
+  `))
+	assert.NoError(t, err)
+
+	newNS, err := nm.loadNamespaces(context.Background(), nm.dumpRootConfig(), nm.plugins)
+	assert.NoError(t, err)
+	assert.Nil(t, newNS["ns1"].ParentNamespace)
+	assert.Equal(t, "ns1", *newNS["ns2"].ParentNamespace)
+	assert.Equal(t, "ns2", *newNS["ns3"].ParentNamespace)
+}
+
+func TestLoadNamespacesParentNotFound(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	coreconfig.Reset()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+  namespaces:
+    default: ns1
+    predefined:
+    - name: ns1
+      parent: ns2
+      plugins: [postgres]
+  `))
+	assert.NoError(t, err)
+
+	_, err = nm.loadNamespaces(context.Background(), nm.dumpRootConfig(), nm.plugins)
+	assert.Regexp(t, "FF10479.*ns2.*ns1", err)
+}
+
+func TestLoadNamespacesParentCycle(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	coreconfig.Reset()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+  namespaces:
+    default: ns1
+    predefined:
+    - name: ns1
+      parent: ns2
+      plugins: [postgres]
+    - name: ns2
+      parent: ns1
+      plugins: [postgres]
+  `))
+	assert.NoError(t, err)
+
+	_, err = nm.loadNamespaces(context.Background(), nm.dumpRootConfig(), nm.plugins)
+	assert.Regexp(t, "FF10480", err)
+}
+
 func TestLoadNamespacesUseDefaults(t *testing.T) {
 	nm, _, cleanup := newTestNamespaceManager(t, true)
 	defer cleanup()
@@ -1313,6 +1429,33 @@ func TestLoadNamespacesUseDefaults(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, newNS, 1)
 	assert.Equal(t, "oldest", newNS["ns1"].config.Multiparty.Contracts[0].FirstEvent)
+	assert.False(t, newNS["ns1"].config.Multiparty.AutoRegister)
+}
+
+func TestLoadNamespacesAutoRegister(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	coreconfig.Reset()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+  namespaces:
+    default: ns1
+    predefined:
+    - name: ns1
+      multiparty:
+        enabled: true
+        autoRegister: true
+        org:
+          name: org1
+        node:
+          name: node1
+  `))
+	assert.NoError(t, err)
+
+	newNS, err := nm.loadNamespaces(context.Background(), nm.dumpRootConfig(), nm.plugins)
+	assert.NoError(t, err)
+	assert.True(t, newNS["ns1"].config.Multiparty.AutoRegister)
 }
 
 func TestLoadTLSConfigsBadTLS(t *testing.T) {
@@ -1535,6 +1678,71 @@ func TestLoadNamespacesNonMultipartyNoDatabase(t *testing.T) {
 
 }
 
+func TestLoadNamespacesRBACNoAuthPlugin(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	coreconfig.Reset()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+  namespaces:
+    default: ns1
+    predefined:
+    - name: ns1
+      plugins: [postgres]
+      rbac:
+        enabled: true
+  `))
+	assert.NoError(t, err)
+
+	nm.namespaces, err = nm.loadNamespaces(context.Background(), nm.dumpRootConfig(), nm.plugins)
+	assert.Regexp(t, "FF10507", err)
+}
+
+func TestLoadNamespacesRBACAuthPluginNoPrincipal(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	coreconfig.Reset()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+  namespaces:
+    default: ns1
+    predefined:
+    - name: ns1
+      plugins: [postgres, basicauth]
+      rbac:
+        enabled: true
+  `))
+	assert.NoError(t, err)
+
+	nm.namespaces, err = nm.loadNamespaces(context.Background(), nm.dumpRootConfig(), nm.plugins)
+	assert.Regexp(t, "FF10507", err)
+}
+
+func TestLoadNamespacesRBACBasicAuth(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+	nm.plugins["basicauth"].auth = &basic.Auth{}
+
+	coreconfig.Reset()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+  namespaces:
+    default: ns1
+    predefined:
+    - name: ns1
+      plugins: [postgres, basicauth]
+      rbac:
+        enabled: true
+  `))
+	assert.NoError(t, err)
+
+	nm.namespaces, err = nm.loadNamespaces(context.Background(), nm.dumpRootConfig(), nm.plugins)
+	assert.NoError(t, err)
+	assert.True(t, nm.namespaces["ns1"].config.RBACEnabled)
+}
+
 func TestLoadNamespacesMultipartyUnknownPlugin(t *testing.T) {
 	nm, _, cleanup := newTestNamespaceManager(t, true)
 	defer cleanup()
@@ -2133,3 +2341,135 @@ func TestOrchestratorWhileInitializing(t *testing.T) {
 	_, err := nm.Orchestrator(nm.ctx, "default", false)
 	assert.Regexp(t, "FF10441", err)
 }
+
+func TestListSubscriptionsAllNamespaces(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	mo1 := &orchestratormocks.Orchestrator{}
+	mem1 := &eventmocks.EventManager{}
+	mo2 := &orchestratormocks.Orchestrator{}
+	mem2 := &eventmocks.EventManager{}
+	nm.namespaces = map[string]*namespace{
+		"ns2":          {orchestrator: mo2, started: true},
+		"ns1":          {orchestrator: mo1, started: true},
+		"initializing": {orchestrator: &orchestratormocks.Orchestrator{}},
+	}
+
+	filter := &core.SubscriptionListFilter{DeliveryState: core.SubscriptionDeliveryStateActive}
+	sub1 := &core.SubscriptionWithStatus{Subscription: core.Subscription{SubscriptionRef: core.SubscriptionRef{Namespace: "ns1"}}}
+	sub2 := &core.SubscriptionWithStatus{Subscription: core.Subscription{SubscriptionRef: core.SubscriptionRef{Namespace: "ns2"}}}
+	mo1.On("Events").Return(mem1)
+	mem1.On("ListSubscriptions", context.Background(), filter).Return([]*core.SubscriptionWithStatus{sub1}, nil)
+	mo2.On("Events").Return(mem2)
+	mem2.On("ListSubscriptions", context.Background(), filter).Return([]*core.SubscriptionWithStatus{sub2}, nil)
+
+	subs, err := nm.ListSubscriptions(context.Background(), filter)
+	assert.NoError(t, err)
+	assert.Equal(t, []*core.SubscriptionWithStatus{sub1, sub2}, subs)
+
+	mo1.AssertExpectations(t)
+	mem1.AssertExpectations(t)
+	mo2.AssertExpectations(t)
+	mem2.AssertExpectations(t)
+}
+
+func TestListSubscriptionsOneNamespaceFail(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	mo := &orchestratormocks.Orchestrator{}
+	mem := &eventmocks.EventManager{}
+	nm.namespaces = map[string]*namespace{
+		"ns1": {orchestrator: mo, started: true},
+		"ns2": {orchestrator: &orchestratormocks.Orchestrator{}, started: true},
+	}
+
+	filter := &core.SubscriptionListFilter{Namespace: "ns1"}
+	mo.On("Events").Return(mem)
+	mem.On("ListSubscriptions", context.Background(), filter).Return(nil, fmt.Errorf("pop"))
+
+	_, err := nm.ListSubscriptions(context.Background(), filter)
+	assert.Regexp(t, "pop", err)
+
+	mo.AssertExpectations(t)
+	mem.AssertExpectations(t)
+}
+
+func TestListSubscriptionsUnknownNamespace(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	_, err := nm.ListSubscriptions(context.Background(), &core.SubscriptionListFilter{Namespace: "unknown"})
+	assert.Regexp(t, "FF10436", err)
+}
+
+func TestListSubscriptionsBadDeliveryState(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	_, err := nm.ListSubscriptions(context.Background(), &core.SubscriptionListFilter{DeliveryState: "stopped"})
+	assert.Regexp(t, "FF10487.*stopped", err)
+}
+
+func TestCopyData(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	mo := &orchestratormocks.Orchestrator{}
+	mdm := &datamocks.Manager{}
+	dbPlugins := &orchestrator.Plugins{Database: orchestrator.DatabasePlugin{Name: "postgres"}}
+	nm.namespaces = map[string]*namespace{
+		"ns1": {orchestrator: mo, plugins: dbPlugins, started: true},
+		"ns2": {orchestrator: &orchestratormocks.Orchestrator{}, plugins: dbPlugins, started: true},
+	}
+	mo.On("Data").Return(mdm)
+	mdm.On("CopyData", context.Background(), "id1", "ns2").Return(&core.Data{Namespace: "ns2"}, nil)
+
+	data, err := nm.CopyData(context.Background(), "ns1", "id1", "ns2")
+	assert.NoError(t, err)
+	assert.Equal(t, "ns2", data.Namespace)
+
+	mdm.AssertExpectations(t)
+}
+
+func TestCopyDataUnknownSource(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	_, err := nm.CopyData(context.Background(), "unknown", "id1", "default")
+	assert.Regexp(t, "FF10436", err)
+}
+
+func TestCopyDataUnknownDest(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	nm.namespaces = map[string]*namespace{
+		"ns1": {orchestrator: &orchestratormocks.Orchestrator{}, started: true},
+	}
+
+	_, err := nm.CopyData(context.Background(), "ns1", "id1", "unknown")
+	assert.Regexp(t, "FF10436", err)
+}
+
+func TestCopyDataDifferentDatabase(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	nm.namespaces = map[string]*namespace{
+		"ns1": {
+			orchestrator: &orchestratormocks.Orchestrator{},
+			plugins:      &orchestrator.Plugins{Database: orchestrator.DatabasePlugin{Name: "postgres"}},
+			started:      true,
+		},
+		"ns2": {
+			orchestrator: &orchestratormocks.Orchestrator{},
+			plugins:      &orchestrator.Plugins{Database: orchestrator.DatabasePlugin{Name: "sqlite3"}},
+			started:      true,
+		},
+	}
+
+	_, err := nm.CopyData(context.Background(), "ns1", "id1", "ns2")
+	assert.Regexp(t, "FF10509", err)
+}
@@ -0,0 +1,105 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"database/sql/driver"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly-common/pkg/retry"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// bootstrap announces this node to the network on the first start of a multiparty namespace,
+// by registering the root org (if needed) and then the local node. Each registration waits
+// for confirmation, as the node can only be registered once its parent org is known.
+// Subsequent starts find the node identity already exists, and skip registration.
+//
+// Failures are retried with the namespace retry backoff, until registration succeeds or the
+// namespace is stopped.
+func (or *orchestrator) bootstrap(ctx context.Context) {
+	r := &retry.Retry{
+		InitialDelay: config.GetDuration(coreconfig.NamespacesRetryInitDelay),
+		MaximumDelay: config.GetDuration(coreconfig.NamespacesRetryMaxDelay),
+		Factor:       config.GetFloat64(coreconfig.NamespacesRetryFactor),
+	}
+	err := r.Do(ctx, "register local identities", func(attempt int) (retry bool, err error) {
+		return true, or.registerLocalIdentities(ctx)
+	})
+	if err != nil {
+		log.L(ctx).Errorf("Failed to register local identities: %s", err)
+	}
+}
+
+func (or *orchestrator) registerLocalIdentities(ctx context.Context) error {
+	node, err := or.identity.GetLocalNode(ctx)
+	if err != nil {
+		return err
+	}
+	if node != nil {
+		log.L(ctx).Debugf("Local node '%s' already registered", node.DID)
+		return nil
+	}
+
+	orgDID, err := or.identity.GetRootOrgDID(ctx)
+	if err != nil {
+		return err
+	}
+	// A claim from an earlier attempt (or an earlier run) might still be on its way to being confirmed.
+	// Broadcasting another would only be rejected as a duplicate, so we wait for it instead.
+	if err := or.checkNoPendingIdentityClaim(ctx, orgDID); err != nil {
+		return err
+	}
+	org, _, err := or.identity.CachedIdentityLookupNilOK(ctx, orgDID)
+	if err != nil {
+		return err
+	}
+	if org == nil {
+		log.L(ctx).Infof("Registering root org '%s'", orgDID)
+		if _, err := or.networkmap.RegisterNodeOrganization(ctx, true); err != nil {
+			return err
+		}
+	}
+
+	log.L(ctx).Infof("Registering local node '%s'", or.config.Multiparty.Node.Name)
+	_, err = or.networkmap.RegisterNode(ctx, true)
+	return err
+}
+
+// checkNoPendingIdentityClaim returns an error if an identity claim authored by the org has been sent, but not yet confirmed.
+// Both the org and node claims are authored by the org.
+func (or *orchestrator) checkNoPendingIdentityClaim(ctx context.Context, orgDID string) error {
+	fb := database.MessageQueryFactory.NewFilterLimit(ctx, 1)
+	msgs, _, err := or.database().GetMessages(ctx, or.namespace.Name, fb.And(
+		fb.Eq("tag", core.SystemTagIdentityClaim),
+		fb.Eq("author", orgDID),
+		fb.In("state", []driver.Value{core.MessageStateStaged, core.MessageStateReady, core.MessageStateSent}),
+	))
+	if err != nil {
+		return err
+	}
+	if len(msgs) > 0 {
+		return i18n.NewError(ctx, coremsgs.MsgIdentityClaimPending, orgDID)
+	}
+	return nil
+}
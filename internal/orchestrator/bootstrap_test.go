@@ -0,0 +1,149 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBootstrapRegistersOrgAndNode(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mim.On("GetLocalNode", mock.Anything).Return(nil, nil)
+	or.mim.On("GetRootOrgDID", mock.Anything).Return("did:firefly:org/org1", nil)
+	or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything).Return([]*core.Message{}, nil, nil)
+	or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:org/org1").Return(nil, false, nil)
+	or.mnm.On("RegisterNodeOrganization", mock.Anything, true).Return(&core.Identity{}, nil)
+	or.mnm.On("RegisterNode", mock.Anything, true).Return(&core.Identity{}, nil)
+
+	err := or.registerLocalIdentities(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestBootstrapOrgExists(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mim.On("GetLocalNode", mock.Anything).Return(nil, nil)
+	or.mim.On("GetRootOrgDID", mock.Anything).Return("did:firefly:org/org1", nil)
+	or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything).Return([]*core.Message{}, nil, nil)
+	or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:org/org1").Return(&core.Identity{}, false, nil)
+	or.mnm.On("RegisterNode", mock.Anything, true).Return(&core.Identity{}, nil)
+
+	err := or.registerLocalIdentities(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestBootstrapNodeExists(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mim.On("GetLocalNode", mock.Anything).Return(&core.Identity{
+		IdentityBase: core.IdentityBase{DID: "did:firefly:node/node1"},
+	}, nil)
+
+	err := or.registerLocalIdentities(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestBootstrapRetries(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	config.Set(coreconfig.NamespacesRetryInitDelay, "1ms")
+	or.mim.On("GetLocalNode", mock.Anything).Return(nil, fmt.Errorf("pop")).Once()
+	or.mim.On("GetLocalNode", mock.Anything).Return(&core.Identity{}, nil).Once()
+
+	or.bootstrap(context.Background())
+
+	or.mim.AssertExpectations(t)
+}
+
+func TestBootstrapStopped(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	or.mim.On("GetLocalNode", mock.Anything).Return(nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		cancel()
+	})
+
+	or.bootstrap(ctx)
+}
+
+func TestBootstrapPendingClaim(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mim.On("GetLocalNode", mock.Anything).Return(nil, nil)
+	or.mim.On("GetRootOrgDID", mock.Anything).Return("did:firefly:org/org1", nil)
+	or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything).Return([]*core.Message{{}}, nil, nil)
+
+	err := or.registerLocalIdentities(context.Background())
+	assert.Regexp(t, "FF10506", err)
+	or.mnm.AssertNotCalled(t, "RegisterNodeOrganization", mock.Anything, mock.Anything)
+	or.mnm.AssertNotCalled(t, "RegisterNode", mock.Anything, mock.Anything)
+}
+
+func TestBootstrapPendingClaimQueryFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mim.On("GetLocalNode", mock.Anything).Return(nil, nil)
+	or.mim.On("GetRootOrgDID", mock.Anything).Return("did:firefly:org/org1", nil)
+	or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := or.registerLocalIdentities(context.Background())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestBootstrapGetRootOrgDIDFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mim.On("GetLocalNode", mock.Anything).Return(nil, nil)
+	or.mim.On("GetRootOrgDID", mock.Anything).Return("", fmt.Errorf("pop"))
+
+	err := or.registerLocalIdentities(context.Background())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestBootstrapOrgLookupFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mim.On("GetLocalNode", mock.Anything).Return(nil, nil)
+	or.mim.On("GetRootOrgDID", mock.Anything).Return("did:firefly:org/org1", nil)
+	or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything).Return([]*core.Message{}, nil, nil)
+	or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:org/org1").Return(nil, true, fmt.Errorf("pop"))
+
+	err := or.registerLocalIdentities(context.Background())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestBootstrapRegisterOrgFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mim.On("GetLocalNode", mock.Anything).Return(nil, nil)
+	or.mim.On("GetRootOrgDID", mock.Anything).Return("did:firefly:org/org1", nil)
+	or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything).Return([]*core.Message{}, nil, nil)
+	or.mim.On("CachedIdentityLookupNilOK", mock.Anything, "did:firefly:org/org1").Return(nil, false, nil)
+	or.mnm.On("RegisterNodeOrganization", mock.Anything, true).Return(nil, fmt.Errorf("pop"))
+
+	err := or.registerLocalIdentities(context.Background())
+	assert.EqualError(t, err, "pop")
+}
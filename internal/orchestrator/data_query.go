@@ -32,6 +32,10 @@ func (or *orchestrator) GetNamespace(ctx context.Context) *core.Namespace {
 	return or.namespace
 }
 
+func (or *orchestrator) GetDescendantNamespaces(ctx context.Context) ([]*core.Namespace, error) {
+	return or.database().GetDescendantNamespaces(ctx, or.namespace.Name)
+}
+
 func (or *orchestrator) GetTransactionByID(ctx context.Context, id string) (*core.Transaction, error) {
 	u, err := fftypes.ParseUUID(ctx, id)
 	if err != nil {
@@ -201,13 +205,57 @@ func (or *orchestrator) GetMessagesWithData(ctx context.Context, filter ffapi.An
 	if err != nil {
 		return nil, nil, err
 	}
-	msgsData := make([]*core.MessageInOut, len(msgs))
+	return or.fetchMessagesData(ctx, msgs, fr)
+}
+
+func (or *orchestrator) GetMessagesForTag(ctx context.Context, tag string, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error) {
+	return or.database().GetMessagesForTag(ctx, or.namespace.Name, tag, filter)
+}
+
+func (or *orchestrator) GetMessagesWithDataForTag(ctx context.Context, tag string, filter ffapi.AndFilter) ([]*core.MessageInOut, *ffapi.FilterResult, error) {
+	msgs, fr, err := or.database().GetMessagesForTag(ctx, or.namespace.Name, tag, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return or.fetchMessagesData(ctx, msgs, fr)
+}
+
+func (or *orchestrator) fetchMessagesData(ctx context.Context, msgs []*core.Message, fr *ffapi.FilterResult) (msgsData []*core.MessageInOut, _ *ffapi.FilterResult, err error) {
+	msgsData = make([]*core.MessageInOut, len(msgs))
 	for i, msg := range msgs {
 		if msgsData[i], err = or.fetchMessageData(ctx, msg); err != nil {
 			return nil, nil, err
 		}
 	}
-	return msgsData, fr, err
+	return msgsData, fr, nil
+}
+
+// PopulateMessagePinStatus sets the status of the pinning transaction on each message that has been pinned.
+// A confirmed receipt takes precedence, otherwise the most recently recorded receipt is used.
+func (or *orchestrator) PopulateMessagePinStatus(ctx context.Context, msgs []*core.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	msgIDs := make([]*fftypes.UUID, len(msgs))
+	for i, msg := range msgs {
+		msgIDs[i] = msg.Header.ID
+	}
+	receipts, err := or.database().GetBlockchainReceiptsForMessages(ctx, or.namespace.Name, msgIDs)
+	if err != nil {
+		return err
+	}
+	statuses := make(map[fftypes.UUID]core.TxStatus)
+	for _, receipt := range receipts {
+		if statuses[*receipt.MessageID] != core.TxStatusConfirmed {
+			statuses[*receipt.MessageID] = receipt.Status
+		}
+	}
+	for _, msg := range msgs {
+		if status, ok := statuses[*msg.Header.ID]; ok {
+			msg.PinStatus = &status
+		}
+	}
+	return nil
 }
 
 func (or *orchestrator) GetMessageData(ctx context.Context, id string) (core.DataArray, error) {
@@ -255,6 +303,36 @@ func (or *orchestrator) GetMessageEvents(ctx context.Context, id string, filter
 	return or.database().GetEvents(ctx, or.namespace.Name, filter)
 }
 
+func (or *orchestrator) GetMessageReplies(ctx context.Context, id string, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error) {
+	u, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	filter = filter.Condition(filter.Builder().Eq("replyto", u))
+	filter.Sort("sequence").Ascending()
+	return or.database().GetMessages(ctx, or.namespace.Name, filter)
+}
+
+func (or *orchestrator) GetMessageReceipts(ctx context.Context, id string, filter ffapi.AndFilter) ([]*core.DeliveryReceipt, *ffapi.FilterResult, error) {
+	u, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	filter = filter.Condition(filter.Builder().Eq("messageid", u))
+	return or.database().GetDeliveryReceipts(ctx, or.namespace.Name, filter)
+}
+
+func (or *orchestrator) GetConversationMessages(ctx context.Context, id string, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error) {
+	u, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	fb := filter.Builder()
+	filter = filter.Condition(fb.Or(fb.Eq("id", u), fb.Eq("conversationid", u)))
+	filter.Sort("sequence").Ascending()
+	return or.database().GetMessages(ctx, or.namespace.Name, filter)
+}
+
 func (or *orchestrator) GetBatches(ctx context.Context, filter ffapi.AndFilter) ([]*core.BatchPersisted, *ffapi.FilterResult, error) {
 	return or.database().GetBatches(ctx, or.namespace.Name, filter)
 }
@@ -312,6 +390,10 @@ func (or *orchestrator) GetPins(ctx context.Context, filter ffapi.AndFilter) ([]
 	return or.database().GetPins(ctx, or.namespace.Name, filter)
 }
 
+func (or *orchestrator) GetDeadEvents(ctx context.Context, filter ffapi.AndFilter) ([]*core.DeadEvent, *ffapi.FilterResult, error) {
+	return or.database().GetDeadEvents(ctx, or.namespace.Name, filter)
+}
+
 func (or *orchestrator) GetNextPins(ctx context.Context, filter ffapi.AndFilter) ([]*core.NextPin, *ffapi.FilterResult, error) {
 	return or.database().GetNextPins(ctx, or.namespace.Name, filter)
 }
@@ -36,6 +36,15 @@ func TestGetNamespace(t *testing.T) {
 	assert.Equal(t, "ns", ns.Name)
 }
 
+func TestGetDescendantNamespaces(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetDescendantNamespaces", mock.Anything, "ns").Return([]*core.Namespace{{Name: "child"}}, nil)
+	namespaces, err := or.GetDescendantNamespaces(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "child", namespaces[0].Name)
+}
+
 func TestGetTransactionByID(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
@@ -229,6 +238,75 @@ func TestGetMessagesWithDataFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestGetMessagesForTag(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetMessagesForTag", mock.Anything, "ns", "invoice", mock.Anything).Return([]*core.Message{}, nil, nil)
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetMessagesForTag(context.Background(), "invoice", fb.And())
+	assert.NoError(t, err)
+}
+
+func TestGetMessagesWithDataForTag(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	msg := &core.Message{
+		Header: core.MessageHeader{
+			ID: fftypes.NewUUID(),
+		},
+		Data: core.DataRefs{},
+	}
+	or.mdi.On("GetMessagesForTag", mock.Anything, "ns", "invoice", mock.Anything).Return([]*core.Message{msg}, nil, nil)
+	or.mdm.On("GetMessageDataCached", mock.Anything, mock.Anything).Return(core.DataArray{}, true, nil)
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	msgs, _, err := or.GetMessagesWithDataForTag(context.Background(), "invoice", fb.And())
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+}
+
+func TestGetMessagesWithDataForTagFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetMessagesForTag", mock.Anything, "ns", "invoice", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetMessagesWithDataForTag(context.Background(), "invoice", fb.And())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPopulateMessagePinStatus(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	msg1 := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	msg2 := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	msg3 := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	or.mdi.On("GetBlockchainReceiptsForMessages", mock.Anything, "ns", []*fftypes.UUID{msg1.Header.ID, msg2.Header.ID, msg3.Header.ID}).Return([]*core.BlockchainReceipt{
+		{MessageID: msg1.Header.ID, Status: core.TxStatusConfirmed},
+		{MessageID: msg1.Header.ID, Status: core.TxStatusFailed},
+		{MessageID: msg2.Header.ID, Status: core.TxStatusFailed},
+		{MessageID: msg2.Header.ID, Status: core.TxStatusPending},
+	}, nil)
+	err := or.PopulateMessagePinStatus(context.Background(), []*core.Message{msg1, msg2, msg3})
+	assert.NoError(t, err)
+	assert.Equal(t, core.TxStatusConfirmed, *msg1.PinStatus)
+	assert.Equal(t, core.TxStatusPending, *msg2.PinStatus)
+	assert.Nil(t, msg3.PinStatus)
+}
+
+func TestPopulateMessagePinStatusNoMessages(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	err := or.PopulateMessagePinStatus(context.Background(), []*core.Message{})
+	assert.NoError(t, err)
+}
+
+func TestPopulateMessagePinStatusFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetBlockchainReceiptsForMessages", mock.Anything, "ns", mock.Anything).Return(nil, fmt.Errorf("pop"))
+	err := or.PopulateMessagePinStatus(context.Background(), []*core.Message{{Header: core.MessageHeader{ID: fftypes.NewUUID()}}})
+	assert.EqualError(t, err, "pop")
+}
+
 func TestGetMessagesForData(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
@@ -378,6 +456,73 @@ func TestGetMessageEventsBadMsgID(t *testing.T) {
 	assert.Nil(t, ev)
 }
 
+func TestGetMessageReceipts(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	u := fftypes.NewUUID()
+	or.mdi.On("GetDeliveryReceipts", mock.Anything, "ns", mock.Anything).Return([]*core.DeliveryReceipt{}, nil, nil)
+	fb := database.DeliveryReceiptQueryFactory.NewFilter(context.Background())
+	f := fb.And()
+	_, _, err := or.GetMessageReceipts(context.Background(), u.String(), f)
+	assert.NoError(t, err)
+	calculatedFilter, err := or.mdi.Calls[0].Arguments[2].(ffapi.Filter).Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`( messageid == '%s' )`, u), calculatedFilter.String())
+}
+
+func TestGetMessageReceiptsBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	fb := database.DeliveryReceiptQueryFactory.NewFilter(context.Background())
+	f := fb.And()
+	_, _, err := or.GetMessageReceipts(context.Background(), "badId", f)
+	assert.Regexp(t, "FF00138", err)
+}
+
+func TestGetMessageReplies(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	u := fftypes.NewUUID()
+	or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything).Return([]*core.Message{}, nil, nil)
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	f := fb.And()
+	_, _, err := or.GetMessageReplies(context.Background(), u.String(), f)
+	assert.NoError(t, err)
+	calculatedFilter, err := or.mdi.Calls[0].Arguments[2].(ffapi.Filter).Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`( replyto == '%s' ) sort=sequence`, u), calculatedFilter.String())
+}
+
+func TestGetMessageRepliesBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetMessageReplies(context.Background(), "bad", fb.And())
+	assert.Regexp(t, "FF00138", err)
+}
+
+func TestGetConversationMessages(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	u := fftypes.NewUUID()
+	or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything).Return([]*core.Message{}, nil, nil)
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	f := fb.And()
+	_, _, err := or.GetConversationMessages(context.Background(), u.String(), f)
+	assert.NoError(t, err)
+	calculatedFilter, err := or.mdi.Calls[0].Arguments[2].(ffapi.Filter).Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`( ( id == '%s' ) || ( conversationid == '%s' ) ) sort=sequence`, u, u), calculatedFilter.String())
+}
+
+func TestGetConversationMessagesBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetConversationMessages(context.Background(), "bad", fb.And())
+	assert.Regexp(t, "FF00138", err)
+}
+
 func TestGetBatchByID(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
@@ -838,6 +983,16 @@ func TestGetPins(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGetDeadEvents(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetDeadEvents", mock.Anything, "ns", mock.Anything).Return([]*core.DeadEvent{}, nil, nil)
+	fb := database.DeadEventQueryFactory.NewFilter(context.Background())
+	f := fb.And(fb.Eq("pin", 12345))
+	_, _, err := or.GetDeadEvents(context.Background(), f)
+	assert.NoError(t, err)
+}
+
 func TestGetNextPins(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
@@ -0,0 +1,116 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+func (or *orchestrator) DeleteEvent(ctx context.Context, id string) error {
+	u, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return err
+	}
+	event, err := or.database().GetEventByID(ctx, or.namespace.Name, u)
+	if err != nil {
+		return err
+	}
+	if event == nil {
+		return i18n.NewError(ctx, coremsgs.Msg404NoResult)
+	}
+	if err := or.checkEventsDelivered(ctx, event.Sequence); err != nil {
+		return err
+	}
+	return or.database().DeleteEvent(ctx, or.namespace.Name, event.ID)
+}
+
+func (or *orchestrator) DeleteEventsBefore(ctx context.Context, sequence int64) (int64, error) {
+	if err := or.checkEventsDelivered(ctx, sequence-1); err != nil {
+		return -1, err
+	}
+	return or.database().DeleteEventsBefore(ctx, or.namespace.Name, sequence)
+}
+
+// PauseAggregator stops the processing of pins into events, for example during maintenance, without stopping the node
+func (or *orchestrator) PauseAggregator(ctx context.Context) error {
+	return or.events.PauseAggregator(ctx)
+}
+
+func (or *orchestrator) ResumeAggregator(ctx context.Context) error {
+	return or.events.ResumeAggregator(ctx)
+}
+
+// ForceUnblockContext skips the pin blocking processing of the given context, recording the caller for audit
+func (or *orchestrator) ForceUnblockContext(ctx context.Context, contextHash string, authReq *fftypes.AuthReq) error {
+	hash, err := fftypes.ParseBytes32(ctx, contextHash)
+	if err != nil {
+		return err
+	}
+	return or.events.ForceUnblockContext(ctx, hash, or.verifiedPrincipal(ctx, authReq))
+}
+
+// ReplayPins processes any undispatched pins in a range the aggregator has already passed, without moving its offset
+func (or *orchestrator) ReplayPins(ctx context.Context, input *core.PinReplayInput) (*core.PinReplay, error) {
+	return or.events.StartPinReplay(ctx, input)
+}
+
+func (or *orchestrator) GetPinReplay(ctx context.Context, id string) (*core.PinReplay, error) {
+	u, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return or.events.GetPinReplay(ctx, u)
+}
+
+func (or *orchestrator) GetBlockedContexts(ctx context.Context, filter ffapi.AndFilter) ([]*core.BlockedContext, *ffapi.FilterResult, error) {
+	return or.events.GetBlockedContexts(ctx, filter)
+}
+
+func (or *orchestrator) GetBlockedContextStats(ctx context.Context) (*core.BlockedContextStats, error) {
+	return or.events.GetBlockedContextStats(ctx)
+}
+
+// checkEventsDelivered ensures that every durable subscription has been delivered all events up to
+// the given sequence, so that none of them are deleted before being processed. A subscription without
+// an offset has not started delivery yet, so is treated as undelivered. Ephemeral subscriptions are
+// not stored in the database, so cannot be checked.
+func (or *orchestrator) checkEventsDelivered(ctx context.Context, sequence int64) error {
+	subs, _, err := or.database().GetSubscriptions(ctx, or.namespace.Name, database.SubscriptionQueryFactory.NewFilter(ctx).And())
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		offset, err := or.database().GetOffset(ctx, core.OffsetTypeSubscription, sub.ID.String())
+		if err != nil {
+			return err
+		}
+		if offset == nil {
+			return i18n.NewError(ctx, coremsgs.MsgEventsNotDeliveredNoOffset, sequence, sub.Name)
+		}
+		if offset.Current < sequence {
+			return i18n.NewError(ctx, coremsgs.MsgEventsNotDelivered, sequence, sub.Name, offset.Current)
+		}
+	}
+	return nil
+}
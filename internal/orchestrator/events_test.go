@@ -0,0 +1,218 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeleteEvent(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	u := fftypes.NewUUID()
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Name: "sub1"}}
+	or.mdi.On("GetEventByID", mock.Anything, "ns", u).Return(&core.Event{
+		ID:        u,
+		Namespace: "ns",
+		Sequence:  10,
+	}, nil)
+	or.mdi.On("GetSubscriptions", mock.Anything, "ns", mock.Anything).Return([]*core.Subscription{sub}, nil, nil)
+	or.mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.ID.String()).Return(&core.Offset{Current: 10}, nil)
+	or.mdi.On("DeleteEvent", mock.Anything, "ns", u).Return(nil)
+	err := or.DeleteEvent(context.Background(), u.String())
+	assert.NoError(t, err)
+}
+
+func TestDeleteEventBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	err := or.DeleteEvent(context.Background(), "")
+	assert.Regexp(t, "FF00138", err)
+}
+
+func TestDeleteEventGetFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	u := fftypes.NewUUID()
+	or.mdi.On("GetEventByID", mock.Anything, "ns", u).Return(nil, fmt.Errorf("pop"))
+	err := or.DeleteEvent(context.Background(), u.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDeleteEventNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	u := fftypes.NewUUID()
+	or.mdi.On("GetEventByID", mock.Anything, "ns", u).Return(nil, nil)
+	err := or.DeleteEvent(context.Background(), u.String())
+	assert.Regexp(t, "FF10143", err)
+}
+
+func TestDeleteEventNotDelivered(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	u := fftypes.NewUUID()
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Name: "sub1"}}
+	or.mdi.On("GetEventByID", mock.Anything, "ns", u).Return(&core.Event{
+		ID:        u,
+		Namespace: "ns",
+		Sequence:  10,
+	}, nil)
+	or.mdi.On("GetSubscriptions", mock.Anything, "ns", mock.Anything).Return([]*core.Subscription{sub}, nil, nil)
+	or.mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.ID.String()).Return(&core.Offset{Current: 9}, nil)
+	err := or.DeleteEvent(context.Background(), u.String())
+	assert.Regexp(t, "FF10475.*sub1", err)
+}
+
+func TestDeleteEventsBefore(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	sub1 := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Name: "sub1"}}
+	sub2 := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Name: "sub2"}}
+	or.mdi.On("GetSubscriptions", mock.Anything, "ns", mock.Anything).Return([]*core.Subscription{sub1, sub2}, nil, nil)
+	or.mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub1.ID.String()).Return(&core.Offset{Current: 99}, nil)
+	or.mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub2.ID.String()).Return(&core.Offset{Current: 150}, nil)
+	or.mdi.On("DeleteEventsBefore", mock.Anything, "ns", int64(100)).Return(int64(5), nil)
+	count, err := or.DeleteEventsBefore(context.Background(), 100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), count)
+}
+
+func TestDeleteEventsBeforeNotDelivered(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Name: "sub1"}}
+	or.mdi.On("GetSubscriptions", mock.Anything, "ns", mock.Anything).Return([]*core.Subscription{sub}, nil, nil)
+	or.mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.ID.String()).Return(&core.Offset{Current: 50}, nil)
+	_, err := or.DeleteEventsBefore(context.Background(), 100)
+	assert.Regexp(t, "FF10475.*99.*sub1.*50", err)
+}
+
+func TestDeleteEventsBeforeNoOffset(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Name: "sub1"}}
+	or.mdi.On("GetSubscriptions", mock.Anything, "ns", mock.Anything).Return([]*core.Subscription{sub}, nil, nil)
+	or.mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.ID.String()).Return(nil, nil)
+	_, err := or.DeleteEventsBefore(context.Background(), 100)
+	assert.Regexp(t, "FF10503.*sub1", err)
+}
+
+func TestDeleteEventsBeforeGetSubscriptionsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetSubscriptions", mock.Anything, "ns", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.DeleteEventsBefore(context.Background(), 100)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDeleteEventsBeforeGetOffsetFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Name: "sub1"}}
+	or.mdi.On("GetSubscriptions", mock.Anything, "ns", mock.Anything).Return([]*core.Subscription{sub}, nil, nil)
+	or.mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.ID.String()).Return(nil, fmt.Errorf("pop"))
+	_, err := or.DeleteEventsBefore(context.Background(), 100)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPauseAggregator(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mem.On("PauseAggregator", context.Background()).Return(nil)
+	err := or.PauseAggregator(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestResumeAggregator(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mem.On("ResumeAggregator", context.Background()).Return(nil)
+	err := or.ResumeAggregator(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestForceUnblockContext(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	hash := fftypes.NewRandB32()
+	authReq := &fftypes.AuthReq{Header: http.Header{}}
+	newTestPrincipalAuth(or, "admin1", nil)
+	or.mem.On("ForceUnblockContext", context.Background(), hash, "admin1").Return(nil)
+	err := or.ForceUnblockContext(context.Background(), hash.String(), authReq)
+	assert.NoError(t, err)
+}
+
+func TestReplayPins(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	input := &core.PinReplayInput{FromSequence: 1, ToSequence: 10}
+	or.mem.On("StartPinReplay", context.Background(), input).Return(&core.PinReplay{}, nil)
+	_, err := or.ReplayPins(context.Background(), input)
+	assert.NoError(t, err)
+}
+
+func TestGetPinReplay(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	id := fftypes.NewUUID()
+	or.mem.On("GetPinReplay", context.Background(), id).Return(&core.PinReplay{ID: id}, nil)
+	replay, err := or.GetPinReplay(context.Background(), id.String())
+	assert.NoError(t, err)
+	assert.Equal(t, id, replay.ID)
+}
+
+func TestGetPinReplayBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	_, err := or.GetPinReplay(context.Background(), "bad")
+	assert.Regexp(t, "FF00138", err)
+}
+
+func TestGetBlockedContexts(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	fb := database.PinQueryFactory.NewFilter(context.Background())
+	f := fb.And()
+	or.mem.On("GetBlockedContexts", context.Background(), f).Return([]*core.BlockedContext{}, nil, nil)
+	_, _, err := or.GetBlockedContexts(context.Background(), f)
+	assert.NoError(t, err)
+}
+
+func TestGetBlockedContextStats(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mem.On("GetBlockedContextStats", context.Background()).Return(&core.BlockedContextStats{}, nil)
+	_, err := or.GetBlockedContextStats(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestForceUnblockContextBadHash(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	err := or.ForceUnblockContext(context.Background(), "!bad", &fftypes.AuthReq{})
+	assert.Regexp(t, "FF00107", err)
+}
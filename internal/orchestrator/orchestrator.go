@@ -40,6 +40,7 @@ import (
 	"github.com/hyperledger/firefly/internal/networkmap"
 	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/internal/privatemessaging"
+	"github.com/hyperledger/firefly/internal/ratelimit"
 	"github.com/hyperledger/firefly/internal/shareddownload"
 	"github.com/hyperledger/firefly/internal/syncasync"
 	"github.com/hyperledger/firefly/internal/txcommon"
@@ -82,12 +83,14 @@ type Orchestrator interface {
 	GetSubscriptionByID(ctx context.Context, id string) (*core.Subscription, error)
 	GetSubscriptionByIDWithStatus(ctx context.Context, id string) (*core.SubscriptionWithStatus, error)
 	GetSubscriptionEventsHistorical(ctx context.Context, subscription *core.Subscription, filter ffapi.AndFilter, startSequence int, endSequence int) ([]*core.EnrichedEvent, *ffapi.FilterResult, error)
+	GetSubscriptionDeadLetters(ctx context.Context, id string, filter ffapi.AndFilter) ([]*core.DeadLetter, *ffapi.FilterResult, error)
 	CreateSubscription(ctx context.Context, subDef *core.Subscription) (*core.Subscription, error)
 	CreateUpdateSubscription(ctx context.Context, subDef *core.Subscription) (*core.Subscription, error)
 	DeleteSubscription(ctx context.Context, id string) error
 
 	// Data Query
 	GetNamespace(ctx context.Context) *core.Namespace
+	GetDescendantNamespaces(ctx context.Context) ([]*core.Namespace, error)
 	GetTransactionByID(ctx context.Context, id string) (*core.Transaction, error)
 	GetTransactionOperations(ctx context.Context, id string) ([]*core.Operation, *ffapi.FilterResult, error)
 	GetTransactionBlockchainEvents(ctx context.Context, id string) ([]*core.BlockchainEvent, *ffapi.FilterResult, error)
@@ -97,8 +100,14 @@ type Orchestrator interface {
 	GetMessageByIDWithData(ctx context.Context, id string) (*core.MessageInOut, error)
 	GetMessages(ctx context.Context, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error)
 	GetMessagesWithData(ctx context.Context, filter ffapi.AndFilter) ([]*core.MessageInOut, *ffapi.FilterResult, error)
+	GetMessagesForTag(ctx context.Context, tag string, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error)
+	GetMessagesWithDataForTag(ctx context.Context, tag string, filter ffapi.AndFilter) ([]*core.MessageInOut, *ffapi.FilterResult, error)
+	PopulateMessagePinStatus(ctx context.Context, msgs []*core.Message) error
 	GetMessageTransaction(ctx context.Context, id string) (*core.Transaction, error)
 	GetMessageEvents(ctx context.Context, id string, filter ffapi.AndFilter) ([]*core.Event, *ffapi.FilterResult, error)
+	GetMessageReceipts(ctx context.Context, id string, filter ffapi.AndFilter) ([]*core.DeliveryReceipt, *ffapi.FilterResult, error)
+	GetMessageReplies(ctx context.Context, id string, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error)
+	GetConversationMessages(ctx context.Context, id string, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error)
 	GetMessageData(ctx context.Context, id string) (core.DataArray, error)
 	GetMessagesForData(ctx context.Context, dataID string, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error)
 	GetBatchByID(ctx context.Context, id string) (*core.BatchPersisted, error)
@@ -116,11 +125,25 @@ type Orchestrator interface {
 	GetEventByIDWithReference(ctx context.Context, id string) (*core.EnrichedEvent, error)
 	GetEvents(ctx context.Context, filter ffapi.AndFilter) ([]*core.Event, *ffapi.FilterResult, error)
 	GetEventsWithReferences(ctx context.Context, filter ffapi.AndFilter) ([]*core.EnrichedEvent, *ffapi.FilterResult, error)
+	DeleteEvent(ctx context.Context, id string) error
+	DeleteEventsBefore(ctx context.Context, sequence int64) (int64, error)
+	PauseAggregator(ctx context.Context) error
+	ResumeAggregator(ctx context.Context) error
+	ForceUnblockContext(ctx context.Context, contextHash string, authReq *fftypes.AuthReq) error
+	ReplayPins(ctx context.Context, input *core.PinReplayInput) (*core.PinReplay, error)
+	GetPinReplay(ctx context.Context, id string) (*core.PinReplay, error)
+	GetBlockedContexts(ctx context.Context, filter ffapi.AndFilter) ([]*core.BlockedContext, *ffapi.FilterResult, error)
+	GetBlockedContextStats(ctx context.Context) (*core.BlockedContextStats, error)
 	GetBlockchainEventByID(ctx context.Context, id string) (*core.BlockchainEvent, error)
 	GetBlockchainEvents(ctx context.Context, filter ffapi.AndFilter) ([]*core.BlockchainEvent, *ffapi.FilterResult, error)
 	GetPins(ctx context.Context, filter ffapi.AndFilter) ([]*core.Pin, *ffapi.FilterResult, error)
 	GetNextPins(ctx context.Context, filter ffapi.AndFilter) ([]*core.NextPin, *ffapi.FilterResult, error)
 	RewindPins(ctx context.Context, rewind *core.PinRewind) (*core.PinRewind, error)
+	GetDeadEvents(ctx context.Context, filter ffapi.AndFilter) ([]*core.DeadEvent, *ffapi.FilterResult, error)
+	RequeueDeadEvent(ctx context.Context, id string) (*core.DeadEvent, error)
+	GetPermissions(ctx context.Context, filter ffapi.AndFilter) ([]*core.NamespacedPermission, *ffapi.FilterResult, error)
+	GrantPermission(ctx context.Context, input *core.PermissionInput) (*core.NamespacedPermission, error)
+	RevokePermission(ctx context.Context, principal string) error
 
 	// Charts
 	GetChartHistogram(ctx context.Context, startTime int64, endTime int64, buckets int64, tableName database.CollectionName) ([]*core.ChartHistogram, error)
@@ -187,6 +210,9 @@ type Config struct {
 	Multiparty                  multiparty.Config
 	TokenBroadcastNames         map[string]string
 	MaxHistoricalEventScanLimit int
+	RBACEnabled                 bool
+	AggregatorRateLimitBurst    int
+	AggregatorRateLimitRate     float64
 }
 
 type orchestrator struct {
@@ -300,6 +326,9 @@ func (or *orchestrator) Start() (err error) {
 	if err == nil {
 		err = or.assets.Start()
 	}
+	if err == nil && or.config.Multiparty.AutoRegister {
+		go or.bootstrap(or.ctx)
+	}
 
 	or.started = true
 	return err
@@ -586,7 +615,11 @@ func (or *orchestrator) initComponents(ctx context.Context) (err error) {
 	}
 
 	if or.events == nil {
-		or.events, err = events.NewEventManager(ctx, or.namespace, or.database(), or.blockchain(), or.identity, or.defhandler, or.data, or.defsender, or.broadcast, or.messaging, or.assets, or.sharedDownload, or.metrics, or.operations, or.txHelper, or.plugins.Events, or.multiparty, or.cacheManager)
+		var aggregatorLimiter ratelimit.RateLimiter
+		if or.config.AggregatorRateLimitRate > 0 {
+			aggregatorLimiter = ratelimit.NewTokenBucket(or.config.AggregatorRateLimitBurst, or.config.AggregatorRateLimitRate)
+		}
+		or.events, err = events.NewEventManager(ctx, or.namespace, or.database(), or.blockchain(), or.identity, or.defhandler, or.data, or.defsender, or.broadcast, or.messaging, or.assets, or.sharedDownload, or.metrics, or.operations, or.txHelper, or.plugins.Events, or.multiparty, or.cacheManager, aggregatorLimiter)
 		if err != nil {
 			return err
 		}
@@ -610,6 +643,10 @@ func (or *orchestrator) SubmitNetworkAction(ctx context.Context, action *core.Ne
 
 func (or *orchestrator) Authorize(ctx context.Context, authReq *fftypes.AuthReq) error {
 	authReq.Namespace = or.namespace.Name
+	if or.config.RBACEnabled {
+		// The auth plugin authenticates the caller as part of identifying the principal
+		return or.authorizeNamespaceRole(ctx, authReq)
+	}
 	if or.plugins.Auth.Plugin != nil {
 		return or.plugins.Auth.Plugin.Authorize(ctx, authReq)
 	}
@@ -631,3 +668,32 @@ func (or *orchestrator) RewindPins(ctx context.Context, rewind *core.PinRewind)
 	or.events.QueueBatchRewind(rewind.Batch)
 	return rewind, nil
 }
+
+func (or *orchestrator) RequeueDeadEvent(ctx context.Context, id string) (*core.DeadEvent, error) {
+	u, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	deadEvent, err := or.database().GetDeadEventByID(ctx, or.namespace.Name, u)
+	if err != nil {
+		return nil, err
+	}
+	if deadEvent == nil {
+		return nil, i18n.NewError(ctx, coremsgs.Msg404NotFound)
+	}
+	err = or.database().RunAsGroup(ctx, func(ctx context.Context) error {
+		// Dead pins are marked dispatched, so they do not block their context. Mark the pin undispatched
+		// again, so that rewinding to the batch hands it back to the aggregator.
+		fb := database.PinQueryFactory.NewFilter(ctx)
+		update := database.PinQueryFactory.NewUpdate(ctx).Set("dispatched", false)
+		if err := or.database().UpdatePins(ctx, or.namespace.Name, fb.Eq("sequence", deadEvent.Pin), update); err != nil {
+			return err
+		}
+		return or.database().DeleteDeadEvent(ctx, or.namespace.Name, u)
+	})
+	if err != nil {
+		return nil, err
+	}
+	or.events.QueueBatchRewind(deadEvent.Batch)
+	return deadEvent, nil
+}
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/hyperledger/firefly-common/mocks/authmocks"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/internal/cache"
 	"github.com/hyperledger/firefly/internal/coreconfig"
@@ -316,6 +317,19 @@ func TestInitEventsComponentFail(t *testing.T) {
 	assert.Regexp(t, "FF10128", err)
 }
 
+func TestInitEventsComponentRateLimitedFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.plugins.Database.Plugin = nil
+	or.events = nil
+	or.config.AggregatorRateLimitBurst = 100
+	or.config.AggregatorRateLimitRate = 10
+	or.mbi.On("StartNamespace", mock.Anything, "ns").Return(nil)
+	or.mmp.On("ConfigureContract", mock.Anything, mock.Anything).Return(nil)
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
 func TestInitEventsComponentStartNamespaceFail(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
@@ -463,6 +477,28 @@ func TestStartBatchFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestStartAutoRegister(t *testing.T) {
+	coreconfig.Reset()
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.config.Multiparty.AutoRegister = true
+	or.mdm.On("Start").Return(nil)
+	or.mba.On("Start").Return(nil)
+	or.mem.On("Start").Return(nil)
+	or.mbm.On("Start").Return(nil)
+	or.msd.On("Start").Return(nil)
+	or.mom.On("Start").Return(nil)
+	or.mtw.On("Start").Return()
+	or.mam.On("Start").Return(nil)
+	bootstrapped := make(chan struct{})
+	or.mim.On("GetLocalNode", mock.Anything).Return(&core.Identity{}, nil).Run(func(args mock.Arguments) {
+		close(bootstrapped)
+	})
+	err := or.Start()
+	assert.NoError(t, err)
+	<-bootstrapped
+}
+
 func TestInitTXWriter(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
@@ -664,3 +700,93 @@ func TestRewindPinsBatch(t *testing.T) {
 	assert.Equal(t, int64(0), result.Sequence)
 	assert.Equal(t, batchID, result.Batch)
 }
+
+func TestRequeueDeadEvent(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	deID := fftypes.NewUUID()
+	batchID := fftypes.NewUUID()
+
+	or.mdi.On("GetDeadEventByID", mock.Anything, "ns", deID).Return(&core.DeadEvent{ID: deID, Batch: batchID, Pin: 12345}, nil)
+	rag := or.mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	or.mdi.On("UpdatePins", mock.Anything, "ns", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.String() == "sequence == 12345"
+	}), mock.MatchedBy(func(u ffapi.Update) bool {
+		update, _ := u.Finalize()
+		v, _ := update.SetOperations[0].Value.Value()
+		return update.SetOperations[0].Field == "dispatched" && v == false
+	})).Return(nil)
+	or.mdi.On("DeleteDeadEvent", mock.Anything, "ns", deID).Return(nil)
+	or.mem.On("QueueBatchRewind", batchID).Return()
+
+	result, err := or.RequeueDeadEvent(context.Background(), deID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, deID, result.ID)
+}
+
+func TestRequeueDeadEventUpdatePinsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	deID := fftypes.NewUUID()
+
+	or.mdi.On("GetDeadEventByID", mock.Anything, "ns", deID).Return(&core.DeadEvent{ID: deID, Batch: fftypes.NewUUID()}, nil)
+	rag := or.mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	or.mdi.On("UpdatePins", mock.Anything, "ns", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := or.RequeueDeadEvent(context.Background(), deID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRequeueDeadEventBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	_, err := or.RequeueDeadEvent(context.Background(), "bad")
+	assert.Regexp(t, "FF00138", err)
+}
+
+func TestRequeueDeadEventGetFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	deID := fftypes.NewUUID()
+
+	or.mdi.On("GetDeadEventByID", mock.Anything, "ns", deID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := or.RequeueDeadEvent(context.Background(), deID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRequeueDeadEventNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	deID := fftypes.NewUUID()
+
+	or.mdi.On("GetDeadEventByID", mock.Anything, "ns", deID).Return(nil, nil)
+
+	_, err := or.RequeueDeadEvent(context.Background(), deID.String())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestRequeueDeadEventDeleteFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	deID := fftypes.NewUUID()
+
+	or.mdi.On("GetDeadEventByID", mock.Anything, "ns", deID).Return(&core.DeadEvent{ID: deID, Batch: fftypes.NewUUID()}, nil)
+	rag := or.mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	or.mdi.On("UpdatePins", mock.Anything, "ns", mock.Anything, mock.Anything).Return(nil)
+	or.mdi.On("DeleteDeadEvent", mock.Anything, "ns", deID).Return(fmt.Errorf("pop"))
+
+	_, err := or.RequeueDeadEvent(context.Background(), deID.String())
+	assert.EqualError(t, err, "pop")
+}
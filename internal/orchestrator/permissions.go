@@ -0,0 +1,151 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/auth"
+	"github.com/hyperledger/firefly-common/pkg/auth/basic"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// requiredRole determines the role needed for a request - SPI routes are admin only,
+// queries need read access, and everything else needs write access
+func requiredRole(authReq *fftypes.AuthReq) core.PermissionRole {
+	if authReq.URL != nil && strings.HasPrefix(authReq.URL.Path, "/spi/") {
+		return core.PermissionRoleAdmin
+	}
+	switch authReq.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return core.PermissionRoleRead
+	default:
+		return core.PermissionRoleWrite
+	}
+}
+
+// PrincipalAuthorizer is an optional interface for auth plugins that can report the principal
+// they authenticated, which is required to enforce the roles of a namespace with RBAC enabled
+type PrincipalAuthorizer interface {
+	AuthorizePrincipal(ctx context.Context, req *fftypes.AuthReq) (principal string, err error)
+}
+
+// basicPrincipalAuthorizer reports the username the basic auth plugin verified against its password file
+type basicPrincipalAuthorizer struct {
+	auth.Plugin
+}
+
+func (b *basicPrincipalAuthorizer) AuthorizePrincipal(ctx context.Context, req *fftypes.AuthReq) (string, error) {
+	if err := b.Authorize(ctx, req); err != nil {
+		return "", err
+	}
+	principal, _, _ := (&http.Request{Header: req.Header}).BasicAuth()
+	return principal, nil
+}
+
+// NewPrincipalAuthorizer returns the principal authorizer for an auth plugin, or nil if the plugin
+// cannot report the principal of the requests it authorizes
+func NewPrincipalAuthorizer(plugin auth.Plugin) PrincipalAuthorizer {
+	switch p := plugin.(type) {
+	case PrincipalAuthorizer:
+		return p
+	case *basic.Auth:
+		return &basicPrincipalAuthorizer{Plugin: p}
+	default:
+		return nil
+	}
+}
+
+// verifiedPrincipal returns the principal the auth plugin authenticated for the request, or an empty
+// string if there is no auth plugin, or it cannot report the principal
+func (or *orchestrator) verifiedPrincipal(ctx context.Context, authReq *fftypes.AuthReq) string {
+	pa := NewPrincipalAuthorizer(or.plugins.Auth.Plugin)
+	if pa == nil {
+		return ""
+	}
+	principal, err := pa.AuthorizePrincipal(ctx, authReq)
+	if err != nil {
+		log.L(ctx).Warnf("Unable to identify the principal of the request: %s", err)
+		return ""
+	}
+	return principal
+}
+
+func (or *orchestrator) authorizeNamespaceRole(ctx context.Context, authReq *fftypes.AuthReq) error {
+	pa := NewPrincipalAuthorizer(or.plugins.Auth.Plugin)
+	if pa == nil {
+		return i18n.NewError(ctx, coremsgs.MsgNamespaceRBACNoPrincipalAuth, or.namespace.Name)
+	}
+	principal, err := pa.AuthorizePrincipal(ctx, authReq)
+	if err != nil {
+		return err
+	}
+	role := requiredRole(authReq)
+	if principal != "" {
+		permission, err := or.database().GetPermission(ctx, or.namespace.Name, principal)
+		if err != nil {
+			return err
+		}
+		if permission != nil && permission.Allows(role) {
+			return nil
+		}
+	}
+	log.L(ctx).Warnf("Principal '%s' denied %s %s - '%s' role required", principal, authReq.Method, authReq.URL, role)
+	return i18n.NewError(ctx, coremsgs.MsgNamespaceRoleRequired, principal, role, or.namespace.Name)
+}
+
+func (or *orchestrator) GetPermissions(ctx context.Context, filter ffapi.AndFilter) ([]*core.NamespacedPermission, *ffapi.FilterResult, error) {
+	return or.database().GetPermissions(ctx, or.namespace.Name, filter)
+}
+
+func (or *orchestrator) GrantPermission(ctx context.Context, input *core.PermissionInput) (*core.NamespacedPermission, error) {
+	if input.Principal == "" {
+		return nil, i18n.NewError(ctx, coremsgs.MsgPermissionPrincipalRequired)
+	}
+	role, err := fftypes.FFEnumParseString(ctx, "permissionrole", input.Role.String())
+	if err != nil {
+		return nil, err
+	}
+	permission := &core.NamespacedPermission{
+		Namespace: or.namespace.Name,
+		Principal: input.Principal,
+		Role:      role,
+		Created:   fftypes.Now(),
+	}
+	if err := or.database().UpsertPermission(ctx, permission); err != nil {
+		return nil, err
+	}
+	return permission, nil
+}
+
+func (or *orchestrator) RevokePermission(ctx context.Context, principal string) error {
+	permission, err := or.database().GetPermission(ctx, or.namespace.Name, principal)
+	if err != nil {
+		return err
+	}
+	if permission == nil {
+		return i18n.NewError(ctx, coremsgs.Msg404NotFound)
+	}
+	return or.database().DeletePermission(ctx, or.namespace.Name, principal)
+}
@@ -0,0 +1,263 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/mocks/authmocks"
+	"github.com/hyperledger/firefly-common/pkg/auth/basic"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestAuthReq(method, path, user string) *fftypes.AuthReq {
+	req, _ := http.NewRequest(method, "http://localhost"+path, nil)
+	if user != "" {
+		req.SetBasicAuth(user, "pass")
+	}
+	return &fftypes.AuthReq{
+		Method: req.Method,
+		URL:    req.URL,
+		Header: req.Header,
+	}
+}
+
+type mockPrincipalAuth struct {
+	authmocks.Plugin
+}
+
+func (m *mockPrincipalAuth) AuthorizePrincipal(ctx context.Context, req *fftypes.AuthReq) (string, error) {
+	ret := m.Called(ctx, req)
+	return ret.String(0), ret.Error(1)
+}
+
+func newTestPrincipalAuth(or *testOrchestrator, principal string, err error) *mockPrincipalAuth {
+	pa := &mockPrincipalAuth{}
+	pa.On("AuthorizePrincipal", mock.Anything, mock.Anything).Return(principal, err)
+	or.plugins.Auth.Plugin = pa
+	return pa
+}
+
+func TestRequiredRole(t *testing.T) {
+	assert.Equal(t, core.PermissionRoleRead, requiredRole(newTestAuthReq(http.MethodGet, "/api/v1/namespaces/ns/messages", "")))
+	assert.Equal(t, core.PermissionRoleWrite, requiredRole(newTestAuthReq(http.MethodPost, "/api/v1/namespaces/ns/messages/broadcast", "")))
+	assert.Equal(t, core.PermissionRoleWrite, requiredRole(&fftypes.AuthReq{Method: http.MethodDelete}))
+	assert.Equal(t, core.PermissionRoleAdmin, requiredRole(newTestAuthReq(http.MethodGet, "/spi/v1/namespaces/ns/operations", "")))
+}
+
+func TestAuthorizeRBACRoleOk(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.config.RBACEnabled = true
+	pa := newTestPrincipalAuth(or, "user1", nil)
+
+	or.mdi.On("GetPermission", mock.Anything, "ns", "user1").Return(&core.NamespacedPermission{
+		Namespace: "ns",
+		Principal: "user1",
+		Role:      core.PermissionRoleWrite,
+	}, nil)
+
+	err := or.Authorize(context.Background(), newTestAuthReq(http.MethodPost, "/api/v1/namespaces/ns/messages/broadcast", "user1"))
+	assert.NoError(t, err)
+	pa.AssertExpectations(t)
+}
+
+func TestAuthorizeRBACRoleInsufficient(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.config.RBACEnabled = true
+	newTestPrincipalAuth(or, "user1", nil)
+
+	or.mdi.On("GetPermission", mock.Anything, "ns", "user1").Return(&core.NamespacedPermission{
+		Namespace: "ns",
+		Principal: "user1",
+		Role:      core.PermissionRoleRead,
+	}, nil)
+
+	err := or.Authorize(context.Background(), newTestAuthReq(http.MethodPost, "/api/v1/namespaces/ns/messages/broadcast", "user1"))
+	assert.Regexp(t, "FF10469", err)
+}
+
+func TestAuthorizeRBACNoPermission(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.config.RBACEnabled = true
+	newTestPrincipalAuth(or, "user1", nil)
+
+	or.mdi.On("GetPermission", mock.Anything, "ns", "user1").Return(nil, nil)
+
+	err := or.Authorize(context.Background(), newTestAuthReq(http.MethodGet, "/api/v1/namespaces/ns/messages", "user1"))
+	assert.Regexp(t, "FF10469", err)
+}
+
+func TestAuthorizeRBACNoPrincipal(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.config.RBACEnabled = true
+	newTestPrincipalAuth(or, "", nil)
+
+	err := or.Authorize(context.Background(), &fftypes.AuthReq{Method: http.MethodGet, URL: &url.URL{Path: "/api/v1/status"}})
+	assert.Regexp(t, "FF10469", err)
+}
+
+func TestAuthorizeRBACLookupFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.config.RBACEnabled = true
+	newTestPrincipalAuth(or, "user1", nil)
+
+	or.mdi.On("GetPermission", mock.Anything, "ns", "user1").Return(nil, fmt.Errorf("pop"))
+
+	err := or.Authorize(context.Background(), newTestAuthReq(http.MethodGet, "/api/v1/namespaces/ns/messages", "user1"))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestAuthorizePluginFailSkipsRBAC(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.config.RBACEnabled = true
+	newTestPrincipalAuth(or, "", fmt.Errorf("pop"))
+
+	err := or.Authorize(context.Background(), newTestAuthReq(http.MethodGet, "/api/v1/namespaces/ns/messages", "user1"))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestAuthorizeRBACNoAuthPlugin(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.config.RBACEnabled = true
+
+	err := or.Authorize(context.Background(), newTestAuthReq(http.MethodGet, "/api/v1/namespaces/ns/messages", "user1"))
+	assert.Regexp(t, "FF10507", err)
+}
+
+func TestAuthorizeRBACPluginCannotIdentifyPrincipal(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.config.RBACEnabled = true
+	auth := &authmocks.Plugin{}
+	or.plugins.Auth.Plugin = auth
+
+	err := or.Authorize(context.Background(), newTestAuthReq(http.MethodGet, "/api/v1/namespaces/ns/messages", "user1"))
+	assert.Regexp(t, "FF10507", err)
+	auth.AssertNotCalled(t, "Authorize", mock.Anything, mock.Anything)
+}
+
+func TestNewPrincipalAuthorizer(t *testing.T) {
+	assert.Nil(t, NewPrincipalAuthorizer(nil))
+	assert.Nil(t, NewPrincipalAuthorizer(&authmocks.Plugin{}))
+	pa := &mockPrincipalAuth{}
+	assert.Equal(t, pa, NewPrincipalAuthorizer(pa))
+	assert.IsType(t, &basicPrincipalAuthorizer{}, NewPrincipalAuthorizer(&basic.Auth{}))
+}
+
+func TestBasicPrincipalAuthorizer(t *testing.T) {
+	auth := &authmocks.Plugin{}
+	auth.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	principal, err := (&basicPrincipalAuthorizer{Plugin: auth}).AuthorizePrincipal(context.Background(), newTestAuthReq(http.MethodGet, "/api/v1/status", "user1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", principal)
+}
+
+func TestBasicPrincipalAuthorizerFail(t *testing.T) {
+	auth := &authmocks.Plugin{}
+	auth.On("Authorize", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	_, err := (&basicPrincipalAuthorizer{Plugin: auth}).AuthorizePrincipal(context.Background(), newTestAuthReq(http.MethodGet, "/api/v1/status", "user1"))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestVerifiedPrincipalFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	newTestPrincipalAuth(or, "", fmt.Errorf("pop"))
+	assert.Empty(t, or.verifiedPrincipal(context.Background(), &fftypes.AuthReq{}))
+}
+
+func TestGetPermissions(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetPermissions", mock.Anything, "ns", mock.Anything).Return([]*core.NamespacedPermission{}, nil, nil)
+	fb := database.PermissionQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetPermissions(context.Background(), fb.And())
+	assert.NoError(t, err)
+}
+
+func TestGrantPermission(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("UpsertPermission", mock.Anything, mock.MatchedBy(func(p *core.NamespacedPermission) bool {
+		return p.Namespace == "ns" && p.Principal == "user1" && p.Role == core.PermissionRoleAdmin && p.Created != nil
+	})).Return(nil)
+	permission, err := or.GrantPermission(context.Background(), &core.PermissionInput{Principal: "user1", Role: "Admin"})
+	assert.NoError(t, err)
+	assert.Equal(t, core.PermissionRoleAdmin, permission.Role)
+}
+
+func TestGrantPermissionNoPrincipal(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	_, err := or.GrantPermission(context.Background(), &core.PermissionInput{Role: core.PermissionRoleRead})
+	assert.Regexp(t, "FF10508", err)
+}
+
+func TestGrantPermissionBadRole(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	_, err := or.GrantPermission(context.Background(), &core.PermissionInput{Principal: "user1", Role: "superuser"})
+	assert.Regexp(t, "FF00172", err)
+}
+
+func TestGrantPermissionFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("UpsertPermission", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	_, err := or.GrantPermission(context.Background(), &core.PermissionInput{Principal: "user1", Role: core.PermissionRoleRead})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRevokePermission(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetPermission", mock.Anything, "ns", "user1").Return(&core.NamespacedPermission{Principal: "user1"}, nil)
+	or.mdi.On("DeletePermission", mock.Anything, "ns", "user1").Return(nil)
+	err := or.RevokePermission(context.Background(), "user1")
+	assert.NoError(t, err)
+}
+
+func TestRevokePermissionNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetPermission", mock.Anything, "ns", "user1").Return(nil, nil)
+	err := or.RevokePermission(context.Background(), "user1")
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestRevokePermissionLookupFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetPermission", mock.Anything, "ns", "user1").Return(nil, fmt.Errorf("pop"))
+	err := or.RevokePermission(context.Background(), "user1")
+	assert.EqualError(t, err, "pop")
+}
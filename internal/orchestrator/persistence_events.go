@@ -31,11 +31,18 @@ func (or *orchestrator) OrderedUUIDCollectionNSEvent(resType database.OrderedUUI
 	switch {
 	case eventType == core.ChangeEventTypeCreated && resType == database.CollectionMessages:
 		or.batch.NewMessages() <- sequence
-	case eventType == core.ChangeEventTypeCreated && resType == database.CollectionEvents:
-		or.events.NewEvents() <- sequence
 	}
 }
 
+// EventCreated implements the optional database.EventCallbacks, so the event manager can filter on the full event
+func (or *orchestrator) EventCreated(event *core.Event) {
+	if event.Namespace != or.namespace.Name {
+		log.L(or.ctx).Debugf("Ignoring database event from different namespace '%s'", event.Namespace)
+		return
+	}
+	or.events.NewEvents() <- event
+}
+
 func (or *orchestrator) OrderedCollectionNSEvent(resType database.OrderedCollectionNS, eventType core.ChangeEventType, ns string, sequence int64) {
 	if ns != or.namespace.Name {
 		log.L(or.ctx).Debugf("Ignoring database event from different namespace '%s'", ns)
@@ -55,8 +55,21 @@ func TestEventCreated(t *testing.T) {
 		namespace: &core.Namespace{Name: "ns1", NetworkName: "ns1"},
 		events:    mem,
 	}
-	mem.On("NewEvents").Return((chan<- int64)(make(chan int64, 1)))
-	o.OrderedUUIDCollectionNSEvent(database.CollectionEvents, core.ChangeEventTypeCreated, "ns1", fftypes.NewUUID(), 12345)
+	mem.On("NewEvents").Return((chan<- *core.Event)(make(chan *core.Event, 1)))
+	// The database plugin only passes full events to handlers that implement the optional interface
+	var ecb database.EventCallbacks = o
+	ecb.EventCreated(&core.Event{Namespace: "ns1", ID: fftypes.NewUUID(), Sequence: 12345})
+	mem.AssertExpectations(t)
+}
+
+func TestEventCreatedWrongNS(t *testing.T) {
+	mem := &eventmocks.EventManager{}
+	o := &orchestrator{
+		ctx:       context.Background(),
+		namespace: &core.Namespace{Name: "ns1", NetworkName: "ns1"},
+		events:    mem,
+	}
+	o.EventCreated(&core.Event{Namespace: "ns2", ID: fftypes.NewUUID(), Sequence: 12345})
 	mem.AssertExpectations(t)
 }
 
@@ -81,7 +81,8 @@ func (or *orchestrator) createUpdateSubscription(ctx context.Context, subDef *co
 		}
 	}
 
-	return subDef, or.events.CreateUpdateDurableSubscription(ctx, subDef, mustNew)
+	err = or.events.CreateUpdateDurableSubscription(ctx, subDef, mustNew)
+	return subDef.WithoutSecrets(), err
 }
 
 func (or *orchestrator) DeleteSubscription(ctx context.Context, id string) error {
@@ -100,7 +101,11 @@ func (or *orchestrator) DeleteSubscription(ctx context.Context, id string) error
 }
 
 func (or *orchestrator) GetSubscriptions(ctx context.Context, filter ffapi.AndFilter) ([]*core.Subscription, *ffapi.FilterResult, error) {
-	return or.database().GetSubscriptions(ctx, or.namespace.Name, filter)
+	subs, fr, err := or.database().GetSubscriptions(ctx, or.namespace.Name, filter)
+	for i, sub := range subs {
+		subs[i] = sub.WithoutSecrets()
+	}
+	return subs, fr, err
 }
 
 func (or *orchestrator) GetSubscriptionByID(ctx context.Context, id string) (*core.Subscription, error) {
@@ -108,7 +113,11 @@ func (or *orchestrator) GetSubscriptionByID(ctx context.Context, id string) (*co
 	if err != nil {
 		return nil, err
 	}
-	return or.database().GetSubscriptionByID(ctx, or.namespace.Name, u)
+	sub, err := or.database().GetSubscriptionByID(ctx, or.namespace.Name, u)
+	if err != nil || sub == nil {
+		return nil, err
+	}
+	return sub.WithoutSecrets(), nil
 }
 
 func (or *orchestrator) GetSubscriptionByIDWithStatus(ctx context.Context, id string) (*core.SubscriptionWithStatus, error) {
@@ -138,6 +147,15 @@ func (or *orchestrator) GetSubscriptionByIDWithStatus(ctx context.Context, id st
 	return subWithStatus, nil
 }
 
+func (or *orchestrator) GetSubscriptionDeadLetters(ctx context.Context, id string, filter ffapi.AndFilter) ([]*core.DeadLetter, *ffapi.FilterResult, error) {
+	u, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	filter = filter.Condition(filter.Builder().Eq("subscription", u))
+	return or.database().GetDeadLetters(ctx, or.namespace.Name, filter)
+}
+
 func (or *orchestrator) GetSubscriptionEventsHistorical(ctx context.Context, subscription *core.Subscription, filter ffapi.AndFilter, startSequence int, endSequence int) ([]*core.EnrichedEvent, *ffapi.FilterResult, error) {
 	if startSequence != -1 && endSequence != -1 && endSequence-startSequence > config.GetInt(coreconfig.SubscriptionMaxHistoricalEventScanLength) {
 		return nil, nil, i18n.NewError(ctx, coremsgs.MsgMaxSubscriptionEventScanLimitBreached, startSequence, endSequence)
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/internal/events/system"
 	"github.com/hyperledger/firefly/internal/events/webhooks"
@@ -157,6 +158,24 @@ func TestCreateSubscriptionOk(t *testing.T) {
 	assert.Equal(t, "ns", sub.Namespace)
 }
 
+func TestCreateSubscriptionWithoutSecrets(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			Name: "sub1",
+		},
+	}
+	sub.Options.Secret = "shh"
+	or.mem.On("CreateUpdateDurableSubscription", mock.Anything, mock.MatchedBy(func(s *core.Subscription) bool {
+		return s.Options.Secret == "shh"
+	}), true).Return(nil)
+	s1, err := or.CreateSubscription(or.ctx, sub)
+	assert.NoError(t, err)
+	assert.Empty(t, s1.Options.Secret)
+}
+
 func TestCreateSubscriptionTLSConfigOk(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
@@ -274,11 +293,14 @@ func TestGetSubscriptions(t *testing.T) {
 	defer or.cleanup(t)
 
 	u := fftypes.NewUUID()
-	or.mdi.On("GetSubscriptions", mock.Anything, "ns", mock.Anything).Return([]*core.Subscription{}, nil, nil)
+	sub := &core.Subscription{}
+	sub.Options.Secret = "shh"
+	or.mdi.On("GetSubscriptions", mock.Anything, "ns", mock.Anything).Return([]*core.Subscription{sub}, nil, nil)
 	fb := database.SubscriptionQueryFactory.NewFilter(context.Background())
 	f := fb.And(fb.Eq("id", u))
-	_, _, err := or.GetSubscriptions(context.Background(), f)
+	subs, _, err := or.GetSubscriptions(context.Background(), f)
 	assert.NoError(t, err)
+	assert.Empty(t, subs[0].Options.Secret)
 }
 
 func TestGetSGetSubscriptionsByID(t *testing.T) {
@@ -291,6 +313,19 @@ func TestGetSGetSubscriptionsByID(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGetSubscriptionByIDWithoutSecrets(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	u := fftypes.NewUUID()
+	sub := &core.Subscription{}
+	sub.Options.Secret = "shh"
+	or.mdi.On("GetSubscriptionByID", mock.Anything, "ns", u).Return(sub, nil)
+	res, err := or.GetSubscriptionByID(context.Background(), u.String())
+	assert.NoError(t, err)
+	assert.Empty(t, res.Options.Secret)
+}
+
 func TestGetSubscriptionDefsByIDBadID(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
@@ -299,6 +334,30 @@ func TestGetSubscriptionDefsByIDBadID(t *testing.T) {
 	assert.Regexp(t, "FF00138", err)
 }
 
+func TestGetSubscriptionDeadLetters(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	u := fftypes.NewUUID()
+	or.mdi.On("GetDeadLetters", mock.Anything, "ns", mock.Anything).Return([]*core.DeadLetter{}, nil, nil)
+	fb := database.DeadLetterQueryFactory.NewFilter(context.Background())
+	f := fb.And()
+	_, _, err := or.GetSubscriptionDeadLetters(context.Background(), u.String(), f)
+	assert.NoError(t, err)
+	calculatedFilter, err := or.mdi.Calls[0].Arguments[2].(ffapi.Filter).Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`( subscription == '%s' )`, u), calculatedFilter.String())
+}
+
+func TestGetSubscriptionDeadLettersBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	fb := database.DeadLetterQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetSubscriptionDeadLetters(context.Background(), "", fb.And())
+	assert.Regexp(t, "FF00138", err)
+}
+
 func TestGetSGetSubscriptionsByIDWithStatus(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
@@ -126,6 +126,12 @@ func (or *orchestrator) GetTransactionStatus(ctx context.Context, id string) (*c
 			})
 		}
 
+	case core.TransactionTypeMessagePin:
+		if len(events) == 0 {
+			result.Details = append(result.Details, pendingPlaceholder(core.TransactionStatusTypeBlockchainEvent))
+			updateStatus(result, core.OpStatusPending)
+		}
+
 	case core.TransactionTypeTokenPool:
 		// Note: no assumptions about blockchain events here (may or may not contain one)
 		f := database.TokenPoolQueryFactory.NewFilter(ctx)
@@ -790,6 +790,53 @@ func TestGetTransactionStatusContractInvokeSuccess(t *testing.T) {
 	or.mdi.AssertExpectations(t)
 }
 
+func TestGetTransactionStatusMessagePinPending(t *testing.T) {
+	or := newTestOrchestrator()
+
+	txID := fftypes.NewUUID()
+	tx := &core.Transaction{
+		Namespace: "ns1",
+		Type:      core.TransactionTypeMessagePin,
+	}
+	ops := []*core.Operation{
+		{
+			Namespace: "ns1",
+			Status:    core.OpStatusSucceeded,
+			ID:        fftypes.NewUUID(),
+			Type:      core.OpTypeBlockchainPinMessage,
+			Updated:   fftypes.UnixTime(0),
+		},
+	}
+
+	or.mth.On("GetTransactionByIDCached", mock.Anything, txID).Return(tx, nil)
+	or.mdi.On("GetOperations", mock.Anything, "ns", mock.Anything).Return(ops, nil, nil)
+	or.mdi.On("GetBlockchainEvents", mock.Anything, "ns", mock.Anything).Return([]*core.BlockchainEvent{}, nil, nil)
+
+	status, err := or.GetTransactionStatus(context.Background(), txID.String())
+	assert.NoError(t, err)
+
+	expectedStatus := compactJSON(`{
+		"status": "Pending",
+		"details": [
+			{
+				"type": "BlockchainEvent",
+				"status": "Pending"
+			},
+			{
+				"type": "Operation",
+				"subtype": "blockchain_pin_message",
+				"status": "Succeeded",
+				"timestamp": "1970-01-01T00:00:00Z",
+				"id": "` + ops[0].ID.String() + `"
+			}
+		]
+	}`)
+	statusJSON, _ := json.Marshal(status)
+	assert.Equal(t, expectedStatus, string(statusJSON))
+
+	or.mdi.AssertExpectations(t)
+}
+
 func TestGetTransactionStatusTXError(t *testing.T) {
 	or := newTestOrchestrator()
 
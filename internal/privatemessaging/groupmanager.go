@@ -34,6 +34,7 @@ import (
 
 type GroupManager interface {
 	GetGroupByID(ctx context.Context, id string) (*core.Group, error)
+	GetGroupByHashCached(ctx context.Context, hash *fftypes.Bytes32) (*core.Group, error)
 	GetGroups(ctx context.Context, filter ffapi.AndFilter) ([]*core.Group, *ffapi.FilterResult, error)
 	ResolveInitGroup(ctx context.Context, msg *core.Message, creator *core.Member) (*core.Group, error)
 	EnsureLocalGroup(ctx context.Context, group *core.Group, creator *core.Member) (ok bool, err error)
@@ -137,7 +138,7 @@ func (gm *groupManager) groupInit(ctx context.Context, signer *core.SignerRef, g
 	if err = msg.Seal(ctx); err == nil {
 		err = gm.database.RunAsGroup(ctx, func(ctx context.Context) error {
 			// Write as data to the local store
-			if err = gm.database.UpsertData(ctx, data, database.UpsertOptimizationNew); err != nil {
+			if err = gm.database.UpsertData(ctx, data, database.UpsertOptimizationNew, false); err != nil {
 				return err
 			}
 
@@ -166,6 +167,12 @@ func (gm *groupManager) GetGroupByID(ctx context.Context, hash string) (*core.Gr
 	return gm.database.GetGroupByHash(ctx, gm.namespace.Name, h)
 }
 
+// GetGroupByHashCached returns the group (or nil if it does not exist), from the group cache where possible
+func (gm *groupManager) GetGroupByHashCached(ctx context.Context, hash *fftypes.Bytes32) (*core.Group, error) {
+	group, _, err := gm.getGroupNodes(ctx, hash, true)
+	return group, err
+}
+
 func (gm *groupManager) GetGroups(ctx context.Context, filter ffapi.AndFilter) ([]*core.Group, *ffapi.FilterResult, error) {
 	return gm.database.GetGroups(ctx, gm.namespace.Name, filter)
 }
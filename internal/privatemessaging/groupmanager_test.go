@@ -51,7 +51,7 @@ func TestGroupInitWriteGroupFail(t *testing.T) {
 
 	mdi := pm.database.(*databasemocks.Plugin)
 	mdi.On("UpsertGroup", mock.Anything, mock.Anything, database.UpsertOptimizationNew).Return(fmt.Errorf("pop"))
-	mdi.On("UpsertData", mock.Anything, mock.Anything, database.UpsertOptimizationNew).Return(nil)
+	mdi.On("UpsertData", mock.Anything, mock.Anything, database.UpsertOptimizationNew, false).Return(nil)
 	mdi.On("UpsertMessage", mock.Anything, mock.Anything, database.UpsertOptimizationNew).Return(nil)
 
 	mim := pm.identity.(*identitymanagermocks.Manager)
@@ -83,7 +83,7 @@ func TestGroupInitWriteMessageFail(t *testing.T) {
 	org := &core.Identity{}
 
 	mdi := pm.database.(*databasemocks.Plugin)
-	mdi.On("UpsertData", mock.Anything, mock.Anything, database.UpsertOptimizationNew).Return(nil)
+	mdi.On("UpsertData", mock.Anything, mock.Anything, database.UpsertOptimizationNew, false).Return(nil)
 	mdi.On("UpsertMessage", mock.Anything, mock.Anything, database.UpsertOptimizationNew).Return(fmt.Errorf("pop"))
 
 	mim := pm.identity.(*identitymanagermocks.Manager)
@@ -115,7 +115,7 @@ func TestGroupInitWriteDataFail(t *testing.T) {
 	org := &core.Identity{}
 
 	mdi := pm.database.(*databasemocks.Plugin)
-	mdi.On("UpsertData", mock.Anything, mock.Anything, database.UpsertOptimizationNew).Return(fmt.Errorf("pop"))
+	mdi.On("UpsertData", mock.Anything, mock.Anything, database.UpsertOptimizationNew, false).Return(fmt.Errorf("pop"))
 
 	mim := pm.identity.(*identitymanagermocks.Manager)
 	mim.On("CachedIdentityLookupByID", mock.Anything, member.Node).Return(node, nil)
@@ -558,6 +558,55 @@ func TestGetGroupByIDBadID(t *testing.T) {
 	assert.Regexp(t, "FF00107", err)
 }
 
+func TestGetGroupByHashCached(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	node1 := fftypes.NewUUID()
+	group := &core.Group{
+		GroupIdentity: core.GroupIdentity{
+			Members: core.Members{
+				&core.Member{Node: node1},
+			},
+		},
+	}
+	group.Seal()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupByHash", pm.ctx, "ns1", group.Hash).Return(group, nil).Once()
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookupByID", pm.ctx, node1).Return(&core.Identity{
+		IdentityBase: core.IdentityBase{
+			ID:   node1,
+			Type: core.IdentityTypeNode,
+		},
+	}, nil).Once()
+
+	g, err := pm.GetGroupByHashCached(pm.ctx, group.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, *group.Hash, *g.Hash)
+
+	// Second lookup is served from the cache
+	g, err = pm.GetGroupByHashCached(pm.ctx, group.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, *group.Hash, *g.Hash)
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestGetGroupByHashCachedNotFound(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupByHash", pm.ctx, "ns1", mock.Anything).Return(nil, nil)
+
+	g, err := pm.GetGroupByHashCached(pm.ctx, fftypes.NewRandB32())
+	assert.NoError(t, err)
+	assert.Nil(t, g)
+}
+
 func TestGetGroupsOk(t *testing.T) {
 	pm, cancel := newTestPrivateMessaging(t)
 	defer cancel()
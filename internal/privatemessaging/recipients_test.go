@@ -57,7 +57,7 @@ func TestResolveMemberListNewGroupE2E(t *testing.T) {
 	mim.On("ValidateNodeOwner", pm.ctx, localNode, localOrg).Return(true, nil)
 	mim.On("ValidateNodeOwner", pm.ctx, remoteNode, remoteOrg).Return(true, nil)
 
-	ud := mdi.On("UpsertData", pm.ctx, mock.Anything, database.UpsertOptimizationNew).Return(nil)
+	ud := mdi.On("UpsertData", pm.ctx, mock.Anything, database.UpsertOptimizationNew, false).Return(nil)
 	ud.RunFn = func(a mock.Arguments) {
 		data := a[1].(*core.Data)
 		assert.Equal(t, core.ValidatorTypeSystemDefinition, data.Validator)
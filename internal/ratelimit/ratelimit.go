@@ -0,0 +1,82 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the rate at which work is taken on
+type RateLimiter interface {
+	// Take takes up to n tokens, and returns the number taken - which is zero once the limiter is exhausted
+	Take(n int) int
+	// Delay returns how long until the next token is available, or zero if one is available now
+	Delay() time.Duration
+}
+
+type tokenBucket struct {
+	mux    sync.Mutex
+	burst  float64
+	rate   float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewTokenBucket returns a RateLimiter that allows bursts of up to burst tokens, refilled at a sustained
+// rate of tokens per second. The bucket starts full. A burst below one is raised to the rate, so that a
+// full second's worth of tokens can be taken at once.
+func NewTokenBucket(burst int, rate float64) RateLimiter {
+	tb := &tokenBucket{
+		burst: float64(burst),
+		rate:  rate,
+		now:   time.Now,
+	}
+	if tb.burst < 1 {
+		tb.burst = math.Max(1, math.Ceil(rate))
+	}
+	tb.tokens = tb.burst
+	tb.last = tb.now()
+	return tb
+}
+
+func (tb *tokenBucket) refill() {
+	now := tb.now()
+	tb.tokens = math.Min(tb.burst, tb.tokens+now.Sub(tb.last).Seconds()*tb.rate)
+	tb.last = now
+}
+
+func (tb *tokenBucket) Take(n int) int {
+	tb.mux.Lock()
+	defer tb.mux.Unlock()
+	tb.refill()
+	taken := int(math.Min(float64(n), math.Floor(tb.tokens)))
+	tb.tokens -= float64(taken)
+	return taken
+}
+
+func (tb *tokenBucket) Delay() time.Duration {
+	tb.mux.Lock()
+	defer tb.mux.Unlock()
+	tb.refill()
+	if tb.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - tb.tokens) / tb.rate * float64(time.Second))
+}
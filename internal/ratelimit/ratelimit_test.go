@@ -0,0 +1,90 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestTokenBucket(clock *testClock, burst int, rate float64) *tokenBucket {
+	tb := NewTokenBucket(burst, rate).(*tokenBucket)
+	tb.now = func() time.Time { return clock.now }
+	tb.last = clock.now
+	return tb
+}
+
+func TestTokenBucketBurst(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	tb := newTestTokenBucket(clock, 10, 2)
+
+	// The full burst is available straight away, and no more
+	assert.Equal(t, 4, tb.Take(4))
+	assert.Equal(t, 6, tb.Take(100))
+	assert.Equal(t, 0, tb.Take(1))
+	assert.Equal(t, 500*time.Millisecond, tb.Delay())
+
+	// Tokens refill at the sustained rate
+	clock.advance(500 * time.Millisecond)
+	assert.Equal(t, time.Duration(0), tb.Delay())
+	assert.Equal(t, 1, tb.Take(5))
+	clock.advance(2 * time.Second)
+	assert.Equal(t, 4, tb.Take(5))
+
+	// The bucket never holds more than the burst
+	clock.advance(time.Hour)
+	assert.Equal(t, 10, tb.Take(100))
+}
+
+func TestTokenBucketFairnessAcrossNamespaces(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	limiters := map[string]*tokenBucket{
+		"ns1": newTestTokenBucket(clock, 5, 5),
+		"ns2": newTestTokenBucket(clock, 5, 5),
+	}
+
+	// A busy namespace exhausting its limiter does not take from the other namespace
+	assert.Equal(t, 5, limiters["ns1"].Take(1000))
+	assert.Equal(t, 0, limiters["ns1"].Take(1000))
+	assert.Equal(t, 5, limiters["ns2"].Take(1000))
+
+	// Over time both get the same sustained share, however much the busy one asks for
+	totals := map[string]int{}
+	for i := 0; i < 10; i++ {
+		clock.advance(200 * time.Millisecond)
+		totals["ns1"] += limiters["ns1"].Take(1000)
+		totals["ns2"] += limiters["ns2"].Take(1)
+	}
+	assert.Equal(t, 10, totals["ns1"])
+	assert.Equal(t, 10, totals["ns2"])
+}
+
+func TestTokenBucketDefaultBurst(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	assert.Equal(t, 3, newTestTokenBucket(clock, 0, 2.5).Take(10))
+	assert.Equal(t, 1, newTestTokenBucket(clock, 0, 0.5).Take(10))
+}
@@ -45,6 +45,8 @@ type Bridge interface {
 
 	// WaitForReply waits for a reply to the message with the supplied ID
 	WaitForReply(ctx context.Context, id *fftypes.UUID, send SendFunction) (*core.MessageInOut, error)
+	// WaitForReplyTo waits for a message that sets replyTo to the supplied ID
+	WaitForReplyTo(ctx context.Context, id *fftypes.UUID, send SendFunction) (*core.MessageInOut, error)
 	// WaitForMessage waits for a message with the supplied ID
 	WaitForMessage(ctx context.Context, id *fftypes.UUID, send SendFunction) (*core.Message, error)
 	// WaitForIdentity waits for an identity with the supplied ID
@@ -75,6 +77,7 @@ type requestType int
 const (
 	messageConfirm requestType = iota
 	messageReply
+	messageReplyTo
 	identityConfirm
 	tokenPoolConfirm
 	tokenTransferConfirm
@@ -164,6 +167,15 @@ func (sa *syncAsyncBridge) getInFlight(ns string, reqType requestType, id *fftyp
 	return nil
 }
 
+func (sa *syncAsyncBridge) hasInFlight(ns string, reqType requestType) bool {
+	for _, inflight := range sa.inflight[ns] {
+		if inflight.reqType == reqType {
+			return true
+		}
+	}
+	return false
+}
+
 func (sa *syncAsyncBridge) removeInFlight(ns string, id *fftypes.UUID) {
 	sa.inflightMux.Lock()
 	defer func() {
@@ -266,8 +278,10 @@ func (sa *syncAsyncBridge) handleMessageConfirmedEvent(event *core.EventDelivery
 	// See if the CID marks this as a reply to an inflight message
 	inflight := sa.getInFlight(event.Namespace, messageConfirm, event.Reference)
 	inflightReply := sa.getInFlight(event.Namespace, messageReply, event.Correlator)
+	// Replies by replyTo can only be matched once the message is loaded
+	awaitingReplyTo := sa.hasInFlight(event.Namespace, messageReplyTo)
 
-	if inflightReply == nil && inflight == nil {
+	if inflightReply == nil && inflight == nil && !awaitingReplyTo {
 		return nil
 	}
 
@@ -276,6 +290,9 @@ func (sa *syncAsyncBridge) handleMessageConfirmedEvent(event *core.EventDelivery
 		return err
 	}
 
+	if inflightReply == nil && awaitingReplyTo {
+		inflightReply = sa.getInFlight(event.Namespace, messageReplyTo, msg.Header.ReplyTo)
+	}
 	if inflightReply != nil {
 		go sa.resolveReply(inflightReply, msg)
 	}
@@ -656,6 +673,14 @@ func (sa *syncAsyncBridge) WaitForReply(ctx context.Context, id *fftypes.UUID, s
 	return reply.(*core.MessageInOut), err
 }
 
+func (sa *syncAsyncBridge) WaitForReplyTo(ctx context.Context, id *fftypes.UUID, send SendFunction) (*core.MessageInOut, error) {
+	reply, err := sa.sendAndWait(ctx, sa.namespace, id, messageReplyTo, send)
+	if err != nil {
+		return nil, err
+	}
+	return reply.(*core.MessageInOut), err
+}
+
 func (sa *syncAsyncBridge) WaitForMessage(ctx context.Context, id *fftypes.UUID, send SendFunction) (*core.Message, error) {
 	reply, err := sa.sendAndWait(ctx, sa.namespace, id, messageConfirm, send)
 	if err != nil {
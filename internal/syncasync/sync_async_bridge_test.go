@@ -98,6 +98,78 @@ func TestRequestReplyOk(t *testing.T) {
 
 }
 
+func TestRequestReplyToOk(t *testing.T) {
+
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+
+	requestID := fftypes.NewUUID()
+	otherID := fftypes.NewUUID()
+	replyID := fftypes.NewUUID()
+	dataID := fftypes.NewUUID()
+
+	mse := sa.sysevents.(*systemeventmocks.EventInterface)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", sa.ctx, "ns1", otherID).Return(&core.Message{
+		Header: core.MessageHeader{
+			ID: otherID,
+		},
+	}, nil)
+	mdi.On("GetMessageByID", sa.ctx, "ns1", replyID).Return(&core.Message{
+		Header: core.MessageHeader{
+			ID:      replyID,
+			ReplyTo: requestID,
+		},
+		Data: core.DataRefs{
+			{ID: dataID},
+		},
+	}, nil)
+
+	mdm := sa.data.(*datamocks.Manager)
+	mdm.On("GetMessageDataCached", sa.ctx, mock.Anything).Return(core.DataArray{
+		{ID: dataID, Value: fftypes.JSONAnyPtr(`"response data"`)},
+	}, true, nil)
+
+	reply, err := sa.WaitForReplyTo(sa.ctx, requestID, func(ctx context.Context) error {
+		go func() {
+			for _, msgID := range []*fftypes.UUID{otherID, replyID} {
+				sa.eventCallback(&core.EventDelivery{
+					EnrichedEvent: core.EnrichedEvent{
+						Event: core.Event{
+							ID:        fftypes.NewUUID(),
+							Type:      core.EventTypeMessageConfirmed,
+							Reference: msgID,
+							Namespace: "ns1",
+						},
+					},
+				})
+			}
+		}()
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, *replyID, *reply.Header.ID)
+	assert.Equal(t, `"response data"`, reply.InlineData[0].Value.String())
+
+}
+
+func TestRequestReplyToTimeout(t *testing.T) {
+
+	sa, cancel := newTestSyncAsyncBridge(t)
+	cancel()
+
+	mse := sa.sysevents.(*systemeventmocks.EventInterface)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
+
+	_, err := sa.WaitForReplyTo(sa.ctx, fftypes.NewUUID(), func(ctx context.Context) error {
+		return nil
+	})
+	assert.Regexp(t, "FF10260", err)
+	assert.Empty(t, sa.inflight["ns1"])
+}
+
 func TestAwaitConfirmationOk(t *testing.T) {
 
 	sa, cancel := newTestSyncAsyncBridge(t)
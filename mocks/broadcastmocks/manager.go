@@ -11,6 +11,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	syncasync "github.com/hyperledger/firefly/internal/syncasync"
+
+	time "time"
 )
 
 // Manager is an autogenerated mock type for the Manager type
@@ -86,6 +88,36 @@ func (_m *Manager) NewBroadcast(in *core.MessageInOut) syncasync.Sender {
 	return r0
 }
 
+// PinMessage provides a mock function with given fields: ctx, id
+func (_m *Manager) PinMessage(ctx context.Context, id string) (*fftypes.UUID, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PinMessage")
+	}
+
+	var r0 *fftypes.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*fftypes.UUID, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *fftypes.UUID); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // PrepareOperation provides a mock function with given fields: ctx, op
 func (_m *Manager) PrepareOperation(ctx context.Context, op *core.Operation) (*core.PreparedOperation, error) {
 	ret := _m.Called(ctx, op)
@@ -176,6 +208,36 @@ func (_m *Manager) PublishDataValue(ctx context.Context, id string, idempotencyK
 	return r0, r1
 }
 
+// RequestReply provides a mock function with given fields: ctx, in, timeout
+func (_m *Manager) RequestReply(ctx context.Context, in *core.MessageInOut, timeout time.Duration) (*core.MessageInOut, error) {
+	ret := _m.Called(ctx, in, timeout)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequestReply")
+	}
+
+	var r0 *core.MessageInOut
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.MessageInOut, time.Duration) (*core.MessageInOut, error)); ok {
+		return rf(ctx, in, timeout)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.MessageInOut, time.Duration) *core.MessageInOut); ok {
+		r0 = rf(ctx, in, timeout)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.MessageInOut)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.MessageInOut, time.Duration) error); ok {
+		r1 = rf(ctx, in, timeout)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RunOperation provides a mock function with given fields: ctx, op
 func (_m *Manager) RunOperation(ctx context.Context, op *core.PreparedOperation) (fftypes.JSONObject, core.OpPhase, error) {
 	ret := _m.Called(ctx, op)
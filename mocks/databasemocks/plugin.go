@@ -15,6 +15,8 @@ import (
 
 	fftypes "github.com/hyperledger/firefly-common/pkg/fftypes"
 
+	io "io"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -23,6 +25,34 @@ type Plugin struct {
 	mock.Mock
 }
 
+// ArchiveEvents provides a mock function with given fields: ctx, namespace, createdBefore, limit
+func (_m *Plugin) ArchiveEvents(ctx context.Context, namespace string, createdBefore *fftypes.FFTime, limit int) (int64, error) {
+	ret := _m.Called(ctx, namespace, createdBefore, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ArchiveEvents")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.FFTime, int) (int64, error)); ok {
+		return rf(ctx, namespace, createdBefore, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.FFTime, int) int64); ok {
+		r0 = rf(ctx, namespace, createdBefore, limit)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.FFTime, int) error); ok {
+		r1 = rf(ctx, namespace, createdBefore, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Capabilities provides a mock function with given fields:
 func (_m *Plugin) Capabilities() *database.Capabilities {
 	ret := _m.Called()
@@ -43,6 +73,80 @@ func (_m *Plugin) Capabilities() *database.Capabilities {
 	return r0
 }
 
+// CloneNamespace provides a mock function with given fields: ctx, src, dest
+func (_m *Plugin) CloneNamespace(ctx context.Context, src string, dest string) error {
+	ret := _m.Called(ctx, src, dest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CloneNamespace")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, src, dest)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CountBlobReferences provides a mock function with given fields: ctx, payloadRef
+func (_m *Plugin) CountBlobReferences(ctx context.Context, payloadRef string) (int64, error) {
+	ret := _m.Called(ctx, payloadRef)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountBlobReferences")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int64, error)); ok {
+		return rf(ctx, payloadRef)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = rf(ctx, payloadRef)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, payloadRef)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountPinsInSequenceRange provides a mock function with given fields: ctx, after, upTo
+func (_m *Plugin) CountPinsInSequenceRange(ctx context.Context, after int64, upTo int64) (int64, error) {
+	ret := _m.Called(ctx, after, upTo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountPinsInSequenceRange")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (int64, error)); ok {
+		return rf(ctx, after, upTo)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) int64); ok {
+		r0 = rf(ctx, after, upTo)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, after, upTo)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteBlob provides a mock function with given fields: ctx, sequence
 func (_m *Plugin) DeleteBlob(ctx context.Context, sequence int64) error {
 	ret := _m.Called(ctx, sequence)
@@ -115,6 +219,70 @@ func (_m *Plugin) DeleteData(ctx context.Context, namespace string, id *fftypes.
 	return r0
 }
 
+// DeleteDeadEvent provides a mock function with given fields: ctx, namespace, id
+func (_m *Plugin) DeleteDeadEvent(ctx context.Context, namespace string, id *fftypes.UUID) error {
+	ret := _m.Called(ctx, namespace, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteDeadEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, namespace, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteEvent provides a mock function with given fields: ctx, namespace, id
+func (_m *Plugin) DeleteEvent(ctx context.Context, namespace string, id *fftypes.UUID) error {
+	ret := _m.Called(ctx, namespace, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, namespace, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteEventsBefore provides a mock function with given fields: ctx, namespace, sequence
+func (_m *Plugin) DeleteEventsBefore(ctx context.Context, namespace string, sequence int64) (int64, error) {
+	ret := _m.Called(ctx, namespace, sequence)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteEventsBefore")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) (int64, error)); ok {
+		return rf(ctx, namespace, sequence)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) int64); ok {
+		r0 = rf(ctx, namespace, sequence)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(ctx, namespace, sequence)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteFFI provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) DeleteFFI(ctx context.Context, namespace string, id *fftypes.UUID) error {
 	ret := _m.Called(ctx, namespace, id)
@@ -133,6 +301,24 @@ func (_m *Plugin) DeleteFFI(ctx context.Context, namespace string, id *fftypes.U
 	return r0
 }
 
+// DeleteMessage provides a mock function with given fields: ctx, namespace, id, states
+func (_m *Plugin) DeleteMessage(ctx context.Context, namespace string, id *fftypes.UUID, states []core.MessageState) error {
+	ret := _m.Called(ctx, namespace, id, states)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID, []core.MessageState) error); ok {
+		r0 = rf(ctx, namespace, id, states)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteNonce provides a mock function with given fields: ctx, hash
 func (_m *Plugin) DeleteNonce(ctx context.Context, hash *fftypes.Bytes32) error {
 	ret := _m.Called(ctx, hash)
@@ -169,6 +355,42 @@ func (_m *Plugin) DeleteOffset(ctx context.Context, t fftypes.FFEnum, name strin
 	return r0
 }
 
+// DeletePendingDeliveryReceipt provides a mock function with given fields: ctx, namespace, id
+func (_m *Plugin) DeletePendingDeliveryReceipt(ctx context.Context, namespace string, id *fftypes.UUID) error {
+	ret := _m.Called(ctx, namespace, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePendingDeliveryReceipt")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, namespace, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeletePermission provides a mock function with given fields: ctx, namespace, principal
+func (_m *Plugin) DeletePermission(ctx context.Context, namespace string, principal string) error {
+	ret := _m.Called(ctx, namespace, principal)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePermission")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, namespace, principal)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteSubscriptionByID provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) DeleteSubscriptionByID(ctx context.Context, namespace string, id *fftypes.UUID) error {
 	ret := _m.Called(ctx, namespace, id)
@@ -259,6 +481,130 @@ func (_m *Plugin) DeleteTokenTransfers(ctx context.Context, namespace string, po
 	return r0
 }
 
+// ExpireMessage provides a mock function with given fields: ctx, namespace, id
+func (_m *Plugin) ExpireMessage(ctx context.Context, namespace string, id *fftypes.UUID) (bool, error) {
+	ret := _m.Called(ctx, namespace, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExpireMessage")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) (bool, error)); ok {
+		return rf(ctx, namespace, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) bool); ok {
+		r0 = rf(ctx, namespace, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, namespace, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExportNamespace provides a mock function with given fields: ctx, namespace, w
+func (_m *Plugin) ExportNamespace(ctx context.Context, namespace string, w io.Writer) error {
+	ret := _m.Called(ctx, namespace, w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportNamespace")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, io.Writer) error); ok {
+		r0 = rf(ctx, namespace, w)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetArchivedEvents provides a mock function with given fields: ctx, namespace, filter
+func (_m *Plugin) GetArchivedEvents(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.Event, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetArchivedEvents")
+	}
+
+	var r0 []*core.Event
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) ([]*core.Event, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) []*core.Event); ok {
+		r0 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetArchivedEventsInNamespaces provides a mock function with given fields: ctx, namespaces, filter
+func (_m *Plugin) GetArchivedEventsInNamespaces(ctx context.Context, namespaces []string, filter ffapi.Filter) ([]*core.Event, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespaces, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetArchivedEventsInNamespaces")
+	}
+
+	var r0 []*core.Event
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string, ffapi.Filter) ([]*core.Event, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespaces, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string, ffapi.Filter) []*core.Event); ok {
+		r0 = rf(ctx, namespaces, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespaces, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, []string, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespaces, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetBatchByID provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) GetBatchByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.BatchPersisted, error) {
 	ret := _m.Called(ctx, namespace, id)
@@ -526,6 +872,135 @@ func (_m *Plugin) GetBlockchainEvents(ctx context.Context, namespace string, fil
 	return r0, r1, r2
 }
 
+// GetBlockchainReceiptByOpID provides a mock function with given fields: ctx, namespace, opID
+func (_m *Plugin) GetBlockchainReceiptByOpID(ctx context.Context, namespace string, opID *fftypes.UUID) (*core.BlockchainReceipt, error) {
+	ret := _m.Called(ctx, namespace, opID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBlockchainReceiptByOpID")
+	}
+
+	var r0 *core.BlockchainReceipt
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) (*core.BlockchainReceipt, error)); ok {
+		return rf(ctx, namespace, opID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) *core.BlockchainReceipt); ok {
+		r0 = rf(ctx, namespace, opID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.BlockchainReceipt)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, namespace, opID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBlockchainReceiptsForMessages provides a mock function with given fields: ctx, namespace, msgIDs
+func (_m *Plugin) GetBlockchainReceiptsForMessages(ctx context.Context, namespace string, msgIDs []*fftypes.UUID) ([]*core.BlockchainReceipt, error) {
+	ret := _m.Called(ctx, namespace, msgIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBlockchainReceiptsForMessages")
+	}
+
+	var r0 []*core.BlockchainReceipt
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*fftypes.UUID) ([]*core.BlockchainReceipt, error)); ok {
+		return rf(ctx, namespace, msgIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*fftypes.UUID) []*core.BlockchainReceipt); ok {
+		r0 = rf(ctx, namespace, msgIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.BlockchainReceipt)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []*fftypes.UUID) error); ok {
+		r1 = rf(ctx, namespace, msgIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBlockedContexts provides a mock function with given fields: ctx, namespace, upToSequence, filter
+func (_m *Plugin) GetBlockedContexts(ctx context.Context, namespace string, upToSequence int64, filter ffapi.Filter) ([]*core.Pin, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, upToSequence, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBlockedContexts")
+	}
+
+	var r0 []*core.Pin
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, ffapi.Filter) ([]*core.Pin, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, upToSequence, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, ffapi.Filter) []*core.Pin); ok {
+		r0 = rf(ctx, namespace, upToSequence, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.Pin)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, upToSequence, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, int64, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, upToSequence, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetBlockedStats provides a mock function with given fields: ctx, namespace, upToSequence, cutoffs
+func (_m *Plugin) GetBlockedStats(ctx context.Context, namespace string, upToSequence int64, cutoffs []*fftypes.FFTime) (*core.BlockedContextStats, error) {
+	ret := _m.Called(ctx, namespace, upToSequence, cutoffs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBlockedStats")
+	}
+
+	var r0 *core.BlockedContextStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, []*fftypes.FFTime) (*core.BlockedContextStats, error)); ok {
+		return rf(ctx, namespace, upToSequence, cutoffs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, []*fftypes.FFTime) *core.BlockedContextStats); ok {
+		r0 = rf(ctx, namespace, upToSequence, cutoffs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.BlockedContextStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, []*fftypes.FFTime) error); ok {
+		r1 = rf(ctx, namespace, upToSequence, cutoffs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetChartHistogram provides a mock function with given fields: ctx, namespace, intervals, collection
 func (_m *Plugin) GetChartHistogram(ctx context.Context, namespace string, intervals []core.ChartHistogramInterval, collection database.CollectionName) ([]*core.ChartHistogram, error) {
 	ret := _m.Called(ctx, namespace, intervals, collection)
@@ -952,6 +1427,45 @@ func (_m *Plugin) GetDataSubPaths(ctx context.Context, namespace string, path st
 	return r0, r1
 }
 
+// GetDataWithBlob provides a mock function with given fields: ctx, namespace, id, withValue
+func (_m *Plugin) GetDataWithBlob(ctx context.Context, namespace string, id *fftypes.UUID, withValue bool) (*core.Data, *core.Blob, error) {
+	ret := _m.Called(ctx, namespace, id, withValue)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDataWithBlob")
+	}
+
+	var r0 *core.Data
+	var r1 *core.Blob
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID, bool) (*core.Data, *core.Blob, error)); ok {
+		return rf(ctx, namespace, id, withValue)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID, bool) *core.Data); ok {
+		r0 = rf(ctx, namespace, id, withValue)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Data)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.UUID, bool) *core.Blob); ok {
+		r1 = rf(ctx, namespace, id, withValue)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*core.Blob)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, *fftypes.UUID, bool) error); ok {
+		r2 = rf(ctx, namespace, id, withValue)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetDatatypeByID provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) GetDatatypeByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.Datatype, error) {
 	ret := _m.Called(ctx, namespace, id)
@@ -1048,7 +1562,184 @@ func (_m *Plugin) GetDatatypes(ctx context.Context, namespace string, filter ffa
 		r2 = ret.Error(2)
 	}
 
-	return r0, r1, r2
+	return r0, r1, r2
+}
+
+// GetDeadEventByID provides a mock function with given fields: ctx, namespace, id
+func (_m *Plugin) GetDeadEventByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.DeadEvent, error) {
+	ret := _m.Called(ctx, namespace, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeadEventByID")
+	}
+
+	var r0 *core.DeadEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) (*core.DeadEvent, error)); ok {
+		return rf(ctx, namespace, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) *core.DeadEvent); ok {
+		r0 = rf(ctx, namespace, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.DeadEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, namespace, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeadEvents provides a mock function with given fields: ctx, namespace, filter
+func (_m *Plugin) GetDeadEvents(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.DeadEvent, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeadEvents")
+	}
+
+	var r0 []*core.DeadEvent
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) ([]*core.DeadEvent, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) []*core.DeadEvent); ok {
+		r0 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.DeadEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetDeadLetters provides a mock function with given fields: ctx, namespace, filter
+func (_m *Plugin) GetDeadLetters(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.DeadLetter, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeadLetters")
+	}
+
+	var r0 []*core.DeadLetter
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) ([]*core.DeadLetter, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) []*core.DeadLetter); ok {
+		r0 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.DeadLetter)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetDeliveryReceipts provides a mock function with given fields: ctx, namespace, filter
+func (_m *Plugin) GetDeliveryReceipts(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.DeliveryReceipt, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeliveryReceipts")
+	}
+
+	var r0 []*core.DeliveryReceipt
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) ([]*core.DeliveryReceipt, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) []*core.DeliveryReceipt); ok {
+		r0 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.DeliveryReceipt)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetDescendantNamespaces provides a mock function with given fields: ctx, root
+func (_m *Plugin) GetDescendantNamespaces(ctx context.Context, root string) ([]*core.Namespace, error) {
+	ret := _m.Called(ctx, root)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDescendantNamespaces")
+	}
+
+	var r0 []*core.Namespace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*core.Namespace, error)); ok {
+		return rf(ctx, root)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*core.Namespace); ok {
+		r0 = rf(ctx, root)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, root)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // GetEventByID provides a mock function with given fields: ctx, namespace, id
@@ -1120,6 +1811,45 @@ func (_m *Plugin) GetEvents(ctx context.Context, namespace string, filter ffapi.
 	return r0, r1, r2
 }
 
+// GetEventsInNamespaces provides a mock function with given fields: ctx, namespaces, filter
+func (_m *Plugin) GetEventsInNamespaces(ctx context.Context, namespaces []string, filter ffapi.Filter) ([]*core.Event, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespaces, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEventsInNamespaces")
+	}
+
+	var r0 []*core.Event
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string, ffapi.Filter) ([]*core.Event, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespaces, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string, ffapi.Filter) []*core.Event); ok {
+		r0 = rf(ctx, namespaces, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespaces, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, []string, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespaces, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetEventsInSequenceRange provides a mock function with given fields: ctx, namespace, filter, startSequence, endSequence
 func (_m *Plugin) GetEventsInSequenceRange(ctx context.Context, namespace string, filter ffapi.Filter, startSequence int, endSequence int) ([]*core.Event, *ffapi.FilterResult, error) {
 	ret := _m.Called(ctx, namespace, filter, startSequence, endSequence)
@@ -1723,6 +2453,36 @@ func (_m *Plugin) GetMessageIDs(ctx context.Context, namespace string, filter ff
 	return r0, r1
 }
 
+// GetMessageRef provides a mock function with given fields: ctx, namespace, id
+func (_m *Plugin) GetMessageRef(ctx context.Context, namespace string, id *fftypes.UUID) (*core.IDAndSequence, error) {
+	ret := _m.Called(ctx, namespace, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessageRef")
+	}
+
+	var r0 *core.IDAndSequence
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) (*core.IDAndSequence, error)); ok {
+		return rf(ctx, namespace, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) *core.IDAndSequence); ok {
+		r0 = rf(ctx, namespace, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.IDAndSequence)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, namespace, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMessages provides a mock function with given fields: ctx, namespace, filter
 func (_m *Plugin) GetMessages(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.Message, *ffapi.FilterResult, error) {
 	ret := _m.Called(ctx, namespace, filter)
@@ -1801,6 +2561,75 @@ func (_m *Plugin) GetMessagesForData(ctx context.Context, namespace string, data
 	return r0, r1, r2
 }
 
+// GetMessagesForTag provides a mock function with given fields: ctx, namespace, tag, filter
+func (_m *Plugin) GetMessagesForTag(ctx context.Context, namespace string, tag string, filter ffapi.Filter) ([]*core.Message, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, tag, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessagesForTag")
+	}
+
+	var r0 []*core.Message
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, ffapi.Filter) ([]*core.Message, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, tag, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, ffapi.Filter) []*core.Message); ok {
+		r0 = rf(ctx, namespace, tag, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, tag, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, tag, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetMessagesPastTTL provides a mock function with given fields: ctx, namespace, now, limit
+func (_m *Plugin) GetMessagesPastTTL(ctx context.Context, namespace string, now *fftypes.FFTime, limit int) ([]*core.Message, error) {
+	ret := _m.Called(ctx, namespace, now, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessagesPastTTL")
+	}
+
+	var r0 []*core.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.FFTime, int) ([]*core.Message, error)); ok {
+		return rf(ctx, namespace, now, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.FFTime, int) []*core.Message); ok {
+		r0 = rf(ctx, namespace, now, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.FFTime, int) error); ok {
+		r1 = rf(ctx, namespace, now, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetNamespace provides a mock function with given fields: ctx, name
 func (_m *Plugin) GetNamespace(ctx context.Context, name string) (*core.Namespace, error) {
 	ret := _m.Called(ctx, name)
@@ -2107,6 +2936,105 @@ func (_m *Plugin) GetOperations(ctx context.Context, namespace string, filter ff
 	return r0, r1, r2
 }
 
+// GetPendingDeliveryReceipts provides a mock function with given fields: ctx, namespace, limit
+func (_m *Plugin) GetPendingDeliveryReceipts(ctx context.Context, namespace string, limit int) ([]*core.DeliveryReceipt, error) {
+	ret := _m.Called(ctx, namespace, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPendingDeliveryReceipts")
+	}
+
+	var r0 []*core.DeliveryReceipt
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]*core.DeliveryReceipt, error)); ok {
+		return rf(ctx, namespace, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []*core.DeliveryReceipt); ok {
+		r0 = rf(ctx, namespace, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.DeliveryReceipt)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, namespace, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPermission provides a mock function with given fields: ctx, namespace, principal
+func (_m *Plugin) GetPermission(ctx context.Context, namespace string, principal string) (*core.NamespacedPermission, error) {
+	ret := _m.Called(ctx, namespace, principal)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPermission")
+	}
+
+	var r0 *core.NamespacedPermission
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*core.NamespacedPermission, error)); ok {
+		return rf(ctx, namespace, principal)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *core.NamespacedPermission); ok {
+		r0 = rf(ctx, namespace, principal)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.NamespacedPermission)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, namespace, principal)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPermissions provides a mock function with given fields: ctx, namespace, filter
+func (_m *Plugin) GetPermissions(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.NamespacedPermission, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPermissions")
+	}
+
+	var r0 []*core.NamespacedPermission
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) ([]*core.NamespacedPermission, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) []*core.NamespacedPermission); ok {
+		r0 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.NamespacedPermission)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetPins provides a mock function with given fields: ctx, namespace, filter
 func (_m *Plugin) GetPins(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.Pin, *ffapi.FilterResult, error) {
 	ret := _m.Called(ctx, namespace, filter)
@@ -2887,6 +3815,24 @@ func (_m *Plugin) GetVerifiers(ctx context.Context, namespace string, filter ffa
 	return r0, r1, r2
 }
 
+// ImportNamespace provides a mock function with given fields: ctx, namespace, r
+func (_m *Plugin) ImportNamespace(ctx context.Context, namespace string, r io.Reader) error {
+	ret := _m.Called(ctx, namespace, r)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportNamespace")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, io.Reader) error); ok {
+		r0 = rf(ctx, namespace, r)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Init provides a mock function with given fields: ctx, _a1
 func (_m *Plugin) Init(ctx context.Context, _a1 config.Section) error {
 	ret := _m.Called(ctx, _a1)
@@ -3007,6 +3953,42 @@ func (_m *Plugin) InsertDataArray(ctx context.Context, data core.DataArray) erro
 	return r0
 }
 
+// InsertDeadLetter provides a mock function with given fields: ctx, deadLetter
+func (_m *Plugin) InsertDeadLetter(ctx context.Context, deadLetter *core.DeadLetter) error {
+	ret := _m.Called(ctx, deadLetter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertDeadLetter")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.DeadLetter) error); ok {
+		r0 = rf(ctx, deadLetter)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertDeliveryReceipt provides a mock function with given fields: ctx, receipt
+func (_m *Plugin) InsertDeliveryReceipt(ctx context.Context, receipt *core.DeliveryReceipt) error {
+	ret := _m.Called(ctx, receipt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertDeliveryReceipt")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.DeliveryReceipt) error); ok {
+		r0 = rf(ctx, receipt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertEvent provides a mock function with given fields: ctx, data
 func (_m *Plugin) InsertEvent(ctx context.Context, data *core.Event) error {
 	ret := _m.Called(ctx, data)
@@ -3316,6 +4298,24 @@ func (_m *Plugin) InsertOrGetTokenTransfer(ctx context.Context, approval *core.T
 	return r0, r1
 }
 
+// InsertPendingDeliveryReceipt provides a mock function with given fields: ctx, receipt
+func (_m *Plugin) InsertPendingDeliveryReceipt(ctx context.Context, receipt *core.DeliveryReceipt) error {
+	ret := _m.Called(ctx, receipt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertPendingDeliveryReceipt")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.DeliveryReceipt) error); ok {
+		r0 = rf(ctx, receipt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertPins provides a mock function with given fields: ctx, pins
 func (_m *Plugin) InsertPins(ctx context.Context, pins []*core.Pin) error {
 	ret := _m.Called(ctx, pins)
@@ -3691,6 +4691,24 @@ func (_m *Plugin) UpdateTransaction(ctx context.Context, namespace string, id *f
 	return r0
 }
 
+// UpsertBlockchainReceipt provides a mock function with given fields: ctx, receipt
+func (_m *Plugin) UpsertBlockchainReceipt(ctx context.Context, receipt *core.BlockchainReceipt) error {
+	ret := _m.Called(ctx, receipt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertBlockchainReceipt")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.BlockchainReceipt) error); ok {
+		r0 = rf(ctx, receipt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpsertContractAPI provides a mock function with given fields: ctx, api, optimization
 func (_m *Plugin) UpsertContractAPI(ctx context.Context, api *core.ContractAPI, optimization database.UpsertOptimization) error {
 	ret := _m.Called(ctx, api, optimization)
@@ -3709,17 +4727,17 @@ func (_m *Plugin) UpsertContractAPI(ctx context.Context, api *core.ContractAPI,
 	return r0
 }
 
-// UpsertData provides a mock function with given fields: ctx, data, optimization
-func (_m *Plugin) UpsertData(ctx context.Context, data *core.Data, optimization database.UpsertOptimization) error {
-	ret := _m.Called(ctx, data, optimization)
+// UpsertData provides a mock function with given fields: ctx, data, optimization, allowExistingByHash
+func (_m *Plugin) UpsertData(ctx context.Context, data *core.Data, optimization database.UpsertOptimization, allowExistingByHash bool) error {
+	ret := _m.Called(ctx, data, optimization, allowExistingByHash)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpsertData")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *core.Data, database.UpsertOptimization) error); ok {
-		r0 = rf(ctx, data, optimization)
+	if rf, ok := ret.Get(0).(func(context.Context, *core.Data, database.UpsertOptimization, bool) error); ok {
+		r0 = rf(ctx, data, optimization, allowExistingByHash)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -3745,6 +4763,24 @@ func (_m *Plugin) UpsertDatatype(ctx context.Context, datadef *core.Datatype, al
 	return r0
 }
 
+// UpsertDeadEvent provides a mock function with given fields: ctx, deadEvent
+func (_m *Plugin) UpsertDeadEvent(ctx context.Context, deadEvent *core.DeadEvent) error {
+	ret := _m.Called(ctx, deadEvent)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertDeadEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.DeadEvent) error); ok {
+		r0 = rf(ctx, deadEvent)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpsertFFI provides a mock function with given fields: ctx, ffi, optimization
 func (_m *Plugin) UpsertFFI(ctx context.Context, ffi *fftypes.FFI, optimization database.UpsertOptimization) error {
 	ret := _m.Called(ctx, ffi, optimization)
@@ -3914,6 +4950,24 @@ func (_m *Plugin) UpsertOffset(ctx context.Context, data *core.Offset, allowExis
 	return r0
 }
 
+// UpsertPermission provides a mock function with given fields: ctx, permission
+func (_m *Plugin) UpsertPermission(ctx context.Context, permission *core.NamespacedPermission) error {
+	ret := _m.Called(ctx, permission)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertPermission")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.NamespacedPermission) error); ok {
+		r0 = rf(ctx, permission)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpsertPin provides a mock function with given fields: ctx, parked
 func (_m *Plugin) UpsertPin(ctx context.Context, parked *core.Pin) error {
 	ret := _m.Called(ctx, parked)
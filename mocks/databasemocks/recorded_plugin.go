@@ -0,0 +1,989 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databasemocks
+
+import (
+	"context"
+	"io"
+	"reflect"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// recordedPlugin is a lightweight test double for database.Plugin, that returns pre-recorded responses.
+type recordedPlugin struct {
+	responses map[string]interface{}
+}
+
+// NewRecordedPlugin returns a database.Plugin that answers each method with the value recorded against its
+// name in the responses map. The value is returned in the first result it can be assigned to, so an
+// error value is returned as the error result, and a slice or struct as the matching result.
+// Methods not in the map return zero values. RunAsGroup calls the supplied function, unless an
+// error is recorded for it.
+func NewRecordedPlugin(responses map[string]interface{}) database.Plugin {
+	return &recordedPlugin{responses: responses}
+}
+
+func (rp *recordedPlugin) respond(method string, results ...interface{}) {
+	v, ok := rp.responses[method]
+	if !ok || v == nil {
+		return
+	}
+	rv := reflect.ValueOf(v)
+	for _, r := range results {
+		target := reflect.ValueOf(r).Elem()
+		if rv.Type().AssignableTo(target.Type()) {
+			target.Set(rv)
+			return
+		}
+	}
+}
+
+func (rp *recordedPlugin) ArchiveEvents(ctx context.Context, namespace string, createdBefore *fftypes.FFTime, limit int) (r0 int64, r1 error) {
+	rp.respond("ArchiveEvents", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) Capabilities() (r0 *database.Capabilities) {
+	rp.respond("Capabilities", &r0)
+	return
+}
+
+func (rp *recordedPlugin) CloneNamespace(ctx context.Context, src string, dest string) (r0 error) {
+	rp.respond("CloneNamespace", &r0)
+	return
+}
+
+func (rp *recordedPlugin) CountBlobReferences(ctx context.Context, payloadRef string) (r0 int64, r1 error) {
+	rp.respond("CountBlobReferences", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) CountPinsInSequenceRange(ctx context.Context, after int64, upTo int64) (r0 int64, r1 error) {
+	rp.respond("CountPinsInSequenceRange", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) DeleteBlob(ctx context.Context, sequence int64) (r0 error) {
+	rp.respond("DeleteBlob", &r0)
+	return
+}
+
+func (rp *recordedPlugin) DeleteContractAPI(ctx context.Context, namespace string, id *fftypes.UUID) (r0 error) {
+	rp.respond("DeleteContractAPI", &r0)
+	return
+}
+
+func (rp *recordedPlugin) DeleteContractListenerByID(ctx context.Context, namespace string, id *fftypes.UUID) (r0 error) {
+	rp.respond("DeleteContractListenerByID", &r0)
+	return
+}
+
+func (rp *recordedPlugin) DeleteData(ctx context.Context, namespace string, id *fftypes.UUID) (r0 error) {
+	rp.respond("DeleteData", &r0)
+	return
+}
+
+func (rp *recordedPlugin) DeleteDeadEvent(ctx context.Context, namespace string, id *fftypes.UUID) (r0 error) {
+	rp.respond("DeleteDeadEvent", &r0)
+	return
+}
+
+func (rp *recordedPlugin) DeleteEvent(ctx context.Context, namespace string, id *fftypes.UUID) (r0 error) {
+	rp.respond("DeleteEvent", &r0)
+	return
+}
+
+func (rp *recordedPlugin) DeleteEventsBefore(ctx context.Context, namespace string, sequence int64) (r0 int64, r1 error) {
+	rp.respond("DeleteEventsBefore", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) DeleteFFI(ctx context.Context, namespace string, id *fftypes.UUID) (r0 error) {
+	rp.respond("DeleteFFI", &r0)
+	return
+}
+
+func (rp *recordedPlugin) DeleteMessage(ctx context.Context, namespace string, id *fftypes.UUID, states []core.MessageState) (r0 error) {
+	rp.respond("DeleteMessage", &r0)
+	return
+}
+
+func (rp *recordedPlugin) DeleteNonce(ctx context.Context, hash *fftypes.Bytes32) (r0 error) {
+	rp.respond("DeleteNonce", &r0)
+	return
+}
+
+func (rp *recordedPlugin) DeleteOffset(ctx context.Context, t fftypes.FFEnum, name string) (r0 error) {
+	rp.respond("DeleteOffset", &r0)
+	return
+}
+
+func (rp *recordedPlugin) DeletePendingDeliveryReceipt(ctx context.Context, namespace string, id *fftypes.UUID) (r0 error) {
+	rp.respond("DeletePendingDeliveryReceipt", &r0)
+	return
+}
+
+func (rp *recordedPlugin) DeletePermission(ctx context.Context, namespace string, principal string) (r0 error) {
+	rp.respond("DeletePermission", &r0)
+	return
+}
+
+func (rp *recordedPlugin) DeleteSubscriptionByID(ctx context.Context, namespace string, id *fftypes.UUID) (r0 error) {
+	rp.respond("DeleteSubscriptionByID", &r0)
+	return
+}
+
+func (rp *recordedPlugin) DeleteTokenApprovals(ctx context.Context, namespace string, poolID *fftypes.UUID) (r0 error) {
+	rp.respond("DeleteTokenApprovals", &r0)
+	return
+}
+
+func (rp *recordedPlugin) DeleteTokenBalances(ctx context.Context, namespace string, poolID *fftypes.UUID) (r0 error) {
+	rp.respond("DeleteTokenBalances", &r0)
+	return
+}
+
+func (rp *recordedPlugin) DeleteTokenPool(ctx context.Context, namespace string, id *fftypes.UUID) (r0 error) {
+	rp.respond("DeleteTokenPool", &r0)
+	return
+}
+
+func (rp *recordedPlugin) DeleteTokenTransfers(ctx context.Context, namespace string, poolID *fftypes.UUID) (r0 error) {
+	rp.respond("DeleteTokenTransfers", &r0)
+	return
+}
+
+func (rp *recordedPlugin) ExpireMessage(ctx context.Context, namespace string, id *fftypes.UUID) (r0 bool, r1 error) {
+	rp.respond("ExpireMessage", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) ExportNamespace(ctx context.Context, namespace string, w io.Writer) (r0 error) {
+	rp.respond("ExportNamespace", &r0)
+	return
+}
+
+func (rp *recordedPlugin) GetArchivedEvents(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.Event, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetArchivedEvents", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetArchivedEventsInNamespaces(ctx context.Context, namespaces []string, filter ffapi.Filter) (r0 []*core.Event, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetArchivedEventsInNamespaces", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetBatchByID(ctx context.Context, namespace string, id *fftypes.UUID) (r0 *core.BatchPersisted, r1 error) {
+	rp.respond("GetBatchByID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetBatchIDsForDataAttachments(ctx context.Context, namespace string, dataIDs []*fftypes.UUID) (r0 []*fftypes.UUID, r1 error) {
+	rp.respond("GetBatchIDsForDataAttachments", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetBatchIDsForMessages(ctx context.Context, namespace string, msgIDs []*fftypes.UUID) (r0 []*fftypes.UUID, r1 error) {
+	rp.respond("GetBatchIDsForMessages", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetBatches(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.BatchPersisted, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetBatches", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetBlobs(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.Blob, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetBlobs", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetBlockchainEventByID(ctx context.Context, namespace string, id *fftypes.UUID) (r0 *core.BlockchainEvent, r1 error) {
+	rp.respond("GetBlockchainEventByID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetBlockchainEventByProtocolID(ctx context.Context, namespace string, listener *fftypes.UUID, protocolID string) (r0 *core.BlockchainEvent, r1 error) {
+	rp.respond("GetBlockchainEventByProtocolID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetBlockchainEvents(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.BlockchainEvent, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetBlockchainEvents", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetBlockchainReceiptByOpID(ctx context.Context, namespace string, opID *fftypes.UUID) (r0 *core.BlockchainReceipt, r1 error) {
+	rp.respond("GetBlockchainReceiptByOpID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetBlockchainReceiptsForMessages(ctx context.Context, namespace string, msgIDs []*fftypes.UUID) (r0 []*core.BlockchainReceipt, r1 error) {
+	rp.respond("GetBlockchainReceiptsForMessages", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetBlockedContexts(ctx context.Context, namespace string, upToSequence int64, filter ffapi.Filter) (r0 []*core.Pin, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetBlockedContexts", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetBlockedStats(ctx context.Context, namespace string, upToSequence int64, cutoffs []*fftypes.FFTime) (r0 *core.BlockedContextStats, r1 error) {
+	rp.respond("GetBlockedStats", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetChartHistogram(ctx context.Context, namespace string, intervals []core.ChartHistogramInterval, collection database.CollectionName) (r0 []*core.ChartHistogram, r1 error) {
+	rp.respond("GetChartHistogram", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetContractAPIByID(ctx context.Context, namespace string, id *fftypes.UUID) (r0 *core.ContractAPI, r1 error) {
+	rp.respond("GetContractAPIByID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetContractAPIByName(ctx context.Context, namespace string, name string) (r0 *core.ContractAPI, r1 error) {
+	rp.respond("GetContractAPIByName", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetContractAPIByNetworkName(ctx context.Context, namespace string, networkName string) (r0 *core.ContractAPI, r1 error) {
+	rp.respond("GetContractAPIByNetworkName", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetContractAPIs(ctx context.Context, namespace string, filter ffapi.AndFilter) (r0 []*core.ContractAPI, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetContractAPIs", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetContractListener(ctx context.Context, namespace string, name string) (r0 *core.ContractListener, r1 error) {
+	rp.respond("GetContractListener", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetContractListenerByBackendID(ctx context.Context, namespace string, id string) (r0 *core.ContractListener, r1 error) {
+	rp.respond("GetContractListenerByBackendID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetContractListenerByID(ctx context.Context, namespace string, id *fftypes.UUID) (r0 *core.ContractListener, r1 error) {
+	rp.respond("GetContractListenerByID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetContractListeners(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.ContractListener, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetContractListeners", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetData(ctx context.Context, namespace string, filter ffapi.Filter) (r0 core.DataArray, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetData", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetDataByID(ctx context.Context, namespace string, id *fftypes.UUID, withValue bool) (r0 *core.Data, r1 error) {
+	rp.respond("GetDataByID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetDataRefs(ctx context.Context, namespace string, filter ffapi.Filter) (r0 core.DataRefs, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetDataRefs", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetDataSubPaths(ctx context.Context, namespace string, path string) (r0 []string, r1 error) {
+	rp.respond("GetDataSubPaths", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetDataWithBlob(ctx context.Context, namespace string, id *fftypes.UUID, withValue bool) (r0 *core.Data, r1 *core.Blob, r2 error) {
+	rp.respond("GetDataWithBlob", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetDatatypeByID(ctx context.Context, namespace string, id *fftypes.UUID) (r0 *core.Datatype, r1 error) {
+	rp.respond("GetDatatypeByID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetDatatypeByName(ctx context.Context, namespace string, name string, version string) (r0 *core.Datatype, r1 error) {
+	rp.respond("GetDatatypeByName", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetDatatypes(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.Datatype, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetDatatypes", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetDeadEventByID(ctx context.Context, namespace string, id *fftypes.UUID) (r0 *core.DeadEvent, r1 error) {
+	rp.respond("GetDeadEventByID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetDeadEvents(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.DeadEvent, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetDeadEvents", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetDeadLetters(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.DeadLetter, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetDeadLetters", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetDeliveryReceipts(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.DeliveryReceipt, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetDeliveryReceipts", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetDescendantNamespaces(ctx context.Context, root string) (r0 []*core.Namespace, r1 error) {
+	rp.respond("GetDescendantNamespaces", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetEventByID(ctx context.Context, namespace string, id *fftypes.UUID) (r0 *core.Event, r1 error) {
+	rp.respond("GetEventByID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetEvents(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.Event, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetEvents", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetEventsInNamespaces(ctx context.Context, namespaces []string, filter ffapi.Filter) (r0 []*core.Event, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetEventsInNamespaces", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetEventsInSequenceRange(ctx context.Context, namespace string, filter ffapi.Filter, startSequence int, endSequence int) (r0 []*core.Event, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetEventsInSequenceRange", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetFFI(ctx context.Context, namespace string, name string, version string) (r0 *fftypes.FFI, r1 error) {
+	rp.respond("GetFFI", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetFFIByID(ctx context.Context, namespace string, id *fftypes.UUID) (r0 *fftypes.FFI, r1 error) {
+	rp.respond("GetFFIByID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetFFIByNetworkName(ctx context.Context, namespace string, networkName string, version string) (r0 *fftypes.FFI, r1 error) {
+	rp.respond("GetFFIByNetworkName", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetFFIErrors(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*fftypes.FFIError, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetFFIErrors", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetFFIEvent(ctx context.Context, namespace string, interfaceID *fftypes.UUID, pathName string) (r0 *fftypes.FFIEvent, r1 error) {
+	rp.respond("GetFFIEvent", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetFFIEvents(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*fftypes.FFIEvent, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetFFIEvents", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetFFIMethod(ctx context.Context, namespace string, interfaceID *fftypes.UUID, pathName string) (r0 *fftypes.FFIMethod, r1 error) {
+	rp.respond("GetFFIMethod", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetFFIMethods(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*fftypes.FFIMethod, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetFFIMethods", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetFFIs(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*fftypes.FFI, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetFFIs", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetGroupByHash(ctx context.Context, namespace string, hash *fftypes.Bytes32) (r0 *core.Group, r1 error) {
+	rp.respond("GetGroupByHash", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetGroups(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.Group, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetGroups", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetIdentities(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.Identity, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetIdentities", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetIdentityByDID(ctx context.Context, namespace string, did string) (r0 *core.Identity, r1 error) {
+	rp.respond("GetIdentityByDID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetIdentityByID(ctx context.Context, namespace string, id *fftypes.UUID) (r0 *core.Identity, r1 error) {
+	rp.respond("GetIdentityByID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetIdentityByName(ctx context.Context, iType fftypes.FFEnum, namespace string, name string) (r0 *core.Identity, r1 error) {
+	rp.respond("GetIdentityByName", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetMessageByID(ctx context.Context, namespace string, id *fftypes.UUID) (r0 *core.Message, r1 error) {
+	rp.respond("GetMessageByID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetMessageIDs(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.IDAndSequence, r1 error) {
+	rp.respond("GetMessageIDs", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetMessageRef(ctx context.Context, namespace string, id *fftypes.UUID) (r0 *core.IDAndSequence, r1 error) {
+	rp.respond("GetMessageRef", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetMessages(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.Message, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetMessages", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetMessagesForData(ctx context.Context, namespace string, dataID *fftypes.UUID, filter ffapi.Filter) (r0 []*core.Message, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetMessagesForData", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetMessagesForTag(ctx context.Context, namespace string, tag string, filter ffapi.Filter) (r0 []*core.Message, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetMessagesForTag", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetMessagesPastTTL(ctx context.Context, namespace string, now *fftypes.FFTime, limit int) (r0 []*core.Message, r1 error) {
+	rp.respond("GetMessagesPastTTL", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetNamespace(ctx context.Context, name string) (r0 *core.Namespace, r1 error) {
+	rp.respond("GetNamespace", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetNextPins(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.NextPin, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetNextPins", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetNextPinsForContext(ctx context.Context, namespace string, hash *fftypes.Bytes32) (r0 []*core.NextPin, r1 error) {
+	rp.respond("GetNextPinsForContext", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetNonce(ctx context.Context, hash *fftypes.Bytes32) (r0 *core.Nonce, r1 error) {
+	rp.respond("GetNonce", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetNonces(ctx context.Context, filter ffapi.Filter) (r0 []*core.Nonce, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetNonces", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetOffset(ctx context.Context, t fftypes.FFEnum, name string) (r0 *core.Offset, r1 error) {
+	rp.respond("GetOffset", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetOffsets(ctx context.Context, filter ffapi.Filter) (r0 []*core.Offset, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetOffsets", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetOperationByID(ctx context.Context, namespace string, id *fftypes.UUID) (r0 *core.Operation, r1 error) {
+	rp.respond("GetOperationByID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetOperations(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.Operation, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetOperations", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetPendingDeliveryReceipts(ctx context.Context, namespace string, limit int) (r0 []*core.DeliveryReceipt, r1 error) {
+	rp.respond("GetPendingDeliveryReceipts", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetPermission(ctx context.Context, namespace string, principal string) (r0 *core.NamespacedPermission, r1 error) {
+	rp.respond("GetPermission", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetPermissions(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.NamespacedPermission, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetPermissions", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetPins(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.Pin, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetPins", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetSubscriptionByID(ctx context.Context, namespace string, id *fftypes.UUID) (r0 *core.Subscription, r1 error) {
+	rp.respond("GetSubscriptionByID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetSubscriptionByName(ctx context.Context, namespace string, name string) (r0 *core.Subscription, r1 error) {
+	rp.respond("GetSubscriptionByName", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetSubscriptions(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.Subscription, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetSubscriptions", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetTokenAccountPools(ctx context.Context, namespace string, key string, filter ffapi.Filter) (r0 []*core.TokenAccountPool, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetTokenAccountPools", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetTokenAccounts(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.TokenAccount, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetTokenAccounts", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetTokenApprovalByID(ctx context.Context, namespace string, localID *fftypes.UUID) (r0 *core.TokenApproval, r1 error) {
+	rp.respond("GetTokenApprovalByID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetTokenApprovalByProtocolID(ctx context.Context, namespace string, poolID *fftypes.UUID, protocolID string) (r0 *core.TokenApproval, r1 error) {
+	rp.respond("GetTokenApprovalByProtocolID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetTokenApprovals(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.TokenApproval, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetTokenApprovals", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetTokenBalance(ctx context.Context, namespace string, poolID *fftypes.UUID, tokenIndex string, identity string) (r0 *core.TokenBalance, r1 error) {
+	rp.respond("GetTokenBalance", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetTokenBalances(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.TokenBalance, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetTokenBalances", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetTokenPool(ctx context.Context, namespace string, name string) (r0 *core.TokenPool, r1 error) {
+	rp.respond("GetTokenPool", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetTokenPoolByID(ctx context.Context, namespace string, id *fftypes.UUID) (r0 *core.TokenPool, r1 error) {
+	rp.respond("GetTokenPoolByID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetTokenPoolByNetworkName(ctx context.Context, namespace string, networkName string) (r0 *core.TokenPool, r1 error) {
+	rp.respond("GetTokenPoolByNetworkName", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetTokenPools(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.TokenPool, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetTokenPools", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetTokenTransferByID(ctx context.Context, namespace string, localID *fftypes.UUID) (r0 *core.TokenTransfer, r1 error) {
+	rp.respond("GetTokenTransferByID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetTokenTransferByProtocolID(ctx context.Context, namespace string, poolID *fftypes.UUID, protocolID string) (r0 *core.TokenTransfer, r1 error) {
+	rp.respond("GetTokenTransferByProtocolID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetTokenTransfers(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.TokenTransfer, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetTokenTransfers", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetTransactionByID(ctx context.Context, namespace string, id *fftypes.UUID) (r0 *core.Transaction, r1 error) {
+	rp.respond("GetTransactionByID", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetTransactions(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.Transaction, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetTransactions", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) GetVerifierByHash(ctx context.Context, namespace string, hash *fftypes.Bytes32) (r0 *core.Verifier, r1 error) {
+	rp.respond("GetVerifierByHash", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetVerifierByValue(ctx context.Context, vType fftypes.FFEnum, namespace string, value string) (r0 *core.Verifier, r1 error) {
+	rp.respond("GetVerifierByValue", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) GetVerifiers(ctx context.Context, namespace string, filter ffapi.Filter) (r0 []*core.Verifier, r1 *ffapi.FilterResult, r2 error) {
+	rp.respond("GetVerifiers", &r0, &r1, &r2)
+	return
+}
+
+func (rp *recordedPlugin) ImportNamespace(ctx context.Context, namespace string, r io.Reader) (r0 error) {
+	rp.respond("ImportNamespace", &r0)
+	return
+}
+
+func (rp *recordedPlugin) Init(ctx context.Context, conf config.Section) (r0 error) {
+	rp.respond("Init", &r0)
+	return
+}
+
+func (rp *recordedPlugin) InitConfig(conf config.Section) {
+	rp.respond("InitConfig")
+}
+
+func (rp *recordedPlugin) InsertBlob(ctx context.Context, blob *core.Blob) (r0 error) {
+	rp.respond("InsertBlob", &r0)
+	return
+}
+
+func (rp *recordedPlugin) InsertBlobs(ctx context.Context, blobs []*core.Blob) (r0 error) {
+	rp.respond("InsertBlobs", &r0)
+	return
+}
+
+func (rp *recordedPlugin) InsertBlockchainEvents(ctx context.Context, messages []*core.BlockchainEvent, hooks ...database.PostCompletionHook) (r0 error) {
+	rp.respond("InsertBlockchainEvents", &r0)
+	return
+}
+
+func (rp *recordedPlugin) InsertContractListener(ctx context.Context, sub *core.ContractListener) (r0 error) {
+	rp.respond("InsertContractListener", &r0)
+	return
+}
+
+func (rp *recordedPlugin) InsertDataArray(ctx context.Context, data core.DataArray) (r0 error) {
+	rp.respond("InsertDataArray", &r0)
+	return
+}
+
+func (rp *recordedPlugin) InsertDeadLetter(ctx context.Context, deadLetter *core.DeadLetter) (r0 error) {
+	rp.respond("InsertDeadLetter", &r0)
+	return
+}
+
+func (rp *recordedPlugin) InsertDeliveryReceipt(ctx context.Context, receipt *core.DeliveryReceipt) (r0 error) {
+	rp.respond("InsertDeliveryReceipt", &r0)
+	return
+}
+
+func (rp *recordedPlugin) InsertEvent(ctx context.Context, data *core.Event) (r0 error) {
+	rp.respond("InsertEvent", &r0)
+	return
+}
+
+func (rp *recordedPlugin) InsertMessages(ctx context.Context, messages []*core.Message, hooks ...database.PostCompletionHook) (r0 error) {
+	rp.respond("InsertMessages", &r0)
+	return
+}
+
+func (rp *recordedPlugin) InsertNextPin(ctx context.Context, nextpin *core.NextPin) (r0 error) {
+	rp.respond("InsertNextPin", &r0)
+	return
+}
+
+func (rp *recordedPlugin) InsertNonce(ctx context.Context, nonce *core.Nonce) (r0 error) {
+	rp.respond("InsertNonce", &r0)
+	return
+}
+
+func (rp *recordedPlugin) InsertOperation(ctx context.Context, operation *core.Operation, hooks ...database.PostCompletionHook) (r0 error) {
+	rp.respond("InsertOperation", &r0)
+	return
+}
+
+func (rp *recordedPlugin) InsertOperations(ctx context.Context, ops []*core.Operation, hooks ...database.PostCompletionHook) (r0 error) {
+	rp.respond("InsertOperations", &r0)
+	return
+}
+
+func (rp *recordedPlugin) InsertOrGetBatch(ctx context.Context, data *core.BatchPersisted) (r0 *core.BatchPersisted, r1 error) {
+	rp.respond("InsertOrGetBatch", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) InsertOrGetBlockchainEvent(ctx context.Context, event *core.BlockchainEvent) (r0 *core.BlockchainEvent, r1 error) {
+	rp.respond("InsertOrGetBlockchainEvent", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) InsertOrGetContractAPI(ctx context.Context, api *core.ContractAPI) (r0 *core.ContractAPI, r1 error) {
+	rp.respond("InsertOrGetContractAPI", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) InsertOrGetFFI(ctx context.Context, ffi *fftypes.FFI) (r0 *fftypes.FFI, r1 error) {
+	rp.respond("InsertOrGetFFI", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) InsertOrGetTokenPool(ctx context.Context, pool *core.TokenPool) (r0 *core.TokenPool, r1 error) {
+	rp.respond("InsertOrGetTokenPool", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) InsertOrGetTokenTransfer(ctx context.Context, approval *core.TokenTransfer) (r0 *core.TokenTransfer, r1 error) {
+	rp.respond("InsertOrGetTokenTransfer", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) InsertPendingDeliveryReceipt(ctx context.Context, receipt *core.DeliveryReceipt) (r0 error) {
+	rp.respond("InsertPendingDeliveryReceipt", &r0)
+	return
+}
+
+func (rp *recordedPlugin) InsertPins(ctx context.Context, pins []*core.Pin) (r0 error) {
+	rp.respond("InsertPins", &r0)
+	return
+}
+
+func (rp *recordedPlugin) InsertTransaction(ctx context.Context, txn *core.Transaction) (r0 error) {
+	rp.respond("InsertTransaction", &r0)
+	return
+}
+
+func (rp *recordedPlugin) InsertTransactions(ctx context.Context, txns []*core.Transaction) (r0 error) {
+	rp.respond("InsertTransactions", &r0)
+	return
+}
+
+func (rp *recordedPlugin) Name() (r0 string) {
+	rp.respond("Name", &r0)
+	return
+}
+
+func (rp *recordedPlugin) ReplaceMessage(ctx context.Context, message *core.Message) (r0 error) {
+	rp.respond("ReplaceMessage", &r0)
+	return
+}
+
+func (rp *recordedPlugin) RunAsGroup(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	rp.respond("RunAsGroup", &err)
+	if err != nil {
+		return err
+	}
+	return fn(ctx)
+}
+
+func (rp *recordedPlugin) SetHandler(namespace string, handler database.Callbacks) {
+	rp.respond("SetHandler")
+}
+
+func (rp *recordedPlugin) UpdateBatch(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) (r0 error) {
+	rp.respond("UpdateBatch", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpdateContractListener(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) (r0 error) {
+	rp.respond("UpdateContractListener", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpdateData(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) (r0 error) {
+	rp.respond("UpdateData", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpdateMessage(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) (r0 error) {
+	rp.respond("UpdateMessage", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpdateMessages(ctx context.Context, namespace string, filter ffapi.Filter, update ffapi.Update) (r0 error) {
+	rp.respond("UpdateMessages", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpdateNextPin(ctx context.Context, namespace string, sequence int64, update ffapi.Update) (r0 error) {
+	rp.respond("UpdateNextPin", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpdateNonce(ctx context.Context, nonce *core.Nonce) (r0 error) {
+	rp.respond("UpdateNonce", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpdateOffset(ctx context.Context, rowID int64, update ffapi.Update) (r0 error) {
+	rp.respond("UpdateOffset", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpdateOperation(ctx context.Context, namespace string, id *fftypes.UUID, filter ffapi.Filter, update ffapi.Update) (r0 bool, r1 error) {
+	rp.respond("UpdateOperation", &r0, &r1)
+	return
+}
+
+func (rp *recordedPlugin) UpdatePins(ctx context.Context, namespace string, filter ffapi.Filter, update ffapi.Update) (r0 error) {
+	rp.respond("UpdatePins", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpdateSubscription(ctx context.Context, namespace string, name string, update ffapi.Update) (r0 error) {
+	rp.respond("UpdateSubscription", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpdateTokenApprovals(ctx context.Context, filter ffapi.Filter, update ffapi.Update) (r0 error) {
+	rp.respond("UpdateTokenApprovals", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpdateTokenBalances(ctx context.Context, transfer *core.TokenTransfer) (r0 error) {
+	rp.respond("UpdateTokenBalances", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpdateTransaction(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) (r0 error) {
+	rp.respond("UpdateTransaction", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertBlockchainReceipt(ctx context.Context, receipt *core.BlockchainReceipt) (r0 error) {
+	rp.respond("UpsertBlockchainReceipt", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertContractAPI(ctx context.Context, api *core.ContractAPI, optimization database.UpsertOptimization) (r0 error) {
+	rp.respond("UpsertContractAPI", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertData(ctx context.Context, data *core.Data, optimization database.UpsertOptimization, allowExistingByHash bool) (r0 error) {
+	rp.respond("UpsertData", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertDatatype(ctx context.Context, datadef *core.Datatype, allowExisting bool) (r0 error) {
+	rp.respond("UpsertDatatype", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertDeadEvent(ctx context.Context, deadEvent *core.DeadEvent) (r0 error) {
+	rp.respond("UpsertDeadEvent", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertFFI(ctx context.Context, ffi *fftypes.FFI, optimization database.UpsertOptimization) (r0 error) {
+	rp.respond("UpsertFFI", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertFFIError(ctx context.Context, method *fftypes.FFIError) (r0 error) {
+	rp.respond("UpsertFFIError", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertFFIEvent(ctx context.Context, method *fftypes.FFIEvent) (r0 error) {
+	rp.respond("UpsertFFIEvent", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertFFIMethod(ctx context.Context, method *fftypes.FFIMethod) (r0 error) {
+	rp.respond("UpsertFFIMethod", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertGroup(ctx context.Context, data *core.Group, optimization database.UpsertOptimization) (r0 error) {
+	rp.respond("UpsertGroup", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertIdentity(ctx context.Context, data *core.Identity, optimization database.UpsertOptimization) (r0 error) {
+	rp.respond("UpsertIdentity", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertMessage(ctx context.Context, message *core.Message, optimization database.UpsertOptimization, hooks ...database.PostCompletionHook) (r0 error) {
+	rp.respond("UpsertMessage", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertNamespace(ctx context.Context, data *core.Namespace, allowExisting bool) (r0 error) {
+	rp.respond("UpsertNamespace", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertOffset(ctx context.Context, data *core.Offset, allowExisting bool) (r0 error) {
+	rp.respond("UpsertOffset", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertPermission(ctx context.Context, permission *core.NamespacedPermission) (r0 error) {
+	rp.respond("UpsertPermission", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertPin(ctx context.Context, parked *core.Pin) (r0 error) {
+	rp.respond("UpsertPin", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertSubscription(ctx context.Context, data *core.Subscription, allowExisting bool) (r0 error) {
+	rp.respond("UpsertSubscription", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertTokenApproval(ctx context.Context, approval *core.TokenApproval) (r0 error) {
+	rp.respond("UpsertTokenApproval", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertTokenPool(ctx context.Context, pool *core.TokenPool, optimization database.UpsertOptimization) (r0 error) {
+	rp.respond("UpsertTokenPool", &r0)
+	return
+}
+
+func (rp *recordedPlugin) UpsertVerifier(ctx context.Context, data *core.Verifier, optimization database.UpsertOptimization) (r0 error) {
+	rp.respond("UpsertVerifier", &r0)
+	return
+}
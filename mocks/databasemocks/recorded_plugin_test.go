@@ -0,0 +1,113 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databasemocks
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordedPluginResponses(t *testing.T) {
+	events := []*core.Event{{Sequence: 12345}}
+	rp := NewRecordedPlugin(map[string]interface{}{
+		"Name":             "recorded",
+		"GetEvents":        events,
+		"GetEventByID":     fmt.Errorf("pop"),
+		"GetMessageByID":   nil,
+		"UpsertOffset":     fmt.Errorf("pop"),
+		"GetSubscriptions": &ffapi.FilterResult{},
+	})
+	ctx := context.Background()
+
+	assert.Equal(t, "recorded", rp.Name())
+
+	res, fr, err := rp.GetEvents(ctx, "ns1", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, fr)
+	assert.Equal(t, events, res)
+
+	ev, err := rp.GetEventByID(ctx, "ns1", nil)
+	assert.EqualError(t, err, "pop")
+	assert.Nil(t, ev)
+
+	msg, err := rp.GetMessageByID(ctx, "ns1", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, msg)
+
+	err = rp.UpsertOffset(ctx, &core.Offset{}, false)
+	assert.EqualError(t, err, "pop")
+
+	subs, fr, err := rp.GetSubscriptions(ctx, "ns1", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, subs)
+	assert.NotNil(t, fr)
+
+	// Not in the map
+	ops, _, err := rp.GetOperations(ctx, "ns1", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, ops)
+	rp.SetHandler("ns1", nil)
+}
+
+func TestRecordedPluginRunAsGroup(t *testing.T) {
+	ctx := context.Background()
+	called := false
+	err := NewRecordedPlugin(nil).RunAsGroup(ctx, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, called)
+
+	called = false
+	err = NewRecordedPlugin(map[string]interface{}{
+		"RunAsGroup": fmt.Errorf("pop"),
+	}).RunAsGroup(ctx, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	assert.EqualError(t, err, "pop")
+	assert.False(t, called)
+}
+
+func TestRecordedPluginAllMethodsZeroValues(t *testing.T) {
+	rp := reflect.ValueOf(NewRecordedPlugin(map[string]interface{}{
+		"RunAsGroup": fmt.Errorf("pop"),
+	}))
+	for i := 0; i < rp.NumMethod(); i++ {
+		m := rp.Method(i)
+		args := make([]reflect.Value, m.Type().NumIn())
+		for j := range args {
+			args[j] = reflect.Zero(m.Type().In(j))
+		}
+		call := m.Call
+		if m.Type().IsVariadic() {
+			call = m.CallSlice
+		}
+		for _, r := range call(args) {
+			if r.Type().String() != "error" {
+				assert.True(t, r.IsZero(), rp.Type().Method(i).Name)
+			}
+		}
+	}
+}
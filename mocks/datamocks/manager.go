@@ -40,6 +40,34 @@ func (_m *Manager) BlobsEnabled() bool {
 	return r0
 }
 
+// CheckDataAvailable provides a mock function with given fields: ctx, _a1
+func (_m *Manager) CheckDataAvailable(ctx context.Context, _a1 core.DataArray) (bool, error) {
+	ret := _m.Called(ctx, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckDataAvailable")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, core.DataArray) (bool, error)); ok {
+		return rf(ctx, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, core.DataArray) bool); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, core.DataArray) error); ok {
+		r1 = rf(ctx, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CheckDatatype provides a mock function with given fields: ctx, datatype
 func (_m *Manager) CheckDatatype(ctx context.Context, datatype *core.Datatype) error {
 	ret := _m.Called(ctx, datatype)
@@ -58,6 +86,36 @@ func (_m *Manager) CheckDatatype(ctx context.Context, datatype *core.Datatype) e
 	return r0
 }
 
+// CopyData provides a mock function with given fields: ctx, sourceID, destNamespace
+func (_m *Manager) CopyData(ctx context.Context, sourceID string, destNamespace string) (*core.Data, error) {
+	ret := _m.Called(ctx, sourceID, destNamespace)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CopyData")
+	}
+
+	var r0 *core.Data
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*core.Data, error)); ok {
+		return rf(ctx, sourceID, destNamespace)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *core.Data); ok {
+		r0 = rf(ctx, sourceID, destNamespace)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Data)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, sourceID, destNamespace)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteData provides a mock function with given fields: ctx, dataID
 func (_m *Manager) DeleteData(ctx context.Context, dataID string) error {
 	ret := _m.Called(ctx, dataID)
@@ -76,6 +134,24 @@ func (_m *Manager) DeleteData(ctx context.Context, dataID string) error {
 	return r0
 }
 
+// DeleteMessage provides a mock function with given fields: ctx, msgID
+func (_m *Manager) DeleteMessage(ctx context.Context, msgID string) error {
+	ret := _m.Called(ctx, msgID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, msgID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DownloadBlob provides a mock function with given fields: ctx, dataID
 func (_m *Manager) DownloadBlob(ctx context.Context, dataID string) (*core.Blob, io.ReadCloser, error) {
 	ret := _m.Called(ctx, dataID)
@@ -299,6 +375,11 @@ func (_m *Manager) ResolveInlineData(ctx context.Context, msg *data.NewMessage)
 	return r0
 }
 
+// SetExternalDataCallbacks provides a mock function with given fields: callbacks
+func (_m *Manager) SetExternalDataCallbacks(callbacks data.ExternalDataCallbacks) {
+	_m.Called(callbacks)
+}
+
 // Start provides a mock function with given fields:
 func (_m *Manager) Start() {
 	_m.Called()
@@ -379,6 +460,24 @@ func (_m *Manager) UploadJSON(ctx context.Context, inData *core.DataRefOrValue)
 	return r0, r1
 }
 
+// UpsertNewDataByHash provides a mock function with given fields: ctx, newMsg
+func (_m *Manager) UpsertNewDataByHash(ctx context.Context, newMsg *data.NewMessage) error {
+	ret := _m.Called(ctx, newMsg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertNewDataByHash")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *data.NewMessage) error); ok {
+		r0 = rf(ctx, newMsg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ValidateAll provides a mock function with given fields: ctx, _a1
 func (_m *Manager) ValidateAll(ctx context.Context, _a1 core.DataArray) (bool, error) {
 	ret := _m.Called(ctx, _a1)
@@ -215,6 +215,24 @@ func (_m *Sender) PublishTokenPool(ctx context.Context, poolNameOrID string, net
 	return r0, r1
 }
 
+// SendDeliveryReceipt provides a mock function with given fields: ctx, receipt
+func (_m *Sender) SendDeliveryReceipt(ctx context.Context, receipt *core.DeliveryReceipt) error {
+	ret := _m.Called(ctx, receipt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendDeliveryReceipt")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.DeliveryReceipt) error); ok {
+		r0 = rf(ctx, receipt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateIdentity provides a mock function with given fields: ctx, identity, def, signingIdentity, waitConfirm
 func (_m *Sender) UpdateIdentity(ctx context.Context, identity *core.Identity, def *core.IdentityUpdate, signingIdentity *core.SignerRef, waitConfirm bool) error {
 	ret := _m.Called(ctx, identity, def, signingIdentity, waitConfirm)
@@ -11,6 +11,8 @@ import (
 
 	dataexchange "github.com/hyperledger/firefly/pkg/dataexchange"
 
+	ffapi "github.com/hyperledger/firefly-common/pkg/ffapi"
+
 	fftypes "github.com/hyperledger/firefly-common/pkg/fftypes"
 
 	mock "github.com/stretchr/testify/mock"
@@ -229,6 +231,123 @@ func (_m *EventManager) FilterHistoricalEventsOnSubscription(ctx context.Context
 	return r0, r1
 }
 
+// ForceUnblockContext provides a mock function with given fields: ctx, contextHash, principal
+func (_m *EventManager) ForceUnblockContext(ctx context.Context, contextHash *fftypes.Bytes32, principal string) error {
+	ret := _m.Called(ctx, contextHash, principal)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ForceUnblockContext")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Bytes32, string) error); ok {
+		r0 = rf(ctx, contextHash, principal)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetBlockedContextStats provides a mock function with given fields: ctx
+func (_m *EventManager) GetBlockedContextStats(ctx context.Context) (*core.BlockedContextStats, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBlockedContextStats")
+	}
+
+	var r0 *core.BlockedContextStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*core.BlockedContextStats, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *core.BlockedContextStats); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.BlockedContextStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBlockedContexts provides a mock function with given fields: ctx, filter
+func (_m *EventManager) GetBlockedContexts(ctx context.Context, filter ffapi.AndFilter) ([]*core.BlockedContext, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBlockedContexts")
+	}
+
+	var r0 []*core.BlockedContext
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) ([]*core.BlockedContext, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) []*core.BlockedContext); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.BlockedContext)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetPinReplay provides a mock function with given fields: ctx, id
+func (_m *EventManager) GetPinReplay(ctx context.Context, id *fftypes.UUID) (*core.PinReplay, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPinReplay")
+	}
+
+	var r0 *core.PinReplay
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) (*core.PinReplay, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *core.PinReplay); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.PinReplay)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPlugins provides a mock function with given fields:
 func (_m *EventManager) GetPlugins() []*core.NamespaceStatusPlugin {
 	ret := _m.Called()
@@ -249,20 +368,50 @@ func (_m *EventManager) GetPlugins() []*core.NamespaceStatusPlugin {
 	return r0
 }
 
+// ListSubscriptions provides a mock function with given fields: ctx, filter
+func (_m *EventManager) ListSubscriptions(ctx context.Context, filter *core.SubscriptionListFilter) ([]*core.SubscriptionWithStatus, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSubscriptions")
+	}
+
+	var r0 []*core.SubscriptionWithStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.SubscriptionListFilter) ([]*core.SubscriptionWithStatus, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.SubscriptionListFilter) []*core.SubscriptionWithStatus); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.SubscriptionWithStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.SubscriptionListFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewEvents provides a mock function with given fields:
-func (_m *EventManager) NewEvents() chan<- int64 {
+func (_m *EventManager) NewEvents() chan<- *core.Event {
 	ret := _m.Called()
 
 	if len(ret) == 0 {
 		panic("no return value specified for NewEvents")
 	}
 
-	var r0 chan<- int64
-	if rf, ok := ret.Get(0).(func() chan<- int64); ok {
+	var r0 chan<- *core.Event
+	if rf, ok := ret.Get(0).(func() chan<- *core.Event); ok {
 		r0 = rf()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(chan<- int64)
+			r0 = ret.Get(0).(chan<- *core.Event)
 		}
 	}
 
@@ -309,6 +458,24 @@ func (_m *EventManager) NewSubscriptions() chan<- *fftypes.UUID {
 	return r0
 }
 
+// PauseAggregator provides a mock function with given fields: ctx
+func (_m *EventManager) PauseAggregator(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PauseAggregator")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // QueueBatchRewind provides a mock function with given fields: batchID
 func (_m *EventManager) QueueBatchRewind(batchID *fftypes.UUID) {
 	_m.Called(batchID)
@@ -351,6 +518,24 @@ func (_m *EventManager) ResolveTransportAndCapabilities(ctx context.Context, tra
 	return r0, r1, r2
 }
 
+// ResumeAggregator provides a mock function with given fields: ctx
+func (_m *EventManager) ResumeAggregator(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResumeAggregator")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SharedStorageBatchDownloaded provides a mock function with given fields: ss, payloadRef, data
 func (_m *EventManager) SharedStorageBatchDownloaded(ss sharedstorage.Plugin, payloadRef string, data []byte) (*fftypes.UUID, error) {
 	ret := _m.Called(ss, payloadRef, data)
@@ -417,6 +602,36 @@ func (_m *EventManager) Start() error {
 	return r0
 }
 
+// StartPinReplay provides a mock function with given fields: ctx, input
+func (_m *EventManager) StartPinReplay(ctx context.Context, input *core.PinReplayInput) (*core.PinReplay, error) {
+	ret := _m.Called(ctx, input)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartPinReplay")
+	}
+
+	var r0 *core.PinReplay
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.PinReplayInput) (*core.PinReplay, error)); ok {
+		return rf(ctx, input)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.PinReplayInput) *core.PinReplay); ok {
+		r0 = rf(ctx, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.PinReplay)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.PinReplayInput) error); ok {
+		r1 = rf(ctx, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SubscriptionUpdates provides a mock function with given fields:
 func (_m *EventManager) SubscriptionUpdates() chan<- *fftypes.UUID {
 	ret := _m.Called()
@@ -241,6 +241,24 @@ func (_m *Manager) GetRootOrgDID(ctx context.Context) (string, error) {
 	return r0, r1
 }
 
+// InvalidateCachedIdentity provides a mock function with given fields: ctx, identity
+func (_m *Manager) InvalidateCachedIdentity(ctx context.Context, identity *core.Identity) error {
+	ret := _m.Called(ctx, identity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InvalidateCachedIdentity")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.Identity) error); ok {
+		r0 = rf(ctx, identity)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ResolveIdentitySigner provides a mock function with given fields: ctx, _a1
 func (_m *Manager) ResolveIdentitySigner(ctx context.Context, _a1 *core.Identity) (*core.SignerRef, error) {
 	ret := _m.Called(ctx, _a1)
@@ -21,6 +21,36 @@ func (_m *Manager) AddTime(id string) {
 	_m.Called(id)
 }
 
+// AggregatorBatchProcessed provides a mock function with given fields: namespace, elapsed
+func (_m *Manager) AggregatorBatchProcessed(namespace string, elapsed time.Duration) {
+	_m.Called(namespace, elapsed)
+}
+
+// AggregatorBlockedContexts provides a mock function with given fields: namespace, count
+func (_m *Manager) AggregatorBlockedContexts(namespace string, count int) {
+	_m.Called(namespace, count)
+}
+
+// AggregatorEvent provides a mock function with given fields: namespace, eventType
+func (_m *Manager) AggregatorEvent(namespace string, eventType fftypes.FFEnum) {
+	_m.Called(namespace, eventType)
+}
+
+// AggregatorLag provides a mock function with given fields: namespace, lag
+func (_m *Manager) AggregatorLag(namespace string, lag int64) {
+	_m.Called(namespace, lag)
+}
+
+// AggregatorRetry provides a mock function with given fields: namespace
+func (_m *Manager) AggregatorRetry(namespace string) {
+	_m.Called(namespace)
+}
+
+// AggregatorSequenceGap provides a mock function with given fields: namespace
+func (_m *Manager) AggregatorSequenceGap(namespace string) {
+	_m.Called(namespace)
+}
+
 // BlockchainContractDeployment provides a mock function with given fields:
 func (_m *Manager) BlockchainContractDeployment() {
 	_m.Called()
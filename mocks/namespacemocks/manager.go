@@ -38,6 +38,36 @@ func (_m *Manager) Authorize(ctx context.Context, authReq *fftypes.AuthReq) erro
 	return r0
 }
 
+// CopyData provides a mock function with given fields: ctx, ns, dataID, destNamespace
+func (_m *Manager) CopyData(ctx context.Context, ns string, dataID string, destNamespace string) (*core.Data, error) {
+	ret := _m.Called(ctx, ns, dataID, destNamespace)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CopyData")
+	}
+
+	var r0 *core.Data
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*core.Data, error)); ok {
+		return rf(ctx, ns, dataID, destNamespace)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *core.Data); ok {
+		r0 = rf(ctx, ns, dataID, destNamespace)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Data)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, ns, dataID, destNamespace)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetNamespaces provides a mock function with given fields: ctx, includeInitializing
 func (_m *Manager) GetNamespaces(ctx context.Context, includeInitializing bool) ([]*core.NamespaceWithInitStatus, error) {
 	ret := _m.Called(ctx, includeInitializing)
@@ -116,6 +146,36 @@ func (_m *Manager) Init(ctx context.Context, cancelCtx context.CancelFunc, reset
 	return r0
 }
 
+// ListSubscriptions provides a mock function with given fields: ctx, filter
+func (_m *Manager) ListSubscriptions(ctx context.Context, filter *core.SubscriptionListFilter) ([]*core.SubscriptionWithStatus, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSubscriptions")
+	}
+
+	var r0 []*core.SubscriptionWithStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.SubscriptionListFilter) ([]*core.SubscriptionWithStatus, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.SubscriptionListFilter) []*core.SubscriptionWithStatus); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.SubscriptionWithStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.SubscriptionListFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // MustOrchestrator provides a mock function with given fields: ns
 func (_m *Manager) MustOrchestrator(ns string) orchestrator.Orchestrator {
 	ret := _m.Called(ns)
@@ -242,6 +242,52 @@ func (_m *Orchestrator) DefinitionSender() definitions.Sender {
 	return r0
 }
 
+// DeleteEvent provides a mock function with given fields: ctx, id
+func (_m *Orchestrator) DeleteEvent(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteEventsBefore provides a mock function with given fields: ctx, sequence
+func (_m *Orchestrator) DeleteEventsBefore(ctx context.Context, sequence int64) (int64, error) {
+	ret := _m.Called(ctx, sequence)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteEventsBefore")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (int64, error)); ok {
+		return rf(ctx, sequence)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) int64); ok {
+		r0 = rf(ctx, sequence)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, sequence)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteSubscription provides a mock function with given fields: ctx, id
 func (_m *Orchestrator) DeleteSubscription(ctx context.Context, id string) error {
 	ret := _m.Called(ctx, id)
@@ -280,6 +326,24 @@ func (_m *Orchestrator) Events() events.EventManager {
 	return r0
 }
 
+// ForceUnblockContext provides a mock function with given fields: ctx, contextHash, authReq
+func (_m *Orchestrator) ForceUnblockContext(ctx context.Context, contextHash string, authReq *fftypes.AuthReq) error {
+	ret := _m.Called(ctx, contextHash, authReq)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ForceUnblockContext")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.AuthReq) error); ok {
+		r0 = rf(ctx, contextHash, authReq)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetBatchByID provides a mock function with given fields: ctx, id
 func (_m *Orchestrator) GetBatchByID(ctx context.Context, id string) (*core.BatchPersisted, error) {
 	ret := _m.Called(ctx, id)
@@ -418,6 +482,75 @@ func (_m *Orchestrator) GetBlockchainEvents(ctx context.Context, filter ffapi.An
 	return r0, r1, r2
 }
 
+// GetBlockedContextStats provides a mock function with given fields: ctx
+func (_m *Orchestrator) GetBlockedContextStats(ctx context.Context) (*core.BlockedContextStats, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBlockedContextStats")
+	}
+
+	var r0 *core.BlockedContextStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*core.BlockedContextStats, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *core.BlockedContextStats); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.BlockedContextStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBlockedContexts provides a mock function with given fields: ctx, filter
+func (_m *Orchestrator) GetBlockedContexts(ctx context.Context, filter ffapi.AndFilter) ([]*core.BlockedContext, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBlockedContexts")
+	}
+
+	var r0 []*core.BlockedContext
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) ([]*core.BlockedContext, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) []*core.BlockedContext); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.BlockedContext)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetChartHistogram provides a mock function with given fields: ctx, startTime, endTime, buckets, tableName
 func (_m *Orchestrator) GetChartHistogram(ctx context.Context, startTime int64, endTime int64, buckets int64, tableName database.CollectionName) ([]*core.ChartHistogram, error) {
 	ret := _m.Called(ctx, startTime, endTime, buckets, tableName)
@@ -448,6 +581,45 @@ func (_m *Orchestrator) GetChartHistogram(ctx context.Context, startTime int64,
 	return r0, r1
 }
 
+// GetConversationMessages provides a mock function with given fields: ctx, id, filter
+func (_m *Orchestrator) GetConversationMessages(ctx context.Context, id string, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, id, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetConversationMessages")
+	}
+
+	var r0 []*core.Message
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, id, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) []*core.Message); ok {
+		r0 = rf(ctx, id, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, id, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, id, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetData provides a mock function with given fields: ctx, filter
 func (_m *Orchestrator) GetData(ctx context.Context, filter ffapi.AndFilter) (core.DataArray, *ffapi.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
@@ -646,6 +818,75 @@ func (_m *Orchestrator) GetDatatypes(ctx context.Context, filter ffapi.AndFilter
 	return r0, r1, r2
 }
 
+// GetDeadEvents provides a mock function with given fields: ctx, filter
+func (_m *Orchestrator) GetDeadEvents(ctx context.Context, filter ffapi.AndFilter) ([]*core.DeadEvent, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeadEvents")
+	}
+
+	var r0 []*core.DeadEvent
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) ([]*core.DeadEvent, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) []*core.DeadEvent); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.DeadEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetDescendantNamespaces provides a mock function with given fields: ctx
+func (_m *Orchestrator) GetDescendantNamespaces(ctx context.Context) ([]*core.Namespace, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDescendantNamespaces")
+	}
+
+	var r0 []*core.Namespace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*core.Namespace, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*core.Namespace); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetEventByID provides a mock function with given fields: ctx, id
 func (_m *Orchestrator) GetEventByID(ctx context.Context, id string) (*core.Event, error) {
 	ret := _m.Called(ctx, id)
@@ -913,6 +1154,84 @@ func (_m *Orchestrator) GetMessageEvents(ctx context.Context, id string, filter
 	return r0, r1, r2
 }
 
+// GetMessageReceipts provides a mock function with given fields: ctx, id, filter
+func (_m *Orchestrator) GetMessageReceipts(ctx context.Context, id string, filter ffapi.AndFilter) ([]*core.DeliveryReceipt, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, id, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessageReceipts")
+	}
+
+	var r0 []*core.DeliveryReceipt
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) ([]*core.DeliveryReceipt, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, id, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) []*core.DeliveryReceipt); ok {
+		r0 = rf(ctx, id, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.DeliveryReceipt)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, id, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, id, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetMessageReplies provides a mock function with given fields: ctx, id, filter
+func (_m *Orchestrator) GetMessageReplies(ctx context.Context, id string, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, id, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessageReplies")
+	}
+
+	var r0 []*core.Message
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, id, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) []*core.Message); ok {
+		r0 = rf(ctx, id, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, id, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, id, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetMessageTransaction provides a mock function with given fields: ctx, id
 func (_m *Orchestrator) GetMessageTransaction(ctx context.Context, id string) (*core.Transaction, error) {
 	ret := _m.Called(ctx, id)
@@ -948,33 +1267,72 @@ func (_m *Orchestrator) GetMessages(ctx context.Context, filter ffapi.AndFilter)
 	ret := _m.Called(ctx, filter)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetMessages")
+		panic("no return value specified for GetMessages")
+	}
+
+	var r0 []*core.Message
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) []*core.Message); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetMessagesForData provides a mock function with given fields: ctx, dataID, filter
+func (_m *Orchestrator) GetMessagesForData(ctx context.Context, dataID string, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, dataID, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessagesForData")
 	}
 
 	var r0 []*core.Message
 	var r1 *ffapi.FilterResult
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error)); ok {
-		return rf(ctx, filter)
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, dataID, filter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) []*core.Message); ok {
-		r0 = rf(ctx, filter)
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) []*core.Message); ok {
+		r0 = rf(ctx, dataID, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*core.Message)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, ffapi.AndFilter) *ffapi.FilterResult); ok {
-		r1 = rf(ctx, filter)
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, dataID, filter)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*ffapi.FilterResult)
 		}
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, ffapi.AndFilter) error); ok {
-		r2 = rf(ctx, filter)
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, dataID, filter)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -982,22 +1340,22 @@ func (_m *Orchestrator) GetMessages(ctx context.Context, filter ffapi.AndFilter)
 	return r0, r1, r2
 }
 
-// GetMessagesForData provides a mock function with given fields: ctx, dataID, filter
-func (_m *Orchestrator) GetMessagesForData(ctx context.Context, dataID string, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error) {
-	ret := _m.Called(ctx, dataID, filter)
+// GetMessagesForTag provides a mock function with given fields: ctx, tag, filter
+func (_m *Orchestrator) GetMessagesForTag(ctx context.Context, tag string, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, tag, filter)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetMessagesForData")
+		panic("no return value specified for GetMessagesForTag")
 	}
 
 	var r0 []*core.Message
 	var r1 *ffapi.FilterResult
 	var r2 error
 	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error)); ok {
-		return rf(ctx, dataID, filter)
+		return rf(ctx, tag, filter)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) []*core.Message); ok {
-		r0 = rf(ctx, dataID, filter)
+		r0 = rf(ctx, tag, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*core.Message)
@@ -1005,7 +1363,7 @@ func (_m *Orchestrator) GetMessagesForData(ctx context.Context, dataID string, f
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.AndFilter) *ffapi.FilterResult); ok {
-		r1 = rf(ctx, dataID, filter)
+		r1 = rf(ctx, tag, filter)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*ffapi.FilterResult)
@@ -1013,7 +1371,7 @@ func (_m *Orchestrator) GetMessagesForData(ctx context.Context, dataID string, f
 	}
 
 	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.AndFilter) error); ok {
-		r2 = rf(ctx, dataID, filter)
+		r2 = rf(ctx, tag, filter)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -1060,6 +1418,45 @@ func (_m *Orchestrator) GetMessagesWithData(ctx context.Context, filter ffapi.An
 	return r0, r1, r2
 }
 
+// GetMessagesWithDataForTag provides a mock function with given fields: ctx, tag, filter
+func (_m *Orchestrator) GetMessagesWithDataForTag(ctx context.Context, tag string, filter ffapi.AndFilter) ([]*core.MessageInOut, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, tag, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessagesWithDataForTag")
+	}
+
+	var r0 []*core.MessageInOut
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) ([]*core.MessageInOut, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, tag, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) []*core.MessageInOut); ok {
+		r0 = rf(ctx, tag, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.MessageInOut)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, tag, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, tag, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetNamespace provides a mock function with given fields: ctx
 func (_m *Orchestrator) GetNamespace(ctx context.Context) *core.Namespace {
 	ret := _m.Called(ctx)
@@ -1218,6 +1615,75 @@ func (_m *Orchestrator) GetOperations(ctx context.Context, filter ffapi.AndFilte
 	return r0, r1, r2
 }
 
+// GetPermissions provides a mock function with given fields: ctx, filter
+func (_m *Orchestrator) GetPermissions(ctx context.Context, filter ffapi.AndFilter) ([]*core.NamespacedPermission, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPermissions")
+	}
+
+	var r0 []*core.NamespacedPermission
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) ([]*core.NamespacedPermission, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) []*core.NamespacedPermission); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.NamespacedPermission)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetPinReplay provides a mock function with given fields: ctx, id
+func (_m *Orchestrator) GetPinReplay(ctx context.Context, id string) (*core.PinReplay, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPinReplay")
+	}
+
+	var r0 *core.PinReplay
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.PinReplay, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.PinReplay); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.PinReplay)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPins provides a mock function with given fields: ctx, filter
 func (_m *Orchestrator) GetPins(ctx context.Context, filter ffapi.AndFilter) ([]*core.Pin, *ffapi.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
@@ -1347,6 +1813,45 @@ func (_m *Orchestrator) GetSubscriptionByIDWithStatus(ctx context.Context, id st
 	return r0, r1
 }
 
+// GetSubscriptionDeadLetters provides a mock function with given fields: ctx, id, filter
+func (_m *Orchestrator) GetSubscriptionDeadLetters(ctx context.Context, id string, filter ffapi.AndFilter) ([]*core.DeadLetter, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, id, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSubscriptionDeadLetters")
+	}
+
+	var r0 []*core.DeadLetter
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) ([]*core.DeadLetter, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, id, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) []*core.DeadLetter); ok {
+		r0 = rf(ctx, id, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.DeadLetter)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, id, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, id, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetSubscriptionEventsHistorical provides a mock function with given fields: ctx, subscription, filter, startSequence, endSequence
 func (_m *Orchestrator) GetSubscriptionEventsHistorical(ctx context.Context, subscription *core.Subscription, filter ffapi.AndFilter, startSequence int, endSequence int) ([]*core.EnrichedEvent, *ffapi.FilterResult, error) {
 	ret := _m.Called(ctx, subscription, filter, startSequence, endSequence)
@@ -1602,6 +2107,36 @@ func (_m *Orchestrator) GetTransactions(ctx context.Context, filter ffapi.AndFil
 	return r0, r1, r2
 }
 
+// GrantPermission provides a mock function with given fields: ctx, input
+func (_m *Orchestrator) GrantPermission(ctx context.Context, input *core.PermissionInput) (*core.NamespacedPermission, error) {
+	ret := _m.Called(ctx, input)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GrantPermission")
+	}
+
+	var r0 *core.NamespacedPermission
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.PermissionInput) (*core.NamespacedPermission, error)); ok {
+		return rf(ctx, input)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.PermissionInput) *core.NamespacedPermission); ok {
+		r0 = rf(ctx, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.NamespacedPermission)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.PermissionInput) error); ok {
+		r1 = rf(ctx, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Identity provides a mock function with given fields:
 func (_m *Orchestrator) Identity() identity.Manager {
 	ret := _m.Called()
@@ -1700,6 +2235,42 @@ func (_m *Orchestrator) Operations() operations.Manager {
 	return r0
 }
 
+// PauseAggregator provides a mock function with given fields: ctx
+func (_m *Orchestrator) PauseAggregator(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PauseAggregator")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PopulateMessagePinStatus provides a mock function with given fields: ctx, msgs
+func (_m *Orchestrator) PopulateMessagePinStatus(ctx context.Context, msgs []*core.Message) error {
+	ret := _m.Called(ctx, msgs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PopulateMessagePinStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*core.Message) error); ok {
+		r0 = rf(ctx, msgs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // PreInit provides a mock function with given fields: ctx, cancelCtx
 func (_m *Orchestrator) PreInit(ctx context.Context, cancelCtx context.CancelFunc) {
 	_m.Called(ctx, cancelCtx)
@@ -1725,6 +2296,36 @@ func (_m *Orchestrator) PrivateMessaging() privatemessaging.Manager {
 	return r0
 }
 
+// ReplayPins provides a mock function with given fields: ctx, input
+func (_m *Orchestrator) ReplayPins(ctx context.Context, input *core.PinReplayInput) (*core.PinReplay, error) {
+	ret := _m.Called(ctx, input)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplayPins")
+	}
+
+	var r0 *core.PinReplay
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.PinReplayInput) (*core.PinReplay, error)); ok {
+		return rf(ctx, input)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.PinReplayInput) *core.PinReplay); ok {
+		r0 = rf(ctx, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.PinReplay)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.PinReplayInput) error); ok {
+		r1 = rf(ctx, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RequestReply provides a mock function with given fields: ctx, msg
 func (_m *Orchestrator) RequestReply(ctx context.Context, msg *core.MessageInOut) (*core.MessageInOut, error) {
 	ret := _m.Called(ctx, msg)
@@ -1755,6 +2356,72 @@ func (_m *Orchestrator) RequestReply(ctx context.Context, msg *core.MessageInOut
 	return r0, r1
 }
 
+// RequeueDeadEvent provides a mock function with given fields: ctx, id
+func (_m *Orchestrator) RequeueDeadEvent(ctx context.Context, id string) (*core.DeadEvent, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequeueDeadEvent")
+	}
+
+	var r0 *core.DeadEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.DeadEvent, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.DeadEvent); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.DeadEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResumeAggregator provides a mock function with given fields: ctx
+func (_m *Orchestrator) ResumeAggregator(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResumeAggregator")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokePermission provides a mock function with given fields: ctx, principal
+func (_m *Orchestrator) RevokePermission(ctx context.Context, principal string) error {
+	ret := _m.Called(ctx, principal)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokePermission")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, principal)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // RewindPins provides a mock function with given fields: ctx, rewind
 func (_m *Orchestrator) RewindPins(ctx context.Context, rewind *core.PinRewind) (*core.PinRewind, error) {
 	ret := _m.Called(ctx, rewind)
@@ -48,6 +48,36 @@ func (_m *Manager) EnsureLocalGroup(ctx context.Context, group *core.Group, crea
 	return r0, r1
 }
 
+// GetGroupByHashCached provides a mock function with given fields: ctx, hash
+func (_m *Manager) GetGroupByHashCached(ctx context.Context, hash *fftypes.Bytes32) (*core.Group, error) {
+	ret := _m.Called(ctx, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGroupByHashCached")
+	}
+
+	var r0 *core.Group
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Bytes32) (*core.Group, error)); ok {
+		return rf(ctx, hash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Bytes32) *core.Group); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Group)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Bytes32) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetGroupByID provides a mock function with given fields: ctx, id
 func (_m *Manager) GetGroupByID(ctx context.Context, id string) (*core.Group, error) {
 	ret := _m.Called(ctx, id)
@@ -175,6 +175,36 @@ func (_m *Bridge) WaitForReply(ctx context.Context, id *fftypes.UUID, send synca
 	return r0, r1
 }
 
+// WaitForReplyTo provides a mock function with given fields: ctx, id, send
+func (_m *Bridge) WaitForReplyTo(ctx context.Context, id *fftypes.UUID, send syncasync.SendFunction) (*core.MessageInOut, error) {
+	ret := _m.Called(ctx, id, send)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WaitForReplyTo")
+	}
+
+	var r0 *core.MessageInOut
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, syncasync.SendFunction) (*core.MessageInOut, error)); ok {
+		return rf(ctx, id, send)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, syncasync.SendFunction) *core.MessageInOut); ok {
+		r0 = rf(ctx, id, send)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.MessageInOut)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID, syncasync.SendFunction) error); ok {
+		r1 = rf(ctx, id, send)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // WaitForTokenApproval provides a mock function with given fields: ctx, id, send
 func (_m *Bridge) WaitForTokenApproval(ctx context.Context, id *fftypes.UUID, send syncasync.SendFunction) (*core.TokenApproval, error) {
 	ret := _m.Called(ctx, id, send)
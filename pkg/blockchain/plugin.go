@@ -145,6 +145,10 @@ const (
 
 const FireFlyActionPrefix = "firefly:"
 
+// MessagePinPayloadRef is the payload reference of a batch pin that anchors the hash of a single message,
+// rather than sequencing a batch - so there are no contexts, and nothing to download
+const MessagePinPayloadRef = FireFlyActionPrefix + "message_pin"
+
 type EventType int
 
 const (
@@ -53,6 +53,8 @@ const (
 	SystemTagIdentityUpdate = "ff_identity_update"
 	// SystemTagGapFill is the tag for messages that provide a nonce gap fill for a message that failed to send
 	SystemTagGapFill = "ff_gap_fill"
+	// SystemTagDeliveryReceipt is the tag for messages that broadcast a recipient's acknowledgement of a private message
+	SystemTagDeliveryReceipt = "ff_delivery_receipt"
 )
 
 const (
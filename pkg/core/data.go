@@ -27,6 +27,18 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 )
 
+// DataType distinguishes how the payload of a data item is held
+type DataType = fftypes.FFEnum
+
+var (
+	// DataTypeValue is data with an inline JSON value, which is always available once the data record exists
+	DataTypeValue = fftypes.FFEnumValue("datatype", "value")
+	// DataTypeBlob is data with an opaque binary blob attached, which must be available in the local blob store
+	DataTypeBlob = fftypes.FFEnumValue("datatype", "blob")
+	// DataTypeReference is data that refers to content held outside of FireFly
+	DataTypeReference = fftypes.FFEnumValue("datatype", "reference")
+)
+
 type DataRef struct {
 	ID   *fftypes.UUID    `ffstruct:"DataRef" json:"id,omitempty"`
 	Hash *fftypes.Bytes32 `ffstruct:"DataRef" json:"hash,omitempty" ffexcludeinput:"true"`
@@ -42,16 +54,24 @@ type BlobRef struct {
 	Public string           `ffstruct:"BlobRef" json:"public,omitempty"`
 }
 
+// ExternalDataRef refers to data content that is held outside of FireFly, and is not stored locally
+type ExternalDataRef struct {
+	URL  string           `ffstruct:"ExternalDataRef" json:"url"`
+	Hash *fftypes.Bytes32 `ffstruct:"ExternalDataRef" json:"hash,omitempty"`
+}
+
 type Data struct {
-	ID        *fftypes.UUID    `ffstruct:"Data" json:"id,omitempty"`
-	Validator ValidatorType    `ffstruct:"Data" json:"validator"`
-	Namespace string           `ffstruct:"Data" json:"namespace,omitempty"`
-	Hash      *fftypes.Bytes32 `ffstruct:"Data" json:"hash,omitempty"`
-	Created   *fftypes.FFTime  `ffstruct:"Data" json:"created,omitempty"`
-	Datatype  *DatatypeRef     `ffstruct:"Data" json:"datatype,omitempty"`
-	Value     *fftypes.JSONAny `ffstruct:"Data" json:"value"`
-	Public    string           `ffstruct:"Data" json:"public,omitempty"`
-	Blob      *BlobRef         `ffstruct:"Data" json:"blob,omitempty"`
+	ID          *fftypes.UUID    `ffstruct:"Data" json:"id,omitempty"`
+	Validator   ValidatorType    `ffstruct:"Data" json:"validator"`
+	Namespace   string           `ffstruct:"Data" json:"namespace,omitempty"`
+	Hash        *fftypes.Bytes32 `ffstruct:"Data" json:"hash,omitempty"`
+	Created     *fftypes.FFTime  `ffstruct:"Data" json:"created,omitempty"`
+	Datatype    *DatatypeRef     `ffstruct:"Data" json:"datatype,omitempty"`
+	Value       *fftypes.JSONAny `ffstruct:"Data" json:"value"`
+	Public      string           `ffstruct:"Data" json:"public,omitempty"`
+	Blob        *BlobRef         `ffstruct:"Data" json:"blob,omitempty"`
+	ExternalRef *ExternalDataRef `ffstruct:"Data" json:"externalRef,omitempty"`
+	Type        DataType         `ffstruct:"Data" json:"type,omitempty" ffenum:"datatype" ffexcludeinput:"true"`
 
 	ValueSize int64 `json:"-"` // Used internally for message size calculation, without full payload retrieval
 }
@@ -80,14 +100,16 @@ func (br *BlobRef) BatchBlobRef(batchType BatchType) *BlobRef {
 // This is what is transferred and hashed in a batch payload between nodes.
 func (d *Data) BatchData(batchType BatchType) *Data {
 	return &Data{
-		ID:        d.ID,
-		Validator: d.Validator,
-		Hash:      d.Hash,
-		Created:   d.Created,
-		Datatype:  d.Datatype,
-		Value:     d.Value,
-		Blob:      d.Blob.BatchBlobRef(batchType),
-		ValueSize: d.ValueSize,
+		ID:          d.ID,
+		Validator:   d.Validator,
+		Hash:        d.Hash,
+		Created:     d.Created,
+		Datatype:    d.Datatype,
+		Value:       d.Value,
+		Blob:        d.Blob.BatchBlobRef(batchType),
+		ExternalRef: d.ExternalRef,
+		Type:        d.Type,
+		ValueSize:   d.ValueSize,
 	}
 }
 
@@ -96,6 +118,11 @@ type DataAndBlob struct {
 	Blob *Blob
 }
 
+// DataCopyInput is the request to copy a data record into another namespace
+type DataCopyInput struct {
+	Namespace string `ffstruct:"DataCopyInput" json:"namespace"`
+}
+
 type DatatypeRef struct {
 	Name    string `ffstruct:"DatatypeRef" json:"name,omitempty"`
 	Version string `ffstruct:"DatatypeRef" json:"version,omitempty"`
@@ -155,25 +182,50 @@ func (d *Data) CalcHash(ctx context.Context) (*fftypes.Bytes32, error) {
 		d.Value = fftypes.JSONAnyPtr(fftypes.NullString)
 	}
 	valueIsNull := d.Value.String() == fftypes.NullString
-	if valueIsNull && (d.Blob == nil || d.Blob.Hash == nil) {
+	attachmentHash := d.attachmentHash()
+	if valueIsNull && attachmentHash == nil {
 		return nil, i18n.NewError(ctx, i18n.MsgDataValueIsNull)
 	}
-	// The hash is either the blob hash, the value hash, or if both are supplied
+	// The hash is either the blob (or external reference) hash, the value hash, or if both are supplied
 	// (e.g. a blob with associated metadata) it a hash of the two HEX hashes
 	// concattenated together (no spaces or separation).
 	switch {
-	case !valueIsNull && (d.Blob == nil || d.Blob.Hash == nil):
+	case !valueIsNull && attachmentHash == nil:
 		return d.Value.Hash(), nil
-	case valueIsNull && d.Blob != nil && d.Blob.Hash != nil:
-		return d.Blob.Hash, nil
+	case valueIsNull && attachmentHash != nil:
+		return attachmentHash, nil
 	default:
 		hash := sha256.New()
 		hash.Write([]byte(d.Value.Hash().String()))
-		hash.Write([]byte(d.Blob.Hash.String()))
+		hash.Write([]byte(attachmentHash.String()))
 		return fftypes.HashResult(hash), nil
 	}
 }
 
+// attachmentHash is the hash of the blob attached to the data, or the hash of the external content it refers to
+func (d *Data) attachmentHash() *fftypes.Bytes32 {
+	switch {
+	case d.Blob != nil && d.Blob.Hash != nil:
+		return d.Blob.Hash
+	case d.ExternalRef != nil:
+		return d.ExternalRef.Hash
+	default:
+		return nil
+	}
+}
+
+// CalcType determines the type of the data from its content. The type is not part of the data hash,
+// so it must always be derived from the content rather than trusted from another member.
+func (d *Data) CalcType() DataType {
+	if d.Blob != nil && d.Blob.Hash != nil {
+		return DataTypeBlob
+	}
+	if d.ExternalRef != nil {
+		return DataTypeReference
+	}
+	return DataTypeValue
+}
+
 func (d *Data) Seal(ctx context.Context, blob *Blob) (err error) {
 	if d.Validator == "" {
 		d.Validator = ValidatorTypeJSON
@@ -210,6 +262,7 @@ func (d *Data) Seal(ctx context.Context, blob *Blob) (err error) {
 	if d.ValueSize <= 0 {
 		d.ValueSize = d.Value.Length()
 	}
+	d.Type = d.CalcType()
 	d.Hash, err = d.CalcHash(ctx)
 	if err == nil {
 		err = CheckValidatorType(ctx, d.Validator)
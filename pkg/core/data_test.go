@@ -180,6 +180,36 @@ func TestSealBlobAndHashOnly(t *testing.T) {
 	assert.Equal(t, int64(12345), d.Blob.Size)
 }
 
+func TestSealExternalRefOnly(t *testing.T) {
+	extHash := fftypes.NewRandB32()
+	d := &Data{
+		ExternalRef: &ExternalDataRef{
+			URL:  "https://example.com/data/1",
+			Hash: extHash,
+		},
+	}
+	err := d.Seal(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, DataTypeReference, d.Type)
+	assert.Equal(t, extHash, d.Hash)
+}
+
+func TestSealExternalRefAndValue(t *testing.T) {
+	extHash, _ := fftypes.ParseBytes32(context.Background(), "22440fcf4ee9ac8c1a83de36c3a9ef39f838d960971dc79b274718392f1735f9")
+	d := &Data{
+		ExternalRef: &ExternalDataRef{
+			URL:  "https://example.com/data/1",
+			Hash: extHash,
+		},
+		Value: fftypes.JSONAnyPtr("{}"),
+	}
+	h := sha256.Sum256([]byte(`44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a22440fcf4ee9ac8c1a83de36c3a9ef39f838d960971dc79b274718392f1735f9`))
+	err := d.Seal(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, DataTypeReference, d.Type)
+	assert.Equal(t, d.Hash[:], h[:])
+}
+
 func TestHashDataNull(t *testing.T) {
 
 	jd := []byte(`{
@@ -0,0 +1,61 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+)
+
+// DeliveryReceipt is broadcast by the recipient of a private message, to acknowledge to the sender that the
+// message and all of its data has been received and confirmed by the recipient's node
+type DeliveryReceipt struct {
+	ID           *fftypes.UUID    `ffstruct:"DeliveryReceipt" json:"id,omitempty"`
+	Namespace    string           `ffstruct:"DeliveryReceipt" json:"namespace,omitempty"`
+	MessageID    *fftypes.UUID    `ffstruct:"DeliveryReceipt" json:"messageId,omitempty"`
+	MessageHash  *fftypes.Bytes32 `ffstruct:"DeliveryReceipt" json:"messageHash,omitempty"`
+	RecipientDID string           `ffstruct:"DeliveryReceipt" json:"recipientDid,omitempty"`
+	ReceivedAt   *fftypes.FFTime  `ffstruct:"DeliveryReceipt" json:"receivedAt,omitempty"`
+	Key          string           `ffstruct:"DeliveryReceipt" json:"key,omitempty"`
+	Broadcast    *fftypes.UUID    `ffstruct:"DeliveryReceipt" json:"broadcast,omitempty"`
+}
+
+func (dr *DeliveryReceipt) Validate(ctx context.Context) error {
+	if dr.ID == nil {
+		return i18n.NewError(ctx, i18n.MsgNilID)
+	}
+	if dr.MessageID == nil {
+		return i18n.NewError(ctx, i18n.MsgMissingRequiredField, "messageId")
+	}
+	if dr.MessageHash == nil {
+		return i18n.NewError(ctx, i18n.MsgMissingRequiredField, "messageHash")
+	}
+	if dr.RecipientDID == "" {
+		return i18n.NewError(ctx, i18n.MsgMissingRequiredField, "recipientDid")
+	}
+	return nil
+}
+
+func (dr *DeliveryReceipt) Topic() string {
+	return fftypes.TypeNamespaceNameTopicHash("deliveryreceipt", dr.Namespace, dr.MessageID.String())
+}
+
+func (dr *DeliveryReceipt) SetBroadcastMessage(msgID *fftypes.UUID) {
+	dr.Broadcast = msgID
+}
@@ -0,0 +1,48 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryReceiptValidation(t *testing.T) {
+
+	dr := &DeliveryReceipt{}
+	assert.Regexp(t, "FF00114", dr.Validate(context.Background()))
+
+	dr.ID = fftypes.NewUUID()
+	assert.Regexp(t, "FF00112.*messageId", dr.Validate(context.Background()))
+
+	dr.MessageID = fftypes.NewUUID()
+	assert.Regexp(t, "FF00112.*messageHash", dr.Validate(context.Background()))
+
+	dr.MessageHash = fftypes.NewRandB32()
+	assert.Regexp(t, "FF00112.*recipientDid", dr.Validate(context.Background()))
+
+	dr.RecipientDID = "did:firefly:org/org1"
+	assert.NoError(t, dr.Validate(context.Background()))
+
+	var def Definition = dr
+	assert.Len(t, def.Topic(), 64)
+	def.SetBroadcastMessage(fftypes.NewUUID())
+	assert.NotNil(t, dr.Broadcast)
+}
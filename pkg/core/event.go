@@ -16,7 +16,11 @@
 
 package core
 
-import "github.com/hyperledger/firefly-common/pkg/fftypes"
+import (
+	"fmt"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+)
 
 // EventType indicates what the event means, as well as what the Reference in the event refers to
 type EventType = fftypes.FFEnum
@@ -29,6 +33,13 @@ var (
 	EventTypeMessageConfirmed = fftypes.FFEnumValue("eventtype", "message_confirmed")
 	// EventTypeMessageRejected occurs if a message is received and confirmed from a sequencing perspective, but is rejected as invalid (mismatch to schema, or duplicate system broadcast)
 	EventTypeMessageRejected = fftypes.FFEnumValue("eventtype", "message_rejected")
+	// EventTypeMessagePinned occurs when the blockchain transaction anchoring the hash of a confirmed message has been confirmed.
+	// The transaction of the event is the FireFly transaction that submitted the hash
+	EventTypeMessagePinned = fftypes.FFEnumValue("eventtype", "message_pinned")
+	// EventTypeMessageExpired occurs when a message with a TTL was not confirmed before the TTL elapsed
+	EventTypeMessageExpired = fftypes.FFEnumValue("eventtype", "message_expired")
+	// EventTypeMessageDelivered occurs when delivery receipts have been received from every recipient of a private message
+	EventTypeMessageDelivered = fftypes.FFEnumValue("eventtype", "message_delivered")
 	// EventTypeDatatypeConfirmed occurs when a new datatype is ready for use (on the namespace of the datatype)
 	EventTypeDatatypeConfirmed = fftypes.FFEnumValue("eventtype", "datatype_confirmed")
 	// EventTypeIdentityConfirmed occurs when a new identity has been confirmed, as as result of a signed claim broadcast, and any associated claim verification
@@ -61,6 +72,11 @@ var (
 	EventTypeBlockchainContractDeployOpSucceeded = fftypes.FFEnumValue("eventtype", "blockchain_contract_deploy_op_succeeded")
 	// EventTypeBlockchainContractDeployOpFailed occurs when a contract deployment request has failed
 	EventTypeBlockchainContractDeployOpFailed = fftypes.FFEnumValue("eventtype", "blockchain_contract_deploy_op_failed")
+	// EventTypeDeadEvent occurs when the aggregator gives up retrying a pin, and records it as a dead event so processing can continue
+	EventTypeDeadEvent = fftypes.FFEnumValue("eventtype", "dead_event")
+	// EventTypeContextForceUnblocked occurs when an administrator skips the pin blocking a context, so that later messages on the context can be processed.
+	// The skipped pin is recorded as a dead event
+	EventTypeContextForceUnblocked = fftypes.FFEnumValue("eventtype", "context_force_unblocked")
 )
 
 // Event is an activity in the system, delivered reliably to applications, that indicates something has happened in the network
@@ -90,6 +106,7 @@ type EnrichedEvent struct {
 	TokenTransfer     *TokenTransfer   `ffstruct:"EnrichedEvent" json:"tokenTransfer,omitempty"`
 	Transaction       *Transaction     `ffstruct:"EnrichedEvent" json:"transaction,omitempty"`
 	Operation         *Operation       `ffstruct:"EnrichedEvent" json:"operation,omitempty"`
+	DeadEvent         *DeadEvent       `ffstruct:"EnrichedEvent" json:"deadEvent,omitempty"`
 }
 
 // EventDelivery adds the referred object to an event, as well as details of the subscription that caused the event to
@@ -99,6 +116,17 @@ type EventDelivery struct {
 	Subscription SubscriptionRef `json:"subscription"`
 }
 
+// EventPurgeResult is the outcome of deleting all events before a sequence
+type EventPurgeResult struct {
+	Before  int64 `ffstruct:"EventPurgeResult" json:"before"`
+	Deleted int64 `ffstruct:"EventPurgeResult" json:"deleted"`
+}
+
+// EventBatch is an ordered set of events, containing each event ID at most once
+type EventBatch struct {
+	Events []*Event `json:"events"`
+}
+
 type CombinedEventDataDelivery struct {
 	Event *EventDelivery
 	Data  DataArray
@@ -126,6 +154,29 @@ func NewEvent(t EventType, ns string, ref *fftypes.UUID, tx *fftypes.UUID, topic
 	}
 }
 
+// NewEventBatch builds a batch from the supplied events, preserving their order. Where the same
+// event ID is supplied more than once, only the first occurrence is kept. A nil event is a
+// programming error, and causes a panic.
+func NewEventBatch(events ...*Event) *EventBatch {
+	batch := &EventBatch{
+		Events: make([]*Event, 0, len(events)),
+	}
+	seen := make(map[fftypes.UUID]bool, len(events))
+	for i, e := range events {
+		if e == nil {
+			panic(fmt.Sprintf("nil event at index %d in event batch", i))
+		}
+		if e.ID != nil {
+			if seen[*e.ID] {
+				continue
+			}
+			seen[*e.ID] = true
+		}
+		batch.Events = append(batch.Events, e)
+	}
+	return batch
+}
+
 func (e *Event) LocalSequence() int64 {
 	return e.Sequence
 }
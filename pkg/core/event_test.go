@@ -17,6 +17,7 @@
 package core
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
@@ -39,3 +40,45 @@ func TestNewEvent(t *testing.T) {
 	assert.Equal(t, int64(12345), ls.LocalSequence())
 
 }
+
+func TestNewEventBatchDedup(t *testing.T) {
+	e1 := NewEvent(EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "topic1")
+	e2 := NewEvent(EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "topic1")
+	e3 := NewEvent(EventTypeMessageRejected, "ns1", fftypes.NewUUID(), nil, "topic2")
+	e1dup := *e1
+	e1dup.Topic = "topic3"
+
+	batch := NewEventBatch(e1, e2, &e1dup, e3, e2)
+	assert.Equal(t, []*Event{e1, e2, e3}, batch.Events)
+	assert.Equal(t, "topic1", batch.Events[0].Topic)
+
+	assert.Empty(t, NewEventBatch().Events)
+}
+
+func TestNewEventBatchNilEvent(t *testing.T) {
+	e1 := NewEvent(EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), nil, "topic1")
+	assert.PanicsWithValue(t, "nil event at index 1 in event batch", func() {
+		NewEventBatch(e1, nil)
+	})
+}
+
+func TestEventBatchJSON(t *testing.T) {
+	e1 := NewEvent(EventTypeMessageConfirmed, "ns1", fftypes.NewUUID(), fftypes.NewUUID(), "topic1")
+	e1.Sequence = 1
+	e2 := NewEvent(EventTypeMessageRejected, "ns1", fftypes.NewUUID(), nil, "topic2")
+	e2.Sequence = 2
+	batch := NewEventBatch(e1, e2)
+
+	b, err := json.Marshal(batch)
+	assert.NoError(t, err)
+	var batch2 *EventBatch
+	err = json.Unmarshal(b, &batch2)
+	assert.NoError(t, err)
+	assert.Len(t, batch2.Events, 2)
+	assert.Equal(t, *e1.ID, *batch2.Events[0].ID)
+	assert.Equal(t, int64(1), batch2.Events[0].Sequence)
+	assert.Equal(t, *e1.Transaction, *batch2.Events[0].Transaction)
+	assert.Equal(t, *e2.ID, *batch2.Events[1].ID)
+	assert.Equal(t, EventTypeMessageRejected, batch2.Events[1].Type)
+	assert.Equal(t, "topic2", batch2.Events[1].Topic)
+}
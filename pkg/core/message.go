@@ -23,11 +23,14 @@ import (
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
 )
 
 const (
 	// DefaultTopic will be set as the topic of any messages set without a topic
 	DefaultTopic = "default"
+	// DefaultContentType is the content type assumed for any message that does not specify one
+	DefaultContentType = "application/json"
 )
 
 // MessageType is the fundamental type of a message
@@ -70,6 +73,8 @@ var (
 	MessageStateRejected = fftypes.FFEnumValue("messagestate", "rejected")
 	// MessageStateCancelled is a message that was cancelled without being sent
 	MessageStateCancelled = fftypes.FFEnumValue("messagestate", "cancelled")
+	// MessageStateExpired is a message with a TTL that was not confirmed before the TTL elapsed
+	MessageStateExpired = fftypes.FFEnumValue("messagestate", "expired")
 )
 
 // MessageHeader contains all fields that contribute to the hash
@@ -80,13 +85,18 @@ type MessageHeader struct {
 	Type   MessageType     `ffstruct:"MessageHeader" json:"type" ffenum:"messagetype"`
 	TxType TransactionType `ffstruct:"MessageHeader" json:"txtype,omitempty" ffenum:"txtype"`
 	SignerRef
-	Created   *fftypes.FFTime       `ffstruct:"MessageHeader" json:"created,omitempty" ffexcludeinput:"true"`
-	Namespace string                `ffstruct:"MessageHeader" json:"namespace,omitempty" ffexcludeinput:"true"`
-	Group     *fftypes.Bytes32      `ffstruct:"MessageHeader" json:"group,omitempty" ffexclude:"postNewMessageBroadcast"`
-	Topics    fftypes.FFStringArray `ffstruct:"MessageHeader" json:"topics,omitempty"`
-	Tag       string                `ffstruct:"MessageHeader" json:"tag,omitempty"`
-	DataHash  *fftypes.Bytes32      `ffstruct:"MessageHeader" json:"datahash,omitempty" ffexcludeinput:"true"`
-	TxParent  *TransactionRef       `ffstruct:"MessageHeader" json:"txparent,omitempty" ffexcludeinput:"true"`
+	Created        *fftypes.FFTime       `ffstruct:"MessageHeader" json:"created,omitempty" ffexcludeinput:"true"`
+	Namespace      string                `ffstruct:"MessageHeader" json:"namespace,omitempty" ffexcludeinput:"true"`
+	Group          *fftypes.Bytes32      `ffstruct:"MessageHeader" json:"group,omitempty" ffexclude:"postNewMessageBroadcast"`
+	Topics         fftypes.FFStringArray `ffstruct:"MessageHeader" json:"topics,omitempty"`
+	Tag            string                `ffstruct:"MessageHeader" json:"tag,omitempty"`
+	DataHash       *fftypes.Bytes32      `ffstruct:"MessageHeader" json:"datahash,omitempty" ffexcludeinput:"true"`
+	TxParent       *TransactionRef       `ffstruct:"MessageHeader" json:"txparent,omitempty" ffexcludeinput:"true"`
+	ContentType    string                `ffstruct:"MessageHeader" json:"contentType,omitempty"`
+	ReplyTo        *fftypes.UUID         `ffstruct:"MessageHeader" json:"replyTo,omitempty"`
+	ConversationID *fftypes.UUID         `ffstruct:"MessageHeader" json:"conversationId,omitempty"`
+	TTL            *fftypes.FFDuration   `ffstruct:"MessageHeader" json:"ttl,omitempty"`
+	Priority       uint8                 `ffstruct:"MessageHeader" json:"priority,omitempty"`
 }
 
 // Message is the envelope by which coordinated data exchange can happen between parties in the network
@@ -100,10 +110,13 @@ type Message struct {
 	TransactionID  *fftypes.UUID         `ffstruct:"Message" json:"txid,omitempty" ffexcludeinput:"true"`
 	State          MessageState          `ffstruct:"Message" json:"state,omitempty" ffenum:"messagestate" ffexcludeinput:"true"`
 	Confirmed      *fftypes.FFTime       `ffstruct:"Message" json:"confirmed,omitempty" ffexcludeinput:"true"`
+	ReceivedAt     *fftypes.FFTime       `ffstruct:"Message" json:"receivedAt,omitempty" ffexcludeinput:"true"`
 	RejectReason   string                `ffstruct:"Message" json:"rejectReason,omitempty" ffexcludeinput:"true"`
 	Data           DataRefs              `ffstruct:"Message" json:"data" ffexcludeinput:"true"`
 	Pins           fftypes.FFStringArray `ffstruct:"Message" json:"pins,omitempty" ffexcludeinput:"true"`
 	IdempotencyKey IdempotencyKey        `ffstruct:"Message" json:"idempotencyKey,omitempty"`
+	Tags           fftypes.FFStringArray `ffstruct:"Message" json:"tags,omitempty"`
+	PinStatus      *TxStatus             `ffstruct:"Message" json:"pinStatus,omitempty" ffexcludeinput:"true"`
 	Sequence       int64                 `ffstruct:"Message" json:"-"` // Local database sequence used internally for batch assembly
 }
 
@@ -121,6 +134,8 @@ func (m *Message) BatchMessage() *Message {
 		TransactionID: m.TransactionID,
 		// The pins are immutable once assigned by the sender, which happens before the batch is sealed
 		Pins: m.Pins,
+		// The tags are immutable once the message is created
+		Tags: m.Tags,
 	}
 }
 
@@ -146,10 +161,11 @@ type InlineData []*DataRefOrValue
 type DataRefOrValue struct {
 	DataRef
 
-	Validator ValidatorType    `ffstruct:"DataRefOrValue" json:"validator,omitempty"`
-	Datatype  *DatatypeRef     `ffstruct:"DataRefOrValue" json:"datatype,omitempty"`
-	Value     *fftypes.JSONAny `ffstruct:"DataRefOrValue" json:"value,omitempty"`
-	Blob      *BlobRef         `ffstruct:"DataRefOrValue" json:"blob,omitempty" ffexcludeinput:"true"`
+	Validator   ValidatorType    `ffstruct:"DataRefOrValue" json:"validator,omitempty"`
+	Datatype    *DatatypeRef     `ffstruct:"DataRefOrValue" json:"datatype,omitempty"`
+	Value       *fftypes.JSONAny `ffstruct:"DataRefOrValue" json:"value,omitempty"`
+	Blob        *BlobRef         `ffstruct:"DataRefOrValue" json:"blob,omitempty" ffexcludeinput:"true"`
+	ExternalRef *ExternalDataRef `ffstruct:"DataRefOrValue" json:"externalRef,omitempty"`
 }
 
 // MessageRef is a lightweight data structure that can be used to refer to a message
@@ -172,9 +188,10 @@ func (m *MessageInOut) SetInlineData(data []*Data) {
 				ID:   d.ID,
 				Hash: d.Hash,
 			},
-			Validator: d.Validator,
-			Datatype:  d.Datatype,
-			Value:     d.Value,
+			Validator:   d.Validator,
+			Datatype:    d.Datatype,
+			Value:       d.Value,
+			ExternalRef: d.ExternalRef,
 		}
 	}
 }
@@ -250,6 +267,9 @@ func (m *Message) VerifyFields(ctx context.Context) error {
 			return err
 		}
 	}
+	if err := m.Tags.Validate(ctx, "tags", true, fftypes.FFStringNameItemsMax); err != nil {
+		return err
+	}
 	return m.DupDataCheck(ctx)
 }
 
@@ -273,6 +293,14 @@ func (m *Message) LocalSequence() int64 {
 	return m.Sequence
 }
 
+// ConfirmationLatencyMs is the time in milliseconds between the message being created, and it being confirmed
+func (m *Message) ConfirmationLatencyMs(ctx context.Context) (int64, error) {
+	if m.Confirmed == nil || m.Header.Created == nil {
+		return 0, i18n.NewError(ctx, coremsgs.MsgMessageNotConfirmed, m.Header.ID)
+	}
+	return m.Confirmed.Time().Sub(*m.Header.Created.Time()).Milliseconds(), nil
+}
+
 // MessageAction is an action to be taken on a message during processing
 type MessageAction int
 
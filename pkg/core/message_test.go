@@ -21,6 +21,7 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
@@ -66,6 +67,17 @@ func TestSealBadTagString(t *testing.T) {
 	assert.Regexp(t, `FF00140.*header.tag`, err)
 }
 
+func TestSealBadTagsString(t *testing.T) {
+	msg := Message{
+		Header: MessageHeader{
+			Tag: "tag1",
+		},
+		Tags: []string{"invoice", "!wrong"},
+	}
+	err := msg.Seal(context.Background())
+	assert.Regexp(t, `FF00140.*tags\[1\]`, err)
+}
+
 func TestVerifyTXType(t *testing.T) {
 	msg := Message{
 		Header: MessageHeader{
@@ -260,6 +272,32 @@ func TestMessageImmutable(t *testing.T) {
 	assert.True(t, msg.Hash.Equals(msg.BatchMessage().Hash))
 }
 
+func TestMessageConfirmationLatencyMs(t *testing.T) {
+	created := fftypes.Now()
+	confirmed := fftypes.FFTime(created.Time().Add(1500 * time.Millisecond))
+	msg := &Message{
+		Header: MessageHeader{
+			ID:      fftypes.NewUUID(),
+			Created: created,
+		},
+		Confirmed: &confirmed,
+	}
+	latency, err := msg.ConfirmationLatencyMs(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1500), latency)
+}
+
+func TestMessageConfirmationLatencyMsNotConfirmed(t *testing.T) {
+	msg := &Message{
+		Header: MessageHeader{
+			ID:      fftypes.NewUUID(),
+			Created: fftypes.Now(),
+		},
+	}
+	_, err := msg.ConfirmationLatencyMs(context.Background())
+	assert.Regexp(t, "FF10492", err)
+}
+
 func TestMessageActions(t *testing.T) {
 	assert.Equal(t, "reject", ActionReject.String())
 	assert.Equal(t, "confirm", ActionConfirm.String())
@@ -29,12 +29,13 @@ import (
 // Namespace is an isolated set of named resources, to allow multiple applications to co-exist in the same network, with the same named objects.
 // Can be used for use case segregation, or multi-tenancy.
 type Namespace struct {
-	Name        string                 `ffstruct:"Namespace" json:"name"`
-	NetworkName string                 `ffstruct:"Namespace" json:"networkName"`
-	Description string                 `ffstruct:"Namespace" json:"description"`
-	Created     *fftypes.FFTime        `ffstruct:"Namespace" json:"created" ffexcludeinput:"true"`
-	Contracts   *MultipartyContracts   `ffstruct:"Namespace" json:"-"`
-	TLSConfigs  map[string]*tls.Config `ffstruct:"Namespace" json:"-" ffexcludeinput:"true"`
+	Name            string                 `ffstruct:"Namespace" json:"name"`
+	NetworkName     string                 `ffstruct:"Namespace" json:"networkName"`
+	Description     string                 `ffstruct:"Namespace" json:"description"`
+	ParentNamespace *string                `ffstruct:"Namespace" json:"parentNamespace,omitempty"`
+	Created         *fftypes.FFTime        `ffstruct:"Namespace" json:"created" ffexcludeinput:"true"`
+	Contracts       *MultipartyContracts   `ffstruct:"Namespace" json:"-"`
+	TLSConfigs      map[string]*tls.Config `ffstruct:"Namespace" json:"-" ffexcludeinput:"true"`
 }
 
 type NamespaceWithInitStatus struct {
@@ -76,6 +77,26 @@ type NetworkAction struct {
 	Type NetworkActionType `ffstruct:"NetworkAction" json:"type" ffenum:"networkactiontype"`
 }
 
+// NamespaceExportRecordType is the type of object held in a namespace export record
+type NamespaceExportRecordType = fftypes.FFEnum
+
+var (
+	// NamespaceExportRecordTypeData is a data record, including its value
+	NamespaceExportRecordTypeData = fftypes.FFEnumValue("namespaceexportrecordtype", "data")
+	// NamespaceExportRecordTypeMessage is a message, including its data references
+	NamespaceExportRecordTypeMessage = fftypes.FFEnumValue("namespaceexportrecordtype", "message")
+	// NamespaceExportRecordTypeEvent is an event
+	NamespaceExportRecordTypeEvent = fftypes.FFEnumValue("namespaceexportrecordtype", "event")
+	// NamespaceExportRecordTypeSubscription is a subscription
+	NamespaceExportRecordTypeSubscription = fftypes.FFEnumValue("namespaceexportrecordtype", "subscription")
+)
+
+// NamespaceExportRecord is a single line of a newline-delimited JSON namespace export
+type NamespaceExportRecord struct {
+	Type   NamespaceExportRecordType `json:"type"`
+	Record json.RawMessage           `json:"record"`
+}
+
 // Scan implements sql.Scanner
 func (fc *MultipartyContracts) Scan(src interface{}) error {
 	switch src := src.(type) {
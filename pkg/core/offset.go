@@ -27,13 +27,22 @@ var (
 	OffsetTypeAggregator = fftypes.FFEnumValue("offsettype", "aggregator")
 	// OffsetTypeSubscription is an offeset stored by a dispatcher on the events table
 	OffsetTypeSubscription = fftypes.FFEnumValue("offsettype", "subscription")
+	// OffsetTypeReplay is an offset stored by a replay of a range of pins through the aggregator, and removed once it completes
+	OffsetTypeReplay = fftypes.FFEnumValue("offsettype", "replay")
 )
 
+// OffsetSchemaVersion is the version of the sequence numbering that stored offsets refer to.
+// It must be incremented by any migration that renumbers the sequences of a table that offsets
+// are stored against, so that offsets written before the migration are detected on startup.
+const OffsetSchemaVersion = 1
+
 // Offset is a simple stored data structure that records a sequence position within another collection
 type Offset struct {
 	Type    OffsetType `json:"type" ffenum:"offsettype"`
 	Name    string     `json:"name"`
 	Current int64      `json:"current,omitempty"`
 
+	SchemaVersion int `json:"schemaVersion,omitempty"`
+
 	RowID int64 `json:"-"`
 }
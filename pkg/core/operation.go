@@ -33,6 +33,8 @@ type OpType = fftypes.FFEnum
 var (
 	// OpTypeBlockchainPinBatch is a blockchain transaction to pin a batch
 	OpTypeBlockchainPinBatch = fftypes.FFEnumValue("optype", "blockchain_pin_batch")
+	// OpTypeBlockchainPinMessage is a blockchain transaction to pin the hash of a single confirmed message
+	OpTypeBlockchainPinMessage = fftypes.FFEnumValue("optype", "blockchain_pin_message")
 	// OpTypeBlockchainNetworkAction is an administrative action on a multiparty blockchain network
 	OpTypeBlockchainNetworkAction = fftypes.FFEnumValue("optype", "blockchain_network_action")
 	// OpTypeBlockchainContractDeploy is a smart contract deploy
@@ -67,6 +69,7 @@ func (op *Operation) IsBlockchainOperation() bool {
 	return op.Type == OpTypeBlockchainInvoke ||
 		op.Type == OpTypeBlockchainNetworkAction ||
 		op.Type == OpTypeBlockchainPinBatch ||
+		op.Type == OpTypeBlockchainPinMessage ||
 		op.Type == OpTypeBlockchainContractDeploy
 }
 
@@ -0,0 +1,57 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "github.com/hyperledger/firefly-common/pkg/fftypes"
+
+// PermissionRole is the level of access granted to a principal within a namespace
+type PermissionRole = fftypes.FFEnum
+
+var (
+	// PermissionRoleRead allows query access to the resources of a namespace
+	PermissionRoleRead = fftypes.FFEnumValue("permissionrole", "read")
+	// PermissionRoleWrite allows read access, plus the ability to create and modify resources in a namespace
+	PermissionRoleWrite = fftypes.FFEnumValue("permissionrole", "write")
+	// PermissionRoleAdmin allows write access, plus access to the administrative (SPI) routes of a namespace
+	PermissionRoleAdmin = fftypes.FFEnumValue("permissionrole", "admin")
+)
+
+// NamespacedPermission records the role granted to an authenticated principal within a namespace
+type NamespacedPermission struct {
+	Namespace string          `ffstruct:"NamespacedPermission" json:"namespace"`
+	Principal string          `ffstruct:"NamespacedPermission" json:"principal"`
+	Role      PermissionRole  `ffstruct:"NamespacedPermission" json:"role" ffenum:"permissionrole"`
+	Created   *fftypes.FFTime `ffstruct:"NamespacedPermission" json:"created,omitempty"`
+}
+
+// PermissionInput is the request to grant a role in a namespace to a principal
+type PermissionInput struct {
+	Principal string         `ffstruct:"PermissionInput" json:"principal"`
+	Role      PermissionRole `ffstruct:"PermissionInput" json:"role" ffenum:"permissionrole"`
+}
+
+var permissionRoleLevels = map[PermissionRole]int{
+	PermissionRoleRead:  1,
+	PermissionRoleWrite: 2,
+	PermissionRoleAdmin: 3,
+}
+
+// Allows returns true if this permission grants at least the level of access of the required role
+func (p *NamespacedPermission) Allows(required PermissionRole) bool {
+	granted, ok := permissionRoleLevels[p.Role]
+	return ok && granted >= permissionRoleLevels[required]
+}
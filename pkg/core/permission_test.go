@@ -0,0 +1,36 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermissionAllows(t *testing.T) {
+	p := &NamespacedPermission{Role: PermissionRoleWrite}
+	assert.True(t, p.Allows(PermissionRoleRead))
+	assert.True(t, p.Allows(PermissionRoleWrite))
+	assert.False(t, p.Allows(PermissionRoleAdmin))
+
+	p = &NamespacedPermission{Role: PermissionRoleAdmin}
+	assert.True(t, p.Allows(PermissionRoleAdmin))
+
+	p = &NamespacedPermission{Role: "unknown"}
+	assert.False(t, p.Allows(PermissionRoleRead))
+}
@@ -58,3 +58,76 @@ type PinRewind struct {
 	Sequence int64         `ffstruct:"PinRewind" json:"sequence"`
 	Batch    *fftypes.UUID `ffstruct:"PinRewind" json:"batch"`
 }
+
+// DeadEvent is a pin the aggregator failed to process within the configured number of retries,
+// set aside so that processing of the pins that follow it can continue
+type DeadEvent struct {
+	ID        *fftypes.UUID    `ffstruct:"DeadEvent" json:"id"`
+	Namespace string           `ffstruct:"DeadEvent" json:"namespace"`
+	Pin       int64            `ffstruct:"DeadEvent" json:"pin"`
+	Batch     *fftypes.UUID    `ffstruct:"DeadEvent" json:"batch,omitempty"`
+	Hash      *fftypes.Bytes32 `ffstruct:"DeadEvent" json:"hash,omitempty"`
+	Index     int64            `ffstruct:"DeadEvent" json:"index"`
+	Attempts  int              `ffstruct:"DeadEvent" json:"attempts"`
+	Error     string           `ffstruct:"DeadEvent" json:"error,omitempty"`
+	Created   *fftypes.FFTime  `ffstruct:"DeadEvent" json:"created"`
+	Updated   *fftypes.FFTime  `ffstruct:"DeadEvent" json:"updated"`
+}
+
+type PinReplayStatus = fftypes.FFEnum
+
+var (
+	// PinReplayStatusRunning the replay is processing pins in its range
+	PinReplayStatusRunning = fftypes.FFEnumValue("pinreplaystatus", "running")
+	// PinReplayStatusComplete the replay has reached the end of its range. Pins that still could not be dispatched are left undispatched
+	PinReplayStatusComplete = fftypes.FFEnumValue("pinreplaystatus", "complete")
+)
+
+// PinReplayInput requests a range of pins to be processed again by the aggregator
+type PinReplayInput struct {
+	FromSequence int64 `ffstruct:"PinReplay" json:"fromSequence"`
+	ToSequence   int64 `ffstruct:"PinReplay" json:"toSequence"`
+}
+
+// PinReplay is the progress of a replay, which processes any undispatched pins in a range behind the
+// aggregator's offset, using a separate offset so the aggregator's own offset is not moved
+type PinReplay struct {
+	ID           *fftypes.UUID   `ffstruct:"PinReplay" json:"id"`
+	Namespace    string          `ffstruct:"PinReplay" json:"namespace"`
+	FromSequence int64           `ffstruct:"PinReplay" json:"fromSequence"`
+	ToSequence   int64           `ffstruct:"PinReplay" json:"toSequence"`
+	Current      int64           `ffstruct:"PinReplay" json:"current"`
+	Status       PinReplayStatus `ffstruct:"PinReplay" json:"status" ffenum:"pinreplaystatus"`
+	Undispatched int64           `ffstruct:"PinReplay" json:"undispatched"`
+	Created      *fftypes.FFTime `ffstruct:"PinReplay" json:"created"`
+	Completed    *fftypes.FFTime `ffstruct:"PinReplay" json:"completed,omitempty"`
+}
+
+// BlockedContext is a context whose earliest undispatched pin is behind the aggregator's offset,
+// so no later message on the context can be dispatched until that pin is processed
+type BlockedContext struct {
+	Context *fftypes.Bytes32      `ffstruct:"BlockedContext" json:"context"`
+	Pin     int64                 `ffstruct:"BlockedContext" json:"pin"`
+	Batch   *fftypes.UUID         `ffstruct:"BlockedContext" json:"batch,omitempty"`
+	Index   int64                 `ffstruct:"BlockedContext" json:"index"`
+	Signer  string                `ffstruct:"BlockedContext" json:"signer,omitempty"`
+	Message *fftypes.UUID         `ffstruct:"BlockedContext" json:"message,omitempty"`
+	Author  string                `ffstruct:"BlockedContext" json:"author,omitempty"`
+	Tag     string                `ffstruct:"BlockedContext" json:"tag,omitempty"`
+	Topics  fftypes.FFStringArray `ffstruct:"BlockedContext" json:"topics,omitempty"`
+	Created *fftypes.FFTime       `ffstruct:"BlockedContext" json:"created,omitempty"`
+}
+
+// BlockedContextStats summarizes how many contexts are blocked, and for how long
+type BlockedContextStats struct {
+	Total     int64                      `ffstruct:"BlockedContextStats" json:"total"`
+	Oldest    *fftypes.FFTime            `ffstruct:"BlockedContextStats" json:"oldest,omitempty"`
+	OldestAge *fftypes.FFDuration        `ffstruct:"BlockedContextStats" json:"oldestAge,omitempty"`
+	Histogram []*BlockedContextAgeBucket `ffstruct:"BlockedContextStats" json:"histogram"`
+}
+
+// BlockedContextAgeBucket counts the blocked contexts with an age from MinAge, up to the MinAge of the next bucket
+type BlockedContextAgeBucket struct {
+	MinAge *fftypes.FFDuration `ffstruct:"BlockedContextAgeBucket" json:"minAge"`
+	Count  int64               `ffstruct:"BlockedContextAgeBucket" json:"count"`
+}
@@ -30,9 +30,11 @@ import (
 type SubscriptionFilter struct {
 	Events           string                `ffstruct:"SubscriptionFilter" json:"events,omitempty"`
 	Message          MessageFilter         `ffstruct:"SubscriptionFilter" json:"message,omitempty"`
+	MessageTag       string                `ffstruct:"SubscriptionFilter" json:"messageTag,omitempty"`
 	Transaction      TransactionFilter     `ffstruct:"SubscriptionFilter" json:"transaction,omitempty"`
 	BlockchainEvent  BlockchainEventFilter `ffstruct:"SubscriptionFilter" json:"blockchainevent,omitempty"`
 	Topic            string                `ffstruct:"SubscriptionFilter" json:"topic,omitempty"`
+	Namespaces       []string              `ffstruct:"SubscriptionFilter" json:"namespaces,omitempty"`
 	DeprecatedTopics string                `ffstruct:"SubscriptionFilter" json:"topics,omitempty"`
 	DeprecatedTag    string                `ffstruct:"SubscriptionFilter" json:"tag,omitempty"`
 	DeprecatedGroup  string                `ffstruct:"SubscriptionFilter" json:"group,omitempty"`
@@ -47,6 +49,7 @@ func NewSubscriptionFilterFromQuery(query url.Values) SubscriptionFilter {
 			Tag:    query.Get("filter.message.tag"),
 			Author: query.Get("filter.message.author"),
 		},
+		MessageTag: query.Get("filter.messageTag"),
 		BlockchainEvent: BlockchainEventFilter{
 			Name:     query.Get("filter.blockchain.name"),
 			Listener: query.Get("filter.blockchain.listener"),
@@ -87,14 +90,40 @@ const (
 	SubOptsFirstEventNewest SubOptsFirstEvent = "newest"
 )
 
+// SubOptsStartupMode controls where a durable subscription picks up from each time it is loaded on startup
+type SubOptsStartupMode string
+
+const (
+	// SubOptsStartupModeResume continues from the last committed offset
+	SubOptsStartupModeResume SubOptsStartupMode = "resume"
+	// SubOptsStartupModeSkip advances to the newest event, skipping any events received while stopped
+	SubOptsStartupModeSkip SubOptsStartupMode = "skip"
+	// SubOptsStartupModeReplay resets to the firstEvent of the subscription, redelivering all events since then
+	SubOptsStartupModeReplay SubOptsStartupMode = "replay"
+)
+
+// SubOptsErrorHandling controls what happens to an event when the transport fails to deliver it
+type SubOptsErrorHandling string
+
+const (
+	// SubOptsErrorHandlingBlock redelivers the failed event until it succeeds, holding up all later events
+	SubOptsErrorHandlingBlock SubOptsErrorHandling = "block"
+	// SubOptsErrorHandlingSkip discards the failed event and continues with the next
+	SubOptsErrorHandlingSkip SubOptsErrorHandling = "skip"
+	// SubOptsErrorHandlingDeadLetter records the failed event as a dead letter and continues with the next
+	SubOptsErrorHandlingDeadLetter SubOptsErrorHandling = "deadletter"
+)
+
 // SubscriptionCoreOptions are the core options that apply across all transports
 // REMEMBER TO ADD OPTIONS HERE TO MarshalJSON()
 type SubscriptionCoreOptions struct {
-	FirstEvent   *SubOptsFirstEvent `ffstruct:"SubscriptionCoreOptions" json:"firstEvent,omitempty"`
-	ReadAhead    *uint16            `ffstruct:"SubscriptionCoreOptions" json:"readAhead,omitempty"`
-	WithData     *bool              `ffstruct:"SubscriptionCoreOptions" json:"withData,omitempty"`
-	Batch        *bool              `ffstruct:"SubscriptionCoreOptions" json:"batch,omitempty"`
-	BatchTimeout *string            `ffstruct:"SubscriptionCoreOptions" json:"batchTimeout,omitempty"`
+	FirstEvent    *SubOptsFirstEvent    `ffstruct:"SubscriptionCoreOptions" json:"firstEvent,omitempty"`
+	ReadAhead     *uint16               `ffstruct:"SubscriptionCoreOptions" json:"readAhead,omitempty"`
+	WithData      *bool                 `ffstruct:"SubscriptionCoreOptions" json:"withData,omitempty"`
+	Batch         *bool                 `ffstruct:"SubscriptionCoreOptions" json:"batch,omitempty"`
+	BatchTimeout  *string               `ffstruct:"SubscriptionCoreOptions" json:"batchTimeout,omitempty"`
+	StartupMode   *SubOptsStartupMode   `ffstruct:"SubscriptionCoreOptions" json:"startupMode,omitempty"`
+	ErrorHandling *SubOptsErrorHandling `ffstruct:"SubscriptionCoreOptions" json:"errorHandling,omitempty"`
 }
 
 // SubscriptionOptions customize the behavior of subscriptions
@@ -125,13 +154,43 @@ type Subscription struct {
 	Updated   *fftypes.FFTime     `ffstruct:"Subscription" json:"updated" ffexcludeinput:"true"`
 }
 
+// DeadLetter is an event that a subscription failed to deliver, and set aside so delivery could continue
+type DeadLetter struct {
+	ID            *fftypes.UUID   `ffstruct:"DeadLetter" json:"id"`
+	Namespace     string          `ffstruct:"DeadLetter" json:"namespace"`
+	Subscription  *fftypes.UUID   `ffstruct:"DeadLetter" json:"subscription"`
+	Event         *fftypes.UUID   `ffstruct:"DeadLetter" json:"event"`
+	EventSequence int64           `ffstruct:"DeadLetter" json:"eventSequence"`
+	Error         string          `ffstruct:"DeadLetter" json:"error,omitempty"`
+	Created       *fftypes.FFTime `ffstruct:"DeadLetter" json:"created"`
+}
+
 type SubscriptionWithStatus struct {
 	Subscription
 	Status SubscriptionStatus `ffstruct:"SubscriptionWithStatus" json:"status,omitempty" ffexcludeinput:"true"`
 }
 
 type SubscriptionStatus struct {
-	CurrentOffset int64 `ffstruct:"SubscriptionStatus" json:"currentOffset,omitempty" ffexcludeinout:"true"`
+	CurrentOffset int64                     `ffstruct:"SubscriptionStatus" json:"currentOffset,omitempty" ffexcludeinout:"true"`
+	DeliveryState SubscriptionDeliveryState `ffstruct:"SubscriptionStatus" json:"deliveryState,omitempty"`
+	DeliveryLag   *int64                    `ffstruct:"SubscriptionStatus" json:"deliveryLag,omitempty"`
+}
+
+// SubscriptionDeliveryState is whether events are currently being delivered for a subscription on this node
+type SubscriptionDeliveryState string
+
+const (
+	// SubscriptionDeliveryStateActive means a connected consumer has a dispatcher delivering events
+	SubscriptionDeliveryStateActive SubscriptionDeliveryState = "active"
+	// SubscriptionDeliveryStatePaused means no events are being delivered, as no consumer is connected or the subscription is draining
+	SubscriptionDeliveryStatePaused SubscriptionDeliveryState = "paused"
+)
+
+// SubscriptionListFilter selects subscriptions across namespaces for monitoring
+type SubscriptionListFilter struct {
+	Namespace     string
+	Transport     string
+	DeliveryState SubscriptionDeliveryState
 }
 
 func (so *SubscriptionOptions) UnmarshalJSON(b []byte) error {
@@ -149,6 +208,8 @@ func (so *SubscriptionOptions) UnmarshalJSON(b []byte) error {
 	delete(so.additionalOptions, "firstEvent")
 	delete(so.additionalOptions, "readAhead")
 	delete(so.additionalOptions, "withData")
+	delete(so.additionalOptions, "startupMode")
+	delete(so.additionalOptions, "errorHandling")
 	return nil
 }
 
@@ -174,10 +235,32 @@ func (so SubscriptionOptions) MarshalJSON() ([]byte, error) {
 	if so.BatchTimeout != nil {
 		so.additionalOptions["batchTimeout"] = so.BatchTimeout
 	}
+	if so.StartupMode != nil {
+		so.additionalOptions["startupMode"] = *so.StartupMode
+	}
+	if so.ErrorHandling != nil {
+		so.additionalOptions["errorHandling"] = *so.ErrorHandling
+	}
 
 	return json.Marshal(&so.additionalOptions)
 }
 
+// WithoutSecrets returns a copy of the subscription with the webhook secret removed, as the secret is
+// write-only and must never be returned by the API
+func (s *Subscription) WithoutSecrets() *Subscription {
+	redacted := *s
+	redacted.Options.Secret = ""
+	if s.Options.additionalOptions != nil {
+		redacted.Options.additionalOptions = make(fftypes.JSONObject, len(s.Options.additionalOptions))
+		for k, v := range s.Options.additionalOptions {
+			if k != "secret" {
+				redacted.Options.additionalOptions[k] = v
+			}
+		}
+	}
+	return &redacted
+}
+
 func (so *SubscriptionOptions) TransportOptions() fftypes.JSONObject {
 	if so.additionalOptions == nil {
 		so.additionalOptions = fftypes.JSONObject{}
@@ -29,14 +29,18 @@ func TestSubscriptionOptionsDatabaseSerialization(t *testing.T) {
 	readAhead := uint16(50)
 	yes := true
 	oneSec := "1s"
+	skip := SubOptsStartupModeSkip
+	deadLetter := SubOptsErrorHandlingDeadLetter
 	sub1 := &Subscription{
 		Options: SubscriptionOptions{
 			SubscriptionCoreOptions: SubscriptionCoreOptions{
-				FirstEvent:   &firstEvent,
-				ReadAhead:    &readAhead,
-				WithData:     &yes,
-				Batch:        &yes,
-				BatchTimeout: &oneSec,
+				FirstEvent:    &firstEvent,
+				ReadAhead:     &readAhead,
+				WithData:      &yes,
+				Batch:         &yes,
+				BatchTimeout:  &oneSec,
+				StartupMode:   &skip,
+				ErrorHandling: &deadLetter,
 			},
 			WebhookSubOptions: WebhookSubOptions{
 				TLSConfigName: "myconfig",
@@ -62,7 +66,9 @@ func TestSubscriptionOptionsDatabaseSerialization(t *testing.T) {
 		"tlsConfigName":"myconfig",
 		"withData":true,
 		"batch":true,
-		"batchTimeout":"1s"
+		"batchTimeout":"1s",
+		"startupMode":"skip",
+		"errorHandling":"deadletter"
 	}`, string(b1.([]byte)))
 
 	f1, err := sub1.Filter.Value()
@@ -79,12 +85,15 @@ func TestSubscriptionOptionsDatabaseSerialization(t *testing.T) {
 	assert.Equal(t, SubOptsFirstEventNewest, *sub2.Options.FirstEvent)
 	assert.Equal(t, uint16(50), *sub2.Options.ReadAhead)
 	assert.Equal(t, "myconfig", sub2.Options.TLSConfigName)
+	assert.Equal(t, SubOptsStartupModeSkip, *sub2.Options.StartupMode)
+	assert.Equal(t, SubOptsErrorHandlingDeadLetter, *sub2.Options.ErrorHandling)
 	assert.Equal(t, string(b1.([]byte)), string(b2.([]byte)))
 
 	// Confirm we don't pass core options, to transports
 	assert.Nil(t, sub2.Options.TransportOptions()["withData"])
 	assert.Nil(t, sub2.Options.TransportOptions()["firstEvent"])
 	assert.Nil(t, sub2.Options.TransportOptions()["readAhead"])
+	assert.Nil(t, sub2.Options.TransportOptions()["startupMode"])
 
 	// Confirm we get back the transport options
 	assert.Equal(t, float64(12345), sub2.Options.TransportOptions().GetObject("my-nested-opts")["myopt1"])
@@ -112,6 +121,25 @@ func TestSubscriptionOptionsDatabaseSerialization(t *testing.T) {
 	assert.Regexp(t, "FF00105", err)
 }
 
+func TestSubscriptionWithoutSecrets(t *testing.T) {
+	sub := &Subscription{}
+	err := json.Unmarshal([]byte(`{"name":"sub1","options":{"url":"http://example.com","secret":"shh"}}`), sub)
+	assert.NoError(t, err)
+	assert.Equal(t, "shh", sub.Options.Secret)
+
+	redacted := sub.WithoutSecrets()
+	b, err := json.Marshal(redacted)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "shh")
+	assert.Equal(t, "http://example.com", redacted.Options.TransportOptions().GetString("url"))
+
+	// The original is unchanged, so it can still be used for delivery
+	assert.Equal(t, "shh", sub.Options.Secret)
+	assert.Equal(t, "shh", sub.Options.TransportOptions().GetString("secret"))
+
+	assert.Equal(t, "sub1", (&Subscription{SubscriptionRef: SubscriptionRef{Name: "sub1"}}).WithoutSecrets().Name)
+}
+
 func TestSubscriptionUnMarshalFail(t *testing.T) {
 
 	b, err := json.Marshal(&SubscriptionOptions{})
@@ -126,13 +154,14 @@ func TestSubscriptionUnMarshalFail(t *testing.T) {
 }
 
 func TestNewSubscriptionFilterFromQuery(t *testing.T) {
-	query, _ := url.ParseQuery("filter.events=message_confirmed&filter.topic=topic1&filter.message.author=did:firefly:org/author1&filter.blockchain.name=flapflip&filter.transaction.type=test&filter.group=deprecated")
+	query, _ := url.ParseQuery("filter.events=message_confirmed&filter.topic=topic1&filter.message.author=did:firefly:org/author1&filter.blockchain.name=flapflip&filter.transaction.type=test&filter.messageTag=invoice&filter.group=deprecated")
 	expectedFilter := SubscriptionFilter{
 		Events: "message_confirmed",
 		Topic:  "topic1",
 		Message: MessageFilter{
 			Author: "did:firefly:org/author1",
 		},
+		MessageTag: "invoice",
 		BlockchainEvent: BlockchainEventFilter{
 			Name: "flapflip",
 		},
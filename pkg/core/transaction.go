@@ -45,6 +45,8 @@ var (
 	TransactionTypeTokenApproval = fftypes.FFEnumValue("txtype", "token_approval")
 	// TransactionTypeDataPublish represents a publish to shared storage
 	TransactionTypeDataPublish = fftypes.FFEnumValue("txtype", "data_publish")
+	// TransactionTypeMessagePin anchors the hash of a single confirmed message on the blockchain, without sequencing it
+	TransactionTypeMessagePin = fftypes.FFEnumValue("txtype", "message_pin")
 )
 
 // TransactionRef refers to a transaction, in other types
@@ -104,3 +106,27 @@ func (tx *Transaction) Size() int64 {
 func IsPinned(t TransactionType) bool {
 	return t.Equals(TransactionTypeBatchPin) || t.Equals(TransactionTypeContractInvokePin)
 }
+
+// TxStatus is the status of a transaction on the blockchain, as reported in a receipt
+type TxStatus string
+
+const (
+	// TxStatusPending indicates the transaction has been submitted, but is not yet confirmed as successful or failed
+	TxStatusPending TxStatus = "pending"
+	// TxStatusConfirmed indicates the transaction has been confirmed on the blockchain
+	TxStatusConfirmed TxStatus = "confirmed"
+	// TxStatusFailed indicates the blockchain connector reported the transaction as failed
+	TxStatusFailed TxStatus = "failed"
+)
+
+// BlockchainReceipt records the outcome of a transaction submitted to the blockchain, for the operation that submitted it
+type BlockchainReceipt struct {
+	Namespace   string          `ffstruct:"BlockchainReceipt" json:"namespace"`
+	Operation   *fftypes.UUID   `ffstruct:"BlockchainReceipt" json:"operation"`
+	TxID        string          `ffstruct:"BlockchainReceipt" json:"txId,omitempty"`
+	MessageID   *fftypes.UUID   `ffstruct:"BlockchainReceipt" json:"messageId,omitempty"`
+	BlockNumber string          `ffstruct:"BlockchainReceipt" json:"blockNumber,omitempty"`
+	BlockHash   string          `ffstruct:"BlockchainReceipt" json:"blockHash,omitempty"`
+	Status      TxStatus        `ffstruct:"BlockchainReceipt" json:"status"`
+	ConfirmedAt *fftypes.FFTime `ffstruct:"BlockchainReceipt" json:"confirmedAt,omitempty"`
+}
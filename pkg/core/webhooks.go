@@ -31,6 +31,7 @@ type WebhookSubOptions struct {
 	ReplyTag      string              `ffstruct:"WebhookSubOptions" json:"replytag,omitempty"`
 	ReplyTX       string              `ffstruct:"WebhookSubOptions" json:"replytx,omitempty"`
 	Headers       map[string]string   `ffstruct:"WebhookSubOptions" json:"headers,omitempty"`
+	Secret        string              `ffstruct:"WebhookSubOptions" json:"secret,omitempty"`
 	Query         map[string]string   `ffstruct:"WebhookSubOptions" json:"query,omitempty"`
 	TLSConfigName string              `ffstruct:"WebhookSubOptions" json:"tlsConfigName,omitempty"`
 	TLSConfig     *tls.Config         `ffstruct:"WebhookSubOptions" json:"-" ffexcludeinput:"true"`
@@ -18,6 +18,7 @@ package database
 
 import (
 	"context"
+	"io"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/ffapi"
@@ -73,6 +74,9 @@ type iNamespaceCollection interface {
 
 	// GetNamespace - Get an namespace by name
 	GetNamespace(ctx context.Context, name string) (namespace *core.Namespace, err error)
+
+	// GetDescendantNamespaces - Get all namespaces below the given namespace in the hierarchy, ordered by name
+	GetDescendantNamespaces(ctx context.Context, root string) (namespaces []*core.Namespace, err error)
 }
 
 type iMessageCollection interface {
@@ -87,6 +91,9 @@ type iMessageCollection interface {
 	// UpdateMessage - Update message
 	UpdateMessage(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) (err error)
 
+	// ExpireMessage - Mark a pending message expired, returning false if it was confirmed or otherwise moved on from pending first
+	ExpireMessage(ctx context.Context, namespace string, id *fftypes.UUID) (expired bool, err error)
+
 	// ReplaceMessage updates the message, and assigns it a new sequence number at the front of the list.
 	// A new event is raised for the message, with the new sequence number - as if it was brand new.
 	ReplaceMessage(ctx context.Context, message *core.Message) (err error)
@@ -97,27 +104,57 @@ type iMessageCollection interface {
 	// GetMessageByID - Get a message by ID
 	GetMessageByID(ctx context.Context, namespace string, id *fftypes.UUID) (message *core.Message, err error)
 
+	// GetMessageRef - Get only the ID and sequence of a message by ID, without loading its data references
+	GetMessageRef(ctx context.Context, namespace string, id *fftypes.UUID) (ref *core.IDAndSequence, err error)
+
+	// DeleteMessage - Delete a message, and its references to data, in a single transaction.
+	//                 Only deletes the message if it is in one of the supplied states, otherwise returns fftypes.DeleteRecordNotFound
+	DeleteMessage(ctx context.Context, namespace string, id *fftypes.UUID, states []core.MessageState) (err error)
+
 	// GetMessages - List messages, reverse sorted (newest first) by Confirmed then Created, with pagination, and simple must filters
 	GetMessages(ctx context.Context, namespace string, filter ffapi.Filter) (message []*core.Message, res *ffapi.FilterResult, err error)
 
 	// GetMessageIDs - Retrieves messages, but only querying the messages ID (no other fields)
 	GetMessageIDs(ctx context.Context, namespace string, filter ffapi.Filter) (ids []*core.IDAndSequence, err error)
 
+	// GetMessagesForTag - List messages that have the specified tag in their tags
+	GetMessagesForTag(ctx context.Context, namespace, tag string, filter ffapi.Filter) (message []*core.Message, res *ffapi.FilterResult, err error)
+
 	// GetMessagesForData - List messages where there is a data reference to the specified ID
 	GetMessagesForData(ctx context.Context, namespace string, dataID *fftypes.UUID, filter ffapi.Filter) (message []*core.Message, res *ffapi.FilterResult, err error)
 
+	// GetMessagesPastTTL - List pending messages whose TTL elapsed before the supplied time, oldest expiry first
+	GetMessagesPastTTL(ctx context.Context, namespace string, now *fftypes.FFTime, limit int) (message []*core.Message, err error)
+
 	// GetBatchIDsForMessages - an optimized query to retrieve any non-null batch IDs for a list of message IDs
 	GetBatchIDsForMessages(ctx context.Context, namespace string, msgIDs []*fftypes.UUID) (batchIDs []*fftypes.UUID, err error)
 
 	// GetBatchIDsForDataAttachments - an optimized query to retrieve any non-null batch IDs for a list of data IDs that might be attached to messages in batches
 	GetBatchIDsForDataAttachments(ctx context.Context, namespace string, dataIDs []*fftypes.UUID) (batchIDs []*fftypes.UUID, err error)
+
+	// InsertDeliveryReceipt - Record the acknowledgement of a private message by one of its recipients
+	InsertDeliveryReceipt(ctx context.Context, receipt *core.DeliveryReceipt) (err error)
+
+	// GetDeliveryReceipts - List delivery receipts, oldest first
+	GetDeliveryReceipts(ctx context.Context, namespace string, filter ffapi.Filter) (receipts []*core.DeliveryReceipt, res *ffapi.FilterResult, err error)
+
+	// InsertPendingDeliveryReceipt - Record a delivery receipt that a local recipient needs to broadcast
+	InsertPendingDeliveryReceipt(ctx context.Context, receipt *core.DeliveryReceipt) (err error)
+
+	// GetPendingDeliveryReceipts - List delivery receipts that are yet to be broadcast, oldest first
+	GetPendingDeliveryReceipts(ctx context.Context, namespace string, limit int) (receipts []*core.DeliveryReceipt, err error)
+
+	// DeletePendingDeliveryReceipt - Remove a pending delivery receipt, once it has been broadcast
+	DeletePendingDeliveryReceipt(ctx context.Context, namespace string, id *fftypes.UUID) (err error)
 }
 
 type iDataCollection interface {
 	// UpsertData - Upsert a data record. A hint can be supplied to whether the data already exists.
 	//              The database layer must ensure that if a record already exists, the hash of that existing record
 	//              must match the hash of the record that is being inserted.
-	UpsertData(ctx context.Context, data *core.Data, optimization UpsertOptimization) (err error)
+	//              If allowExistingByHash is set, and a different record with the same hash already exists,
+	//              then nothing is written and the supplied data is updated in-place to be the existing record.
+	UpsertData(ctx context.Context, data *core.Data, optimization UpsertOptimization, allowExistingByHash bool) (err error)
 
 	// InsertDataArray performs a batch insert of data assured to be new records - fails if they already exist, so caller can fall back to upsert individually
 	InsertDataArray(ctx context.Context, data core.DataArray) (err error)
@@ -128,6 +165,9 @@ type iDataCollection interface {
 	// GetDataByID - Get a data record by ID
 	GetDataByID(ctx context.Context, namespace string, id *fftypes.UUID, withValue bool) (message *core.Data, err error)
 
+	// GetDataWithBlob - Get a data record by ID, along with the blob record for any blob attached to it, read in a single transaction
+	GetDataWithBlob(ctx context.Context, namespace string, id *fftypes.UUID, withValue bool) (data *core.Data, blob *core.Blob, err error)
+
 	// GetData - Get data
 	GetData(ctx context.Context, namespace string, filter ffapi.Filter) (message core.DataArray, res *ffapi.FilterResult, err error)
 
@@ -171,6 +211,15 @@ type iTransactionCollection interface {
 
 	// GetTransactions - Get transactions
 	GetTransactions(ctx context.Context, namespace string, filter ffapi.Filter) (txn []*core.Transaction, res *ffapi.FilterResult, err error)
+
+	// UpsertBlockchainReceipt - Record the receipt for a blockchain transaction, replacing any earlier receipt for the same operation
+	UpsertBlockchainReceipt(ctx context.Context, receipt *core.BlockchainReceipt) (err error)
+
+	// GetBlockchainReceiptByOpID - Get the receipt for the blockchain transaction submitted by an operation
+	GetBlockchainReceiptByOpID(ctx context.Context, namespace string, opID *fftypes.UUID) (receipt *core.BlockchainReceipt, err error)
+
+	// GetBlockchainReceiptsForMessages - Get the receipts for the transactions that pinned a set of messages, in the order they were recorded
+	GetBlockchainReceiptsForMessages(ctx context.Context, namespace string, msgIDs []*fftypes.UUID) (receipts []*core.BlockchainReceipt, err error)
 }
 
 type iDatatypeCollection interface {
@@ -214,8 +263,29 @@ type iPinCollection interface {
 	// GetPins - Get pins
 	GetPins(ctx context.Context, namespace string, filter ffapi.Filter) (offset []*core.Pin, res *ffapi.FilterResult, err error)
 
+	// CountPinsInSequenceRange - Count the pins across all namespaces with a sequence after the first value, up to and including the second
+	CountPinsInSequenceRange(ctx context.Context, after, upTo int64) (count int64, err error)
+
+	// GetBlockedContexts - Get the earliest undispatched pin of each context, where that pin is at or before the given sequence
+	GetBlockedContexts(ctx context.Context, namespace string, upToSequence int64, filter ffapi.Filter) (pins []*core.Pin, res *ffapi.FilterResult, err error)
+
+	// GetBlockedStats - Count the contexts returned by GetBlockedContexts, with a histogram bucket for the contexts blocked at or before each cutoff, but after the next
+	GetBlockedStats(ctx context.Context, namespace string, upToSequence int64, cutoffs []*fftypes.FFTime) (stats *core.BlockedContextStats, err error)
+
 	// UpdatePins - Updates pins
 	UpdatePins(ctx context.Context, namespace string, filter ffapi.Filter, update ffapi.Update) (err error)
+
+	// UpsertDeadEvent - Record a pin the aggregator gave up on, updating the existing record if the same pin has failed before
+	UpsertDeadEvent(ctx context.Context, deadEvent *core.DeadEvent) (err error)
+
+	// GetDeadEvents - Get dead events
+	GetDeadEvents(ctx context.Context, namespace string, filter ffapi.Filter) (deadEvents []*core.DeadEvent, res *ffapi.FilterResult, err error)
+
+	// GetDeadEventByID - Get a dead event by ID
+	GetDeadEventByID(ctx context.Context, namespace string, id *fftypes.UUID) (deadEvent *core.DeadEvent, err error)
+
+	// DeleteDeadEvent - Delete a dead event
+	DeleteDeadEvent(ctx context.Context, namespace string, id *fftypes.UUID) (err error)
 }
 
 type iOperationCollection interface {
@@ -254,6 +324,12 @@ type iSubscriptionCollection interface {
 
 	// DeleteSubscriptionByID - Delete a subscription
 	DeleteSubscriptionByID(ctx context.Context, namespace string, id *fftypes.UUID) (err error)
+
+	// InsertDeadLetter - Record an event that a subscription failed to deliver
+	InsertDeadLetter(ctx context.Context, deadLetter *core.DeadLetter) (err error)
+
+	// GetDeadLetters - Get dead letters, most recently recorded first
+	GetDeadLetters(ctx context.Context, namespace string, filter ffapi.Filter) (deadLetters []*core.DeadLetter, res *ffapi.FilterResult, err error)
 }
 
 type iEventCollection interface {
@@ -266,9 +342,28 @@ type iEventCollection interface {
 	// GetEventByID - Get a event by ID
 	GetEventByID(ctx context.Context, namespace string, id *fftypes.UUID) (message *core.Event, err error)
 
+	// DeleteEvent - Delete an event by ID
+	DeleteEvent(ctx context.Context, namespace string, id *fftypes.UUID) (err error)
+
+	// DeleteEventsBefore - Delete all events with a sequence lower than the one supplied, returning the number deleted
+	DeleteEventsBefore(ctx context.Context, namespace string, sequence int64) (count int64, err error)
+
 	// GetEvents - Get events
 	GetEvents(ctx context.Context, namespace string, filter ffapi.Filter) (message []*core.Event, res *ffapi.FilterResult, err error)
 
+	// ArchiveEvents - Move up to limit of the oldest events created before the supplied time into the archive table,
+	//                 stopping at the first event that is not old enough. Returns the number archived
+	ArchiveEvents(ctx context.Context, namespace string, createdBefore *fftypes.FFTime, limit int) (count int64, err error)
+
+	// GetArchivedEvents - Get events that have been moved to the archive table
+	GetArchivedEvents(ctx context.Context, namespace string, filter ffapi.Filter) (message []*core.Event, res *ffapi.FilterResult, err error)
+
+	// GetEventsInNamespaces - Get events across a set of namespaces
+	GetEventsInNamespaces(ctx context.Context, namespaces []string, filter ffapi.Filter) (message []*core.Event, res *ffapi.FilterResult, err error)
+
+	// GetArchivedEventsInNamespaces - Get archived events across a set of namespaces
+	GetArchivedEventsInNamespaces(ctx context.Context, namespaces []string, filter ffapi.Filter) (message []*core.Event, res *ffapi.FilterResult, err error)
+
 	// GetEventsInSequenceRange - Get a range of events between 2 sequence values
 	GetEventsInSequenceRange(ctx context.Context, namespace string, filter ffapi.Filter, startSequence int, endSequence int) (message []*core.Event, res *ffapi.FilterResult, err error)
 }
@@ -332,6 +427,20 @@ type iNonceCollection interface {
 	DeleteNonce(ctx context.Context, hash *fftypes.Bytes32) (err error)
 }
 
+type iPermissionCollection interface {
+	// UpsertPermission - Upsert the role of a principal within a namespace
+	UpsertPermission(ctx context.Context, permission *core.NamespacedPermission) (err error)
+
+	// GetPermission - Get the permission of a principal within a namespace
+	GetPermission(ctx context.Context, namespace, principal string) (permission *core.NamespacedPermission, err error)
+
+	// GetPermissions - Get permissions within a namespace
+	GetPermissions(ctx context.Context, namespace string, filter ffapi.Filter) (permissions []*core.NamespacedPermission, res *ffapi.FilterResult, err error)
+
+	// DeletePermission - Delete the permission of a principal within a namespace
+	DeletePermission(ctx context.Context, namespace, principal string) (err error)
+}
+
 type iNextPinCollection interface {
 	// InsertNextPin - insert a nextpin
 	InsertNextPin(ctx context.Context, nextpin *core.NextPin) (err error)
@@ -356,6 +465,9 @@ type iBlobCollection interface {
 	// GetBlobs - get blobs
 	GetBlobs(ctx context.Context, namespace string, filter ffapi.Filter) (message []*core.Blob, res *ffapi.FilterResult, err error)
 
+	// CountBlobReferences - count the blob records, across all namespaces, that refer to the same payload in the blob store
+	CountBlobReferences(ctx context.Context, payloadRef string) (count int64, err error)
+
 	// DeleteBlob - delete a blob, using its local database ID
 	DeleteBlob(ctx context.Context, sequence int64) (err error)
 }
@@ -594,6 +706,17 @@ type iChartCollection interface {
 type PersistenceInterface interface {
 	core.Named
 
+	// ExportNamespace - Write all data, messages, events and subscriptions for a namespace to the writer as
+	//                   newline-delimited JSON, each type in sequence order
+	ExportNamespace(ctx context.Context, namespace string, w io.Writer) error
+
+	// ImportNamespace - Read records written by ExportNamespace into the namespace, within a single group
+	ImportNamespace(ctx context.Context, namespace string, r io.Reader) error
+
+	// CloneNamespace - Copy all messages, data, datatypes, groups and identities from one namespace to another,
+	//                  with each cloned UUID derived deterministically from the original and the destination namespace
+	CloneNamespace(ctx context.Context, src, dest string) error
+
 	// RunAsGroup instructs the database plugin that all database operations performed within the context
 	// function can be grouped into a single transaction (if supported).
 	// Requirements:
@@ -617,6 +740,7 @@ type PersistenceInterface interface {
 	iVerifiersCollection
 	iGroupCollection
 	iNonceCollection
+	iPermissionCollection
 	iNextPinCollection
 	iBlobCollection
 	iTokenPoolCollection
@@ -700,6 +824,7 @@ const (
 	CollectionNextpins      OtherCollection = "nextpins"
 	CollectionNonces        OtherCollection = "nonces"
 	CollectionOffsets       OtherCollection = "offsets"
+	CollectionPermissions   OtherCollection = "permissions"
 	CollectionTokenBalances OtherCollection = "tokenbalances"
 )
 
@@ -733,6 +858,12 @@ type Callbacks interface {
 	HashCollectionNSEvent(resType HashCollectionNS, eventType core.ChangeEventType, namespace string, hash *fftypes.Bytes32)
 }
 
+// EventCallbacks can optionally be implemented by a Callbacks handler, to be passed the full event on insert
+// so it can filter on the content. Every new event is also emitted to all handlers via OrderedUUIDCollectionNSEvent.
+type EventCallbacks interface {
+	EventCreated(event *core.Event)
+}
+
 // Capabilities defines the capabilities a plugin can report as implementing or not
 type Capabilities struct {
 	Concurrency bool
@@ -755,7 +886,12 @@ var MessageQueryFactory = &ffapi.QueryFields{
 	"pins":           &ffapi.FFStringArrayField{},
 	"state":          &ffapi.StringField{},
 	"confirmed":      &ffapi.TimeField{},
+	"receivedat":     &ffapi.TimeField{},
 	"rejectreason":   &ffapi.StringField{},
+	"contenttype":    &ffapi.StringField{},
+	"replyto":        &ffapi.UUIDField{},
+	"conversationid": &ffapi.UUIDField{},
+	"priority":       &ffapi.Int64Field{},
 	"sequence":       &ffapi.Int64Field{},
 	"txtype":         &ffapi.StringField{},
 	"batch":          &ffapi.UUIDField{},
@@ -804,6 +940,9 @@ var DataQueryFactory = &ffapi.QueryFields{
 	"created":          &ffapi.TimeField{},
 	"value":            &ffapi.JSONField{},
 	"public":           &ffapi.StringField{},
+	"type":             &ffapi.StringField{},
+	"externalref.url":  &ffapi.StringField{},
+	"externalref.hash": &ffapi.Bytes32Field{},
 }
 
 // DatatypeQueryFactory filter fields for data definitions
@@ -818,9 +957,10 @@ var DatatypeQueryFactory = &ffapi.QueryFields{
 
 // OffsetQueryFactory filter fields for data offsets
 var OffsetQueryFactory = &ffapi.QueryFields{
-	"name":    &ffapi.StringField{},
-	"type":    &ffapi.StringField{},
-	"current": &ffapi.Int64Field{},
+	"name":          &ffapi.StringField{},
+	"type":          &ffapi.StringField{},
+	"current":       &ffapi.Int64Field{},
+	"schemaversion": &ffapi.Int64Field{},
 }
 
 // OperationQueryFactory filter fields for data operations
@@ -849,6 +989,40 @@ var SubscriptionQueryFactory = &ffapi.QueryFields{
 	"created":   &ffapi.TimeField{},
 }
 
+// DeadLetterQueryFactory filter fields for dead letters
+var DeadLetterQueryFactory = &ffapi.QueryFields{
+	"id":            &ffapi.UUIDField{},
+	"subscription":  &ffapi.UUIDField{},
+	"event":         &ffapi.UUIDField{},
+	"eventsequence": &ffapi.Int64Field{},
+	"error":         &ffapi.StringField{},
+	"created":       &ffapi.TimeField{},
+}
+
+// DeadEventQueryFactory filter fields for dead events
+var DeadEventQueryFactory = &ffapi.QueryFields{
+	"id":       &ffapi.UUIDField{},
+	"pin":      &ffapi.Int64Field{},
+	"batch":    &ffapi.UUIDField{},
+	"hash":     &ffapi.Bytes32Field{},
+	"index":    &ffapi.Int64Field{},
+	"attempts": &ffapi.Int64Field{},
+	"error":    &ffapi.StringField{},
+	"created":  &ffapi.TimeField{},
+	"updated":  &ffapi.TimeField{},
+}
+
+// DeliveryReceiptQueryFactory filter fields for delivery receipts
+var DeliveryReceiptQueryFactory = &ffapi.QueryFields{
+	"id":          &ffapi.UUIDField{},
+	"messageid":   &ffapi.UUIDField{},
+	"messagehash": &ffapi.Bytes32Field{},
+	"recipient":   &ffapi.StringField{},
+	"key":         &ffapi.StringField{},
+	"broadcast":   &ffapi.UUIDField{},
+	"received":    &ffapi.TimeField{},
+}
+
 // EventQueryFactory filter fields for data events
 var EventQueryFactory = &ffapi.QueryFields{
 	"id":         &ffapi.UUIDField{},
@@ -912,6 +1086,13 @@ var NonceQueryFactory = &ffapi.QueryFields{
 	"nonce": &ffapi.Int64Field{},
 }
 
+// PermissionQueryFactory filter fields for namespaced permissions
+var PermissionQueryFactory = &ffapi.QueryFields{
+	"principal": &ffapi.StringField{},
+	"role":      &ffapi.StringField{},
+	"created":   &ffapi.TimeField{},
+}
+
 // NextPinQueryFactory filter fields for next pins
 var NextPinQueryFactory = &ffapi.QueryFields{
 	"context":  &ffapi.Bytes32Field{},